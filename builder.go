@@ -0,0 +1,224 @@
+// Package traffic2openapi provides a high-level API for turning captured HTTP
+// traffic into OpenAPI specifications and documentation sites.
+//
+// It composes the ir, har, postman, curl, inference, openapi, and sitegen
+// packages with their default options, so they can be embedded in other Go
+// services without shelling out to the CLI. The CLI configures those packages
+// directly and exposes options the Builder does not; use the packages
+// themselves for that level of control:
+//
+//	b := traffic2openapi.NewBuilder()
+//	if err := b.AddHARFile("traffic.har"); err != nil {
+//		return err
+//	}
+//	b.Redact(ir.RedactRules{Headers: []string{"Authorization"}})
+//	spec, err := b.GenerateSpec(traffic2openapi.DefaultSpecOptions())
+package traffic2openapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/grokify/traffic2openapi/pkg/curl"
+	"github.com/grokify/traffic2openapi/pkg/har"
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/grokify/traffic2openapi/pkg/postman"
+	"github.com/grokify/traffic2openapi/pkg/sitegen"
+)
+
+// ErrNoRecords is returned when generating output from a builder with no records.
+var ErrNoRecords = errors.New("no records")
+
+// SpecOptions configures spec generation.
+type SpecOptions struct {
+	// Engine configures endpoint and schema inference.
+	Engine inference.EngineOptions
+
+	// Generator configures the generated OpenAPI document.
+	Generator openapi.GeneratorOptions
+}
+
+// DefaultSpecOptions returns the default spec generation options.
+func DefaultSpecOptions() SpecOptions {
+	return SpecOptions{
+		Engine:    inference.DefaultEngineOptions(),
+		Generator: openapi.DefaultGeneratorOptions(),
+	}
+}
+
+// Builder collects IR records from any supported source and generates
+// OpenAPI specs and documentation sites from them.
+type Builder struct {
+	records []ir.IRRecord
+	rules   []ir.RedactRules
+}
+
+// NewBuilder creates an empty builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// AddRecords adds IR records.
+func (b *Builder) AddRecords(records ...ir.IRRecord) *Builder {
+	b.records = append(b.records, records...)
+	return b
+}
+
+// AddIRFile adds records from an IR file (.ndjson or .json) or directory.
+func (b *Builder) AddIRFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("input path error: %w", err)
+	}
+
+	var records []ir.IRRecord
+	if info.IsDir() {
+		records, err = ir.ReadDir(path)
+	} else {
+		records, err = ir.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("reading IR: %w", err)
+	}
+
+	b.AddRecords(records...)
+	return nil
+}
+
+// AddIRReader adds all records from an IR reader and closes it.
+func (b *Builder) AddIRReader(reader ir.IRReader) error {
+	defer reader.Close()
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading IR: %w", err)
+		}
+		b.records = append(b.records, *record)
+	}
+}
+
+// AddHARFile adds records converted from a HAR file or directory of HAR files.
+func (b *Builder) AddHARFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("input path error: %w", err)
+	}
+
+	reader := har.NewReader()
+	var records []ir.IRRecord
+	if info.IsDir() {
+		records, err = reader.ReadDir(path)
+	} else {
+		records, err = reader.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("reading HAR: %w", err)
+	}
+
+	b.AddRecords(records...)
+	return nil
+}
+
+// AddPostmanFile adds records converted from a Postman collection.
+func (b *Builder) AddPostmanFile(path string, opts ...postman.ConverterOption) error {
+	records, err := postman.ConvertFileToRecords(path, opts...)
+	if err != nil {
+		return fmt.Errorf("reading Postman collection: %w", err)
+	}
+
+	b.AddRecords(records...)
+	return nil
+}
+
+// AddCurlFile adds records converted from a file of curl commands.
+func (b *Builder) AddCurlFile(path string) error {
+	records, err := curl.NewReader().ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading curl commands: %w", err)
+	}
+
+	b.AddRecords(records...)
+	return nil
+}
+
+// Redact adds redaction rules. Rules apply to all records, including those
+// added later, whenever records are read or output is generated.
+func (b *Builder) Redact(rules ir.RedactRules) *Builder {
+	b.rules = append(b.rules, rules)
+	return b
+}
+
+// Records returns deep copies of the collected records with redaction rules
+// applied, so callers may modify them without affecting the builder.
+func (b *Builder) Records() []ir.IRRecord {
+	records := b.records
+	if len(b.rules) == 0 {
+		result := make([]ir.IRRecord, len(records))
+		for i, record := range records {
+			result[i] = ir.CloneRecord(record)
+		}
+		return result
+	}
+	for _, rules := range b.rules {
+		records = ir.RedactAll(records, rules)
+	}
+	return records
+}
+
+// Len returns the number of collected records.
+func (b *Builder) Len() int {
+	return len(b.records)
+}
+
+// Infer runs endpoint and schema inference over the collected records.
+func (b *Builder) Infer(opts inference.EngineOptions) (*inference.InferenceResult, error) {
+	if len(b.records) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	engine := inference.NewEngine(opts)
+	engine.ProcessRecords(b.Records())
+	return engine.Finalize(), nil
+}
+
+// GenerateSpec generates an OpenAPI spec from the collected records.
+func (b *Builder) GenerateSpec(opts SpecOptions) (*openapi.Spec, error) {
+	result, err := b.Infer(opts.Engine)
+	if err != nil {
+		return nil, err
+	}
+	return openapi.GenerateFromInference(result, opts.Generator), nil
+}
+
+// WriteSpec generates an OpenAPI spec and writes it to path.
+// Format is determined by file extension (.json, .yaml, or .yml).
+func (b *Builder) WriteSpec(path string, opts SpecOptions) error {
+	spec, err := b.GenerateSpec(opts)
+	if err != nil {
+		return err
+	}
+	if err := openapi.WriteFile(path, spec); err != nil {
+		return fmt.Errorf("writing spec: %w", err)
+	}
+	return nil
+}
+
+// GenerateSite generates a static HTML documentation site in dir.
+// If opts is nil, default site options are used.
+func (b *Builder) GenerateSite(dir string, opts *sitegen.Options) error {
+	if len(b.records) == 0 {
+		return ErrNoRecords
+	}
+
+	gen := sitegen.NewGenerator(dir, opts)
+	gen.ProcessRecords(b.Records())
+	return gen.Generate()
+}
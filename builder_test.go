@@ -0,0 +1,83 @@
+package traffic2openapi
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestBuilderGenerateSpec(t *testing.T) {
+	b := NewBuilder()
+	if err := b.AddIRFile("examples/sample-stream.ndjson"); err != nil {
+		t.Fatalf("AddIRFile failed: %v", err)
+	}
+	if err := b.AddHARFile("examples/har/sample.har"); err != nil {
+		t.Fatalf("AddHARFile failed: %v", err)
+	}
+	if b.Len() == 0 {
+		t.Fatal("expected records")
+	}
+
+	spec, err := b.GenerateSpec(DefaultSpecOptions())
+	if err != nil {
+		t.Fatalf("GenerateSpec failed: %v", err)
+	}
+	if len(spec.Paths) == 0 {
+		t.Error("expected paths in generated spec")
+	}
+
+	outputDir := t.TempDir()
+	if err := b.WriteSpec(filepath.Join(outputDir, "openapi.yaml"), DefaultSpecOptions()); err != nil {
+		t.Fatalf("WriteSpec failed: %v", err)
+	}
+	if err := b.GenerateSite(filepath.Join(outputDir, "site"), nil); err != nil {
+		t.Fatalf("GenerateSite failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "site", "index.html")); err != nil {
+		t.Errorf("expected site index: %v", err)
+	}
+}
+
+func TestBuilderRedact(t *testing.T) {
+	b := NewBuilder()
+	b.Redact(ir.RedactRules{Headers: []string{"x-api-key"}})
+	if err := b.AddIRReader(ir.NewSliceReader([]ir.IRRecord{{
+		Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items", Headers: map[string]string{"x-api-key": "secret"}},
+		Response: ir.Response{Status: 200},
+	}})); err != nil {
+		t.Fatalf("AddIRReader failed: %v", err)
+	}
+
+	records := b.Records()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	if got := records[0].Request.Headers["x-api-key"]; got != ir.DefaultRedactReplacement {
+		t.Errorf("expected redacted header, got %q", got)
+	}
+}
+
+func TestBuilderRecordsAreCopies(t *testing.T) {
+	headers := map[string]string{"Accept": "application/json"}
+	b := NewBuilder().AddRecords(ir.IRRecord{
+		Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items", Headers: headers},
+		Response: ir.Response{Status: 200},
+	})
+
+	b.Records()[0].Request.Headers["Accept"] = "text/plain"
+	if got := b.Records()[0].Request.Headers["Accept"]; got != "application/json" {
+		t.Errorf("expected builder records to be unchanged, got %q", got)
+	}
+	if headers["Accept"] != "application/json" {
+		t.Error("expected the added record's headers to be unchanged")
+	}
+}
+
+func TestBuilderNoRecords(t *testing.T) {
+	if _, err := NewBuilder().GenerateSpec(DefaultSpecOptions()); !errors.Is(err, ErrNoRecords) {
+		t.Errorf("expected ErrNoRecords, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/apiblueprint"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/grokify/traffic2openapi/pkg/raml"
+	"github.com/spf13/cobra"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle <spec-file|url>",
+	Short: "Bundle a multi-file OpenAPI spec into a single file",
+	Long: `Resolve the external $refs of an OpenAPI spec, to relative files and
+URLs, and write a single self-contained spec.
+
+Refs to components in other files (common.yaml#/components/schemas/Pet) are
+copied into the spec's own components and referenced locally, renamed if the
+name is already taken. Other external refs are replaced by the content they
+point to. Key order and fields such as x- extensions are preserved.
+
+The diff, merge, and serve commands resolve refs the same way with
+--resolve-refs.
+
+Examples:
+  # Bundle to stdout as YAML
+  traffic2openapi bundle openapi.yaml
+
+  # Bundle to a JSON file
+  traffic2openapi bundle openapi.yaml -o bundled.json
+
+  # Bundle a spec published on the web
+  traffic2openapi bundle https://example.com/api/openapi.yaml -o api.yaml
+
+  # Fail instead of fetching URL refs
+  traffic2openapi bundle openapi.yaml --local-only`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBundle,
+}
+
+var (
+	bundleOutput    string
+	bundleFormat    string
+	bundleLocalOnly bool
+
+	// resolveRefs is set by the --resolve-refs flag of diff, merge, and serve.
+	resolveRefs bool
+)
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+
+	bundleCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "Output file path (default: stdout)")
+	bundleCmd.Flags().StringVarP(&bundleFormat, "format", "f", "", "Output format: json or yaml (default: from output extension, else yaml)")
+	bundleCmd.Flags().BoolVar(&bundleLocalOnly, "local-only", false, "Resolve file refs only, failing on URL refs")
+}
+
+func runBundle(cmd *cobra.Command, args []string) error {
+	format := openapi.FormatYAML
+	switch {
+	case bundleFormat != "":
+		format = openapi.Format(strings.ToLower(bundleFormat))
+	case strings.EqualFold(filepath.Ext(bundleOutput), ".json"):
+		format = openapi.FormatJSON
+	}
+	if format != openapi.FormatJSON && format != openapi.FormatYAML {
+		return inputError(fmt.Errorf("unsupported format: %s (use json or yaml)", bundleFormat))
+	}
+
+	doc, err := openapi.Bundle(args[0], openapi.ReadOptions{AllowRemote: !bundleLocalOnly})
+	if err != nil {
+		return inputError(fmt.Errorf("bundling %s: %w", args[0], err))
+	}
+
+	var out io.Writer = cmd.OutOrStdout()
+	if bundleOutput != "" {
+		f, err := os.Create(bundleOutput)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := openapi.WriteDocument(out, doc, format); err != nil {
+		return err
+	}
+	if bundleOutput != "" {
+		cmd.Printf("Wrote bundled spec to %s\n", bundleOutput)
+	}
+	return nil
+}
+
+// addResolveRefsFlag adds --resolve-refs to a command that reads specs.
+func addResolveRefsFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&resolveRefs, "resolve-refs", false, "Resolve external $refs to other files and URLs, as the bundle command does")
+}
+
+// readSpec reads an OpenAPI spec, resolving external refs if --resolve-refs is set.
+// RAML (.raml) and API Blueprint (.apib) documents are converted to OpenAPI.
+func readSpec(path string) (*openapi.Spec, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".raml":
+		return raml.ReadFile(path)
+	case ".apib":
+		return apiblueprint.ReadFile(path)
+	}
+	return openapi.ReadFileWithOptions(path, openapi.ReadOptions{ResolveRefs: resolveRefs, AllowRemote: true})
+}
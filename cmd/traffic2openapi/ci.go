@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/cireport"
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// Drift categories accepted by --fail-on.
+const (
+	ciFailEndpoints = "endpoints"
+	ciFailStatuses  = "statuses"
+	ciFailFields    = "fields"
+	ciFailBreaking  = "breaking"
+)
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Fail when traffic drifts from a golden OpenAPI spec",
+	Long: `Check IR traffic against a committed golden OpenAPI spec in CI.
+
+The spec is inferred from traffic and compared with the golden spec, which is
+never modified. The command prints a focused report and exits with status 1
+(with --detailed-exit-codes, 3 for breaking drift and 2 for other findings)
+when traffic reveals:
+
+  endpoints  observed endpoints the spec has no operation for
+  statuses   observed status codes an operation declares no response for
+  fields     observed body fields the spec's schemas don't declare
+  breaking   observed values of a type the schema doesn't allow, and
+             required response fields never observed
+
+Use --fail-on to choose which categories fail the check; the others are
+still reported. The github format emits workflow commands so findings appear
+as annotations on the run; junit and sarif write reports for CI test tabs
+and code scanning.
+
+Examples:
+  # Check traffic against the golden spec
+  traffic2openapi ci --golden api.yaml -i traffic/
+
+  # GitHub Actions annotations
+  traffic2openapi ci --golden api.yaml -i traffic/ --format github
+
+  # Only fail on undocumented endpoints and breaking drift
+  traffic2openapi ci --golden api.yaml -i traffic/ --fail-on endpoints,breaking
+
+  # Keep the regenerated spec as a build artifact
+  traffic2openapi ci --golden api.yaml -i traffic/ --spec-out observed.yaml
+
+  # Post failures to a Slack channel
+  traffic2openapi ci --golden api.yaml -i traffic/ --notify "$SLACK_WEBHOOK_URL" --notify-format slack`,
+	RunE: runCI,
+}
+
+var (
+	ciGoldenPath  string
+	ciInputPath   string
+	ciFormat      string
+	ciFailOn      []string
+	ciSpecOutPath string
+)
+
+func init() {
+	rootCmd.AddCommand(ciCmd)
+
+	ciCmd.Flags().StringVar(&ciGoldenPath, "golden", "", "Golden OpenAPI spec, or RAML or API Blueprint document, to check against (required)")
+	ciCmd.Flags().StringVarP(&ciInputPath, "input", "i", "", "Input file or directory containing IR files (required)")
+	ciCmd.Flags().StringVarP(&ciFormat, "format", "f", "text", "Output format: text, json, github, junit, or sarif")
+	ciCmd.Flags().StringSliceVar(&ciFailOn, "fail-on", []string{ciFailEndpoints, ciFailStatuses, ciFailFields, ciFailBreaking},
+		"Drift categories that fail the check (comma-separated: endpoints,statuses,fields,breaking)")
+	ciCmd.Flags().StringVar(&ciSpecOutPath, "spec-out", "", "Write the spec regenerated from traffic to this file")
+	addNotifyFlags(ciCmd)
+
+	_ = ciCmd.MarkFlagRequired("golden")
+	_ = ciCmd.MarkFlagRequired("input")
+}
+
+func runCI(cmd *cobra.Command, args []string) error {
+	switch ciFormat {
+	case "text", "json", "github", cireport.FormatJUnit, cireport.FormatSARIF:
+	default:
+		return inputError(fmt.Errorf("unsupported format: %s (use text, json, github, junit, or sarif)", ciFormat))
+	}
+	for _, category := range ciFailOn {
+		switch category {
+		case ciFailEndpoints, ciFailStatuses, ciFailFields, ciFailBreaking:
+		default:
+			return inputError(fmt.Errorf("unsupported drift category: %s (use endpoints, statuses, fields, or breaking)", category))
+		}
+	}
+	if err := checkNotifyFlags(); err != nil {
+		return err
+	}
+
+	golden, err := readSpec(ciGoldenPath)
+	if err != nil {
+		return inputError(fmt.Errorf("reading golden spec: %w", err))
+	}
+
+	records, err := readIRInput(cmd, ciInputPath)
+	if err != nil {
+		return fmt.Errorf("reading IR files: %w", err)
+	}
+
+	result := inference.InferFromRecords(records)
+	report := openapi.Drift(golden, result, records)
+
+	if ciSpecOutPath != "" {
+		spec := openapi.GenerateFromInference(result, openapi.DefaultGeneratorOptions())
+		if err := openapi.WriteFile(ciSpecOutPath, spec); err != nil {
+			return fmt.Errorf("writing regenerated spec: %w", err)
+		}
+		cmd.Printf("Wrote regenerated spec to %s\n", ciSpecOutPath)
+	}
+
+	code := ciExitCode(report)
+	failed := code != exitOK
+	switch ciFormat {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	case "github":
+		outputCIGitHub(report)
+	case cireport.FormatJUnit, cireport.FormatSARIF:
+		if err := ciReport(report).Write(os.Stdout, ciFormat); err != nil {
+			return err
+		}
+	default:
+		outputCIText(cmd, report, len(records), failed)
+	}
+
+	if failed {
+		notify(cmd, notification{
+			Command:  "ci",
+			Title:    fmt.Sprintf("Traffic drifts from the golden spec %s", ciGoldenPath),
+			Breaking: code == exitBreaking,
+			Findings: findingMessages(ciReport(report)),
+			Report:   report,
+		})
+		return withExitCode(code, nil)
+	}
+	return nil
+}
+
+// ciExitCode returns exitBreaking if the report has breaking findings and
+// --fail-on includes them, exitDifferences for findings in other --fail-on
+// categories, and exitOK otherwise.
+func ciExitCode(report *openapi.DriftReport) int {
+	counts := map[string]int{
+		ciFailEndpoints: len(report.UndocumentedEndpoints),
+		ciFailStatuses:  len(report.UndocumentedStatuses),
+		ciFailFields:    len(report.UndocumentedFields),
+		ciFailBreaking:  len(report.Breaking),
+	}
+	code := exitOK
+	for _, category := range ciFailOn {
+		if counts[category] == 0 {
+			continue
+		}
+		if category == ciFailBreaking {
+			return exitBreaking
+		}
+		code = exitDifferences
+	}
+	return code
+}
+
+// ciLevel returns the report level for findings in a category.
+func ciLevel(category string) cireport.Level {
+	if slices.Contains(ciFailOn, category) {
+		return cireport.LevelError
+	}
+	return cireport.LevelWarning
+}
+
+// ciReport converts drift findings to JUnit/SARIF checks located in the
+// golden spec. Categories in --fail-on are errors, others warnings.
+func ciReport(report *openapi.DriftReport) *cireport.Report {
+	r := cireport.NewReport("traffic2openapi ci", version)
+	r.Rule("undocumented-endpoint", "Observed endpoint with no spec operation")
+	r.Rule("undocumented-status", "Observed status code the operation declares no response for")
+	r.Rule("undocumented-field", "Observed body field the schema doesn't declare")
+	r.Rule(openapi.DriftTypeMismatch, "Observed value of a type the schema doesn't allow")
+	r.Rule(openapi.DriftMissingRequired, "Required response field never observed")
+
+	add := func(category, name, ruleID, message string) {
+		r.Add(cireport.Check{
+			Name:    name,
+			Group:   category,
+			RuleID:  ruleID,
+			Level:   ciLevel(category),
+			Message: message,
+			File:    ciGoldenPath,
+		})
+	}
+	for _, endpoint := range report.UndocumentedEndpoints {
+		add(ciFailEndpoints, endpoint, "undocumented-endpoint", "Undocumented endpoint "+endpoint)
+	}
+	for _, s := range report.UndocumentedStatuses {
+		name := s.Method + " " + s.Path
+		add(ciFailStatuses, name, "undocumented-status", fmt.Sprintf("%s: undocumented status %d (%d records)", name, s.Status, s.Count))
+	}
+	for _, f := range report.UndocumentedFields {
+		name := f.Method + " " + f.Path
+		add(ciFailFields, name, "undocumented-field", fmt.Sprintf("%s (%s): undocumented field %s", name, f.Location, fieldWithType(f.Field, f.Type)))
+	}
+	for _, d := range report.Breaking {
+		name := d.Method + " " + d.Path
+		add(ciFailBreaking, name, d.Kind, fmt.Sprintf("%s (%s): %s", name, d.Location, driftDescription(d)))
+	}
+	if len(r.Checks) == 0 {
+		r.Add(cireport.Check{Name: "golden spec", Group: "ci", File: ciGoldenPath})
+	}
+	return r
+}
+
+// ciMarker returns the marker for findings in a category: an error if the
+// category fails the check, a warning otherwise.
+func ciMarker(category string) string {
+	if slices.Contains(ciFailOn, category) {
+		return "❌"
+	}
+	return "⚠️ "
+}
+
+func outputCIText(cmd *cobra.Command, report *openapi.DriftReport, records int, failed bool) {
+	cmd.Printf("Checked %d records against %s: %d operations matched\n", records, ciGoldenPath, report.Operations)
+
+	if len(report.UndocumentedEndpoints) > 0 {
+		marker := ciMarker(ciFailEndpoints)
+		cmd.Printf("\n%s Undocumented Endpoints:\n", marker)
+		for _, endpoint := range report.UndocumentedEndpoints {
+			cmd.Printf("  %s %s\n", marker, endpoint)
+		}
+	}
+
+	if len(report.UndocumentedStatuses) > 0 {
+		marker := ciMarker(ciFailStatuses)
+		cmd.Printf("\n%s Undocumented Status Codes:\n", marker)
+		for _, s := range report.UndocumentedStatuses {
+			line := fmt.Sprintf("  %s %s %s: %d (%d records)", marker, s.Method, s.Path, s.Status, s.Count)
+			if len(s.RecordIDs) > 0 {
+				line += " e.g. " + strings.Join(s.RecordIDs, ", ")
+			}
+			cmd.Println(line)
+		}
+	}
+
+	if len(report.UndocumentedFields) > 0 {
+		marker := ciMarker(ciFailFields)
+		cmd.Printf("\n%s Undocumented Fields:\n", marker)
+		for _, f := range report.UndocumentedFields {
+			cmd.Printf("  %s %s %s (%s): %s\n", marker, f.Method, f.Path, f.Location, fieldWithType(f.Field, f.Type))
+		}
+	}
+
+	if len(report.Breaking) > 0 {
+		marker := ciMarker(ciFailBreaking)
+		cmd.Printf("\n%s Breaking Drift:\n", marker)
+		for _, d := range report.Breaking {
+			cmd.Printf("  %s [%s] %s %s (%s): %s\n", marker, strings.ToUpper(d.Kind), d.Method, d.Path, d.Location, driftDescription(d))
+		}
+	}
+
+	if failed {
+		cmd.Println("\nTraffic drifts from the golden spec")
+	} else {
+		cmd.Println("\nTraffic matches the golden spec")
+	}
+}
+
+// outputCIGitHub prints findings as GitHub Actions workflow commands.
+func outputCIGitHub(report *openapi.DriftReport) {
+	annotate := func(category, title, message string) {
+		level := "warning"
+		if slices.Contains(ciFailOn, category) {
+			level = "error"
+		}
+		fmt.Printf("::%s file=%s,title=%s::%s\n", level, githubEscapeProperty(ciGoldenPath), githubEscapeProperty(title), githubEscapeData(message))
+	}
+
+	for _, endpoint := range report.UndocumentedEndpoints {
+		annotate(ciFailEndpoints, "Undocumented endpoint", endpoint)
+	}
+	for _, s := range report.UndocumentedStatuses {
+		annotate(ciFailStatuses, "Undocumented status code", fmt.Sprintf("%s %s: %d (%d records)", s.Method, s.Path, s.Status, s.Count))
+	}
+	for _, f := range report.UndocumentedFields {
+		annotate(ciFailFields, "Undocumented field", fmt.Sprintf("%s %s (%s): %s", f.Method, f.Path, f.Location, fieldWithType(f.Field, f.Type)))
+	}
+	for _, d := range report.Breaking {
+		annotate(ciFailBreaking, "Breaking drift", fmt.Sprintf("%s %s (%s): %s", d.Method, d.Path, d.Location, driftDescription(d)))
+	}
+}
+
+func fieldWithType(field, typ string) string {
+	if typ == "" {
+		return field
+	}
+	return field + " " + typ
+}
+
+// driftDescription describes a breaking drift finding.
+func driftDescription(d openapi.SchemaDrift) string {
+	field := d.Field
+	if field == "" {
+		field = "body"
+	}
+	if d.Kind == openapi.DriftMissingRequired {
+		return fmt.Sprintf("required field %s never observed", field)
+	}
+	return fmt.Sprintf("%s declared %s, observed %s", field, d.Declared, d.Observed)
+}
+
+// githubEscapeData escapes a workflow command message.
+func githubEscapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubEscapeProperty escapes a workflow command property value.
+func githubEscapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/grokify/omnistorage"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish|powershell>",
+	Short: "Generate shell completion scripts",
+	Long: `Generate a completion script for bash, zsh, fish, or PowerShell.
+
+Completions cover commands, flags, the values of enumerated flags such as
+--format and --fail-on, file names by extension, and record IDs for show.
+
+Examples:
+  # Load bash completions in the current shell
+  source <(traffic2openapi completion bash)
+
+  # Install zsh completions
+  traffic2openapi completion zsh > "${fpath[1]}/_traffic2openapi"
+
+  # Install fish completions
+  traffic2openapi completion fish > ~/.config/fish/completions/traffic2openapi.fish`,
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	RunE:                  runCompletion,
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	switch args[0] {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(out, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(out)
+	case "fish":
+		return rootCmd.GenFishCompletion(out, true)
+	default:
+		return rootCmd.GenPowerShellCompletionWithDesc(out)
+	}
+}
+
+// IR and OpenAPI file extensions for completion. Commands that read specs
+// with readSpec also accept RAML and API Blueprint documents.
+var (
+	irExtensions      = []string{"ndjson", "json", "gz"}
+	specExtensions    = []string{"yaml", "yml", "json"}
+	anySpecExtensions = []string{"yaml", "yml", "json", "raml", "apib"}
+)
+
+// registerCompletions registers completions for flag values and arguments.
+// It runs after every command's init has defined its flags.
+func registerCompletions() {
+	values := []struct {
+		cmd    *cobra.Command
+		flag   string
+		values []string
+	}{
+		{generateCmd, "version", []string{"3.0", "3.1", "3.2"}},
+		{generateCmd, "format", []string{"json", "yaml"}},
+		{generateCmd, "header-mode", []string{"blacklist", "allowlist", "none"}},
+		{generateCmd, "noise-filter", []string{"off", "drop", "tag"}},
+		{generateCmd, "example-select", []string{"first", "realistic"}},
+		{generateCmd, "tag-groups", []string{"resource", "host"}},
+		{enrichCmd, "format", []string{"json", "yaml"}},
+		{exportArazzoCmd, "format", []string{"json", "yaml"}},
+		{exportFlowsCmd, "format", []string{"json", "text"}},
+		{postmanCmd, "format", []string{"ndjson", "batch"}},
+		{errorsCmd, "format", []string{"text", "json"}},
+		{evolutionCmd, "format", []string{"text", "json"}},
+		{diffCmd, "format", []string{"text", "json", "junit", "sarif"}},
+		{diffCmd, "fail-on", []string{"none", "changes", "breaking"}},
+		{coverageCmd, "format", []string{"text", "json", "junit", "sarif"}},
+		{coverageCmd, "fail-on", []string{"none", "undocumented", "uncovered"}},
+		{ciCmd, "format", []string{"text", "json", "github", "junit", "sarif"}},
+		{ciCmd, "fail-on", []string{ciFailEndpoints, ciFailStatuses, ciFailFields, ciFailBreaking}},
+		{watchdogCmd, "alert-on", []string{ciFailEndpoints, ciFailStatuses, ciFailFields, ciFailBreaking}},
+		{validateCmd, "format", []string{"text", "junit", "sarif"}},
+		{validateSpecCmd, "format", []string{"text", "junit", "sarif"}},
+		{validateSpecCmd, "fail-on", []string{"error", "warning"}},
+		{specStatsCmd, "format", []string{"text", "json"}},
+		{bundleCmd, "format", []string{"json", "yaml"}},
+		{diffCmd, "notify-format", []string{notifyFormatJSON, notifyFormatSlack}},
+		{ciCmd, "notify-format", []string{notifyFormatJSON, notifyFormatSlack}},
+		{watchdogCmd, "notify-format", []string{notifyFormatJSON, notifyFormatSlack}},
+		{initCmd, "mode", []string{initModeTransport, initModeMiddleware, initModeProxy}},
+		{mergeCmd, "sort-by", []string{mergeSortTimestamp}},
+		{mergeCmd, "dedupe-by", []string{mergeDedupeID, mergeDedupeSemantic}},
+		{mergeCmd, "strategy", []string{"first-wins", "last-wins", "deep-merge", "fail"}},
+		{sitePublishCmd, "backend", omnistorage.Backends()},
+	}
+	for _, v := range values {
+		_ = v.cmd.RegisterFlagCompletionFunc(v.flag, cobra.FixedCompletions(v.values, cobra.ShellCompDirectiveNoFileComp))
+	}
+
+	files := []struct {
+		cmd        *cobra.Command
+		flag       string
+		extensions []string
+	}{
+		{generateCmd, "input", irExtensions},
+		{generateCmd, "select", []string{"json"}},
+		{enrichCmd, "input", irExtensions},
+		{enrichCmd, "spec", specExtensions},
+		{coverageCmd, "input", irExtensions},
+		{coverageCmd, "spec", anySpecExtensions},
+		{ciCmd, "input", irExtensions},
+		{ciCmd, "golden", anySpecExtensions},
+		{watchdogCmd, "input", irExtensions},
+		{watchdogCmd, "spec", specExtensions},
+		{exploreCmd, "input", irExtensions},
+		{exploreCmd, "output", []string{"json"}},
+		{errorsCmd, "input", irExtensions},
+		{evolutionCmd, "input", irExtensions},
+		{dedupeCmd, "input", irExtensions},
+		{redactCmd, "input", irExtensions},
+		{redactCmd, "encrypt-key", []string{"pem"}},
+		{decryptCmd, "input", irExtensions},
+		{decryptCmd, "key", []string{"pem"}},
+		{showCmd, "input", irExtensions},
+		{siteCmd, "input", irExtensions},
+		{serveCmd, "from-ir", irExtensions},
+		{exportHARCmd, "input", irExtensions},
+		{exportFlowsCmd, "input", irExtensions},
+		{exportArazzoCmd, "input", irExtensions},
+		{exportTypeSpecCmd, "input", irExtensions},
+		{exportTypeSpecCmd, "spec", specExtensions},
+		{exportSmithyCmd, "input", irExtensions},
+		{exportSmithyCmd, "spec", specExtensions},
+		{harCmd, "input", []string{"har", "json"}},
+		{postmanCmd, "input", []string{"json"}},
+		{openapiConvertCmd, "input", specExtensions},
+		{pcapCmd, "input", []string{"pcap", "pcapng", "cap"}},
+	}
+	for _, f := range files {
+		_ = f.cmd.MarkFlagFilename(f.flag, f.extensions...)
+	}
+
+	_ = initCmd.MarkFlagDirname("dir")
+	_ = sitePublishCmd.MarkFlagDirname("repo")
+
+	diffCmd.ValidArgsFunction = fileArgs(anySpecExtensions)
+	validateSpecCmd.ValidArgsFunction = fileArgs(specExtensions)
+	bundleCmd.ValidArgsFunction = fileArgs(specExtensions)
+	validateCmd.ValidArgsFunction = fileArgs(irExtensions)
+	showCmd.ValidArgsFunction = completeRecordIDs
+}
+
+// fileArgs completes positional arguments with files having the given
+// extensions, or directories.
+func fileArgs(extensions []string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return extensions, cobra.ShellCompDirectiveFilterFileExt
+	}
+}
+
+// maxCompletedRecordIDs limits the record IDs offered for show.
+const maxCompletedRecordIDs = 200
+
+// completeRecordIDs completes show's record ID from the records in the
+// --input file, once it has been given.
+func completeRecordIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 || showInputPath == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	records, err := ir.ReadFile(showInputPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var ids []cobra.Completion
+	for _, record := range records {
+		if record.Id == nil || !strings.HasPrefix(*record.Id, toComplete) {
+			continue
+		}
+		ids = append(ids, cobra.CompletionWithDesc(*record.Id, string(record.Request.Method)+" "+record.Request.Path))
+		if len(ids) == maxCompletedRecordIDs {
+			break
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
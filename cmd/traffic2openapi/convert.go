@@ -1,6 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
 	"github.com/spf13/cobra"
 )
 
@@ -12,6 +17,9 @@ var convertCmd = &cobra.Command{
 Supported sources:
   - har:     HAR (HTTP Archive) files from browser DevTools, Playwright, etc.
   - postman: Postman Collection v2.1 files
+  - curl:    curl command lines (one per line, from a file or stdin)
+  - openapi: OpenAPI specs (synthetic records, one per operation)
+  - pcap:    pcap/pcapng packet captures, with an SSLKEYLOGFILE for HTTPS
 
 Examples:
   # Convert HAR files to IR
@@ -23,10 +31,119 @@ Examples:
   # Convert Postman collection to IR
   traffic2openapi convert postman -i collection.json -o api.ndjson
 
+  # Convert curl commands to IR
+  traffic2openapi convert curl -i commands.txt -o traffic.ndjson
+
+  # Convert a packet capture, decrypting HTTPS with logged TLS secrets
+  traffic2openapi convert pcap -i capture.pcap --keylog keys.log -o traffic.ndjson
+
   # Convert Postman collection with base URL
-  traffic2openapi convert postman -i collection.json -o api.ndjson --base-url https://api.example.com`,
+  traffic2openapi convert postman -i collection.json -o api.ndjson --base-url https://api.example.com
+
+  # Tag converted records with metadata
+  traffic2openapi convert har -i recording.har -o traffic.ndjson --meta environment=staging
+
+  # Write a record ID index for fast lookups with show
+  traffic2openapi convert har -i recording.har -o traffic.ndjson --index`,
 }
 
+var (
+	convertMeta  []string
+	convertIndex bool
+)
+
 func init() {
 	rootCmd.AddCommand(convertCmd)
+
+	convertCmd.PersistentFlags().StringArrayVar(&convertMeta, "meta", nil, "Metadata to add to every record as key=value (can be repeated)")
+	convertCmd.PersistentFlags().BoolVar(&convertIndex, "index", false, "Write a sidecar record ID index next to NDJSON output (used by show)")
+}
+
+// convertMetadata returns the metadata set with --meta.
+func convertMetadata() (map[string]any, error) {
+	if len(convertMeta) == 0 {
+		return nil, nil
+	}
+	metadata, err := ir.ParseMetadata(convertMeta)
+	return metadata, inputError(err)
+}
+
+// writeIRFile writes records to path like ir.WriteFile. With index, the
+// output must be NDJSON (.ndjson or .ndjson.gz) and a sidecar record ID
+// index is written next to it.
+func writeIRFile(path string, records []ir.IRRecord, index bool) error {
+	if !index {
+		return ir.WriteFile(path, records)
+	}
+	w, err := createIndexedIRFile(path)
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		if err := w.Write(&records[i]); err != nil {
+			_ = w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// createIRFile creates a streaming writer for path in the format ir.WriteFile
+// would write: NDJSON for .ndjson, gzip-compressed NDJSON for .ndjson.gz, and
+// batch JSON otherwise. With index, see createIndexedIRFile.
+func createIRFile(path string, index bool) (ir.IRWriter, error) {
+	if index {
+		return createIndexedIRFile(path)
+	}
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".ndjson.gz"):
+		w, err := ir.NewGzipNDJSONFileWriter(path)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	case strings.HasSuffix(lower, ".ndjson"):
+		w, err := ir.NewNDJSONFileWriter(path)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	default:
+		w, err := ir.NewBatchFileWriter(path)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+}
+
+// discardIRFile closes a partially written output file created by
+// createIRFile and removes it along with any sidecar index.
+func discardIRFile(w ir.IRWriter, path string) {
+	_ = w.Close()
+	_ = os.Remove(path)
+	_ = os.Remove(ir.IndexPath(path))
+}
+
+// createIndexedIRFile creates a streaming NDJSON writer for path, gzip
+// compressed for .gz paths, that writes a sidecar record ID index on Close.
+func createIndexedIRFile(path string) (ir.IRWriter, error) {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".ndjson.gz"):
+		w, err := ir.NewIndexedGzipNDJSONFileWriter(path)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	case strings.HasSuffix(lower, ".ndjson"):
+		w, err := ir.NewIndexedNDJSONFileWriter(path)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	default:
+		return nil, fmt.Errorf("--index requires .ndjson or .ndjson.gz output: %s", path)
+	}
 }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/curl"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+var curlCmd = &cobra.Command{
+	Use:   "curl",
+	Short: "Convert curl commands to IR format",
+	Long: `Convert curl command lines to Intermediate Representation (IR) format.
+
+Commands are read one per line from a file or stdin. Lines ending in a
+backslash continue on the next line (as produced by "Copy as cURL" in
+browser DevTools), and blank lines and # comments are skipped.
+
+Supported curl flags include -X, -H, -d/--data*, --data-urlencode, --json,
+-F, -G, -I, -u, -A, -e, -b and --url, also combined (-sSL, -sXPOST). Other
+flags are ignored. Data arguments naming a file (-d @body.json) are read
+from the file, relative to --data-dir.
+
+By default records get a placeholder 200 response, marked with
+meta.responsePlaceholder so that generate documents the request but not the
+response. Use --execute to send each request and record the live response
+instead.
+
+Examples:
+  # Convert a file of curl commands
+  traffic2openapi convert curl -i commands.txt -o traffic.ndjson
+
+  # Read commands from stdin
+  pbpaste | traffic2openapi convert curl -o traffic.ndjson
+
+  # Execute the requests and capture real responses
+  traffic2openapi convert curl -i commands.txt -o traffic.ndjson --execute`,
+	RunE: runCurlConvert,
+}
+
+var (
+	// curl flags
+	curlInputPath      string
+	curlOutputPath     string
+	curlIncludeHeaders bool
+	curlFilterHeaders  string
+	curlExecute        bool
+	curlDataDir        string
+)
+
+func init() {
+	convertCmd.AddCommand(curlCmd)
+
+	// Input/output flags
+	curlCmd.Flags().StringVarP(&curlInputPath, "input", "i", "", "Input file of curl commands (default: stdin)")
+	curlCmd.Flags().StringVarP(&curlOutputPath, "output", "o", "", "Output file path (default: stdout)")
+
+	// Conversion flags
+	curlCmd.Flags().BoolVar(&curlIncludeHeaders, "headers", true, "Include HTTP headers in output")
+	curlCmd.Flags().StringVar(&curlFilterHeaders, "filter-headers", "", "Additional headers to filter (comma-separated)")
+	curlCmd.Flags().BoolVar(&curlExecute, "execute", false, "Send each request and record the live response")
+	curlCmd.Flags().StringVar(&curlDataDir, "data-dir", "", "Directory @file data arguments are read from (default: current directory)")
+}
+
+func runCurlConvert(cmd *cobra.Command, args []string) error {
+	reader := curl.NewReader()
+	reader.Converter.IncludeHeaders = curlIncludeHeaders
+	reader.Converter.Execute = curlExecute
+	reader.Converter.DataDir = curlDataDir
+
+	metadata, err := convertMetadata()
+	if err != nil {
+		return err
+	}
+	reader.Converter.Metadata = metadata
+
+	if curlFilterHeaders != "" {
+		for _, h := range strings.Split(curlFilterHeaders, ",") {
+			h = strings.TrimSpace(h)
+			if h != "" {
+				reader.Converter.FilterHeaders = append(reader.Converter.FilterHeaders, h)
+			}
+		}
+	}
+
+	var records []ir.IRRecord
+	if curlInputPath == "" || curlInputPath == "-" {
+		cmd.Printf("Reading curl commands from stdin\n")
+		records, err = reader.Read(os.Stdin)
+	} else {
+		cmd.Printf("Reading curl commands: %s\n", curlInputPath)
+		records, err = reader.ReadFile(curlInputPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		cmd.Printf("No records found\n")
+		return nil
+	}
+
+	cmd.Printf("Converted %d records\n", len(records))
+
+	// Write output
+	if curlOutputPath == "" {
+		return ir.WriteNDJSON(os.Stdout, records)
+	}
+
+	if err := writeIRFile(curlOutputPath, records, convertIndex); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	cmd.Printf("Wrote IR records to %s\n", curlOutputPath)
+	return nil
+}
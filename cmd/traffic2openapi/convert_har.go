@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/grokify/traffic2openapi/pkg/har"
@@ -31,6 +32,13 @@ Examples:
   # Convert and filter specific hosts
   traffic2openapi convert har -i recording.har -o traffic.ndjson --host api.example.com
 
+  # Convert subdomains of example.com, skipping health checks
+  traffic2openapi convert har -i recording.har -o traffic.ndjson \
+    --allow-host '*.example.com' --skip-path /health
+
+  # Convert only API traffic (skip HTML, scripts, stylesheets, images, fonts)
+  traffic2openapi convert har -i recording.har -o traffic.ndjson --api-only
+
   # Convert without headers
   traffic2openapi convert har -i recording.har -o traffic.ndjson --no-headers
 
@@ -49,6 +57,11 @@ var (
 	harFilterHost     string
 	harFilterMethod   string
 	harIncludeCookies bool
+	harAPIOnly        bool
+	harIncludeBinary  bool
+	harAllowHosts     []string
+	harSkipHosts      []string
+	harSkipPaths      []string
 )
 
 func init() {
@@ -62,8 +75,13 @@ func init() {
 	harCmd.Flags().BoolVar(&harIncludeHeaders, "headers", true, "Include HTTP headers in output")
 	harCmd.Flags().StringVar(&harFilterHeaders, "filter-headers", "", "Additional headers to filter (comma-separated)")
 	harCmd.Flags().StringVar(&harFilterHost, "host", "", "Only include requests to this host")
+	harCmd.Flags().StringArrayVar(&harAllowHosts, "allow-host", nil, "Only include hosts matching this pattern: name, *.wildcard, re:regex, or CIDR (can be repeated)")
+	harCmd.Flags().StringArrayVar(&harSkipHosts, "skip-host", nil, "Skip hosts matching this pattern (can be repeated)")
+	harCmd.Flags().StringArrayVar(&harSkipPaths, "skip-path", nil, "Skip paths with this prefix, or matching a *wildcard or re:regex, optionally for some methods ('GET /metrics') (can be repeated)")
 	harCmd.Flags().StringVar(&harFilterMethod, "method", "", "Only include requests with this method (GET, POST, etc.)")
 	harCmd.Flags().BoolVar(&harIncludeCookies, "cookies", false, "Include cookie headers in output")
+	harCmd.Flags().BoolVar(&harAPIOnly, "api-only", false, "Skip static assets and page loads (HTML, JS, CSS, images, fonts)")
+	harCmd.Flags().BoolVar(&harIncludeBinary, "include-binary", false, "Keep binary bodies (images, PDFs, octet-stream) base64-encoded")
 
 	_ = harCmd.MarkFlagRequired("input")
 }
@@ -77,6 +95,18 @@ func runHARConvert(cmd *cobra.Command, args []string) error {
 	reader := har.NewReader()
 	configureHARConverter(reader.Converter)
 
+	filter, err := ir.NewRequestMatcher(harAllowHosts, harSkipHosts, harSkipPaths)
+	if err != nil {
+		return err
+	}
+	reader.Converter.Filter = filter
+
+	metadata, err := convertMetadata()
+	if err != nil {
+		return err
+	}
+	reader.Converter.Metadata = metadata
+
 	// Check if input is file or directory
 	info, err := os.Stat(harInputPath)
 	if err != nil {
@@ -90,7 +120,7 @@ func runHARConvert(cmd *cobra.Command, args []string) error {
 		records, err = reader.ReadDir(harInputPath)
 	} else {
 		cmd.Printf("Reading HAR file: %s\n", harInputPath)
-		records, err = reader.ReadFile(harInputPath)
+		records, err = readHARFileProgress(cmd, reader, harInputPath, info.Size())
 	}
 
 	if err != nil {
@@ -112,7 +142,7 @@ func runHARConvert(cmd *cobra.Command, args []string) error {
 		return ir.WriteNDJSON(os.Stdout, records)
 	}
 
-	if err := ir.WriteFile(harOutputPath, records); err != nil {
+	if err := writeIRFile(harOutputPath, records, convertIndex); err != nil {
 		return fmt.Errorf("writing output: %w", err)
 	}
 
@@ -120,9 +150,29 @@ func runHARConvert(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// readHARFileProgress reads a HAR file, reporting read progress for large files.
+func readHARFileProgress(cmd *cobra.Command, reader *har.Reader, path string, size int64) ([]ir.IRRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+
+	p := newProgress(cmd, "Reading "+filepath.Base(path), progressBytes, size)
+	records, err := reader.Read(&progressReader{r: f, progress: p})
+	p.Done()
+	if err != nil {
+		return nil, err
+	}
+	ir.ApplyMetadata(records, map[string]any{ir.MetaSourceFile: filepath.Base(path)})
+	return records, nil
+}
+
 func configureHARConverter(converter *har.Converter) {
 	converter.IncludeHeaders = harIncludeHeaders
 	converter.IncludeCookies = harIncludeCookies
+	converter.APIOnly = harAPIOnly
+	converter.IncludeBinaryBodies = harIncludeBinary
 
 	if harFilterHeaders != "" {
 		additional := strings.Split(harFilterHeaders, ",")
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var openapiConvertCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Synthesize IR records from an OpenAPI spec",
+	Long: `Synthesize Intermediate Representation (IR) records from an existing OpenAPI spec.
+
+One record is generated per operation, using spec examples where available
+and schema-derived values otherwise. Use this when no live capture exists
+yet, to drive the site, diff, and coverage tooling, or to merge documented
+but unobserved endpoints with real traffic.
+
+Examples:
+  # Synthesize one record per operation
+  traffic2openapi convert openapi -i openapi.yaml -o synthetic.ndjson
+
+  # One record per documented response status code
+  traffic2openapi convert openapi -i openapi.yaml -o synthetic.ndjson --all-responses
+
+  # Blend documented endpoints with captured traffic
+  traffic2openapi convert openapi -i openapi.yaml -o synthetic.ndjson
+  traffic2openapi merge -i synthetic.ndjson -i traffic.ndjson -o combined.ndjson`,
+	RunE: runOpenAPIConvert,
+}
+
+var (
+	// OpenAPI synthesis flags
+	synthInputPath       string
+	synthOutputPath      string
+	synthAllResponses    bool
+	synthIncludeOptional bool
+	synthServerURL       string
+)
+
+func init() {
+	convertCmd.AddCommand(openapiConvertCmd)
+
+	// Input/output flags
+	openapiConvertCmd.Flags().StringVarP(&synthInputPath, "input", "i", "", "Input OpenAPI spec file (required)")
+	openapiConvertCmd.Flags().StringVarP(&synthOutputPath, "output", "o", "", "Output file path (default: stdout)")
+
+	// Synthesis flags
+	openapiConvertCmd.Flags().BoolVar(&synthAllResponses, "all-responses", false, "Generate one record per documented response status")
+	openapiConvertCmd.Flags().BoolVar(&synthIncludeOptional, "include-optional", false, "Include optional parameters without examples")
+	openapiConvertCmd.Flags().StringVar(&synthServerURL, "server", "", "Server URL override (default: first server in spec)")
+
+	_ = openapiConvertCmd.MarkFlagRequired("input")
+}
+
+func runOpenAPIConvert(cmd *cobra.Command, args []string) error {
+	cmd.Printf("Reading OpenAPI spec: %s\n", synthInputPath)
+	spec, err := openapi.ReadFile(synthInputPath)
+	if err != nil {
+		return fmt.Errorf("reading spec: %w", err)
+	}
+
+	options := openapi.DefaultSynthesizeOptions()
+	options.AllResponses = synthAllResponses
+	options.IncludeOptional = synthIncludeOptional
+	options.ServerURL = synthServerURL
+
+	records, err := openapi.Synthesize(spec, options)
+	if err != nil {
+		return fmt.Errorf("synthesizing records: %w", err)
+	}
+
+	if len(records) == 0 {
+		cmd.Printf("No operations found\n")
+		return nil
+	}
+
+	cmd.Printf("Synthesized %d records\n", len(records))
+
+	// Write output
+	if synthOutputPath == "" {
+		return ir.WriteNDJSON(os.Stdout, records)
+	}
+
+	if err := writeIRFile(synthOutputPath, records, convertIndex); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	cmd.Printf("Wrote IR records to %s\n", synthOutputPath)
+	return nil
+}
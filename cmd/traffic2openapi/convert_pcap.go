@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/pcap"
+	"github.com/spf13/cobra"
+)
+
+var pcapCmd = &cobra.Command{
+	Use:   "pcap",
+	Short: "Convert packet captures to IR format",
+	Long: `Convert pcap and pcapng packet captures of HTTP/1.x traffic to
+Intermediate Representation (IR) format.
+
+HTTPS sessions are decrypted with the TLS secrets in an SSLKEYLOGFILE
+(--keylog), so traffic can be captured with tcpdump or an eBPF keylog tool
+without modifying application code or trusting a proxy CA. Secrets embedded
+in pcapng files (editcap --inject-secrets) are used as well.
+
+TLS 1.2 and 1.3 sessions using AES-GCM cipher suites are supported.
+HTTP/2 is not decoded; capture HTTP/1.1 clients (e.g. curl --http1.1).
+
+Examples:
+  # Capture traffic while logging TLS secrets
+  tcpdump -i any -w capture.pcap port 443 &
+  SSLKEYLOGFILE=keys.log curl --http1.1 https://api.example.com/users
+
+  # Convert the capture to IR
+  traffic2openapi convert pcap -i capture.pcap --keylog keys.log -o traffic.ndjson
+
+  # Convert only one API's traffic
+  traffic2openapi convert pcap -i capture.pcapng --keylog keys.log -o traffic.ndjson \
+    --allow-host api.example.com --api-only`,
+	RunE: runPcapConvert,
+}
+
+var (
+	// pcap flags
+	pcapInputPath      string
+	pcapOutputPath     string
+	pcapKeyLogPath     string
+	pcapIncludeHeaders bool
+	pcapFilterHeaders  string
+	pcapAPIOnly        bool
+	pcapIncludeBinary  bool
+	pcapAllowHosts     []string
+	pcapSkipHosts      []string
+	pcapSkipPaths      []string
+)
+
+func init() {
+	convertCmd.AddCommand(pcapCmd)
+
+	// Input/output flags
+	pcapCmd.Flags().StringVarP(&pcapInputPath, "input", "i", "", "Input pcap or pcapng file (required)")
+	pcapCmd.Flags().StringVarP(&pcapOutputPath, "output", "o", "", "Output file path (default: stdout)")
+	pcapCmd.Flags().StringVar(&pcapKeyLogPath, "keylog", "", "TLS key log file (SSLKEYLOGFILE) for decrypting HTTPS")
+
+	// Filter flags
+	pcapCmd.Flags().BoolVar(&pcapIncludeHeaders, "headers", true, "Include HTTP headers in output")
+	pcapCmd.Flags().StringVar(&pcapFilterHeaders, "filter-headers", "", "Additional headers to filter (comma-separated)")
+	pcapCmd.Flags().StringArrayVar(&pcapAllowHosts, "allow-host", nil, "Only include hosts matching this pattern: name, *.wildcard, re:regex, or CIDR (can be repeated)")
+	pcapCmd.Flags().StringArrayVar(&pcapSkipHosts, "skip-host", nil, "Skip hosts matching this pattern (can be repeated)")
+	pcapCmd.Flags().StringArrayVar(&pcapSkipPaths, "skip-path", nil, "Skip paths with this prefix, or matching a *wildcard or re:regex, optionally for some methods ('GET /metrics') (can be repeated)")
+	pcapCmd.Flags().BoolVar(&pcapAPIOnly, "api-only", false, "Skip static assets and page loads (HTML, JS, CSS, images, fonts)")
+	pcapCmd.Flags().BoolVar(&pcapIncludeBinary, "include-binary", false, "Keep binary bodies (images, PDFs, octet-stream) base64-encoded")
+
+	_ = pcapCmd.MarkFlagRequired("input")
+}
+
+func runPcapConvert(cmd *cobra.Command, args []string) error {
+	converter := pcap.NewConverter()
+	converter.IncludeHeaders = pcapIncludeHeaders
+	converter.APIOnly = pcapAPIOnly
+	converter.IncludeBinaryBodies = pcapIncludeBinary
+	for _, h := range strings.Split(pcapFilterHeaders, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			converter.FilterHeaders = append(converter.FilterHeaders, h)
+		}
+	}
+
+	filter, err := ir.NewRequestMatcher(pcapAllowHosts, pcapSkipHosts, pcapSkipPaths)
+	if err != nil {
+		return err
+	}
+	converter.Filter = filter
+
+	metadata, err := convertMetadata()
+	if err != nil {
+		return err
+	}
+	converter.Metadata = metadata
+
+	if pcapKeyLogPath != "" {
+		keys, err := pcap.ReadKeyLogFile(pcapKeyLogPath)
+		if err != nil {
+			return err
+		}
+		cmd.Printf("Loaded TLS secrets for %d sessions\n", keys.Len())
+		converter.KeyLog = keys
+	}
+
+	cmd.Printf("Reading capture: %s\n", pcapInputPath)
+	result, err := converter.ConvertFile(pcapInputPath)
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Found %d TCP connections\n", result.Connections)
+	if result.TLSSessions > 0 {
+		cmd.Printf("Decrypted %d of %d TLS sessions\n", result.DecryptedSessions, result.TLSSessions)
+	}
+
+	if len(result.Records) == 0 {
+		cmd.Printf("No records found\n")
+		return nil
+	}
+
+	cmd.Printf("Converted %d records\n", len(result.Records))
+
+	// Write output
+	if pcapOutputPath == "" {
+		return ir.WriteNDJSON(os.Stdout, result.Records)
+	}
+
+	if err := writeIRFile(pcapOutputPath, result.Records, convertIndex); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	cmd.Printf("Wrote IR records to %s\n", pcapOutputPath)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/grokify/traffic2openapi/pkg/ir"
@@ -96,6 +97,12 @@ func runPostmanConvert(cmd *cobra.Command, args []string) error {
 	converter := postman.NewConverter()
 	configurePostmanConverter(converter)
 
+	metadata, err := convertMetadata()
+	if err != nil {
+		return err
+	}
+	converter.Metadata = metadata
+
 	// Convert
 	result, err := converter.Convert(collection)
 	if err != nil {
@@ -103,6 +110,7 @@ func runPostmanConvert(cmd *cobra.Command, args []string) error {
 	}
 
 	records := result.Records
+	ir.ApplyMetadata(records, map[string]any{ir.MetaSourceFile: filepath.Base(postmanInputPath)})
 
 	// Apply post-conversion filters
 	records = filterPostmanRecords(records)
@@ -146,6 +154,9 @@ func runPostmanConvert(cmd *cobra.Command, args []string) error {
 
 	// Write to file
 	if postmanOutputFormat == "batch" {
+		if convertIndex {
+			return fmt.Errorf("--index requires ndjson format")
+		}
 		batch := ir.NewBatchWithMetadata(records, result.Metadata)
 		data, err := json.MarshalIndent(batch, "", "  ")
 		if err != nil {
@@ -155,7 +166,7 @@ func runPostmanConvert(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("writing output: %w", err)
 		}
 	} else {
-		if err := ir.WriteFile(postmanOutputPath, records); err != nil {
+		if err := writeIRFile(postmanOutputPath, records, convertIndex); err != nil {
 			return fmt.Errorf("writing output: %w", err)
 		}
 	}
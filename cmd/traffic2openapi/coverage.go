@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/cireport"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "Report how much of an OpenAPI spec traffic exercises",
+	Long: `Compare IR traffic against an OpenAPI specification.
+
+Records are matched to spec operations by method and path template (with
+server base paths removed). The report lists operations never observed and
+status codes observed for an operation that the spec doesn't declare, even
+by range (2XX) or default, with counts and example record IDs. With
+--fail-on, the command exits with status 1 (2 with --detailed-exit-codes)
+when it finds undocumented status codes or, for uncovered, also uncovered
+operations.
+
+The spec may also be a RAML (.raml) or API Blueprint (.apib) document, which
+is converted to OpenAPI first.
+
+Examples:
+  # Report coverage
+  traffic2openapi coverage --spec api.yaml -i traffic.ndjson
+
+  # Output as JSON for CI/CD
+  traffic2openapi coverage --spec api.yaml -i ./logs/ --format json
+
+  # Check traffic against a legacy API Blueprint document
+  traffic2openapi coverage --spec api.apib -i traffic.ndjson
+
+  # JUnit XML with one test case per operation
+  traffic2openapi coverage --spec api.yaml -i ./logs/ --format junit > coverage.xml
+
+  # Fail the build on undocumented status codes
+  traffic2openapi coverage --spec api.yaml -i traffic.ndjson --fail-on undocumented
+
+  # Declare undocumented status codes and write the patched spec
+  traffic2openapi coverage --spec api.yaml -i traffic.ndjson --patch api.patched.yaml`,
+	RunE: runCoverage,
+}
+
+var (
+	coverageSpecPath  string
+	coverageInputPath string
+	coverageFormat    string
+	coveragePatchPath string
+	coverageFailOn    string
+)
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+
+	coverageCmd.Flags().StringVar(&coverageSpecPath, "spec", "", "OpenAPI spec, or RAML or API Blueprint document, to check (required)")
+	coverageCmd.Flags().StringVarP(&coverageInputPath, "input", "i", "", "Input file or directory containing IR files (required)")
+	coverageCmd.Flags().StringVarP(&coverageFormat, "format", "f", "text", "Output format: text, json, junit, or sarif")
+	coverageCmd.Flags().StringVar(&coverageFailOn, "fail-on", "none", "Exit non-zero on: none, undocumented (status codes), or uncovered (operations or status codes)")
+	coverageCmd.Flags().StringVar(&coveragePatchPath, "patch", "", "Write the spec with undocumented status codes added as responses to this file")
+
+	_ = coverageCmd.MarkFlagRequired("spec")
+	_ = coverageCmd.MarkFlagRequired("input")
+}
+
+func runCoverage(cmd *cobra.Command, args []string) error {
+	if coverageFormat != "text" && coverageFormat != "json" && !cireport.IsFormat(coverageFormat) {
+		return inputError(fmt.Errorf("unsupported format: %s (use text, json, junit, or sarif)", coverageFormat))
+	}
+	switch coverageFailOn {
+	case "none", "undocumented", "uncovered":
+	default:
+		return inputError(fmt.Errorf("unsupported --fail-on: %s (use none, undocumented, or uncovered)", coverageFailOn))
+	}
+
+	spec, err := readSpec(coverageSpecPath)
+	if err != nil {
+		return inputError(fmt.Errorf("reading spec: %w", err))
+	}
+
+	records, err := readIRInput(cmd, coverageInputPath)
+	if err != nil {
+		return fmt.Errorf("reading IR files: %w", err)
+	}
+
+	report := openapi.Coverage(spec, records)
+
+	switch coverageFormat {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	case cireport.FormatJUnit, cireport.FormatSARIF:
+		if err := coverageReport(report, coverageSpecPath).Write(os.Stdout, coverageFormat); err != nil {
+			return err
+		}
+	default:
+		outputCoverageText(cmd, report)
+	}
+
+	if coveragePatchPath != "" {
+		added := openapi.AddUndocumentedResponses(spec, report)
+		if err := openapi.WriteFile(coveragePatchPath, spec); err != nil {
+			return fmt.Errorf("writing patched spec: %w", err)
+		}
+		cmd.Printf("Added %d responses; wrote patched spec to %s\n", added, coveragePatchPath)
+	}
+
+	switch {
+	case coverageFailOn != "none" && len(report.UndocumentedStatuses) > 0:
+		return withExitCode(exitDifferences, nil)
+	case coverageFailOn == "uncovered" && len(report.Uncovered()) > 0:
+		return withExitCode(exitDifferences, nil)
+	}
+	return nil
+}
+
+// coverageReport converts coverage to JUnit/SARIF checks, one per spec
+// operation. Uncovered operations are notes and undocumented status codes
+// warnings.
+func coverageReport(report *openapi.CoverageReport, specPath string) *cireport.Report {
+	r := cireport.NewReport("traffic2openapi coverage", version)
+	r.Rule("uncovered-operation", "Spec operation not exercised by traffic")
+	r.Rule("undocumented-status", "Observed status code the operation declares no response for")
+
+	undocumented := make(map[string][]openapi.UndocumentedStatus)
+	for _, s := range report.UndocumentedStatuses {
+		key := s.Method + " " + s.Path
+		undocumented[key] = append(undocumented[key], s)
+	}
+
+	for _, op := range report.Operations {
+		name := op.Method + " " + op.Path
+		if op.Requests == 0 {
+			r.Add(cireport.Check{
+				Name:    name,
+				Group:   "coverage",
+				RuleID:  "uncovered-operation",
+				Level:   cireport.LevelNote,
+				Message: name + " is not exercised by traffic",
+				File:    specPath,
+			})
+			continue
+		}
+		statuses := undocumented[name]
+		if len(statuses) == 0 {
+			r.Add(cireport.Check{Name: name, Group: "coverage", File: specPath})
+			continue
+		}
+		for _, s := range statuses {
+			message := fmt.Sprintf("%s returned undocumented status %d (%d records)", name, s.Status, s.Count)
+			if len(s.RecordIDs) > 0 {
+				message += " e.g. " + strings.Join(s.RecordIDs, ", ")
+			}
+			r.Add(cireport.Check{
+				Name:    name,
+				Group:   "coverage",
+				RuleID:  "undocumented-status",
+				Level:   cireport.LevelWarning,
+				Message: message,
+				File:    specPath,
+			})
+		}
+	}
+	return r
+}
+
+func outputCoverageText(cmd *cobra.Command, report *openapi.CoverageReport) {
+	cmd.Printf("Coverage: %d/%d operations (%.1f%%)\n", report.Covered, report.Total, report.Percent())
+
+	if uncovered := report.Uncovered(); len(uncovered) > 0 {
+		cmd.Println("\n- Uncovered Operations:")
+		for _, op := range uncovered {
+			cmd.Printf("  - %s %s\n", op.Method, op.Path)
+		}
+	}
+
+	if len(report.UndocumentedStatuses) > 0 {
+		cmd.Println("\n⚠️  Undocumented Status Codes:")
+		for _, s := range report.UndocumentedStatuses {
+			line := fmt.Sprintf("  ⚠️  %s %s: %d (%d records)", s.Method, s.Path, s.Status, s.Count)
+			if len(s.RecordIDs) > 0 {
+				line += " e.g. " + strings.Join(s.RecordIDs, ", ")
+			}
+			cmd.Println(line)
+		}
+	}
+
+	if report.UnmatchedRecords > 0 {
+		cmd.Printf("\n%d records matched no operation in the spec\n", report.UnmatchedRecords)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/sitegen"
+	"github.com/spf13/cobra"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Reduce an IR capture to representative records",
+	Long: `Reduce an IR capture to at most K representative records per
+(endpoint, status, response structure) group.
+
+Records are grouped using the same dedup key as the site generator: method,
+path template, status code, and a fingerprint of the response body structure.
+The first K records of each group are kept in capture order, so the output
+preserves schema coverage while being much smaller to archive for later spec
+regeneration.
+
+NDJSON (.ndjson) and gzip-compressed NDJSON (.ndjson.gz) inputs are streamed
+record by record. Output format is determined by extension (.ndjson, .gz, or
+.json); without --output, NDJSON is written to stdout.
+
+Examples:
+  # Keep 3 records per group (default)
+  traffic2openapi dedupe -i traffic.ndjson -o traffic.min.ndjson
+
+  # Keep a single record per group and compress the output
+  traffic2openapi dedupe -i traffic.ndjson.gz -o traffic.min.ndjson.gz -k 1
+
+  # Deduplicate a directory of IR files to stdout
+  traffic2openapi dedupe -i ./logs/ > traffic.min.ndjson
+
+  # Keep feature-flag variants apart and ignore a cache-busting query key
+  traffic2openapi dedupe -i traffic.ndjson -o traffic.min.ndjson \
+    --key-header X-Feature-Flags --exclude-query _`,
+	RunE: runDedupe,
+}
+
+var (
+	dedupeInputPath  string
+	dedupeOutputPath string
+	dedupePerGroup   int
+	dedupeIndex      bool
+	dedupeKeyOptions sitegen.DedupOptions
+)
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+
+	dedupeCmd.Flags().StringVarP(&dedupeInputPath, "input", "i", "", "Input IR file or directory (required)")
+	dedupeCmd.Flags().StringVarP(&dedupeOutputPath, "output", "o", "", "Output IR file path (default: stdout)")
+	dedupeCmd.Flags().IntVarP(&dedupePerGroup, "per-group", "k", 3, "Maximum records kept per group")
+	dedupeCmd.Flags().BoolVar(&dedupeIndex, "index", false, "Write a sidecar record ID index next to NDJSON output (used by show)")
+	addDedupKeyFlags(dedupeCmd, &dedupeKeyOptions)
+
+	_ = dedupeCmd.MarkFlagRequired("input")
+}
+
+func runDedupe(cmd *cobra.Command, args []string) error {
+	if dedupePerGroup < 1 {
+		return inputError(fmt.Errorf("invalid --per-group: %d (must be at least 1)", dedupePerGroup))
+	}
+
+	reader, err := openDedupeInput(dedupeInputPath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	deduper := sitegen.NewDeduper(dedupePerGroup)
+	deduper.Options = dedupeKeyOptions
+
+	// Batch JSON output needs all records up front
+	if strings.HasSuffix(strings.ToLower(dedupeOutputPath), ".json") {
+		var kept []ir.IRRecord
+		if err := readDedupe(reader, deduper, func(record *ir.IRRecord) error {
+			kept = append(kept, *record)
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := writeIRFile(dedupeOutputPath, kept, dedupeIndex); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+		cmd.Printf("Kept %d of %d records (%d groups) in %s\n",
+			deduper.Kept(), deduper.Seen(), deduper.Groups(), dedupeOutputPath)
+		return nil
+	}
+
+	writer, err := openDedupeOutput(dedupeOutputPath)
+	if err != nil {
+		return err
+	}
+
+	if err := readDedupe(reader, deduper, writer.Write); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	if dedupeOutputPath != "" {
+		cmd.Printf("Kept %d of %d records (%d groups) in %s\n",
+			deduper.Kept(), deduper.Seen(), deduper.Groups(), dedupeOutputPath)
+	} else {
+		cmd.Printf("Kept %d of %d records (%d groups)\n",
+			deduper.Kept(), deduper.Seen(), deduper.Groups())
+	}
+	return nil
+}
+
+// addDedupKeyFlags registers the flags configuring the dedup key.
+func addDedupKeyFlags(cmd *cobra.Command, opts *sitegen.DedupOptions) {
+	cmd.Flags().StringSliceVar(&opts.IncludeQueryKeys, "include-query", nil, "Only consider these query keys in the dedup key (comma-separated)")
+	cmd.Flags().StringSliceVar(&opts.ExcludeQueryKeys, "exclude-query", nil, "Ignore these query keys in the dedup key (comma-separated)")
+	cmd.Flags().BoolVar(&opts.RequestHeaderStructure, "header-structure", false, "Include request header names in the dedup key")
+	cmd.Flags().StringSliceVar(&opts.RequestHeaderValues, "key-header", nil, "Include this request header's value in the dedup key (can be repeated)")
+}
+
+// readDedupe passes each record kept by the deduper to emit.
+func readDedupe(reader ir.IRReader, deduper *sitegen.Deduper, emit func(*ir.IRRecord) error) error {
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+		if !deduper.Keep(record) {
+			continue
+		}
+		if err := emit(record); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
+}
+
+// openDedupeInput returns a streaming reader for NDJSON inputs and a slice
+// reader for directories and batch JSON files.
+func openDedupeInput(path string) (ir.IRReader, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("input path error: %w", err)
+	}
+
+	lower := strings.ToLower(path)
+	switch {
+	case info.IsDir():
+		records, err := ir.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading input: %w", err)
+		}
+		return ir.NewSliceReader(records), nil
+	case strings.HasSuffix(lower, ".gz"):
+		reader, err := ir.NewGzipNDJSONFileReader(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening input: %w", err)
+		}
+		return reader, nil
+	case strings.HasSuffix(lower, ".ndjson"):
+		reader, err := ir.NewNDJSONFileReader(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening input: %w", err)
+		}
+		return reader, nil
+	default:
+		records, err := ir.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading input: %w", err)
+		}
+		return ir.NewSliceReader(records), nil
+	}
+}
+
+// openDedupeOutput returns an NDJSON writer for the output path, gzip
+// compressed for .gz paths and indexed with --index, or writing to stdout
+// when path is empty.
+func openDedupeOutput(path string) (ir.IRWriter, error) {
+	if path == "" {
+		return ir.NewNDJSONWriter(os.Stdout), nil
+	}
+	if dedupeIndex {
+		writer, err := createIndexedIRFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("creating output: %w", err)
+		}
+		return writer, nil
+	}
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		writer, err := ir.NewGzipNDJSONFileWriter(path)
+		if err != nil {
+			return nil, fmt.Errorf("creating output: %w", err)
+		}
+		return writer, nil
+	}
+	writer, err := ir.NewNDJSONFileWriter(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating output: %w", err)
+	}
+	return writer, nil
+}
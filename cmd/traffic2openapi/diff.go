@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/grokify/traffic2openapi/pkg/cireport"
 	"github.com/grokify/traffic2openapi/pkg/openapi"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +20,9 @@ var diffCmd = &cobra.Command{
 Detects added, removed, and modified endpoints, parameters, and schemas.
 Can identify breaking changes for API versioning.
 
+RAML (.raml) and API Blueprint (.apib) documents are converted to OpenAPI
+first, so a legacy definition can be compared with a generated spec.
+
 Examples:
   # Compare two specs
   traffic2openapi diff old.yaml new.yaml
@@ -26,11 +30,23 @@ Examples:
   # Output as JSON for CI/CD
   traffic2openapi diff old.yaml new.yaml --format json
 
+  # Compare a legacy RAML definition with the spec generated from traffic
+  traffic2openapi diff legacy.raml openapi.yaml
+
+  # SARIF for code scanning, with breaking changes as errors
+  traffic2openapi diff old.yaml new.yaml --format sarif > diff.sarif
+
   # Only show breaking changes
   traffic2openapi diff old.yaml new.yaml --breaking-only
 
-  # Exit with non-zero code if breaking changes found (for CI)
-  traffic2openapi diff old.yaml new.yaml --breaking-only --exit-code`,
+  # Exit with status 1 if breaking changes found (for CI)
+  traffic2openapi diff old.yaml new.yaml --fail-on breaking
+
+  # Exit with status 2 for any difference, 3 if any are breaking
+  traffic2openapi diff old.yaml new.yaml --fail-on changes --detailed-exit-codes
+
+  # Post differences to a Slack channel
+  traffic2openapi diff old.yaml new.yaml --notify "$SLACK_WEBHOOK_URL" --notify-format slack`,
 	Args: cobra.ExactArgs(2),
 	RunE: runDiff,
 }
@@ -39,14 +55,18 @@ var (
 	diffFormat       string
 	diffBreakingOnly bool
 	diffExitCode     bool
+	diffFailOn       string
 )
 
 func init() {
 	rootCmd.AddCommand(diffCmd)
 
-	diffCmd.Flags().StringVarP(&diffFormat, "format", "f", "text", "Output format: text or json")
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "f", "text", "Output format: text, json, junit, or sarif")
 	diffCmd.Flags().BoolVar(&diffBreakingOnly, "breaking-only", false, "Only show breaking changes")
-	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "Exit with non-zero code if differences found")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "Exit with non-zero code if differences found (same as --fail-on changes)")
+	diffCmd.Flags().StringVar(&diffFailOn, "fail-on", "none", "Exit non-zero on: none, changes, or breaking (see --detailed-exit-codes)")
+	addResolveRefsFlag(diffCmd)
+	addNotifyFlags(diffCmd)
 }
 
 // DiffResult holds the comparison results.
@@ -81,15 +101,28 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	oldPath := args[0]
 	newPath := args[1]
 
+	failOn := diffFailOn
+	if diffExitCode && failOn == "none" {
+		failOn = "changes"
+	}
+	switch failOn {
+	case "none", "changes", "breaking":
+	default:
+		return inputError(fmt.Errorf("unsupported --fail-on: %s (use none, changes, or breaking)", failOn))
+	}
+	if err := checkNotifyFlags(); err != nil {
+		return err
+	}
+
 	// Read specs
-	oldSpec, err := openapi.ReadFile(oldPath)
+	oldSpec, err := readSpec(oldPath)
 	if err != nil {
-		return fmt.Errorf("reading old spec: %w", err)
+		return inputError(fmt.Errorf("reading old spec: %w", err))
 	}
 
-	newSpec, err := openapi.ReadFile(newPath)
+	newSpec, err := readSpec(newPath)
 	if err != nil {
-		return fmt.Errorf("reading new spec: %w", err)
+		return inputError(fmt.Errorf("reading new spec: %w", err))
 	}
 
 	// Compare specs
@@ -101,16 +134,40 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output results
-	if diffFormat == "json" {
-		return outputDiffJSON(result)
+	switch diffFormat {
+	case "json":
+		if err := outputDiffJSON(result); err != nil {
+			return err
+		}
+	case cireport.FormatJUnit, cireport.FormatSARIF:
+		if err := diffReport(result, args[1]).Write(os.Stdout, diffFormat); err != nil {
+			return err
+		}
+	default:
+		outputDiffText(cmd, result)
+	}
+
+	if hasChanges(result) {
+		notify(cmd, notification{
+			Command: "diff",
+			Title: fmt.Sprintf("API changes from %s to %s: %d added, %d removed, %d modified, %d breaking",
+				oldPath, newPath,
+				len(result.AddedPaths)+len(result.AddedOperations),
+				len(result.RemovedPaths)+len(result.RemovedOps),
+				len(result.ModifiedOps),
+				len(result.BreakingChanges)),
+			Breaking: len(result.BreakingChanges) > 0,
+			Findings: findingMessages(diffReport(result, newPath)),
+			Report:   result,
+		})
 	}
-	outputDiffText(cmd, result)
 
 	// Exit code handling
-	if diffExitCode {
-		if hasChanges(result) {
-			os.Exit(1)
-		}
+	switch {
+	case failOn != "none" && len(result.BreakingChanges) > 0:
+		return withExitCode(exitBreaking, nil)
+	case failOn == "changes" && hasChanges(result):
+		return withExitCode(exitDifferences, nil)
 	}
 
 	return nil
@@ -194,7 +251,7 @@ func comparePaths(result *DiffResult, path string, oldItem, newItem *openapi.Pat
 				Description: fmt.Sprintf("Operation %s %s was removed", m.name, path),
 			})
 		} else if m.oldOp != nil && m.newOp != nil {
-			diff := compareOperations(path, m.name, m.oldOp, m.newOp)
+			diff := compareOperations(path, m.name, oldItem, newItem, m.oldOp, m.newOp)
 			if diff != nil {
 				result.ModifiedOps = append(result.ModifiedOps, *diff)
 
@@ -212,7 +269,7 @@ func comparePaths(result *DiffResult, path string, oldItem, newItem *openapi.Pat
 	}
 }
 
-func compareOperations(path, method string, oldOp, newOp *openapi.Operation) *OpDiff {
+func compareOperations(path, method string, oldItem, newItem *openapi.PathItem, oldOp, newOp *openapi.Operation) *OpDiff {
 	diff := &OpDiff{
 		Path:   path,
 		Method: method,
@@ -224,10 +281,11 @@ func compareOperations(path, method string, oldOp, newOp *openapi.Operation) *Op
 	oldParams := make(map[string]bool)
 	newParams := make(map[string]bool)
 
-	for _, p := range oldOp.Parameters {
+	// Path-level parameters apply to every operation on the path
+	for _, p := range openapi.OperationParameters(oldItem, oldOp) {
 		oldParams[fmt.Sprintf("%s:%s", p.In, p.Name)] = true
 	}
-	for _, p := range newOp.Parameters {
+	for _, p := range openapi.OperationParameters(newItem, newOp) {
 		newParams[fmt.Sprintf("%s:%s", p.In, p.Name)] = true
 	}
 
@@ -294,6 +352,64 @@ func outputDiffJSON(result *DiffResult) error {
 	return nil
 }
 
+// diffReport converts a diff to JUnit/SARIF checks located in the new spec.
+// Breaking changes are errors; other changes are notes.
+func diffReport(result *DiffResult, specPath string) *cireport.Report {
+	report := cireport.NewReport("traffic2openapi diff", version)
+	report.Rule("path_removed", "Path removed (breaking)")
+	report.Rule("operation_removed", "Operation removed (breaking)")
+	report.Rule("parameter_removed", "Parameter removed (breaking)")
+	report.Rule("path_added", "Path added")
+	report.Rule("operation_added", "Operation added")
+	report.Rule("operation_modified", "Operation parameters or responses changed")
+
+	for _, bc := range result.BreakingChanges {
+		name := bc.Path
+		if bc.Method != "" {
+			name = bc.Method + " " + bc.Path
+		}
+		report.Add(cireport.Check{
+			Name:    name,
+			Group:   "breaking",
+			RuleID:  bc.Type,
+			Level:   cireport.LevelError,
+			Message: bc.Description,
+			File:    specPath,
+		})
+	}
+	for _, path := range result.AddedPaths {
+		report.Add(cireport.Check{Name: path, Group: "added", RuleID: "path_added", Level: cireport.LevelNote, Message: "Added path " + path, File: specPath})
+	}
+	for _, op := range result.AddedOperations {
+		report.Add(cireport.Check{Name: op, Group: "added", RuleID: "operation_added", Level: cireport.LevelNote, Message: "Added operation " + op, File: specPath})
+	}
+	for _, op := range result.ModifiedOps {
+		var changes []string
+		for _, p := range op.AddedParams {
+			changes = append(changes, "+param "+p)
+		}
+		for _, p := range op.RemovedParams {
+			changes = append(changes, "-param "+p)
+		}
+		for _, r := range op.AddedResponses {
+			changes = append(changes, "+response "+r)
+		}
+		for _, r := range op.RemovedResponses {
+			changes = append(changes, "-response "+r)
+		}
+		name := op.Method + " " + op.Path
+		report.Add(cireport.Check{
+			Name:    name,
+			Group:   "modified",
+			RuleID:  "operation_modified",
+			Level:   cireport.LevelNote,
+			Message: "Modified operation " + name + ": " + strings.Join(changes, ", "),
+			File:    specPath,
+		})
+	}
+	return report
+}
+
 func outputDiffText(cmd *cobra.Command, result *DiffResult) {
 	if !hasChanges(result) {
 		cmd.Println("No differences found.")
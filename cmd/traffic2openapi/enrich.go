@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var enrichCmd = &cobra.Command{
+	Use:   "enrich",
+	Short: "Add traffic examples to a hand-written OpenAPI spec",
+	Long: `Enrich a hand-written OpenAPI specification with details observed in
+traffic, without changing its structural contract.
+
+Only the following are added:
+  - examples for parameters, request bodies, and responses that have none
+  - observed response headers missing from documented responses
+
+Paths, operations, parameters, responses, and schemas are never added or
+removed. Body fields seen in traffic but not declared in the spec are
+reported as warnings, and with --observed-extensions recorded on the
+operation as x-observed-fields. Observed endpoints with no matching
+operation are reported but not added.
+
+With --overlay, the spec is left untouched and the additions are written as
+an OpenAPI Overlay document instead, to be applied with standard overlay
+tooling so the base spec stays pristine.
+
+Examples:
+  # Enrich a spec and write the result
+  traffic2openapi enrich --spec api.yaml -i traffic.ndjson -o api.enriched.yaml
+
+  # Only add examples, recording undeclared fields as extensions
+  traffic2openapi enrich --spec api.yaml -i ./logs/ -o api.yaml \
+    --response-headers=false --observed-extensions
+
+  # Write the additions as an overlay for the spec
+  traffic2openapi enrich --spec api.yaml -i traffic.ndjson --overlay -o traffic.overlay.yaml`,
+	RunE: runEnrich,
+}
+
+var (
+	enrichSpecPath           string
+	enrichInputPath          string
+	enrichOutputPath         string
+	enrichFormat             string
+	enrichExamples           bool
+	enrichResponseHeaders    bool
+	enrichObservedExtensions bool
+	enrichAPIOnly            bool
+	enrichOverlay            bool
+)
+
+func init() {
+	rootCmd.AddCommand(enrichCmd)
+
+	enrichCmd.Flags().StringVar(&enrichSpecPath, "spec", "", "OpenAPI spec to enrich (required)")
+	enrichCmd.Flags().StringVarP(&enrichInputPath, "input", "i", "", "Input file or directory containing IR files (required)")
+	enrichCmd.Flags().StringVarP(&enrichOutputPath, "output", "o", "", "Output file path (default: stdout)")
+	enrichCmd.Flags().StringVarP(&enrichFormat, "format", "f", "", "Output format: json or yaml (default: auto-detect from extension)")
+	enrichCmd.Flags().BoolVar(&enrichExamples, "examples", true, "Add observed examples where the spec has none")
+	enrichCmd.Flags().BoolVar(&enrichResponseHeaders, "response-headers", true, "Add observed response headers missing from the spec")
+	enrichCmd.Flags().BoolVar(&enrichObservedExtensions, "observed-extensions", false, "Record undeclared body fields as x-observed-fields operation extensions")
+	enrichCmd.Flags().BoolVar(&enrichAPIOnly, "api-only", false, "Skip static assets and page loads (HTML, JS, CSS, images, fonts)")
+	enrichCmd.Flags().BoolVar(&enrichOverlay, "overlay", false, "Write the additions as an OpenAPI Overlay document instead of the enriched spec")
+
+	_ = enrichCmd.MarkFlagRequired("spec")
+	_ = enrichCmd.MarkFlagRequired("input")
+}
+
+func runEnrich(cmd *cobra.Command, args []string) error {
+	format := enrichFormat
+	if format == "" {
+		format = "yaml"
+		if strings.ToLower(filepath.Ext(enrichOutputPath)) == ".json" {
+			format = "json"
+		}
+	}
+	if format != "json" && format != "yaml" {
+		return inputError(fmt.Errorf("unsupported format: %s (use json or yaml)", format))
+	}
+
+	spec, err := openapi.ReadFile(enrichSpecPath)
+	if err != nil {
+		return fmt.Errorf("reading spec: %w", err)
+	}
+
+	records, err := readIRInput(cmd, enrichInputPath)
+	if err != nil {
+		return fmt.Errorf("reading IR files: %w", err)
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("no records found in input")
+	}
+	cmd.Printf("Read %d IR records\n", len(records))
+
+	engineOpts := inference.DefaultEngineOptions()
+	engineOpts.APIOnly = enrichAPIOnly
+	engine := inference.NewEngine(engineOpts)
+	p := newProgress(cmd, "Inferring", progressRecords, int64(len(records)))
+	for i := range records {
+		engine.ProcessRecord(&records[i])
+		p.Add(1)
+	}
+	p.Done()
+	result := engine.Finalize()
+
+	opts := openapi.DefaultEnrichOptions()
+	opts.Examples = enrichExamples
+	opts.ResponseHeaders = enrichResponseHeaders
+	opts.ObservedFieldExtensions = enrichObservedExtensions
+	report := openapi.Enrich(spec, result, opts)
+
+	cmd.Printf("Matched %d operations: added %d examples, %d response headers\n",
+		report.Operations, report.Examples, report.Headers)
+	for _, f := range report.ObservedFields {
+		typ := ""
+		if f.Type != "" {
+			typ = " (" + f.Type + ")"
+		}
+		cmd.Printf("Warning: %s %s %s: field %s%s is not in the spec\n", f.Method, f.Path, f.Location, f.Field, typ)
+	}
+	for _, endpoint := range report.Undocumented {
+		cmd.Printf("Warning: %s has no operation in the spec\n", endpoint)
+	}
+
+	if enrichOverlay {
+		return writeEnrichOverlay(cmd, spec, format)
+	}
+
+	if enrichOutputPath == "" {
+		oaFormat := openapi.FormatYAML
+		if format == "json" {
+			oaFormat = openapi.FormatJSON
+		}
+		output, err := openapi.ToString(spec, oaFormat)
+		if err != nil {
+			return fmt.Errorf("generating output: %w", err)
+		}
+		fmt.Print(output)
+		return nil
+	}
+
+	if err := openapi.WriteFile(enrichOutputPath, spec); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	cmd.Printf("Wrote enriched spec to %s\n", enrichOutputPath)
+	return nil
+}
+
+// writeEnrichOverlay writes an overlay turning the spec as read into the
+// enriched spec.
+func writeEnrichOverlay(cmd *cobra.Command, enriched *openapi.Spec, format string) error {
+	base, err := openapi.ReadFile(enrichSpecPath)
+	if err != nil {
+		return fmt.Errorf("reading spec: %w", err)
+	}
+	title := "Traffic enrichment"
+	if base.Info.Title != "" {
+		title = base.Info.Title + " traffic enrichment"
+	}
+	overlay, err := openapi.DiffOverlay(base, enriched, openapi.OverlayInfo{Title: title, Version: "1.0.0"})
+	if err != nil {
+		return fmt.Errorf("generating overlay: %w", err)
+	}
+	overlay.Extends = enrichSpecPath
+
+	oaFormat := openapi.FormatYAML
+	if format == "json" {
+		oaFormat = openapi.FormatJSON
+	}
+	if enrichOutputPath == "" {
+		return openapi.WriteOverlay(os.Stdout, overlay, oaFormat)
+	}
+
+	f, err := os.Create(enrichOutputPath)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	if err := openapi.WriteOverlay(f, overlay, oaFormat); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing output: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	cmd.Printf("Wrote overlay with %d actions to %s\n", len(overlay.Actions), enrichOutputPath)
+	return nil
+}
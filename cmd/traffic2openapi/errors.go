@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/spf13/cobra"
+)
+
+var errorsCmd = &cobra.Command{
+	Use:   "errors",
+	Short: "Report error codes observed in 4xx/5xx responses",
+	Long: `Extract the error codes returned across all endpoints and report which
+endpoints and statuses return each code, and how often.
+
+Codes are read from the code, error, error_code, errorCode, error.code, and
+errors[].code fields of 4xx and 5xx JSON response bodies. Only code-like
+strings (no whitespace, at most 64 characters) are counted, so messages in an
+"error" field are ignored.
+
+Use 'generate --error-codes' to declare the codes as a shared ErrorCode enum
+component in the generated spec.
+
+Examples:
+  # Report error codes
+  traffic2openapi errors -i traffic.ndjson
+
+  # Output as JSON
+  traffic2openapi errors -i ./logs/ --format json`,
+	RunE: runErrors,
+}
+
+var (
+	errorsInputPath string
+	errorsFormat    string
+)
+
+func init() {
+	rootCmd.AddCommand(errorsCmd)
+
+	errorsCmd.Flags().StringVarP(&errorsInputPath, "input", "i", "", "Input file or directory containing IR files (required)")
+	errorsCmd.Flags().StringVarP(&errorsFormat, "format", "f", "text", "Output format: text or json")
+
+	_ = errorsCmd.MarkFlagRequired("input")
+}
+
+func runErrors(cmd *cobra.Command, args []string) error {
+	if errorsFormat != "text" && errorsFormat != "json" {
+		return inputError(fmt.Errorf("unsupported format: %s (use text or json)", errorsFormat))
+	}
+
+	records, err := readIRInput(cmd, errorsInputPath)
+	if err != nil {
+		return fmt.Errorf("reading IR files: %w", err)
+	}
+
+	engine := inference.NewEngine(inference.DefaultEngineOptions())
+	engine.ProcessRecords(records)
+	codes := engine.Finalize().ErrorCodes
+
+	if errorsFormat == "json" {
+		data, err := json.MarshalIndent(codes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	cmd.Printf("Found %d error codes in %d records\n", len(codes), len(records))
+	for _, code := range codes {
+		cmd.Printf("\n%s: %d responses\n", code.Code, code.Count)
+		for _, use := range code.Endpoints {
+			cmd.Printf("  %s %d (%s): %d\n", use.Endpoint, use.Status, use.Field, use.Count)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/spf13/cobra"
+)
+
+var evolutionCmd = &cobra.Command{
+	Use:   "evolution",
+	Short: "Reconstruct a schema changelog from traffic over time",
+	Long: `Bucket traffic into time windows, infer the schemas of each window, and
+report when endpoints were first and last seen and when body fields appeared,
+disappeared, or changed type.
+
+Each request and response body is compared with the last window its endpoint
+and status were observed in, so quiet periods don't report fields as removed.
+A field is reported removed once it is missing from --removed-after such
+windows in a row, so optional fields absent from a few windows don't flap.
+Records without a timestamp are skipped.
+
+The window is a Go duration (30m, 6h) or a number of days or weeks (1d, 2w).
+Windows are aligned to UTC.
+
+Examples:
+  # Daily changelog
+  traffic2openapi evolution -i captures/ --window 1d
+
+  # Hourly windows as JSON
+  traffic2openapi evolution -i traffic.ndjson --window 1h --format json`,
+	RunE: runEvolution,
+}
+
+var (
+	evolutionInputPath string
+	evolutionWindow    string
+	evolutionFormat    string
+	evolutionRemoved   int
+)
+
+func init() {
+	rootCmd.AddCommand(evolutionCmd)
+
+	evolutionCmd.Flags().StringVarP(&evolutionInputPath, "input", "i", "", "Input file or directory containing IR files (required)")
+	evolutionCmd.Flags().StringVarP(&evolutionWindow, "window", "w", "1d", "Time window size, e.g. 1h, 1d, or 1w")
+	evolutionCmd.Flags().StringVarP(&evolutionFormat, "format", "f", "text", "Output format: text or json")
+	evolutionCmd.Flags().IntVar(&evolutionRemoved, "removed-after", inference.DefaultEvolutionRemovedAfter, "Windows a field must be missing from before it is reported removed")
+
+	_ = evolutionCmd.MarkFlagRequired("input")
+}
+
+func runEvolution(cmd *cobra.Command, args []string) error {
+	if evolutionFormat != "text" && evolutionFormat != "json" {
+		return inputError(fmt.Errorf("unsupported format: %s (use text or json)", evolutionFormat))
+	}
+	window, err := parseWindow(evolutionWindow)
+	if err != nil {
+		return inputError(err)
+	}
+
+	records, err := readIRInput(cmd, evolutionInputPath)
+	if err != nil {
+		return inputError(fmt.Errorf("reading IR files: %w", err))
+	}
+
+	report := inference.Evolution(records, inference.EvolutionOptions{
+		Window:       window,
+		RemovedAfter: evolutionRemoved,
+		Engine:       inference.DefaultEngineOptions(),
+	})
+
+	if evolutionFormat == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	layout := time.DateOnly
+	if window < 24*time.Hour || window%(24*time.Hour) != 0 {
+		layout = "2006-01-02 15:04"
+	}
+
+	cmd.Printf("Found %d windows of %s in %d records\n", len(report.Windows), evolutionWindow, len(records))
+	if report.Untimestamped > 0 {
+		cmd.Printf("Skipped %d records without a timestamp\n", report.Untimestamped)
+	}
+	if len(report.Windows) == 0 {
+		return nil
+	}
+
+	cmd.Println("\nWindows:")
+	for _, w := range report.Windows {
+		cmd.Printf("  %s  %d records, %d endpoints\n", w.Start.Format(layout), w.Records, w.Endpoints)
+	}
+
+	cmd.Println("\nEndpoints:")
+	for _, e := range report.Endpoints {
+		cmd.Printf("  %s  first seen %s, last seen %s\n", e.Endpoint, e.FirstSeen.Format(layout), e.LastSeen.Format(layout))
+	}
+
+	if len(report.Changes) == 0 {
+		cmd.Println("\nNo field changes")
+		return nil
+	}
+	cmd.Println("\nChanges:")
+	for _, c := range report.Changes {
+		var change string
+		switch c.Kind {
+		case inference.FieldAdded:
+			change = fmt.Sprintf("+ %s (%s)", c.Field, c.NewType)
+		case inference.FieldRemoved:
+			change = fmt.Sprintf("- %s (%s)", c.Field, c.OldType)
+		default:
+			change = fmt.Sprintf("~ %s: %s -> %s", c.Field, c.OldType, c.NewType)
+		}
+		cmd.Printf("  %s  %s %s  %s\n", c.Window.Format(layout), c.Endpoint, c.Location, change)
+	}
+	return nil
+}
+
+// parseWindow parses a window size given as a Go duration or as a whole
+// number of days (1d) or weeks (1w).
+func parseWindow(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+
+	var window time.Duration
+	if unit > 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid window: %s", s)
+		}
+		window = time.Duration(n) * unit
+	} else {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window: %s", s)
+		}
+		window = d
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("invalid window: %s (must be positive)", s)
+	}
+	return window, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/grokify/traffic2openapi/pkg/har"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+)
+
+// Exit codes shared by all commands, so scripts can branch on outcomes.
+// Every failure exits with exitFailure unless --detailed-exit-codes is set.
+const (
+	exitOK          = 0
+	exitFailure     = 1 // unexpected failure
+	exitDifferences = 2 // differences or drift found
+	exitBreaking    = 3 // breaking changes found
+	exitValidation  = 4 // validation errors found
+	exitInput       = 5 // invalid arguments or unreadable input
+)
+
+// exitError sets the process exit code for an error returned by a command.
+// A nil err reports an outcome, such as differences found, with no message.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode returns an error that exits with code.
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// inputError marks err as an input error, or returns nil if err is nil.
+func inputError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return withExitCode(exitInput, err)
+}
+
+// commandStarted is set once arguments and flags have been validated, so
+// errors before then are reported as input errors.
+var commandStarted bool
+
+// detailedExitCodes is set with --detailed-exit-codes.
+var detailedExitCodes bool
+
+// exitCode returns the process exit code for an error returned by Execute:
+// exitFailure for any error, or with --detailed-exit-codes the code of its
+// outcome.
+func exitCode(err error) int {
+	code := outcomeCode(err)
+	if code != exitOK && !detailedExitCodes {
+		return exitFailure
+	}
+	return code
+}
+
+// outcomeCode returns the exit code for the outcome of an error. Unreadable
+// files and the parse errors of the ir, openapi, and har packages are input
+// errors wherever they occur.
+func outcomeCode(err error) int {
+	var exit *exitError
+	var pathErr *fs.PathError
+	var invalidRecord *ir.ErrInvalidRecord
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &exit):
+		return exit.code
+	case !commandStarted, errors.As(err, &pathErr), errors.As(err, &invalidRecord),
+		errors.Is(err, ir.ErrUnsupportedVersion), errors.Is(err, openapi.ErrInvalidSpec),
+		errors.Is(err, har.ErrMissingLog):
+		return exitInput
+	default:
+		return exitFailure
+	}
+}
+
+// errorMessage returns the message to print for an error returned by
+// Execute, or "" for outcomes that carry only an exit code.
+func errorMessage(err error) string {
+	var exit *exitError
+	if err == nil || (errors.As(err, &exit) && exit.err == nil) {
+		return ""
+	}
+	return err.Error()
+}
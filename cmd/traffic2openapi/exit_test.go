@@ -0,0 +1,41 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestInvalidFlagExitCodes checks that each command reports an invalid flag
+// value as an input error before it reads the input, which does not exist.
+func TestInvalidFlagExitCodes(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.ndjson")
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"generate", []string{"generate", "-i", missing, "--header-mode", "bogus"}, "unsupported header mode"},
+		{"generate noise filter", []string{"generate", "-i", missing, "--noise-filter", "bogus"}, "unsupported noise filter"},
+		{"generate version", []string{"generate", "-i", missing, "--version", "2.0"}, "unsupported OpenAPI version"},
+		{"dedupe", []string{"dedupe", "-i", missing, "--per-group", "0"}, "invalid --per-group"},
+		{"enrich", []string{"enrich", "--spec", missing, "-i", missing, "--format", "bogus"}, "unsupported format"},
+		{"errors", []string{"errors", "-i", missing, "--format", "bogus"}, "unsupported format"},
+		{"export arazzo", []string{"export", "arazzo", "-i", missing, "--format", "bogus"}, "unsupported format"},
+		{"export flows", []string{"export", "flows", "-i", missing, "--format", "bogus"}, "unsupported format"},
+		{"export resources", []string{"export", "resources", "-i", missing, "--format", "bogus"}, "unsupported format"},
+		{"serve", []string{"serve", missing, "--ui", "bogus"}, "unsupported UI"},
+		{"merge", []string{"merge", "-i", missing, "-o", missing, "--time-offset", "bogus"}, "invalid time offset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCLI(t, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			if code := outcomeCode(err); code != exitInput {
+				t.Errorf("expected exit code %d, got %d (%v)", exitInput, code, err)
+			}
+		})
+	}
+}
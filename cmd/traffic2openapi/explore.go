@@ -0,0 +1,442 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+var exploreCmd = &cobra.Command{
+	Use:   "explore",
+	Short: "Browse inferred endpoints and choose which to document",
+	Long: `Interactively browse the endpoints inferred from IR traffic, inspect
+their example requests and responses, and mark endpoints for inclusion or
+exclusion. The selection is saved as JSON for generate --select.
+
+If the selection file already exists it is loaded, so a selection can be
+refined across sessions. Commands at the explore> prompt:
+
+  list [text]        List endpoints, optionally those containing text
+  show <n> [example] Show an endpoint and one of its example exchanges
+  include <n>...     Include endpoints (numbers, ranges like 3-7, or all)
+  exclude <n>...     Exclude endpoints
+  save               Write the selection file
+  quit               Exit (asks again if there are unsaved changes)
+
+Examples:
+  # Explore a capture, saving the selection to selection.json
+  traffic2openapi explore -i capture.ndjson
+
+  # Generate a spec from the chosen endpoints
+  traffic2openapi generate -i capture.ndjson --select selection.json -o api.yaml`,
+	RunE: runExplore,
+}
+
+var (
+	exploreInputPath  string
+	exploreOutputPath string
+	exploreAPIOnly    bool
+	exploreMetaFilter []string
+)
+
+func init() {
+	rootCmd.AddCommand(exploreCmd)
+
+	exploreCmd.Flags().StringVarP(&exploreInputPath, "input", "i", "", "Input file or directory containing IR files (required)")
+	exploreCmd.Flags().StringVarP(&exploreOutputPath, "output", "o", "selection.json", "Endpoint selection file to load and save")
+	exploreCmd.Flags().BoolVar(&exploreAPIOnly, "api-only", false, "Skip static assets and page loads (HTML, JS, CSS, images, fonts)")
+
+	exploreCmd.Flags().StringArrayVar(&exploreMetaFilter, "meta-filter", nil, "Only use records with this metadata as key=value, e.g. service=checkout (can be repeated)")
+
+	_ = exploreCmd.MarkFlagRequired("input")
+}
+
+func runExplore(cmd *cobra.Command, args []string) error {
+	filter, err := ir.ParseMetadata(exploreMetaFilter)
+	if err != nil {
+		return inputError(err)
+	}
+
+	records, err := readIRInput(cmd, exploreInputPath)
+	if err != nil {
+		return fmt.Errorf("reading IR files: %w", err)
+	}
+	if len(filter) > 0 {
+		// Filter here rather than in the engine so example exchanges match too
+		records = slices.DeleteFunc(records, func(r ir.IRRecord) bool { return !ir.MatchesMetadata(&r, filter) })
+	}
+
+	opts := inference.DefaultEngineOptions()
+	opts.APIOnly = exploreAPIOnly
+	engine := inference.NewEngine(opts)
+	engine.ProcessRecords(records)
+	result := engine.Finalize()
+	if len(result.Endpoints) == 0 {
+		return inputError(fmt.Errorf("no endpoints found in input"))
+	}
+
+	selection, err := inference.ReadEndpointSelection(exploreOutputPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		selection = nil
+	} else if err != nil {
+		return inputError(err)
+	}
+
+	e := newExplorer(cmd.OutOrStdout(), result, records, selection)
+	return e.run(cmd.InOrStdin())
+}
+
+// explorer is an interactive endpoint browsing session.
+type explorer struct {
+	out       io.Writer
+	keys      []string // endpoint keys in list order
+	endpoints map[string]*inference.EndpointData
+	records   map[string][]*ir.IRRecord // endpoint key -> observed records
+	excluded  map[string]bool
+	extra     *inference.EndpointSelection // loaded entries for endpoints not in this traffic
+	dirty     bool
+}
+
+func newExplorer(out io.Writer, result *inference.InferenceResult, records []ir.IRRecord, selection *inference.EndpointSelection) *explorer {
+	e := &explorer{
+		out:       out,
+		endpoints: result.Endpoints,
+		records:   make(map[string][]*ir.IRRecord),
+		excluded:  make(map[string]bool),
+		extra:     &inference.EndpointSelection{},
+	}
+
+	trie := inference.NewTemplateTrie()
+	for key, endpoint := range result.Endpoints {
+		e.keys = append(e.keys, key)
+		trie.Add(endpoint.PathTemplate)
+	}
+	sort.Slice(e.keys, func(i, j int) bool {
+		a, b := result.Endpoints[e.keys[i]], result.Endpoints[e.keys[j]]
+		if a.PathTemplate != b.PathTemplate {
+			return a.PathTemplate < b.PathTemplate
+		}
+		return a.Method < b.Method
+	})
+
+	for i := range records {
+		record := &records[i]
+		template, _, ok := trie.Match(record.Request.Path)
+		if !ok {
+			continue
+		}
+		key := inference.EndpointKey(string(record.Request.Method), template)
+		if _, ok := e.endpoints[key]; ok {
+			e.records[key] = append(e.records[key], record)
+		}
+	}
+
+	if selection != nil {
+		for _, key := range e.keys {
+			e.excluded[key] = !selection.Allows(key)
+		}
+		// Keep exclusions of endpoints absent from this traffic
+		for _, key := range selection.Exclude {
+			if _, ok := e.endpoints[key]; !ok {
+				e.extra.Exclude = append(e.extra.Exclude, key)
+			}
+		}
+	}
+	return e
+}
+
+// run reads commands until quit or end of input.
+func (e *explorer) run(in io.Reader) error {
+	e.list("")
+	fmt.Fprintln(e.out, "\nType help for commands.")
+
+	scanner := bufio.NewScanner(in)
+	confirmQuit := false
+	for {
+		fmt.Fprint(e.out, "explore> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(e.out)
+			if e.dirty {
+				fmt.Fprintln(e.out, "Unsaved changes discarded")
+			}
+			return scanner.Err()
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		command, args := strings.ToLower(fields[0]), fields[1:]
+		if command != "quit" && command != "q" && command != "exit" {
+			confirmQuit = false
+		}
+		switch command {
+		case "help", "h", "?":
+			e.help()
+		case "list", "ls", "l":
+			e.list(strings.Join(args, " "))
+		case "show", "s":
+			e.show(args)
+		case "include", "i":
+			e.mark(args, false)
+		case "exclude", "x":
+			e.mark(args, true)
+		case "save", "w":
+			if err := e.save(); err != nil {
+				fmt.Fprintf(e.out, "Error: %v\n", err)
+			}
+		case "quit", "q", "exit":
+			if e.dirty && !confirmQuit {
+				fmt.Fprintln(e.out, "Unsaved changes; save first, or quit again to discard them")
+				confirmQuit = true
+				continue
+			}
+			return nil
+		default:
+			if _, err := strconv.Atoi(command); err == nil {
+				e.show(fields)
+				continue
+			}
+			fmt.Fprintf(e.out, "Unknown command: %s (type help)\n", command)
+		}
+	}
+}
+
+func (e *explorer) help() {
+	fmt.Fprintln(e.out, `Commands:
+  list [text]        List endpoints, optionally those containing text (l)
+  show <n> [example] Show an endpoint and one of its examples (s, or just <n>)
+  include <n>...     Include endpoints: numbers, ranges like 3-7, or all (i)
+  exclude <n>...     Exclude endpoints (x)
+  save               Write the selection to `+exploreOutputPath+` (w)
+  quit               Exit (q)`)
+}
+
+// list prints the endpoints whose key contains filter.
+func (e *explorer) list(filter string) {
+	filter = strings.ToLower(filter)
+	included := 0
+	for i, key := range e.keys {
+		if !e.excluded[key] {
+			included++
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(key), filter) {
+			continue
+		}
+		mark := "[+]"
+		if e.excluded[key] {
+			mark = "[ ]"
+		}
+		endpoint := e.endpoints[key]
+		fmt.Fprintf(e.out, "%4d %s %-7s %s  (%d requests; %s)\n", i+1, mark, endpoint.Method, endpoint.PathTemplate,
+			endpoint.RequestCount, e.statusSummary(key))
+	}
+	fmt.Fprintf(e.out, "%d of %d endpoints included\n", included, len(e.keys))
+}
+
+// statusSummary lists the observed status codes of an endpoint.
+func (e *explorer) statusSummary(key string) string {
+	statuses := make([]int, 0, len(e.endpoints[key].Responses))
+	for status := range e.endpoints[key].Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = strconv.Itoa(status)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// show prints an endpoint's parameters and one example exchange.
+func (e *explorer) show(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(e.out, "Usage: show <n> [example]")
+		return
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(e.keys) {
+		fmt.Fprintf(e.out, "No endpoint %s (1-%d)\n", args[0], len(e.keys))
+		return
+	}
+	key := e.keys[n-1]
+	endpoint := e.endpoints[key]
+	records := e.records[key]
+
+	status := "included"
+	if e.excluded[key] {
+		status = "excluded"
+	}
+	fmt.Fprintf(e.out, "%s %s (%s)\n", endpoint.Method, endpoint.PathTemplate, status)
+	fmt.Fprintf(e.out, "  Requests: %d\n", endpoint.RequestCount)
+	fmt.Fprintf(e.out, "  Statuses: %s\n", e.statusSummary(key))
+	for _, group := range []struct {
+		name   string
+		params map[string]*inference.ParamData
+	}{
+		{"Path params", endpoint.PathParams},
+		{"Query params", endpoint.QueryParams},
+		{"Header params", endpoint.HeaderParams},
+	} {
+		if len(group.params) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(group.params))
+		for name := range group.params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(e.out, "  %s: %s\n", group.name, strings.Join(names, ", "))
+	}
+
+	if len(records) == 0 {
+		return
+	}
+	example := 1
+	if len(args) > 1 {
+		if example, err = strconv.Atoi(args[1]); err != nil || example < 1 || example > len(records) {
+			fmt.Fprintf(e.out, "No example %s (1-%d)\n", args[1], len(records))
+			return
+		}
+	}
+	record := records[example-1]
+
+	fmt.Fprintf(e.out, "\nExample %d of %d", example, len(records))
+	if record.Id != nil {
+		fmt.Fprintf(e.out, " (%s)", *record.Id)
+	}
+	fmt.Fprintln(e.out)
+	target := record.Request.Path
+	if len(record.Request.Query) > 0 {
+		names := make([]string, 0, len(record.Request.Query))
+		for name := range record.Request.Query {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			sep := "&"
+			if i == 0 {
+				sep = "?"
+			}
+			target += fmt.Sprintf("%s%s=%v", sep, name, record.Request.Query[name])
+		}
+	}
+	fmt.Fprintf(e.out, "> %s %s\n", record.Request.Method, target)
+	e.printBody(">", record.Request.Body)
+	fmt.Fprintf(e.out, "< %d\n", record.Response.Status)
+	e.printBody("<", record.Response.Body)
+}
+
+// maxExploreBodyLines limits the body lines shown per example.
+const maxExploreBodyLines = 40
+
+// printBody prints a body as indented JSON, truncated to maxExploreBodyLines.
+func (e *explorer) printBody(prefix string, body any) {
+	if body == nil {
+		return
+	}
+	text, ok := body.(string)
+	if !ok {
+		data, err := json.MarshalIndent(body, "", "  ")
+		if err != nil {
+			return
+		}
+		text = string(data)
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if i == maxExploreBodyLines {
+			fmt.Fprintf(e.out, "%s ... (%d more lines)\n", prefix, len(lines)-i)
+			break
+		}
+		fmt.Fprintf(e.out, "%s %s\n", prefix, line)
+	}
+}
+
+// mark includes or excludes the endpoints named by args.
+func (e *explorer) mark(args []string, exclude bool) {
+	indexes, err := parseEndpointIndexes(args, len(e.keys))
+	if err != nil {
+		fmt.Fprintf(e.out, "Error: %v\n", err)
+		return
+	}
+	changed := 0
+	for _, i := range indexes {
+		key := e.keys[i]
+		if e.excluded[key] != exclude {
+			e.excluded[key] = exclude
+			changed++
+		}
+	}
+	if changed > 0 {
+		e.dirty = true
+	}
+	verb := "Included"
+	if exclude {
+		verb = "Excluded"
+	}
+	fmt.Fprintf(e.out, "%s %d endpoints\n", verb, changed)
+}
+
+// parseEndpointIndexes parses endpoint numbers, ranges such as 3-7, and
+// "all" into zero-based indexes.
+func parseEndpointIndexes(args []string, count int) ([]int, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no endpoints given (use numbers, ranges like 3-7, or all)")
+	}
+	var indexes []int
+	for _, arg := range args {
+		if strings.EqualFold(arg, "all") {
+			for i := range count {
+				indexes = append(indexes, i)
+			}
+			continue
+		}
+		from, to, isRange := strings.Cut(arg, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint number: %s", arg)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil {
+				return nil, fmt.Errorf("invalid endpoint range: %s", arg)
+			}
+		}
+		if first < 1 || last > count || first > last {
+			return nil, fmt.Errorf("endpoint %s out of range (1-%d)", arg, count)
+		}
+		for n := first; n <= last; n++ {
+			indexes = append(indexes, n-1)
+		}
+	}
+	return indexes, nil
+}
+
+// save writes the excluded endpoints to the selection file. Endpoints not
+// excluded stay included, so newly observed endpoints are documented.
+func (e *explorer) save() error {
+	selection := &inference.EndpointSelection{Exclude: append([]string(nil), e.extra.Exclude...)}
+	for _, key := range e.keys {
+		if e.excluded[key] {
+			selection.Exclude = append(selection.Exclude, key)
+		}
+	}
+	if err := selection.WriteFile(exploreOutputPath); err != nil {
+		return err
+	}
+	e.dirty = false
+	fmt.Fprintf(e.out, "Saved %d excluded endpoints to %s\n", len(selection.Exclude), exploreOutputPath)
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export IR records to other traffic and API definition formats",
+	Long: `Export Intermediate Representation (IR) records to other traffic and API
+definition formats.
+
+Supported targets:
+  - har:      HAR 1.2 (HTTP Archive) for browser DevTools, Charles, Fiddler, etc.
+  - flows:    Common call sequences across client sessions
+  - arazzo:   Common call sequences as an OpenAPI Arazzo workflows document
+  - typespec: The inferred API, or an OpenAPI spec, as a TypeSpec definition
+  - smithy:   The inferred API, or an OpenAPI spec, as a Smithy model
+  - resources: The resources of the inferred API and their relations, as
+               JSON or a Mermaid or Graphviz ER diagram
+
+Examples:
+  # Export IR records to HAR
+  traffic2openapi export har -i traffic.ndjson -o traffic.har
+
+  # Export a directory of IR files to HAR
+  traffic2openapi export har -i ./logs/ -o traffic.har
+
+  # Export common call sequences
+  traffic2openapi export flows -i traffic.ndjson -o flows.json
+
+  # Export common call sequences as Arazzo workflows
+  traffic2openapi export arazzo -i traffic.ndjson -o workflows.arazzo.yaml
+
+  # Export the inferred API as TypeSpec or Smithy
+  traffic2openapi export typespec -i traffic.ndjson -o main.tsp
+  traffic2openapi export smithy --spec openapi.yaml -o main.smithy
+
+  # Export the resource model as a Mermaid ER diagram
+  traffic2openapi export resources -i traffic.ndjson -f mermaid -o resources.mmd`,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+}
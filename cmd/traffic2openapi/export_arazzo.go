@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/arazzo"
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+var exportArazzoCmd = &cobra.Command{
+	Use:   "arazzo",
+	Short: "Export common call sequences as an Arazzo workflows document",
+	Long: `Group IR records into client sessions and export the call sequences
+common to many sessions as an OpenAPI Arazzo 1.0 document, with a workflow
+per sequence.
+
+Sessions and sequences are found as in 'export flows'. A step parameter is
+passed from an earlier step's response field when its value matched that
+field in every occurrence of the sequence, e.g. the id returned by
+POST /orders used as {orderId} in GET /orders/{orderId}. Other path
+parameters become workflow inputs.
+
+Steps reference operations by the operation IDs 'generate' assigns, so
+point --spec-url at a spec generated from the same traffic.
+
+Examples:
+  # Export workflows next to the generated spec
+  traffic2openapi generate -i traffic.ndjson -o openapi.yaml
+  traffic2openapi export arazzo -i traffic.ndjson -o workflows.arazzo.yaml
+
+  # Reference a published spec and require 5 sessions per workflow
+  traffic2openapi export arazzo -i ./logs/ -o workflows.arazzo.json \
+    --spec-url https://api.example.com/openapi.json --min-sessions 5`,
+	RunE: runExportArazzo,
+}
+
+var (
+	exportArazzoInputPath   string
+	exportArazzoOutputPath  string
+	exportArazzoFormat      string
+	exportArazzoSpecURL     string
+	exportArazzoTitle       string
+	exportArazzoGap         time.Duration
+	exportArazzoMinLength   int
+	exportArazzoMaxLength   int
+	exportArazzoMinSessions int
+)
+
+func init() {
+	exportCmd.AddCommand(exportArazzoCmd)
+
+	defaults := arazzo.DefaultOptions()
+	exportArazzoCmd.Flags().StringVarP(&exportArazzoInputPath, "input", "i", "", "Input IR file or directory (required)")
+	exportArazzoCmd.Flags().StringVarP(&exportArazzoOutputPath, "output", "o", "", "Output file path (default: stdout)")
+	exportArazzoCmd.Flags().StringVarP(&exportArazzoFormat, "format", "f", "", "Output format: json or yaml (default: auto-detect from extension)")
+	exportArazzoCmd.Flags().StringVar(&exportArazzoSpecURL, "spec-url", defaults.SourceURL, "URL of the OpenAPI spec the workflows call")
+	exportArazzoCmd.Flags().StringVar(&exportArazzoTitle, "title", defaults.Title, "Document title")
+	exportArazzoCmd.Flags().DurationVar(&exportArazzoGap, "gap", ir.DefaultSessionGap, "Idle time that ends a session")
+	exportArazzoCmd.Flags().IntVar(&exportArazzoMinLength, "min-length", defaults.Flows.MinLength, "Minimum steps in a workflow")
+	exportArazzoCmd.Flags().IntVar(&exportArazzoMaxLength, "max-length", defaults.Flows.MaxLength, "Maximum steps in a workflow")
+	exportArazzoCmd.Flags().IntVar(&exportArazzoMinSessions, "min-sessions", defaults.Flows.MinSessions, "Minimum sessions a workflow must occur in")
+
+	_ = exportArazzoCmd.MarkFlagRequired("input")
+}
+
+func runExportArazzo(cmd *cobra.Command, args []string) error {
+	format := exportArazzoFormat
+	if format == "" {
+		format = "yaml"
+		if strings.ToLower(filepath.Ext(exportArazzoOutputPath)) == ".json" {
+			format = "json"
+		}
+	}
+	if format != "json" && format != "yaml" {
+		return inputError(fmt.Errorf("unsupported format: %s (use json or yaml)", format))
+	}
+
+	records, err := readIRInput(cmd, exportArazzoInputPath)
+	if err != nil {
+		return fmt.Errorf("reading input: %w", err)
+	}
+
+	sessions := ir.Sessionize(records, ir.SessionOptions{Gap: exportArazzoGap})
+	opts := arazzo.DefaultOptions()
+	opts.Title = exportArazzoTitle
+	opts.SourceURL = exportArazzoSpecURL
+	opts.Flows = inference.FlowOptions{
+		MinLength:   exportArazzoMinLength,
+		MaxLength:   exportArazzoMaxLength,
+		MinSessions: exportArazzoMinSessions,
+	}
+	doc := arazzo.Generate(sessions, opts)
+	cmd.Printf("Found %d workflows in %d sessions (%d records)\n", len(doc.Workflows), len(sessions), len(records))
+
+	out := os.Stdout
+	if exportArazzoOutputPath != "" {
+		f, err := os.Create(exportArazzoOutputPath)
+		if err != nil {
+			return fmt.Errorf("creating output: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if format == "json" {
+		err = arazzo.WriteJSON(out, doc)
+	} else {
+		err = arazzo.WriteYAML(out, doc)
+	}
+	if err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	if exportArazzoOutputPath != "" {
+		cmd.Printf("Wrote workflows to %s\n", exportArazzoOutputPath)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+var exportFlowsCmd = &cobra.Command{
+	Use:   "flows",
+	Short: "Export common call sequences across client sessions",
+	Long: `Group IR records into client sessions and export the call sequences
+common to many sessions, such as login → list → detail.
+
+Sessions are identified by a hash of the bearer token, else a hash of the
+API key header, else the client IP (the clientIP metadata key or the
+X-Forwarded-For and X-Real-IP headers), and end after --gap of inactivity.
+Records without an identity are skipped; converters filter authorization
+headers by default, so keep them (or record client IPs) when capturing.
+
+Repeated consecutive calls to one endpoint count as a single step. A
+sequence is left out when a longer sequence containing it occurs in as many
+sessions.
+
+Examples:
+  # Export flows as JSON
+  traffic2openapi export flows -i traffic.ndjson -o flows.json
+
+  # Print flows of up to 6 steps seen in at least 5 sessions
+  traffic2openapi export flows -i ./logs/ -f text --max-length 6 --min-sessions 5`,
+	RunE: runExportFlows,
+}
+
+var (
+	exportFlowsInputPath   string
+	exportFlowsOutputPath  string
+	exportFlowsFormat      string
+	exportFlowsGap         time.Duration
+	exportFlowsMinLength   int
+	exportFlowsMaxLength   int
+	exportFlowsMinSessions int
+)
+
+// flowsOutput is the JSON document written by export flows.
+type flowsOutput struct {
+	Records  int                  `json:"records"`
+	Sessions int                  `json:"sessions"`
+	Flows    []inference.CallFlow `json:"flows"`
+}
+
+func init() {
+	exportCmd.AddCommand(exportFlowsCmd)
+
+	defaults := inference.DefaultFlowOptions()
+	exportFlowsCmd.Flags().StringVarP(&exportFlowsInputPath, "input", "i", "", "Input IR file or directory (required)")
+	exportFlowsCmd.Flags().StringVarP(&exportFlowsOutputPath, "output", "o", "", "Output file path (default: stdout)")
+	exportFlowsCmd.Flags().StringVarP(&exportFlowsFormat, "format", "f", "json", "Output format: json or text")
+	exportFlowsCmd.Flags().DurationVar(&exportFlowsGap, "gap", ir.DefaultSessionGap, "Idle time that ends a session")
+	exportFlowsCmd.Flags().IntVar(&exportFlowsMinLength, "min-length", defaults.MinLength, "Minimum steps in a flow")
+	exportFlowsCmd.Flags().IntVar(&exportFlowsMaxLength, "max-length", defaults.MaxLength, "Maximum steps in a flow")
+	exportFlowsCmd.Flags().IntVar(&exportFlowsMinSessions, "min-sessions", defaults.MinSessions, "Minimum sessions a flow must occur in")
+
+	_ = exportFlowsCmd.MarkFlagRequired("input")
+}
+
+func runExportFlows(cmd *cobra.Command, args []string) error {
+	if exportFlowsFormat != "json" && exportFlowsFormat != "text" {
+		return inputError(fmt.Errorf("unsupported format: %s (use json or text)", exportFlowsFormat))
+	}
+
+	records, err := readIRInput(cmd, exportFlowsInputPath)
+	if err != nil {
+		return fmt.Errorf("reading input: %w", err)
+	}
+
+	sessions := ir.Sessionize(records, ir.SessionOptions{Gap: exportFlowsGap})
+	flows := inference.CommonFlows(sessions, inference.FlowOptions{
+		MinLength:   exportFlowsMinLength,
+		MaxLength:   exportFlowsMaxLength,
+		MinSessions: exportFlowsMinSessions,
+	})
+	cmd.Printf("Found %d flows in %d sessions (%d records)\n", len(flows), len(sessions), len(records))
+
+	var data []byte
+	if exportFlowsFormat == "json" {
+		if flows == nil {
+			flows = []inference.CallFlow{}
+		}
+		data, err = json.MarshalIndent(flowsOutput{Records: len(records), Sessions: len(sessions), Flows: flows}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		data = append(data, '\n')
+	} else {
+		for _, flow := range flows {
+			data = fmt.Appendf(data, "%4d sessions  %4d times  %s\n", flow.Sessions, flow.Count, flow)
+		}
+	}
+
+	if exportFlowsOutputPath == "" {
+		fmt.Print(string(data))
+		return nil
+	}
+	if err := os.WriteFile(exportFlowsOutputPath, data, 0600); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	cmd.Printf("Wrote flows to %s\n", exportFlowsOutputPath)
+	return nil
+}
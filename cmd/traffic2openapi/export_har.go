@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grokify/traffic2openapi/pkg/har"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+var exportHARCmd = &cobra.Command{
+	Use:   "har",
+	Short: "Export IR records to a HAR file",
+	Long: `Export Intermediate Representation (IR) records to a HAR 1.2 file.
+
+The resulting file can be opened in browser DevTools (Network panel import),
+Charles Proxy, Fiddler, or any other HAR viewer to debug captured traffic,
+for example traffic recorded with the LoggingTransport.
+
+Records with har.pageref metadata are grouped into HAR pages, titled by their
+har.pageTitle metadata.
+
+Examples:
+  # Export an IR file to HAR
+  traffic2openapi export har -i traffic.ndjson -o traffic.har
+
+  # Export a directory of IR files
+  traffic2openapi export har -i ./logs/ -o traffic.har
+
+  # Write HAR to stdout
+  traffic2openapi export har -i traffic.ndjson`,
+	RunE: runExportHAR,
+}
+
+var (
+	exportHARInputPath  string
+	exportHAROutputPath string
+)
+
+func init() {
+	exportCmd.AddCommand(exportHARCmd)
+
+	exportHARCmd.Flags().StringVarP(&exportHARInputPath, "input", "i", "", "Input IR file or directory (required)")
+	exportHARCmd.Flags().StringVarP(&exportHAROutputPath, "output", "o", "", "Output HAR file path (default: stdout)")
+
+	_ = exportHARCmd.MarkFlagRequired("input")
+}
+
+func runExportHAR(cmd *cobra.Command, args []string) error {
+	info, err := os.Stat(exportHARInputPath)
+	if err != nil {
+		return fmt.Errorf("input path error: %w", err)
+	}
+
+	var records []ir.IRRecord
+	if info.IsDir() {
+		records, err = ir.ReadDir(exportHARInputPath)
+	} else {
+		records, err = ir.ReadFile(exportHARInputPath)
+	}
+	if err != nil {
+		return fmt.Errorf("reading input: %w", err)
+	}
+
+	writer := har.NewWriter()
+	writer.CreatorVersion = version
+
+	if exportHAROutputPath == "" {
+		return writer.Write(os.Stdout, records)
+	}
+
+	if err := writer.WriteFile(exportHAROutputPath, records); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	cmd.Printf("Exported %d records to %s\n", len(records), exportHAROutputPath)
+	return nil
+}
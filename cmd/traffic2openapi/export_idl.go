@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/grokify/traffic2openapi/pkg/openapi/idl"
+	"github.com/spf13/cobra"
+)
+
+var exportTypeSpecCmd = &cobra.Command{
+	Use:   "typespec",
+	Short: "Export an API definition as TypeSpec",
+	Long: `Export the API inferred from IR records, or an existing OpenAPI spec, as a
+TypeSpec definition using the @typespec/http library.
+
+Component schemas become models, enums, and unions of the same name; inline
+object schemas become models named after their operation or parent, such as
+GetUserResponse. Each operation becomes an op with @route, its parameters,
+and a response union by status code. Default responses are left out.
+
+Examples:
+  # Export the API inferred from traffic
+  traffic2openapi export typespec -i traffic.ndjson -o main.tsp
+
+  # Convert an existing spec
+  traffic2openapi export typespec --spec openapi.yaml --namespace Users -o main.tsp`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExportIDL(cmd, "TypeSpec", idl.WriteTypeSpec)
+	},
+}
+
+var exportSmithyCmd = &cobra.Command{
+	Use:   "smithy",
+	Short: "Export an API definition as a Smithy model",
+	Long: `Export the API inferred from IR records, or an existing OpenAPI spec, as a
+Smithy 2.0 IDL model.
+
+The model has a service shape listing an operation per spec operation, with
+@http, @httpLabel, @httpQuery, @httpHeader, and @httpPayload bindings. Each
+operation's output is its first success response, and its 4xx and 5xx
+responses become @error structures. Smithy has no unions of arbitrary shapes,
+so oneOf and anyOf schemas, and payloads that aren't structures, become
+documents.
+
+Examples:
+  # Export the API inferred from traffic
+  traffic2openapi export smithy -i traffic.ndjson -o model/main.smithy
+
+  # Convert an existing spec
+  traffic2openapi export smithy --spec openapi.yaml --namespace example.users -o main.smithy`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExportIDL(cmd, "Smithy model", idl.WriteSmithy)
+	},
+}
+
+var (
+	exportIDLInputPath  string
+	exportIDLSpecPath   string
+	exportIDLOutputPath string
+	exportIDLNamespace  string
+)
+
+func init() {
+	for _, c := range []*cobra.Command{exportTypeSpecCmd, exportSmithyCmd} {
+		exportCmd.AddCommand(c)
+
+		c.Flags().StringVarP(&exportIDLInputPath, "input", "i", "", "Input IR file or directory to infer the API from")
+		c.Flags().StringVar(&exportIDLSpecPath, "spec", "", "OpenAPI spec to convert instead of inferring one")
+		c.Flags().StringVarP(&exportIDLOutputPath, "output", "o", "", "Output file path (default: stdout)")
+		c.Flags().StringVar(&exportIDLNamespace, "namespace", "", "Namespace of the definitions (default: derived from the spec title)")
+		c.MarkFlagsOneRequired("input", "spec")
+		c.MarkFlagsMutuallyExclusive("input", "spec")
+	}
+}
+
+func runExportIDL(cmd *cobra.Command, kind string, write func(io.Writer, *openapi.Spec, idl.Options) error) error {
+	var spec *openapi.Spec
+	if exportIDLSpecPath != "" {
+		var err error
+		spec, err = openapi.ReadFile(exportIDLSpecPath)
+		if err != nil {
+			return inputError(fmt.Errorf("reading spec: %w", err))
+		}
+	} else {
+		records, err := readIRInput(cmd, exportIDLInputPath)
+		if err != nil {
+			return inputError(fmt.Errorf("reading input: %w", err))
+		}
+		spec = openapi.GenerateFromInference(inference.InferFromRecords(records), openapi.DefaultGeneratorOptions())
+		cmd.Printf("Inferred %d paths from %d records\n", len(spec.Paths), len(records))
+	}
+
+	out := io.Writer(os.Stdout)
+	if exportIDLOutputPath != "" {
+		f, err := os.Create(exportIDLOutputPath)
+		if err != nil {
+			return fmt.Errorf("creating output: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := write(out, spec, idl.Options{Namespace: exportIDLNamespace}); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	if exportIDLOutputPath != "" {
+		cmd.Printf("Wrote %s to %s\n", kind, exportIDLOutputPath)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/resources"
+	"github.com/spf13/cobra"
+)
+
+var exportResourcesCmd = &cobra.Command{
+	Use:   "resources",
+	Short: "Export the resource model of the API with its relations",
+	Long: `Infer the API from IR records and export its resource model: the REST
+resources it exposes, their fields, and the relations between them.
+
+Resources are the collections and singletons of create, read, update, and
+delete endpoints, named in the singular (/users gives user). Their fields
+are the top-level properties of request and success response bodies, with
+arrays and list envelopes such as {"data": [...]} unwrapped. Relations come
+from nested paths (/users/{userId}/posts) and from fields such as userId,
+user_id, tagIds, or an embedded user object naming another resource.
+
+Formats:
+  - json:    The resources, operations, fields, and relations
+  - mermaid: A Mermaid erDiagram
+  - dot:     A Graphviz digraph of record nodes
+
+Examples:
+  # Export the resource model as JSON
+  traffic2openapi export resources -i traffic.ndjson -o resources.json
+
+  # Render an ER diagram with the Mermaid CLI or Graphviz
+  traffic2openapi export resources -i ./logs/ -f mermaid -o resources.mmd
+  traffic2openapi export resources -i ./logs/ -f dot | dot -Tsvg -o resources.svg`,
+	RunE: runExportResources,
+}
+
+var (
+	exportResourcesInputPath  string
+	exportResourcesOutputPath string
+	exportResourcesFormat     string
+)
+
+func init() {
+	exportCmd.AddCommand(exportResourcesCmd)
+
+	exportResourcesCmd.Flags().StringVarP(&exportResourcesInputPath, "input", "i", "", "Input IR file or directory (required)")
+	exportResourcesCmd.Flags().StringVarP(&exportResourcesOutputPath, "output", "o", "", "Output file path (default: stdout)")
+	exportResourcesCmd.Flags().StringVarP(&exportResourcesFormat, "format", "f", "json", "Output format: json, mermaid, or dot")
+
+	_ = exportResourcesCmd.MarkFlagRequired("input")
+}
+
+func runExportResources(cmd *cobra.Command, args []string) error {
+	var write func(io.Writer, *resources.Graph) error
+	switch exportResourcesFormat {
+	case "json":
+		write = resources.WriteJSON
+	case "mermaid":
+		write = resources.WriteMermaid
+	case "dot":
+		write = resources.WriteDOT
+	default:
+		return inputError(fmt.Errorf("unsupported format: %s (use json, mermaid, or dot)", exportResourcesFormat))
+	}
+
+	records, err := readIRInput(cmd, exportResourcesInputPath)
+	if err != nil {
+		return inputError(fmt.Errorf("reading input: %w", err))
+	}
+	graph := resources.Build(inference.InferFromRecords(records))
+	cmd.Printf("Found %d resources and %d relations in %d records\n", len(graph.Resources), len(graph.Relations), len(records))
+
+	out := io.Writer(os.Stdout)
+	if exportResourcesOutputPath != "" {
+		f, err := os.Create(exportResourcesOutputPath)
+		if err != nil {
+			return fmt.Errorf("creating output: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := write(out, graph); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	if exportResourcesOutputPath != "" {
+		cmd.Printf("Wrote resource model to %s\n", exportResourcesOutputPath)
+	}
+	return nil
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -48,25 +51,101 @@ Examples:
     --server https://api.example.com
 
   # Skip validation for faster generation
-  traffic2openapi generate -i ./logs/ -o api.yaml --skip-validation`,
+  traffic2openapi generate -i ./logs/ -o api.yaml --skip-validation
+
+  # Only document the endpoints chosen with explore
+  traffic2openapi generate -i ./logs/ -o api.yaml --select selection.json
+
+  # Merge mixed-case and trailing-slash duplicates from a legacy backend
+  traffic2openapi generate -i ./logs/ -o api.yaml --case-insensitive-paths --ignore-trailing-slash
+
+  # Locale and tenant segments as enumerated path parameters
+  traffic2openapi generate -i ./logs/ -o api.yaml --locale-segments --tenant acme --tenant globex
+
+  # Operation IDs and summaries from CRUD semantics (listUsers, createUser)
+  traffic2openapi generate -i ./logs/ -o api.yaml --crud-names
+
+  # Document request bodies the API rejected with 400 or 422
+  traffic2openapi generate -i ./logs/ -o api.yaml --invalid-examples 2
+
+  # Path parameter value statistics, for validation design
+  traffic2openapi generate -i ./logs/ -o api.yaml --param-stats
+
+  # Custom response descriptions for a status and a status class
+  traffic2openapi generate -i ./logs/ -o api.yaml --response-description "404=Resource not found" --response-description "5XX=Server error: {reason}"
+
+  # Custom credentials documented as apiKey security schemes
+  traffic2openapi generate -i ./logs/ -o api.yaml --security-rule orgToken=header:X-Org-Token --security-rule signature=query:signature
+
+  # One query parameter for pageSize and page_size
+  traffic2openapi generate -i ./logs/ -o api.yaml --query-param-aliases
+
+  # One operation per SOAP action rather than one per service URL
+  traffic2openapi generate -i ./soap/ -o api.yaml --soap-operations
+
+  # One spec per service label from a shared capture bucket
+  traffic2openapi generate -i ./bucket/ -o ./specs/ --partition-by label:service`,
 	RunE: runGenerate,
 }
 
 var (
-	inputPath       string
-	outputPath      string
-	openAPIVersion  string
-	openAPIVersions []string
-	allVersions     bool
-	outputFormat    string
-	apiTitle        string
-	apiDescription  string
-	apiVersion      string
-	servers         []string
-	includeErrors   bool
-	watchMode       bool
-	watchDebounce   time.Duration
-	skipValidation  bool
+	inputPath        string
+	outputPath       string
+	openAPIVersion   string
+	openAPIVersions  []string
+	allVersions      bool
+	outputFormat     string
+	apiTitle         string
+	apiDescription   string
+	apiVersion       string
+	servers          []string
+	includeErrors    bool
+	watchMode        bool
+	watchDebounce    time.Duration
+	skipValidation   bool
+	headerMode       string
+	allowHeaders     []string
+	minHeaderCount   int
+	requiredQuery    float64
+	requiredHeader   float64
+	minRequests      int
+	flagLowSample    bool
+	pruneNotFound    bool
+	unmatchedReport  string
+	noiseFilter      string
+	noiseEmptyAgent  bool
+	apiOnly          bool
+	ignoreGetDelete  bool
+	exampleSelect    string
+	maxExampleLen    int
+	exampleBudgetMB  int
+	metaFilters      []string
+	partitionBy      string
+	metaExtensions   []string
+	keepMediaParams  bool
+	collapseVendor   bool
+	keepOpPathParams bool
+	tagGroups        string
+	detectLinks      bool
+	linkMinValues    int
+	examplePairs     int
+	invalidExamples  int
+	errorCodeEnum    bool
+	selectionPath    string
+	soapOperations   bool
+	queryAliases     bool
+	commaQuery       []string
+	respDescriptions []string
+	securityRules    []string
+	crudNames        bool
+	paramStats       bool
+	localeSegments   bool
+	tenantSegments   bool
+	tenants          []string
+	ignoreCase       bool
+	ignoreSlash      bool
+
+	exampleSelection openapi.ExampleSelection
 )
 
 func init() {
@@ -86,6 +165,52 @@ func init() {
 	generateCmd.Flags().BoolVarP(&watchMode, "watch", "w", false, "Watch for file changes and regenerate")
 	generateCmd.Flags().DurationVar(&watchDebounce, "debounce", 500*time.Millisecond, "Debounce interval for watch mode")
 	generateCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip validation of generated spec")
+	generateCmd.Flags().StringVar(&headerMode, "header-mode", "blacklist", "Header parameter mode: blacklist, allowlist, or none")
+	generateCmd.Flags().StringSliceVar(&allowHeaders, "allow-header", nil, "Header to document in allowlist mode (can be repeated)")
+	generateCmd.Flags().IntVar(&minHeaderCount, "min-header-count", 1, "Minimum observations before a header is documented")
+	generateCmd.Flags().Float64Var(&requiredQuery, "required-query-threshold", 0, "Mark query params required when present in at least this fraction of requests (0-1, 0 disables)")
+	generateCmd.Flags().IntVar(&minRequests, "min-requests", 1, "Minimum requests for an endpoint to be documented")
+	generateCmd.Flags().BoolVar(&flagLowSample, "flag-low-sample", false, "Keep endpoints below --min-requests and mark them with x-low-sample")
+	generateCmd.Flags().BoolVar(&pruneNotFound, "prune-not-found", false, "Exclude endpoints whose only responses are 404/405")
+	generateCmd.Flags().StringVar(&selectionPath, "select", "", "Endpoint selection file (as written by explore) choosing endpoints to document")
+	generateCmd.Flags().StringVar(&unmatchedReport, "unmatched-report", "", "Write excluded endpoints to this JSON file")
+	generateCmd.Flags().BoolVar(&apiOnly, "api-only", false, "Skip static assets and page loads (HTML, JS, CSS, images, fonts)")
+	generateCmd.Flags().BoolVar(&ignoreGetDelete, "ignore-get-delete-bodies", false, "Ignore request bodies on GET, HEAD, DELETE, and OPTIONS requests")
+	generateCmd.Flags().StringVar(&noiseFilter, "noise-filter", "off", "Bot/scanner traffic handling: off, drop, or tag")
+	generateCmd.Flags().BoolVar(&noiseEmptyAgent, "noise-empty-agent", false, "With --noise-filter, also treat runs of 404s from requests without a User-Agent as fuzzing")
+	generateCmd.Flags().StringVar(&exampleSelect, "example-select", "first", "Example ordering: first (observation order) or realistic (score by completeness)")
+	generateCmd.Flags().IntVar(&maxExampleLen, "max-example-length", inference.DefaultMaxExampleStringLength, "Truncate string examples longer than this many bytes (0 disables)")
+	generateCmd.Flags().StringArrayVar(&metaFilters, "meta-filter", nil, "Only use records with this metadata as key=value, e.g. environment=staging (can be repeated)")
+	generateCmd.Flags().StringVar(&partitionBy, "partition-by", "", "Write one spec per value of a record label, e.g. label:service, into the --output directory")
+	generateCmd.Flags().StringSliceVar(&metaExtensions, "meta-extension", nil, "Record metadata key to emit as x-meta-<key> operation extensions (can be repeated)")
+	generateCmd.Flags().BoolVar(&keepMediaParams, "keep-media-type-params", false, "Keep content type parameters such as charset in media type keys")
+	generateCmd.Flags().BoolVar(&collapseVendor, "collapse-vendor-types", false, "Map vendor types such as application/vnd.foo+json to their suffix type")
+	generateCmd.Flags().BoolVar(&keepOpPathParams, "keep-operation-path-params", false, "Declare path parameters on each operation instead of hoisting shared ones to the path")
+	generateCmd.Flags().StringVar(&tagGroups, "tag-groups", "", "Emit x-tagGroups (Redoc) grouping tags by resource or host, ordering paths by tag")
+	generateCmd.Flags().IntVar(&invalidExamples, "invalid-examples", 0, "Emit up to this many request bodies answered 400 or 422 per operation as invalid-example request examples (0 disables)")
+	generateCmd.Flags().IntVar(&examplePairs, "example-pairs", 0, "Emit up to this many matching request/response examples per operation and status, taken from the same transactions (0 disables)")
+	generateCmd.Flags().BoolVar(&errorCodeEnum, "error-codes", false, "Declare error codes observed in 4xx/5xx bodies as a shared ErrorCode enum component")
+	generateCmd.Flags().BoolVar(&detectLinks, "links", false, "Emit response links when a response field is later used as another operation's path parameter")
+	generateCmd.Flags().IntVar(&linkMinValues, "link-min-values", inference.DefaultLinkMinValues, "Distinct values a response field must share with a path parameter before --links emits a link")
+	generateCmd.Flags().IntVar(&exampleBudgetMB, "example-memory-mb", 0, "Memory budget in MB for stored body examples (0 for unlimited)")
+	generateCmd.Flags().Float64Var(&requiredHeader, "required-header-threshold", 0, "Mark header params required when present in at least this fraction of requests (0-1, 0 disables)")
+	generateCmd.Flags().BoolVar(&ignoreCase, "case-insensitive-paths", false, "Treat paths differing only in letter case (/Users, /users) as one endpoint")
+	generateCmd.Flags().BoolVar(&ignoreSlash, "ignore-trailing-slash", false, "Treat paths differing only in a trailing slash (/users/, /users) as one endpoint")
+	generateCmd.Flags().BoolVar(&localeSegments, "locale-segments", false, "Document locale path segments (/en-US/, a leading /de/) as a {locale} parameter with an enum of observed values")
+	generateCmd.Flags().BoolVar(&tenantSegments, "tenant-segments", false, "Document slugs after tenant collections (/orgs/acme, /tenants/acme) as parameters with an enum of observed values")
+	generateCmd.Flags().StringSliceVar(&tenants, "tenant", nil, "Tenant slug to document as a {tenant} path parameter wherever it appears (can be repeated)")
+	generateCmd.Flags().BoolVar(&soapOperations, "soap-operations", false, "Document each SOAP action as its own operation (path#Action) instead of one POST per service URL")
+	generateCmd.Flags().BoolVar(&paramStats, "param-stats", false, "Add x-param-stats extensions with the distinct count, numeric range, lengths, and formats of path parameter values")
+	generateCmd.Flags().BoolVar(&crudNames, "crud-names", false, "Name operations by their CRUD action (listUsers, getUser, createUser) with summaries such as \"List users\"")
+	generateCmd.Flags().StringArrayVar(&respDescriptions, "response-description", nil, "Response description for a status or class as status=text, e.g. 404=Resource not found or '5XX={reason} (retry later)' (can be repeated)")
+	generateCmd.Flags().StringArrayVar(&securityRules, "security-rule", nil, "Custom credential documented as an apiKey security scheme, as key=in:name with in header, query, or cookie, e.g. orgToken=header:X-Org-Token (can be repeated)")
+	generateCmd.Flags().StringSliceVar(&commaQuery, "comma-query-param", nil, "Query parameter whose values are comma-separated lists (ids=1,2,3), documented as an array (can be repeated)")
+	generateCmd.Flags().BoolVar(&queryAliases, "query-param-aliases", false, "Document query parameters differing only in case, underscores, or hyphens (pageSize, page_size) as one parameter with x-aliases")
+
+	generateCmd.MarkFlagsMutuallyExclusive("partition-by", "watch")
+	generateCmd.MarkFlagsMutuallyExclusive("partition-by", "versions")
+	generateCmd.MarkFlagsMutuallyExclusive("partition-by", "all-versions")
+	generateCmd.MarkFlagsMutuallyExclusive("partition-by", "unmatched-report")
 
 	if err := generateCmd.MarkFlagRequired("input"); err != nil {
 		panic(fmt.Sprintf("failed to mark input flag required: %v", err))
@@ -114,26 +239,24 @@ func parseTargetVersions(versions []string) ([]convert.TargetVersion, error) {
 		case "3.2", "3.2.0":
 			targets = append(targets, convert.Version320)
 		default:
-			return nil, fmt.Errorf("unsupported version: %s", v)
+			return nil, inputError(fmt.Errorf("unsupported version: %s", v))
 		}
 	}
 	return targets, nil
 }
 
 func doGenerate(cmd *cobra.Command) error {
-	// Validate input exists
-	info, err := os.Stat(inputPath)
+	// Validate flags before reading what may be a large input
+	engineOpts, err := generateEngineOptions()
 	if err != nil {
-		return fmt.Errorf("input path error: %w", err)
+		return err
+	}
+	if err := validateOutputFlags(); err != nil {
+		return err
 	}
 
 	// Read IR records
-	var records []ir.IRRecord
-	if info.IsDir() {
-		records, err = ir.ReadDir(inputPath)
-	} else {
-		records, err = ir.ReadFile(inputPath)
-	}
+	records, err := readIRInput(cmd, inputPath)
 	if err != nil {
 		return fmt.Errorf("reading IR files: %w", err)
 	}
@@ -144,16 +267,39 @@ func doGenerate(cmd *cobra.Command) error {
 
 	cmd.Printf("Read %d IR records\n", len(records))
 
-	// Configure inference engine
-	engineOpts := inference.DefaultEngineOptions()
-	engineOpts.IncludeErrorResponses = includeErrors
+	if partitionBy != "" {
+		return generatePartitions(cmd, records, engineOpts)
+	}
 
 	// Run inference
 	engine := inference.NewEngine(engineOpts)
-	engine.ProcessRecords(records)
+	p := newProgress(cmd, "Inferring", progressRecords, int64(len(records)))
+	for i := range records {
+		engine.ProcessRecord(&records[i])
+		p.Add(1)
+	}
+	p.Done()
 	result := engine.Finalize()
 
+	if memory := engine.ExampleMemory(); memory != nil && memory.Evicted() > 0 {
+		cmd.Printf("Evicted %d examples to stay within the example memory budget\n", memory.Evicted())
+	}
+
+	for reason, count := range result.FilteredRecords {
+		cmd.Printf("Filtered %d records (%s)\n", count, reason)
+	}
+	printPathVariants(cmd, result)
 	cmd.Printf("Inferred %d endpoints\n", len(result.Endpoints))
+	if len(result.Excluded) > 0 {
+		cmd.Printf("Excluded %d endpoints\n", len(result.Excluded))
+	}
+
+	if unmatchedReport != "" {
+		if err := writeUnmatchedReport(unmatchedReport, result); err != nil {
+			return err
+		}
+		cmd.Printf("Wrote unmatched traffic report to %s\n", unmatchedReport)
+	}
 
 	// Check if multi-version output is requested
 	if allVersions || len(openAPIVersions) > 0 {
@@ -164,14 +310,197 @@ func doGenerate(cmd *cobra.Command) error {
 	return doGenerateSingleVersion(cmd, result)
 }
 
-func doGenerateSingleVersion(cmd *cobra.Command, result *inference.InferenceResult) error {
-	// Configure OpenAPI generator
+// generateEngineOptions returns the inference engine options set by flags,
+// or an input error for an invalid flag.
+func generateEngineOptions() (inference.EngineOptions, error) {
+	if partitionBy != "" {
+		if _, err := partitionKey(); err != nil {
+			return inference.EngineOptions{}, err
+		}
+	}
+
+	engineOpts := inference.DefaultEngineOptions()
+	engineOpts.IncludeErrorResponses = includeErrors
+	engineOpts.AllowedHeaders = allowHeaders
+	engineOpts.MinHeaderObservations = minHeaderCount
+	engineOpts.RequiredQueryThreshold = requiredQuery
+	engineOpts.RequiredHeaderThreshold = requiredHeader
+	engineOpts.MinRequestsPerEndpoint = minRequests
+	engineOpts.FlagLowSampleEndpoints = flagLowSample
+	engineOpts.PruneNotFoundEndpoints = pruneNotFound
+	engineOpts.APIOnly = apiOnly
+	engineOpts.IgnoreGetDeleteBodies = ignoreGetDelete
+	engineOpts.MaxExampleStringLength = maxExampleLen
+	engineOpts.ExampleMemoryBudget = int64(exampleBudgetMB) * 1024 * 1024
+	engineOpts.DetectLinks = detectLinks
+	engineOpts.LinkMinValues = linkMinValues
+	engineOpts.ExamplePairs = examplePairs
+	engineOpts.InvalidExamples = invalidExamples
+	engineOpts.SOAPOperations = soapOperations
+	engineOpts.QueryParamAliases = queryAliases
+	engineOpts.CommaQueryParams = commaQuery
+	engineOpts.CaseInsensitivePaths = ignoreCase
+	engineOpts.IgnoreTrailingSlash = ignoreSlash
+	engineOpts.PathSegments = inference.PathSegmentOptions{
+		Locales:           localeSegments,
+		TenantCollections: tenantSegments,
+		Tenants:           tenants,
+	}
+
+	if len(metaFilters) > 0 {
+		filter, err := ir.ParseMetadata(metaFilters)
+		if err != nil {
+			return engineOpts, inputError(err)
+		}
+		engineOpts.MetadataFilter = filter
+	}
+
+	if selectionPath != "" {
+		selection, err := inference.ReadEndpointSelection(selectionPath)
+		if err != nil {
+			return engineOpts, inputError(err)
+		}
+		engineOpts.Selection = selection
+	}
+
+	switch noiseFilter {
+	case "off", "":
+		engineOpts.NoiseMode = inference.NoiseModeOff
+	case "drop":
+		engineOpts.NoiseMode = inference.NoiseModeDrop
+	case "tag":
+		engineOpts.NoiseMode = inference.NoiseModeTag
+	default:
+		return engineOpts, inputError(fmt.Errorf("unsupported noise filter: %s (use off, drop, or tag)", noiseFilter))
+	}
+	engineOpts.NoiseEmptyAgent = noiseEmptyAgent
+
+	switch inference.HeaderMode(headerMode) {
+	case inference.HeaderModeBlacklist, inference.HeaderModeAllowlist, inference.HeaderModeNone:
+		engineOpts.HeaderMode = inference.HeaderMode(headerMode)
+	default:
+		return engineOpts, inputError(fmt.Errorf("unsupported header mode: %s (use blacklist, allowlist, or none)", headerMode))
+	}
+
+	rules, err := parseSecurityRules(securityRules)
+	if err != nil {
+		return engineOpts, err
+	}
+	engineOpts.SecurityRules = rules
+
+	switch exampleSelect {
+	case "realistic":
+		exampleSelection = openapi.ExampleSelectionRealistic
+	case "first", "":
+		exampleSelection = openapi.ExampleSelectionFirst
+	default:
+		return engineOpts, inputError(fmt.Errorf("unsupported example selection: %s (use first or realistic)", exampleSelect))
+	}
+
+	switch tagGroups {
+	case "", "resource", "host":
+	default:
+		return engineOpts, inputError(fmt.Errorf("unsupported tag grouping: %s (use resource or host)", tagGroups))
+	}
+
+	return engineOpts, nil
+}
+
+// validateOutputFlags checks the flags of the spec output, or returns an
+// input error.
+func validateOutputFlags() error {
+	if allVersions || len(openAPIVersions) > 0 {
+		if outputPath == "" {
+			return inputError(fmt.Errorf("--output is required for multi-version output"))
+		}
+		if _, err := parseTargetVersions(openAPIVersions); err != nil {
+			return err
+		}
+		_, err := parseResponseDescriptions(respDescriptions)
+		return err
+	}
+	_, err := generatorOptions()
+	return err
+}
+
+// printPathVariants warns about path spellings merged by
+// --case-insensitive-paths and --ignore-trailing-slash.
+func printPathVariants(cmd *cobra.Command, result *inference.InferenceResult) {
+	templates := make([]string, 0, len(result.PathVariants))
+	for template := range result.PathVariants {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+	for _, template := range templates {
+		cmd.Printf("Warning: merged %s into %s\n", strings.Join(result.PathVariants[template], ", "), template)
+	}
+}
+
+// parseResponseDescriptions parses --response-description values given as
+// status=text, where status is a code (404) or class (4XX).
+func parseResponseDescriptions(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	descriptions := make(map[string]string, len(values))
+	for _, value := range values {
+		status, text, ok := strings.Cut(value, "=")
+		status = strings.ToUpper(strings.TrimSpace(status))
+		if !ok || text == "" || !responseStatusPattern.MatchString(status) {
+			return nil, inputError(fmt.Errorf("invalid --response-description: %s (use status=text, e.g. 404=Not found or 4XX=Client error)", value))
+		}
+		descriptions[status] = text
+	}
+	return descriptions, nil
+}
+
+// parseSecurityRules parses --security-rule values given as key=in:name,
+// such as orgToken=header:X-Org-Token.
+func parseSecurityRules(values []string) ([]inference.SecurityRule, error) {
+	rules := make([]inference.SecurityRule, 0, len(values))
+	for _, value := range values {
+		key, location, _ := strings.Cut(value, "=")
+		in, name, _ := strings.Cut(location, ":")
+		rule := inference.SecurityRule{
+			Key:  strings.TrimSpace(key),
+			In:   strings.ToLower(strings.TrimSpace(in)),
+			Name: strings.TrimSpace(name),
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, inputError(fmt.Errorf("invalid --security-rule %s (use key=in:name, e.g. orgToken=header:X-Org-Token): %w", value, err))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// responseStatusPattern matches the status codes and classes accepted by
+// --response-description.
+var responseStatusPattern = regexp.MustCompile(`^[1-5](\d\d|XX)$`)
+
+// generatorOptions returns the OpenAPI generator options set by flags, for
+// the version set by --version.
+func generatorOptions() (openapi.GeneratorOptions, error) {
 	genOpts := openapi.GeneratorOptions{
-		Title:       apiTitle,
-		Description: apiDescription,
-		APIVersion:  apiVersion,
-		Servers:     servers,
+		Title:                       apiTitle,
+		Description:                 apiDescription,
+		APIVersion:                  apiVersion,
+		Servers:                     servers,
+		ExampleSelection:            exampleSelection,
+		MetadataExtensions:          metaExtensions,
+		KeepMediaTypeParameters:     keepMediaParams,
+		CollapseVendorMediaTypes:    collapseVendor,
+		KeepOperationPathParameters: keepOpPathParams,
+		TagGroups:                   openapi.TagGroupBy(tagGroups),
+		ErrorCodeEnum:               errorCodeEnum,
+		CRUDNames:                   crudNames,
+		ParamStats:                  paramStats,
+	}
+	descriptions, err := parseResponseDescriptions(respDescriptions)
+	if err != nil {
+		return genOpts, err
 	}
+	genOpts.ResponseDescriptions = descriptions
 
 	// Set OpenAPI version
 	switch openAPIVersion {
@@ -182,7 +511,16 @@ func doGenerateSingleVersion(cmd *cobra.Command, result *inference.InferenceResu
 	case "3.2", "3.2.0":
 		genOpts.Version = openapi.Version32
 	default:
-		return fmt.Errorf("unsupported OpenAPI version: %s (use 3.0, 3.1, or 3.2)", openAPIVersion)
+		return genOpts, inputError(fmt.Errorf("unsupported OpenAPI version: %s (use 3.0, 3.1, or 3.2)", openAPIVersion))
+	}
+	return genOpts, nil
+}
+
+func doGenerateSingleVersion(cmd *cobra.Command, result *inference.InferenceResult) error {
+	// Configure OpenAPI generator
+	genOpts, err := generatorOptions()
+	if err != nil {
+		return err
 	}
 
 	// Generate spec
@@ -202,7 +540,6 @@ func doGenerateSingleVersion(cmd *cobra.Command, result *inference.InferenceResu
 	if outputPath == "" {
 		// Write to stdout
 		var output string
-		var err error
 		if format == "json" {
 			output, err = openapi.ToString(spec, openapi.FormatJSON)
 		} else {
@@ -226,7 +563,7 @@ func doGenerateSingleVersion(cmd *cobra.Command, result *inference.InferenceResu
 func doGenerateMultiVersion(cmd *cobra.Command, result *inference.InferenceResult) error {
 	// Require output path for multi-version
 	if outputPath == "" {
-		return fmt.Errorf("--output is required for multi-version output")
+		return inputError(fmt.Errorf("--output is required for multi-version output"))
 	}
 
 	// Determine target versions
@@ -251,12 +588,26 @@ func doGenerateMultiVersion(cmd *cobra.Command, result *inference.InferenceResul
 
 	// Generate base spec (use 3.1 as canonical format)
 	genOpts := openapi.GeneratorOptions{
-		Title:       apiTitle,
-		Description: apiDescription,
-		APIVersion:  apiVersion,
-		Servers:     servers,
-		Version:     openapi.Version31,
+		Title:                       apiTitle,
+		Description:                 apiDescription,
+		APIVersion:                  apiVersion,
+		Servers:                     servers,
+		Version:                     openapi.Version31,
+		ExampleSelection:            exampleSelection,
+		MetadataExtensions:          metaExtensions,
+		KeepMediaTypeParameters:     keepMediaParams,
+		CollapseVendorMediaTypes:    collapseVendor,
+		KeepOperationPathParameters: keepOpPathParams,
+		TagGroups:                   openapi.TagGroupBy(tagGroups),
+		ErrorCodeEnum:               errorCodeEnum,
+		CRUDNames:                   crudNames,
+		ParamStats:                  paramStats,
+	}
+	descriptions, err := parseResponseDescriptions(respDescriptions)
+	if err != nil {
+		return err
 	}
+	genOpts.ResponseDescriptions = descriptions
 	spec := openapi.GenerateFromInference(result, genOpts)
 
 	// Convert to multiple versions
@@ -302,6 +653,18 @@ func doGenerateMultiVersion(cmd *cobra.Command, result *inference.InferenceResul
 	return nil
 }
 
+// writeUnmatchedReport writes endpoints excluded from the spec as a JSON report.
+func writeUnmatchedReport(path string, result *inference.InferenceResult) error {
+	data, err := json.MarshalIndent(result.UnmatchedTraffic(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding unmatched report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing unmatched report: %w", err)
+	}
+	return nil
+}
+
 func getOutputFormat() string {
 	format := outputFormat
 	if format == "" && outputPath != "" {
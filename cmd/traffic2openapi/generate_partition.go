@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// partitionLabelPrefix prefixes the record metadata key given to --partition-by.
+const partitionLabelPrefix = "label:"
+
+// generatePartitions runs inference for each value of the --partition-by
+// label in one pass over the records, and writes a spec per value to the
+// --output directory. The example memory budget is split across the
+// partitions in proportion to their record counts.
+func generatePartitions(cmd *cobra.Command, records []ir.IRRecord, engineOpts inference.EngineOptions) error {
+	key, err := partitionKey()
+	if err != nil {
+		return err
+	}
+	genOpts, err := generatorOptions()
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	labeled := 0
+	for i := range records {
+		if value := records[i].MetaString(key); value != "" {
+			counts[value]++
+			labeled++
+		}
+	}
+	if unlabeled := len(records) - labeled; unlabeled > 0 {
+		cmd.Printf("Skipped %d records without label %s\n", unlabeled, key)
+	}
+	if len(counts) == 0 {
+		return inputError(fmt.Errorf("no records have label %s", key))
+	}
+
+	engines := make(map[string]*inference.Engine, len(counts))
+	for value, count := range counts {
+		opts := engineOpts
+		if opts.ExampleMemoryBudget > 0 {
+			opts.ExampleMemoryBudget = max(1, opts.ExampleMemoryBudget*int64(count)/int64(labeled))
+		}
+		engines[value] = inference.NewEngine(opts)
+	}
+
+	p := newProgress(cmd, "Inferring", progressRecords, int64(len(records)))
+	for i := range records {
+		p.Add(1)
+		if engine, ok := engines[records[i].MetaString(key)]; ok {
+			engine.ProcessRecord(&records[i])
+		}
+	}
+	p.Done()
+
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	values := make([]string, 0, len(engines))
+	for value := range engines {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	ext := "." + getOutputFormat()
+	used := make(map[string]int)
+	evicted := 0
+	filtered := make(map[string]int)
+	for _, value := range values {
+		engine := engines[value]
+		result := engine.Finalize()
+		if memory := engine.ExampleMemory(); memory != nil {
+			evicted += memory.Evicted()
+		}
+		for reason, count := range result.FilteredRecords {
+			filtered[reason] += count
+		}
+		printPathVariants(cmd, result)
+
+		opts := genOpts
+		opts.Title = fmt.Sprintf("%s (%s=%s)", apiTitle, key, value)
+		spec := openapi.GenerateFromInference(result, opts)
+		if !skipValidation {
+			if err := validateSpec(cmd, spec); err != nil {
+				return fmt.Errorf("validation failed for %s=%s: %w", key, value, err)
+			}
+		}
+
+		name := specRouteName(value)
+		used[name]++
+		if n := used[name]; n > 1 {
+			name = fmt.Sprintf("%s-%d", name, n)
+		}
+		path := filepath.Join(outputPath, name+ext)
+		if err := openapi.WriteFile(path, spec); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+		cmd.Printf("Wrote OpenAPI %s spec for %s=%s to %s (%d endpoints)\n", opts.Version, key, value, path, len(result.Endpoints))
+	}
+
+	if evicted > 0 {
+		cmd.Printf("Evicted %d examples to stay within the example memory budget\n", evicted)
+	}
+	reasons := make([]string, 0, len(filtered))
+	for reason := range filtered {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		cmd.Printf("Filtered %d records (%s)\n", filtered[reason], reason)
+	}
+	return nil
+}
+
+// partitionKey returns the record metadata key set by --partition-by.
+func partitionKey() (string, error) {
+	key, ok := strings.CutPrefix(partitionBy, partitionLabelPrefix)
+	if !ok || key == "" {
+		return "", inputError(fmt.Errorf("unsupported --partition-by: %s (use label:<key>, e.g. label:service)", partitionBy))
+	}
+	if outputPath == "" {
+		return "", inputError(fmt.Errorf("--output is required with --partition-by"))
+	}
+	return key, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func writePartitionInput(t *testing.T) string {
+	t.Helper()
+	var records []ir.IRRecord
+	for _, labels := range []map[string]any{
+		{"service": "checkout", "env": "prod"},
+		{"service": "checkout", "env": "staging"},
+		{"service": "payments", "env": "prod"},
+		{"service": "payments", "env": "staging"},
+		{"env": "prod"},
+	} {
+		record := ir.NewRecord(ir.RequestMethodGET, "/"+labels["env"].(string), 200)
+		for key, value := range labels {
+			record.SetMeta(key, value)
+		}
+		records = append(records, *record)
+	}
+	path := filepath.Join(t.TempDir(), "traffic.ndjson")
+	if err := ir.WriteFile(path, records); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGeneratePartitions(t *testing.T) {
+	input := writePartitionInput(t)
+	output := t.TempDir()
+	out, err := runCLI(t, "generate", "-i", input, "-o", output,
+		"--partition-by", "label:service", "--meta-filter", "env=prod", "--example-memory-mb", "1")
+	if err != nil {
+		t.Fatalf("generate failed: %v\n%s", err, out)
+	}
+
+	for _, want := range []string{
+		"Skipped 1 records without label service",
+		"Filtered 2 records (metadata)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	for _, name := range []string{"checkout.yaml", "payments.yaml"} {
+		if _, err := os.Stat(filepath.Join(output, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+}
+
+func TestGeneratePartitionsRejectsUnmatchedReport(t *testing.T) {
+	input := writePartitionInput(t)
+	dir := t.TempDir()
+	_, err := runCLI(t, "generate", "-i", input, "-o", dir,
+		"--partition-by", "label:service", "--unmatched-report", filepath.Join(dir, "unmatched.json"))
+	if err == nil {
+		t.Fatal("expected --unmatched-report to be rejected with --partition-by")
+	}
+}
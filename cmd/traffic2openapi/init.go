@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Scaffold a traffic capture setup",
+	Long: `Scaffold the files needed to start capturing traffic.
+
+Modes:
+  transport   capture.json with LoggingTransport options and capture.go,
+              wiring an http.Client to log outgoing requests
+  middleware  capture.json and capture.go, registering HTTP server
+              middleware that logs incoming requests
+  proxy       docker-compose.yml running a mitmproxy proxy that records
+              traffic to a HAR file for convert har
+
+The Go snippets are package main; adapt the package name and call them from
+the application. Existing files are not overwritten unless --force is set.
+
+Examples:
+  # Capture an http.Client's traffic
+  traffic2openapi init --mode transport
+
+  # Capture a server's traffic into ./capture/
+  traffic2openapi init --mode middleware --dir ./capture/
+
+  # Capture through a proxy listening on port 9090
+  traffic2openapi init --mode proxy --port 9090`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+var (
+	initMode    string
+	initDir     string
+	initCapture string
+	initPort    int
+	initForce   bool
+)
+
+// Capture setups scaffolded by init.
+const (
+	initModeTransport  = "transport"
+	initModeMiddleware = "middleware"
+	initModeProxy      = "proxy"
+)
+
+//go:embed scaffold
+var scaffoldFiles embed.FS
+
+// scaffoldFile is a scaffold template and the file it is written to.
+type scaffoldFile struct {
+	template string
+	name     string
+}
+
+// initScaffolds lists the files written for each mode.
+var initScaffolds = map[string][]scaffoldFile{
+	initModeTransport: {
+		{"scaffold/capture.json", "capture.json"},
+		{"scaffold/transport/capture.go.tmpl", "capture.go"},
+	},
+	initModeMiddleware: {
+		{"scaffold/capture.json", "capture.json"},
+		{"scaffold/middleware/capture.go.tmpl", "capture.go"},
+	},
+	initModeProxy: {
+		{"scaffold/proxy/docker-compose.yml.tmpl", "docker-compose.yml"},
+	},
+}
+
+// scaffoldData is the data the scaffold templates are executed with.
+type scaffoldData struct {
+	Config  string
+	Capture string
+	Source  ir.IRRecordSource
+	Port    int
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringVarP(&initMode, "mode", "m", "", "Capture setup: transport, middleware, or proxy (required)")
+	initCmd.Flags().StringVarP(&initDir, "dir", "d", ".", "Directory to write the files to")
+	initCmd.Flags().StringVar(&initCapture, "capture", "traffic.ndjson", "IR capture file the setup writes to")
+	initCmd.Flags().IntVar(&initPort, "port", 8080, "Host port for the proxy (proxy mode)")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite existing files")
+
+	_ = initCmd.MarkFlagRequired("mode")
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	files, ok := initScaffolds[initMode]
+	if !ok {
+		return inputError(fmt.Errorf("invalid mode %q: use transport, middleware, or proxy", initMode))
+	}
+	if initPort < 1 || initPort > 65535 {
+		return inputError(fmt.Errorf("invalid port %d", initPort))
+	}
+
+	data := scaffoldData{
+		Config:  "capture.json",
+		Capture: initCapture,
+		Source:  ir.IRRecordSourceLoggingTransport,
+		Port:    initPort,
+	}
+
+	// Render everything and check for existing files before writing any.
+	rendered := make([][]byte, len(files))
+	for i, f := range files {
+		out, err := renderScaffold(f.template, data)
+		if err != nil {
+			return err
+		}
+		rendered[i] = out
+
+		path := filepath.Join(initDir, f.name)
+		if _, err := os.Stat(path); err == nil && !initForce {
+			return inputError(fmt.Errorf("%s already exists (use --force to overwrite)", path))
+		}
+	}
+
+	if err := os.MkdirAll(initDir, 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	for i, f := range files {
+		path := filepath.Join(initDir, f.name)
+		if err := os.WriteFile(path, rendered[i], 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		cmd.Printf("Wrote %s\n", path)
+	}
+
+	cmd.Printf("\nNext steps:\n")
+	switch initMode {
+	case initModeTransport:
+		cmd.Printf("  1. Call newCaptureClient in capture.go and use the client for API requests\n")
+	case initModeMiddleware:
+		cmd.Printf("  1. Wrap the server's handler with captureMiddleware in capture.go\n")
+	case initModeProxy:
+		cmd.Printf("  1. Run docker compose up -d in %s and set HTTPS_PROXY=http://localhost:%d\n", initDir, initPort)
+		cmd.Printf("  2. Stop the proxy and run: traffic2openapi convert har -i captures/capture.har -o %s\n", initCapture)
+		cmd.Printf("  3. Run: traffic2openapi generate -i %s -o openapi.yaml\n", initCapture)
+		return nil
+	}
+	cmd.Printf("  2. Exercise the API, then run: traffic2openapi generate -i %s -o openapi.yaml\n", initCapture)
+	return nil
+}
+
+// renderScaffold executes an embedded scaffold template.
+func renderScaffold(name string, data scaffoldData) ([]byte, error) {
+	text, err := scaffoldFiles.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("reading scaffold %s: %w", name, err)
+	}
+	tmpl, err := template.New(filepath.Base(name)).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("parsing scaffold %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("rendering scaffold %s: %w", name, err)
+	}
+	return []byte(strings.TrimLeft(buf.String(), "\n")), nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+// irTail reads the records added to an IR input since the previous read.
+// NDJSON files are read from where the previous read stopped, so records
+// appended by a capture pipeline are read once. Other changes, such as a
+// truncated NDJSON file or a rewritten batch file, are reported so the caller
+// can start over.
+type irTail struct {
+	cmd   *cobra.Command
+	input string
+	files map[string]watchedFile
+}
+
+// watchedFile is the part of an input file that has been processed.
+type watchedFile struct {
+	offset  int64 // NDJSON bytes processed
+	size    int64
+	modTime time.Time
+}
+
+func newIRTail(cmd *cobra.Command, input string) *irTail {
+	t := &irTail{cmd: cmd, input: input}
+	t.reset()
+	return t
+}
+
+// reset forgets what has been read, so the next read returns every record.
+func (t *irTail) reset() {
+	t.files = make(map[string]watchedFile)
+}
+
+// next returns the records not read yet. It stops and reports rebuild when a
+// file already read changed other than by appending; the caller should then
+// reset and read the whole input again.
+func (t *irTail) next() ([]ir.IRRecord, bool, error) {
+	paths, err := irInputFiles(t.input)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var all []ir.IRRecord
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			// Removed since it was listed
+			continue
+		}
+		prev, seen := t.files[path]
+		if seen && info.Size() == prev.size && info.ModTime().Equal(prev.modTime) {
+			continue
+		}
+
+		var records []ir.IRRecord
+		offset := prev.offset
+		if strings.EqualFold(filepath.Ext(path), ".ndjson") {
+			if info.Size() < prev.offset {
+				return nil, true, nil
+			}
+			records, offset, err = t.readAppended(path, prev.offset, info.Size())
+		} else {
+			if seen {
+				return nil, true, nil
+			}
+			records, err = ir.ReadFile(path)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("reading %s: %w", path, err)
+		}
+
+		all = append(all, records...)
+		t.files[path] = watchedFile{offset: offset, size: info.Size(), modTime: info.ModTime()}
+	}
+	return all, false, nil
+}
+
+// readAppended reads the complete NDJSON lines of path between offset and
+// size. It returns the records and the offset after the last complete line;
+// a partly written last line is read once it is complete.
+func (t *irTail) readAppended(path string, offset, size int64) ([]ir.IRRecord, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset, fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+
+	data := make([]byte, size-offset)
+	if _, err := f.ReadAt(data, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, offset, fmt.Errorf("reading file: %w", err)
+	}
+	end := bytes.LastIndexByte(data, '\n') + 1
+	data = data[:end]
+
+	var records []ir.IRRecord
+	reader := ir.NewNDJSONReader(bytes.NewReader(data))
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var invalid *ir.ErrInvalidRecord
+		if errors.As(err, &invalid) {
+			t.cmd.Printf("Warning: skipping invalid record in %s: %v\n", path, err)
+			continue
+		}
+		if err != nil {
+			return nil, offset, err
+		}
+		records = append(records, *record)
+	}
+	return records, offset + int64(end), nil
+}
+
+// irInputFiles returns the IR files read from an input: the file itself, or
+// the .json and .ndjson files of a directory.
+func irInputFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, inputError(fmt.Errorf("input path error: %w", err))
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, inputError(fmt.Errorf("reading directory: %w", err))
+	}
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".json" && ext != ".ndjson") {
+			continue
+		}
+		files = append(files, filepath.Join(path, entry.Name()))
+	}
+	return files, nil
+}
+
+// watchIRInput calls update, debounced, whenever an IR file of input is
+// written or created. It runs until the watcher is closed.
+func watchIRInput(cmd *cobra.Command, input string, debounce time.Duration, update func() error) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("input path error: %w", err)
+	}
+	if err := watcher.Add(input); err != nil {
+		return fmt.Errorf("adding input to watcher: %w", err)
+	}
+	if info.IsDir() {
+		cmd.Printf("Watching directory: %s\n", input)
+	} else {
+		cmd.Printf("Watching file: %s\n", input)
+	}
+
+	// Debounce timer
+	var debounceTimer *time.Timer
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			// Only react to write/create events
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+
+			// Skip non-IR files
+			ext := strings.ToLower(filepath.Ext(event.Name))
+			if ext != ".json" && ext != ".ndjson" {
+				continue
+			}
+
+			// Debounce updates
+			if debounceTimer != nil {
+				debounceTimer.Stop()
+			}
+			debounceTimer = time.AfterFunc(debounce, func() {
+				if err := update(); err != nil {
+					cmd.Printf("Update failed: %v\n", err)
+				}
+			})
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			cmd.Printf("Watcher error: %v\n", err)
+		}
+	}
+}
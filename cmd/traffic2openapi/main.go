@@ -1,11 +1,15 @@
 package main
 
 import (
+	"fmt"
 	"os"
 )
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	registerCompletions()
+	err := rootCmd.Execute()
+	if msg := errorMessage(err); msg != "" {
+		fmt.Fprintln(os.Stderr, "Error:", msg)
 	}
+	os.Exit(exitCode(err))
 }
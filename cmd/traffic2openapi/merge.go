@@ -1,10 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/grokify/traffic2openapi/pkg/ir"
 	"github.com/grokify/traffic2openapi/pkg/openapi"
@@ -18,6 +20,9 @@ var mergeCmd = &cobra.Command{
 
 For IR files (.ndjson, .json), records are combined with optional deduplication.
 For OpenAPI specs (.yaml, .yml, .json), paths and components are merged.
+Operations, components, and path-level fields (parameters, servers, and
+so on) defined differently by more than one spec are listed in a conflict
+report and resolved per --strategy.
 
 Examples:
   # Merge multiple traffic files
@@ -29,23 +34,68 @@ Examples:
   # Merge with deduplication by record ID
   traffic2openapi merge -i traffic1.ndjson -i traffic2.ndjson -o combined.ndjson --dedupe
 
+  # Drop requests captured by both a browser and a server log
+  traffic2openapi merge -i browser.ndjson -i server.ndjson -o combined.ndjson --dedupe-by semantic
+
+  # Sort the merged records chronologically
+  traffic2openapi merge -i browser.ndjson -i server.ndjson -o combined.ndjson --sort-by timestamp
+
+  # Normalize timestamps to UTC, correcting a server clock that is 90s behind
+  traffic2openapi merge -i browser.ndjson -i server.ndjson -o combined.ndjson --utc \
+    --time-offset server.ndjson=90s
+
   # Merge OpenAPI specs
-  traffic2openapi merge -i api-v1.yaml -i api-v2.yaml -o merged.yaml`,
+  traffic2openapi merge -i api-v1.yaml -i api-v2.yaml -o merged.yaml
+
+  # Merge specs, combining operations and schemas both define differently
+  traffic2openapi merge -i api-v1.yaml -i api-v2.yaml -o merged.yaml --strategy deep-merge`,
 	RunE: runMerge,
 }
 
 var (
-	mergeInputs []string
-	mergeOutput string
-	mergeDedupe bool
+	mergeInputs        []string
+	mergeOutput        string
+	mergeDedupe        bool
+	mergeUTC           bool
+	mergeTimeOffsets   []string
+	mergeDetectSkew    bool
+	mergeSkewThreshold time.Duration
+	mergeSkewMinMatch  int
+	mergeSortBy        string
+	mergeSortChunkSize int
+	mergeDedupeBy      string
+	mergeDedupeWindow  time.Duration
+	mergeStrategy      string
+	mergeIndex         bool
+)
+
+// Values of --dedupe-by.
+const (
+	mergeDedupeID       = "id"
+	mergeDedupeSemantic = "semantic"
 )
 
+// mergeSortTimestamp is the --sort-by value that orders records by timestamp.
+const mergeSortTimestamp = "timestamp"
+
 func init() {
 	rootCmd.AddCommand(mergeCmd)
 
 	mergeCmd.Flags().StringArrayVarP(&mergeInputs, "input", "i", nil, "Input files or directories (can be repeated)")
 	mergeCmd.Flags().StringVarP(&mergeOutput, "output", "o", "", "Output file path (required)")
 	mergeCmd.Flags().BoolVar(&mergeDedupe, "dedupe", false, "Deduplicate records by ID")
+	mergeCmd.Flags().BoolVar(&mergeIndex, "index", false, "Write a sidecar record ID index next to NDJSON output (used by show)")
+	mergeCmd.Flags().StringVar(&mergeDedupeBy, "dedupe-by", mergeDedupeID, "Deduplication key: id, or semantic to also match method, path, query, body hash, and timestamp bucket (implies --dedupe)")
+	mergeCmd.Flags().DurationVar(&mergeDedupeWindow, "dedupe-window", ir.DefaultDedupeWindow, "Timestamp bucket width for --dedupe-by semantic")
+	mergeCmd.Flags().BoolVar(&mergeUTC, "utc", false, "Normalize record timestamps to UTC")
+	mergeCmd.Flags().StringArrayVar(&mergeTimeOffsets, "time-offset", nil, "Clock correction for an input as input=duration, e.g. server.ndjson=-1h30s (can be repeated, implies --utc)")
+	mergeCmd.Flags().BoolVar(&mergeDetectSkew, "detect-skew", true, "Warn about clock skew between inputs (keeps a timestamp per record in memory; disable for very large inputs)")
+	mergeCmd.Flags().DurationVar(&mergeSkewThreshold, "skew-threshold", ir.DefaultClockSkewThreshold, "Warn when inputs capturing the same traffic disagree by more than this")
+	mergeCmd.Flags().IntVar(&mergeSkewMinMatch, "skew-min-matches", ir.DefaultClockSkewMinMatches, "Records two inputs must have in common before their clocks are compared")
+	mergeCmd.Flags().StringVar(&mergeSortBy, "sort-by", "", "Sort merged records: timestamp (default: input order)")
+	mergeCmd.Flags().IntVar(&mergeSortChunkSize, "sort-chunk-size", ir.DefaultSortChunkSize, "Records sorted in memory before spilling to a temporary file")
+	mergeCmd.Flags().StringVar(&mergeStrategy, "strategy", string(openapi.MergeFirstWins), "Resolve spec conflicts: first-wins, last-wins, deep-merge, or fail")
+	addResolveRefsFlag(mergeCmd)
 
 	if err := mergeCmd.MarkFlagRequired("input"); err != nil {
 		panic(fmt.Sprintf("failed to mark input flag required: %v", err))
@@ -77,125 +127,206 @@ func runMerge(cmd *cobra.Command, args []string) error {
 }
 
 func mergeIRFiles(cmd *cobra.Command) error {
-	var allRecords []ir.IRRecord
-	seenIDs := make(map[string]bool)
+	if mergeSortBy != "" && mergeSortBy != mergeSortTimestamp {
+		return inputError(fmt.Errorf("unsupported sort: %s (use %s)", mergeSortBy, mergeSortTimestamp))
+	}
+	if mergeDedupeBy != mergeDedupeID && mergeDedupeBy != mergeDedupeSemantic {
+		return inputError(fmt.Errorf("unsupported dedupe key: %s (use %s or %s)", mergeDedupeBy, mergeDedupeID, mergeDedupeSemantic))
+	}
+	if cmd.Flags().Changed("dedupe-by") {
+		mergeDedupe = true
+	}
+	offsets, err := parseTimeOffsets(mergeTimeOffsets, mergeInputs)
+	if err != nil {
+		return err
+	}
 
-	for _, input := range mergeInputs {
-		info, err := os.Stat(input)
-		if err != nil {
-			return fmt.Errorf("input path error for %s: %w", input, err)
+	// Records are streamed from each input; with --sort-by they go through
+	// the sorter, which spills sorted runs to disk, and otherwise straight
+	// to the output
+	var sorter *ir.TimestampSorter
+	var out ir.IRWriter
+	if mergeSortBy == mergeSortTimestamp {
+		sorter = ir.NewTimestampSorter(mergeSortChunkSize, "")
+		defer sorter.Close()
+	} else {
+		if out, err = createIRFile(mergeOutput, mergeIndex); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
+	written := false
+	defer func() {
+		if out != nil && !written {
+			discardIRFile(out, mergeOutput)
 		}
+	}()
 
-		var records []ir.IRRecord
-		if info.IsDir() {
-			records, err = ir.ReadDir(input)
-		} else {
-			records, err = ir.ReadFile(input)
+	var semantic *ir.SemanticDeduper
+	if mergeDedupe && mergeDedupeBy == mergeDedupeSemantic {
+		semantic = ir.NewSemanticDeduper(mergeDedupeWindow)
+	}
+
+	// Clock skew detection indexes every record, so it can be turned off
+	var detector *ir.ClockSkewDetector
+	if mergeDetectSkew {
+		detector = ir.NewClockSkewDetector(mergeSkewThreshold, mergeSkewMinMatch)
+	}
+	normalize := mergeUTC || len(offsets) > 0
+	total, duplicates := 0, 0
+	seenIDs := make(map[string]bool)
+	for i, input := range mergeInputs {
+		source := 0
+		if detector != nil {
+			source = detector.AddSource(input, offsets[input])
 		}
+		read := 0
+		err := eachIRInput(cmd, input, func(rec *ir.IRRecord) error {
+			read++
+			if detector != nil {
+				detector.Add(source, rec)
+			}
+			if normalize {
+				ir.NormalizeTimestamp(rec, offsets[input])
+			}
+
+			// Add records with optional deduplication
+			if isMergeDuplicate(rec, i, seenIDs, semantic) {
+				duplicates++
+				return nil
+			}
+			total++
+			if sorter != nil {
+				if err := sorter.Add(*rec); err != nil {
+					return fmt.Errorf("sorting records: %w", err)
+				}
+				return nil
+			}
+			if err := out.Write(rec); err != nil {
+				return fmt.Errorf("writing output: %w", err)
+			}
+			return nil
+		})
 		if err != nil {
 			return fmt.Errorf("reading %s: %w", input, err)
 		}
 
-		cmd.Printf("Read %d records from %s\n", len(records), input)
+		cmd.Printf("Read %d records from %s\n", read, input)
+	}
 
-		// Add records with optional deduplication
-		for _, rec := range records {
-			if mergeDedupe && rec.Id != nil {
-				if seenIDs[*rec.Id] {
-					continue
-				}
-				seenIDs[*rec.Id] = true
-			}
-			allRecords = append(allRecords, rec)
+	// Warn about inputs whose clocks disagree, after applying corrections
+	if detector != nil {
+		for _, skew := range detector.Skews() {
+			cmd.Printf("Warning: clock skew: %s; use --time-offset %s=%s to correct\n",
+				skew, skew.Other, offsets[skew.Other]+skew.Correction())
 		}
 	}
 
-	if len(allRecords) == 0 {
+	if total == 0 {
 		return fmt.Errorf("no records found in inputs")
 	}
 
 	// Write merged records
-	if err := ir.WriteFile(mergeOutput, allRecords); err != nil {
+	if sorter != nil {
+		if out, err = createIRFile(mergeOutput, mergeIndex); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+		if _, err := sorter.WriteTo(out); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
+	written = true
+	if err := out.Close(); err != nil {
 		return fmt.Errorf("writing output: %w", err)
 	}
 
-	cmd.Printf("Wrote %d records to %s\n", len(allRecords), mergeOutput)
+	cmd.Printf("Wrote %d records to %s\n", total, mergeOutput)
 	if mergeDedupe {
-		cmd.Printf("Deduplicated %d duplicate records\n", countDuplicates(mergeInputs)-len(allRecords))
+		cmd.Printf("Deduplicated %d duplicate records\n", duplicates)
 	}
 
 	return nil
 }
 
-func countDuplicates(inputs []string) int {
-	total := 0
+// parseTimeOffsets parses input=duration clock corrections, keyed by input.
+func parseTimeOffsets(values, inputs []string) (map[string]time.Duration, error) {
+	known := make(map[string]bool, len(inputs))
 	for _, input := range inputs {
-		info, err := os.Stat(input)
+		known[input] = true
+	}
+
+	offsets := make(map[string]time.Duration, len(values))
+	for _, value := range values {
+		input, durationStr, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, inputError(fmt.Errorf("invalid time offset: %s (use input=duration)", value))
+		}
+		if !known[input] {
+			return nil, inputError(fmt.Errorf("time offset for unknown input: %s", input))
+		}
+		offset, err := time.ParseDuration(durationStr)
 		if err != nil {
-			continue
+			return nil, inputError(fmt.Errorf("invalid time offset for %s: %w", input, err))
 		}
-		var records []ir.IRRecord
-		if info.IsDir() {
-			records, _ = ir.ReadDir(input)
-		} else {
-			records, _ = ir.ReadFile(input)
+		offsets[input] = offset
+	}
+	return offsets, nil
+}
+
+// isMergeDuplicate reports whether a record from the source with the given
+// index duplicates an earlier one by record ID or, when semantic is set, by
+// semantic key.
+func isMergeDuplicate(rec *ir.IRRecord, source int, seenIDs map[string]bool, semantic *ir.SemanticDeduper) bool {
+	if !mergeDedupe {
+		return false
+	}
+	if rec.Id != nil {
+		if seenIDs[*rec.Id] {
+			return true
 		}
-		total += len(records)
+		seenIDs[*rec.Id] = true
 	}
-	return total
+	return semantic != nil && semantic.Duplicate(rec, source)
 }
 
 func mergeOpenAPISpecs(cmd *cobra.Command) error {
-	var mergedSpec *openapi.Spec
+	strategy := openapi.MergeStrategy(mergeStrategy)
+	if !slices.Contains(openapi.MergeStrategies, strategy) {
+		return inputError(fmt.Errorf("unsupported strategy: %s (use first-wins, last-wins, deep-merge, or fail)", mergeStrategy))
+	}
 
+	merger := openapi.NewSpecMerger(strategy)
 	for _, input := range mergeInputs {
-		spec, err := openapi.ReadFile(input)
+		spec, err := readSpec(input)
 		if err != nil {
 			return fmt.Errorf("reading %s: %w", input, err)
 		}
 
 		cmd.Printf("Read spec from %s (%d paths)\n", input, len(spec.Paths))
 
-		if mergedSpec == nil {
-			mergedSpec = spec
-			continue
-		}
-
-		// Merge paths
-		for path, pathItem := range spec.Paths {
-			if existing, ok := mergedSpec.Paths[path]; ok {
-				// Merge operations
-				mergePathItem(existing, pathItem)
-			} else {
-				mergedSpec.Paths[path] = pathItem
-			}
-		}
-
-		// Merge servers (dedupe by URL)
-		serverURLs := make(map[string]bool)
-		for _, s := range mergedSpec.Servers {
-			serverURLs[s.URL] = true
-		}
-		for _, s := range spec.Servers {
-			if !serverURLs[s.URL] {
-				mergedSpec.Servers = append(mergedSpec.Servers, s)
-				serverURLs[s.URL] = true
-			}
-		}
-
-		// Merge components
-		if spec.Components != nil {
-			if mergedSpec.Components == nil {
-				mergedSpec.Components = &openapi.Components{}
-			}
-			mergeComponents(mergedSpec.Components, spec.Components)
+		// With --strategy fail, report every conflict before failing
+		var conflictErr *openapi.MergeConflictError
+		if err := merger.Add(input, spec); err != nil && !errors.As(err, &conflictErr) {
+			return fmt.Errorf("merging %s: %w", input, err)
 		}
 	}
 
+	mergedSpec := merger.Spec()
 	if mergedSpec == nil {
 		return fmt.Errorf("no specs found in inputs")
 	}
 
+	conflicts := merger.Conflicts()
+	if len(conflicts) > 0 {
+		cmd.Printf("Conflicts (%d):\n", len(conflicts))
+		for _, c := range conflicts {
+			cmd.Printf("  %s\n", c)
+		}
+		if strategy == openapi.MergeFail {
+			return inputError(&openapi.MergeConflictError{Conflicts: conflicts})
+		}
+		cmd.Printf("Resolved conflicts with --strategy %s\n", strategy)
+	}
+
 	// Write merged spec
 	if err := openapi.WriteFile(mergeOutput, mergedSpec); err != nil {
 		return fmt.Errorf("writing output: %w", err)
@@ -205,65 +336,3 @@ func mergeOpenAPISpecs(cmd *cobra.Command) error {
 
 	return nil
 }
-
-func mergePathItem(target, source *openapi.PathItem) {
-	if source.Get != nil && target.Get == nil {
-		target.Get = source.Get
-	}
-	if source.Post != nil && target.Post == nil {
-		target.Post = source.Post
-	}
-	if source.Put != nil && target.Put == nil {
-		target.Put = source.Put
-	}
-	if source.Delete != nil && target.Delete == nil {
-		target.Delete = source.Delete
-	}
-	if source.Patch != nil && target.Patch == nil {
-		target.Patch = source.Patch
-	}
-	if source.Head != nil && target.Head == nil {
-		target.Head = source.Head
-	}
-	if source.Options != nil && target.Options == nil {
-		target.Options = source.Options
-	}
-	if source.Trace != nil && target.Trace == nil {
-		target.Trace = source.Trace
-	}
-}
-
-func mergeComponents(target, source *openapi.Components) {
-	if source.Schemas != nil {
-		if target.Schemas == nil {
-			target.Schemas = make(map[string]*openapi.Schema)
-		}
-		for name, schema := range source.Schemas {
-			if _, exists := target.Schemas[name]; !exists {
-				target.Schemas[name] = schema
-			}
-		}
-	}
-
-	if source.SecuritySchemes != nil {
-		if target.SecuritySchemes == nil {
-			target.SecuritySchemes = make(map[string]*openapi.SecurityScheme)
-		}
-		for name, scheme := range source.SecuritySchemes {
-			if _, exists := target.SecuritySchemes[name]; !exists {
-				target.SecuritySchemes[name] = scheme
-			}
-		}
-	}
-
-	if source.Parameters != nil {
-		if target.Parameters == nil {
-			target.Parameters = make(map[string]*openapi.Parameter)
-		}
-		for name, param := range source.Parameters {
-			if _, exists := target.Parameters[name]; !exists {
-				target.Parameters[name] = param
-			}
-		}
-	}
-}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// runCLI runs the CLI with args and returns its output. The flags of the
+// commands run are reset afterwards, so tests don't leak flag values.
+func runCLI(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(args)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+		resetFlags(rootCmd)
+	}()
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			var values []string
+			if def := strings.Trim(f.DefValue, "[]"); def != "" {
+				values = strings.Split(def, ",")
+			}
+			_ = slice.Replace(values)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// writeMergeInput writes records for paths /items/0.. with timestamps start,
+// start+step, ... and IDs prefix-0.. to path.
+func writeMergeInput(t *testing.T, path, prefix string, n int, start time.Time, step time.Duration) {
+	t.Helper()
+	w, err := createIRFile(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		record := ir.NewRecord(ir.RequestMethodGET, fmt.Sprintf("/items/%d", i), 200).
+			SetID(fmt.Sprintf("%s-%d", prefix, i)).
+			SetTimestamp(start.Add(time.Duration(i) * step))
+		if err := w.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMergeSortedSpillsRuns(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	a := filepath.Join(dir, "a.ndjson")
+	b := filepath.Join(dir, "b.ndjson.gz")
+	writeMergeInput(t, a, "a", 30, start, 2*time.Second)
+	writeMergeInput(t, b, "b", 30, start.Add(time.Second), 2*time.Second)
+
+	// Far more records than the sort chunk size, so sorted runs are spilled
+	// and merged; batch output is streamed from the merge
+	output := filepath.Join(dir, "merged.json")
+	out, err := runCLI(t, "merge", "-i", a, "-i", b, "-o", output,
+		"--sort-by", "timestamp", "--sort-chunk-size", "4")
+	if err != nil {
+		t.Fatalf("merge failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Wrote 60 records") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	records, err := ir.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 60 {
+		t.Fatalf("expected 60 records, got %d", len(records))
+	}
+	for i := range records {
+		want := start.Add(time.Duration(i) * time.Second)
+		if records[i].Timestamp == nil || !records[i].Timestamp.Equal(want) {
+			t.Fatalf("record %d: expected timestamp %v, got %v", i, want, records[i].Timestamp)
+		}
+	}
+}
+
+func TestMergeStreamsInInputOrder(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	a := filepath.Join(dir, "a.ndjson")
+	b := filepath.Join(dir, "b.ndjson")
+	writeMergeInput(t, a, "a", 10, start.Add(time.Hour), time.Second)
+	// b repeats a's IDs with a clock 90s behind
+	writeMergeInput(t, b, "a", 10, start.Add(time.Hour-90*time.Second), time.Second)
+
+	output := filepath.Join(dir, "merged.ndjson")
+	out, err := runCLI(t, "merge", "-i", a, "-i", b, "-o", output, "--dedupe")
+	if err != nil {
+		t.Fatalf("merge failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Warning: clock skew") || !strings.Contains(out, b+"=1m30s") {
+		t.Errorf("expected clock skew warning, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Deduplicated 10 duplicate records") {
+		t.Errorf("expected 10 duplicates, got:\n%s", out)
+	}
+
+	records, err := ir.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 10 || *records[0].Id != "a-0" || *records[9].Id != "a-9" {
+		t.Errorf("unexpected merged records: %d", len(records))
+	}
+}
+
+func TestMergeWithoutSkewDetection(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	a := filepath.Join(dir, "a.ndjson")
+	b := filepath.Join(dir, "b.ndjson")
+	writeMergeInput(t, a, "a", 10, start.Add(time.Hour), time.Second)
+	writeMergeInput(t, b, "a", 10, start.Add(time.Hour-90*time.Second), time.Second)
+
+	out, err := runCLI(t, "merge", "-i", a, "-i", b, "-o", filepath.Join(dir, "merged.ndjson"), "--detect-skew=false")
+	if err != nil {
+		t.Fatalf("merge failed: %v\n%s", err, out)
+	}
+	if strings.Contains(out, "clock skew") {
+		t.Errorf("expected no clock skew warning, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Wrote 20 records") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestMergeNoRecordsRemovesOutput(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "empty.ndjson")
+	if err := os.WriteFile(input, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "merged.ndjson")
+	if _, err := runCLI(t, "merge", "-i", input, "-o", output); err == nil {
+		t.Fatal("expected an error for inputs without records")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("expected no output file, got %v", err)
+	}
+}
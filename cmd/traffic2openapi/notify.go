@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/cireport"
+	"github.com/spf13/cobra"
+)
+
+// Payload formats accepted by --notify-format.
+const (
+	notifyFormatJSON  = "json"
+	notifyFormatSlack = "slack"
+)
+
+// notifyMaxLines is the number of findings listed in a Slack message; the
+// rest are summarized in a final line.
+const notifyMaxLines = 20
+
+var (
+	notifyURL    string
+	notifyFormat string
+
+	notifyClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// addNotifyFlags adds --notify and --notify-format to a command that reports
+// API changes or drift.
+func addNotifyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&notifyURL, "notify", "", "POST a notification of changes or drift found to this webhook URL")
+	cmd.Flags().StringVar(&notifyFormat, "notify-format", notifyFormatJSON, "Notification payload: json or slack (Slack-compatible incoming webhook)")
+}
+
+// checkNotifyFlags validates --notify-format.
+func checkNotifyFlags() error {
+	if notifyFormat != notifyFormatJSON && notifyFormat != notifyFormatSlack {
+		return inputError(fmt.Errorf("unsupported notify format: %s (use json or slack)", notifyFormat))
+	}
+	return nil
+}
+
+// notification is the generic JSON payload posted to --notify.
+type notification struct {
+	Command  string   `json:"command"` // reporting command, e.g. "diff"
+	Title    string   `json:"title"`
+	Breaking bool     `json:"breaking"`
+	Findings []string `json:"findings,omitempty"`
+	Report   any      `json:"report,omitempty"` // the command's JSON output
+}
+
+// findingMessages returns the messages of the findings in a report, errors
+// first.
+func findingMessages(report *cireport.Report) []string {
+	var failures, others []string
+	for _, check := range report.Checks {
+		switch check.Level {
+		case cireport.LevelPass:
+		case cireport.LevelError:
+			failures = append(failures, check.Message)
+		default:
+			others = append(others, check.Message)
+		}
+	}
+	return append(failures, others...)
+}
+
+// slackPayload formats a notification as a Slack incoming webhook message,
+// also accepted by Mattermost, Rocket.Chat, and Discord's /slack endpoint.
+func (n notification) slackPayload() map[string]string {
+	marker := ":warning:"
+	if n.Breaking {
+		marker = ":rotating_light:"
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s *%s*", marker, n.Title)
+	for i, finding := range n.Findings {
+		if i == notifyMaxLines {
+			fmt.Fprintf(&text, "\n…and %d more", len(n.Findings)-i)
+			break
+		}
+		fmt.Fprintf(&text, "\n• %s", finding)
+	}
+	return map[string]string{"text": text.String()}
+}
+
+// notify posts n to --notify, if set. A failed delivery is reported as a
+// warning so it doesn't change the command's outcome.
+func notify(cmd *cobra.Command, n notification) {
+	if notifyURL == "" {
+		return
+	}
+
+	var payload any = n
+	if notifyFormat == notifyFormatSlack {
+		payload = n.slackPayload()
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		cmd.Printf("Warning: encoding notification: %v\n", err)
+		return
+	}
+
+	resp, err := notifyClient.Post(notifyURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		cmd.Printf("Warning: sending notification: %v\n", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		cmd.Printf("Warning: notification webhook returned %s\n", resp.Status)
+	}
+}
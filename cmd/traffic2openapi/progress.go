@@ -0,0 +1,290 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// progressRecordThreshold is the record count at which progress is reported.
+	progressRecordThreshold = 10000
+
+	// progressByteThreshold is the input size at which read progress is reported.
+	progressByteThreshold = 50 * 1024 * 1024
+
+	// progressInterval is the minimum time between progress lines.
+	progressInterval = 2 * time.Second
+)
+
+type progressUnit int
+
+const (
+	progressRecords progressUnit = iota
+	progressBytes
+)
+
+// progress prints periodic progress lines (processed, rate, ETA) for long
+// operations. It is a no-op for small inputs or when --quiet is set.
+type progress struct {
+	cmd     *cobra.Command
+	label   string
+	unit    progressUnit
+	total   int64
+	done    int64
+	start   time.Time
+	last    time.Time
+	enabled bool
+}
+
+func newProgress(cmd *cobra.Command, label string, unit progressUnit, total int64) *progress {
+	threshold := int64(progressRecordThreshold)
+	if unit == progressBytes {
+		threshold = progressByteThreshold
+	}
+	now := time.Now()
+	return &progress{
+		cmd:     cmd,
+		label:   label,
+		unit:    unit,
+		total:   total,
+		start:   now,
+		last:    now,
+		enabled: !quiet && total >= threshold,
+	}
+}
+
+// Add records n more units of work and prints a progress line if the
+// reporting interval has elapsed.
+func (p *progress) Add(n int64) {
+	if !p.enabled {
+		return
+	}
+	p.done += n
+	if now := time.Now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.report()
+	}
+}
+
+// Done prints a final summary line.
+func (p *progress) Done() {
+	if !p.enabled {
+		return
+	}
+	elapsed := time.Since(p.start)
+	p.cmd.Printf("%s: %s in %s (%s)\n", p.label, p.amount(p.done),
+		elapsed.Round(time.Millisecond), p.rate(elapsed))
+}
+
+func (p *progress) report() {
+	elapsed := time.Since(p.start)
+	percent := 100 * float64(p.done) / float64(p.total)
+
+	eta := "unknown"
+	if p.done > 0 && p.done < p.total {
+		remaining := time.Duration(float64(elapsed) * float64(p.total-p.done) / float64(p.done))
+		eta = remaining.Round(time.Second).String()
+	}
+
+	p.cmd.Printf("%s: %s/%s (%.0f%%, %s, ETA %s)\n", p.label,
+		p.amount(p.done), p.amount(p.total), percent, p.rate(elapsed), eta)
+}
+
+func (p *progress) amount(n int64) string {
+	if p.unit == progressBytes {
+		return formatBytes(n)
+	}
+	return fmt.Sprintf("%d records", n)
+}
+
+func (p *progress) rate(elapsed time.Duration) string {
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		return "-"
+	}
+	perSecond := float64(p.done) / seconds
+	if p.unit == progressBytes {
+		return formatBytes(int64(perSecond)) + "/s"
+	}
+	return fmt.Sprintf("%.0f records/s", perSecond)
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// progressReader reports bytes read to a progress tracker.
+type progressReader struct {
+	r        io.Reader
+	progress *progress
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.progress.Add(int64(n))
+	return n, err
+}
+
+// readIRInput reads IR records from a file or directory like ir.ReadFile and
+// ir.ReadDir, reporting read progress for large inputs. Errors are input
+// errors.
+func readIRInput(cmd *cobra.Command, path string) ([]ir.IRRecord, error) {
+	var records []ir.IRRecord
+	err := eachIRInput(cmd, path, func(record *ir.IRRecord) error {
+		records = append(records, *record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// eachIRInput calls fn for every IR record in a file or directory, in the
+// order readIRInput returns them, stopping at the first error. NDJSON and
+// gzip-compressed NDJSON (.ndjson.gz) files are streamed one record at a
+// time; batch JSON files are decoded whole. Errors reading the input are
+// input errors; errors returned by fn are returned unchanged.
+func eachIRInput(cmd *cobra.Command, path string, fn func(*ir.IRRecord) error) error {
+	err := eachIRInputFile(cmd, path, func(record *ir.IRRecord) error {
+		if err := fn(record); err != nil {
+			return &recordFuncError{err: err}
+		}
+		return nil
+	})
+	var fnErr *recordFuncError
+	if errors.As(err, &fnErr) {
+		return fnErr.err
+	}
+	return inputError(err)
+}
+
+// recordFuncError marks errors returned by an eachIRInput callback.
+type recordFuncError struct {
+	err error
+}
+
+func (e *recordFuncError) Error() string { return e.err.Error() }
+
+func eachIRInputFile(cmd *cobra.Command, path string, fn func(*ir.IRRecord) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("input path error: %w", err)
+	}
+
+	if !info.IsDir() {
+		p := newProgress(cmd, "Reading "+filepath.Base(path), progressBytes, info.Size())
+		if err := eachIRFileProgress(path, p, fn); err != nil {
+			return err
+		}
+		p.Done()
+		return nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("reading directory: %w", err)
+	}
+
+	var files []string
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := strings.ToLower(entry.Name())
+		ext := filepath.Ext(name)
+		if ext != ".json" && ext != ".ndjson" && !strings.HasSuffix(name, ".ndjson.gz") {
+			continue
+		}
+		if entryInfo, err := entry.Info(); err == nil {
+			total += entryInfo.Size()
+		}
+		files = append(files, filepath.Join(path, entry.Name()))
+	}
+
+	p := newProgress(cmd, "Reading "+path, progressBytes, total)
+	for _, file := range files {
+		if err := eachIRFileProgress(file, p, fn); err != nil {
+			return fmt.Errorf("reading %s: %w", filepath.Base(file), err)
+		}
+	}
+	p.Done()
+
+	return nil
+}
+
+func eachIRFileProgress(path string, p *progress, fn func(*ir.IRRecord) error) error {
+	lower := strings.ToLower(path)
+	ext := filepath.Ext(lower)
+	if ext != ".ndjson" && ext != ".json" && !strings.HasSuffix(lower, ".ndjson.gz") {
+		// Auto-detected formats are read without progress
+		records, err := ir.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return eachRecord(records, fn)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+	r := &progressReader{r: f, progress: p}
+
+	if ext == ".json" {
+		records, err := ir.ReadBatch(r)
+		if err != nil {
+			return err
+		}
+		return eachRecord(records, fn)
+	}
+
+	var reader ir.IRReader = ir.NewNDJSONReader(r)
+	if ext == ".gz" {
+		if reader, err = ir.NewGzipNDJSONReader(r); err != nil {
+			return err
+		}
+	}
+	defer reader.Close()
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+func eachRecord(records []ir.IRRecord, fn func(*ir.IRRecord) error) error {
+	for i := range records {
+		if err := fn(&records[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
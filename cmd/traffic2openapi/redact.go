@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+var redactCmd = &cobra.Command{
+	Use:   "redact",
+	Short: "Redact or encrypt sensitive fields in IR records",
+	Long: `Redact sensitive headers, query parameters, and body fields in IR records.
+
+Names are matched case-insensitively; body fields are matched at any depth.
+Matching values are replaced with "[REDACTED]", or, with --encrypt-key,
+encrypted for the holder of the matching X25519 private key. Encrypted
+captures keep every other value readable, so they can be stored and shared
+broadly while a small trusted group can recover the originals with
+"traffic2openapi decrypt".
+
+Generate a key pair with OpenSSL:
+  openssl genpkey -algorithm X25519 -out capture.key
+  openssl pkey -in capture.key -pubout -out capture.pub
+
+Examples:
+  # Replace credentials with [REDACTED]
+  traffic2openapi redact -i traffic.ndjson -o traffic.redacted.ndjson \
+    --header Authorization --query api_key --field password
+
+  # Encrypt credentials for the holder of capture.key
+  traffic2openapi redact -i traffic.ndjson -o traffic.enc.ndjson \
+    --header Authorization --field password --encrypt-key capture.pub`,
+	RunE: runRedact,
+}
+
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt fields encrypted by redact --encrypt-key",
+	Long: `Decrypt the header, query, and body values encrypted by
+"traffic2openapi redact --encrypt-key" using the matching X25519 private key.
+
+Examples:
+  # Restore the original values
+  traffic2openapi decrypt -i traffic.enc.ndjson -o traffic.ndjson --key capture.key`,
+	RunE: runDecrypt,
+}
+
+var (
+	redactInputPath  string
+	redactOutputPath string
+	redactRules      ir.RedactRules
+	redactKeyPath    string
+
+	decryptInputPath  string
+	decryptOutputPath string
+	decryptKeyPath    string
+)
+
+func init() {
+	rootCmd.AddCommand(redactCmd)
+	rootCmd.AddCommand(decryptCmd)
+
+	redactCmd.Flags().StringVarP(&redactInputPath, "input", "i", "", "Input IR file or directory (required)")
+	redactCmd.Flags().StringVarP(&redactOutputPath, "output", "o", "", "Output IR file path (required)")
+	redactCmd.Flags().StringArrayVar(&redactRules.Headers, "header", nil, "Header to redact (can be repeated)")
+	redactCmd.Flags().StringArrayVar(&redactRules.QueryParams, "query", nil, "Query parameter to redact (can be repeated)")
+	redactCmd.Flags().StringArrayVar(&redactRules.BodyFields, "field", nil, "Body field to redact at any depth (can be repeated)")
+	redactCmd.Flags().StringVar(&redactKeyPath, "encrypt-key", "", "PEM X25519 public key to encrypt values for instead of replacing them")
+
+	_ = redactCmd.MarkFlagRequired("input")
+	_ = redactCmd.MarkFlagRequired("output")
+
+	decryptCmd.Flags().StringVarP(&decryptInputPath, "input", "i", "", "Input IR file or directory (required)")
+	decryptCmd.Flags().StringVarP(&decryptOutputPath, "output", "o", "", "Output IR file path (required)")
+	decryptCmd.Flags().StringVar(&decryptKeyPath, "key", "", "PEM X25519 private key (required)")
+
+	_ = decryptCmd.MarkFlagRequired("input")
+	_ = decryptCmd.MarkFlagRequired("output")
+	_ = decryptCmd.MarkFlagRequired("key")
+}
+
+func runRedact(cmd *cobra.Command, args []string) error {
+	if len(redactRules.Headers)+len(redactRules.QueryParams)+len(redactRules.BodyFields) == 0 {
+		return fmt.Errorf("nothing to redact (use --header, --query, or --field)")
+	}
+
+	if redactKeyPath != "" {
+		data, err := os.ReadFile(redactKeyPath)
+		if err != nil {
+			return fmt.Errorf("reading encryption key: %w", err)
+		}
+		pub, err := ir.ParseEncryptionPublicKey(data)
+		if err != nil {
+			return fmt.Errorf("reading encryption key: %w", err)
+		}
+		if redactRules.Encryptor, err = ir.NewFieldEncryptor(pub); err != nil {
+			return err
+		}
+	}
+
+	records, err := readIRInput(cmd, redactInputPath)
+	if err != nil {
+		return fmt.Errorf("reading IR files: %w", err)
+	}
+
+	records = ir.RedactAll(records, redactRules)
+	if err := ir.WriteFile(redactOutputPath, records); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	action := "Redacted"
+	if redactRules.Encryptor != nil {
+		action = "Encrypted"
+	}
+	cmd.Printf("%s %d records to %s\n", action, len(records), redactOutputPath)
+	return nil
+}
+
+func runDecrypt(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(decryptKeyPath)
+	if err != nil {
+		return fmt.Errorf("reading key: %w", err)
+	}
+	key, err := ir.ParseEncryptionPrivateKey(data)
+	if err != nil {
+		return fmt.Errorf("reading key: %w", err)
+	}
+
+	records, err := readIRInput(cmd, decryptInputPath)
+	if err != nil {
+		return fmt.Errorf("reading IR files: %w", err)
+	}
+
+	for i := range records {
+		if records[i], err = ir.DecryptRecord(records[i], key); err != nil {
+			return fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+
+	if err := ir.WriteFile(decryptOutputPath, records); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	cmd.Printf("Decrypted %d records to %s\n", len(records), decryptOutputPath)
+	return nil
+}
@@ -22,10 +22,27 @@ Examples:
   traffic2openapi generate -i traffic.ndjson -o api.json --version 3.0
 
   # Validate IR files
-  traffic2openapi validate ./logs/`,
-	Version: version,
+  traffic2openapi validate ./logs/
+
+Exit codes:
+  0  success
+  1  failure, or with --detailed-exit-codes an unexpected failure
+  2  differences or drift found (--detailed-exit-codes)
+  3  breaking changes found (--detailed-exit-codes)
+  4  validation errors found (--detailed-exit-codes)
+  5  invalid arguments or unreadable input (--detailed-exit-codes)`,
+	Version:       version,
+	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Arguments and flags are valid; later errors aren't usage errors
+		commandStarted = true
+		cmd.SilenceUsage = true
+	},
 }
 
+var quiet bool
+
 func init() {
-	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress output for large inputs")
+	rootCmd.PersistentFlags().BoolVar(&detailedExitCodes, "detailed-exit-codes", false, "Exit with a status per outcome (2 differences, 3 breaking, 4 validation, 5 input) instead of 1 for every failure")
 }
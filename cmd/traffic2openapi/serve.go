@@ -1,11 +1,15 @@
 package main
 
 import (
+	"embed"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/grokify/traffic2openapi/pkg/openapi"
@@ -13,12 +17,31 @@ import (
 )
 
 var serveCmd = &cobra.Command{
-	Use:   "serve <spec-file>",
+	Use:   "serve <spec-file|dir>...",
 	Short: "Serve OpenAPI spec with interactive documentation",
 	Long: `Serve an OpenAPI specification with interactive documentation UI.
 
 Supports Swagger UI and Redoc for browsing and testing the API.
 
+When several specs are given (or a directory of .yaml, .yml, and .json
+specs), an index page lists them and each spec is served under
+/<name>/, where name is the spec file name without its extension.
+
+UI assets are served from the binary, which embeds swagger-ui-bundle.js,
+swagger-ui.css, and redoc.standalone.js from the static directory (make
+vendor-ui downloads them before building), so the UI works without network
+access. --assets serves them from a directory instead, and --cdn loads them
+from a CDN.
+
+With --compare, two specs (old and new) are compared using the same engine
+as the diff command, and / shows the changes side by side with breaking
+changes highlighted. Each spec's documentation is served under /old/ and /new/.
+
+With --from-ir, no spec file is needed: the spec is inferred from an IR file
+or directory in-process and regenerated as records are added, and the
+traffic documentation site is served under /docs/. This combines
+generate --watch, site --watch, and serve for local development loops.
+
 Examples:
   # Serve with Swagger UI (default)
   traffic2openapi serve openapi.yaml
@@ -30,15 +53,41 @@ Examples:
   traffic2openapi serve openapi.yaml --ui redoc
 
   # Auto-reload when spec changes
-  traffic2openapi serve openapi.yaml --watch`,
-	Args: cobra.ExactArgs(1),
+  traffic2openapi serve openapi.yaml --watch
+
+  # Serve every spec in a directory
+  traffic2openapi serve ./specs/
+
+  # Serve UI assets from a local directory
+  traffic2openapi serve openapi.yaml --assets ./swagger-assets/
+
+  # Load UI assets from a CDN
+  traffic2openapi serve openapi.yaml --cdn
+
+  # Review the changes between two versions of a spec
+  traffic2openapi serve --compare old.yaml new.yaml
+
+  # Serve an always-fresh spec and site generated from captured traffic
+  traffic2openapi serve --from-ir ./captures/`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if serveFromIR != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: runServe,
 }
 
 var (
-	servePort  int
-	serveUI    string
-	serveWatch bool
+	servePort    int
+	serveUI      string
+	serveWatch   bool
+	serveAssets  string
+	serveCDN     bool
+	serveCompare bool
+	serveFromIR  string
+	// serveDebounce is the debounce interval for --from-ir.
+	serveDebounce time.Duration
 )
 
 func init() {
@@ -47,6 +96,25 @@ func init() {
 	serveCmd.Flags().IntVarP(&servePort, "port", "p", 8080, "Port to serve on")
 	serveCmd.Flags().StringVar(&serveUI, "ui", "swagger", "Documentation UI: swagger or redoc")
 	serveCmd.Flags().BoolVarP(&serveWatch, "watch", "w", false, "Watch for file changes and auto-reload")
+	serveCmd.Flags().StringVar(&serveAssets, "assets", "", "Directory of UI assets to serve instead of the embedded ones")
+	serveCmd.Flags().BoolVar(&serveCDN, "cdn", false, "Load UI assets from a CDN instead of serving the embedded ones")
+	serveCmd.Flags().BoolVar(&serveCompare, "compare", false, "Compare two specs (old, new) in a browsable diff view")
+	serveCmd.Flags().StringVar(&serveFromIR, "from-ir", "", "Generate the spec and site from an IR file or directory, regenerating as records are added")
+	serveCmd.Flags().DurationVar(&serveDebounce, "debounce", 500*time.Millisecond, "Debounce interval for --from-ir")
+	serveCmd.MarkFlagsMutuallyExclusive("compare", "from-ir")
+	serveCmd.MarkFlagsMutuallyExclusive("assets", "cdn")
+	addResolveRefsFlag(serveCmd)
+}
+
+// staticFiles holds UI assets vendored into the static directory at build time.
+//
+//go:embed static
+var staticFiles embed.FS
+
+// uiAssets lists the files each UI needs to run offline.
+var uiAssets = map[string][]string{
+	"swagger": {"swagger-ui-bundle.js", "swagger-ui.css"},
+	"redoc":   {"redoc.standalone.js"},
 }
 
 // HTML templates for documentation UIs
@@ -55,7 +123,11 @@ const swaggerUIHTML = `<!DOCTYPE html>
 <head>
   <meta charset="UTF-8">
   <title>{{.Title}} - Swagger UI</title>
+  {{- if .Offline}}
+  <link rel="stylesheet" type="text/css" href="/assets/swagger-ui.css">
+  {{- else}}
   <link rel="stylesheet" type="text/css" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+  {{- end}}
   <style>
     html { box-sizing: border-box; overflow-y: scroll; }
     *, *:before, *:after { box-sizing: inherit; }
@@ -63,12 +135,19 @@ const swaggerUIHTML = `<!DOCTYPE html>
   </style>
 </head>
 <body>
+  {{- if .IndexURL}}
+  <a href="{{.IndexURL}}" style="display:block;padding:8px 16px;font-family:sans-serif;">&larr; All specs</a>
+  {{- end}}
   <div id="swagger-ui"></div>
+  {{- if .Offline}}
+  <script src="/assets/swagger-ui-bundle.js"></script>
+  {{- else}}
   <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  {{- end}}
   <script>
     window.onload = function() {
       SwaggerUIBundle({
-        url: "/spec.json",
+        url: "{{.SpecURL}}",
         dom_id: '#swagger-ui',
         deepLinking: true,
         presets: [
@@ -91,60 +170,221 @@ const redocHTML = `<!DOCTYPE html>
   <meta charset="UTF-8">
   <title>{{.Title}} - Redoc</title>
   <meta name="viewport" content="width=device-width, initial-scale=1">
+  {{- if not .Offline}}
   <link href="https://fonts.googleapis.com/css?family=Montserrat:300,400,700|Roboto:300,400,700" rel="stylesheet">
+  {{- end}}
   <style>
     body { margin: 0; padding: 0; }
   </style>
 </head>
 <body>
-  <redoc spec-url='/spec.json'></redoc>
+  {{- if .IndexURL}}
+  <a href="{{.IndexURL}}" style="display:block;padding:8px 16px;font-family:sans-serif;">&larr; All specs</a>
+  {{- end}}
+  <redoc spec-url='{{.SpecURL}}'></redoc>
+  {{- if .Offline}}
+  <script src="/assets/redoc.standalone.js"></script>
+  {{- else}}
   <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+  {{- end}}
+</body>
+</html>`
+
+const specIndexHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>API Documentation</title>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <style>
+    body { margin: 0 auto; max-width: 960px; padding: 24px; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; color: #1f2937; }
+    table { width: 100%; border-collapse: collapse; }
+    th, td { text-align: left; padding: 8px 12px; border-bottom: 1px solid #e5e7eb; }
+    th { background: #f9fafb; }
+    a { color: #2563eb; text-decoration: none; }
+    .muted { color: #6b7280; }
+  </style>
+</head>
+<body>
+  <h1>API Documentation</h1>
+  <table>
+    <thead>
+      <tr><th>API</th><th>Version</th><th>File</th><th>Download</th></tr>
+    </thead>
+    <tbody>
+      {{- range .}}
+      <tr>
+        <td><a href="/{{.Name}}/">{{.Title}}</a></td>
+        <td class="muted">{{.Version}}</td>
+        <td class="muted">{{.File}}</td>
+        <td><a href="/{{.Name}}/spec.json">JSON</a> · <a href="/{{.Name}}/spec.yaml">YAML</a></td>
+      </tr>
+      {{- end}}
+    </tbody>
+  </table>
 </body>
 </html>`
 
 type templateData struct {
-	Title string
+	Title    string
+	SpecURL  string
+	IndexURL string
+	Offline  bool
+}
+
+// servedSpec is a spec file served under its own route prefix.
+type servedSpec struct {
+	Name    string
+	Path    string
+	File    string
+	Title   string
+	Version string
+	spec    *openapi.Spec
+	// load, if set, returns the spec instead of the file.
+	load func() (*openapi.Spec, error)
+}
+
+// current returns the spec, re-reading the file when watching.
+func (s *servedSpec) current() (*openapi.Spec, error) {
+	if s.load != nil {
+		return s.load()
+	}
+	if !serveWatch {
+		return s.spec, nil
+	}
+	return readSpec(s.Path)
 }
 
 func runServe(cmd *cobra.Command, args []string) error {
-	specPath := args[0]
+	if _, ok := uiAssets[serveUI]; !ok {
+		return inputError(fmt.Errorf("unsupported UI: %s (use swagger or redoc)", serveUI))
+	}
+	if serveCompare {
+		return runServeCompare(cmd, args)
+	}
+	if serveFromIR != "" {
+		return runServeFromIR(cmd)
+	}
+
+	paths, err := collectSpecPaths(args)
+	if err != nil {
+		return err
+	}
 
-	// Validate spec file exists
-	if _, err := os.Stat(specPath); err != nil {
-		return fmt.Errorf("spec file error: %w", err)
+	specs, err := loadServedSpecs(paths)
+	if err != nil {
+		return err
 	}
 
-	// Read spec to get title
-	spec, err := openapi.ReadFile(specPath)
+	assets, err := resolveUIAssets(serveAssets, serveUI, serveCDN)
 	if err != nil {
-		return fmt.Errorf("reading spec: %w", err)
+		return err
 	}
 
-	title := spec.Info.Title
-	if title == "" {
-		title = "API Documentation"
+	tmpl, err := parseUITemplate()
+	if err != nil {
+		return err
 	}
 
 	// Create HTTP handlers
-	mux := http.NewServeMux()
+	mux := newServeMux(assets)
 
-	// Serve the spec as JSON
-	mux.HandleFunc("/spec.json", func(w http.ResponseWriter, r *http.Request) {
-		// Re-read spec each time if watching
-		currentSpec := spec
-		if serveWatch {
-			var readErr error
-			currentSpec, readErr = openapi.ReadFile(specPath)
-			if readErr != nil {
-				http.Error(w, readErr.Error(), http.StatusInternalServerError)
+	if len(specs) == 1 {
+		registerSpecRoutes(mux, "/", specs[0], tmpl, assets != nil, "")
+	} else {
+		index, err := template.New("index").Parse(specIndexHTML)
+		if err != nil {
+			return fmt.Errorf("parsing template: %w", err)
+		}
+		for _, s := range specs {
+			registerSpecRoutes(mux, "/"+s.Name+"/", s, tmpl, assets != nil, "/")
+		}
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
 				return
 			}
+			w.Header().Set("Content-Type", "text/html")
+			if err := index.Execute(w, specs); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		})
+	}
+
+	// Start server
+	addr := fmt.Sprintf(":%d", servePort)
+	if len(specs) == 1 {
+		cmd.Printf("Serving %s at http://localhost%s\n", specs[0].File, addr)
+	} else {
+		cmd.Printf("Serving %d specs at http://localhost%s\n", len(specs), addr)
+		for _, s := range specs {
+			cmd.Printf("  /%s/  %s\n", s.Name, s.File)
+		}
+	}
+	return listenAndServe(cmd, addr, mux, assets != nil)
+}
+
+// parseUITemplate parses the documentation UI template selected by --ui.
+func parseUITemplate() (*template.Template, error) {
+	var htmlTemplate string
+	switch serveUI {
+	case "redoc":
+		htmlTemplate = redocHTML
+	default:
+		htmlTemplate = swaggerUIHTML
+	}
+
+	tmpl, err := template.New("ui").Parse(htmlTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// newServeMux creates a mux serving local UI assets at /assets/, if any.
+func newServeMux(assets fs.FS) *http.ServeMux {
+	mux := http.NewServeMux()
+	if assets != nil {
+		mux.Handle("/assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(assets))))
+	}
+	return mux
+}
+
+// listenAndServe prints the serve options and starts the server.
+func listenAndServe(cmd *cobra.Command, addr string, handler http.Handler, offline bool) error {
+	cmd.Printf("UI: %s\n", serveUI)
+	if offline {
+		cmd.Println("Assets: local (offline)")
+	}
+	if serveWatch {
+		cmd.Println("Watching for file changes...")
+	}
+	cmd.Println("\nPress Ctrl+C to stop")
+
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
+
+	return server.ListenAndServe()
+}
+
+// registerSpecRoutes serves a spec's UI, JSON, and YAML under prefix.
+func registerSpecRoutes(mux *http.ServeMux, prefix string, s *servedSpec, tmpl *template.Template, offline bool, indexURL string) {
+	// Serve the spec as JSON
+	mux.HandleFunc(prefix+"spec.json", func(w http.ResponseWriter, r *http.Request) {
+		spec, err := s.current()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 
-		data, err := openapi.ToJSON(currentSpec)
+		data, err := openapi.ToJSON(spec)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -153,21 +393,17 @@ func runServe(cmd *cobra.Command, args []string) error {
 	})
 
 	// Serve the spec as YAML
-	mux.HandleFunc("/spec.yaml", func(w http.ResponseWriter, r *http.Request) {
-		currentSpec := spec
-		if serveWatch {
-			var readErr error
-			currentSpec, readErr = openapi.ReadFile(specPath)
-			if readErr != nil {
-				http.Error(w, readErr.Error(), http.StatusInternalServerError)
-				return
-			}
+	mux.HandleFunc(prefix+"spec.yaml", func(w http.ResponseWriter, r *http.Request) {
+		spec, err := s.current()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
 
 		w.Header().Set("Content-Type", "application/x-yaml")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 
-		data, err := openapi.ToYAML(currentSpec)
+		data, err := openapi.ToYAML(spec)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -175,48 +411,155 @@ func runServe(cmd *cobra.Command, args []string) error {
 		_, _ = w.Write(data)
 	})
 
-	// Serve the documentation UI
-	var htmlTemplate string
-	switch serveUI {
-	case "redoc":
-		htmlTemplate = redocHTML
-	default:
-		htmlTemplate = swaggerUIHTML
-	}
-
-	tmpl, err := template.New("ui").Parse(htmlTemplate)
-	if err != nil {
-		return fmt.Errorf("parsing template: %w", err)
-	}
-
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/" {
+	mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prefix {
 			http.NotFound(w, r)
 			return
 		}
 
 		w.Header().Set("Content-Type", "text/html")
-		data := templateData{Title: title}
+		data := templateData{
+			Title:    s.Title,
+			SpecURL:  prefix + "spec.json",
+			IndexURL: indexURL,
+			Offline:  offline,
+		}
 		if err := tmpl.Execute(w, data); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 	})
+}
 
-	// Start server
-	addr := fmt.Sprintf(":%d", servePort)
-	cmd.Printf("Serving %s at http://localhost%s\n", filepath.Base(specPath), addr)
-	cmd.Printf("UI: %s\n", serveUI)
-	if serveWatch {
-		cmd.Println("Watching for file changes...")
+// collectSpecPaths expands directory arguments to the spec files they contain.
+func collectSpecPaths(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, fmt.Errorf("spec file error: %w", err)
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, fmt.Errorf("reading directory: %w", err)
+		}
+		found := 0
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				paths = append(paths, filepath.Join(arg, entry.Name()))
+				found++
+			}
+		}
+		if found == 0 {
+			return nil, fmt.Errorf("no spec files (.yaml, .yml, .json) found in %s", arg)
+		}
 	}
-	cmd.Println("\nPress Ctrl+C to stop")
+	return paths, nil
+}
 
-	server := &http.Server{
-		Addr:         addr,
-		Handler:      mux,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+// loadServedSpecs reads each spec and assigns it a unique URL-safe name.
+func loadServedSpecs(paths []string) ([]*servedSpec, error) {
+	specs := make([]*servedSpec, 0, len(paths))
+	used := make(map[string]int)
+	for _, path := range paths {
+		file := filepath.Base(path)
+		name := specRouteName(strings.TrimSuffix(file, filepath.Ext(file)))
+		used[name]++
+		if n := used[name]; n > 1 {
+			name = fmt.Sprintf("%s-%d", name, n)
+		}
+
+		s, err := newServedSpec(path, name)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, s)
 	}
 
-	return server.ListenAndServe()
+	sort.SliceStable(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs, nil
+}
+
+// newServedSpec reads the spec at path to serve under name.
+func newServedSpec(path, name string) (*servedSpec, error) {
+	spec, err := readSpec(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading spec %s: %w", path, err)
+	}
+
+	s := &servedSpec{
+		Name:    name,
+		Path:    path,
+		File:    filepath.Base(path),
+		Title:   "API Documentation",
+		Version: spec.Info.Version,
+		spec:    spec,
+	}
+	if spec.Info.Title != "" {
+		s.Title = spec.Info.Title
+	}
+	return s, nil
+}
+
+// specRouteName converts a file name to a URL path segment.
+func specRouteName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	// "assets" is reserved for vendored UI assets
+	if b.Len() == 0 || b.String() == "assets" {
+		return "spec"
+	}
+	return b.String()
+}
+
+// resolveUIAssets returns the local assets to serve for ui: the --assets
+// directory if set, else the files embedded from the static directory. It
+// returns nil with cdn so the UI loads its assets from a CDN.
+func resolveUIAssets(dir, ui string, cdn bool) (fs.FS, error) {
+	if cdn {
+		return nil, nil
+	}
+	if dir != "" {
+		assets := os.DirFS(dir)
+		if missing := missingAssets(assets, ui); len(missing) > 0 {
+			return nil, fmt.Errorf("assets directory %s is missing %s", dir, strings.Join(missing, ", "))
+		}
+		return assets, nil
+	}
+
+	embedded, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		return nil, err
+	}
+	if missing := missingAssets(embedded, ui); len(missing) > 0 {
+		return nil, fmt.Errorf("%s UI assets are not embedded in this build (missing %s): run make vendor-ui before building, pass --assets, or use --cdn",
+			ui, strings.Join(missing, ", "))
+	}
+	return embedded, nil
+}
+
+// missingAssets returns the files ui needs that are not in assets.
+func missingAssets(assets fs.FS, ui string) []string {
+	var missing []string
+	for _, name := range uiAssets[ui] {
+		if _, err := fs.Stat(assets, name); err != nil {
+			missing = append(missing, name)
+		}
+	}
+	return missing
 }
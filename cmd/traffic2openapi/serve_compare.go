@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+const compareHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>{{.Old.Title}} - API Changes</title>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <style>
+    body { margin: 0 auto; max-width: 1200px; padding: 24px; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; color: #1f2937; }
+    a { color: #2563eb; text-decoration: none; }
+    code { font-family: SFMono-Regular, Menlo, Consolas, monospace; font-size: 0.9em; }
+    .muted { color: #6b7280; }
+    .summary { display: flex; gap: 12px; margin: 16px 0 24px; }
+    .stat { padding: 12px 16px; border-radius: 6px; background: #f3f4f6; }
+    .stat strong { display: block; font-size: 1.5em; }
+    .stat.breaking { background: #fee2e2; color: #991b1b; }
+    .breaking-list { border: 1px solid #fca5a5; background: #fef2f2; border-radius: 6px; padding: 12px 16px; margin-bottom: 24px; }
+    .breaking-list h2 { margin-top: 0; color: #991b1b; font-size: 1.1em; }
+    table { width: 100%; border-collapse: collapse; table-layout: fixed; }
+    th, td { text-align: left; vertical-align: top; padding: 8px 12px; border-bottom: 1px solid #e5e7eb; }
+    th { background: #f9fafb; }
+    th.op { width: 30%; }
+    tr.is-breaking td:first-child { border-left: 4px solid #dc2626; }
+    ul.changes { list-style: none; margin: 0; padding: 0; }
+    ul.changes li { padding: 2px 6px; margin-bottom: 2px; border-radius: 4px; }
+    .old li { background: #fee2e2; }
+    .new li { background: #dcfce7; }
+    .badge { display: inline-block; padding: 1px 6px; border-radius: 4px; font-size: 0.8em; font-weight: 600; background: #e5e7eb; }
+    .badge.added { background: #dcfce7; color: #166534; }
+    .badge.removed { background: #fee2e2; color: #991b1b; }
+    .badge.modified { background: #fef3c7; color: #92400e; }
+  </style>
+</head>
+<body>
+  <h1>API Changes</h1>
+  <p class="muted">
+    <a href="/old/">{{.Old.Title}} {{.Old.Version}}</a> ({{.Old.File}})
+    &rarr;
+    <a href="/new/">{{.New.Title}} {{.New.Version}}</a> ({{.New.File}})
+  </p>
+
+  <div class="summary">
+    <div class="stat"><strong>{{.Added}}</strong>added</div>
+    <div class="stat"><strong>{{.Removed}}</strong>removed</div>
+    <div class="stat"><strong>{{.Modified}}</strong>modified</div>
+    <div class="stat{{if .Result.BreakingChanges}} breaking{{end}}"><strong>{{len .Result.BreakingChanges}}</strong>breaking</div>
+  </div>
+
+  {{- if .Result.BreakingChanges}}
+  <div class="breaking-list">
+    <h2>Breaking Changes</h2>
+    <ul>
+      {{- range .Result.BreakingChanges}}
+      <li><code>{{.Type}}</code> {{.Description}}</li>
+      {{- end}}
+    </ul>
+  </div>
+  {{- end}}
+
+  {{- if .Rows}}
+  <table>
+    <thead>
+      <tr><th class="op">Operation</th><th>Old</th><th>New</th></tr>
+    </thead>
+    <tbody>
+      {{- range .Rows}}
+      <tr{{if .Breaking}} class="is-breaking"{{end}}>
+        <td><span class="badge {{.Kind}}">{{.Kind}}</span> <code>{{.Label}}</code></td>
+        <td class="old"><ul class="changes">{{range .Old}}<li><code>{{.}}</code></li>{{end}}</ul></td>
+        <td class="new"><ul class="changes">{{range .New}}<li><code>{{.}}</code></li>{{end}}</ul></td>
+      </tr>
+      {{- end}}
+    </tbody>
+  </table>
+  {{- else}}
+  <p>No differences found.</p>
+  {{- end}}
+</body>
+</html>`
+
+type compareData struct {
+	Old      *servedSpec
+	New      *servedSpec
+	Result   *DiffResult
+	Rows     []compareRow
+	Added    int
+	Removed  int
+	Modified int
+}
+
+// compareRow is one changed path or operation, with what the old spec had
+// that the new one lacks on the left and additions on the right.
+type compareRow struct {
+	Kind     string // added, removed, or modified
+	Label    string
+	Old      []string
+	New      []string
+	Breaking bool
+}
+
+func runServeCompare(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("--compare requires exactly two specs (old, new), got %d", len(args))
+	}
+
+	oldSpec, err := newServedSpec(args[0], "old")
+	if err != nil {
+		return err
+	}
+	newSpec, err := newServedSpec(args[1], "new")
+	if err != nil {
+		return err
+	}
+
+	assets, err := resolveUIAssets(serveAssets, serveUI, serveCDN)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := parseUITemplate()
+	if err != nil {
+		return err
+	}
+	page, err := template.New("compare").Parse(compareHTML)
+	if err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+
+	mux := newServeMux(assets)
+	registerSpecRoutes(mux, "/old/", oldSpec, tmpl, assets != nil, "/")
+	registerSpecRoutes(mux, "/new/", newSpec, tmpl, assets != nil, "/")
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		// Re-compare each time if watching
+		oldCurrent, err := oldSpec.current()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		newCurrent, err := newSpec.current()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		data := newCompareData(oldSpec, newSpec, compareSpecs(oldCurrent, newCurrent))
+		if err := page.Execute(w, data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	addr := fmt.Sprintf(":%d", servePort)
+	cmd.Printf("Comparing %s -> %s at http://localhost%s\n", oldSpec.File, newSpec.File, addr)
+	return listenAndServe(cmd, addr, mux, assets != nil)
+}
+
+// newCompareData lays out a diff result as side-by-side rows.
+func newCompareData(oldSpec, newSpec *servedSpec, result *DiffResult) compareData {
+	data := compareData{
+		Old:      oldSpec,
+		New:      newSpec,
+		Result:   result,
+		Added:    len(result.AddedPaths) + len(result.AddedOperations),
+		Removed:  len(result.RemovedPaths) + len(result.RemovedOps),
+		Modified: len(result.ModifiedOps),
+	}
+
+	for _, path := range result.RemovedPaths {
+		data.Rows = append(data.Rows, compareRow{Kind: "removed", Label: path, Old: []string{path}, Breaking: true})
+	}
+	for _, op := range result.RemovedOps {
+		data.Rows = append(data.Rows, compareRow{Kind: "removed", Label: op, Old: []string{op}, Breaking: true})
+	}
+	for _, op := range result.ModifiedOps {
+		row := compareRow{
+			Kind:     "modified",
+			Label:    op.Method + " " + op.Path,
+			Breaking: len(op.RemovedParams) > 0,
+		}
+		for _, p := range op.RemovedParams {
+			row.Old = append(row.Old, "param "+p)
+		}
+		for _, r := range op.RemovedResponses {
+			row.Old = append(row.Old, "response "+r)
+		}
+		for _, p := range op.AddedParams {
+			row.New = append(row.New, "param "+p)
+		}
+		for _, r := range op.AddedResponses {
+			row.New = append(row.New, "response "+r)
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	for _, path := range result.AddedPaths {
+		data.Rows = append(data.Rows, compareRow{Kind: "added", Label: path, New: []string{path}})
+	}
+	for _, op := range result.AddedOperations {
+		data.Rows = append(data.Rows, compareRow{Kind: "added", Label: op, New: []string{op}})
+	}
+	return data
+}
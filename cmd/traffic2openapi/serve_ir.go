@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/grokify/traffic2openapi/pkg/sitegen"
+	"github.com/spf13/cobra"
+)
+
+// irServer keeps a generated spec and site up to date with an IR input for
+// serve --from-ir. Records appended to NDJSON files are added to the running
+// inference, so the spec is regenerated without reprocessing earlier records
+// and only the site pages of their endpoints are rewritten.
+type irServer struct {
+	mu      sync.Mutex
+	cmd     *cobra.Command
+	docsDir string
+	tail    *irTail
+	engine  *inference.Engine
+	site    *sitegen.Generator
+	spec    *openapi.Spec
+}
+
+func newIRServer(cmd *cobra.Command, input, docsDir string) *irServer {
+	s := &irServer{cmd: cmd, docsDir: docsDir, tail: newIRTail(cmd, input)}
+	s.reset()
+	return s
+}
+
+func (s *irServer) reset() {
+	s.engine = inference.NewEngine(inference.DefaultEngineOptions())
+	s.site = sitegen.NewGenerator(s.docsDir, &sitegen.Options{Title: "API Traffic Documentation"})
+	s.tail.reset()
+}
+
+// current returns the spec generated from the records processed so far.
+func (s *irServer) current() (*openapi.Spec, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spec, nil
+}
+
+// build processes the whole input and generates the spec and full site.
+func (s *irServer) build() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rebuild()
+}
+
+func (s *irServer) rebuild() error {
+	s.reset()
+	n, _, err := s.ingest()
+	if err != nil {
+		return err
+	}
+	if err := s.site.Generate(); err != nil {
+		return fmt.Errorf("generating site: %w", err)
+	}
+	s.cmd.Printf("Generated spec with %d paths from %d records\n", len(s.spec.Paths), n)
+	return nil
+}
+
+// update processes new input, regenerates the spec, and rewrites the site
+// pages it changed.
+func (s *irServer) update() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, rebuild, err := s.ingest()
+	if err != nil {
+		return err
+	}
+	if rebuild {
+		s.cmd.Println("Input rewritten, regenerating from scratch")
+		return s.rebuild()
+	}
+	if n == 0 {
+		return nil
+	}
+
+	pages, err := s.site.GenerateChanged()
+	if err != nil {
+		return fmt.Errorf("generating site: %w", err)
+	}
+	s.cmd.Printf("Added %d records: spec has %d paths, regenerated %d endpoint pages\n", n, len(s.spec.Paths), pages)
+	return nil
+}
+
+// ingest processes the input not yet processed and regenerates the spec. It
+// returns the number of records added, or reports rebuild when the input was
+// rewritten.
+func (s *irServer) ingest() (int, bool, error) {
+	records, rebuild, err := s.tail.next()
+	if err != nil || rebuild {
+		return 0, rebuild, err
+	}
+	if s.spec != nil && len(records) == 0 {
+		return 0, false, nil
+	}
+	s.engine.ProcessRecords(records)
+	s.site.ProcessRecords(records)
+	s.spec = openapi.GenerateFromInference(s.engine.Snapshot(), openapi.DefaultGeneratorOptions())
+	return len(records), false, nil
+}
+
+func runServeFromIR(cmd *cobra.Command) error {
+	assets, err := resolveUIAssets(serveAssets, serveUI, serveCDN)
+	if err != nil {
+		return err
+	}
+	tmpl, err := parseUITemplate()
+	if err != nil {
+		return err
+	}
+
+	docsDir, err := os.MkdirTemp("", "traffic2openapi-docs-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(docsDir)
+
+	// The server runs until interrupted, so remove the site on the way out
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupt
+		_ = os.RemoveAll(docsDir)
+		os.Exit(130)
+	}()
+
+	s := newIRServer(cmd, serveFromIR, docsDir)
+	if err := s.build(); err != nil {
+		return err
+	}
+	go func() {
+		if err := watchIRInput(cmd, serveFromIR, serveDebounce, s.update); err != nil {
+			cmd.Printf("Watch failed: %v\n", err)
+		}
+	}()
+
+	spec := &servedSpec{
+		Name:  "spec",
+		Path:  serveFromIR,
+		File:  serveFromIR,
+		Title: "API Documentation",
+		load:  s.current,
+	}
+	if title := s.spec.Info.Title; title != "" {
+		spec.Title = title
+	}
+
+	mux := newServeMux(assets)
+	registerSpecRoutes(mux, "/", spec, tmpl, assets != nil, "")
+	mux.Handle("/docs/", http.StripPrefix("/docs/", http.FileServer(http.Dir(docsDir))))
+
+	addr := fmt.Sprintf(":%d", servePort)
+	cmd.Printf("Serving spec generated from %s at http://localhost%s\n", serveFromIR, addr)
+	cmd.Printf("Traffic documentation at http://localhost%s/docs/\n", addr)
+	return listenAndServe(cmd, addr, mux, assets != nil)
+}
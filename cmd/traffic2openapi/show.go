@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <record-id>",
+	Short: "Show a single IR record by ID",
+	Long: `Retrieve and pretty-print a single IR record, including headers and
+bodies, from an NDJSON capture. Useful for debugging specific requests
+referenced in validation or audit reports.
+
+If a sidecar index (<file>.idx) exists and is up to date, it is used to
+locate the record directly; otherwise the capture is scanned. Use
+--build-index to create the sidecar for faster repeated lookups.
+
+Examples:
+  # Show a record from a capture
+  traffic2openapi show req-42 -i capture.ndjson
+
+  # Show a record from a compressed capture, building an index first
+  traffic2openapi show req-42 -i capture.ndjson.gz --build-index`,
+	Args: cobra.ExactArgs(1),
+	RunE: runShow,
+}
+
+var (
+	showInputPath  string
+	showBuildIndex bool
+)
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+
+	showCmd.Flags().StringVarP(&showInputPath, "input", "i", "", "Input NDJSON file, optionally gzip-compressed (required)")
+	showCmd.Flags().BoolVar(&showBuildIndex, "build-index", false, "Build the sidecar index before lookup")
+
+	_ = showCmd.MarkFlagRequired("input")
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	if showBuildIndex {
+		idx, err := ir.BuildIndex(showInputPath)
+		if err != nil {
+			return fmt.Errorf("building index: %w", err)
+		}
+		if err := ir.WriteIndexFile(ir.IndexPath(showInputPath), idx); err != nil {
+			return err
+		}
+		cmd.Printf("Indexed %d records in %s\n", len(idx.Offsets), ir.IndexPath(showInputPath))
+	}
+
+	record, err := ir.FindRecord(showInputPath, args[0])
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding record: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/grokify/traffic2openapi/pkg/ir"
 	"github.com/grokify/traffic2openapi/pkg/sitegen"
 	"github.com/spf13/cobra"
 )
@@ -17,6 +19,12 @@ with both deduplicated and distinct views of captured requests/responses.
 
 Features:
   - Index page with all endpoints
+  - User flow grouping for records of a HAR page (har.pageref metadata),
+    titled by the page title, or by a metadata key (--flow-meta)
+  - Optional per-host sections with stats (--group-by-host)
+  - Optional per-resource sections in CRUD order (--group-by-resource)
+  - Optional resource model page with an ER diagram (--resource-model)
+  - Optional client sessions by bearer token, API key, or client IP (--sessions)
   - Per-endpoint pages with request/response details
   - Deduped view showing all captured parameter values
   - Distinct view showing individual requests
@@ -32,7 +40,16 @@ Examples:
   traffic2openapi site -i ./logs/ -o ./docs/api/
 
   # Custom title
-  traffic2openapi site -i traffic.ndjson -o ./site/ --title "My API Docs"`,
+  traffic2openapi site -i traffic.ndjson -o ./site/ --title "My API Docs"
+
+  # Custom templates, falling back to the defaults for missing files
+  traffic2openapi site -i traffic.ndjson -o ./site/ --template-dir ./templates/
+
+  # Keep the site up to date while a capture pipeline appends records
+  traffic2openapi site -i ./logs/ -o ./site/ --watch
+
+  # Document one service of a shared, labeled capture
+  traffic2openapi site -i ./logs/ -o ./site/ --meta-filter service=checkout`,
 	RunE: runSite,
 }
 
@@ -41,6 +58,17 @@ var (
 	siteOutputPath string
 	siteTitle      string
 	siteBaseURL    string
+	siteFlowMeta   string
+	siteByHost     bool
+	siteByResource bool
+	siteModel      bool
+	siteTemplates  string
+	siteDedup      sitegen.DedupOptions
+	siteSessions   bool
+	siteMetaFilter []string
+	siteSessionGap time.Duration
+	siteWatch      bool
+	siteDebounce   time.Duration
 )
 
 func init() {
@@ -50,24 +78,79 @@ func init() {
 	siteCmd.Flags().StringVarP(&siteOutputPath, "output", "o", "./site/", "Output directory for generated site")
 	siteCmd.Flags().StringVar(&siteTitle, "title", "API Traffic Documentation", "Site title")
 	siteCmd.Flags().StringVar(&siteBaseURL, "base-url", "", "Base URL for links (e.g., /docs/api/)")
+	siteCmd.Flags().BoolVar(&siteByHost, "group-by-host", false, "Group endpoints into a section per host with per-host stats")
+	siteCmd.Flags().BoolVar(&siteByResource, "group-by-resource", false, "Group endpoints into a section per REST resource, ordered list, create, get, replace, update, delete")
+	siteCmd.Flags().BoolVar(&siteModel, "resource-model", false, "Add a page with the resources, fields, and relations of the API and a Mermaid ER diagram")
+	siteCmd.Flags().StringVar(&siteFlowMeta, "flow-meta", "", "Group user flows by this record metadata key instead of the HAR page")
+	siteCmd.Flags().BoolVar(&siteSessions, "sessions", false, "Group requests into client sessions by bearer token, API key, or client IP")
+	siteCmd.Flags().DurationVar(&siteSessionGap, "session-gap", ir.DefaultSessionGap, "Idle time that ends a session")
+	siteCmd.Flags().BoolVar(&siteDedup.ResponseStructure, "response-structure", false, "Include the response body structure in the dedup key")
+	addDedupKeyFlags(siteCmd, &siteDedup)
+	siteCmd.Flags().StringArrayVar(&siteMetaFilter, "meta-filter", nil, "Only use records with this metadata as key=value, e.g. service=checkout (can be repeated)")
+	siteCmd.Flags().StringVar(&siteTemplates, "template-dir", "", "Directory of custom templates (index.html, endpoint.html, partials/*.html)")
+	siteCmd.Flags().BoolVarP(&siteWatch, "watch", "w", false, "Watch the input for new records and regenerate changed pages")
+	siteCmd.Flags().DurationVar(&siteDebounce, "debounce", 500*time.Millisecond, "Debounce interval for watch mode")
+
+	siteCmd.MarkFlagsMutuallyExclusive("group-by-host", "group-by-resource")
 
 	if err := siteCmd.MarkFlagRequired("input"); err != nil {
 		panic(fmt.Sprintf("failed to mark input flag required: %v", err))
 	}
 }
 
+// siteProgressBatch is the number of records processed between progress updates.
+const siteProgressBatch = 1000
+
 func runSite(cmd *cobra.Command, args []string) error {
 	opts := &sitegen.Options{
-		Title:   siteTitle,
-		BaseURL: siteBaseURL,
+		Title:           siteTitle,
+		BaseURL:         siteBaseURL,
+		FlowMetaKey:     siteFlowMeta,
+		GroupByHost:     siteByHost,
+		GroupByResource: siteByResource,
+		ResourceModel:   siteModel,
+		Sessions:        siteSessions,
+		SessionGap:      siteSessionGap,
+		Dedup:           siteDedup,
+		TemplateDir:     siteTemplates,
+	}
+	if len(siteMetaFilter) > 0 {
+		filter, err := ir.ParseMetadata(siteMetaFilter)
+		if err != nil {
+			return inputError(err)
+		}
+		opts.MetadataFilter = filter
+	}
+
+	if siteWatch {
+		return runSiteWatch(cmd, opts)
 	}
 
 	cmd.Printf("Reading IR files from %s...\n", siteInputPath)
 
-	if err := sitegen.GenerateFromFile(siteInputPath, siteOutputPath, opts); err != nil {
+	records, err := readIRInput(cmd, siteInputPath)
+	if err != nil {
+		return fmt.Errorf("reading input: %w", err)
+	}
+	if siteSessions {
+		ir.SortByTimestamp(records)
+	}
+
+	gen := sitegen.NewGenerator(siteOutputPath, opts)
+	p := newProgress(cmd, "Processing", progressRecords, int64(len(records)))
+	for start := 0; start < len(records); start += siteProgressBatch {
+		end := min(start+siteProgressBatch, len(records))
+		gen.ProcessRecords(records[start:end])
+		p.Add(int64(end - start))
+	}
+	p.Done()
+
+	if err := gen.Generate(); err != nil {
 		return fmt.Errorf("generating site: %w", err)
 	}
 
-	cmd.Printf("Site generated successfully at %s\n", siteOutputPath)
+	stats := gen.Stats()
+	cmd.Printf("Site generated successfully at %s (%d files written, %d unchanged)\n",
+		siteOutputPath, stats.Written, stats.Unchanged)
 	return nil
 }
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/grokify/omnistorage"
+	_ "github.com/grokify/omnistorage/backend/file" // registers the file backend
+	_ "github.com/grokify/omnistorage/backend/s3"   // registers the s3 backend
+	"github.com/grokify/traffic2openapi/pkg/sitegen"
+	"github.com/spf13/cobra"
+)
+
+var sitePublishCmd = &cobra.Command{
+	Use:   "publish <site-dir>",
+	Short: "Publish a generated site to a storage backend or GitHub Pages branch",
+	Long: `Publish a site generated by the site command.
+
+With --backend, every file is copied to an omnistorage backend keeping the
+directory layout, with a content type derived from its extension.
+--cache-control sets the Cache-Control header of every object; only the s3
+backend supports it. Backends are configured with repeated
+--config key=value flags: the file backend takes root=<dir>, and the s3
+backend takes bucket, region, and optionally endpoint and use_path_style
+for S3-compatible stores. S3 credentials come from the AWS environment or
+the access_key_id and secret_access_key keys.
+
+With --gh-pages, the site becomes the content of a branch of a local git
+repository, committed on top of the branch's previous commit without touching
+the working tree. --push pushes the branch to a remote for GitHub Pages to
+serve it.
+
+Examples:
+  # Copy the site into a directory served by a web server
+  traffic2openapi site publish ./site/ --backend file --config root=/srv/www/api-docs
+
+  # Upload the site to an S3 bucket under docs/api/
+  traffic2openapi site publish ./site/ --backend s3 --config bucket=my-docs \
+    --config region=us-east-1 --prefix docs/api
+
+  # Commit the site to the gh-pages branch and push it
+  traffic2openapi site publish ./site/ --gh-pages gh-pages --push origin`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSitePublish,
+}
+
+var (
+	publishBackend      string
+	publishConfig       []string
+	publishPrefix       string
+	publishCacheControl string
+	publishBranch       string
+	publishRepo         string
+	publishMessage      string
+	publishRemote       string
+)
+
+func init() {
+	siteCmd.AddCommand(sitePublishCmd)
+
+	sitePublishCmd.Flags().StringVar(&publishBackend, "backend", "", "omnistorage backend to publish to: file or s3")
+	sitePublishCmd.Flags().StringArrayVar(&publishConfig, "config", nil, "Backend configuration as key=value (can be repeated)")
+	sitePublishCmd.Flags().StringVar(&publishPrefix, "prefix", "", "Path prefix for published objects")
+	sitePublishCmd.Flags().StringVar(&publishCacheControl, "cache-control", "", "Cache-Control header for published objects, e.g. max-age=300 (s3 backend)")
+	sitePublishCmd.Flags().StringVar(&publishBranch, "gh-pages", "", "Commit the site to this branch of a git repository instead")
+	sitePublishCmd.Flags().StringVar(&publishRepo, "repo", ".", "Git repository for --gh-pages")
+	sitePublishCmd.Flags().StringVar(&publishMessage, "message", "Publish API traffic documentation", "Commit message for --gh-pages")
+	sitePublishCmd.Flags().StringVar(&publishRemote, "push", "", "Push the --gh-pages branch to this remote")
+	sitePublishCmd.MarkFlagsMutuallyExclusive("backend", "gh-pages")
+	sitePublishCmd.MarkFlagsOneRequired("backend", "gh-pages")
+}
+
+func runSitePublish(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+	if info, err := os.Stat(filepath.Join(dir, "index.html")); err != nil || info.IsDir() {
+		return inputError(fmt.Errorf("%s is not a generated site (no index.html)", dir))
+	}
+
+	if publishBranch != "" {
+		return publishGitBranch(cmd, dir)
+	}
+
+	config := make(map[string]string, len(publishConfig))
+	for _, kv := range publishConfig {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return inputError(fmt.Errorf("invalid --config: %s (use key=value)", kv))
+		}
+		config[key] = value
+	}
+	backend, err := omnistorage.Open(publishBackend, config)
+	if err != nil {
+		return inputError(fmt.Errorf("opening backend (available: %s): %w", strings.Join(omnistorage.Backends(), ", "), err))
+	}
+	defer backend.Close()
+	if publishBackend == "s3" {
+		if backend, err = newS3HeaderBackend(cmd.Context(), backend, config); err != nil {
+			return inputError(err)
+		}
+	}
+
+	count, err := sitegen.Publish(cmd.Context(), dir, backend, sitegen.PublishOptions{
+		Prefix:       publishPrefix,
+		CacheControl: publishCacheControl,
+	})
+	if errors.Is(err, sitegen.ErrCacheControlUnsupported) {
+		return inputError(fmt.Errorf("--cache-control is not supported by the %s backend", publishBackend))
+	}
+	if err != nil {
+		return err
+	}
+	cmd.Printf("Published %d files to %s backend\n", count, publishBackend)
+	return nil
+}
+
+// publishGitBranch commits the site directory as the tree of publishBranch,
+// using a temporary index so the repository's working tree and index are
+// left untouched.
+func publishGitBranch(cmd *cobra.Command, dir string) error {
+	siteDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	gitDir, err := git(publishRepo, nil, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return inputError(fmt.Errorf("%s is not a git repository: %w", publishRepo, err))
+	}
+
+	index, err := os.CreateTemp("", "traffic2openapi-index-*")
+	if err != nil {
+		return err
+	}
+	indexPath := index.Name()
+	_ = index.Close()
+	_ = os.Remove(indexPath) // git creates the index
+	defer os.Remove(indexPath)
+
+	site := []string{"--git-dir=" + gitDir, "--work-tree=" + siteDir}
+	env := []string{"GIT_INDEX_FILE=" + indexPath}
+	if _, err := git(siteDir, env, append(site, "add", "--all", "--force", ".")...); err != nil {
+		return err
+	}
+	tree, err := git(siteDir, env, append(site, "write-tree")...)
+	if err != nil {
+		return err
+	}
+
+	ref := "refs/heads/" + publishBranch
+	commitArgs := []string{"commit-tree", tree, "-m", publishMessage}
+	parent, err := git(publishRepo, nil, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err == nil {
+		parentTree, err := git(publishRepo, nil, "rev-parse", parent+"^{tree}")
+		if err != nil {
+			return err
+		}
+		if parentTree == tree {
+			cmd.Printf("Branch %s is up to date\n", publishBranch)
+			return pushGitBranch(cmd, ref)
+		}
+		commitArgs = append(commitArgs, "-p", parent)
+	}
+
+	commit, err := git(publishRepo, nil, commitArgs...)
+	if err != nil {
+		return err
+	}
+	if _, err := git(publishRepo, nil, "update-ref", ref, commit); err != nil {
+		return err
+	}
+	cmd.Printf("Committed site to branch %s (%s)\n", publishBranch, commit[:min(len(commit), 12)])
+	return pushGitBranch(cmd, ref)
+}
+
+func pushGitBranch(cmd *cobra.Command, ref string) error {
+	if publishRemote == "" {
+		return nil
+	}
+	if _, err := git(publishRepo, nil, "push", publishRemote, ref+":"+ref); err != nil {
+		return err
+	}
+	cmd.Printf("Pushed %s to %s\n", publishBranch, publishRemote)
+	return nil
+}
+
+// git runs a git command in dir with extra environment variables and returns
+// its trimmed standard output.
+func git(dir string, env []string, args ...string) (string, error) {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	c.Env = append(os.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		subcommand := args[0]
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "-") {
+				subcommand = arg
+				break
+			}
+		}
+		return "", fmt.Errorf("git %s: %s", subcommand, msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/grokify/omnistorage"
+	omnis3 "github.com/grokify/omnistorage/backend/s3"
+	"github.com/grokify/traffic2openapi/pkg/sitegen"
+)
+
+// s3HeaderBackend makes the omnistorage s3 backend a sitegen.HeaderBackend.
+// Its writers can only store Cache-Control as x-amz-meta-* user metadata, so
+// objects are uploaded with PutObject, which sets the object's real headers.
+type s3HeaderBackend struct {
+	omnistorage.Backend
+	client *s3.Client
+	config omnis3.Config
+}
+
+// newS3HeaderBackend wraps an s3 backend opened from configMap, building an
+// S3 client from the same configuration keys.
+func newS3HeaderBackend(ctx context.Context, backend omnistorage.Backend, configMap map[string]string) (*s3HeaderBackend, error) {
+	cfg := omnis3.ConfigFromMap(configMap)
+
+	var optFns []func(*config.LoadOptions) error
+	if cfg.Region != "" {
+		optFns = append(optFns, config.WithRegion(cfg.Region))
+	}
+	if cfg.AccessKeyID != "" && cfg.SecretAccessKey != "" {
+		optFns = append(optFns, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken)))
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
+	return &s3HeaderBackend{Backend: backend, client: client, config: cfg}, nil
+}
+
+// NewHeaderWriter buffers an object and uploads it on Close with headers.
+func (b *s3HeaderBackend) NewHeaderWriter(ctx context.Context, p string, headers sitegen.ObjectHeaders) (io.WriteCloser, error) {
+	return &s3HeaderWriter{ctx: ctx, backend: b, key: path.Join(b.config.Prefix, p), headers: headers}, nil
+}
+
+type s3HeaderWriter struct {
+	bytes.Buffer
+	ctx     context.Context
+	backend *s3HeaderBackend
+	key     string
+	headers sitegen.ObjectHeaders
+}
+
+func (w *s3HeaderWriter) Close() error {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(w.backend.config.Bucket),
+		Key:    aws.String(w.key),
+		Body:   bytes.NewReader(w.Bytes()),
+	}
+	if w.headers.ContentType != "" {
+		input.ContentType = aws.String(w.headers.ContentType)
+	}
+	if w.headers.CacheControl != "" {
+		input.CacheControl = aws.String(w.headers.CacheControl)
+	}
+	if _, err := w.backend.client.PutObject(w.ctx, input); err != nil {
+		return fmt.Errorf("s3: uploading object: %w", err)
+	}
+	return nil
+}
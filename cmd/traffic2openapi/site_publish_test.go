@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grokify/omnistorage"
+)
+
+func TestPublishBackendsRegistered(t *testing.T) {
+	for _, name := range []string{"file", "s3"} {
+		if !omnistorage.IsRegistered(name) {
+			t.Errorf("backend %s is not registered (available: %v)", name, omnistorage.Backends())
+		}
+	}
+}
+
+// writeSite writes a generated site with an index page and a users page.
+func writeSite(t *testing.T) string {
+	t.Helper()
+	site := t.TempDir()
+	for name, content := range map[string]string{
+		"index.html":       "<html></html>",
+		"users/index.html": "<html>users</html>",
+	} {
+		path := filepath.Join(site, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return site
+}
+
+func TestSitePublishFileBackend(t *testing.T) {
+	site := writeSite(t)
+	root := t.TempDir()
+	out, err := runCLI(t, "site", "publish", site, "--backend", "file", "--config", "root="+root, "--prefix", "docs")
+	if err != nil {
+		t.Fatalf("publish failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Published 2 files to file backend") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "docs", "users", "index.html"))
+	if err != nil || string(data) != "<html>users</html>" {
+		t.Errorf("expected published users/index.html, got %q (%v)", data, err)
+	}
+}
+
+func TestSitePublishRejectsNonSite(t *testing.T) {
+	if _, err := runCLI(t, "site", "publish", t.TempDir(), "--backend", "file"); err == nil {
+		t.Error("expected an error for a directory without index.html")
+	}
+}
+
+func TestSitePublishS3CacheControl(t *testing.T) {
+	var mu sync.Mutex
+	headers := make(map[string]http.Header)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			_, _ = io.Copy(io.Discard, r.Body)
+			mu.Lock()
+			headers[r.URL.Path] = r.Header.Clone()
+			mu.Unlock()
+		}
+	}))
+	defer server.Close()
+
+	out, err := runCLI(t, "site", "publish", writeSite(t), "--backend", "s3",
+		"--config", "bucket=docs", "--config", "region=us-east-1", "--config", "endpoint="+server.URL,
+		"--config", "use_path_style=true", "--config", "access_key_id=key", "--config", "secret_access_key=secret",
+		"--prefix", "api", "--cache-control", "max-age=300")
+	if err != nil {
+		t.Fatalf("publish failed: %v\n%s", err, out)
+	}
+	for _, key := range []string{"/docs/api/index.html", "/docs/api/users/index.html"} {
+		header, ok := headers[key]
+		if !ok {
+			t.Errorf("%s not uploaded (uploaded: %v)", key, headers)
+			continue
+		}
+		if got := header.Get("Cache-Control"); got != "max-age=300" {
+			t.Errorf("%s: expected Cache-Control max-age=300, got %q", key, got)
+		}
+		if got := header.Get("Content-Type"); got != "text/html; charset=utf-8" {
+			t.Errorf("%s: unexpected Content-Type %q", key, got)
+		}
+		if got := header.Get("X-Amz-Meta-Cache-Control"); got != "" {
+			t.Errorf("%s: unexpected Cache-Control user metadata %q", key, got)
+		}
+	}
+}
+
+func TestSitePublishRejectsUnsupportedCacheControl(t *testing.T) {
+	root := t.TempDir()
+	_, err := runCLI(t, "site", "publish", writeSite(t), "--backend", "file", "--config", "root="+root,
+		"--cache-control", "max-age=300")
+	if code := outcomeCode(err); code != exitInput {
+		t.Fatalf("expected exit code %d, got %d (%v)", exitInput, code, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "index.html")); err == nil {
+		t.Error("expected nothing to be published")
+	}
+}
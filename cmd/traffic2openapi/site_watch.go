@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/sitegen"
+	"github.com/spf13/cobra"
+)
+
+// siteWatcher keeps a site up to date with its input for site --watch.
+// Records appended to NDJSON files are processed once and only the pages of
+// their endpoints are regenerated; other changes to the input rebuild the
+// site from scratch.
+type siteWatcher struct {
+	mu   sync.Mutex
+	cmd  *cobra.Command
+	opts *sitegen.Options
+	tail *irTail
+	gen  *sitegen.Generator
+}
+
+func newSiteWatcher(cmd *cobra.Command, opts *sitegen.Options) *siteWatcher {
+	w := &siteWatcher{cmd: cmd, opts: opts, tail: newIRTail(cmd, siteInputPath)}
+	w.reset()
+	return w
+}
+
+func (w *siteWatcher) reset() {
+	w.gen = sitegen.NewGenerator(siteOutputPath, w.opts)
+	w.tail.reset()
+}
+
+// build processes the whole input and generates the full site.
+func (w *siteWatcher) build() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rebuild()
+}
+
+func (w *siteWatcher) rebuild() error {
+	w.reset()
+	n, _, err := w.ingest()
+	if err != nil {
+		return err
+	}
+	if err := w.gen.Generate(); err != nil {
+		return fmt.Errorf("generating site: %w", err)
+	}
+	stats := w.gen.Stats()
+	w.cmd.Printf("Site generated at %s (%d records, %d files written, %d unchanged)\n",
+		siteOutputPath, n, stats.Written, stats.Unchanged)
+	return nil
+}
+
+// update processes new input and regenerates the pages it changed.
+func (w *siteWatcher) update() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n, rebuild, err := w.ingest()
+	if err != nil {
+		return err
+	}
+	if rebuild {
+		w.cmd.Println("Input rewritten, rebuilding site")
+		return w.rebuild()
+	}
+	if n == 0 {
+		return nil
+	}
+
+	pages, err := w.gen.GenerateChanged()
+	if err != nil {
+		return fmt.Errorf("generating site: %w", err)
+	}
+	w.cmd.Printf("Added %d records, regenerated %d endpoint pages\n", n, pages)
+	return nil
+}
+
+// ingest processes the input not yet processed and returns the number of
+// records added, or reports rebuild when the input was rewritten.
+func (w *siteWatcher) ingest() (int, bool, error) {
+	records, rebuild, err := w.tail.next()
+	if err != nil || rebuild {
+		return 0, rebuild, err
+	}
+	if w.opts.Sessions {
+		ir.SortByTimestamp(records)
+	}
+	w.gen.ProcessRecords(records)
+	return len(records), false, nil
+}
+
+func runSiteWatch(cmd *cobra.Command, opts *sitegen.Options) error {
+	cmd.Println("Starting watch mode...")
+	w := newSiteWatcher(cmd, opts)
+	if err := w.build(); err != nil {
+		cmd.Printf("Initial generation failed: %v\n", err)
+	}
+	cmd.Println("Press Ctrl+C to stop")
+	return watchIRInput(cmd, siteInputPath, siteDebounce, w.update)
+}
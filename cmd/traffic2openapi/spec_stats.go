@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var specStatsCmd = &cobra.Command{
+	Use:   "spec-stats <spec-file|url>",
+	Short: "Report size and documentation completeness of an OpenAPI spec",
+	Long: `Report statistics for an OpenAPI specification.
+
+Counts paths, operations, parameters, component schemas and other
+components, and the operations declaring each response code, along with the
+percentage of operations with a description and with examples, and the
+average nesting depth of request, response, and component schemas.
+
+Run it on each release and keep the JSON output to track documentation
+completeness over time.
+
+Examples:
+  # Print statistics
+  traffic2openapi spec-stats api.yaml
+
+  # Output as JSON for dashboards
+  traffic2openapi spec-stats api.yaml --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSpecStats,
+}
+
+var specStatsFormat string
+
+func init() {
+	rootCmd.AddCommand(specStatsCmd)
+
+	specStatsCmd.Flags().StringVarP(&specStatsFormat, "format", "f", "text", "Output format: text or json")
+	addResolveRefsFlag(specStatsCmd)
+}
+
+func runSpecStats(cmd *cobra.Command, args []string) error {
+	if specStatsFormat != "text" && specStatsFormat != "json" {
+		return inputError(fmt.Errorf("unsupported format: %s (use text or json)", specStatsFormat))
+	}
+
+	spec, err := readSpec(args[0])
+	if err != nil {
+		return inputError(fmt.Errorf("reading spec: %w", err))
+	}
+
+	stats := openapi.Statistics(spec)
+	if specStatsFormat == "json" {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	cmd.Printf("Paths:       %d\n", stats.Paths)
+	cmd.Printf("Operations:  %d\n", stats.Operations)
+	cmd.Printf("Parameters:  %d\n", stats.Parameters)
+	cmd.Printf("Schemas:     %d\n", stats.Schemas)
+	if len(stats.Components) > 0 {
+		cmd.Printf("Components:  %s\n", formatCounts(stats.Components))
+	}
+	if len(stats.ResponseCodes) > 0 {
+		cmd.Printf("Responses:   %s\n", formatCounts(stats.ResponseCodes))
+	}
+	cmd.Printf("\nSummaries:    %d/%d operations (%.1f%%)\n",
+		stats.OperationsWithSummary, stats.Operations, stats.SummaryPercent)
+	cmd.Printf("Descriptions: %d/%d operations (%.1f%%)\n",
+		stats.OperationsWithDescription, stats.Operations, stats.DescriptionPercent)
+	cmd.Printf("Examples:     %d/%d operations (%.1f%%)\n",
+		stats.OperationsWithExamples, stats.Operations, stats.ExamplePercent)
+	cmd.Printf("Schema depth: %.1f average, %d max\n", stats.AverageSchemaDepth, stats.MaxSchemaDepth)
+	return nil
+}
+
+// formatCounts formats counts as "key count" pairs sorted by key.
+func formatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s %d", key, counts[key])
+	}
+	return strings.Join(parts, ", ")
+}
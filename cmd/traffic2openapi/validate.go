@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/grokify/traffic2openapi/pkg/cireport"
 	"github.com/grokify/traffic2openapi/pkg/ir"
 	"github.com/spf13/cobra"
 )
@@ -16,7 +17,8 @@ var validateCmd = &cobra.Command{
 	Long: `Validate Intermediate Representation (IR) files for correctness.
 
 This command reads IR files and checks that they conform to the IR schema,
-reporting any parsing errors or invalid records.
+reporting any parsing errors or invalid records. It exits with status 1 (4
+with --detailed-exit-codes) if any file fails validation.
 
 Examples:
   # Validate a single file
@@ -26,32 +28,43 @@ Examples:
   traffic2openapi validate ./logs/
 
   # Validate with verbose output
-  traffic2openapi validate ./logs/ --verbose`,
+  traffic2openapi validate ./logs/ --verbose
+
+  # JUnit XML for CI test reports
+  traffic2openapi validate ./logs/ --format junit > validate.xml`,
 	Args: cobra.ExactArgs(1),
 	RunE: runValidate,
 }
 
-var verboseValidate bool
+var (
+	verboseValidate bool
+	validateFormat  string
+)
 
 func init() {
 	rootCmd.AddCommand(validateCmd)
 
 	validateCmd.Flags().BoolVarP(&verboseValidate, "verbose", "V", false, "Show detailed validation results")
+	validateCmd.Flags().StringVarP(&validateFormat, "format", "f", "text", "Report format: text, junit, or sarif")
 }
 
 func runValidate(cmd *cobra.Command, args []string) error {
+	if validateFormat != "text" && !cireport.IsFormat(validateFormat) {
+		return inputError(fmt.Errorf("unsupported format: %s (use text, junit, or sarif)", validateFormat))
+	}
+
 	inputPath := args[0]
 
 	info, err := os.Stat(inputPath)
 	if err != nil {
-		return fmt.Errorf("input path error: %w", err)
+		return inputError(fmt.Errorf("input path error: %w", err))
 	}
 
 	var files []string
 	if info.IsDir() {
 		entries, err := os.ReadDir(inputPath)
 		if err != nil {
-			return fmt.Errorf("reading directory: %w", err)
+			return inputError(fmt.Errorf("reading directory: %w", err))
 		}
 		for _, entry := range entries {
 			if entry.IsDir() {
@@ -67,22 +80,34 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(files) == 0 {
-		return fmt.Errorf("no IR files found")
+		return inputError(fmt.Errorf("no IR files found"))
 	}
 
 	totalRecords := 0
 	totalErrors := 0
 	validFiles := 0
 
+	report := cireport.NewReport("traffic2openapi validate", version)
+	report.Rule("invalid-ir", "IR file fails to parse or validate")
+
 	for _, file := range files {
 		records, err := ir.ReadFile(file)
 		if err != nil {
 			cmd.Printf("FAIL %s: %v\n", filepath.Base(file), err)
 			totalErrors++
+			report.Add(cireport.Check{
+				Name:    filepath.Base(file),
+				Group:   "validate",
+				RuleID:  "invalid-ir",
+				Level:   cireport.LevelError,
+				Message: err.Error(),
+				File:    file,
+			})
 			continue
 		}
 
 		validFiles++
+		report.Add(cireport.Check{Name: filepath.Base(file), Group: "validate", File: file})
 		totalRecords += len(records)
 
 		if verboseValidate {
@@ -105,8 +130,14 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	cmd.Printf("  Files:   %d valid, %d invalid, %d total\n", validFiles, totalErrors, len(files))
 	cmd.Printf("  Records: %d total\n", totalRecords)
 
+	if validateFormat != "text" {
+		if err := report.Write(os.Stdout, validateFormat); err != nil {
+			return err
+		}
+	}
+
 	if totalErrors > 0 {
-		return fmt.Errorf("%d file(s) failed validation", totalErrors)
+		return withExitCode(exitValidation, fmt.Errorf("%d file(s) failed validation", totalErrors))
 	}
 
 	cmd.Printf("\nAll files valid.\n")
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/grokify/traffic2openapi/pkg/cireport"
 	"github.com/grokify/traffic2openapi/pkg/openapi/validate"
 	"github.com/spf13/cobra"
 )
@@ -17,6 +18,8 @@ var validateSpecCmd = &cobra.Command{
 
 This command reads OpenAPI specification files (YAML or JSON) and validates
 them against the OpenAPI specification, reporting any errors or warnings.
+It exits with status 1 (4 with --detailed-exit-codes) if any file has
+errors, or warnings with --fail-on warning.
 
 Supports OpenAPI 3.0.x, 3.1.x, and 3.2.x specifications.
 
@@ -28,7 +31,10 @@ Examples:
   traffic2openapi validate-spec ./specs/
 
   # Validate with verbose output showing warnings
-  traffic2openapi validate-spec openapi.yaml --verbose`,
+  traffic2openapi validate-spec openapi.yaml --verbose
+
+  # SARIF for code scanning
+  traffic2openapi validate-spec ./specs/ --format sarif > specs.sarif`,
 	Args: cobra.ExactArgs(1),
 	RunE: runValidateSpec,
 }
@@ -37,29 +43,44 @@ var (
 	verboseSpec  bool
 	strictSpec   bool
 	showWarnings bool
+	specFormat   string
+	specFailOn   string
 )
 
 func init() {
 	rootCmd.AddCommand(validateSpecCmd)
 
 	validateSpecCmd.Flags().BoolVarP(&verboseSpec, "verbose", "V", false, "Show detailed validation results")
-	validateSpecCmd.Flags().BoolVar(&strictSpec, "strict", false, "Treat warnings as errors")
+	validateSpecCmd.Flags().BoolVar(&strictSpec, "strict", false, "Treat warnings as errors (same as --fail-on warning)")
+	validateSpecCmd.Flags().StringVar(&specFailOn, "fail-on", "error", "Lowest severity that fails validation: error or warning")
 	validateSpecCmd.Flags().BoolVarP(&showWarnings, "warnings", "w", true, "Show warnings (default: true)")
+	validateSpecCmd.Flags().StringVarP(&specFormat, "format", "f", "text", "Report format: text, junit, or sarif")
 }
 
 func runValidateSpec(cmd *cobra.Command, args []string) error {
+	if specFormat != "text" && !cireport.IsFormat(specFormat) {
+		return inputError(fmt.Errorf("unsupported format: %s (use text, junit, or sarif)", specFormat))
+	}
+	switch specFailOn {
+	case "error":
+	case "warning":
+		strictSpec = true
+	default:
+		return inputError(fmt.Errorf("unsupported --fail-on: %s (use error or warning)", specFailOn))
+	}
+
 	inputPath := args[0]
 
 	info, err := os.Stat(inputPath)
 	if err != nil {
-		return fmt.Errorf("input path error: %w", err)
+		return inputError(fmt.Errorf("input path error: %w", err))
 	}
 
 	var files []string
 	if info.IsDir() {
 		entries, err := os.ReadDir(inputPath)
 		if err != nil {
-			return fmt.Errorf("reading directory: %w", err)
+			return inputError(fmt.Errorf("reading directory: %w", err))
 		}
 		for _, entry := range entries {
 			if entry.IsDir() {
@@ -75,7 +96,7 @@ func runValidateSpec(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(files) == 0 {
-		return fmt.Errorf("no OpenAPI specification files found")
+		return inputError(fmt.Errorf("no OpenAPI specification files found"))
 	}
 
 	totalErrors := 0
@@ -83,14 +104,26 @@ func runValidateSpec(cmd *cobra.Command, args []string) error {
 	validFiles := 0
 	invalidFiles := 0
 
+	report := cireport.NewReport("traffic2openapi validate-spec", version)
+	report.Rule("invalid-spec", "OpenAPI spec fails to parse or validate")
+
 	for _, file := range files {
 		result, err := validate.ValidateFile(file)
 		if err != nil {
 			cmd.Printf("ERROR %s: %v\n", filepath.Base(file), err)
 			invalidFiles++
 			totalErrors++
+			report.Add(cireport.Check{
+				Name:    filepath.Base(file),
+				Group:   "validate-spec",
+				RuleID:  "invalid-spec",
+				Level:   cireport.LevelError,
+				Message: err.Error(),
+				File:    file,
+			})
 			continue
 		}
+		addSpecChecks(report, file, result)
 
 		fileErrors := len(result.Errors)
 		fileWarnings := len(result.Warnings)
@@ -136,14 +169,58 @@ func runValidateSpec(cmd *cobra.Command, args []string) error {
 		cmd.Printf("  Warnings: %d\n", totalWarnings)
 	}
 
+	if specFormat != "text" {
+		if err := report.Write(os.Stdout, specFormat); err != nil {
+			return err
+		}
+	}
+
 	if invalidFiles > 0 {
-		return fmt.Errorf("%d file(s) failed validation", invalidFiles)
+		return withExitCode(exitValidation, fmt.Errorf("%d file(s) failed validation", invalidFiles))
 	}
 
 	if strictSpec && totalWarnings > 0 {
-		return fmt.Errorf("%d warning(s) found (strict mode)", totalWarnings)
+		return withExitCode(exitValidation, fmt.Errorf("%d warning(s) found (strict mode)", totalWarnings))
 	}
 
 	cmd.Printf("\nAll files valid.\n")
 	return nil
 }
+
+// addSpecChecks adds a file's validation errors and warnings to a report,
+// or a passing check if it has neither. Warnings are errors in strict mode.
+func addSpecChecks(report *cireport.Report, file string, result *validate.ValidationResult) {
+	name := filepath.Base(file)
+	add := func(v validate.ValidationError, level cireport.Level) {
+		ruleID := v.RuleID
+		if ruleID == "" {
+			ruleID = "invalid-spec"
+		}
+		report.Add(cireport.Check{
+			Name:    name,
+			Group:   "validate-spec",
+			RuleID:  ruleID,
+			Level:   level,
+			Message: v.Message,
+			File:    file,
+			Line:    v.Line,
+			Column:  v.Column,
+		})
+	}
+
+	for _, e := range result.Errors {
+		add(e, cireport.LevelError)
+	}
+	warningLevel := cireport.LevelWarning
+	if strictSpec {
+		warningLevel = cireport.LevelError
+	}
+	if showWarnings || strictSpec {
+		for _, w := range result.Warnings {
+			add(w, warningLevel)
+		}
+	}
+	if len(result.Errors) == 0 && (len(result.Warnings) == 0 || (!showWarnings && !strictSpec)) {
+		report.Add(cireport.Check{Name: name, Group: "validate-spec", File: file})
+	}
+}
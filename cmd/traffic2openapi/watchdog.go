@@ -0,0 +1,327 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var watchdogCmd = &cobra.Command{
+	Use:   "watchdog",
+	Short: "Alert when live traffic drifts from a baseline spec",
+	Long: `Tail an IR stream and alert when traffic departs from a baseline OpenAPI
+spec: undocumented endpoints, unexpected status codes, undocumented body
+fields, and payloads that violate the declared schemas.
+
+The input is read once at startup, then records appended to its NDJSON files
+are checked as they arrive. Use -i - to check NDJSON records piped to stdin.
+New records are checked together with the records before them, up to
+--window records, so schemas are inferred from more than a single record.
+Each finding is alerted once, as a JSON line on stdout, and is also posted to
+--notify if set. With --exit-on-alert the watchdog stops at the first alert,
+exiting with status 1 (with --detailed-exit-codes, 3 for schema violations
+and 2 for other drift).
+
+The categories are those of the ci command:
+
+  endpoints  observed endpoints the spec has no operation for
+  statuses   observed status codes an operation declares no response for
+  fields     observed body fields the spec's schemas don't declare
+  breaking   observed values of a type the schema doesn't allow, and
+             required response fields missing from responses
+
+Examples:
+  # Alert on drift as captures are appended
+  traffic2openapi watchdog --spec api.yaml -i captures/
+
+  # Post alerts to a Slack channel
+  traffic2openapi watchdog --spec api.yaml -i traffic.ndjson --notify "$SLACK_WEBHOOK_URL" --notify-format slack
+
+  # Check a baseline whose schemas are split across files
+  traffic2openapi watchdog --spec api.yaml --resolve-refs -i captures/
+
+  # Check a live capture pipe, stopping at the first schema violation
+  capture-proxy | traffic2openapi watchdog --spec api.yaml -i - --alert-on breaking --exit-on-alert`,
+	RunE: runWatchdog,
+}
+
+var (
+	watchdogSpecPath    string
+	watchdogInputPath   string
+	watchdogAlertOn     []string
+	watchdogExitOnAlert bool
+	watchdogDebounce    time.Duration
+	watchdogWindow      int
+)
+
+func init() {
+	rootCmd.AddCommand(watchdogCmd)
+
+	watchdogCmd.Flags().StringVar(&watchdogSpecPath, "spec", "", "Baseline OpenAPI spec to check against (required)")
+	watchdogCmd.Flags().StringVarP(&watchdogInputPath, "input", "i", "", "Input file or directory containing IR files, or - for NDJSON on stdin (required)")
+	watchdogCmd.Flags().StringSliceVar(&watchdogAlertOn, "alert-on", []string{ciFailEndpoints, ciFailStatuses, ciFailBreaking},
+		"Drift categories to alert on (comma-separated: endpoints,statuses,fields,breaking)")
+	watchdogCmd.Flags().BoolVar(&watchdogExitOnAlert, "exit-on-alert", false, "Exit at the first alert (see --detailed-exit-codes)")
+	watchdogCmd.Flags().DurationVar(&watchdogDebounce, "debounce", 500*time.Millisecond, "Debounce duration for input changes")
+	watchdogCmd.Flags().IntVar(&watchdogWindow, "window", 100, "Recent records checked together with each new record")
+	addNotifyFlags(watchdogCmd)
+	addResolveRefsFlag(watchdogCmd)
+
+	_ = watchdogCmd.MarkFlagRequired("spec")
+	_ = watchdogCmd.MarkFlagRequired("input")
+}
+
+// watchdogAlert is a drift finding reported by the watchdog.
+type watchdogAlert struct {
+	Time      time.Time `json:"time"`
+	Category  string    `json:"category"` // ci drift category, e.g. "breaking"
+	Kind      string    `json:"kind"`     // e.g. "undocumented-status" or openapi.DriftTypeMismatch
+	Endpoint  string    `json:"endpoint"` // "METHOD /path"
+	Location  string    `json:"location,omitempty"`
+	Status    int       `json:"status,omitempty"`
+	Field     string    `json:"field,omitempty"`
+	Message   string    `json:"message"`
+	RecordIDs []string  `json:"recordIds,omitempty"`
+}
+
+// key identifies the finding, so it is alerted once however often it recurs.
+func (a watchdogAlert) key() string {
+	return a.Kind + " " + a.Endpoint + " " + a.Location + " " + strconv.Itoa(a.Status) + " " + a.Field
+}
+
+// watchdog checks batches of records against the baseline spec and alerts
+// on findings not alerted before.
+type watchdog struct {
+	mu   sync.Mutex // serializes checks of input updates
+	cmd  *cobra.Command
+	spec *openapi.Spec
+	seen map[string]bool
+
+	// recent holds the latest records, checked together with new ones.
+	recent []ir.IRRecord
+
+	// stop receives the exit error when --exit-on-alert fires.
+	stop chan error
+}
+
+func runWatchdog(cmd *cobra.Command, args []string) error {
+	for _, category := range watchdogAlertOn {
+		switch category {
+		case ciFailEndpoints, ciFailStatuses, ciFailFields, ciFailBreaking:
+		default:
+			return inputError(fmt.Errorf("unsupported drift category: %s (use endpoints, statuses, fields, or breaking)", category))
+		}
+	}
+	if err := checkNotifyFlags(); err != nil {
+		return err
+	}
+	if watchdogWindow < 1 {
+		return inputError(fmt.Errorf("--window must be at least 1"))
+	}
+
+	spec, err := readSpec(watchdogSpecPath)
+	if err != nil {
+		return inputError(fmt.Errorf("reading spec: %w", err))
+	}
+
+	w := &watchdog{
+		cmd:  cmd,
+		spec: spec,
+		seen: make(map[string]bool),
+		stop: make(chan error, 1),
+	}
+	if watchdogInputPath == "-" {
+		return w.watchStdin()
+	}
+	return w.watchInput()
+}
+
+// watchStdin checks NDJSON records from stdin as they arrive until EOF.
+func (w *watchdog) watchStdin() error {
+	w.cmd.Printf("Checking records from stdin against %s\n", watchdogSpecPath)
+	reader := ir.NewNDJSONReader(w.cmd.InOrStdin())
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var invalid *ir.ErrInvalidRecord
+		if errors.As(err, &invalid) {
+			w.cmd.Printf("Warning: skipping invalid record: %v\n", err)
+			continue
+		}
+		if err != nil {
+			return inputError(fmt.Errorf("reading stdin: %w", err))
+		}
+		if err := w.checkNew([]ir.IRRecord{*record}); err != nil {
+			return err
+		}
+	}
+}
+
+// watchInput checks the input, then the records appended to it, until
+// interrupted or --exit-on-alert fires.
+func (w *watchdog) watchInput() error {
+	tail := newIRTail(w.cmd, watchdogInputPath)
+	update := func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		records, rebuild, err := tail.next()
+		if rebuild {
+			// Findings already alerted stay silenced, so rereading is safe
+			w.cmd.Println("Input rewritten, checking it again")
+			tail.reset()
+			w.recent = nil
+			records, _, err = tail.next()
+		}
+		if err != nil {
+			return err
+		}
+		if err := w.checkNew(records); err != nil {
+			select {
+			case w.stop <- err:
+			default:
+			}
+		}
+		return nil
+	}
+
+	if err := update(); err != nil {
+		return err
+	}
+	select {
+	case err := <-w.stop:
+		return err
+	default:
+	}
+
+	w.cmd.Printf("Checking traffic against %s\n", watchdogSpecPath)
+	go func() {
+		w.stop <- watchIRInput(w.cmd, watchdogInputPath, watchdogDebounce, update)
+	}()
+	return <-w.stop
+}
+
+// checkNew checks new records together with the recent records before them,
+// up to --window records in all (or all of the new records, if more).
+func (w *watchdog) checkNew(records []ir.IRRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	w.recent = append(w.recent, records...)
+	window := w.recent[max(0, len(w.recent)-max(len(records), watchdogWindow)):]
+	err := w.check(window)
+
+	// Trim the recent records once they are twice the window
+	if len(w.recent) >= 2*watchdogWindow {
+		w.recent = slices.Clone(w.recent[len(w.recent)-watchdogWindow:])
+	}
+	return err
+}
+
+// check alerts on the findings in records not alerted before. With
+// --exit-on-alert it returns the exit error once any alert was sent.
+func (w *watchdog) check(records []ir.IRRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	result := inference.InferFromRecords(records)
+	report := openapi.Drift(w.spec, result, records)
+
+	code := exitOK
+	for _, alert := range watchdogAlerts(report) {
+		if !slices.Contains(watchdogAlertOn, alert.Category) || w.seen[alert.key()] {
+			continue
+		}
+		w.seen[alert.key()] = true
+		alert.Time = time.Now().UTC()
+		if err := w.emit(alert); err != nil {
+			return err
+		}
+		if alert.Category == ciFailBreaking {
+			code = exitBreaking
+		} else if code == exitOK {
+			code = exitDifferences
+		}
+	}
+	if watchdogExitOnAlert && code != exitOK {
+		return withExitCode(code, nil)
+	}
+	return nil
+}
+
+// emit prints an alert as a JSON line and posts it to --notify, if set.
+func (w *watchdog) emit(alert watchdogAlert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("encoding alert: %w", err)
+	}
+	fmt.Fprintln(w.cmd.OutOrStdout(), string(data))
+
+	notify(w.cmd, notification{
+		Command:  "watchdog",
+		Title:    alert.Message,
+		Breaking: alert.Category == ciFailBreaking,
+		Report:   alert,
+	})
+	return nil
+}
+
+// watchdogAlerts converts drift findings to alerts in report order.
+func watchdogAlerts(report *openapi.DriftReport) []watchdogAlert {
+	var alerts []watchdogAlert
+	for _, endpoint := range report.UndocumentedEndpoints {
+		alerts = append(alerts, watchdogAlert{
+			Category: ciFailEndpoints,
+			Kind:     "undocumented-endpoint",
+			Endpoint: endpoint,
+			Message:  "Undocumented endpoint " + endpoint,
+		})
+	}
+	for _, s := range report.UndocumentedStatuses {
+		endpoint := s.Method + " " + s.Path
+		alerts = append(alerts, watchdogAlert{
+			Category:  ciFailStatuses,
+			Kind:      "undocumented-status",
+			Endpoint:  endpoint,
+			Status:    s.Status,
+			Message:   fmt.Sprintf("%s: undocumented status %d", endpoint, s.Status),
+			RecordIDs: s.RecordIDs,
+		})
+	}
+	for _, f := range report.UndocumentedFields {
+		endpoint := f.Method + " " + f.Path
+		alerts = append(alerts, watchdogAlert{
+			Category: ciFailFields,
+			Kind:     "undocumented-field",
+			Endpoint: endpoint,
+			Location: f.Location,
+			Field:    f.Field,
+			Message:  fmt.Sprintf("%s (%s): undocumented field %s", endpoint, f.Location, fieldWithType(f.Field, f.Type)),
+		})
+	}
+	for _, d := range report.Breaking {
+		endpoint := d.Method + " " + d.Path
+		alerts = append(alerts, watchdogAlert{
+			Category: ciFailBreaking,
+			Kind:     d.Kind,
+			Endpoint: endpoint,
+			Location: d.Location,
+			Field:    d.Field,
+			Message:  fmt.Sprintf("%s (%s): %s", endpoint, d.Location, driftDescription(d)),
+		})
+	}
+	return alerts
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const watchdogTestSpec = `openapi: 3.1.0
+info:
+  title: Users
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: "%s"
+`
+
+const watchdogTestUser = `type: object
+required: [id, email]
+properties:
+  id:
+    type: integer
+  email:
+    type: string
+`
+
+func writeWatchdogSpec(t *testing.T, ref string) string {
+	t.Helper()
+	dir := t.TempDir()
+	spec := strings.Replace(watchdogTestSpec, "%s", ref, 1)
+	if !strings.HasPrefix(ref, "#") {
+		if err := os.WriteFile(filepath.Join(dir, "user.yaml"), []byte(watchdogTestUser), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		spec += "components:\n  schemas:\n    User:\n" + indent(watchdogTestUser, "      ")
+	}
+	path := filepath.Join(dir, "api.yaml")
+	if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func indent(s, prefix string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	return prefix + strings.Join(lines, "\n"+prefix) + "\n"
+}
+
+const watchdogTestRecords = `{"request":{"method":"GET","path":"/users"},"response":{"status":200,"body":{"id":1,"email":"a@example.com"}}}
+{"request":{"method":"GET","path":"/users"},"response":{"status":200,"body":{"id":2}}}
+`
+
+func runWatchdogStdin(t *testing.T, input string, args ...string) (string, error) {
+	t.Helper()
+	rootCmd.SetIn(strings.NewReader(input))
+	defer rootCmd.SetIn(nil)
+	return runCLI(t, append([]string{"watchdog", "-i", "-"}, args...)...)
+}
+
+func TestWatchdogStdinWindow(t *testing.T) {
+	spec := writeWatchdogSpec(t, "#/components/schemas/User")
+
+	// email was observed within the window, so it is not missing
+	out, err := runWatchdogStdin(t, watchdogTestRecords, "--spec", spec)
+	if err != nil {
+		t.Fatalf("watchdog failed: %v\n%s", err, out)
+	}
+	if strings.Contains(out, `"kind":"missing-required"`) {
+		t.Errorf("expected no missing-required alert with a window, got:\n%s", out)
+	}
+
+	// Checked one record at a time, the second response misses email
+	out, err = runWatchdogStdin(t, watchdogTestRecords, "--spec", spec, "--window", "1")
+	if err != nil {
+		t.Fatalf("watchdog failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, `"kind":"missing-required"`) || !strings.Contains(out, `"field":"email"`) {
+		t.Errorf("expected missing-required alert for email, got:\n%s", out)
+	}
+}
+
+func TestWatchdogResolveRefs(t *testing.T) {
+	spec := writeWatchdogSpec(t, "user.yaml")
+	records := `{"request":{"method":"GET","path":"/users"},"response":{"status":200,"body":{"id":"1","email":"a@example.com"}}}
+`
+	out, err := runWatchdogStdin(t, records, "--spec", spec, "--resolve-refs")
+	if err != nil {
+		t.Fatalf("watchdog failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, `"kind":"type-mismatch"`) || !strings.Contains(out, `"field":"id"`) {
+		t.Errorf("expected type-mismatch alert for id from the referenced schema, got:\n%s", out)
+	}
+}
+
+func TestWatchdogExitOnAlert(t *testing.T) {
+	spec := writeWatchdogSpec(t, "#/components/schemas/User")
+	records := `{"request":{"method":"GET","path":"/orders"},"response":{"status":200}}
+`
+	out, err := runWatchdogStdin(t, records, "--spec", spec, "--exit-on-alert")
+	if err == nil || exitCode(err) != exitFailure {
+		t.Fatalf("expected failure exit, got %v\n%s", err, out)
+	}
+	if !strings.Contains(out, `"kind":"undocumented-endpoint"`) {
+		t.Errorf("expected undocumented endpoint alert, got:\n%s", out)
+	}
+}
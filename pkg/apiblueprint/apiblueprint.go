@@ -0,0 +1,591 @@
+// Package apiblueprint reads API Blueprint documents as OpenAPI specs, so
+// legacy Blueprint documents can be diffed against generated specs and
+// checked for coverage by captured traffic.
+//
+// Resource groups, resources, actions (including the "## GET /path" and
+// "### Name [GET /path]" forms), URI parameters, requests, responses, and
+// MSON attributes and data structures are read. Body schemas come from a
+// payload's Schema section, else its Attributes, else are inferred from its
+// JSON Body. Relations and blueprint references ([Name][]) are ignored.
+package apiblueprint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+)
+
+// ReadFile reads an API Blueprint document as an OpenAPI spec.
+func ReadFile(path string) (*openapi.Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses an API Blueprint document as an OpenAPI spec.
+func Parse(data []byte) (*openapi.Spec, error) {
+	text := strings.ReplaceAll(strings.ReplaceAll(string(data), "\r\n", "\n"), "\t", "    ")
+	lines := strings.Split(text, "\n")
+
+	p := &parser{
+		spec: &openapi.Spec{
+			OpenAPI: string(openapi.Version31),
+			Info:    openapi.Info{Version: "1.0.0"},
+			Paths:   make(map[string]*openapi.PathItem),
+		},
+		types: make(map[string]bool),
+	}
+	lines = p.metadata(lines)
+	sections := splitSections(lines)
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("parsing API Blueprint: no sections found")
+	}
+	p.collectTypes(sections)
+	p.convert(sections)
+	return p.spec, nil
+}
+
+// section is a heading and the lines up to the next heading.
+type section struct {
+	level int
+	title string
+	lines []string
+}
+
+var heading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// splitSections splits a document at its Markdown headings, skipping
+// fenced code blocks.
+func splitSections(lines []string) []section {
+	var sections []section
+	current := section{}
+	fenced := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+		}
+		if m := heading.FindStringSubmatch(line); m != nil && !fenced {
+			if current.level > 0 || len(current.lines) > 0 {
+				sections = append(sections, current)
+			}
+			current = section{level: len(m[1]), title: m[2]}
+			continue
+		}
+		current.lines = append(current.lines, line)
+	}
+	if current.level > 0 || len(current.lines) > 0 {
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+var (
+	groupHeading    = regexp.MustCompile(`^Group\s+(.+)$`)
+	resourceHeading = regexp.MustCompile(`^(?:(.*?)\s*)?\[(/[^\]]*)\]$`)
+	actionHeading   = regexp.MustCompile(`^(?:(.*?)\s*)?\[([A-Z]+)(?:\s+(/[^\]]*))?\]$`)
+	endpointHeading = regexp.MustCompile(`^([A-Z]+)\s+(/\S*)$`)
+	typeHeading     = regexp.MustCompile(`^(.+?)\s*(?:\(([^)]*)\))?$`)
+)
+
+// parser converts the sections of a document to a spec.
+type parser struct {
+	spec  *openapi.Spec
+	types map[string]bool // names of data structures and resource models
+
+	tag      string
+	resource *resource
+}
+
+// resource is the resource whose actions are being read.
+type resource struct {
+	path   string
+	query  []string // query parameters in the URI template
+	params []*param
+}
+
+func (p *parser) metadata(lines []string) []string {
+	for i, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if strings.TrimSpace(line) == "" || !ok || strings.ContainsAny(key, " #") {
+			return lines[i:]
+		}
+		if key == "HOST" {
+			p.spec.Servers = []openapi.Server{{URL: strings.TrimRight(strings.TrimSpace(value), "/")}}
+		}
+	}
+	return nil
+}
+
+// collectTypes records the names of named types, so type references can be
+// told apart from unknown names.
+func (p *parser) collectTypes(sections []section) {
+	dataLevel := 0
+	for _, s := range sections {
+		switch {
+		case dataLevel > 0 && s.level > dataLevel:
+			if m := typeHeading.FindStringSubmatch(s.title); m != nil {
+				p.types[m[1]] = true
+			}
+			continue
+		case s.title == "Data Structures":
+			dataLevel = s.level
+			continue
+		}
+		dataLevel = 0
+		if m := resourceHeading.FindStringSubmatch(s.title); m != nil && m[1] != "" {
+			_, items := parseOutline(s.lines)
+			if slices.ContainsFunc(items, func(it *item) bool { return keyword(it.text) == "Attributes" }) {
+				p.types[m[1]] = true
+			}
+		}
+	}
+}
+
+func (p *parser) convert(sections []section) {
+	dataLevel := 0
+	titled := false
+	for _, s := range sections {
+		if dataLevel > 0 && s.level > dataLevel {
+			p.dataStructure(s)
+			continue
+		}
+		dataLevel = 0
+
+		switch m := s.title; {
+		case s.level == 0:
+			// Text before the first heading
+		case m == "Data Structures":
+			dataLevel = s.level
+		case groupHeading.MatchString(m):
+			p.tag = groupHeading.FindStringSubmatch(m)[1]
+			p.resource = nil
+			desc, _ := parseOutline(s.lines)
+			p.spec.Tags = append(p.spec.Tags, openapi.Tag{Name: p.tag, Description: desc})
+		case actionHeading.MatchString(m):
+			g := actionHeading.FindStringSubmatch(m)
+			if g[3] != "" {
+				p.resource = newResource(g[3], nil)
+			}
+			if p.resource != nil {
+				p.action(g[1], g[2], s.lines)
+			}
+		case resourceHeading.MatchString(m):
+			g := resourceHeading.FindStringSubmatch(m)
+			p.resourceSection(g[1], g[2], s.lines)
+		case endpointHeading.MatchString(m):
+			g := endpointHeading.FindStringSubmatch(m)
+			p.resource = newResource(g[2], nil)
+			p.action("", g[1], s.lines)
+		case !titled:
+			titled = true
+			p.spec.Info.Title = m
+			p.spec.Info.Description, _ = parseOutline(s.lines)
+		}
+	}
+	if p.spec.Info.Title == "" {
+		p.spec.Info.Title = "API"
+	}
+}
+
+// resourceSection reads a resource's parameters and attributes.
+func (p *parser) resourceSection(name, uri string, lines []string) {
+	_, items := parseOutline(lines)
+	var params []*param
+	for _, it := range items {
+		switch keyword(it.text) {
+		case "Parameters":
+			params = append(params, parameters(it)...)
+		case "Attributes":
+			if name != "" {
+				p.addSchema(name, p.attributes(it))
+			}
+		}
+	}
+	p.resource = newResource(uri, params)
+}
+
+// dataStructure reads a named type from the Data Structures section.
+func (p *parser) dataStructure(s section) {
+	m := typeHeading.FindStringSubmatch(s.title)
+	if m == nil {
+		return
+	}
+	desc, items := parseOutline(s.lines)
+	schema := p.msonSchema(m[2], items)
+	if desc != "" {
+		schema.Description = desc
+	}
+	p.addSchema(m[1], schema)
+}
+
+func (p *parser) addSchema(name string, schema *openapi.Schema) {
+	if p.spec.Components == nil {
+		p.spec.Components = &openapi.Components{Schemas: make(map[string]*openapi.Schema)}
+	}
+	p.spec.Components.Schemas[name] = schema
+}
+
+// action adds the operation of an action to the current resource.
+func (p *parser) action(name, method string, lines []string) {
+	desc, items := parseOutline(lines)
+	op := &openapi.Operation{
+		Summary:     name,
+		Description: desc,
+		Responses:   make(map[string]openapi.Response),
+	}
+	if p.tag != "" {
+		op.Tags = []string{p.tag}
+	}
+
+	params := slices.Clone(p.resource.params)
+	var attributes *openapi.Schema
+	for _, it := range items {
+		switch keyword(it.text) {
+		case "Parameters":
+			for _, added := range parameters(it) {
+				params = slices.DeleteFunc(params, func(existing *param) bool { return existing.name == added.name })
+				params = append(params, added)
+			}
+		case "Attributes":
+			attributes = p.attributes(it)
+		case "Request":
+			p.request(op, it, attributes)
+		case "Response":
+			p.response(op, it)
+		}
+	}
+	if op.RequestBody == nil && attributes != nil {
+		op.RequestBody = &openapi.RequestBody{Content: map[string]openapi.MediaType{"application/json": {Schema: attributes}}}
+	}
+
+	item := p.spec.Paths[p.resource.path]
+	if item == nil {
+		item = &openapi.PathItem{}
+		p.spec.Paths[p.resource.path] = item
+	}
+	// URI parameters come before the request headers already added
+	headers := op.Parameters
+	op.Parameters = nil
+	pathParams := templateParams(p.resource.path)
+	for _, declared := range params {
+		in := "query"
+		if slices.Contains(pathParams, declared.name) {
+			in = "path"
+		}
+		op.Parameters = append(op.Parameters, declared.parameter(in))
+	}
+	// Declare template variables the blueprint leaves implicit as strings
+	for _, name := range append(pathParams, p.resource.query...) {
+		if !slices.ContainsFunc(params, func(declared *param) bool { return declared.name == name }) {
+			in := "query"
+			if slices.Contains(pathParams, name) {
+				in = "path"
+			}
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: name, In: in, Required: in == "path", Schema: &openapi.Schema{Type: "string"}})
+		}
+	}
+	op.Parameters = append(op.Parameters, headers...)
+
+	switch method {
+	case "GET":
+		item.Get = op
+	case "PUT":
+		item.Put = op
+	case "POST":
+		item.Post = op
+	case "DELETE":
+		item.Delete = op
+	case "OPTIONS":
+		item.Options = op
+	case "HEAD":
+		item.Head = op
+	case "PATCH":
+		item.Patch = op
+	case "TRACE":
+		item.Trace = op
+	}
+}
+
+var payloadHeading = regexp.MustCompile(`^(Request|Response)\s*([^()]*?)\s*(?:\(([^)]*)\))?\s*$`)
+
+// request adds a request payload. Later requests, usually examples, add
+// only media types not seen before.
+func (p *parser) request(op *openapi.Operation, it *item, attributes *openapi.Schema) {
+	mediaType, schema, example, headers := p.payload(it)
+	for _, h := range headers {
+		if !slices.ContainsFunc(op.Parameters, func(existing openapi.Parameter) bool { return existing.Name == h }) {
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: h, In: "header", Schema: &openapi.Schema{Type: "string"}})
+		}
+	}
+	if schema == nil {
+		schema = attributes
+	}
+	if schema == nil {
+		return
+	}
+	if op.RequestBody == nil {
+		op.RequestBody = &openapi.RequestBody{Content: make(map[string]openapi.MediaType), Required: true}
+	}
+	if _, ok := op.RequestBody.Content[mediaType]; !ok {
+		op.RequestBody.Content[mediaType] = openapi.MediaType{Schema: schema, Example: example}
+	}
+}
+
+// response adds a response payload. Later responses with the same status
+// add only media types not seen before.
+func (p *parser) response(op *openapi.Operation, it *item) {
+	status := "200"
+	if m := payloadHeading.FindStringSubmatch(it.text); m != nil && m[2] != "" {
+		status = m[2]
+	}
+	resp, ok := op.Responses[status]
+	if !ok {
+		code, _ := strconv.Atoi(status)
+		resp = openapi.Response{Description: http.StatusText(code)}
+	}
+
+	mediaType, schema, example, headers := p.payload(it)
+	for _, h := range headers {
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]openapi.Header)
+		}
+		resp.Headers[h] = openapi.Header{Schema: &openapi.Schema{Type: "string"}}
+	}
+	if schema != nil {
+		if resp.Content == nil {
+			resp.Content = make(map[string]openapi.MediaType)
+		}
+		if _, ok := resp.Content[mediaType]; !ok {
+			resp.Content[mediaType] = openapi.MediaType{Schema: schema, Example: example}
+		}
+	}
+	op.Responses[status] = resp
+}
+
+// payload reads a request or response: its media type, body schema and
+// example, and header names other than Content-Type.
+func (p *parser) payload(it *item) (string, *openapi.Schema, any, []string) {
+	mediaType := ""
+	if m := payloadHeading.FindStringSubmatch(it.text); m != nil {
+		mediaType = strings.TrimSpace(m[3])
+	}
+
+	var schema, attributes *openapi.Schema
+	var headers []string
+	body := ""
+	for _, child := range it.children {
+		switch keyword(child.text) {
+		case "Headers":
+			for _, line := range strings.Split(child.asset(), "\n") {
+				name, value, ok := strings.Cut(line, ":")
+				name = strings.TrimSpace(name)
+				if !ok || name == "" {
+					continue
+				}
+				if strings.EqualFold(name, "Content-Type") {
+					if mediaType == "" {
+						mediaType = strings.TrimSpace(value)
+					}
+					continue
+				}
+				headers = append(headers, name)
+			}
+		case "Attributes":
+			attributes = p.attributes(child)
+		case "Body":
+			body = child.asset()
+		case "Schema":
+			var s openapi.Schema
+			if err := json.Unmarshal([]byte(child.asset()), &s); err == nil {
+				schema = &s
+			}
+		}
+	}
+	if len(it.children) == 0 {
+		body = it.asset()
+	}
+	if mediaType == "" {
+		mediaType = "application/json"
+	}
+
+	var example any
+	if body != "" && json.Unmarshal([]byte(body), &example) != nil {
+		example = nil
+	}
+	switch {
+	case schema != nil:
+	case attributes != nil:
+		schema = attributes
+	case example != nil:
+		schema = inferSchema(example)
+	case body != "":
+		schema = &openapi.Schema{Type: "string"}
+	}
+	return mediaType, schema, example, headers
+}
+
+// param is a URI parameter.
+type param struct {
+	name        string
+	description string
+	required    bool
+	schema      *openapi.Schema
+	example     any
+}
+
+func (p *param) parameter(in string) openapi.Parameter {
+	return openapi.Parameter{
+		Name:        p.name,
+		In:          in,
+		Description: p.description,
+		Required:    p.required || in == "path",
+		Schema:      p.schema,
+		Example:     p.example,
+	}
+}
+
+var defaultValue = regexp.MustCompile("\\s*=\\s*`([^`]*)`")
+
+// parameters reads a Parameters section. Parameters are required unless
+// marked optional.
+func parameters(it *item) []*param {
+	var params []*param
+	for _, child := range it.children {
+		m := parseMember(defaultValue.ReplaceAllString(child.text, ""))
+		if m.name == "" {
+			continue
+		}
+		p := &param{name: m.name, description: m.description, required: true}
+		typ := ""
+		for _, attr := range m.attributes {
+			switch attr {
+			case "required":
+			case "optional":
+				p.required = false
+			default:
+				if sample, ok := strings.CutPrefix(attr, "`"); ok {
+					p.example = strings.TrimSuffix(sample, "`")
+				} else if typ == "" {
+					typ = attr
+				}
+			}
+		}
+		if m.value != "" {
+			p.example = m.value
+		}
+		p.schema = primitiveSchema(typ, m.value)
+		for _, nested := range child.children {
+			switch keyword(nested.text) {
+			case "Members":
+				for _, member := range nested.children {
+					p.schema.Enum = append(p.schema.Enum, strings.Trim(parseMember(member.text).name, "`"))
+				}
+			case "Default":
+				_, value, _ := strings.Cut(nested.text, ":")
+				p.schema.Default = strings.Trim(strings.TrimSpace(value), "`")
+			}
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+var (
+	queryExpression = regexp.MustCompile(`\{[?&]([^}]*)\}`)
+	otherExpression = regexp.MustCompile(`\{#[^}]*\}`)
+	pathExpression  = regexp.MustCompile(`\{\+?([^}]*)\}`)
+)
+
+// newResource returns a resource for a URI template, such as
+// /users/{id}{?limit}, with its query expressions moved out of the path.
+func newResource(uri string, params []*param) *resource {
+	r := &resource{params: params}
+	for _, m := range queryExpression.FindAllStringSubmatch(uri, -1) {
+		for _, name := range strings.Split(m[1], ",") {
+			if name = variableName(name); name != "" {
+				r.query = append(r.query, name)
+			}
+		}
+	}
+	path := queryExpression.ReplaceAllString(uri, "")
+	path = otherExpression.ReplaceAllString(path, "")
+	r.path = pathExpression.ReplaceAllStringFunc(path, func(expr string) string {
+		return "{" + variableName(pathExpression.FindStringSubmatch(expr)[1]) + "}"
+	})
+	if r.path == "" {
+		r.path = "/"
+	}
+	return r
+}
+
+// variableName strips the explode and prefix modifiers of a URI template
+// variable.
+func variableName(name string) string {
+	name = strings.TrimSpace(name)
+	if i := strings.IndexAny(name, "*:"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func templateParams(path string) []string {
+	var names []string
+	for _, m := range pathExpression.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// keyword returns the first word of an item, such as Request or Attributes.
+func keyword(text string) string {
+	word, _, _ := strings.Cut(strings.TrimSpace(text), " ")
+	word, _, _ = strings.Cut(word, "(")
+	return strings.TrimSuffix(word, ":")
+}
+
+// inferSchema returns a schema for an example JSON value.
+func inferSchema(v any) *openapi.Schema {
+	switch v := v.(type) {
+	case map[string]any:
+		schema := &openapi.Schema{Type: "object", Properties: make(map[string]*openapi.Schema)}
+		for _, key := range sortedKeys(v) {
+			schema.Properties[key] = inferSchema(v[key])
+		}
+		return schema
+	case []any:
+		schema := &openapi.Schema{Type: "array"}
+		if len(v) > 0 {
+			schema.Items = inferSchema(v[0])
+		}
+		return schema
+	case string:
+		return &openapi.Schema{Type: "string"}
+	case float64:
+		if v == float64(int64(v)) {
+			return &openapi.Schema{Type: "integer"}
+		}
+		return &openapi.Schema{Type: "number"}
+	case bool:
+		return &openapi.Schema{Type: "boolean"}
+	}
+	return &openapi.Schema{}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
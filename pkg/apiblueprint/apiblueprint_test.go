@@ -0,0 +1,194 @@
+package apiblueprint
+
+import (
+	"reflect"
+	"testing"
+)
+
+const usersBlueprint = "FORMAT: 1A\n" +
+	"HOST: https://api.example.com/\n" +
+	`
+# Users API
+
+Manage users.
+
+# Group Users
+
+User accounts.
+
+## Users Collection [/users{?limit,cursor}]
+
++ Parameters
+    + limit: 20 (number, optional) - Max results
+
+### List Users [GET]
+
++ Response 200 (application/json)
+    + Headers
+
+            X-Total-Count: 42
+
+    + Attributes (array[User])
+
+### Create User [POST]
+
++ Request (application/json)
+
+        {"name": "Ann", "email": "ann@example.com", "tags": ["admin"]}
+
++ Response 201 (application/json)
+    + Attributes (User)
+
++ Response 422
+
+## User [/users/{id}]
+
++ Parameters
+    + id: 1 (number) - User ID
+
++ Attributes
+    + id: 1 (number, required)
+    + name: Ann (string, required)
+    + nickname (string, nullable)
+    + status (enum[string])
+        + Members
+            + active
+            + suspended
+    + address (object)
+        + city: Paris
+
+### Get User [GET]
+
++ Request
+    + Headers
+
+            Authorization: Bearer token
+
++ Response 200 (application/json)
+    + Attributes (User)
+
++ Response 404
+
+## GET /health
+
++ Response 204
+
+# Data Structures
+
+## Admin (User)
++ permissions (array[string], required)
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(usersBlueprint))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if spec.Info.Title != "Users API" || spec.Info.Description != "Manage users." {
+		t.Errorf("Info = %+v", spec.Info)
+	}
+	if len(spec.Servers) != 1 || spec.Servers[0].URL != "https://api.example.com" {
+		t.Errorf("Servers = %+v", spec.Servers)
+	}
+	if len(spec.Tags) != 1 || spec.Tags[0].Name != "Users" || spec.Tags[0].Description != "User accounts." {
+		t.Errorf("Tags = %+v", spec.Tags)
+	}
+
+	user := spec.Components.Schemas["User"]
+	if user == nil {
+		t.Fatal("missing User schema from resource attributes")
+	}
+	if want := []string{"id", "name"}; !reflect.DeepEqual(user.Required, want) {
+		t.Errorf("User required = %v, want %v", user.Required, want)
+	}
+	if got := user.Properties["nickname"].Type; !reflect.DeepEqual(got, []string{"string", "null"}) {
+		t.Errorf("nickname type = %v", got)
+	}
+	if got := user.Properties["status"].Enum; !reflect.DeepEqual(got, []any{"active", "suspended"}) {
+		t.Errorf("status enum = %v", got)
+	}
+	if city := user.Properties["address"].Properties["city"]; city == nil || city.Type != "string" {
+		t.Errorf("address.city = %+v", city)
+	}
+	admin := spec.Components.Schemas["Admin"]
+	if admin == nil || len(admin.AllOf) != 2 || admin.AllOf[0].Ref != "#/components/schemas/User" {
+		t.Errorf("Admin = %+v", admin)
+	}
+
+	users := spec.Paths["/users"]
+	if users == nil || users.Get == nil || users.Post == nil {
+		t.Fatalf("/users = %+v", users)
+	}
+	if users.Get.Summary != "List Users" || !reflect.DeepEqual(users.Get.Tags, []string{"Users"}) {
+		t.Errorf("GET /users summary = %q, tags = %v", users.Get.Summary, users.Get.Tags)
+	}
+	var query []string
+	for _, p := range users.Get.Parameters {
+		query = append(query, p.In+" "+p.Name)
+	}
+	if want := []string{"query limit", "query cursor"}; !reflect.DeepEqual(query, want) {
+		t.Errorf("GET /users parameters = %v, want %v", query, want)
+	}
+	if users.Get.Parameters[0].Required || users.Get.Parameters[0].Schema.Type != "number" {
+		t.Errorf("limit = %+v", users.Get.Parameters[0])
+	}
+	list := users.Get.Responses["200"]
+	if schema := list.Content["application/json"].Schema; schema.Type != "array" || schema.Items.Ref != "#/components/schemas/User" {
+		t.Errorf("GET /users 200 schema = %+v", schema)
+	}
+	if _, ok := list.Headers["X-Total-Count"]; !ok {
+		t.Errorf("GET /users 200 headers = %v", list.Headers)
+	}
+
+	body := users.Post.RequestBody.Content["application/json"]
+	if body.Schema.Type != "object" || body.Schema.Properties["tags"].Items.Type != "string" {
+		t.Errorf("POST /users body schema = %+v", body.Schema)
+	}
+	if body.Example == nil {
+		t.Error("POST /users body has no example")
+	}
+	if _, ok := users.Post.Responses["422"]; !ok {
+		t.Errorf("POST /users responses = %v", users.Post.Responses)
+	}
+
+	get := spec.Paths["/users/{id}"].Get
+	if get == nil {
+		t.Fatal("missing GET /users/{id}")
+	}
+	var params []string
+	for _, p := range get.Parameters {
+		params = append(params, p.In+" "+p.Name)
+	}
+	if want := []string{"path id", "header Authorization"}; !reflect.DeepEqual(params, want) {
+		t.Errorf("GET /users/{id} parameters = %v, want %v", params, want)
+	}
+	if got := get.Responses["404"].Description; got != "Not Found" {
+		t.Errorf("404 description = %q", got)
+	}
+
+	health := spec.Paths["/health"]
+	if health == nil || health.Get == nil {
+		t.Fatalf("/health = %+v", health)
+	}
+	if _, ok := health.Get.Responses["204"]; !ok {
+		t.Errorf("GET /health responses = %v", health.Get.Responses)
+	}
+}
+
+func TestParseMember(t *testing.T) {
+	tests := []struct {
+		text string
+		want member
+	}{
+		{"id: 1 (number, required) - The ID", member{name: "id", value: "1", attributes: []string{"number", "required"}, description: "The ID"}},
+		{"`first-name`: Ann", member{name: "first-name", value: "Ann"}},
+		{"tags (array[string, number])", member{name: "tags", attributes: []string{"array[string, number]"}}},
+		{"(User)", member{attributes: []string{"User"}}},
+	}
+	for _, tt := range tests {
+		if got := parseMember(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseMember(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
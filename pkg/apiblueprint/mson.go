@@ -0,0 +1,419 @@
+package apiblueprint
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+)
+
+// item is a list item of a section, with its nested items and the other
+// lines indented under it: a description, or an asset such as a body.
+type item struct {
+	indent   int
+	text     string
+	children []*item
+	lines    []string
+}
+
+var listItem = regexp.MustCompile(`^(\s*)[+*-]\s+(.*)$`)
+
+// parseOutline parses the list items of a section. Text before the first
+// item is returned as the description.
+func parseOutline(lines []string) (string, []*item) {
+	var desc []string
+	var items, stack []*item
+	fenced := false
+	for _, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		trimmed := strings.TrimSpace(line)
+		var top *item
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		fence := strings.HasPrefix(trimmed, "```")
+		if fence {
+			fenced = !fenced
+		}
+		if fence || fenced || trimmed == "" {
+			if top != nil {
+				top.lines = append(top.lines, line)
+			} else if len(items) == 0 {
+				desc = append(desc, trimmed)
+			}
+			continue
+		}
+
+		// Assets are indented by 8 spaces, and may contain lines that
+		// look like list items
+		m := listItem.FindStringSubmatch(line)
+		if m != nil && (top == nil || !top.hasAsset() || indent < top.indent+8) {
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			it := &item{indent: indent, text: strings.TrimSpace(m[2])}
+			if len(stack) == 0 {
+				items = append(items, it)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, it)
+			}
+			stack = append(stack, it)
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			stack[len(stack)-1].lines = append(stack[len(stack)-1].lines, line)
+		} else if len(items) == 0 {
+			desc = append(desc, trimmed)
+		}
+	}
+	return strings.TrimSpace(strings.Join(desc, "\n")), items
+}
+
+// hasAsset reports whether an item can contain an asset.
+func (it *item) hasAsset() bool {
+	switch keyword(it.text) {
+	case "Request", "Response", "Body", "Schema", "Headers":
+		return true
+	}
+	return false
+}
+
+// asset returns the lines under an item, dedented and without code fences.
+func (it *item) asset() string {
+	var lines []string
+	indent := -1
+	for _, line := range it.lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			continue
+		}
+		lines = append(lines, line)
+		if strings.TrimSpace(line) != "" {
+			n := len(line) - len(strings.TrimLeft(line, " "))
+			if indent < 0 || n < indent {
+				indent = n
+			}
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// member is an MSON member: name: value (attributes) - description.
+type member struct {
+	name        string
+	value       string
+	attributes  []string
+	description string
+}
+
+// parseMember parses an MSON member or parameter line.
+func parseMember(text string) member {
+	var m member
+	if i := indexOutside(text, " - "); i >= 0 {
+		m.description = strings.TrimSpace(text[i+3:])
+		text = text[:i]
+	} else if strings.HasPrefix(text, "- ") {
+		return member{description: strings.TrimSpace(text[2:])}
+	}
+	text = strings.TrimSpace(text)
+	if strings.HasSuffix(text, ")") {
+		if i := strings.LastIndex(text, "("); i >= 0 {
+			m.attributes = splitTopLevel(text[i+1 : len(text)-1])
+			text = strings.TrimSpace(text[:i])
+		}
+	}
+
+	rest := ""
+	if strings.HasPrefix(text, "`") {
+		if end := strings.Index(text[1:], "`"); end >= 0 {
+			m.name, rest = text[1:end+1], text[end+2:]
+		} else {
+			m.name = strings.Trim(text, "`")
+		}
+	} else {
+		m.name, rest, _ = strings.Cut(text, ":")
+	}
+	m.name = strings.TrimSpace(m.name)
+	if value, ok := strings.CutPrefix(strings.TrimSpace(rest), ":"); ok {
+		rest = value
+	}
+	m.value = strings.Trim(strings.TrimSpace(rest), "`")
+	return m
+}
+
+// indexOutside returns the index of sep outside backticks and brackets,
+// or -1.
+func indexOutside(s, sep string) int {
+	depth, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '`':
+			quoted = !quoted
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		}
+		if !quoted && depth == 0 && strings.HasPrefix(s[i:], sep) {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits a list of attributes on commas outside brackets.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// typeAttributes are the type attributes of a member.
+type typeAttributes struct {
+	typ      string
+	required bool
+	nullable bool
+}
+
+func parseAttributes(attrs []string) typeAttributes {
+	var a typeAttributes
+	for _, attr := range attrs {
+		switch attr {
+		case "required":
+			a.required = true
+		case "nullable":
+			a.nullable = true
+		case "optional", "fixed", "fixed-type", "sample", "default":
+		default:
+			if a.typ == "" && !strings.HasPrefix(attr, "`") {
+				a.typ = attr
+			}
+		}
+	}
+	return a
+}
+
+// attributes converts an Attributes section, such as
+// "Attributes (array[User])" with nested members.
+func (p *parser) attributes(it *item) *openapi.Schema {
+	m := parseMember(it.text)
+	return p.msonSchema(strings.Join(m.attributes, ","), it.children)
+}
+
+// msonSchema converts a type definition, given as its attributes, and its
+// nested members.
+func (p *parser) msonSchema(attrs string, children []*item) *openapi.Schema {
+	a := parseAttributes(splitTopLevel(attrs))
+	schema := p.typedSchema(a.typ, "", children)
+	if a.nullable {
+		return nullable(schema)
+	}
+	return schema
+}
+
+// typedSchema converts a value of an MSON type, such as array[string] or a
+// named type, with its sample value and nested members.
+func (p *parser) typedSchema(typ, value string, children []*item) *openapi.Schema {
+	base, inner := typ, ""
+	if i := strings.Index(typ, "["); i > 0 && strings.HasSuffix(typ, "]") {
+		base, inner = typ[:i], typ[i+1:len(typ)-1]
+	}
+	if base == "" {
+		switch {
+		case hasMembers(children):
+			base = "object"
+		default:
+			return primitiveSchema("", value)
+		}
+	}
+
+	switch base {
+	case "object":
+		schema := &openapi.Schema{Type: "object"}
+		p.addMembers(schema, children)
+		return schema
+	case "array":
+		schema := &openapi.Schema{Type: "array"}
+		// Element types are those declared, else those of the samples
+		var elements []*openapi.Schema
+		seen := make(map[string]bool)
+		add := func(element *openapi.Schema) {
+			key, _ := json.Marshal(element)
+			if !seen[string(key)] {
+				seen[string(key)] = true
+				elements = append(elements, element)
+			}
+		}
+		for _, t := range splitTopLevel(inner) {
+			add(p.typedSchema(t, "", nil))
+		}
+		if inner == "" {
+			for _, child := range elementItems(children) {
+				m := parseMember(child.text)
+				add(p.typedSchema(parseAttributes(m.attributes).typ, m.name, child.children))
+			}
+		}
+		switch len(elements) {
+		case 0:
+		case 1:
+			schema.Items = elements[0]
+		default:
+			schema.Items = &openapi.Schema{OneOf: elements}
+		}
+		return schema
+	case "enum":
+		schema := primitiveSchema(inner, "")
+		for _, child := range elementItems(children) {
+			schema.Enum = append(schema.Enum, parseMember(child.text).name)
+		}
+		return schema
+	case "string", "number", "boolean":
+		return primitiveSchema(base, value)
+	}
+
+	if !p.types[base] {
+		return &openapi.Schema{}
+	}
+	ref := &openapi.Schema{Ref: "#/components/schemas/" + base}
+	if !hasMembers(children) {
+		return ref
+	}
+	object := &openapi.Schema{Type: "object"}
+	p.addMembers(object, children)
+	return &openapi.Schema{AllOf: []*openapi.Schema{ref, object}}
+}
+
+// addMembers adds the properties of nested members to an object schema.
+func (p *parser) addMembers(schema *openapi.Schema, children []*item) {
+	for _, child := range children {
+		switch keyword(child.text) {
+		case "Properties":
+			p.addMembers(schema, child.children)
+			continue
+		case "Include":
+			name := strings.Trim(strings.TrimSpace(strings.TrimPrefix(child.text, "Include")), "()`")
+			if p.types[name] {
+				schema.AllOf = append(schema.AllOf, &openapi.Schema{Ref: "#/components/schemas/" + name})
+			}
+			continue
+		case "One":
+			// One Of alternatives are added as optional properties
+			alternatives := &openapi.Schema{}
+			p.addMembers(alternatives, child.children)
+			if schema.Properties == nil && len(alternatives.Properties) > 0 {
+				schema.Properties = make(map[string]*openapi.Schema)
+			}
+			for name, prop := range alternatives.Properties {
+				schema.Properties[name] = prop
+			}
+			continue
+		case "Sample", "Default", "Members", "Items":
+			continue
+		}
+
+		m := parseMember(child.text)
+		if m.name == "" {
+			continue
+		}
+		a := parseAttributes(m.attributes)
+		prop := p.typedSchema(a.typ, m.value, child.children)
+		if m.description != "" {
+			prop.Description = m.description
+		}
+		if a.nullable {
+			prop = nullable(prop)
+		}
+		if schema.Properties == nil {
+			schema.Properties = make(map[string]*openapi.Schema)
+		}
+		schema.Properties[m.name] = prop
+		if a.required {
+			schema.Required = append(schema.Required, m.name)
+		}
+	}
+}
+
+// hasMembers reports whether nested items declare members, rather than
+// only samples and defaults.
+func hasMembers(children []*item) bool {
+	for _, child := range children {
+		switch keyword(child.text) {
+		case "Sample", "Default":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// elementItems returns the items of an array or the values of an enum,
+// which may be grouped under an Items or Members section.
+func elementItems(children []*item) []*item {
+	var elements []*item
+	for _, child := range children {
+		switch keyword(child.text) {
+		case "Items", "Members":
+			elements = append(elements, child.children...)
+		case "Sample", "Default":
+		default:
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+// primitiveSchema returns the schema of a primitive type, inferred from the
+// sample value if the type is not given.
+func primitiveSchema(typ, value string) *openapi.Schema {
+	if typ == "" {
+		switch _, err := strconv.ParseFloat(value, 64); {
+		case value == "true" || value == "false":
+			typ = "boolean"
+		case value != "" && err == nil:
+			typ = "number"
+		default:
+			typ = "string"
+		}
+	}
+	switch typ {
+	case "number", "boolean":
+		return &openapi.Schema{Type: typ}
+	}
+	return &openapi.Schema{Type: "string"}
+}
+
+func nullable(schema *openapi.Schema) *openapi.Schema {
+	if t, ok := schema.Type.(string); ok && schema.Ref == "" {
+		schema.Type = []string{t, "null"}
+		return schema
+	}
+	return &openapi.Schema{OneOf: []*openapi.Schema{schema, {Type: "null"}}}
+}
@@ -0,0 +1,339 @@
+package arazzo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+)
+
+// Options configures workflow generation.
+type Options struct {
+	// Title is the document title (default "Observed API workflows").
+	Title string
+
+	// Version is the document version (default "1.0.0").
+	Version string
+
+	// SourceName names the OpenAPI source description (default "api").
+	SourceName string
+
+	// SourceURL locates the OpenAPI description generated from the same
+	// traffic (default "openapi.yaml").
+	SourceURL string
+
+	// Flows configures which call sequences become workflows.
+	Flows inference.FlowOptions
+}
+
+// DefaultOptions returns the default generation options.
+func DefaultOptions() Options {
+	return Options{
+		Title:      "Observed API workflows",
+		Version:    "1.0.0",
+		SourceName: "api",
+		SourceURL:  "openapi.yaml",
+		Flows:      inference.DefaultFlowOptions(),
+	}
+}
+
+// Generate builds an Arazzo document with a workflow for each common call
+// flow across the sessions (see inference.CommonFlows).
+//
+// A step parameter is bound to an earlier step's response field when, in
+// every occurrence of the flow, the parameter value equals that field's
+// value. Path parameters that are not bound become workflow inputs.
+// Operation IDs are those the OpenAPI generator assigns, so the document
+// pairs with a spec generated from the same traffic.
+func Generate(sessions []*ir.Session, opts Options) *Document {
+	defaults := DefaultOptions()
+	if opts.Title == "" {
+		opts.Title = defaults.Title
+	}
+	if opts.Version == "" {
+		opts.Version = defaults.Version
+	}
+	if opts.SourceName == "" {
+		opts.SourceName = defaults.SourceName
+	}
+	if opts.SourceURL == "" {
+		opts.SourceURL = defaults.SourceURL
+	}
+
+	doc := &Document{
+		Arazzo: Version,
+		Info: Info{
+			Title:   opts.Title,
+			Summary: fmt.Sprintf("Call sequences observed in %d sessions", len(sessions)),
+			Version: opts.Version,
+		},
+		SourceDescriptions: []SourceDescription{
+			{Name: opts.SourceName, URL: opts.SourceURL, Type: "openapi"},
+		},
+		Workflows: []Workflow{},
+	}
+
+	workflowIDs := make(map[string]int)
+	for _, flow := range inference.CommonFlows(sessions, opts.Flows) {
+		occurrences := inference.FlowOccurrences(sessions, flow)
+		if len(occurrences) == 0 {
+			continue
+		}
+		workflow := buildWorkflow(flow, occurrences)
+		workflow.WorkflowID = uniqueID(workflowIDs, workflow.WorkflowID)
+		doc.Workflows = append(doc.Workflows, workflow)
+	}
+	return doc
+}
+
+// requestParam is a path or query parameter value of a request.
+type requestParam struct {
+	name  string
+	in    string
+	value string
+}
+
+// valueSource is a response field of an earlier step.
+type valueSource struct {
+	step    int
+	pointer string
+}
+
+func buildWorkflow(flow inference.CallFlow, occurrences [][]inference.SessionCall) Workflow {
+	first := occurrences[0]
+	stepIDs := make(map[string]int)
+	steps := make([]Step, len(first))
+	ids := make([]string, len(first))
+	for j, call := range first {
+		operationID := callOperationID(call)
+		ids[j] = uniqueID(stepIDs, operationID)
+		steps[j] = Step{
+			StepID:      ids[j],
+			OperationID: operationID,
+			SuccessCriteria: []Criterion{
+				{Condition: "$statusCode == " + strconv.Itoa(commonStatus(occurrences, j))},
+			},
+		}
+	}
+
+	bodies := bodyValueCache(occurrences)
+	inputs := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for j := range steps {
+		for _, param := range requestParams(first[j]) {
+			var value string
+			if source, ok := bindParameter(occurrences, bodies, j, param); ok {
+				output := addOutput(&steps[source.step], param.name, "$response.body#"+source.pointer)
+				value = "$steps." + steps[source.step].StepID + ".outputs." + output
+			} else if param.in == "path" {
+				value = "$inputs." + addInput(inputs, param)
+			} else {
+				continue
+			}
+			steps[j].Parameters = append(steps[j].Parameters, Parameter{Name: param.name, In: param.in, Value: value})
+		}
+	}
+
+	workflow := Workflow{
+		WorkflowID:  strings.Join(ids, "-"),
+		Summary:     flow.String(),
+		Description: fmt.Sprintf("Observed in %d sessions (%d times).", flow.Sessions, flow.Count),
+		Steps:       steps,
+	}
+	if len(inputs.Properties) > 0 {
+		workflow.Inputs = inputs
+	}
+	return workflow
+}
+
+// bindParameter finds the response field of an earlier step that holds the
+// parameter's value in every occurrence that has the parameter. The nearest
+// step wins, then fields named like the parameter, then id-like fields.
+func bindParameter(occurrences [][]inference.SessionCall, bodies [][]map[string][]string, j int, param requestParam) (valueSource, bool) {
+	var candidates map[valueSource]bool
+	for o, occurrence := range occurrences {
+		value, ok := paramValue(occurrence[j], param)
+		if !ok {
+			continue
+		}
+		found := make(map[valueSource]bool)
+		for k := 0; k < j; k++ {
+			for _, pointer := range bodies[o][k][value] {
+				source := valueSource{step: k, pointer: pointer}
+				if candidates == nil || candidates[source] {
+					found[source] = true
+				}
+			}
+		}
+		candidates = found
+		if len(candidates) == 0 {
+			return valueSource{}, false
+		}
+	}
+	if len(candidates) == 0 {
+		return valueSource{}, false
+	}
+
+	sources := make([]valueSource, 0, len(candidates))
+	for source := range candidates {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(a, b int) bool {
+		sa, sb := sources[a], sources[b]
+		if sa.step != sb.step {
+			return sa.step > sb.step
+		}
+		if ra, rb := inference.FieldRank(sa.pointer, param.name), inference.FieldRank(sb.pointer, param.name); ra != rb {
+			return ra < rb
+		}
+		if len(sa.pointer) != len(sb.pointer) {
+			return len(sa.pointer) < len(sb.pointer)
+		}
+		return sa.pointer < sb.pointer
+	})
+	return sources[0], true
+}
+
+// bodyValueCache indexes the response body values of every call, by
+// occurrence and step.
+func bodyValueCache(occurrences [][]inference.SessionCall) [][]map[string][]string {
+	cache := make([][]map[string][]string, len(occurrences))
+	for o, occurrence := range occurrences {
+		cache[o] = make([]map[string][]string, len(occurrence))
+		for k, call := range occurrence {
+			cache[o][k] = inference.BodyValues(call.Record.Response.Body)
+		}
+	}
+	return cache
+}
+
+// requestParams returns the path and query parameters of a call.
+func requestParams(call inference.SessionCall) []requestParam {
+	var params []requestParam
+	path := pathParams(call)
+	names := make([]string, 0, len(path))
+	for name := range path {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		params = append(params, requestParam{name: name, in: "path", value: path[name]})
+	}
+
+	query := call.Record.Request.Query
+	names = names[:0]
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value, ok := inference.ScalarString(query[name]); ok {
+			params = append(params, requestParam{name: name, in: "query", value: value})
+		}
+	}
+	return params
+}
+
+// paramValue returns the value of a parameter in a call.
+func paramValue(call inference.SessionCall, param requestParam) (string, bool) {
+	if param.in == "path" {
+		value, ok := pathParams(call)[param.name]
+		return value, ok
+	}
+	return inference.ScalarString(call.Record.Request.Query[param.name])
+}
+
+// pathParams returns the path parameter values of a call, matching the
+// request path against the call's path template when the record has none.
+func pathParams(call inference.SessionCall) map[string]string {
+	if len(call.Record.Request.PathParams) > 0 {
+		return call.Record.Request.PathParams
+	}
+	params := make(map[string]string)
+	templateSegs := strings.Split(strings.Trim(call.PathTemplate, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(call.Record.Request.Path, "/"), "/")
+	if len(templateSegs) != len(pathSegs) {
+		return params
+	}
+	for i, seg := range templateSegs {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			params[seg[1:len(seg)-1]] = pathSegs[i]
+		}
+	}
+	return params
+}
+
+// addOutput declares a step output for expr, reusing an existing output
+// with the same expression, and returns its name.
+func addOutput(step *Step, name, expr string) string {
+	for existing, e := range step.Outputs {
+		if e == expr {
+			return existing
+		}
+	}
+	if step.Outputs == nil {
+		step.Outputs = make(map[string]string)
+	}
+	output := name
+	for n := 2; step.Outputs[output] != ""; n++ {
+		output = name + strconv.Itoa(n)
+	}
+	step.Outputs[output] = expr
+	return output
+}
+
+// addInput declares a string workflow input for a parameter, reusing an
+// input of the same name and example value, and returns its name.
+func addInput(inputs *Schema, param requestParam) string {
+	name := param.name
+	for n := 2; ; n++ {
+		existing, ok := inputs.Properties[name]
+		if !ok {
+			break
+		}
+		if len(existing.Examples) > 0 && existing.Examples[0] == param.value {
+			return name
+		}
+		name = param.name + strconv.Itoa(n)
+	}
+	inputs.Properties[name] = &Schema{Type: "string", Examples: []interface{}{param.value}}
+	inputs.Required = append(inputs.Required, name)
+	return name
+}
+
+// commonStatus returns the most frequent response status of step j, the
+// lowest status on ties.
+func commonStatus(occurrences [][]inference.SessionCall, j int) int {
+	counts := make(map[int]int)
+	for _, occurrence := range occurrences {
+		counts[occurrence[j].Record.Response.Status]++
+	}
+	best, bestCount := 0, 0
+	for status, count := range counts {
+		if count > bestCount || (count == bestCount && status < best) {
+			best, bestCount = status, count
+		}
+	}
+	return best
+}
+
+// callOperationID returns the record's operation ID, else the one the
+// OpenAPI generator assigns.
+func callOperationID(call inference.SessionCall) string {
+	if id := call.Record.OperationId; id != nil && *id != "" {
+		return *id
+	}
+	return openapi.OperationID(string(call.Record.Request.Method), call.PathTemplate)
+}
+
+// uniqueID returns id, suffixed with a number when already used.
+func uniqueID(used map[string]int, id string) string {
+	used[id]++
+	if n := used[id]; n > 1 {
+		return id + strconv.Itoa(n)
+	}
+	return id
+}
@@ -0,0 +1,104 @@
+package arazzo
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func orderSession(orderID, customerID float64) *ir.Session {
+	return &ir.Session{Records: []*ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/orders"},
+			Response: ir.Response{Status: 201, Body: map[string]interface{}{"id": orderID, "status": "new"}},
+		},
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/orders/" + formatID(orderID)},
+			Response: ir.Response{Status: 200, Body: map[string]interface{}{
+				"id":       orderID,
+				"customer": map[string]interface{}{"id": customerID},
+			}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/customers/" + formatID(customerID), Query: map[string]interface{}{"expand": "all"}},
+			Response: ir.Response{Status: 200},
+		},
+	}}
+}
+
+func formatID(id float64) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
+
+func TestGenerate(t *testing.T) {
+	sessions := []*ir.Session{orderSession(1234, 901), orderSession(5678, 702)}
+	doc := Generate(sessions, DefaultOptions())
+
+	if doc.Arazzo != Version || len(doc.SourceDescriptions) != 1 || doc.SourceDescriptions[0].URL != "openapi.yaml" {
+		t.Fatalf("unexpected document header: %+v", doc)
+	}
+	if len(doc.Workflows) != 1 {
+		t.Fatalf("expected 1 workflow, got %d", len(doc.Workflows))
+	}
+	workflow := doc.Workflows[0]
+	if workflow.WorkflowID != "postOrders-getOrdersByOrderId-getCustomersByCustomerId" {
+		t.Errorf("unexpected workflow ID %q", workflow.WorkflowID)
+	}
+	if workflow.Inputs != nil {
+		t.Errorf("expected all path parameters bound, got inputs %+v", workflow.Inputs.Properties)
+	}
+
+	steps := workflow.Steps
+	if len(steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d", len(steps))
+	}
+	if steps[0].SuccessCriteria[0].Condition != "$statusCode == 201" {
+		t.Errorf("unexpected success criteria %+v", steps[0].SuccessCriteria)
+	}
+	if got := steps[0].Outputs["orderId"]; got != "$response.body#/id" {
+		t.Errorf("expected orderId output from /id, got %q", got)
+	}
+	if len(steps[1].Parameters) != 1 || steps[1].Parameters[0].Value != "$steps.postOrders.outputs.orderId" {
+		t.Errorf("expected orderId passed from postOrders, got %+v", steps[1].Parameters)
+	}
+	if got := steps[1].Outputs["customerId"]; got != "$response.body#/customer/id" {
+		t.Errorf("expected customerId output from /customer/id, got %q", got)
+	}
+	// The constant query value is not found in earlier responses
+	if len(steps[2].Parameters) != 1 || steps[2].Parameters[0].Value != "$steps.getOrdersByOrderId.outputs.customerId" {
+		t.Errorf("expected only customerId passed to the last step, got %+v", steps[2].Parameters)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteYAML(&buf, doc); err != nil {
+		t.Fatalf("WriteYAML failed: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("arazzo: 1.0.1")) {
+		t.Errorf("expected arazzo version in YAML output:\n%s", buf.String())
+	}
+}
+
+func TestGenerateUnboundInputs(t *testing.T) {
+	// Order IDs differ from the created IDs, so they are workflow inputs
+	a, b := orderSession(1234, 901), orderSession(5678, 702)
+	a.Records[0].Response.Body = map[string]interface{}{"id": float64(1)}
+	b.Records[0].Response.Body = map[string]interface{}{"id": float64(2)}
+
+	doc := Generate([]*ir.Session{a, b}, DefaultOptions())
+	if len(doc.Workflows) != 1 {
+		t.Fatalf("expected 1 workflow, got %d", len(doc.Workflows))
+	}
+	workflow := doc.Workflows[0]
+	if workflow.Inputs == nil || workflow.Inputs.Properties["orderId"] == nil {
+		t.Fatalf("expected orderId workflow input, got %+v", workflow.Inputs)
+	}
+	if got := workflow.Steps[1].Parameters[0].Value; got != "$inputs.orderId" {
+		t.Errorf("expected orderId from inputs, got %q", got)
+	}
+	if _, ok := workflow.Steps[0].Outputs["orderId"]; ok {
+		t.Error("expected no orderId output on postOrders")
+	}
+}
@@ -0,0 +1,70 @@
+// Package arazzo generates OpenAPI Arazzo workflow documents from call
+// sequences observed in traffic.
+package arazzo
+
+// Version is the Arazzo specification version of generated documents.
+const Version = "1.0.1"
+
+// Document represents an Arazzo document.
+type Document struct {
+	Arazzo             string              `json:"arazzo" yaml:"arazzo"`
+	Info               Info                `json:"info" yaml:"info"`
+	SourceDescriptions []SourceDescription `json:"sourceDescriptions" yaml:"sourceDescriptions"`
+	Workflows          []Workflow          `json:"workflows" yaml:"workflows"`
+}
+
+// Info provides metadata about the workflows.
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Summary     string `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+// SourceDescription references the API description the workflows call.
+type SourceDescription struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+	Type string `json:"type,omitempty" yaml:"type,omitempty"` // "openapi" or "arazzo"
+}
+
+// Workflow is a sequence of steps calling API operations.
+type Workflow struct {
+	WorkflowID  string            `json:"workflowId" yaml:"workflowId"`
+	Summary     string            `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Inputs      *Schema           `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	Steps       []Step            `json:"steps" yaml:"steps"`
+	Outputs     map[string]string `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+}
+
+// Step calls one operation, passing values from inputs or earlier steps.
+type Step struct {
+	StepID          string            `json:"stepId" yaml:"stepId"`
+	Description     string            `json:"description,omitempty" yaml:"description,omitempty"`
+	OperationID     string            `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	OperationPath   string            `json:"operationPath,omitempty" yaml:"operationPath,omitempty"`
+	Parameters      []Parameter       `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	SuccessCriteria []Criterion       `json:"successCriteria,omitempty" yaml:"successCriteria,omitempty"`
+	Outputs         map[string]string `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+}
+
+// Parameter passes a value to an operation parameter.
+type Parameter struct {
+	Name  string `json:"name" yaml:"name"`
+	In    string `json:"in" yaml:"in"` // "path", "query", "header", or "cookie"
+	Value string `json:"value" yaml:"value"`
+}
+
+// Criterion is a condition a step's response must satisfy.
+type Criterion struct {
+	Condition string `json:"condition" yaml:"condition"`
+}
+
+// Schema is the JSON Schema of workflow inputs.
+type Schema struct {
+	Type       string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	Examples   []interface{}      `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
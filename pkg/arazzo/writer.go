@@ -0,0 +1,47 @@
+package arazzo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WriteFile writes the document to a file.
+// Format is determined by file extension (.json or .yaml/.yml).
+func WriteFile(path string, doc *Document) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer f.Close()
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return WriteJSON(f, doc)
+	}
+	return WriteYAML(f, doc)
+}
+
+// WriteJSON writes the document as JSON.
+func WriteJSON(w io.Writer, doc *Document) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	return nil
+}
+
+// WriteYAML writes the document as YAML.
+func WriteYAML(w io.Writer, doc *Document) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("encoding YAML: %w", err)
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,86 @@
+package cireport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as a JUnit XML test suite with one test case
+// per check. Errors are failures; notes and warnings pass, with their
+// message kept as output.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{Name: r.Tool, Cases: make([]junitTestCase, 0, len(r.Checks))}
+	for _, c := range r.Checks {
+		tc := junitTestCase{Name: c.Name, ClassName: c.Group}
+		switch c.Level {
+		case LevelError:
+			tc.Failure = &junitFailure{Message: c.Message, Type: c.RuleID, Text: location(c)}
+			suite.Failures++
+		case LevelWarning, LevelNote:
+			tc.SystemOut = string(c.Level) + ": " + c.Message
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	doc := junitTestSuites{
+		Name:     r.Tool,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encoding JUnit XML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// location formats a check's file position, e.g. "api.yaml:12:5".
+func location(c Check) string {
+	if c.File == "" {
+		return ""
+	}
+	switch {
+	case c.Line > 0 && c.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", c.File, c.Line, c.Column)
+	case c.Line > 0:
+		return fmt.Sprintf("%s:%d", c.File, c.Line)
+	default:
+		return c.File
+	}
+}
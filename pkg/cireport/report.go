@@ -0,0 +1,114 @@
+// Package cireport writes command results as JUnit XML and SARIF, so that
+// failures surface in CI test tabs and code-scanning views rather than only
+// in build logs.
+package cireport
+
+import (
+	"fmt"
+	"io"
+)
+
+// Level is the severity of a check result.
+type Level string
+
+const (
+	LevelPass    Level = ""
+	LevelNote    Level = "note"
+	LevelWarning Level = "warning"
+	LevelError   Level = "error"
+)
+
+// Formats supported by Write.
+const (
+	FormatJUnit = "junit"
+	FormatSARIF = "sarif"
+)
+
+// Check is a single result of a command, such as a validated file or a
+// breaking change.
+type Check struct {
+	// Name identifies the check, e.g. "GET /users/{id}".
+	Name string
+
+	// Group groups related checks: the JUnit classname.
+	Group string
+
+	// RuleID identifies the kind of finding, e.g. "breaking-change".
+	RuleID string
+
+	// Level is the severity; LevelPass for checks that passed.
+	Level Level
+
+	// Message describes the finding.
+	Message string
+
+	// File, Line, and Column locate the finding. Line and Column are 1-based
+	// and optional.
+	File   string
+	Line   int
+	Column int
+}
+
+// Report collects the checks of one command run.
+type Report struct {
+	// Tool names the command, e.g. "traffic2openapi diff".
+	Tool string
+
+	// Version is the tool version.
+	Version string
+
+	// Rules describes rule IDs for SARIF output.
+	Rules map[string]string
+
+	Checks []Check
+}
+
+// NewReport creates an empty report for a tool.
+func NewReport(tool, version string) *Report {
+	return &Report{
+		Tool:    tool,
+		Version: version,
+		Rules:   make(map[string]string),
+	}
+}
+
+// Add appends a check to the report.
+func (r *Report) Add(check Check) {
+	r.Checks = append(r.Checks, check)
+}
+
+// Rule describes a rule ID for SARIF output.
+func (r *Report) Rule(id, description string) {
+	if r.Rules == nil {
+		r.Rules = make(map[string]string)
+	}
+	r.Rules[id] = description
+}
+
+// Count returns the number of checks at a level.
+func (r *Report) Count(level Level) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Level == level {
+			n++
+		}
+	}
+	return n
+}
+
+// IsFormat reports whether format is one Write supports.
+func IsFormat(format string) bool {
+	return format == FormatJUnit || format == FormatSARIF
+}
+
+// Write writes the report in the given format.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJUnit:
+		return r.WriteJUnit(w)
+	case FormatSARIF:
+		return r.WriteSARIF(w)
+	default:
+		return fmt.Errorf("unsupported report format: %s (use junit or sarif)", format)
+	}
+}
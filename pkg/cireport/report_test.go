@@ -0,0 +1,110 @@
+package cireport
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func sampleReport() *Report {
+	r := NewReport("traffic2openapi validate-spec", "1.2.3")
+	r.Rule("invalid-spec", "The spec fails OpenAPI validation")
+	r.Add(Check{Name: "good.yaml", Group: "validate-spec", File: "good.yaml"})
+	r.Add(Check{
+		Name:    "bad.yaml",
+		Group:   "validate-spec",
+		RuleID:  "invalid-spec",
+		Level:   LevelError,
+		Message: "paths must be an object",
+		File:    "specs/bad.yaml",
+		Line:    4,
+		Column:  3,
+	})
+	r.Add(Check{Name: "bad.yaml", Group: "validate-spec", RuleID: "unused-component", Level: LevelWarning, Message: "schema Foo is unused"})
+	return r
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().WriteJUnit(&buf); err != nil {
+		t.Fatalf("WriteJUnit failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Error("expected XML header")
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if doc.Tests != 3 || doc.Failures != 1 || len(doc.Suites) != 1 {
+		t.Fatalf("expected 3 tests and 1 failure, got %+v", doc)
+	}
+	cases := doc.Suites[0].Cases
+	if cases[0].Failure != nil {
+		t.Error("expected passing check to have no failure")
+	}
+	if f := cases[1].Failure; f == nil || f.Type != "invalid-spec" || f.Message != "paths must be an object" || strings.TrimSpace(f.Text) != "specs/bad.yaml:4:3" {
+		t.Errorf("unexpected failure: %+v", f)
+	}
+	if cases[2].Failure != nil || cases[2].SystemOut != "warning: schema Foo is unused" {
+		t.Errorf("expected warning as output, got %+v", cases[2])
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().Write(&buf, FormatSARIF); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log: %+v", log)
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "traffic2openapi validate-spec" || run.Tool.Driver.Version != "1.2.3" {
+		t.Errorf("unexpected driver: %+v", run.Tool.Driver)
+	}
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "invalid-spec" || run.Tool.Driver.Rules[0].ShortDescription == nil {
+		t.Errorf("unexpected rules: %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected passing checks to be left out, got %d results", len(run.Results))
+	}
+	first := run.Results[0]
+	if first.Level != "error" || len(first.Locations) != 1 {
+		t.Fatalf("unexpected result: %+v", first)
+	}
+	loc := first.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "specs/bad.yaml" || loc.Region == nil || loc.Region.StartLine != 4 {
+		t.Errorf("unexpected location: %+v", loc)
+	}
+	if run.Results[1].Level != "warning" || run.Results[1].Locations != nil {
+		t.Errorf("unexpected warning result: %+v", run.Results[1])
+	}
+}
+
+func TestWriteEmptySARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewReport("traffic2openapi diff", "").WriteSARIF(&buf); err != nil {
+		t.Fatalf("WriteSARIF failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"results": []`) {
+		t.Errorf("expected empty results array, got %s", buf.String())
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	if err := sampleReport().Write(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+	if !IsFormat(FormatJUnit) || IsFormat("text") {
+		t.Error("unexpected IsFormat result")
+	}
+}
@@ -0,0 +1,287 @@
+package curl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// Converter converts parsed curl commands to IR records.
+type Converter struct {
+	// IncludeHeaders controls whether to include HTTP headers in output.
+	IncludeHeaders bool
+
+	// FilterHeaders is a list of header names to exclude (case-insensitive).
+	FilterHeaders []string
+
+	// Execute sends each request and records the live response. When false,
+	// records get a placeholder 200 response without a body, marked with
+	// ir.MetaResponsePlaceholder so that inference ignores it.
+	Execute bool
+
+	// DataDir is the directory relative @file arguments of -d, --json, and
+	// --data-urlencode are read from (default: the current directory).
+	DataDir string
+
+	// Client is the HTTP client used when Execute is true.
+	Client *http.Client
+
+	// Metadata is copied into the metadata of every converted record.
+	Metadata map[string]any
+}
+
+// NewConverter creates a new curl to IR converter with default settings.
+func NewConverter() *Converter {
+	return &Converter{
+		IncludeHeaders: true,
+		FilterHeaders: []string{
+			"authorization",
+			"cookie",
+			"set-cookie",
+			"x-api-key",
+			"x-auth-token",
+			"x-csrf-token",
+			"proxy-authorization",
+		},
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ConvertLine parses a curl command line and converts it to an IR record.
+func (c *Converter) ConvertLine(line string) (*ir.IRRecord, error) {
+	cmd, err := ParseInDir(line, c.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	return c.Convert(cmd)
+}
+
+// Convert converts a parsed curl command to an IR record.
+func (c *Converter) Convert(cmd *Command) (*ir.IRRecord, error) {
+	parsedURL, err := url.Parse(cmd.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing URL: %w", err)
+	}
+
+	record := &ir.IRRecord{
+		Source: ptrSource(ir.IRRecordSourceCurl),
+		Request: ir.Request{
+			Method: ir.RequestMethod(cmd.Method),
+			Scheme: ir.RequestScheme(strings.ToLower(parsedURL.Scheme)),
+			Host:   ptrString(parsedURL.Host),
+			Path:   parsedURL.Path,
+		},
+		Response: ir.Response{
+			Status: 200,
+		},
+		Metadata: maps.Clone(c.Metadata),
+	}
+	if record.Request.Path == "" {
+		record.Request.Path = "/"
+	}
+
+	// Extract query parameters (repeated keys become arrays)
+	if query := parsedURL.Query(); len(query) > 0 {
+		record.Request.Query = make(map[string]interface{})
+		for k, v := range query {
+			if value := queryValue(v); value != nil {
+				record.Request.Query[k] = value
+			}
+		}
+	}
+
+	contentType := cmd.Header("Content-Type")
+	if contentType != "" {
+		record.Request.ContentType = ptrString(contentType)
+	}
+
+	if c.IncludeHeaders {
+		headers := make(map[string][]string)
+		for _, h := range cmd.Headers {
+			name := strings.ToLower(h.Name)
+			if c.shouldFilterHeader(name) {
+				continue
+			}
+			headers[name] = append(headers[name], h.Value)
+		}
+		record.Request.Headers, record.Request.HeaderValues = ir.SplitHeaderValues(headers)
+	}
+
+	switch {
+	case cmd.Body != "":
+		record.Request.Body = parseBody(cmd.Body, contentType)
+	case len(cmd.Form) > 0:
+		form := make(map[string]interface{}, len(cmd.Form))
+		for k, v := range cmd.Form {
+			form[k] = v
+		}
+		record.Request.Body = form
+	}
+
+	if c.Execute {
+		if err := c.execute(cmd, record); err != nil {
+			return nil, err
+		}
+	} else {
+		record.SetMeta(ir.MetaResponsePlaceholder, true)
+	}
+
+	return record, nil
+}
+
+// execute sends the request and fills in the response of the record.
+func (c *Converter) execute(cmd *Command, record *ir.IRRecord) error {
+	var body io.Reader
+	formContentType := ""
+	if cmd.Body != "" {
+		body = strings.NewReader(cmd.Body)
+	} else if len(cmd.Form) > 0 {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		for k, v := range cmd.Form {
+			if err := mw.WriteField(k, v); err != nil {
+				return fmt.Errorf("writing form field %s: %w", k, err)
+			}
+		}
+		if err := mw.Close(); err != nil {
+			return fmt.Errorf("writing form: %w", err)
+		}
+		body = &buf
+		formContentType = mw.FormDataContentType()
+	}
+
+	req, err := http.NewRequest(cmd.Method, cmd.URL, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	for _, h := range cmd.Headers {
+		req.Header.Add(h.Name, h.Value)
+	}
+	if formContentType != "" {
+		req.Header.Set("Content-Type", formContentType)
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("executing %s %s: %w", cmd.Method, cmd.URL, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	duration := ir.Milliseconds(time.Since(start))
+
+	record.Timestamp = ptrTime(start.UTC())
+	record.DurationMs = &duration
+	record.Response.Status = resp.StatusCode
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		record.Response.ContentType = ptrString(contentType)
+	}
+
+	if c.IncludeHeaders && len(resp.Header) > 0 {
+		headers := make(map[string][]string)
+		for name, values := range resp.Header {
+			if !c.shouldFilterHeader(name) {
+				headers[name] = values
+			}
+		}
+		record.Response.Headers, record.Response.HeaderValues = ir.SplitHeaderValues(headers)
+	}
+
+	if len(data) > 0 {
+		record.Response.Body = parseBody(string(data), contentType)
+	}
+
+	return nil
+}
+
+// shouldFilterHeader checks if a header should be filtered out.
+func (c *Converter) shouldFilterHeader(name string) bool {
+	for _, filter := range c.FilterHeaders {
+		if strings.EqualFold(filter, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryValue returns a single string for one value or a list for repeated keys.
+func queryValue(values []string) interface{} {
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	default:
+		list := make([]interface{}, len(values))
+		for i, v := range values {
+			list[i] = v
+		}
+		return list
+	}
+}
+
+// parseBody parses a body string based on its content type.
+func parseBody(text, contentType string) interface{} {
+	if text == "" {
+		return nil
+	}
+
+	mimeType := strings.ToLower(contentType)
+
+	if strings.Contains(mimeType, "x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(text); err == nil && len(values) > 0 {
+			form := make(map[string]interface{}, len(values))
+			for k, v := range values {
+				form[k] = queryValue(v)
+			}
+			return form
+		}
+		return text
+	}
+
+	if strings.Contains(mimeType, "text") ||
+		strings.Contains(mimeType, "xml") ||
+		strings.Contains(mimeType, "html") {
+		return text
+	}
+
+	// Try JSON for JSON and unknown types
+	var v interface{}
+	if err := json.Unmarshal([]byte(text), &v); err == nil {
+		return v
+	}
+
+	return text
+}
+
+func ptrString(s string) *string {
+	return &s
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func ptrSource(s ir.IRRecordSource) *ir.IRRecordSource {
+	return &s
+}
@@ -0,0 +1,89 @@
+package curl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestConvertLine(t *testing.T) {
+	c := NewConverter()
+	record, err := c.ConvertLine(`curl -X POST 'https://api.example.com/users?tag=a&tag=b' -H 'Content-Type: application/json' -H 'Authorization: Bearer tok' -d '{"name":"Alice"}'`)
+	if err != nil {
+		t.Fatalf("ConvertLine failed: %v", err)
+	}
+
+	if record.Source == nil || *record.Source != ir.IRRecordSourceCurl {
+		t.Errorf("expected curl source, got %v", record.Source)
+	}
+	if record.Request.Method != ir.RequestMethodPOST {
+		t.Errorf("expected POST, got %s", record.Request.Method)
+	}
+	if record.Request.Host == nil || *record.Request.Host != "api.example.com" {
+		t.Errorf("unexpected host: %v", record.Request.Host)
+	}
+	if record.Request.Path != "/users" {
+		t.Errorf("expected /users, got %s", record.Request.Path)
+	}
+	if tags, ok := record.Request.Query["tag"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("expected tag array, got %v", record.Request.Query["tag"])
+	}
+	if _, ok := record.Request.Headers["authorization"]; ok {
+		t.Error("authorization header should be filtered")
+	}
+	if record.Request.Headers["content-type"] != "application/json" {
+		t.Errorf("expected content-type header, got %v", record.Request.Headers)
+	}
+	body, ok := record.Request.Body.(map[string]interface{})
+	if !ok || body["name"] != "Alice" {
+		t.Errorf("unexpected body: %v", record.Request.Body)
+	}
+	if record.Response.Status != 200 || !record.HasPlaceholderResponse() {
+		t.Errorf("expected marked placeholder status 200, got %d (%v)", record.Response.Status, record.Metadata)
+	}
+}
+
+func TestConvertFormBody(t *testing.T) {
+	record, err := NewConverter().ConvertLine(`curl https://api.example.com/login -d user=bob -d remember=true`)
+	if err != nil {
+		t.Fatalf("ConvertLine failed: %v", err)
+	}
+	body, ok := record.Request.Body.(map[string]interface{})
+	if !ok || body["user"] != "bob" || body["remember"] != "true" {
+		t.Errorf("unexpected form body: %v", record.Request.Body)
+	}
+}
+
+func TestConvertExecute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-Test") != "yes" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"42"}`))
+	}))
+	defer server.Close()
+
+	c := NewConverter()
+	c.Execute = true
+	c.Client = server.Client()
+
+	record, err := c.ConvertLine(`curl -X POST ` + server.URL + `/items -H 'X-Test: yes' -d '{}'`)
+	if err != nil {
+		t.Fatalf("ConvertLine failed: %v", err)
+	}
+	if record.Response.Status != http.StatusCreated {
+		t.Errorf("expected 201, got %d", record.Response.Status)
+	}
+	body, ok := record.Response.Body.(map[string]interface{})
+	if !ok || body["id"] != "42" {
+		t.Errorf("unexpected response body: %v", record.Response.Body)
+	}
+	if record.DurationMs == nil {
+		t.Error("expected duration to be recorded")
+	}
+}
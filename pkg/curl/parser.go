@@ -0,0 +1,491 @@
+// Package curl provides an adapter for converting curl command lines to IR format.
+//
+// Commands are parsed with POSIX shell quoting rules (single quotes, double
+// quotes, backslash escapes, $'...' strings and line continuations), which
+// covers the output of "Copy as cURL" in browser DevTools and most
+// hand-written commands.
+package curl
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotCurl is returned when a command line does not start with curl.
+var ErrNotCurl = errors.New("not a curl command")
+
+// Header is a single request header in command-line order.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Command is a parsed curl command line.
+type Command struct {
+	Method  string
+	URL     string
+	Headers []Header
+	Body    string            // request body from -d/--data*/--json
+	Form    map[string]string // multipart fields from -F/--form
+}
+
+// Header returns the value of the first header matching name (case-insensitive).
+func (c *Command) Header(name string) string {
+	for _, h := range c.Headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// setDefaultHeader adds a header unless it is already present.
+func (c *Command) setDefaultHeader(name, value string) {
+	if c.Header(name) == "" {
+		c.Headers = append(c.Headers, Header{Name: name, Value: value})
+	}
+}
+
+// Flags that take a value but do not affect the recorded request.
+var ignoredValueFlags = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true,
+	"--connect-timeout": true, "--retry": true, "-w": true, "--write-out": true,
+	"-x": true, "--proxy": true, "--cacert": true, "--cert": true, "-E": true,
+	"--key": true, "-c": true, "--cookie-jar": true, "-r": true, "--range": true,
+	"--resolve": true, "--limit-rate": true, "-K": true, "--config": true,
+	"--interface": true, "-U": true, "--proxy-user": true,
+}
+
+// Short flags that take a value, allowing attached forms like -XPOST.
+var shortValueFlags = "XHdFuAebTomwxEcrKU"
+
+// Parse parses a single curl command line, reading @file data arguments
+// relative to the current directory.
+func Parse(line string) (*Command, error) {
+	return ParseInDir(line, "")
+}
+
+// ParseInDir parses a single curl command line, reading relative @file data
+// arguments (-d @body.json) from dir.
+func ParseInDir(line, dir string) (*Command, error) {
+	args, err := splitArgs(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || path.Base(args[0]) != "curl" {
+		return nil, ErrNotCurl
+	}
+
+	cmd := &Command{}
+	var (
+		method    string
+		data      []string
+		getMode   bool
+		headMode  bool
+		jsonMode  bool
+		uploading bool
+	)
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if cmd.URL == "" {
+				cmd.URL = arg
+			}
+			continue
+		}
+
+		// Split combined short flags (-sSL, -sXPOST) into one flag each
+		if flags := splitShortFlags(arg); len(flags) > 1 {
+			args = append(args[:i], append(flags, args[i+1:]...)...)
+			arg = args[i]
+		}
+
+		// Split --flag=value and attached short values (-XPOST)
+		name, value, hasValue := arg, "", false
+		if strings.HasPrefix(arg, "--") {
+			if eq := strings.Index(arg, "="); eq > 0 {
+				name, value, hasValue = arg[:eq], arg[eq+1:], true
+			}
+		} else if len(arg) > 2 && strings.ContainsRune(shortValueFlags, rune(arg[1])) {
+			name, value, hasValue = arg[:2], arg[2:], true
+		}
+
+		next := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch name {
+		case "-X", "--request":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			method = strings.ToUpper(v)
+		case "-H", "--header":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if h, ok := parseHeader(v); ok {
+				cmd.Headers = append(cmd.Headers, h)
+			}
+		case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw", "--json":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if name != "--data-raw" {
+				// -d and --data-ascii strip newlines from files, like curl
+				if v, err = readDataArg(v, dir, name != "--data-binary" && name != "--json"); err != nil {
+					return nil, err
+				}
+			}
+			data = append(data, v)
+			jsonMode = jsonMode || name == "--json"
+		case "--data-urlencode":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if v, err = urlEncodeData(v, dir); err != nil {
+				return nil, err
+			}
+			data = append(data, v)
+		case "-F", "--form", "--form-string":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if cmd.Form == nil {
+				cmd.Form = make(map[string]string)
+			}
+			if k, fv, ok := strings.Cut(v, "="); ok {
+				cmd.Form[k] = fv
+			}
+		case "-u", "--user":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			cmd.setDefaultHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(v)))
+		case "-A", "--user-agent":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			cmd.setDefaultHeader("User-Agent", v)
+		case "-e", "--referer":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			cmd.setDefaultHeader("Referer", v)
+		case "-b", "--cookie":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if strings.Contains(v, "=") {
+				cmd.setDefaultHeader("Cookie", v)
+			}
+		case "-T", "--upload-file":
+			if _, err := next(); err != nil {
+				return nil, err
+			}
+			uploading = true
+		case "--url":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			cmd.URL = v
+		case "-G", "--get":
+			getMode = true
+		case "-I", "--head":
+			headMode = true
+		default:
+			if ignoredValueFlags[name] {
+				if _, err := next(); err != nil {
+					return nil, err
+				}
+			}
+			// Other flags (-s, -L, -k, --compressed, ...) do not change the request
+		}
+	}
+
+	if cmd.URL == "" {
+		return nil, fmt.Errorf("no URL in curl command")
+	}
+	if !strings.Contains(cmd.URL, "://") {
+		cmd.URL = "http://" + cmd.URL
+	}
+
+	body := strings.Join(data, "&")
+	if getMode && body != "" {
+		cmd.URL = appendQuery(cmd.URL, body)
+		body = ""
+	}
+	cmd.Body = body
+
+	switch {
+	case jsonMode:
+		cmd.setDefaultHeader("Content-Type", "application/json")
+		cmd.setDefaultHeader("Accept", "application/json")
+	case cmd.Body != "":
+		cmd.setDefaultHeader("Content-Type", "application/x-www-form-urlencoded")
+	case len(cmd.Form) > 0:
+		cmd.setDefaultHeader("Content-Type", "multipart/form-data")
+	}
+
+	// Method resolution follows curl: explicit -X, then -I, -G, upload, data
+	switch {
+	case method != "":
+		cmd.Method = method
+	case headMode:
+		cmd.Method = "HEAD"
+	case getMode:
+		cmd.Method = "GET"
+	case uploading:
+		cmd.Method = "PUT"
+	case len(data) > 0 || len(cmd.Form) > 0:
+		cmd.Method = "POST"
+	default:
+		cmd.Method = "GET"
+	}
+
+	return cmd, nil
+}
+
+// parseHeader parses "Name: value". Headers of the form "Name;" (empty value)
+// are accepted; "Name:" (header removal) is ignored.
+func parseHeader(s string) (Header, bool) {
+	if name, value, ok := strings.Cut(s, ":"); ok {
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if name == "" || value == "" {
+			return Header{}, false
+		}
+		return Header{Name: name, Value: value}, true
+	}
+	if name, ok := strings.CutSuffix(strings.TrimSpace(s), ";"); ok && name != "" {
+		return Header{Name: name}, true
+	}
+	return Header{}, false
+}
+
+// splitShortFlags splits combined short flags such as -sSL into -s, -S,
+// and -L. The first flag taking a value keeps the rest of the argument as
+// its attached value (-sXPOST becomes -s and -XPOST). Other arguments are
+// returned unchanged.
+func splitShortFlags(arg string) []string {
+	if len(arg) <= 2 || strings.HasPrefix(arg, "--") {
+		return []string{arg}
+	}
+	var flags []string
+	for j := 1; j < len(arg); j++ {
+		if strings.IndexByte(shortValueFlags, arg[j]) >= 0 {
+			return append(flags, "-"+arg[j:])
+		}
+		flags = append(flags, "-"+arg[j:j+1])
+	}
+	return flags
+}
+
+// readDataArg returns the data of a -d, --data-binary, or --json argument:
+// the contents of the named file for @file, else the argument itself. With
+// stripNewlines, carriage returns and newlines are removed from file data.
+// Data read from stdin (@-) is not available and is left empty.
+func readDataArg(arg, dir string, stripNewlines bool) (string, error) {
+	name, ok := strings.CutPrefix(arg, "@")
+	if !ok {
+		return arg, nil
+	}
+	if name == "-" {
+		return "", nil
+	}
+	if dir != "" && !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("reading data file: %w", err)
+	}
+	if stripNewlines {
+		return strings.NewReplacer("\r", "", "\n", "").Replace(string(data)), nil
+	}
+	return string(data), nil
+}
+
+// urlEncodeData encodes a --data-urlencode argument: "name=value", "value",
+// "=value", or "name@file" and "@file", whose file contents are encoded.
+func urlEncodeData(s, dir string) (string, error) {
+	if eq := strings.IndexAny(s, "=@"); eq >= 0 && s[eq] == '@' {
+		value, err := readDataArg(s[eq:], dir, false)
+		if err != nil {
+			return "", err
+		}
+		if eq == 0 {
+			return url.QueryEscape(value), nil
+		}
+		return s[:eq] + "=" + url.QueryEscape(value), nil
+	}
+	if name, value, ok := strings.Cut(s, "="); ok {
+		if name == "" {
+			return url.QueryEscape(value), nil
+		}
+		return name + "=" + url.QueryEscape(value), nil
+	}
+	return url.QueryEscape(s), nil
+}
+
+// appendQuery appends raw query data to a URL.
+func appendQuery(rawURL, query string) string {
+	if strings.Contains(rawURL, "?") {
+		return rawURL + "&" + query
+	}
+	return rawURL + "?" + query
+}
+
+// splitArgs splits a command line into arguments using POSIX shell quoting.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+	)
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] == '\n' || runes[i] == '\r' {
+					// Line continuation
+					if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+						i++
+					}
+					continue
+				}
+				current.WriteRune(runes[i])
+				inArg = true
+			}
+		case r == '\'':
+			end := indexRune(runes, '\'', i+1)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+			inArg = true
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			s, end, err := readANSIString(runes, i+2)
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(s)
+			i = end
+			inArg = true
+		case r == '"':
+			s, end, err := readDoubleQuoted(runes, i+1)
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(s)
+			i = end
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// readDoubleQuoted reads a double-quoted string starting after the opening quote.
+func readDoubleQuoted(runes []rune, start int) (string, int, error) {
+	var sb strings.Builder
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			return sb.String(), i, nil
+		case '\\':
+			if i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+				i++
+				if runes[i] != '\n' {
+					sb.WriteRune(runes[i])
+				}
+				continue
+			}
+			sb.WriteRune(runes[i])
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated double quote")
+}
+
+// readANSIString reads a $'...' string starting after the opening quote.
+func readANSIString(runes []rune, start int) (string, int, error) {
+	var sb strings.Builder
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '\'':
+			return sb.String(), i, nil
+		case '\\':
+			if i+1 >= len(runes) {
+				break
+			}
+			i++
+			switch runes[i] {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			default:
+				sb.WriteRune(runes[i])
+			}
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated $' quote")
+}
+
+// indexRune returns the index of r in runes at or after start, or -1.
+func indexRune(runes []rune, r rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,203 @@
+package curl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		wantMethod  string
+		wantURL     string
+		wantBody    string
+		wantHeaders map[string]string
+	}{
+		{
+			name:       "simple GET",
+			line:       `curl https://api.example.com/users`,
+			wantMethod: "GET",
+			wantURL:    "https://api.example.com/users",
+		},
+		{
+			name:        "POST with JSON body",
+			line:        `curl -X POST https://api.example.com/users -H 'Content-Type: application/json' -d '{"name": "Alice"}'`,
+			wantMethod:  "POST",
+			wantURL:     "https://api.example.com/users",
+			wantBody:    `{"name": "Alice"}`,
+			wantHeaders: map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name:        "implicit POST from data",
+			line:        `curl api.example.com/login --data "user=bob&pass=secret"`,
+			wantMethod:  "POST",
+			wantURL:     "http://api.example.com/login",
+			wantBody:    "user=bob&pass=secret",
+			wantHeaders: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		},
+		{
+			name:       "attached method and long flag values",
+			line:       `curl -XDELETE --url=https://api.example.com/users/1 -sSL --compressed`,
+			wantMethod: "DELETE",
+			wantURL:    "https://api.example.com/users/1",
+		},
+		{
+			name:       "get mode moves data to query",
+			line:       `curl -G https://api.example.com/search -d q=shoes --data-urlencode "sort=price desc"`,
+			wantMethod: "GET",
+			wantURL:    "https://api.example.com/search?q=shoes&sort=price+desc",
+		},
+		{
+			name:        "json flag",
+			line:        `curl --json '{"a":1}' https://api.example.com/items`,
+			wantMethod:  "POST",
+			wantURL:     "https://api.example.com/items",
+			wantBody:    `{"a":1}`,
+			wantHeaders: map[string]string{"Content-Type": "application/json", "Accept": "application/json"},
+		},
+		{
+			name:        "devtools ansi quoting and continuation",
+			line:        "curl 'https://api.example.com/notes' \\\n  -H 'accept: */*' \\\n  --data-raw $'{\"text\":\"it\\'s\"}'",
+			wantMethod:  "POST",
+			wantURL:     "https://api.example.com/notes",
+			wantBody:    `{"text":"it's"}`,
+			wantHeaders: map[string]string{"accept": "*/*"},
+		},
+		{
+			name:       "combined short flags",
+			line:       `curl -sSLk https://api.example.com/users`,
+			wantMethod: "GET",
+			wantURL:    "https://api.example.com/users",
+		},
+		{
+			name:       "combined short flags ending in an attached value",
+			line:       `curl -sXPATCH https://api.example.com/users/1 -sd name=bob`,
+			wantMethod: "PATCH",
+			wantURL:    "https://api.example.com/users/1",
+			wantBody:   "name=bob",
+		},
+		{
+			name:       "combined short flags with a separate value",
+			line:       `curl -sX PUT -sG https://api.example.com/items -d q=x`,
+			wantMethod: "PUT",
+			wantURL:    "https://api.example.com/items?q=x",
+		},
+		{
+			name:       "head flag in a cluster",
+			line:       `curl -sI https://api.example.com/health`,
+			wantMethod: "HEAD",
+			wantURL:    "https://api.example.com/health",
+		},
+		{
+			name:       "data raw keeps an at sign",
+			line:       `curl https://api.example.com/mail --data-raw '@everyone'`,
+			wantMethod: "POST",
+			wantURL:    "https://api.example.com/mail",
+			wantBody:   "@everyone",
+		},
+		{
+			name:        "basic auth",
+			line:        `curl -u admin:secret https://api.example.com/admin`,
+			wantMethod:  "GET",
+			wantURL:     "https://api.example.com/admin",
+			wantHeaders: map[string]string{"Authorization": "Basic YWRtaW46c2VjcmV0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := Parse(tt.line)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if cmd.Method != tt.wantMethod {
+				t.Errorf("Method = %q, want %q", cmd.Method, tt.wantMethod)
+			}
+			if cmd.URL != tt.wantURL {
+				t.Errorf("URL = %q, want %q", cmd.URL, tt.wantURL)
+			}
+			if cmd.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", cmd.Body, tt.wantBody)
+			}
+			for name, want := range tt.wantHeaders {
+				if got := cmd.Header(name); got != want {
+					t.Errorf("Header(%q) = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(`wget https://example.com`); !errors.Is(err, ErrNotCurl) {
+		t.Errorf("expected ErrNotCurl, got %v", err)
+	}
+	if _, err := Parse(`curl -X POST`); err == nil {
+		t.Error("expected error for missing URL")
+	}
+	if _, err := Parse(`curl 'https://example.com`); err == nil {
+		t.Error("expected error for unterminated quote")
+	}
+	if _, err := Parse(`curl https://example.com -H`); err == nil {
+		t.Error("expected error for missing flag value")
+	}
+}
+
+func TestParseDataFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "body.json"), []byte("{\"name\":\n \"Alice\"}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "note.txt"), []byte("a&b c"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line     string
+		wantBody string
+	}{
+		{`curl https://api.example.com/users -d @body.json`, `{"name": "Alice"}`},
+		{`curl https://api.example.com/users -d@body.json`, `{"name": "Alice"}`},
+		{`curl https://api.example.com/users --data-binary @body.json`, "{\"name\":\n \"Alice\"}\n"},
+		{`curl https://api.example.com/users --json @body.json`, "{\"name\":\n \"Alice\"}\n"},
+		{`curl https://api.example.com/notes --data-urlencode text@note.txt`, "text=a%26b+c"},
+		{`curl https://api.example.com/notes --data-urlencode @note.txt`, "a%26b+c"},
+		{`curl https://api.example.com/users -d @-`, ""},
+	}
+	for _, tt := range tests {
+		cmd, err := ParseInDir(tt.line, dir)
+		if err != nil {
+			t.Fatalf("ParseInDir(%q) failed: %v", tt.line, err)
+		}
+		if cmd.Body != tt.wantBody {
+			t.Errorf("ParseInDir(%q) body = %q, want %q", tt.line, cmd.Body, tt.wantBody)
+		}
+		if cmd.Method != "POST" {
+			t.Errorf("ParseInDir(%q) method = %q, want POST", tt.line, cmd.Method)
+		}
+	}
+
+	if _, err := ParseInDir(`curl https://api.example.com/users -d @missing.json`, dir); err == nil {
+		t.Error("expected error for a missing data file")
+	}
+}
+
+func TestSplitShortFlags(t *testing.T) {
+	tests := map[string][]string{
+		"-sSL":    {"-s", "-S", "-L"},
+		"-XPOST":  {"-XPOST"},
+		"-sXPOST": {"-s", "-XPOST"},
+		"-sH":     {"-s", "-H"},
+		"-k":      {"-k"},
+		"--data":  {"--data"},
+	}
+	for arg, want := range tests {
+		if got := splitShortFlags(arg); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitShortFlags(%q) = %q, want %q", arg, got, want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package curl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// Reader reads files of curl commands and converts them to IR format.
+//
+// Each command is on its own line. Lines ending in a backslash continue on
+// the next line, and blank lines and lines starting with # are skipped.
+type Reader struct {
+	Converter *Converter
+}
+
+// NewReader creates a new curl reader with default settings.
+func NewReader() *Reader {
+	return &Reader{
+		Converter: NewConverter(),
+	}
+}
+
+// ReadFile reads a file of curl commands and returns IR records, recording
+// the file name in each record's metadata.
+func (r *Reader) ReadFile(path string) ([]ir.IRRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+
+	records, err := r.Read(f)
+	if err != nil {
+		return nil, err
+	}
+	ir.ApplyMetadata(records, map[string]any{ir.MetaSourceFile: filepath.Base(path)})
+	return records, nil
+}
+
+// Read reads curl commands from an io.Reader and returns IR records.
+func (r *Reader) Read(reader io.Reader) ([]ir.IRRecord, error) {
+	var records []ir.IRRecord
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	var (
+		command   strings.Builder
+		startLine int
+		lineNum   int
+	)
+
+	flush := func() error {
+		line := strings.TrimSpace(command.String())
+		command.Reset()
+		if line == "" {
+			return nil
+		}
+		record, err := r.Converter.ConvertLine(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", startLine, err)
+		}
+		records = append(records, *record)
+		return nil
+	}
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if command.Len() == 0 {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			startLine = lineNum
+		}
+
+		if cont, ok := strings.CutSuffix(line, "\\"); ok {
+			command.WriteString(cont)
+			command.WriteString(" ")
+			continue
+		}
+
+		command.WriteString(line)
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -0,0 +1,34 @@
+package curl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestReaderRead(t *testing.T) {
+	input := `# exported from support ticket
+curl https://api.example.com/users
+
+curl -X POST https://api.example.com/users \
+  -H 'Content-Type: application/json' \
+  -d '{"name":"Bob"}'
+curl https://api.example.com/users/1
+`
+	records, err := NewReader().Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if records[1].Request.Method != ir.RequestMethodPOST {
+		t.Errorf("expected continued command to be POST, got %s", records[1].Request.Method)
+	}
+
+	_, err = NewReader().Read(strings.NewReader("curl https://a.example.com\nhttp GET b.example.com\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected line 2 error, got %v", err)
+	}
+}
@@ -1,4 +1,5 @@
-// Package har provides an adapter for converting HAR (HTTP Archive) files to IR format.
+// Package har provides an adapter for converting HAR (HTTP Archive) files to IR format
+// and for exporting IR records back to HAR.
 //
 // HAR is a standard format for recording HTTP transactions, supported by:
 //   - Browser DevTools (Chrome, Firefox, Safari)
@@ -10,6 +11,7 @@ package har
 import (
 	"encoding/base64"
 	"encoding/json"
+	"maps"
 	"net/url"
 	"strings"
 	"time"
@@ -28,6 +30,21 @@ type Converter struct {
 
 	// IncludeCookies controls whether to include cookies in headers.
 	IncludeCookies bool
+
+	// APIOnly skips static assets and page loads (HTML, scripts, stylesheets,
+	// images, fonts, media) when converting batches.
+	APIOnly bool
+
+	// Filter, if set, skips entries whose host or path it does not allow
+	// when converting batches.
+	Filter *ir.RequestMatcher
+
+	// IncludeBinaryBodies keeps binary bodies (images, PDFs, octet-stream)
+	// base64-encoded. By default they are left out of the IR.
+	IncludeBinaryBodies bool
+
+	// Metadata is copied into the metadata of every converted record.
+	Metadata map[string]any
 }
 
 // NewConverter creates a new HAR to IR converter with default settings.
@@ -62,6 +79,7 @@ func (c *Converter) Convert(entry *har.Entry) *ir.IRRecord {
 		Response: ir.Response{
 			Status: int(entry.Response.Status),
 		},
+		Metadata: maps.Clone(c.Metadata),
 	}
 
 	// Parse timestamp
@@ -82,12 +100,12 @@ func (c *Converter) Convert(entry *har.Entry) *ir.IRRecord {
 			record.Request.Path = "/"
 		}
 
-		// Extract query parameters
+		// Extract query parameters (repeated keys become arrays)
 		if len(parsedURL.Query()) > 0 {
 			record.Request.Query = make(map[string]interface{})
 			for k, v := range parsedURL.Query() {
-				if len(v) > 0 {
-					record.Request.Query[k] = v[0]
+				if value := queryValue(v); value != nil {
+					record.Request.Query[k] = value
 				}
 			}
 		}
@@ -98,16 +116,25 @@ func (c *Converter) Convert(entry *har.Entry) *ir.IRRecord {
 		if record.Request.Query == nil {
 			record.Request.Query = make(map[string]interface{})
 		}
+		values := make(map[string][]string)
+		var names []string
 		for _, nvp := range entry.Request.QueryString {
-			record.Request.Query[nvp.Name] = nvp.Value
+			if _, seen := values[nvp.Name]; !seen {
+				names = append(names, nvp.Name)
+			}
+			values[nvp.Name] = append(values[nvp.Name], nvp.Value)
+		}
+		for _, name := range names {
+			record.Request.Query[name] = queryValue(values[name])
 		}
 	}
 
 	// Convert request headers
 	if c.IncludeHeaders && len(entry.Request.Headers) > 0 {
-		headers := c.convertHeaders(entry.Request.Headers)
+		headers, values := c.convertHeaders(entry.Request.Headers)
 		if len(headers) > 0 {
 			record.Request.Headers = headers
+			record.Request.HeaderValues = values
 			if ct, ok := headers["content-type"]; ok {
 				record.Request.ContentType = ptrString(ct)
 			}
@@ -116,7 +143,7 @@ func (c *Converter) Convert(entry *har.Entry) *ir.IRRecord {
 
 	// Convert request body
 	if entry.Request.PostData != nil && entry.Request.PostData.Text != "" {
-		record.Request.Body = parseBody(
+		record.Request.Body = c.convertBody(
 			entry.Request.PostData.Text,
 			entry.Request.PostData.MimeType,
 			"",
@@ -128,9 +155,10 @@ func (c *Converter) Convert(entry *har.Entry) *ir.IRRecord {
 
 	// Convert response headers
 	if c.IncludeHeaders && len(entry.Response.Headers) > 0 {
-		headers := c.convertHeaders(entry.Response.Headers)
+		headers, values := c.convertHeaders(entry.Response.Headers)
 		if len(headers) > 0 {
 			record.Response.Headers = headers
+			record.Response.HeaderValues = values
 			if ct, ok := headers["content-type"]; ok {
 				record.Response.ContentType = ptrString(ct)
 			}
@@ -139,7 +167,7 @@ func (c *Converter) Convert(entry *har.Entry) *ir.IRRecord {
 
 	// Convert response body
 	if entry.Response.Content != nil && entry.Response.Content.Text != "" {
-		record.Response.Body = parseBody(
+		record.Response.Body = c.convertBody(
 			entry.Response.Content.Text,
 			entry.Response.Content.MimeType,
 			entry.Response.Content.Encoding,
@@ -153,6 +181,12 @@ func (c *Converter) Convert(entry *har.Entry) *ir.IRRecord {
 	if entry.Time > 0 {
 		record.DurationMs = ptrFloat64(entry.Time)
 	}
+	record.Timings = convertTimings(entry.Timings)
+
+	// Carry page reference for grouping by user flow
+	if entry.Pageref != "" {
+		record.SetMeta(ir.MetaHARPageRef, entry.Pageref)
+	}
 
 	return record
 }
@@ -161,24 +195,75 @@ func (c *Converter) Convert(entry *har.Entry) *ir.IRRecord {
 func (c *Converter) ConvertBatch(entries []*har.Entry) []ir.IRRecord {
 	records := make([]ir.IRRecord, 0, len(entries))
 	for _, entry := range entries {
-		if record := c.Convert(entry); record != nil {
-			records = append(records, *record)
+		record := c.Convert(entry)
+		if record == nil {
+			continue
+		}
+		if c.APIOnly && ir.IsNonAPIRecord(record) {
+			continue
+		}
+		if !c.Filter.AllowRecord(record) {
+			continue
 		}
+		records = append(records, *record)
 	}
 	return records
 }
 
-// ConvertHAR converts a complete HAR file to IR records.
+// ConvertHAR converts a complete HAR file to IR records. Records of an
+// entry belonging to a page carry the page's title in their metadata.
 func (c *Converter) ConvertHAR(h *har.HAR) []ir.IRRecord {
 	if h == nil || h.Log == nil {
 		return nil
 	}
-	return c.ConvertBatch(h.Log.Entries)
+	records := c.ConvertBatch(h.Log.Entries)
+
+	titles := make(map[string]string, len(h.Log.Pages))
+	for _, page := range h.Log.Pages {
+		if page != nil && page.Title != "" {
+			titles[page.ID] = page.Title
+		}
+	}
+	for i := range records {
+		if title, ok := titles[records[i].MetaString(ir.MetaHARPageRef)]; ok {
+			records[i].SetMeta(ir.MetaHARPageTitle, title)
+		}
+	}
+	return records
 }
 
-// convertHeaders converts HAR NameValuePair headers to a string map.
-func (c *Converter) convertHeaders(headers []*har.NameValuePair) map[string]string {
-	result := make(map[string]string)
+// convertTimings converts HAR timings to IR timing phases. HAR uses -1 for
+// phases that don't apply and includes ssl time in connect.
+func convertTimings(t *har.Timings) *ir.Timings {
+	if t == nil {
+		return nil
+	}
+	phase := func(ms float64) *float64 {
+		if ms <= 0 {
+			return nil
+		}
+		return ptrFloat64(ms)
+	}
+	timings := &ir.Timings{
+		DnsMs: phase(t.DNS),
+		TlsMs: phase(t.Ssl),
+	}
+	if t.Connect > 0 {
+		timings.ConnectMs = phase(t.Connect - max(t.Ssl, 0))
+	}
+	if ttfb := max(t.Blocked, 0) + max(t.DNS, 0) + max(t.Connect, 0) + t.Send + t.Wait; ttfb > 0 {
+		timings.TtfbMs = ptrFloat64(ttfb)
+	}
+	if *timings == (ir.Timings{}) {
+		return nil
+	}
+	return timings
+}
+
+// convertHeaders converts HAR NameValuePair headers to the first value of
+// each header and the values of repeated headers.
+func (c *Converter) convertHeaders(headers []*har.NameValuePair) (map[string]string, map[string][]string) {
+	result := make(map[string][]string)
 
 	for _, h := range headers {
 		name := strings.ToLower(h.Name)
@@ -193,10 +278,10 @@ func (c *Converter) convertHeaders(headers []*har.NameValuePair) map[string]stri
 			continue
 		}
 
-		result[name] = h.Value
+		result[name] = append(result[name], h.Value)
 	}
 
-	return result
+	return ir.SplitHeaderValues(result)
 }
 
 // shouldFilterHeader checks if a header should be filtered out.
@@ -210,6 +295,50 @@ func (c *Converter) shouldFilterHeader(name string) bool {
 	return false
 }
 
+// queryValue returns a single string for one value or a list for repeated keys.
+func queryValue(values []string) interface{} {
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	default:
+		list := make([]interface{}, len(values))
+		for i, v := range values {
+			list[i] = v
+		}
+		return list
+	}
+}
+
+// convertBody converts a HAR body. Binary bodies are left out unless
+// IncludeBinaryBodies is set, in which case they are kept base64-encoded.
+func (c *Converter) convertBody(text, mimeType, encoding string) interface{} {
+	if !isBinaryBody(text, mimeType, encoding) {
+		return parseBody(text, mimeType, encoding)
+	}
+	if !c.IncludeBinaryBodies {
+		return nil
+	}
+	if encoding == "base64" {
+		return text
+	}
+	return base64.StdEncoding.EncodeToString([]byte(text))
+}
+
+// isBinaryBody reports whether a body has a binary content type or decodes
+// from base64 to binary data.
+func isBinaryBody(text, mimeType, encoding string) bool {
+	if ir.IsBinaryContentType(mimeType) {
+		return true
+	}
+	if encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(text)
+		return err == nil && ir.IsBinaryData(decoded)
+	}
+	return false
+}
+
 // parseBody parses a body string, handling JSON and base64 encoding.
 func parseBody(text, mimeType, encoding string) interface{} {
 	if text == "" {
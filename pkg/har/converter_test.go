@@ -225,6 +225,39 @@ func TestConverterBase64Body(t *testing.T) {
 	}
 }
 
+func TestConverterBinaryBody(t *testing.T) {
+	// Base64 encoded PNG signature
+	entry := &har.Entry{
+		Request: &har.Request{
+			Method: "GET",
+			URL:    "https://api.example.com/avatar",
+		},
+		Response: &har.Response{
+			Status: 200,
+			Content: &har.Content{
+				MimeType: "image/png",
+				Text:     "iVBORw0KGgo=",
+				Encoding: "base64",
+			},
+		},
+	}
+
+	converter := NewConverter()
+	record := converter.Convert(entry)
+	if record.Response.Body != nil {
+		t.Errorf("expected binary body to be skipped, got %v", record.Response.Body)
+	}
+	if record.Response.ContentType == nil || *record.Response.ContentType != "image/png" {
+		t.Errorf("expected content type image/png, got %v", record.Response.ContentType)
+	}
+
+	converter.IncludeBinaryBodies = true
+	record = converter.Convert(entry)
+	if record.Response.Body != "iVBORw0KGgo=" {
+		t.Errorf("expected base64 body, got %v", record.Response.Body)
+	}
+}
+
 func TestConverterNilEntry(t *testing.T) {
 	converter := NewConverter()
 
@@ -274,3 +307,148 @@ func TestConvertBatch(t *testing.T) {
 		t.Errorf("expected DELETE, got %s", records[2].Request.Method)
 	}
 }
+
+func TestConverterRepeatedQueryParams(t *testing.T) {
+	converter := NewConverter()
+
+	entry := &har.Entry{
+		Request: &har.Request{
+			Method: "GET",
+			URL:    "https://api.example.com/items?tag=a&tag=b&limit=10",
+			QueryString: []*har.NameValuePair{
+				{Name: "tag", Value: "a"},
+				{Name: "tag", Value: "b"},
+				{Name: "limit", Value: "10"},
+			},
+		},
+		Response: &har.Response{Status: 200},
+	}
+
+	record := converter.Convert(entry)
+	if record == nil {
+		t.Fatal("expected record, got nil")
+	}
+
+	tags, ok := record.Request.Query["tag"].([]interface{})
+	if !ok || len(tags) != 2 || tags[0] != "a" || tags[1] != "b" {
+		t.Errorf("expected tag=[a b], got %v", record.Request.Query["tag"])
+	}
+	if record.Request.Query["limit"] != "10" {
+		t.Errorf("expected limit=10, got %v", record.Request.Query["limit"])
+	}
+}
+
+func TestConverterRepeatedHeaders(t *testing.T) {
+	converter := NewConverter()
+
+	entry := &har.Entry{
+		Request: &har.Request{
+			Method: "GET",
+			URL:    "https://api.example.com/items",
+			Headers: []*har.NameValuePair{
+				{Name: "Via", Value: "1.1 proxy-a"},
+				{Name: "via", Value: "1.1 proxy-b"},
+				{Name: "Accept", Value: "application/json"},
+			},
+		},
+		Response: &har.Response{Status: 200},
+	}
+
+	record := converter.Convert(entry)
+	if record == nil {
+		t.Fatal("expected record, got nil")
+	}
+	if record.Request.Headers["via"] != "1.1 proxy-a" {
+		t.Errorf("expected first via value, got %q", record.Request.Headers["via"])
+	}
+	if via := record.Request.HeaderValues["via"]; len(via) != 2 || via[1] != "1.1 proxy-b" {
+		t.Errorf("expected both via values, got %v", via)
+	}
+	if _, ok := record.Request.HeaderValues["accept"]; ok {
+		t.Error("single-valued headers should not be in header values")
+	}
+}
+
+func TestConverterTimings(t *testing.T) {
+	converter := NewConverter()
+
+	entry := &har.Entry{
+		Request:  &har.Request{Method: "GET", URL: "https://api.example.com/items"},
+		Response: &har.Response{Status: 200},
+		Time:     120.5,
+		Timings:  &har.Timings{Blocked: -1, DNS: 5, Connect: 30, Ssl: 20, Send: 0.5, Wait: 60, Receive: 25},
+	}
+
+	timings := converter.Convert(entry).Timings
+	if timings == nil || timings.DnsMs == nil || timings.ConnectMs == nil || timings.TlsMs == nil || timings.TtfbMs == nil {
+		t.Fatalf("expected all timing phases, got %+v", timings)
+	}
+	if *timings.DnsMs != 5 || *timings.ConnectMs != 10 || *timings.TlsMs != 20 || *timings.TtfbMs != 95.5 {
+		t.Errorf("unexpected timings: dns=%v connect=%v tls=%v ttfb=%v", *timings.DnsMs, *timings.ConnectMs, *timings.TlsMs, *timings.TtfbMs)
+	}
+
+	entry.Timings = &har.Timings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: 0, Receive: 0}
+	if timings := converter.Convert(entry).Timings; timings != nil {
+		t.Errorf("expected no timings when no phase applies, got %+v", timings)
+	}
+}
+
+func TestConverterAPIOnly(t *testing.T) {
+	converter := NewConverter()
+	converter.APIOnly = true
+
+	newEntry := func(url, mimeType string) *har.Entry {
+		return &har.Entry{
+			Request: &har.Request{Method: "GET", URL: url},
+			Response: &har.Response{
+				Status:  200,
+				Headers: []*har.NameValuePair{{Name: "Content-Type", Value: mimeType}},
+			},
+		}
+	}
+
+	records := converter.ConvertBatch([]*har.Entry{
+		newEntry("https://example.com/api/users", "application/json"),
+		newEntry("https://example.com/", "text/html; charset=utf-8"),
+		newEntry("https://example.com/static/app.js", "application/javascript"),
+		newEntry("https://example.com/logo.png", "image/png"),
+		newEntry("https://example.com/fonts/inter", "font/woff2"),
+	})
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 API record, got %d", len(records))
+	}
+	if records[0].Request.Path != "/api/users" {
+		t.Errorf("expected /api/users, got %s", records[0].Request.Path)
+	}
+}
+
+func TestConverterFilter(t *testing.T) {
+	filter, err := ir.NewRequestMatcher([]string{"*.example.com"}, []string{"cdn.example.com"}, []string{"/health"})
+	if err != nil {
+		t.Fatalf("NewRequestMatcher: %v", err)
+	}
+	converter := NewConverter()
+	converter.Filter = filter
+
+	newEntry := func(url string) *har.Entry {
+		return &har.Entry{
+			Request:  &har.Request{Method: "GET", URL: url},
+			Response: &har.Response{Status: 200},
+		}
+	}
+
+	records := converter.ConvertBatch([]*har.Entry{
+		newEntry("https://api.example.com/users"),
+		newEntry("https://api.example.com/health"),
+		newEntry("https://cdn.example.com/logo.png"),
+		newEntry("https://tracker.example.org/collect"),
+	})
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	if records[0].Request.Path != "/users" {
+		t.Errorf("expected /users, got %s", records[0].Request.Path)
+	}
+}
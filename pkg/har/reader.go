@@ -2,6 +2,7 @@ package har
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,6 +13,10 @@ import (
 	"github.com/grokify/traffic2openapi/pkg/ir"
 )
 
+// ErrMissingLog is returned when HAR data has no log field, as when a JSON
+// file that isn't a HAR export is read.
+var ErrMissingLog = errors.New("invalid HAR: missing log field")
+
 // Reader reads HAR files and converts them to IR format.
 type Reader struct {
 	Converter *Converter
@@ -24,7 +29,8 @@ func NewReader() *Reader {
 	}
 }
 
-// ReadFile reads a HAR file and returns IR records.
+// ReadFile reads a HAR file and returns IR records, recording the file name
+// in each record's metadata.
 func (r *Reader) ReadFile(path string) ([]ir.IRRecord, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -32,7 +38,12 @@ func (r *Reader) ReadFile(path string) ([]ir.IRRecord, error) {
 	}
 	defer f.Close()
 
-	return r.Read(f)
+	records, err := r.Read(f)
+	if err != nil {
+		return nil, err
+	}
+	ir.ApplyMetadata(records, map[string]any{ir.MetaSourceFile: filepath.Base(path)})
+	return records, nil
 }
 
 // Read reads HAR data from an io.Reader and returns IR records.
@@ -95,7 +106,7 @@ func Parse(data []byte) (*har.HAR, error) {
 	}
 
 	if h.Log == nil {
-		return nil, fmt.Errorf("invalid HAR: missing log field")
+		return nil, ErrMissingLog
 	}
 
 	return &h, nil
@@ -185,3 +196,33 @@ func FilterByContentType(h *har.HAR, contentType string) []*har.Entry {
 		return strings.Contains(strings.ToLower(e.Response.Content.MimeType), contentType)
 	})
 }
+
+// Pages returns the pages metadata of a HAR file, in log order.
+func Pages(h *har.HAR) []*har.Page {
+	if h == nil || h.Log == nil {
+		return nil
+	}
+	return h.Log.Pages
+}
+
+// FilterByPage returns entries belonging to the given page ID (pageref).
+func FilterByPage(h *har.HAR, pageID string) []*har.Entry {
+	return FilterEntries(h, func(e *har.Entry) bool {
+		return e.Pageref == pageID
+	})
+}
+
+// GroupByPage groups entries by their pageref. Entries without a pageref
+// are grouped under the empty string.
+func GroupByPage(h *har.HAR) map[string][]*har.Entry {
+	if h == nil || h.Log == nil {
+		return nil
+	}
+
+	groups := make(map[string][]*har.Entry)
+	for _, entry := range h.Log.Entries {
+		groups[entry.Pageref] = append(groups[entry.Pageref], entry)
+	}
+
+	return groups
+}
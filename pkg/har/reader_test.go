@@ -1,6 +1,7 @@
 package har
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -146,8 +147,8 @@ func TestParseInvalid(t *testing.T) {
 
 	// Missing log field
 	_, err = Parse([]byte(`{}`))
-	if err == nil {
-		t.Error("expected error for missing log")
+	if !errors.Is(err, ErrMissingLog) {
+		t.Errorf("expected ErrMissingLog, got %v", err)
 	}
 }
 
@@ -417,6 +418,54 @@ func TestFilterByContentType(t *testing.T) {
 	}
 }
 
+func TestPagesAndFilterByPage(t *testing.T) {
+	h, err := Parse([]byte(`{
+		"log": {
+			"version": "1.2",
+			"pages": [
+				{"startedDateTime": "2024-01-15T10:30:00.000Z", "id": "page_1", "title": "Login", "pageTimings": {}},
+				{"startedDateTime": "2024-01-15T10:31:00.000Z", "id": "page_2", "title": "Checkout", "pageTimings": {}}
+			],
+			"entries": [
+				{"pageref": "page_1", "request": {"method": "GET", "url": "https://example.com/login", "httpVersion": "HTTP/1.1", "headers": [], "queryString": [], "cookies": [], "headersSize": 0, "bodySize": 0}, "response": {"status": 200, "statusText": "OK", "httpVersion": "HTTP/1.1", "headers": [], "cookies": [], "content": {"size": 0, "mimeType": "application/json"}, "redirectURL": "", "headersSize": 0, "bodySize": 0}, "cache": {}, "timings": {"send": 0, "wait": 0, "receive": 0}},
+				{"pageref": "page_2", "request": {"method": "GET", "url": "https://example.com/cart", "httpVersion": "HTTP/1.1", "headers": [], "queryString": [], "cookies": [], "headersSize": 0, "bodySize": 0}, "response": {"status": 200, "statusText": "OK", "httpVersion": "HTTP/1.1", "headers": [], "cookies": [], "content": {"size": 0, "mimeType": "application/json"}, "redirectURL": "", "headersSize": 0, "bodySize": 0}, "cache": {}, "timings": {"send": 0, "wait": 0, "receive": 0}},
+				{"pageref": "page_2", "request": {"method": "GET", "url": "https://example.com/orders", "httpVersion": "HTTP/1.1", "headers": [], "queryString": [], "cookies": [], "headersSize": 0, "bodySize": 0}, "response": {"status": 200, "statusText": "OK", "httpVersion": "HTTP/1.1", "headers": [], "cookies": [], "content": {"size": 0, "mimeType": "application/json"}, "redirectURL": "", "headersSize": 0, "bodySize": 0}, "cache": {}, "timings": {"send": 0, "wait": 0, "receive": 0}}
+			]
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	pages := Pages(h)
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	if pages[1].Title != "Checkout" {
+		t.Errorf("expected second page title Checkout, got %s", pages[1].Title)
+	}
+
+	if entries := FilterByPage(h, "page_2"); len(entries) != 2 {
+		t.Errorf("expected 2 entries for page_2, got %d", len(entries))
+	}
+
+	groups := GroupByPage(h)
+	if len(groups["page_1"]) != 1 || len(groups["page_2"]) != 2 {
+		t.Errorf("unexpected grouping: page_1=%d page_2=%d", len(groups["page_1"]), len(groups["page_2"]))
+	}
+
+	records := NewConverter().ConvertHAR(h)
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if ref := records[0].MetaString(ir.MetaHARPageRef); ref != "page_1" {
+		t.Errorf("expected pageref page_1, got %q", ref)
+	}
+	if title := records[1].MetaString(ir.MetaHARPageTitle); title != "Checkout" {
+		t.Errorf("expected page title Checkout, got %q", title)
+	}
+}
+
 func TestFilterEntriesNil(t *testing.T) {
 	var nilHAR *chromedphar.HAR = nil
 
@@ -0,0 +1,313 @@
+package har
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/har"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// HAR format constants used when exporting.
+const (
+	harVersion  = "1.2"
+	httpVersion = "HTTP/1.1"
+)
+
+// Writer converts IR records to HAR 1.2 format.
+type Writer struct {
+	// CreatorName is written to log.creator.name.
+	CreatorName string
+
+	// CreatorVersion is written to log.creator.version.
+	CreatorVersion string
+}
+
+// NewWriter creates a new IR to HAR writer with default settings.
+func NewWriter() *Writer {
+	return &Writer{
+		CreatorName:    "traffic2openapi",
+		CreatorVersion: "0.1.0",
+	}
+}
+
+// ToHAR converts IR records to a HAR document. Records with a HAR page
+// reference in their metadata produce a page entry per distinct reference,
+// titled after the page title metadata when present.
+func (w *Writer) ToHAR(records []ir.IRRecord) *har.HAR {
+	log := &har.Log{
+		Version: harVersion,
+		Creator: &har.Creator{
+			Name:    w.CreatorName,
+			Version: w.CreatorVersion,
+		},
+		Entries: make([]*har.Entry, 0, len(records)),
+	}
+
+	pages := make(map[string]bool)
+	for i := range records {
+		entry := w.ConvertRecord(&records[i])
+		log.Entries = append(log.Entries, entry)
+
+		if entry.Pageref != "" && !pages[entry.Pageref] {
+			pages[entry.Pageref] = true
+			title := records[i].MetaString(ir.MetaHARPageTitle)
+			if title == "" {
+				title = entry.Pageref
+			}
+			log.Pages = append(log.Pages, &har.Page{
+				StartedDateTime: entry.StartedDateTime,
+				ID:              entry.Pageref,
+				Title:           title,
+				PageTimings:     &har.PageTimings{},
+			})
+		}
+	}
+
+	return &har.HAR{Log: log}
+}
+
+// ConvertRecord converts a single IR record to a HAR entry.
+func (w *Writer) ConvertRecord(record *ir.IRRecord) *har.Entry {
+	started := time.Now().UTC()
+	if record.Timestamp != nil {
+		started = record.Timestamp.UTC()
+	}
+
+	var duration float64
+	if record.DurationMs != nil {
+		duration = *record.DurationMs
+	}
+
+	entry := &har.Entry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Time:            duration,
+		Request:         convertRequest(&record.Request),
+		Response:        convertResponse(&record.Response),
+		Cache:           &har.Cache{},
+		Timings:         entryTimings(record.Timings, duration),
+	}
+
+	entry.Pageref = record.MetaString(ir.MetaHARPageRef)
+
+	return entry
+}
+
+// entryTimings converts IR timing phases to HAR timings. Without phases,
+// the whole duration is reported as waiting time.
+func entryTimings(t *ir.Timings, duration float64) *har.Timings {
+	if t == nil || t.TtfbMs == nil {
+		return &har.Timings{Wait: duration}
+	}
+	phase := func(ms *float64) float64 {
+		if ms == nil {
+			return -1
+		}
+		return *ms
+	}
+	timings := &har.Timings{
+		Blocked: -1,
+		DNS:     phase(t.DnsMs),
+		Connect: phase(t.ConnectMs),
+		Ssl:     phase(t.TlsMs),
+	}
+	// HAR connect time includes the TLS handshake
+	if t.TlsMs != nil {
+		timings.Connect = max(timings.Connect, 0) + *t.TlsMs
+	}
+	timings.Wait = max(*t.TtfbMs-max(timings.DNS, 0)-max(timings.Connect, 0), 0)
+	timings.Receive = max(duration-*t.TtfbMs, 0)
+	return timings
+}
+
+// Write writes IR records to w as a HAR JSON document.
+func (w *Writer) Write(out io.Writer, records []ir.IRRecord) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(w.ToHAR(records)); err != nil {
+		return fmt.Errorf("encoding HAR: %w", err)
+	}
+	return nil
+}
+
+// WriteFile writes IR records to a HAR file.
+func (w *Writer) WriteFile(path string, records []ir.IRRecord) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer f.Close()
+
+	return w.Write(f, records)
+}
+
+// convertRequest converts an IR request to a HAR request.
+func convertRequest(req *ir.Request) *har.Request {
+	result := &har.Request{
+		Method:      string(req.Method),
+		URL:         requestURL(req),
+		HTTPVersion: httpVersion,
+		Cookies:     []*har.Cookie{},
+		Headers:     headerPairs(req.Headers, req.HeaderValues),
+		QueryString: queryPairs(req.Query),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	if req.Body != nil {
+		mimeType := ""
+		if req.ContentType != nil {
+			mimeType = *req.ContentType
+		}
+		text := bodyText(req.Body)
+		result.PostData = &har.PostData{
+			MimeType: mimeType,
+			Params:   []*har.Param{},
+			Text:     text,
+		}
+		result.BodySize = int64(len(text))
+	}
+
+	return result
+}
+
+// convertResponse converts an IR response to a HAR response.
+func convertResponse(resp *ir.Response) *har.Response {
+	mimeType := ""
+	if resp.ContentType != nil {
+		mimeType = *resp.ContentType
+	}
+
+	text := ""
+	if resp.Body != nil {
+		text = bodyText(resp.Body)
+	}
+
+	return &har.Response{
+		Status:      int64(resp.Status),
+		StatusText:  http.StatusText(resp.Status),
+		HTTPVersion: httpVersion,
+		Cookies:     []*har.Cookie{},
+		Headers:     headerPairs(resp.Headers, resp.HeaderValues),
+		Content: &har.Content{
+			Size:     int64(len(text)),
+			MimeType: mimeType,
+			Text:     text,
+		},
+		RedirectURL: headerValue(resp.Headers, "location"),
+		HeadersSize: -1,
+		BodySize:    int64(len(text)),
+	}
+}
+
+// requestURL builds an absolute URL from IR request fields.
+func requestURL(req *ir.Request) string {
+	u := url.URL{
+		Scheme: string(req.Scheme),
+		Path:   req.Path,
+	}
+	if u.Scheme == "" {
+		u.Scheme = string(ir.RequestSchemeHTTPS)
+	}
+	if req.Host != nil {
+		u.Host = *req.Host
+	} else {
+		u.Host = "localhost"
+	}
+
+	values := url.Values{}
+	for _, nvp := range queryPairs(req.Query) {
+		values.Add(nvp.Name, nvp.Value)
+	}
+	u.RawQuery = values.Encode()
+
+	return u.String()
+}
+
+// queryPairs converts IR query parameters to HAR pairs, expanding arrays
+// into repeated keys.
+func queryPairs(query map[string]interface{}) []*har.NameValuePair {
+	pairs := []*har.NameValuePair{}
+	for _, name := range sortedKeys(query) {
+		switch v := query[name].(type) {
+		case []interface{}:
+			for _, item := range v {
+				pairs = append(pairs, &har.NameValuePair{Name: name, Value: scalarString(item)})
+			}
+		case []string:
+			for _, item := range v {
+				pairs = append(pairs, &har.NameValuePair{Name: name, Value: item})
+			}
+		default:
+			pairs = append(pairs, &har.NameValuePair{Name: name, Value: scalarString(v)})
+		}
+	}
+	return pairs
+}
+
+// headerPairs converts IR headers to HAR pairs in sorted order, with a pair
+// for each value of repeated headers.
+func headerPairs(headers map[string]string, values map[string][]string) []*har.NameValuePair {
+	pairs := make([]*har.NameValuePair, 0, len(headers))
+	for _, name := range sortedKeys(headers) {
+		vals, ok := values[name]
+		if !ok {
+			vals = []string{headers[name]}
+		}
+		for _, v := range vals {
+			pairs = append(pairs, &har.NameValuePair{Name: name, Value: v})
+		}
+	}
+	return pairs
+}
+
+// bodyText renders an IR body as HAR text. Strings are written as-is,
+// everything else is JSON-encoded.
+func bodyText(body interface{}) string {
+	if s, ok := body.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Sprintf("%v", body)
+	}
+	return string(data)
+}
+
+// scalarString formats a query value as a string.
+func scalarString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// headerValue returns a header value using a case-insensitive name match.
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,87 @@
+package har
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	records := []ir.IRRecord{
+		{
+			Timestamp: &ts,
+			Request: ir.Request{
+				Method: ir.RequestMethodPOST,
+				Scheme: ir.RequestSchemeHTTPS,
+				Host:   ptrString("api.example.com"),
+				Path:   "/users",
+				Query: map[string]interface{}{
+					"tag":   []interface{}{"a", "b"},
+					"limit": "10",
+				},
+				Headers:     map[string]string{"content-type": "application/json"},
+				ContentType: ptrString("application/json"),
+				Body:        map[string]interface{}{"name": "Alice"},
+			},
+			Response: ir.Response{
+				Status:      201,
+				ContentType: ptrString("application/json"),
+				Body:        map[string]interface{}{"id": "1"},
+			},
+			DurationMs: ptrFloat64(42),
+			Metadata:   map[string]interface{}{ir.MetaHARPageRef: "page_1", ir.MetaHARPageTitle: "Sign up"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewWriter().Write(&buf, records); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	h, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if h.Log.Version != "1.2" {
+		t.Errorf("expected HAR version 1.2, got %s", h.Log.Version)
+	}
+	if len(h.Log.Pages) != 1 || h.Log.Pages[0].ID != "page_1" || h.Log.Pages[0].Title != "Sign up" {
+		t.Errorf("expected page_1 titled Sign up in pages, got %v", h.Log.Pages)
+	}
+
+	entry := h.Log.Entries[0]
+	if entry.Request.URL != "https://api.example.com/users?limit=10&tag=a&tag=b" {
+		t.Errorf("unexpected URL: %s", entry.Request.URL)
+	}
+	if entry.Response.StatusText != "Created" {
+		t.Errorf("expected status text Created, got %s", entry.Response.StatusText)
+	}
+
+	back := NewConverter().ConvertHAR(h)
+	if len(back) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(back))
+	}
+	rec := back[0]
+	if rec.Request.Method != ir.RequestMethodPOST || rec.Request.Path != "/users" {
+		t.Errorf("unexpected request: %s %s", rec.Request.Method, rec.Request.Path)
+	}
+	if tags, ok := rec.Request.Query["tag"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("expected tag array, got %v", rec.Request.Query["tag"])
+	}
+	body, ok := rec.Response.Body.(map[string]interface{})
+	if !ok || body["id"] != "1" {
+		t.Errorf("unexpected response body: %v", rec.Response.Body)
+	}
+	if rec.Timestamp == nil || !rec.Timestamp.Equal(ts) {
+		t.Errorf("expected timestamp %v, got %v", ts, rec.Timestamp)
+	}
+	if ref := rec.MetaString(ir.MetaHARPageRef); ref != "page_1" {
+		t.Errorf("expected pageref page_1, got %q", ref)
+	}
+	if title := rec.MetaString(ir.MetaHARPageTitle); title != "Sign up" {
+		t.Errorf("expected page title Sign up, got %q", title)
+	}
+}
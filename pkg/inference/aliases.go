@@ -0,0 +1,76 @@
+package inference
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SetQueryParamAliases sets whether query parameters whose names differ only
+// in case, underscores, or hyphens (pageSize, page_size, page-size) are
+// documented as one parameter. It must be called before records are added.
+func (c *EndpointClusterer) SetQueryParamAliases(enabled bool) {
+	c.queryAliases = enabled
+}
+
+// foldParamName returns the name shared by equivalent spellings of a
+// parameter name: lowercased, without underscores and hyphens.
+func foldParamName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+// unifyQueryAliases renames the query observations of a request to the
+// parameter of the endpoint they are a spelling of, recording the spelling
+// as an alias. Spellings of a new parameter are named for the first in
+// sorted order. If a request has several spellings of one parameter, the
+// observation of its canonical name is kept.
+func unifyQueryAliases(params map[string]*ParamData, queryObs map[string]*QueryObservation) map[string]*QueryObservation {
+	canonical := make(map[string]string, len(params))
+	for name := range params {
+		canonical[foldParamName(name)] = name
+	}
+
+	names := make([]string, 0, len(queryObs))
+	for name := range queryObs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make(map[string]*QueryObservation, len(queryObs))
+	aliases := make(map[string][]string)
+	for _, name := range names {
+		key := foldParamName(name)
+		target, ok := canonical[key]
+		if !ok {
+			target = name
+			canonical[key] = name
+		}
+		if target != name {
+			aliases[target] = append(aliases[target], name)
+		}
+		if _, seen := result[target]; !seen || target == name {
+			result[target] = queryObs[name]
+		}
+	}
+
+	for target, spellings := range aliases {
+		param, exists := params[target]
+		if !exists {
+			param = NewParamData(target)
+			param.Required = false // Query params start as optional
+			params[target] = param
+		}
+		param.AddAlias(spellings...)
+	}
+	return result
+}
+
+// AddAlias records other spellings of a parameter's name, kept sorted.
+func (p *ParamData) AddAlias(names ...string) {
+	for _, name := range names {
+		if name != p.Name && !slices.Contains(p.Aliases, name) {
+			p.Aliases = append(p.Aliases, name)
+		}
+	}
+	sort.Strings(p.Aliases)
+}
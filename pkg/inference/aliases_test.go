@@ -0,0 +1,50 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestQueryParamAliases(t *testing.T) {
+	queries := []map[string]any{
+		{"pageSize": "10"},
+		{"page_size": "20", "sort": "name"},
+		{"page-size": "30", "pageSize": "40"},
+	}
+	run := func(aliases bool) *EndpointData {
+		opts := DefaultEngineOptions()
+		opts.QueryParamAliases = aliases
+		engine := NewEngine(opts)
+		for _, query := range queries {
+			engine.ProcessRecord(&ir.IRRecord{
+				Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items", Query: query},
+				Response: ir.Response{Status: 200},
+			})
+		}
+		return engine.Finalize().Endpoints["GET /items"]
+	}
+
+	if got := len(run(false).QueryParams); got != 4 {
+		t.Errorf("expected 4 parameters without aliases, got %d", got)
+	}
+
+	items := run(true)
+	if len(items.QueryParams) != 2 {
+		t.Fatalf("expected pageSize and sort, got %v", items.QueryParams)
+	}
+	pageSize := items.QueryParams["pageSize"]
+	if pageSize == nil {
+		t.Fatalf("expected the first spelling to be canonical, got %v", items.QueryParams)
+	}
+	if !slices.Equal(pageSize.Aliases, []string{"page-size", "page_size"}) {
+		t.Errorf("aliases = %v", pageSize.Aliases)
+	}
+	if !slices.Equal(pageSize.Examples, []any{"10", "20", "40"}) {
+		t.Errorf("pageSize examples = %v, want observations of every spelling", pageSize.Examples)
+	}
+	if len(items.QueryParams["sort"].Aliases) != 0 {
+		t.Errorf("sort should have no aliases")
+	}
+}
@@ -0,0 +1,130 @@
+package inference
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// maxPendingMonitors is the most status monitor URLs remembered while
+// waiting for them to be polled, so memory stays flat on large captures.
+const maxPendingMonitors = 10000
+
+// monitorHeaders are the 202 Accepted response headers naming the status
+// monitor of a long-running operation, most specific first.
+var monitorHeaders = []string{"Operation-Location", "Azure-AsyncOperation", "Location"}
+
+// AsyncDetector detects long-running operations: endpoints answered with
+// 202 Accepted whose status monitor URL, from the Location or
+// Operation-Location header, is later polled with GET, e.g. POST /reports
+// returning Location: /operations/42, then GET /operations/{operationId}.
+type AsyncDetector struct {
+	pending map[string]asyncSource // monitor path -> 202 response naming it
+	counts  map[asyncKey]int
+}
+
+// AsyncOperation is an initiating endpoint and the endpoint polled for its
+// status.
+type AsyncOperation struct {
+	Source  string // initiating endpoint key, e.g. "POST /reports"
+	Header  string // 202 response header holding the monitor URL, e.g. "Location"
+	Monitor string // polling endpoint key, e.g. "GET /operations/{operationId}"
+	Count   int    // polls observed
+}
+
+type asyncSource struct {
+	endpoint string
+	header   string
+}
+
+type asyncKey struct {
+	source  asyncSource
+	monitor string
+}
+
+// NewAsyncDetector creates a new AsyncDetector.
+func NewAsyncDetector() *AsyncDetector {
+	return &AsyncDetector{
+		pending: make(map[string]asyncSource),
+		counts:  make(map[asyncKey]int),
+	}
+}
+
+// DetectFromRecord counts a GET of a monitor URL returned earlier as a poll
+// of that operation, then remembers the monitor URL of a 202 response.
+func (d *AsyncDetector) DetectFromRecord(endpoint, method, path string, status int, responseHeaders map[string]string) {
+	if method == http.MethodGet {
+		if source, ok := d.pending[path]; ok && source.endpoint != endpoint {
+			d.counts[asyncKey{source: source, monitor: endpoint}]++
+		}
+	}
+
+	if status != http.StatusAccepted {
+		return
+	}
+	header, value := monitorHeader(responseHeaders)
+	if header == "" {
+		return
+	}
+	monitor := monitorPath(path, value)
+	if monitor == "" {
+		return
+	}
+	if _, ok := d.pending[monitor]; !ok && len(d.pending) >= maxPendingMonitors {
+		return
+	}
+	d.pending[monitor] = asyncSource{endpoint: endpoint, header: header}
+}
+
+// GetOperations returns the detected long-running operations, sorted by
+// initiating endpoint and monitor.
+func (d *AsyncDetector) GetOperations() []*AsyncOperation {
+	ops := make([]*AsyncOperation, 0, len(d.counts))
+	for key, count := range d.counts {
+		ops = append(ops, &AsyncOperation{
+			Source:  key.source.endpoint,
+			Header:  key.source.header,
+			Monitor: key.monitor,
+			Count:   count,
+		})
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		a, b := ops[i], ops[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Monitor != b.Monitor {
+			return a.Monitor < b.Monitor
+		}
+		return a.Header < b.Header
+	})
+	return ops
+}
+
+// monitorHeader returns the canonical name and value of the monitor header
+// in a response, if any.
+func monitorHeader(headers map[string]string) (string, string) {
+	for _, name := range monitorHeaders {
+		for key, value := range headers {
+			if strings.EqualFold(key, name) && value != "" {
+				return name, value
+			}
+		}
+	}
+	return "", ""
+}
+
+// monitorPath returns the path of a monitor URL, which may be absolute or
+// relative to the request path.
+func monitorPath(requestPath, monitor string) string {
+	ref, err := url.Parse(strings.TrimSpace(monitor))
+	if err != nil {
+		return ""
+	}
+	base, err := url.Parse(requestPath)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).Path
+}
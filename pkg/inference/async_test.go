@@ -0,0 +1,57 @@
+package inference
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestAsyncOperations(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/reports"},
+			Response: ir.Response{Status: 202, Headers: map[string]string{"location": "https://api.example.com/operations/11111111-1111-4111-8111-111111111111?view=full"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/operations/11111111-1111-4111-8111-111111111111"},
+			Response: ir.Response{Status: 200, Body: map[string]any{"status": "running"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/operations/11111111-1111-4111-8111-111111111111"},
+			Response: ir.Response{Status: 200, Body: map[string]any{"status": "succeeded"}},
+		},
+		{
+			// Operation-Location wins over Location, and may be relative
+			Request: ir.Request{Method: ir.RequestMethodPOST, Path: "/v1/exports"},
+			Response: ir.Response{Status: 202, Headers: map[string]string{
+				"Location":           "/v1/exports/1",
+				"Operation-Location": "jobs/22222222-2222-4222-8222-222222222222",
+			}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/v1/jobs/22222222-2222-4222-8222-222222222222"},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			// A 201 Location names the created resource, not a monitor
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users"},
+			Response: ir.Response{Status: 201, Headers: map[string]string{"Location": "/users/1"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/users/1"},
+			Response: ir.Response{Status: 200},
+		},
+	}
+	got := InferFromRecords(records).AsyncOperations
+	want := []*AsyncOperation{
+		{Source: "POST /reports", Header: "Location", Monitor: "GET /operations/{operationId}", Count: 2},
+		{Source: "POST /v1/exports", Header: "Operation-Location", Monitor: "GET /v1/jobs/{jobId}", Count: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for _, op := range got {
+			t.Logf("async operation %+v", *op)
+		}
+		t.Errorf("unexpected async operations")
+	}
+}
@@ -0,0 +1,134 @@
+package inference
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// benchBody returns a realistic nested JSON body: a paginated list of users
+// with addresses, tags, and mixed scalar types.
+func benchBody(n int) map[string]any {
+	users := make([]any, n)
+	for i := range users {
+		users[i] = map[string]any{
+			"id":        fmt.Sprintf("3fa85f64-5717-4562-b3fc-2c963f66a%03d", i),
+			"email":     fmt.Sprintf("user%d@example.com", i),
+			"name":      fmt.Sprintf("User %d", i),
+			"age":       float64(20 + i%50),
+			"active":    i%2 == 0,
+			"createdAt": "2024-01-15T10:30:00Z",
+			"tags":      []any{"alpha", "beta"},
+			"address": map[string]any{
+				"street": fmt.Sprintf("%d Main St", i),
+				"city":   "Springfield",
+				"zip":    "12345",
+			},
+		}
+	}
+	return map[string]any{
+		"users": users,
+		"total": float64(n),
+		"page":  float64(1),
+		"next":  nil,
+	}
+}
+
+// benchRecords returns a synthetic capture spread across several resources
+// with numeric and UUID identifiers.
+func benchRecords(n int) []ir.IRRecord {
+	records := make([]ir.IRRecord, n)
+	for i := range records {
+		var path string
+		switch i % 4 {
+		case 0:
+			path = "/api/v1/users"
+		case 1:
+			path = fmt.Sprintf("/api/v1/users/%d", i)
+		case 2:
+			path = fmt.Sprintf("/api/v1/users/%d/orders/3fa85f64-5717-4562-b3fc-2c963f66a%03d", i, i%1000)
+		default:
+			path = fmt.Sprintf("/api/v1/products/%d/reviews", i)
+		}
+		ct := "application/json"
+		records[i] = ir.IRRecord{
+			Request: ir.Request{
+				Method:  ir.RequestMethodGET,
+				Path:    path,
+				Query:   map[string]any{"limit": "10", "offset": fmt.Sprint(i % 100)},
+				Headers: map[string]string{"accept": "application/json", "x-request-id": fmt.Sprint(i)},
+			},
+			Response: ir.Response{
+				Status:      200,
+				ContentType: &ct,
+				Body:        benchBody(3),
+			},
+		}
+	}
+	return records
+}
+
+func BenchmarkProcessBody(b *testing.B) {
+	body := benchBody(20)
+	b.ReportAllocs()
+	for b.Loop() {
+		ProcessBody(NewSchemaStore(), body)
+	}
+}
+
+func BenchmarkBuildSchemaTree(b *testing.B) {
+	store := NewSchemaStore()
+	for i := 0; i < 50; i++ {
+		ProcessBody(store, benchBody(5))
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		BuildSchemaTree(store)
+	}
+}
+
+func BenchmarkPathInferrerInferTemplate(b *testing.B) {
+	p := NewPathInferrer()
+	paths := []string{
+		"/api/v1/users",
+		"/api/v1/users/12345",
+		"/api/v1/users/12345/orders/3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		"/api/v1/products/987/reviews?sort=desc",
+		"/files/2024-01-15/report.pdf",
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, path := range paths {
+			p.InferTemplate(path)
+		}
+	}
+}
+
+func BenchmarkEngineProcessRecords(b *testing.B) {
+	records := benchRecords(1000)
+	b.ReportAllocs()
+	for b.Loop() {
+		engine := NewEngine(DefaultEngineOptions())
+		engine.ProcessRecords(records)
+		engine.Finalize()
+	}
+}
+
+func BenchmarkTemplateTrieMatch(b *testing.B) {
+	trie := NewTemplateTrie()
+	for i := 0; i < 1000; i++ {
+		trie.Add(fmt.Sprintf("/api/v1/resource%d/{id}/children/{childId}", i))
+	}
+	paths := []string{
+		"/api/v1/resource0/123/children/456",
+		"/api/v1/resource500/abc/children/def",
+		"/api/v1/resource999/42/children/7",
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, path := range paths {
+			trie.Match(path)
+		}
+	}
+}
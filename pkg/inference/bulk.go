@@ -0,0 +1,195 @@
+package inference
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MultiStatusData holds the items of 207 Multi-Status response bodies, with
+// a schema per item status, so that a bulk response reporting 201 for some
+// items and 400 for others isn't inferred as one merged item object.
+type MultiStatusData struct {
+	ItemsPath   string               // property holding the items, "" for a top-level array
+	StatusField string               // item member holding the item status, e.g. "status"
+	Items       map[int]*SchemaStore // item status -> item schema
+}
+
+// multiStatusItemProperties are the body properties checked first for the
+// items of a multi-status response.
+var multiStatusItemProperties = []string{"results", "items", "responses", "data", "operations"}
+
+// multiStatusFields are the item members holding an item's status.
+var multiStatusFields = []string{"status", "statusCode", "status_code", "httpStatus", "code"}
+
+// bulkSegments are path segments naming bulk endpoints, such as /users/batch
+// and Elasticsearch's /_bulk.
+var bulkSegments = map[string]bool{"batch": true, "bulk": true, "_bulk": true, "_batch": true}
+
+// IsBulkPath reports whether a path template names a bulk endpoint: one with
+// a batch or bulk segment (/users/batch, /_bulk) or a batch custom method
+// (/users:batchCreate).
+func IsBulkPath(pathTemplate string) bool {
+	if strings.Contains(pathTemplate, "#") {
+		return false
+	}
+	for _, segment := range splitPathSegments(pathTemplate) {
+		segment = strings.ToLower(segment)
+		if bulkSegments[segment] {
+			return true
+		}
+		if _, method, ok := strings.Cut(segment, ":"); ok && (strings.HasPrefix(method, "batch") || strings.HasPrefix(method, "bulk")) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBulkDetection sets Bulk on endpoints with a bulk path, a 207
+// response, or a request body that is an array of objects.
+func applyBulkDetection(result *InferenceResult) {
+	for _, endpoint := range result.Endpoints {
+		_, multiStatus := endpoint.Responses[http.StatusMultiStatus]
+		endpoint.Bulk = IsBulkPath(endpoint.PathTemplate) || multiStatus || hasObjectArrayBody(endpoint)
+	}
+}
+
+// hasObjectArrayBody reports whether an endpoint's request body is an array
+// of objects, such as a list of users to create.
+func hasObjectArrayBody(endpoint *EndpointData) bool {
+	if endpoint.RequestBody == nil || !methodHasBodySemantics(endpoint.Method) {
+		return false
+	}
+	for path := range endpoint.RequestBody.Schema.Types {
+		if strings.HasPrefix(path, "[].") {
+			return true
+		}
+	}
+	return false
+}
+
+// addMultiStatus records the items of a 207 response body by item status.
+// The item array and status member are fixed by the first body they are
+// found in.
+func (r *ResponseData) addMultiStatus(body any) {
+	if r.MultiStatus == nil {
+		path, field, ok := findMultiStatusItems(body)
+		if !ok {
+			return
+		}
+		r.MultiStatus = &MultiStatusData{ItemsPath: path, StatusField: field, Items: make(map[int]*SchemaStore)}
+	}
+	m := r.MultiStatus
+	for _, item := range multiStatusItems(body, m.ItemsPath) {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		status, ok := itemStatus(obj[m.StatusField])
+		if !ok {
+			continue
+		}
+		store, ok := m.Items[status]
+		if !ok {
+			store = NewSchemaStore()
+			m.Items[status] = store
+		}
+		ProcessBody(store, obj)
+	}
+}
+
+// findMultiStatusItems returns the property holding the items of a
+// multi-status body ("" for a top-level array) and the item member holding
+// their status.
+func findMultiStatusItems(body any) (path, field string, ok bool) {
+	if field, ok := statusField(body); ok {
+		return "", field, true
+	}
+	obj, isObject := body.(map[string]any)
+	if !isObject {
+		return "", "", false
+	}
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return slices.Contains(multiStatusItemProperties, names[i]) && !slices.Contains(multiStatusItemProperties, names[j])
+	})
+	for _, name := range names {
+		if field, ok := statusField(obj[name]); ok {
+			return name, field, true
+		}
+	}
+	return "", "", false
+}
+
+// statusField returns the status member of the items of an array of
+// objects, if the first item has one.
+func statusField(value any) (string, bool) {
+	items, ok := value.([]any)
+	if !ok || len(items) == 0 {
+		return "", false
+	}
+	item, ok := items[0].(map[string]any)
+	if !ok {
+		return "", false
+	}
+	for _, field := range multiStatusFields {
+		if _, ok := itemStatus(item[field]); ok {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// multiStatusItems returns the items of a multi-status body.
+func multiStatusItems(body any, path string) []any {
+	if path != "" {
+		obj, _ := body.(map[string]any)
+		body = obj[path]
+	}
+	items, _ := body.([]any)
+	return items
+}
+
+// itemStatus returns the HTTP status held by an item member, as a number or
+// a numeric string.
+func itemStatus(value any) (int, bool) {
+	var status int
+	switch v := value.(type) {
+	case int:
+		status = v
+	case int64:
+		status = int(v)
+	case float64:
+		status = int(v)
+		if float64(status) != v {
+			return 0, false
+		}
+	case string:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, false
+		}
+		status = n
+	default:
+		return 0, false
+	}
+	return status, status >= 100 && status <= 599
+}
+
+// clone returns a deep copy of the multi-status data.
+func (m *MultiStatusData) clone() *MultiStatusData {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	c.Items = make(map[int]*SchemaStore, len(m.Items))
+	for status, store := range m.Items {
+		c.Items[status] = store.clone()
+	}
+	return &c
+}
@@ -0,0 +1,76 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestBulkEndpoints(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/users/batch":       true,
+		"/_bulk":             true,
+		"/users:batchCreate": true,
+		"/v1/Bulk/{jobId}":   true,
+		"/users/{userId}":    false,
+		"/batches":           false,
+		"/soap#Batch":        false,
+	} {
+		if got := IsBulkPath(path); got != want {
+			t.Errorf("IsBulkPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodPOST, Path: "/users/import", Body: []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}}},
+			Response: ir.Response{Status: 207, Body: map[string]any{
+				"results": []any{
+					map[string]any{"status": float64(201), "id": "1"},
+					map[string]any{"status": float64(400), "error": "name taken"},
+				},
+			}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/tags", Body: []any{map[string]any{"label": "go"}}},
+			Response: ir.Response{Status: 201},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{"name": "a"}},
+			Response: ir.Response{Status: 201, Body: map[string]any{"results": []any{map[string]any{"status": "ok"}}}},
+		},
+	}
+	result := InferFromRecords(records)
+	for key, want := range map[string]bool{"POST /users/import": true, "POST /tags": true, "POST /users": false} {
+		if got := result.Endpoints[key].Bulk; got != want {
+			t.Errorf("%s Bulk = %v, want %v", key, got, want)
+		}
+	}
+
+	multi := result.Endpoints["POST /users/import"].Responses[207].MultiStatus
+	if multi == nil {
+		t.Fatal("expected multi-status items for 207 response")
+	}
+	if multi.ItemsPath != "results" || multi.StatusField != "status" {
+		t.Errorf("items path/status field = %q/%q", multi.ItemsPath, multi.StatusField)
+	}
+	if len(multi.Items) != 2 {
+		t.Fatalf("expected items for 2 statuses, got %d", len(multi.Items))
+	}
+	created := BuildSchemaTree(multi.Items[201])
+	if _, ok := created.Properties["error"]; ok {
+		t.Error("201 item schema has the 400 item's error property")
+	}
+	if _, ok := BuildSchemaTree(multi.Items[400]).Properties["error"]; !ok {
+		t.Error("400 item schema is missing error")
+	}
+
+	// The envelope stays an object with an array property
+	body := BuildSchemaTree(result.Endpoints["POST /users/import"].Responses[207].Body)
+	if body.Type != TypeObject || body.Properties["results"] == nil {
+		t.Errorf("207 body schema = %+v, want object with results", body)
+	}
+	if result.Endpoints["POST /users"].Responses[201].MultiStatus != nil {
+		t.Error("multi-status items recorded for a non-207 response")
+	}
+}
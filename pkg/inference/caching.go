@@ -0,0 +1,77 @@
+package inference
+
+import (
+	"net/http"
+	"strings"
+)
+
+// requestContractHeaders are request headers that carry operational
+// contracts (idempotent retries and conditional requests). They are
+// documented as parameters even when generic header exclusion drops them.
+var requestContractHeaders = map[string]string{
+	"idempotency-key":     "Unique key identifying the request, so a retried request is applied only once",
+	"if-match":            "Perform the request only if the resource's current ETag matches (412 Precondition Failed otherwise)",
+	"if-none-match":       "Return the resource only if its current ETag does not match (304 Not Modified otherwise)",
+	"if-modified-since":   "Return the resource only if it was modified after this date (304 Not Modified otherwise)",
+	"if-unmodified-since": "Perform the request only if the resource was not modified after this date (412 Precondition Failed otherwise)",
+}
+
+// responseContractHeaders are response headers that describe caching and
+// resource versions. They are documented even when generic header exclusion
+// drops them.
+var responseContractHeaders = map[string]string{
+	"etag":          "Version of the returned representation, for use in If-Match and If-None-Match",
+	"last-modified": "Date the resource was last modified, for use in If-Modified-Since and If-Unmodified-Since",
+	"cache-control": "Caching directives for the response",
+	"age":           "Seconds the response has been held in a shared cache",
+}
+
+// conditionalHeaders are the request headers that make a request conditional.
+var conditionalHeaders = map[string]bool{
+	"if-match":            true,
+	"if-none-match":       true,
+	"if-modified-since":   true,
+	"if-unmodified-since": true,
+}
+
+// HeaderDescription returns a description of an idempotency, conditional
+// request, or caching header, or "" for other headers.
+func HeaderDescription(name string) string {
+	name = strings.ToLower(name)
+	if desc, ok := requestContractHeaders[name]; ok {
+		return desc
+	}
+	return responseContractHeaders[name]
+}
+
+// isConditionalRequest reports whether a request used a conditional header
+// or received a response only conditional requests produce.
+func isConditionalRequest(headers map[string]string, status int) bool {
+	if status == http.StatusNotModified || status == http.StatusPreconditionFailed {
+		return true
+	}
+	for name := range headers {
+		if conditionalHeaders[strings.ToLower(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcludedRequestHeader checks if a request header should be excluded,
+// keeping idempotency and conditional request headers.
+func isExcludedRequestHeader(name string) bool {
+	if _, ok := requestContractHeaders[strings.ToLower(name)]; ok {
+		return false
+	}
+	return isExcludedHeader(name)
+}
+
+// isExcludedResponseHeader checks if a response header should be excluded,
+// keeping caching and resource version headers.
+func isExcludedResponseHeader(name string) bool {
+	if _, ok := responseContractHeaders[strings.ToLower(name)]; ok {
+		return false
+	}
+	return isExcludedHeader(name)
+}
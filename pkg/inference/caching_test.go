@@ -0,0 +1,58 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestConditionalRequestHeaders(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/items", Headers: map[string]string{
+				"if-none-match": `"v1"`, "cache-control": "no-cache",
+			}},
+			Response: ir.Response{Status: 304, Headers: map[string]string{
+				"etag": `"v1"`, "cache-control": "max-age=60", "age": "12", "server": "nginx",
+			}},
+		},
+		{
+			Request: ir.Request{Method: ir.RequestMethodPOST, Path: "/items", Headers: map[string]string{
+				"Idempotency-Key": "c8f0e2a4",
+			}},
+			Response: ir.Response{Status: 201},
+		},
+	}
+	result := InferFromRecords(records)
+
+	get := result.Endpoints["GET /items"]
+	if !get.ConditionalRequests {
+		t.Error("expected GET /items to support conditional requests")
+	}
+	if _, ok := get.HeaderParams["if-none-match"]; !ok {
+		t.Error("expected if-none-match to be documented")
+	}
+	if _, ok := get.HeaderParams["cache-control"]; ok {
+		t.Error("expected request cache-control to be excluded")
+	}
+	headers := get.Responses[304].Headers
+	for _, name := range []string{"etag", "cache-control", "age"} {
+		if _, ok := headers[name]; !ok {
+			t.Errorf("expected response header %q to be documented", name)
+		}
+	}
+	if _, ok := headers["server"]; ok {
+		t.Error("expected response server header to be excluded")
+	}
+
+	post := result.Endpoints["POST /items"]
+	if post.ConditionalRequests {
+		t.Error("expected POST /items not to be flagged as conditional")
+	}
+	if _, ok := post.HeaderParams["Idempotency-Key"]; !ok {
+		t.Error("expected Idempotency-Key to be documented")
+	}
+	if HeaderDescription("Idempotency-Key") == "" || HeaderDescription("X-Tenant") != "" {
+		t.Error("expected descriptions only for operational headers")
+	}
+}
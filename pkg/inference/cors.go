@@ -0,0 +1,161 @@
+package inference
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxCORSValues bounds the distinct values kept per list of a CORS policy.
+const maxCORSValues = 50
+
+// corsHeaderPrefix is the lowercase prefix of CORS response headers.
+const corsHeaderPrefix = "access-control-"
+
+// CORSDetector collects the Access-Control-* response headers observed for
+// each endpoint and host.
+type CORSDetector struct {
+	policies map[string]*CORSPolicy // host + " " + endpoint key -> policy
+}
+
+// CORSPolicy summarizes the CORS response headers observed for one endpoint
+// on one host.
+type CORSPolicy struct {
+	Host             string   `json:"host,omitempty"`
+	Endpoint         string   `json:"endpoint"` // endpoint key, e.g. "GET /users"
+	AllowedOrigins   []string `json:"allowedOrigins,omitempty"`
+	AllowedMethods   []string `json:"allowedMethods,omitempty"`
+	AllowedHeaders   []string `json:"allowedHeaders,omitempty"`
+	ExposedHeaders   []string `json:"exposedHeaders,omitempty"`
+	AllowCredentials bool     `json:"allowCredentials,omitempty"`
+	MaxAge           int      `json:"maxAge,omitempty"` // largest preflight cache lifetime, in seconds
+	Count            int      `json:"count"`            // responses with CORS headers
+}
+
+// NewCORSDetector creates a new CORSDetector.
+func NewCORSDetector() *CORSDetector {
+	return &CORSDetector{policies: make(map[string]*CORSPolicy)}
+}
+
+// DetectFromResponse records the Access-Control-* headers of a response.
+// Responses without them are ignored.
+func (d *CORSDetector) DetectFromResponse(host, endpoint string, headers map[string]string) {
+	var policy *CORSPolicy
+	for name, value := range headers {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, corsHeaderPrefix) {
+			continue
+		}
+		if policy == nil {
+			key := host + " " + endpoint
+			policy = d.policies[key]
+			if policy == nil {
+				policy = &CORSPolicy{Host: host, Endpoint: endpoint}
+				d.policies[key] = policy
+			}
+			policy.Count++
+		}
+		switch name {
+		case "access-control-allow-origin":
+			policy.AllowedOrigins = addCORSValues(policy.AllowedOrigins, []string{strings.TrimSpace(value)})
+		case "access-control-allow-methods":
+			policy.AllowedMethods = addCORSValues(policy.AllowedMethods, splitCORSList(strings.ToUpper(value)))
+		case "access-control-allow-headers":
+			policy.AllowedHeaders = addCORSValues(policy.AllowedHeaders, splitCORSList(strings.ToLower(value)))
+		case "access-control-expose-headers":
+			policy.ExposedHeaders = addCORSValues(policy.ExposedHeaders, splitCORSList(strings.ToLower(value)))
+		case "access-control-allow-credentials":
+			if strings.EqualFold(strings.TrimSpace(value), "true") {
+				policy.AllowCredentials = true
+			}
+		case "access-control-max-age":
+			if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds > policy.MaxAge {
+				policy.MaxAge = seconds
+			}
+		}
+	}
+}
+
+// GetPolicies returns copies of the detected CORS policies, sorted by host
+// and endpoint.
+func (d *CORSDetector) GetPolicies() []*CORSPolicy {
+	policies := make([]*CORSPolicy, 0, len(d.policies))
+	for _, policy := range d.policies {
+		copied := *policy
+		copied.AllowedOrigins = sortedCopy(policy.AllowedOrigins)
+		copied.AllowedMethods = sortedCopy(policy.AllowedMethods)
+		copied.AllowedHeaders = sortedCopy(policy.AllowedHeaders)
+		copied.ExposedHeaders = sortedCopy(policy.ExposedHeaders)
+		policies = append(policies, &copied)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].Host != policies[j].Host {
+			return policies[i].Host < policies[j].Host
+		}
+		return policies[i].Endpoint < policies[j].Endpoint
+	})
+	return policies
+}
+
+// MergeCORSPolicies combines the policies of one endpoint across hosts.
+// The merged policy has no host, and its endpoint is that of the first
+// policy. It returns nil if policies is empty.
+func MergeCORSPolicies(policies []*CORSPolicy) *CORSPolicy {
+	if len(policies) == 0 {
+		return nil
+	}
+	merged := &CORSPolicy{Endpoint: policies[0].Endpoint}
+	for _, policy := range policies {
+		merged.AllowedOrigins = addCORSValues(merged.AllowedOrigins, policy.AllowedOrigins)
+		merged.AllowedMethods = addCORSValues(merged.AllowedMethods, policy.AllowedMethods)
+		merged.AllowedHeaders = addCORSValues(merged.AllowedHeaders, policy.AllowedHeaders)
+		merged.ExposedHeaders = addCORSValues(merged.ExposedHeaders, policy.ExposedHeaders)
+		merged.AllowCredentials = merged.AllowCredentials || policy.AllowCredentials
+		merged.MaxAge = max(merged.MaxAge, policy.MaxAge)
+		merged.Count += policy.Count
+	}
+	sort.Strings(merged.AllowedOrigins)
+	sort.Strings(merged.AllowedMethods)
+	sort.Strings(merged.AllowedHeaders)
+	sort.Strings(merged.ExposedHeaders)
+	return merged
+}
+
+// hasCORSHeaders reports whether headers include an Access-Control-* header.
+func hasCORSHeaders(headers map[string]string) bool {
+	for name := range headers {
+		if len(name) > len(corsHeaderPrefix) && strings.EqualFold(name[:len(corsHeaderPrefix)], corsHeaderPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitCORSList splits a comma-separated header value, dropping empty items.
+func splitCORSList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// addCORSValues appends the values not yet in list, up to maxCORSValues.
+func addCORSValues(list, values []string) []string {
+	for _, value := range values {
+		if value == "" || len(list) >= maxCORSValues || slices.Contains(list, value) {
+			continue
+		}
+		list = append(list, value)
+	}
+	return list
+}
+
+func sortedCopy(values []string) []string {
+	copied := append([]string(nil), values...)
+	sort.Strings(copied)
+	return copied
+}
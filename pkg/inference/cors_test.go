@@ -0,0 +1,61 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestCORSDetector(t *testing.T) {
+	apiHost, otherHost := "api.example.com", "cdn.example.com"
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodOPTIONS, Host: &apiHost, Path: "/items"},
+			Response: ir.Response{Status: 204, Headers: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Methods":     "get, POST",
+				"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "600",
+			}},
+		},
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Host: &apiHost, Path: "/items"},
+			Response: ir.Response{Status: 200, Headers: map[string]string{
+				"access-control-allow-origin":   "https://admin.example.com",
+				"access-control-expose-headers": "X-Total-Count",
+			}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Host: &otherHost, Path: "/items"},
+			Response: ir.Response{Status: 200, Headers: map[string]string{"access-control-allow-origin": "*"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Host: &apiHost, Path: "/health"},
+			Response: ir.Response{Status: 200},
+		},
+	}
+	policies := InferFromRecords(records).CORS
+
+	if len(policies) != 3 {
+		t.Fatalf("expected 3 policies, got %d: %+v", len(policies), policies)
+	}
+	get, preflight, other := policies[0], policies[1], policies[2]
+	if get.Host != apiHost || get.Endpoint != "GET /items" || preflight.Endpoint != "OPTIONS /items" || other.Host != otherHost {
+		t.Fatalf("unexpected policy order: %+v", policies)
+	}
+	if !slices.Equal(preflight.AllowedMethods, []string{"GET", "POST"}) ||
+		!slices.Equal(preflight.AllowedHeaders, []string{"authorization", "content-type"}) ||
+		!preflight.AllowCredentials || preflight.MaxAge != 600 {
+		t.Errorf("unexpected preflight policy: %+v", preflight)
+	}
+	if !slices.Equal(get.ExposedHeaders, []string{"x-total-count"}) || get.AllowCredentials {
+		t.Errorf("unexpected GET policy: %+v", get)
+	}
+
+	merged := MergeCORSPolicies([]*CORSPolicy{get, other})
+	if !slices.Equal(merged.AllowedOrigins, []string{"*", "https://admin.example.com"}) || merged.Count != 2 {
+		t.Errorf("unexpected merged policy: %+v", merged)
+	}
+}
@@ -0,0 +1,108 @@
+package inference
+
+import (
+	"slices"
+	"strings"
+)
+
+// CRUDAction is the create, read, update, or delete semantics of an
+// endpoint, recorded in EndpointData.Action.
+type CRUDAction string
+
+const (
+	CRUDList    CRUDAction = "list"    // GET of a collection
+	CRUDGet     CRUDAction = "get"     // GET of an item or singleton
+	CRUDCreate  CRUDAction = "create"  // POST to a collection
+	CRUDUpdate  CRUDAction = "update"  // PATCH of an item or singleton
+	CRUDReplace CRUDAction = "replace" // PUT of an item or singleton
+	CRUDDelete  CRUDAction = "delete"  // DELETE of an item or singleton
+)
+
+// ClassifyCRUD returns the CRUD action of an endpoint from its method, path
+// shape, and observed status codes, or "" for other operations such as
+// POST /users/{userId}/activate. A path ending in a parameter is an item;
+// one ending in a plural literal (/users) is a collection, and other
+// literals (/users/{userId}/profile) are singletons. POST to a collection
+// is a create if it is plural or a 201 was observed.
+func ClassifyCRUD(method, pathTemplate string, statuses []int) CRUDAction {
+	segments := splitPathSegments(pathTemplate)
+	if len(segments) == 0 || strings.Contains(pathTemplate, "#") {
+		return ""
+	}
+	last := segments[len(segments)-1]
+	_, item := templateParamName(last)
+	collection := !item && pluralSegment(last)
+
+	switch strings.ToUpper(method) {
+	case "GET":
+		if collection {
+			return CRUDList
+		}
+		return CRUDGet
+	case "POST":
+		if !item && (collection || slices.Contains(statuses, 201)) {
+			return CRUDCreate
+		}
+	case "PUT":
+		if !collection {
+			return CRUDReplace
+		}
+	case "PATCH":
+		if !collection {
+			return CRUDUpdate
+		}
+	case "DELETE":
+		if !collection {
+			return CRUDDelete
+		}
+	}
+	return ""
+}
+
+// pluralSegment reports whether a literal path segment names a collection.
+func pluralSegment(segment string) bool {
+	word := strings.ToLower(segment)
+	if strings.HasSuffix(word, "us") || strings.HasSuffix(word, "is") {
+		// status, analysis
+		return false
+	}
+	return singularize(word) != word
+}
+
+// CRUDResource returns the words naming the resource of a path template for
+// a CRUD action: the collections it is nested in, singular, then the
+// resource itself, plural for CRUDList and singular otherwise. API and
+// version prefixes are left out, so GET /v1/users/{userId}/posts gives
+// ["user", "posts"].
+func CRUDResource(action CRUDAction, pathTemplate string) []string {
+	segments := splitPathSegments(pathTemplate)
+	var literals []string
+	for i, segment := range segments {
+		if _, ok := templateParamName(segment); ok || segment == "api" || versionPattern.MatchString(segment) {
+			continue
+		}
+		word := decodeSegment(segment)
+		// Collections followed by an item are named by the item
+		if i+1 < len(segments) {
+			if _, ok := templateParamName(segments[i+1]); ok {
+				word = singularize(word)
+			}
+		}
+		literals = append(literals, word)
+	}
+	if n := len(literals); n > 0 && action != CRUDList && pluralSegment(literals[n-1]) {
+		literals[n-1] = singularize(literals[n-1])
+	}
+	return literals
+}
+
+// applyCRUDActions classifies each endpoint of a result, setting its Action.
+func applyCRUDActions(result *InferenceResult) {
+	for _, endpoint := range result.Endpoints {
+		statuses := make([]int, 0, len(endpoint.Responses))
+		for status := range endpoint.Responses {
+			statuses = append(statuses, status)
+		}
+		endpoint.Action = ClassifyCRUD(endpoint.Method, endpoint.PathTemplate, statuses)
+	}
+}
@@ -0,0 +1,54 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestClassifyCRUD(t *testing.T) {
+	tests := []struct {
+		method   string
+		template string
+		statuses []int
+		want     CRUDAction
+	}{
+		{"GET", "/users", []int{200}, CRUDList},
+		{"GET", "/v1/users/{userId}", []int{200, 404}, CRUDGet},
+		{"GET", "/users/{userId}/profile", []int{200}, CRUDGet},
+		{"POST", "/users", []int{200}, CRUDCreate},
+		{"POST", "/signup", []int{201}, CRUDCreate},
+		{"POST", "/search", []int{200}, ""},
+		{"POST", "/users/{userId}/activate", []int{200}, ""},
+		{"PUT", "/users/{userId}", []int{200}, CRUDReplace},
+		{"PATCH", "/users/{userId}", []int{200}, CRUDUpdate},
+		{"DELETE", "/users/{userId}", []int{204}, CRUDDelete},
+		{"DELETE", "/users", []int{204}, ""},
+		{"GET", "/status", []int{200}, CRUDGet},
+		{"POST", "/ws/stock#GetQuote", []int{200}, ""},
+	}
+	for _, tt := range tests {
+		if got := ClassifyCRUD(tt.method, tt.template, tt.statuses); got != tt.want {
+			t.Errorf("ClassifyCRUD(%s %s) = %q, want %q", tt.method, tt.template, got, tt.want)
+		}
+	}
+
+	if got := CRUDResource(CRUDList, "/v1/users/{userId}/posts"); !slices.Equal(got, []string{"user", "posts"}) {
+		t.Errorf("CRUDResource(list) = %v", got)
+	}
+	if got := CRUDResource(CRUDCreate, "/users/{userId}/posts"); !slices.Equal(got, []string{"user", "post"}) {
+		t.Errorf("CRUDResource(create) = %v", got)
+	}
+
+	result := InferFromRecords([]ir.IRRecord{
+		{Request: ir.Request{Method: ir.RequestMethodPOST, Path: "/users"}, Response: ir.Response{Status: 201}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users/42"}, Response: ir.Response{Status: 200}},
+	})
+	if got := result.Endpoints["POST /users"].Action; got != CRUDCreate {
+		t.Errorf("POST /users action = %q", got)
+	}
+	if got := result.Endpoints["GET /users/{userId}"].Action; got != CRUDGet {
+		t.Errorf("GET /users/{userId} action = %q", got)
+	}
+}
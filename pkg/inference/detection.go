@@ -9,6 +9,7 @@ import (
 // SecurityDetector detects authentication schemes from request headers.
 type SecurityDetector struct {
 	schemes map[string]*DetectedSecurityScheme
+	rules   []SecurityRule // custom credentials, checked before the built-in ones
 }
 
 // DetectedSecurityScheme represents a detected security scheme.
@@ -18,6 +19,7 @@ type DetectedSecurityScheme struct {
 	Name         string // header name (for apiKey type)
 	In           string // header, query, cookie
 	BearerFormat string // JWT, etc.
+	Description  string // from the SecurityRule that detected it, if any
 	Count        int    // number of times observed
 }
 
@@ -31,28 +33,44 @@ func NewSecurityDetector() *SecurityDetector {
 // DetectFromHeaders analyzes request headers for security schemes.
 func (d *SecurityDetector) DetectFromHeaders(headers map[string]string) {
 	for name, value := range headers {
-		nameLower := strings.ToLower(name)
-
-		switch nameLower {
-		case "authorization":
-			d.detectAuthorizationHeader(value)
-		case "x-api-key", "api-key", "apikey":
-			d.addScheme("apiKeyHeader", &DetectedSecurityScheme{
-				Type: "apiKey",
-				Name: name,
-				In:   "header",
-			})
-		case "x-auth-token", "x-access-token":
-			d.addScheme("tokenHeader", &DetectedSecurityScheme{
-				Type: "apiKey",
-				Name: name,
-				In:   "header",
-			})
+		if key, scheme, ok := d.HeaderScheme(name, value); ok {
+			d.addScheme(key, scheme)
+		}
+		if len(d.rules) > 0 && strings.EqualFold(name, "Cookie") {
+			d.detectFromCookies(value)
 		}
 	}
 }
 
-func (d *SecurityDetector) detectAuthorizationHeader(value string) {
+// SecurityHeaderScheme returns the security scheme a request header
+// authenticates with, and its key in InferenceResult.SecuritySchemes. It is
+// the one place deciding which headers are credentials: those are
+// documented by their scheme, never as header parameters. An Authorization
+// header of an unrecognized scheme is not matched, so it stays documented.
+// SecurityDetector.HeaderScheme also applies custom SecurityRules.
+func SecurityHeaderScheme(name, value string) (string, *DetectedSecurityScheme, bool) {
+	switch strings.ToLower(name) {
+	case "authorization":
+		return authorizationScheme(value)
+	case "x-api-key", "api-key", "apikey":
+		return "apiKeyHeader", &DetectedSecurityScheme{
+			Type: "apiKey",
+			Name: name,
+			In:   "header",
+		}, true
+	case "x-auth-token", "x-access-token":
+		return "tokenHeader", &DetectedSecurityScheme{
+			Type: "apiKey",
+			Name: name,
+			In:   "header",
+		}, true
+	}
+	return "", nil, false
+}
+
+// authorizationScheme returns the security scheme of an Authorization
+// header value.
+func authorizationScheme(value string) (string, *DetectedSecurityScheme, bool) {
 	valueLower := strings.ToLower(value)
 
 	if strings.HasPrefix(valueLower, "bearer ") {
@@ -69,18 +87,19 @@ func (d *SecurityDetector) detectAuthorizationHeader(value string) {
 			scheme.BearerFormat = "JWT"
 		}
 
-		d.addScheme("bearerAuth", scheme)
+		return "bearerAuth", scheme, true
 	} else if strings.HasPrefix(valueLower, "basic ") {
-		d.addScheme("basicAuth", &DetectedSecurityScheme{
+		return "basicAuth", &DetectedSecurityScheme{
 			Type:   "http",
 			Scheme: "basic",
-		})
+		}, true
 	} else if strings.HasPrefix(valueLower, "digest ") {
-		d.addScheme("digestAuth", &DetectedSecurityScheme{
+		return "digestAuth", &DetectedSecurityScheme{
 			Type:   "http",
 			Scheme: "digest",
-		})
+		}, true
 	}
+	return "", nil, false
 }
 
 func (d *SecurityDetector) addScheme(key string, scheme *DetectedSecurityScheme) {
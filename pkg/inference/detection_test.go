@@ -0,0 +1,50 @@
+package inference
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestSecurityHeadersNotParams(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/a", Headers: map[string]string{
+				"X-API-Key": "k1", "Authorization": "Bearer abc", "X-Tenant": "t",
+			}},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			// Authorization of an unrecognized scheme stays a parameter
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/b", Headers: map[string]string{"Authorization": "Token abc"}},
+			Response: ir.Response{Status: 200},
+		},
+	}
+	for _, mode := range []HeaderMode{HeaderModeBlacklist, HeaderModeAllowlist} {
+		opts := DefaultEngineOptions()
+		opts.HeaderMode = mode
+		opts.AllowedHeaders = []string{"x-api-key", "authorization", "x-tenant"}
+		engine := NewEngine(opts)
+		engine.ProcessRecords(records)
+		result := engine.Finalize()
+
+		if _, ok := result.SecuritySchemes["apiKeyHeader"]; !ok {
+			t.Fatalf("mode %v: apiKeyHeader scheme not detected", mode)
+		}
+		var names []string
+		for name := range result.Endpoints["GET /a"].HeaderParams {
+			names = append(names, name)
+		}
+		if !reflect.DeepEqual(names, []string{"X-Tenant"}) {
+			t.Errorf("mode %v: GET /a header params = %v, want [X-Tenant]", mode, names)
+		}
+		if _, ok := result.Endpoints["GET /b"].HeaderParams["Authorization"]; !ok {
+			t.Errorf("mode %v: unrecognized Authorization scheme dropped", mode)
+		}
+	}
+
+	if key, _, ok := SecurityHeaderScheme("x-access-token", "t"); !ok || key != "tokenHeader" {
+		t.Errorf("SecurityHeaderScheme(x-access-token) = %q, %v", key, ok)
+	}
+}
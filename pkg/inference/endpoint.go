@@ -1,8 +1,13 @@
 package inference
 
 import (
+	"hash/fnv"
+	"net/http"
+	"slices"
 	"strings"
 	"sync"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
 )
 
 // RecordDocumentation holds documentation fields from an IR record.
@@ -15,64 +20,282 @@ type RecordDocumentation struct {
 	ExternalDocs *ExternalDocsData
 }
 
+// endpointShardCount is the number of independently locked endpoint shards.
+const endpointShardCount = 32
+
+// endpointShard holds the endpoints whose keys hash to it.
+type endpointShard struct {
+	mu        sync.Mutex
+	endpoints map[string]*EndpointData
+}
+
 // EndpointClusterer groups IR records by endpoint (method + path template).
+// It is safe for concurrent use: endpoint state is sharded by endpoint key with
+// per-shard locks, and shared state (hosts, schemes, detectors) has its own lock.
 type EndpointClusterer struct {
 	mu                 sync.RWMutex
+	shards             [endpointShardCount]endpointShard
 	pathInferrer       *PathInferrer
-	endpoints          map[string]*EndpointData
+	templates          *TemplateTrie
 	hosts              map[string]bool
 	schemes            map[string]bool
 	securityDetector   *SecurityDetector
 	paginationDetector *PaginationDetector
 	rateLimitDetector  *RateLimitDetector
+	errorCodeDetector  *ErrorCodeDetector
+	corsDetector       *CORSDetector
+	asyncDetector      *AsyncDetector
+	linkDetector       *LinkDetector // nil unless link detection is enabled
+	pathUnifier        *PathUnifier  // nil unless path unification is enabled
+	headerMode         HeaderMode
+	allowedHeaders     map[string]bool
+	exampleMemory      *ExampleMemory
+	examplePairs       int      // transactions kept per endpoint and status
+	invalidExamples    int      // rejected transactions kept per endpoint
+	queryAliases       bool     // merge equivalent query parameter spellings
+	commaLists         []string // query parameters sent as comma-separated lists
 }
 
 // NewEndpointClusterer creates a new EndpointClusterer.
 func NewEndpointClusterer() *EndpointClusterer {
-	return &EndpointClusterer{
+	c := &EndpointClusterer{
 		pathInferrer:       NewPathInferrer(),
-		endpoints:          make(map[string]*EndpointData),
+		templates:          NewTemplateTrie(),
 		hosts:              make(map[string]bool),
 		schemes:            make(map[string]bool),
 		securityDetector:   NewSecurityDetector(),
 		paginationDetector: NewPaginationDetector(),
 		rateLimitDetector:  NewRateLimitDetector(),
+		errorCodeDetector:  NewErrorCodeDetector(),
+		corsDetector:       NewCORSDetector(),
+		asyncDetector:      NewAsyncDetector(),
 	}
+	for i := range c.shards {
+		c.shards[i].endpoints = make(map[string]*EndpointData)
+	}
+	// Inferred templates are matched with the clusterer's own segment options
+	c.templates.inferrer = c.pathInferrer
+	return c
 }
 
-// AddRecord processes an IR record and adds it to the appropriate endpoint.
-func (c *EndpointClusterer) AddRecord(method, path string, pathTemplate string, pathParams map[string]string,
-	query map[string]any, headers map[string]string, requestBody any, requestContentType string,
-	status int, responseBody any, responseContentType string, responseHeaders map[string]string,
-	host string, scheme string, docs *RecordDocumentation) {
+// SetPathSegmentOptions sets the locale and tenant path segments documented
+// as enumerated parameters. It must be called before records are added.
+func (c *EndpointClusterer) SetPathSegmentOptions(opts PathSegmentOptions) {
+	c.pathInferrer.SetSegmentOptions(opts)
+}
+
+// shard returns the shard holding an endpoint key.
+func (c *EndpointClusterer) shard(key string) *endpointShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &c.shards[h.Sum32()%endpointShardCount]
+}
+
+// lockAll locks every shard in order; unlockAll releases them.
+func (c *EndpointClusterer) lockAll() {
+	for i := range c.shards {
+		c.shards[i].mu.Lock()
+	}
+}
+
+func (c *EndpointClusterer) unlockAll() {
+	for i := range c.shards {
+		c.shards[i].mu.Unlock()
+	}
+}
+
+// SetHeaderFilter configures which request headers are recorded as parameters.
+// It should be called before records are added.
+func (c *EndpointClusterer) SetHeaderFilter(mode HeaderMode, allowed []string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Track host and scheme
+	c.headerMode = mode
+	c.allowedHeaders = make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		c.allowedHeaders[strings.ToLower(name)] = true
+	}
+}
+
+// Templates returns the trie of path templates known to the clusterer.
+// It can be shared with subsystems that match concrete paths to endpoints.
+func (c *EndpointClusterer) Templates() *TemplateTrie {
+	return c.templates
+}
+
+// AddTemplates seeds the clusterer with known path templates (e.g., from an
+// existing spec) so matching records are assigned to them.
+func (c *EndpointClusterer) AddTemplates(templates ...string) {
+	for _, template := range templates {
+		c.templates.Add(template)
+	}
+}
+
+// EnableLinkDetection turns on detection of response fields reused as path
+// parameters (see LinkDetector), requiring minValues distinct matching values
+// per link. It must be called before records are added.
+func (c *EndpointClusterer) EnableLinkDetection(minValues int) {
+	c.linkDetector = NewLinkDetector(minValues)
+}
+
+// SetPathUnification merges path templates that differ only in letter case
+// or a trailing slash into one endpoint (see PathUnifier). It must be called
+// before records are added.
+func (c *EndpointClusterer) SetPathUnification(caseInsensitive, trailingSlash bool) {
+	if caseInsensitive || trailingSlash {
+		c.pathUnifier = NewPathUnifier(caseInsensitive, trailingSlash)
+	}
+}
+
+// SetExamplePairs sets how many transactions are kept per endpoint and
+// status as EndpointData.ExamplePairs (0 disables). It must be called
+// before records are added.
+func (c *EndpointClusterer) SetExamplePairs(n int) {
+	c.examplePairs = n
+}
+
+// SetExampleMemory bounds the memory used by body examples of all endpoints.
+// It should be called before records are added.
+func (c *EndpointClusterer) SetExampleMemory(m *ExampleMemory) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.exampleMemory = m
+}
+
+// includeHeaderParam checks if a request header should be recorded as a
+// parameter. Credentials (see SecurityDetector.HeaderScheme) never are, in
+// any header mode, since their security scheme documents them.
+func (c *EndpointClusterer) includeHeaderParam(name, value string) bool {
+	if _, _, ok := c.securityDetector.HeaderScheme(name, value); ok {
+		return false
+	}
+	switch c.headerMode {
+	case HeaderModeNone:
+		return false
+	case HeaderModeAllowlist:
+		return c.allowedHeaders[strings.ToLower(name)]
+	default:
+		return !isExcludedRequestHeader(name)
+	}
+}
+
+// resolveTemplate returns the path template of a request, its path
+// parameters, and the names of its matrix parameters: the record's own
+// template if it has one, else a known template matching the path, else one
+// inferred from it. Matrix parameters (/items;color=red) are split from the
+// path first and added to the template as /items{color}.
+func (c *EndpointClusterer) resolveTemplate(path, pathTemplate string, pathParams map[string]string) (string, map[string]string, map[string]bool) {
+	// Match known templates before inferring, so records resolve consistently
+	if pathTemplate != "" {
+		c.templates.Add(pathTemplate)
+		return pathTemplate, pathParams, nil
+	}
+	path, matrix := splitMatrixParams(path)
+	template, params, matched := c.templates.Match(path)
+	if !matched {
+		template, params = c.pathInferrer.InferTemplate(path)
+		c.templates.AddInferred(template)
+	}
+	if len(matrix) == 0 {
+		return template, params, nil
+	}
+	template, names := addMatrixParams(template, params, matrix)
+	return template, params, names
+}
+
+// AddRecord processes an IR record and adds it to the appropriate endpoint.
+// It returns the endpoint the record was assigned to.
+func (c *EndpointClusterer) AddRecord(method, path string, pathTemplate string, pathParams map[string]string,
+	query map[string]any, headers map[string]string, headerValues map[string][]string, requestBody any, requestContentType string,
+	status int, responseBody any, responseContentType string, responseHeaders map[string]string, responseHeaderValues map[string][]string,
+	host string, scheme string, docs *RecordDocumentation) *EndpointData {
+	// Update shared state first, so the shard lock is never held while
+	// waiting on the shared lock
+	c.mu.Lock()
 	if host != "" {
 		c.hosts[host] = true
 	}
 	if scheme != "" {
 		c.schemes[scheme] = true
 	}
+	c.securityDetector.DetectFromHeaders(headers)
+	c.securityDetector.DetectFromQuery(query)
+	c.paginationDetector.DetectFromQuery(query)
+	if status > 0 {
+		c.rateLimitDetector.DetectFromHeaders(responseHeaders)
+	}
+	c.mu.Unlock()
 
-	// Infer path template if not provided
-	var inferredParams map[string]string
-	if pathTemplate == "" {
-		pathTemplate, inferredParams = c.pathInferrer.InferTemplate(path)
-	} else {
-		inferredParams = pathParams
+	pathTemplate, inferredParams, matrixParams := c.resolveTemplate(path, pathTemplate, pathParams)
+	if c.pathUnifier != nil {
+		c.mu.Lock()
+		pathTemplate = c.pathUnifier.Unify(path, pathTemplate)
+		c.mu.Unlock()
+	}
+	key := EndpointKey(method, pathTemplate)
+	if status >= 400 {
+		c.mu.Lock()
+		c.errorCodeDetector.DetectFromResponse(key, status, responseBody)
+		c.mu.Unlock()
+	}
+	if hasCORSHeaders(responseHeaders) {
+		c.mu.Lock()
+		c.corsDetector.DetectFromResponse(host, key, responseHeaders)
+		c.mu.Unlock()
+	}
+	if status == http.StatusAccepted || method == http.MethodGet {
+		c.mu.Lock()
+		c.asyncDetector.DetectFromRecord(key, method, path, status, responseHeaders)
+		c.mu.Unlock()
+	}
+	if c.linkDetector != nil {
+		var values map[string][]string
+		if status >= 200 && status < 300 {
+			values = BodyValues(responseBody)
+		}
+		c.mu.Lock()
+		c.linkDetector.DetectFromRecord(key, inferredParams, status, values)
+		c.mu.Unlock()
 	}
 
 	// Get or create endpoint
-	key := EndpointKey(method, pathTemplate)
-	endpoint, exists := c.endpoints[key]
+	shard := c.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	endpoint, exists := shard.endpoints[key]
 	if !exists {
 		endpoint = NewEndpointData(method, pathTemplate)
-		c.endpoints[key] = endpoint
+		shard.endpoints[key] = endpoint
 	}
 
 	endpoint.RequestCount++
+	if c.examplePairs > 0 && (requestBody != nil || responseBody != nil) {
+		endpoint.addExamplePair(c.examplePairs, &ExamplePair{
+			Status:              status,
+			RequestBody:         requestBody,
+			RequestContentType:  requestContentType,
+			ResponseBody:        responseBody,
+			ResponseContentType: responseContentType,
+		})
+	}
+	if c.invalidExamples > 0 && requestBody != nil && IsInvalidRequestStatus(status) {
+		endpoint.addInvalidExample(c.invalidExamples, &ExamplePair{
+			Status:              status,
+			RequestBody:         requestBody,
+			RequestContentType:  requestContentType,
+			ResponseBody:        responseBody,
+			ResponseContentType: responseContentType,
+		})
+	}
+	if isConditionalRequest(headers, status) {
+		endpoint.ConditionalRequests = true
+	}
+	endpoint.addPreconditions(method, headers, status)
+	if host != "" && !slices.Contains(endpoint.Hosts, host) {
+		endpoint.Hosts = append(endpoint.Hosts, host)
+	}
 
 	// Merge documentation (first non-empty value wins)
 	if docs != nil {
@@ -102,32 +325,49 @@ func (c *EndpointClusterer) AddRecord(method, path string, pathTemplate string,
 		if !exists {
 			param = NewParamData(name)
 			param.Required = true // Path params are always required
+			if matrixParams[name] {
+				param.Style = PathStyleMatrix
+			}
 			endpoint.PathParams[name] = param
 		}
 		param.AddValue(value)
+		if param.Stats == nil {
+			param.Stats = &ParamStats{}
+		}
+		param.Stats.AddValue(value)
+		if c.pathInferrer.EnumeratedParam(pathTemplate, name) {
+			param.AddEnumValue(value)
+		}
 	}
 
-	// Process query parameters
-	for name, value := range query {
+	// Process query parameters (arrays and deepObject params are grouped by base name)
+	queryObs := NormalizeQuery(query, c.commaLists...)
+	if c.queryAliases {
+		queryObs = unifyQueryAliases(endpoint.QueryParams, queryObs)
+	}
+	for name, obs := range queryObs {
+		if c.securityDetector.IsQueryCredential(name) {
+			continue
+		}
 		param, exists := endpoint.QueryParams[name]
 		if !exists {
 			param = NewParamData(name)
 			param.Required = false // Query params start as optional
 			endpoint.QueryParams[name] = param
 		}
-		param.AddValue(value)
+		addQueryObservation(param, obs)
 	}
 
 	// Update query param optionality
 	for name, param := range endpoint.QueryParams {
-		if _, inThisRequest := query[name]; !inThisRequest {
+		if _, inThisRequest := queryObs[name]; !inThisRequest {
 			param.Required = false
 		}
 	}
 
-	// Process header parameters (exclude common headers)
+	// Process header parameters (filtered by header mode)
 	for name, value := range headers {
-		if isExcludedHeader(name) {
+		if !c.includeHeaderParam(name, value) {
 			continue
 		}
 		param, exists := endpoint.HeaderParams[name]
@@ -136,25 +376,37 @@ func (c *EndpointClusterer) AddRecord(method, path string, pathTemplate string,
 			param.Required = false
 			endpoint.HeaderParams[name] = param
 		}
-		param.AddValue(value)
+		addHeaderObservation(param, value, headerValues[name])
 	}
 
-	// Detect security schemes from request headers
-	c.securityDetector.DetectFromHeaders(headers)
-
-	// Detect pagination patterns from query parameters
-	c.paginationDetector.DetectFromQuery(query)
-
-	// Process request body
-	if requestBody != nil {
+	// Process request body (empty bodies count as absent). Binary bodies are
+	// usually left out of the IR, so a binary content type counts as a body
+	// and is not inferred from.
+	binaryRequest := ir.IsBinaryContentType(requestContentType)
+	if (requestBody != nil && requestBody != "") || binaryRequest {
 		if endpoint.RequestBody == nil {
 			ct := requestContentType
 			if ct == "" {
 				ct = "application/json"
 			}
 			endpoint.RequestBody = NewBodyData(ct)
+			if c.exampleMemory != nil {
+				endpoint.RequestBody.Schema.SetExampleMemory(c.exampleMemory)
+			}
+		}
+		endpoint.RequestBody.Count++
+		if requestContentType != "" {
+			endpoint.RequestBody.ContentTypes[requestContentType]++
+		}
+		// Raw multipart bodies are split into text fields and files; those
+		// that don't parse are counted but not inferred from
+		_, raw := requestBody.(string)
+		if upload, ok := parseMultipartBody(requestContentType, requestBody); ok {
+			endpoint.RequestBody.addMultipart(upload)
+			ProcessBody(endpoint.RequestBody.Schema, upload.fields)
+		} else if !binaryRequest && !(raw && IsMultipartFormData(requestContentType)) {
+			ProcessBody(endpoint.RequestBody.Schema, requestBody)
 		}
-		ProcessBody(endpoint.RequestBody.Schema, requestBody)
 	}
 
 	// Process response
@@ -162,6 +414,9 @@ func (c *EndpointClusterer) AddRecord(method, path string, pathTemplate string,
 		resp, exists := endpoint.Responses[status]
 		if !exists {
 			resp = NewResponseData(status)
+			if c.exampleMemory != nil {
+				resp.Body.SetExampleMemory(c.exampleMemory)
+			}
 			if responseContentType != "" {
 				resp.ContentType = responseContentType
 			} else {
@@ -170,14 +425,28 @@ func (c *EndpointClusterer) AddRecord(method, path string, pathTemplate string,
 			endpoint.Responses[status] = resp
 		}
 
-		// Process response body
-		if responseBody != nil {
-			ProcessBody(resp.Body, responseBody)
+		// Process response body (binary bodies and downloads are counted but
+		// not inferred from)
+		filename, download := attachmentFilename(contentDisposition(responseHeaders))
+		if download {
+			resp.addDownload(filename)
+		}
+		binaryResponse := ir.IsBinaryContentType(responseContentType) || download
+		if responseBody != nil || binaryResponse {
+			if responseContentType != "" {
+				resp.ContentTypes[responseContentType]++
+			}
+			if !binaryResponse {
+				ProcessBody(resp.Body, responseBody)
+				if status == http.StatusMultiStatus {
+					resp.addMultiStatus(responseBody)
+				}
+			}
 		}
 
 		// Process response headers
 		for name, value := range responseHeaders {
-			if isExcludedHeader(name) {
+			if isExcludedResponseHeader(name) {
 				continue
 			}
 			param, exists := resp.Headers[name]
@@ -185,42 +454,140 @@ func (c *EndpointClusterer) AddRecord(method, path string, pathTemplate string,
 				param = NewParamData(name)
 				resp.Headers[name] = param
 			}
-			param.AddValue(value)
+			addHeaderObservation(param, value, responseHeaderValues[name])
 		}
+	}
 
-		// Detect rate limit headers from response
-		c.rateLimitDetector.DetectFromHeaders(responseHeaders)
+	return endpoint
+}
+
+// addMetadata records a request's metadata on the endpoint it was assigned to.
+func (c *EndpointClusterer) addMetadata(endpoint *EndpointData, metadata map[string]any) {
+	shard := c.shard(EndpointKey(endpoint.Method, endpoint.PathTemplate))
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	endpoint.AddMetadata(metadata)
+}
+
+// markNoise records that a request assigned to endpoint was flagged as noise.
+func (c *EndpointClusterer) markNoise(endpoint *EndpointData) {
+	shard := c.shard(EndpointKey(endpoint.Method, endpoint.PathTemplate))
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	endpoint.NoiseCount++
+}
+
+// maxEarlyHints limits the Link values recorded from 103 Early Hints.
+const maxEarlyHints = 10
+
+// addEarlyHints records the Link values of a request's 103 Early Hints
+// responses on the endpoint it was assigned to.
+func (c *EndpointClusterer) addEarlyHints(endpoint *EndpointData, links []string) {
+	shard := c.shard(EndpointKey(endpoint.Method, endpoint.PathTemplate))
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	for _, link := range links {
+		if len(endpoint.EarlyHints) < maxEarlyHints && !slices.Contains(endpoint.EarlyHints, link) {
+			endpoint.EarlyHints = append(endpoint.EarlyHints, link)
+		}
 	}
 }
 
+// addQueryObservation records a normalized query observation on a parameter.
+func addQueryObservation(param *ParamData, obs *QueryObservation) {
+	switch obs.Style {
+	case QueryStyleForm:
+		param.AddArrayValue(obs.Values, obs.Explode)
+	case QueryStyleDeepObject:
+		param.AddObjectValue(obs.Properties)
+	default:
+		// A parameter previously seen as an array stays an array
+		if param.Type == TypeArray {
+			explode := param.Explode == nil || *param.Explode
+			param.AddArrayValue([]any{obs.Value}, explode)
+		} else if !addQueryExpression(param, obs.Value) {
+			param.AddValue(obs.Value)
+		}
+	}
+}
+
+// addHeaderObservation adds a header's value, or all of its values when it
+// occurred more than once. Repeated headers are documented as arrays in the
+// default simple style, and stay arrays once seen repeated.
+func addHeaderObservation(param *ParamData, value string, values []string) {
+	if len(values) < 2 && param.Type != TypeArray {
+		param.AddValue(value)
+		return
+	}
+	if len(values) == 0 {
+		values = []string{value}
+	}
+	items := make([]any, len(values))
+	for i, v := range values {
+		items[i] = v
+	}
+	param.AddArrayValue(items, false)
+	param.Style, param.Explode = "", nil
+}
+
 // Finalize completes the inference process (e.g., marking optional fields).
 func (c *EndpointClusterer) Finalize() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.lockAll()
+	defer c.unlockAll()
 
-	for _, endpoint := range c.endpoints {
-		// Finalize request body schema
-		if endpoint.RequestBody != nil {
-			endpoint.RequestBody.Schema.FinalizeOptional()
+	for i := range c.shards {
+		for _, endpoint := range c.shards[i].endpoints {
+			finalizeEndpoint(endpoint)
 		}
+	}
+}
 
-		// Finalize response schemas
-		for _, resp := range endpoint.Responses {
-			resp.Body.FinalizeOptional()
-		}
+// finalizeEndpoint marks optional fields in an endpoint's body schemas and
+// whether its request body is required.
+func finalizeEndpoint(endpoint *EndpointData) {
+	if endpoint.RequestBody != nil {
+		endpoint.RequestBody.Schema.FinalizeOptional()
+		endpoint.RequestBody.Required = endpoint.RequestBody.Count >= endpoint.RequestCount
+	}
+	for _, resp := range endpoint.Responses {
+		resp.Body.FinalizeOptional()
 	}
 }
 
-// GetResult returns the inference result.
+// GetResult returns the inference result. Endpoints are shared with the
+// clusterer; use Snapshot for a result that is safe to use during ingestion.
 func (c *EndpointClusterer) GetResult() *InferenceResult {
+	return c.result(false)
+}
+
+// Snapshot returns a finalized inference result built from copies of the
+// current endpoint state, so records can continue to be added concurrently.
+func (c *EndpointClusterer) Snapshot() *InferenceResult {
+	return c.result(true)
+}
+
+func (c *EndpointClusterer) result(snapshot bool) *InferenceResult {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	result := NewInferenceResult()
 
-	// Copy endpoints
-	for key, endpoint := range c.endpoints {
-		result.Endpoints[key] = endpoint
+	// Copy endpoints; all shards are locked for a consistent view
+	c.lockAll()
+	for i := range c.shards {
+		for key, endpoint := range c.shards[i].endpoints {
+			if snapshot {
+				endpoint = endpoint.clone()
+			}
+			result.Endpoints[key] = endpoint
+		}
+	}
+	c.unlockAll()
+
+	if snapshot {
+		for _, endpoint := range result.Endpoints {
+			finalizeEndpoint(endpoint)
+		}
 	}
 
 	// Collect hosts
@@ -235,19 +602,42 @@ func (c *EndpointClusterer) GetResult() *InferenceResult {
 
 	// Copy detected security schemes
 	for key, scheme := range c.securityDetector.GetSchemes() {
+		if snapshot {
+			copied := *scheme
+			scheme = &copied
+		}
 		result.SecuritySchemes[key] = scheme
 	}
 
 	// Copy detected pagination parameters
 	for key, param := range c.paginationDetector.GetParams() {
+		if snapshot {
+			copied := *param
+			copied.Examples = append([]string(nil), param.Examples...)
+			param = &copied
+		}
 		result.PaginationParams[key] = param
 	}
 
 	// Copy detected rate limit headers
 	for key, header := range c.rateLimitDetector.GetHeaders() {
+		if snapshot {
+			copied := *header
+			header = &copied
+		}
 		result.RateLimitHeaders[key] = header
 	}
 
+	result.ErrorCodes = c.errorCodeDetector.GetCodes()
+	result.CORS = c.corsDetector.GetPolicies()
+	result.AsyncOperations = c.asyncDetector.GetOperations()
+	if c.linkDetector != nil {
+		result.Links = c.linkDetector.GetLinks()
+	}
+	if c.pathUnifier != nil {
+		result.PathVariants = c.pathUnifier.Variants()
+	}
+
 	return result
 }
 
@@ -0,0 +1,144 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestHeaderModes(t *testing.T) {
+	newRecord := func(headers map[string]string) ir.IRRecord {
+		return ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items", Headers: headers},
+			Response: ir.Response{Status: 200},
+		}
+	}
+	records := []ir.IRRecord{
+		newRecord(map[string]string{"x-tenant": "a", "x-client-build": "123"}),
+		newRecord(map[string]string{"x-tenant": "b"}),
+	}
+
+	tests := []struct {
+		name     string
+		mode     HeaderMode
+		allowed  []string
+		minCount int
+		want     []string
+	}{
+		{"blacklist", HeaderModeBlacklist, nil, 1, []string{"x-client-build", "x-tenant"}},
+		{"allowlist", HeaderModeAllowlist, []string{"X-Tenant"}, 1, []string{"x-tenant"}},
+		{"none", HeaderModeNone, nil, 1, nil},
+		{"threshold", HeaderModeBlacklist, nil, 2, []string{"x-tenant"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultEngineOptions()
+			opts.HeaderMode = tt.mode
+			opts.AllowedHeaders = tt.allowed
+			opts.MinHeaderObservations = tt.minCount
+
+			engine := NewEngine(opts)
+			engine.ProcessRecords(records)
+			endpoint := engine.Finalize().Endpoints["GET /items"]
+
+			if len(endpoint.HeaderParams) != len(tt.want) {
+				t.Fatalf("expected headers %v, got %v", tt.want, endpoint.HeaderParams)
+			}
+			for _, name := range tt.want {
+				if _, ok := endpoint.HeaderParams[name]; !ok {
+					t.Errorf("expected header %q to be documented", name)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiValueHeaders(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{
+				Method:       ir.RequestMethodGET,
+				Path:         "/items",
+				Headers:      map[string]string{"x-forwarded-tag": "a"},
+				HeaderValues: map[string][]string{"x-forwarded-tag": {"a", "b"}},
+			},
+			Response: ir.Response{
+				Status:       200,
+				Headers:      map[string]string{"link": "</items?page=2>; rel=\"next\"", "x-request-cost": "3"},
+				HeaderValues: map[string][]string{"link": {"</items?page=2>; rel=\"next\"", "</items?page=9>; rel=\"last\""}},
+			},
+		},
+		{
+			Request: ir.Request{
+				Method:  ir.RequestMethodGET,
+				Path:    "/items",
+				Headers: map[string]string{"x-forwarded-tag": "c"},
+			},
+			Response: ir.Response{Status: 200},
+		},
+	}
+
+	engine := NewEngine(DefaultEngineOptions())
+	engine.ProcessRecords(records)
+	endpoint := engine.Finalize().Endpoints["GET /items"]
+
+	tag := endpoint.HeaderParams["x-forwarded-tag"]
+	if tag == nil || tag.Type != TypeArray || tag.Items == nil {
+		t.Fatalf("expected repeated request header to be an array, got %+v", tag)
+	}
+	if tag.Style != "" || tag.Explode != nil {
+		t.Errorf("expected the default header style, got style %q explode %v", tag.Style, tag.Explode)
+	}
+	if len(tag.Items.Examples) != 3 {
+		t.Errorf("expected item examples from both records, got %v", tag.Items.Examples)
+	}
+
+	headers := endpoint.Responses[200].Headers
+	if link := headers["link"]; link == nil || link.Type != TypeArray {
+		t.Errorf("expected repeated response header to be an array, got %+v", link)
+	}
+	if cost := headers["x-request-cost"]; cost == nil || cost.Type == TypeArray {
+		t.Errorf("expected single-valued response header to stay scalar, got %+v", cost)
+	}
+}
+
+func TestRequestBodyRequired(t *testing.T) {
+	body := func(method ir.RequestMethod, path string, b any) ir.IRRecord {
+		return ir.IRRecord{
+			Request:  ir.Request{Method: method, Path: path, Body: b},
+			Response: ir.Response{Status: 200},
+		}
+	}
+	records := []ir.IRRecord{
+		body(ir.RequestMethodPOST, "/users", map[string]any{"name": "Ada"}),
+		body(ir.RequestMethodPOST, "/users", map[string]any{"name": "Grace"}),
+		body(ir.RequestMethodPATCH, "/users", map[string]any{"name": "Ada"}),
+		body(ir.RequestMethodPATCH, "/users", ""),
+		body(ir.RequestMethodPATCH, "/users", nil),
+		body(ir.RequestMethodDELETE, "/users", map[string]any{"reason": "spam"}),
+	}
+
+	result := InferFromRecords(records)
+	if rb := result.Endpoints["POST /users"].RequestBody; rb == nil || !rb.Required {
+		t.Errorf("expected POST body always sent to be required, got %+v", rb)
+	}
+	if rb := result.Endpoints["PATCH /users"].RequestBody; rb == nil || rb.Required || rb.Count != 1 {
+		t.Errorf("expected PATCH body sometimes omitted to be optional, got %+v", rb)
+	}
+	if result.Endpoints["DELETE /users"].RequestBody == nil {
+		t.Error("expected DELETE body to be kept by default")
+	}
+
+	opts := DefaultEngineOptions()
+	opts.IgnoreGetDeleteBodies = true
+	engine := NewEngine(opts)
+	engine.ProcessRecords(records)
+	result = engine.Finalize()
+	if rb := result.Endpoints["DELETE /users"].RequestBody; rb != nil {
+		t.Errorf("expected DELETE body to be ignored, got %+v", rb)
+	}
+	if result.Endpoints["POST /users"].RequestBody == nil {
+		t.Error("expected POST body to be kept")
+	}
+}
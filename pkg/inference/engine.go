@@ -2,15 +2,32 @@ package inference
 
 import (
 	"io"
+	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/grokify/traffic2openapi/pkg/ir"
 )
 
+const (
+	// FilterReasonNonAPI is the FilteredRecords reason for static asset and page traffic.
+	FilterReasonNonAPI = "non-api"
+
+	// FilterReasonMetadata is the FilteredRecords reason for records not
+	// matching EngineOptions.MetadataFilter.
+	FilterReasonMetadata = "metadata"
+)
+
 // Engine orchestrates the inference process.
+// ProcessRecord and Snapshot are safe for concurrent use.
 type Engine struct {
-	clusterer   *EndpointClusterer
-	options     EngineOptions
-	apiMetadata *APIMetadataData
+	mu            sync.Mutex // guards apiMetadata, noiseDetector, and filtered
+	clusterer     *EndpointClusterer
+	options       EngineOptions
+	apiMetadata   *APIMetadataData
+	noiseDetector *NoiseDetector
+	filtered      map[string]int // filter reason -> dropped record count
+	exampleMemory *ExampleMemory
 }
 
 // EngineOptions configures the inference engine.
@@ -26,26 +43,194 @@ type EngineOptions struct {
 
 	// SkipEmptyBodies skips recording empty request/response bodies
 	SkipEmptyBodies bool
+
+	// IgnoreGetDeleteBodies ignores request bodies on GET, HEAD, DELETE, and
+	// OPTIONS requests, for which HTTP defines no body semantics.
+	IgnoreGetDeleteBodies bool
+
+	// HeaderMode controls which request headers become documented parameters
+	// (default: HeaderModeBlacklist)
+	HeaderMode HeaderMode
+
+	// AllowedHeaders lists the headers to document in HeaderModeAllowlist (case-insensitive)
+	AllowedHeaders []string
+
+	// MinHeaderObservations is the minimum number of requests to an endpoint a header
+	// must appear in before it is documented as a parameter (default: 1)
+	MinHeaderObservations int
+
+	// RequiredQueryThreshold marks a query parameter as required when it appears in at
+	// least this fraction of an endpoint's requests (e.g., 0.99). 0 disables the check
+	// and leaves all query parameters optional.
+	RequiredQueryThreshold float64
+
+	// RequiredHeaderThreshold marks a header parameter as required when it appears in at
+	// least this fraction of an endpoint's requests. 0 disables the check.
+	RequiredHeaderThreshold float64
+
+	// MinRequestsPerEndpoint is the minimum number of requests an endpoint needs
+	// to be documented (default: 1). Endpoints below it are excluded, or flagged
+	// when FlagLowSampleEndpoints is set.
+	MinRequestsPerEndpoint int
+
+	// FlagLowSampleEndpoints keeps endpoints below MinRequestsPerEndpoint and marks
+	// them with EndpointData.LowSample instead of excluding them.
+	FlagLowSampleEndpoints bool
+
+	// PruneNotFoundEndpoints excludes endpoints whose only observed responses are
+	// 404 or 405 (typically scanner noise or typos). Excluded endpoints are listed
+	// in InferenceResult.Excluded.
+	PruneNotFoundEndpoints bool
+
+	// NoiseMode enables bot/scanner traffic filtering (default: NoiseModeOff)
+	NoiseMode NoiseMode
+
+	// NoiseEmptyAgent also treats runs of 404 responses to requests without
+	// a User-Agent as fuzzing (see NoiseDetector.FuzzEmptyAgent).
+	NoiseEmptyAgent bool
+
+	// APIOnly skips static assets and page loads (HTML, scripts, stylesheets,
+	// images, fonts, media) before inference.
+	APIOnly bool
+
+	// MetadataFilter skips records whose metadata doesn't have every key with
+	// the given value (e.g., {"environment": "staging"}).
+	MetadataFilter map[string]any
+
+	// MaxExampleStringLength truncates body string examples longer than this
+	// many bytes (default: DefaultMaxExampleStringLength). 0 disables truncation.
+	MaxExampleStringLength int
+
+	// ExampleMemoryBudget bounds the bytes held by body examples across all
+	// endpoints, evicting surplus examples when exceeded. 0 means unlimited.
+	ExampleMemoryBudget int64
+
+	// ExamplePairs keeps the request and response bodies of up to this many
+	// transactions per endpoint and status, reported in
+	// EndpointData.ExamplePairs. 0 disables pairing.
+	ExamplePairs int
+
+	// InvalidExamples keeps the request and response bodies of up to this
+	// many distinct requests per endpoint answered 400 or 422, reported in
+	// EndpointData.InvalidExamples. 0 disables them.
+	InvalidExamples int
+
+	// DetectLinks detects response fields whose values are later used as
+	// path parameters of another endpoint, reported in InferenceResult.Links.
+	DetectLinks bool
+
+	// LinkMinValues is the number of distinct values a response field must
+	// share with a path parameter before a link is reported
+	// (DefaultLinkMinValues if 0).
+	LinkMinValues int
+
+	// SecurityRules declares custom credential headers, query parameters,
+	// and cookies, detected as apiKey security schemes (see SecurityRule).
+	SecurityRules []SecurityRule
+
+	// Selection, if set, excludes the endpoints it doesn't keep. Excluded
+	// endpoints are listed in InferenceResult.Excluded.
+	Selection *EndpointSelection
+
+	// SOAPOperations models each SOAP action as its own endpoint, with
+	// "#<action>" appended to the path template (see SOAPOperation), rather
+	// than one POST endpoint per service URL. The action also becomes the
+	// operation ID unless the record sets one.
+	SOAPOperations bool
+
+	// CaseInsensitivePaths treats paths whose literal segments differ only
+	// in letter case (/Users, /users) as one endpoint, documented under the
+	// spelling seen first. Merged spellings are listed in
+	// InferenceResult.PathVariants.
+	CaseInsensitivePaths bool
+
+	// IgnoreTrailingSlash treats /users/ and /users as one endpoint. Inferred
+	// templates never keep a trailing slash; this also merges templates given
+	// by records, and lists merged spellings in InferenceResult.PathVariants.
+	IgnoreTrailingSlash bool
+
+	// PathSegments documents locale and tenant path segments as parameters
+	// with an enum of their observed values.
+	PathSegments PathSegmentOptions
+
+	// QueryParamAliases documents query parameters whose names differ only
+	// in case, underscores, or hyphens (pageSize, page_size) as one
+	// parameter, listing the other spellings in ParamData.Aliases.
+	QueryParamAliases bool
+
+	// CommaQueryParams names query parameters whose values are
+	// comma-separated lists (?ids=1,2,3), documented as arrays with explode
+	// false. Other values containing commas are documented as strings.
+	CommaQueryParams []string
 }
 
+// HeaderMode selects how request headers are turned into operation parameters.
+type HeaderMode string
+
+const (
+	// HeaderModeBlacklist documents all headers except common infrastructure headers.
+	HeaderModeBlacklist HeaderMode = "blacklist"
+
+	// HeaderModeAllowlist documents only the headers listed in EngineOptions.AllowedHeaders.
+	HeaderModeAllowlist HeaderMode = "allowlist"
+
+	// HeaderModeNone documents no request headers.
+	HeaderModeNone HeaderMode = "none"
+)
+
 // DefaultEngineOptions returns the default engine options.
 func DefaultEngineOptions() EngineOptions {
 	return EngineOptions{
-		IncludeErrorResponses: true,
-		MinStatusCode:         100,
-		MaxStatusCode:         599,
-		SkipEmptyBodies:       false,
+		IncludeErrorResponses:  true,
+		MinStatusCode:          100,
+		MaxStatusCode:          599,
+		SkipEmptyBodies:        false,
+		HeaderMode:             HeaderModeBlacklist,
+		MinHeaderObservations:  1,
+		MinRequestsPerEndpoint: 1,
+		MaxExampleStringLength: DefaultMaxExampleStringLength,
 	}
 }
 
 // NewEngine creates a new inference engine.
 func NewEngine(options EngineOptions) *Engine {
+	clusterer := NewEndpointClusterer()
+	clusterer.SetHeaderFilter(options.HeaderMode, options.AllowedHeaders)
+	clusterer.SetExamplePairs(options.ExamplePairs)
+	clusterer.SetInvalidExamples(options.InvalidExamples)
+	clusterer.SetPathSegmentOptions(options.PathSegments)
+	clusterer.SetPathUnification(options.CaseInsensitivePaths, options.IgnoreTrailingSlash)
+	clusterer.SetQueryParamAliases(options.QueryParamAliases)
+	clusterer.SetCommaQueryParams(options.CommaQueryParams)
+	clusterer.SetSecurityRules(options.SecurityRules)
+	if options.DetectLinks {
+		clusterer.EnableLinkDetection(options.LinkMinValues)
+	}
+
+	var memory *ExampleMemory
+	if options.MaxExampleStringLength > 0 || options.ExampleMemoryBudget > 0 {
+		memory = NewExampleMemory(options.MaxExampleStringLength, options.ExampleMemoryBudget)
+		clusterer.SetExampleMemory(memory)
+	}
+
+	noiseDetector := NewNoiseDetector()
+	noiseDetector.FuzzEmptyAgent = options.NoiseEmptyAgent
+
 	return &Engine{
-		clusterer: NewEndpointClusterer(),
-		options:   options,
+		clusterer:     clusterer,
+		options:       options,
+		noiseDetector: noiseDetector,
+		filtered:      make(map[string]int),
+		exampleMemory: memory,
 	}
 }
 
+// ExampleMemory returns the engine's example memory bounds, or nil if
+// truncation and the memory budget are both disabled.
+func (e *Engine) ExampleMemory() *ExampleMemory {
+	return e.exampleMemory
+}
+
 // ProcessRecords processes a slice of IR records.
 func (e *Engine) ProcessRecords(records []ir.IRRecord) {
 	for i := range records {
@@ -81,6 +266,41 @@ func (e *Engine) ProcessRecord(record *ir.IRRecord) {
 		return
 	}
 
+	// Record the request only when the response was not captured
+	if record.HasPlaceholderResponse() {
+		status = 0
+	}
+
+	// Skip static assets and page loads if configured
+	if e.options.APIOnly && ir.IsNonAPIRecord(record) {
+		e.mu.Lock()
+		e.filtered[FilterReasonNonAPI]++
+		e.mu.Unlock()
+		return
+	}
+
+	// Skip records from other environments, sessions, etc. if configured
+	if len(e.options.MetadataFilter) > 0 && !ir.MatchesMetadata(record, e.options.MetadataFilter) {
+		e.mu.Lock()
+		e.filtered[FilterReasonMetadata]++
+		e.mu.Unlock()
+		return
+	}
+
+	// Check for bot/scanner traffic
+	var noisy bool
+	if e.options.NoiseMode != NoiseModeOff {
+		e.mu.Lock()
+		var reason string
+		noisy, reason = e.noiseDetector.Check(record)
+		if noisy && e.options.NoiseMode == NoiseModeDrop {
+			e.filtered[reason]++
+			e.mu.Unlock()
+			return
+		}
+		e.mu.Unlock()
+	}
+
 	// Extract fields from record
 	method := string(record.Request.Method)
 	path := record.Request.Path
@@ -111,6 +331,10 @@ func (e *Engine) ProcessRecord(record *ir.IRRecord) {
 	if record.Request.ContentType != nil {
 		requestContentType = *record.Request.ContentType
 	}
+	if e.options.IgnoreGetDeleteBodies && !methodHasBodySemantics(method) {
+		requestBody = nil
+		requestContentType = ""
+	}
 
 	// Get response body
 	var responseBody any
@@ -168,28 +392,67 @@ func (e *Engine) ProcessRecord(record *ir.IRRecord) {
 		}
 	}
 
+	// Give each SOAP action its own endpoint
+	if e.options.SOAPOperations {
+		if action := SOAPOperation(record); action != "" {
+			pathTemplate, pathParams, _ = e.clusterer.resolveTemplate(path, pathTemplate, pathParams)
+			pathTemplate += "#" + action
+			if docs == nil {
+				docs = &RecordDocumentation{}
+			}
+			if docs.OperationID == "" {
+				docs.OperationID = action
+			}
+		}
+	}
+
 	// Add to clusterer
-	e.clusterer.AddRecord(
+	endpoint := e.clusterer.AddRecord(
 		method,
 		path,
 		pathTemplate,
 		pathParams,
 		query,
 		headers,
+		record.Request.HeaderValues,
 		requestBody,
 		requestContentType,
 		status,
 		responseBody,
 		responseContentType,
 		responseHeaders,
+		record.Response.HeaderValues,
 		host,
 		scheme,
 		docs,
 	)
+	if noisy {
+		e.clusterer.markNoise(endpoint)
+	}
+	if len(record.Metadata) > 0 {
+		e.clusterer.addMetadata(endpoint, record.Metadata)
+	}
+	var earlyHints []string
+	for _, info := range record.Response.Informational {
+		if info.Status == http.StatusEarlyHints {
+			earlyHints = append(earlyHints, info.HeaderValuesOf("link")...)
+		}
+	}
+	if len(earlyHints) > 0 {
+		e.clusterer.addEarlyHints(endpoint, earlyHints)
+	}
+}
+
+// Templates returns the trie of path templates used to assign records to
+// endpoints. Seed it with Add before processing to match known templates.
+func (e *Engine) Templates() *TemplateTrie {
+	return e.clusterer.Templates()
 }
 
 // SetAPIMetadata sets API-level metadata from IR batch metadata.
 func (e *Engine) SetAPIMetadata(metadata *APIMetadataData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.apiMetadata = metadata
 }
 
@@ -258,17 +521,88 @@ func (e *Engine) SetAPIMetadataFromIR(irMeta *ir.APIMetadata) {
 		meta.TagDefinitions = append(meta.TagDefinitions, tagDef)
 	}
 
-	e.apiMetadata = meta
+	e.SetAPIMetadata(meta)
 }
 
 // Finalize completes the inference process.
 func (e *Engine) Finalize() *InferenceResult {
 	e.clusterer.Finalize()
-	result := e.clusterer.GetResult()
+	return e.postProcess(e.clusterer.GetResult())
+}
+
+// Snapshot returns a finalized inference result for the records processed so
+// far, without stopping ingestion. The result is built from copies of the
+// endpoint state, so ProcessRecord may continue concurrently and later calls
+// to Snapshot or Finalize are unaffected.
+func (e *Engine) Snapshot() *InferenceResult {
+	return e.postProcess(e.clusterer.Snapshot())
+}
+
+// postProcess applies the engine's thresholds, pruning, and metadata to a result.
+func (e *Engine) postProcess(result *InferenceResult) *InferenceResult {
+	pruneHeaderParams(result, e.options.MinHeaderObservations)
+	applyRequiredThresholds(result, e.options.RequiredQueryThreshold, e.options.RequiredHeaderThreshold)
+	if e.options.PruneNotFoundEndpoints {
+		applyNotFoundPruning(result)
+	}
+	applyMinRequests(result, e.options.MinRequestsPerEndpoint, e.options.FlagLowSampleEndpoints)
+	applySelection(result, e.options.Selection)
+	applyCRUDActions(result)
+	applyBulkDetection(result)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	result.APIMetadata = e.apiMetadata
+	for reason, count := range e.filtered {
+		result.FilteredRecords[reason] = count
+	}
 	return result
 }
 
+// pruneHeaderParams removes header parameters observed fewer than minCount times.
+func pruneHeaderParams(result *InferenceResult, minCount int) {
+	if minCount <= 1 {
+		return
+	}
+	for _, endpoint := range result.Endpoints {
+		for name, param := range endpoint.HeaderParams {
+			if param.seenCount < minCount {
+				delete(endpoint.HeaderParams, name)
+			}
+		}
+	}
+}
+
+// applyRequiredThresholds marks query and header parameters as required based on
+// how often they were observed. Path parameters are always required by OpenAPI.
+func applyRequiredThresholds(result *InferenceResult, queryThreshold, headerThreshold float64) {
+	for _, endpoint := range result.Endpoints {
+		if endpoint.RequestCount == 0 {
+			continue
+		}
+		if queryThreshold > 0 {
+			for _, param := range endpoint.QueryParams {
+				param.Required = param.Frequency(endpoint.RequestCount) >= queryThreshold
+			}
+		}
+		if headerThreshold > 0 {
+			for _, param := range endpoint.HeaderParams {
+				param.Required = param.Frequency(endpoint.RequestCount) >= headerThreshold
+			}
+		}
+	}
+}
+
+// methodHasBodySemantics reports whether HTTP defines request body semantics
+// for a method.
+func methodHasBodySemantics(method string) bool {
+	switch strings.ToUpper(method) {
+	case "GET", "HEAD", "DELETE", "OPTIONS":
+		return false
+	}
+	return true
+}
+
 // InferFromRecords is a convenience function that processes records and returns results.
 func InferFromRecords(records []ir.IRRecord) *InferenceResult {
 	engine := NewEngine(DefaultEngineOptions())
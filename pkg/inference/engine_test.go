@@ -2,6 +2,7 @@ package inference
 
 import (
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/grokify/traffic2openapi/pkg/ir"
@@ -247,3 +248,172 @@ func TestEndToEndInference(t *testing.T) {
 		t.Error("POST /users should have request body")
 	}
 }
+
+func TestRequiredQueryThreshold(t *testing.T) {
+	var records []ir.IRRecord
+	for i := 0; i < 100; i++ {
+		query := map[string]any{"limit": "10"}
+		if i%10 == 0 {
+			query["debug"] = "1"
+		}
+		if i == 0 {
+			delete(query, "limit")
+		}
+		records = append(records, ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items", Query: query},
+			Response: ir.Response{Status: 200},
+		})
+	}
+
+	// Default: all query params optional
+	endpoint := InferFromRecords(records).Endpoints["GET /items"]
+	if endpoint.QueryParams["limit"].Required {
+		t.Error("expected limit to be optional without threshold")
+	}
+
+	opts := DefaultEngineOptions()
+	opts.RequiredQueryThreshold = 0.99
+	engine := NewEngine(opts)
+	engine.ProcessRecords(records)
+	endpoint = engine.Finalize().Endpoints["GET /items"]
+
+	if !endpoint.QueryParams["limit"].Required {
+		t.Error("expected limit (99%) to be required")
+	}
+	if endpoint.QueryParams["debug"].Required {
+		t.Error("expected debug (10%) to be optional")
+	}
+}
+
+func TestAPIOnlyFiltering(t *testing.T) {
+	html := "text/html"
+	records := []ir.IRRecord{
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/api/users"}, Response: ir.Response{Status: 200}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/assets/site.css"}, Response: ir.Response{Status: 200}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/about"}, Response: ir.Response{Status: 200, ContentType: &html}},
+	}
+
+	opts := DefaultEngineOptions()
+	opts.APIOnly = true
+	engine := NewEngine(opts)
+	engine.ProcessRecords(records)
+	result := engine.Finalize()
+
+	if len(result.Endpoints) != 1 || result.Endpoints["GET /api/users"] == nil {
+		t.Errorf("expected only GET /api/users, got %v", result.Endpoints)
+	}
+	if result.FilteredRecords[FilterReasonNonAPI] != 2 {
+		t.Errorf("expected 2 non-api records filtered, got %d", result.FilteredRecords[FilterReasonNonAPI])
+	}
+}
+
+func TestEngineConcurrentProcessRecord(t *testing.T) {
+	records := benchRecords(800)
+
+	engine := NewEngine(DefaultEngineOptions())
+	done := make(chan struct{})
+	snapshots := make(chan int)
+	go func() {
+		count := 0
+		for {
+			select {
+			case <-done:
+				snapshots <- count
+				return
+			default:
+				engine.Snapshot()
+				count++
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	const workers = 8
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(records); i += workers {
+				engine.ProcessRecord(&records[i])
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(done)
+	if <-snapshots == 0 {
+		t.Error("expected at least one snapshot during ingestion")
+	}
+
+	sequential := NewEngine(DefaultEngineOptions())
+	sequential.ProcessRecords(records)
+	want := sequential.Finalize()
+
+	snapshot := engine.Snapshot()
+	got := engine.Finalize()
+	for _, result := range []*InferenceResult{snapshot, got} {
+		if len(result.Endpoints) != len(want.Endpoints) {
+			t.Fatalf("expected %d endpoints, got %d", len(want.Endpoints), len(result.Endpoints))
+		}
+		for key, endpoint := range want.Endpoints {
+			if result.Endpoints[key] == nil || result.Endpoints[key].RequestCount != endpoint.RequestCount {
+				t.Errorf("%s: expected %d requests, got %+v", key, endpoint.RequestCount, result.Endpoints[key])
+			}
+		}
+	}
+}
+
+func TestEngineMetadataFilter(t *testing.T) {
+	opts := DefaultEngineOptions()
+	opts.MetadataFilter = map[string]any{ir.MetaEnvironment: "staging"}
+	engine := NewEngine(opts)
+
+	for _, env := range []string{"staging", "production", ""} {
+		record := ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/users"},
+			Response: ir.Response{Status: 200},
+		}
+		if env != "" {
+			record.SetMeta(ir.MetaEnvironment, env)
+			record.SetMeta(ir.MetaLabel, "run-"+env)
+		}
+		engine.ProcessRecord(&record)
+	}
+	result := engine.Finalize()
+
+	if result.FilteredRecords[FilterReasonMetadata] != 2 {
+		t.Errorf("expected 2 records filtered by metadata, got %v", result.FilteredRecords)
+	}
+	endpoint := result.Endpoints["GET /users"]
+	if endpoint == nil || endpoint.RequestCount != 1 {
+		t.Fatalf("expected 1 request to GET /users, got %+v", endpoint)
+	}
+	if got := endpoint.Metadata[ir.MetaLabel]; len(got) != 1 || got[0] != "run-staging" {
+		t.Errorf("expected label metadata on endpoint, got %v", endpoint.Metadata)
+	}
+}
+
+func TestPlaceholderResponsesIgnored(t *testing.T) {
+	placeholder := ir.IRRecord{
+		Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{"name": "a"}},
+		Response: ir.Response{Status: 200},
+	}
+	placeholder.SetMeta(ir.MetaResponsePlaceholder, true)
+	live := ir.IRRecord{
+		Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{"name": "b"}},
+		Response: ir.Response{Status: 201, Body: map[string]any{"id": "1"}},
+	}
+
+	endpoint := InferFromRecords([]ir.IRRecord{placeholder, live}).Endpoints["POST /users"]
+	if endpoint == nil {
+		t.Fatal("missing POST /users")
+	}
+	if endpoint.RequestCount != 2 || endpoint.RequestBody == nil || endpoint.RequestBody.Count != 2 {
+		t.Errorf("expected both requests recorded, got %d requests", endpoint.RequestCount)
+	}
+	if _, ok := endpoint.Responses[200]; ok {
+		t.Error("placeholder 200 response was inferred")
+	}
+	if _, ok := endpoint.Responses[201]; !ok {
+		t.Error("live 201 response is missing")
+	}
+}
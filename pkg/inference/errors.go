@@ -0,0 +1,156 @@
+package inference
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxErrorCodeLength bounds the length of values read as error codes.
+const maxErrorCodeLength = 64
+
+// ErrorCodeFields are the response body fields, as schema paths, whose
+// values are read as error codes.
+var ErrorCodeFields = []string{"code", "error", "error_code", "errorCode", "error.code", "errors[].code"}
+
+// ErrorCodeDetector collects error code values from 4xx and 5xx response
+// bodies across endpoints.
+type ErrorCodeDetector struct {
+	codes map[string]*ErrorCode
+}
+
+// ErrorCode is an error code observed in error responses.
+type ErrorCode struct {
+	Code      string         `json:"code"`
+	Fields    []string       `json:"fields"`    // schema paths holding the code, e.g. "error.code"
+	Count     int            `json:"count"`     // responses containing the code
+	Endpoints []ErrorCodeUse `json:"endpoints"` // by count, descending
+}
+
+// ErrorCodeUse counts the responses of one endpoint and status with a code
+// in a field.
+type ErrorCodeUse struct {
+	Endpoint string `json:"endpoint"` // endpoint key, e.g. "POST /users"
+	Status   int    `json:"status"`
+	Field    string `json:"field"`
+	Count    int    `json:"count"`
+}
+
+// NewErrorCodeDetector creates a new ErrorCodeDetector.
+func NewErrorCodeDetector() *ErrorCodeDetector {
+	return &ErrorCodeDetector{codes: make(map[string]*ErrorCode)}
+}
+
+// DetectFromResponse records the error codes of an error response body.
+// Only code-like strings (no whitespace, at most 64 characters) are read, so
+// human-readable messages in an "error" field are ignored.
+func (d *ErrorCodeDetector) DetectFromResponse(endpoint string, status int, body any) {
+	if status < 400 {
+		return
+	}
+	for field, code := range errorCodesFromBody(body) {
+		entry, ok := d.codes[code]
+		if !ok {
+			entry = &ErrorCode{Code: code}
+			d.codes[code] = entry
+		}
+		entry.Count++
+		if !slices.Contains(entry.Fields, field) {
+			entry.Fields = append(entry.Fields, field)
+		}
+		found := false
+		for i := range entry.Endpoints {
+			if use := &entry.Endpoints[i]; use.Endpoint == endpoint && use.Status == status && use.Field == field {
+				use.Count++
+				found = true
+				break
+			}
+		}
+		if !found {
+			entry.Endpoints = append(entry.Endpoints, ErrorCodeUse{Endpoint: endpoint, Status: status, Field: field, Count: 1})
+		}
+	}
+}
+
+// GetCodes returns copies of the detected error codes, most frequent first.
+func (d *ErrorCodeDetector) GetCodes() []*ErrorCode {
+	codes := make([]*ErrorCode, 0, len(d.codes))
+	for _, entry := range d.codes {
+		copied := *entry
+		copied.Fields = append([]string(nil), entry.Fields...)
+		sort.Strings(copied.Fields)
+		copied.Endpoints = append([]ErrorCodeUse(nil), entry.Endpoints...)
+		sort.Slice(copied.Endpoints, func(i, j int) bool {
+			a, b := copied.Endpoints[i], copied.Endpoints[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			if a.Endpoint != b.Endpoint {
+				return a.Endpoint < b.Endpoint
+			}
+			if a.Status != b.Status {
+				return a.Status < b.Status
+			}
+			return a.Field < b.Field
+		})
+		codes = append(codes, &copied)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if codes[i].Count != codes[j].Count {
+			return codes[i].Count > codes[j].Count
+		}
+		return codes[i].Code < codes[j].Code
+	})
+	return codes
+}
+
+// errorCodesFromBody returns the code-like values of ErrorCodeFields in a
+// body, keyed by field. Each field yields at most one code per response.
+func errorCodesFromBody(body any) map[string]string {
+	obj, ok := body.(map[string]any)
+	if !ok {
+		return nil
+	}
+	codes := make(map[string]string)
+	for _, field := range ErrorCodeFields {
+		if code, ok := errorCodeAt(obj, strings.Split(field, ".")); ok {
+			codes[field] = code
+		}
+	}
+	return codes
+}
+
+// errorCodeAt returns the code-like string at a schema path in obj, taking
+// the first array item for "[]" segments.
+func errorCodeAt(obj map[string]any, parts []string) (string, bool) {
+	name, isArray := strings.CutSuffix(parts[0], "[]")
+	value := obj[name]
+	if isArray {
+		items, ok := value.([]any)
+		if !ok || len(items) == 0 {
+			return "", false
+		}
+		value = items[0]
+	}
+	if len(parts) > 1 {
+		next, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		return errorCodeAt(next, parts[1:])
+	}
+	code, ok := value.(string)
+	if !ok || !isCodeLike(code) {
+		return "", false
+	}
+	return code, true
+}
+
+// isCodeLike reports whether s looks like an error code rather than a message.
+func isCodeLike(s string) bool {
+	if s == "" || len(s) > maxErrorCodeLength {
+		return false
+	}
+	return strings.IndexFunc(s, unicode.IsSpace) == -1
+}
@@ -0,0 +1,31 @@
+package inference
+
+import "testing"
+
+func TestErrorCodeDetector(t *testing.T) {
+	d := NewErrorCodeDetector()
+	d.DetectFromResponse("POST /users", 400, map[string]any{"error": map[string]any{"code": "missing_field"}})
+	d.DetectFromResponse("POST /users", 400, map[string]any{"error": map[string]any{"code": "missing_field"}})
+	d.DetectFromResponse("PUT /users/{userId}", 422, map[string]any{"errors": []any{map[string]any{"code": "missing_field"}}})
+	d.DetectFromResponse("GET /orders", 401, map[string]any{"error": "Authentication is required"})
+	d.DetectFromResponse("GET /orders", 403, map[string]any{"code": "forbidden"})
+	d.DetectFromResponse("GET /orders", 200, map[string]any{"code": "ok"})
+
+	codes := d.GetCodes()
+	if len(codes) != 2 {
+		t.Fatalf("expected 2 codes, got %d: %+v", len(codes), codes)
+	}
+	missing := codes[0]
+	if missing.Code != "missing_field" || missing.Count != 3 {
+		t.Fatalf("expected missing_field in 3 responses first, got %+v", missing)
+	}
+	if len(missing.Fields) != 2 || missing.Fields[0] != "error.code" || missing.Fields[1] != "errors[].code" {
+		t.Errorf("unexpected fields %v", missing.Fields)
+	}
+	if use := missing.Endpoints[0]; use.Endpoint != "POST /users" || use.Status != 400 || use.Count != 2 {
+		t.Errorf("expected POST /users 400 most frequent, got %+v", use)
+	}
+	if codes[1].Code != "forbidden" {
+		t.Errorf("expected forbidden, got %q", codes[1].Code)
+	}
+}
@@ -0,0 +1,262 @@
+package inference
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// DefaultEvolutionWindow is the window size used when EvolutionOptions.Window
+// is not set.
+const DefaultEvolutionWindow = 24 * time.Hour
+
+// DefaultEvolutionRemovedAfter is the number of windows a field must be
+// missing from before Evolution reports it removed, when
+// EvolutionOptions.RemovedAfter is not set.
+const DefaultEvolutionRemovedAfter = 3
+
+// Field change kinds reported by Evolution.
+const (
+	FieldAdded       = "added"        // field first observed, or observed again after being removed
+	FieldRemoved     = "removed"      // field no longer observed
+	FieldTypeChanged = "type-changed" // field observed with a different type
+)
+
+// EvolutionOptions configures schema evolution analysis.
+type EvolutionOptions struct {
+	// Window is the size of the time windows records are bucketed into
+	// (default DefaultEvolutionWindow). Windows are aligned to UTC.
+	Window time.Duration
+
+	// RemovedAfter is the number of consecutive windows in which a body was
+	// observed without a field before the field is reported removed
+	// (default DefaultEvolutionRemovedAfter). Optional fields are often
+	// missing from the few bodies of a quiet window, and would otherwise be
+	// reported removed and added again.
+	RemovedAfter int
+
+	// Engine configures the inference run on each window.
+	Engine EngineOptions
+}
+
+// EvolutionReport is a changelog of an API reconstructed from traffic.
+type EvolutionReport struct {
+	Windows   []EvolutionWindow  `json:"windows"`
+	Endpoints []EndpointLifetime `json:"endpoints,omitempty"`
+	Changes   []FieldChange      `json:"changes,omitempty"`
+
+	// Untimestamped counts the records left out for having no timestamp.
+	Untimestamped int `json:"untimestamped,omitempty"`
+}
+
+// EvolutionWindow is a time window that contained traffic.
+type EvolutionWindow struct {
+	Start     time.Time `json:"start"`
+	Records   int       `json:"records"`
+	Endpoints int       `json:"endpoints"`
+}
+
+// EndpointLifetime gives the first and last windows an endpoint was
+// observed in.
+type EndpointLifetime struct {
+	Endpoint  string    `json:"endpoint"` // "METHOD /path/template"
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// FieldChange is a change to a body field between two windows in which its
+// endpoint was observed. Removals are reported at the first window the field
+// was missing from.
+type FieldChange struct {
+	Kind     string    `json:"kind"`     // FieldAdded, FieldRemoved, or FieldTypeChanged
+	Window   time.Time `json:"window"`   // start of the window the change was observed in
+	Endpoint string    `json:"endpoint"` // "METHOD /path/template"
+	Location string    `json:"location"` // "request" or "response <status>"
+	Field    string    `json:"field"`    // dotted field path, e.g. "items[].name"
+	OldType  string    `json:"oldType,omitempty"`
+	NewType  string    `json:"newType,omitempty"`
+}
+
+// Evolution buckets records into time windows, infers the body schemas of
+// each window, and reports when fields appeared, disappeared, or changed type.
+//
+// Each request or response body is compared with the last window its
+// endpoint and status were observed in, so a field isn't reported removed
+// because the endpoint got no traffic for a while, and a field is only
+// reported removed once it has been missing from opts.RemovedAfter such
+// windows in a row. Fields of the first window a body was observed in are
+// its baseline and aren't reported. Null values don't count as a type change.
+func Evolution(records []ir.IRRecord, opts EvolutionOptions) *EvolutionReport {
+	if opts.Window <= 0 {
+		opts.Window = DefaultEvolutionWindow
+	}
+	if opts.RemovedAfter <= 0 {
+		opts.RemovedAfter = DefaultEvolutionRemovedAfter
+	}
+	report := &EvolutionReport{}
+
+	buckets := make(map[time.Time][]ir.IRRecord)
+	for _, record := range records {
+		if record.Timestamp == nil {
+			report.Untimestamped++
+			continue
+		}
+		start := record.Timestamp.UTC().Truncate(opts.Window)
+		buckets[start] = append(buckets[start], record)
+	}
+	starts := make([]time.Time, 0, len(buckets))
+	for start := range buckets {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	lifetimes := make(map[string]*EndpointLifetime)
+	baselines := make(map[string]map[string]*observedField) // endpoint + location -> field
+	for _, start := range starts {
+		engine := NewEngine(opts.Engine)
+		engine.ProcessRecords(buckets[start])
+		result := engine.Finalize()
+
+		report.Windows = append(report.Windows, EvolutionWindow{
+			Start:     start,
+			Records:   len(buckets[start]),
+			Endpoints: len(result.Endpoints),
+		})
+
+		for _, key := range slices.Sorted(maps.Keys(result.Endpoints)) {
+			if lifetime, ok := lifetimes[key]; ok {
+				lifetime.LastSeen = start
+			} else {
+				lifetimes[key] = &EndpointLifetime{Endpoint: key, FirstSeen: start, LastSeen: start}
+			}
+
+			for _, body := range endpointBodies(result.Endpoints[key]) {
+				id := key + "\n" + body.location
+				fields := schemaFieldTypes(body.schema)
+				baseline, ok := baselines[id]
+				if !ok {
+					baseline = make(map[string]*observedField, len(fields))
+					for field, typ := range fields {
+						baseline[field] = &observedField{typ: typ}
+					}
+					baselines[id] = baseline
+					continue
+				}
+				for _, change := range fieldChanges(baseline, fields, start, opts.RemovedAfter) {
+					change.Endpoint = key
+					change.Location = body.location
+					report.Changes = append(report.Changes, change)
+				}
+			}
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(lifetimes)) {
+		report.Endpoints = append(report.Endpoints, *lifetimes[key])
+	}
+
+	// Removals are dated to the window the field went missing from
+	sort.SliceStable(report.Changes, func(i, j int) bool {
+		a, b := report.Changes[i], report.Changes[j]
+		if !a.Window.Equal(b.Window) {
+			return a.Window.Before(b.Window)
+		}
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		return a.Field < b.Field
+	})
+	return report
+}
+
+type observedBody struct {
+	location string
+	schema   *SchemaStore
+}
+
+// endpointBodies returns the request and response bodies observed for an
+// endpoint, request first and responses by status.
+func endpointBodies(endpoint *EndpointData) []observedBody {
+	var bodies []observedBody
+	if endpoint.RequestBody != nil && endpoint.RequestBody.Count > 0 {
+		bodies = append(bodies, observedBody{"request", endpoint.RequestBody.Schema})
+	}
+	for _, status := range slices.Sorted(maps.Keys(endpoint.Responses)) {
+		resp := endpoint.Responses[status]
+		if resp.Body != nil && resp.Body.totalCount > 0 {
+			bodies = append(bodies, observedBody{"response " + strconv.Itoa(status), resp.Body})
+		}
+	}
+	return bodies
+}
+
+// schemaFieldTypes returns the type of each field path in a store. Fields
+// only observed as null have type "null".
+func schemaFieldTypes(store *SchemaStore) map[string]string {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	fields := make(map[string]string, len(store.Types))
+	for path := range store.Nullable {
+		fields[path] = "null"
+	}
+	for path, typ := range store.Types {
+		fields[path] = typ
+	}
+	delete(fields, "")
+	return fields
+}
+
+// observedField is a body field of a baseline.
+type observedField struct {
+	typ          string
+	missing      int       // consecutive windows the field was missing from
+	missingSince time.Time // first of those windows
+}
+
+// fieldChanges compares the fields observed in window with the baseline and
+// updates the baseline to match. A field is removed once it has been missing
+// from removedAfter windows in a row, and a null-only observation keeps the
+// field's earlier type.
+func fieldChanges(baseline map[string]*observedField, fields map[string]string, window time.Time, removedAfter int) []FieldChange {
+	var changes []FieldChange
+	for _, field := range slices.Sorted(maps.Keys(baseline)) {
+		if _, ok := fields[field]; ok {
+			continue
+		}
+		observed := baseline[field]
+		if observed.missing == 0 {
+			observed.missingSince = window
+		}
+		observed.missing++
+		if observed.missing >= removedAfter {
+			changes = append(changes, FieldChange{Kind: FieldRemoved, Window: observed.missingSince, Field: field, OldType: observed.typ})
+			delete(baseline, field)
+		}
+	}
+	for _, field := range slices.Sorted(maps.Keys(fields)) {
+		typ := fields[field]
+		observed, ok := baseline[field]
+		switch {
+		case !ok:
+			changes = append(changes, FieldChange{Kind: FieldAdded, Window: window, Field: field, NewType: typ})
+			baseline[field] = &observedField{typ: typ}
+			continue
+		case typ == "null":
+		case observed.typ != typ && observed.typ != "null":
+			changes = append(changes, FieldChange{Kind: FieldTypeChanged, Window: window, Field: field, OldType: observed.typ, NewType: typ})
+			observed.typ = typ
+		default:
+			observed.typ = typ
+		}
+		observed.missing = 0
+	}
+	return changes
+}
@@ -0,0 +1,103 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestEvolution(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	newRecord := func(ts time.Time, path string, body any) ir.IRRecord {
+		return ir.IRRecord{
+			Timestamp: &ts,
+			Request:   ir.Request{Method: ir.RequestMethodGET, Path: path},
+			Response:  ir.Response{Status: 200, Body: body},
+		}
+	}
+	records := []ir.IRRecord{
+		newRecord(day.Add(time.Hour), "/users", map[string]any{"id": 1, "legacyId": 7, "age": "42"}),
+		newRecord(day.Add(2*time.Hour), "/orders", map[string]any{"total": 10}),
+		newRecord(day.Add(25*time.Hour), "/users", map[string]any{"id": 2, "email": "a@example.com", "age": 42}),
+		// /orders is quiet on day two, so its fields aren't removed
+		newRecord(day.Add(49*time.Hour), "/orders", map[string]any{"total": 12}),
+		newRecord(day.Add(50*time.Hour), "/users", map[string]any{"id": 3, "email": nil, "age": 40}),
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users"}, Response: ir.Response{Status: 200}},
+	}
+
+	report := Evolution(records, EvolutionOptions{Window: 24 * time.Hour, RemovedAfter: 1, Engine: DefaultEngineOptions()})
+
+	if len(report.Windows) != 3 || !report.Windows[1].Start.Equal(day.Add(24*time.Hour)) {
+		t.Fatalf("expected 3 daily windows, got %+v", report.Windows)
+	}
+	if report.Untimestamped != 1 {
+		t.Errorf("expected 1 untimestamped record, got %d", report.Untimestamped)
+	}
+	if len(report.Endpoints) != 2 || report.Endpoints[0].Endpoint != "GET /orders" ||
+		!report.Endpoints[0].LastSeen.Equal(day.Add(48*time.Hour)) {
+		t.Errorf("unexpected endpoint lifetimes: %+v", report.Endpoints)
+	}
+
+	var got []string
+	for _, c := range report.Changes {
+		got = append(got, c.Window.Format("01-02")+" "+c.Endpoint+" "+c.Location+" "+c.Kind+" "+c.Field+" "+c.OldType+">"+c.NewType)
+	}
+	want := []string{
+		"03-02 GET /users response 200 type-changed age string>integer",
+		"03-02 GET /users response 200 added email >string",
+		"03-02 GET /users response 200 removed legacyId integer>",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("changes:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestEvolutionIntermittentField(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	var records []ir.IRRecord
+	add := func(days int, body map[string]any) {
+		ts := day.Add(time.Duration(days)*24*time.Hour + time.Hour)
+		records = append(records, ir.IRRecord{
+			Timestamp: &ts,
+			Request:   ir.Request{Method: ir.RequestMethodGET, Path: "/users"},
+			Response:  ir.Response{Status: 200, Body: body},
+		})
+	}
+	// nickname is optional and missing from every other day's traffic;
+	// legacyId goes away for good on day 3
+	for d := 0; d < 7; d++ {
+		body := map[string]any{"id": d}
+		if d%2 == 0 {
+			body["nickname"] = "n"
+		}
+		if d < 3 {
+			body["legacyId"] = d
+		}
+		add(d, body)
+	}
+
+	report := Evolution(records, EvolutionOptions{Window: 24 * time.Hour, Engine: DefaultEngineOptions()})
+
+	var got []string
+	for _, c := range report.Changes {
+		got = append(got, c.Window.Format("01-02")+" "+c.Kind+" "+c.Field)
+	}
+	want := []string{"03-04 removed legacyId"}
+	if !slices.Equal(got, want) {
+		t.Errorf("changes:\n got %q\nwant %q", got, want)
+	}
+
+	// Without the threshold, the optional field flaps
+	report = Evolution(records, EvolutionOptions{Window: 24 * time.Hour, RemovedAfter: 1, Engine: DefaultEngineOptions()})
+	flaps := 0
+	for _, c := range report.Changes {
+		if c.Field == "nickname" {
+			flaps++
+		}
+	}
+	if flaps != 6 {
+		t.Errorf("expected nickname to be removed and added 3 times each, got %d changes", flaps)
+	}
+}
@@ -0,0 +1,185 @@
+package inference
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"slices"
+	"strings"
+)
+
+// maxFilenames is the most distinct filenames kept per file part or
+// download, as examples.
+const maxFilenames = 5
+
+// maxMultipartField is the most bytes read from a text part of a multipart
+// body.
+const maxMultipartField = 64 << 10
+
+// FilePart is a multipart/form-data field carrying uploaded files.
+type FilePart struct {
+	Count        int            // multipart bodies holding the field
+	Multiple     bool           // a body held several files for the field
+	ContentTypes map[string]int // part content type -> files
+	Filenames    []string       // distinct uploaded filenames, up to maxFilenames
+}
+
+// DownloadData describes responses sent as file downloads, with
+// Content-Disposition: attachment.
+type DownloadData struct {
+	Count     int      // attachment responses
+	Filenames []string // distinct filenames, up to maxFilenames
+}
+
+// IsMultipartFormData reports whether a content type is multipart/form-data.
+func IsMultipartFormData(contentType string) bool {
+	base, _, _ := strings.Cut(contentType, ";")
+	return strings.EqualFold(strings.TrimSpace(base), "multipart/form-data")
+}
+
+// multipartUpload is a multipart/form-data body split into its text fields
+// and its files.
+type multipartUpload struct {
+	fields map[string]any
+	files  map[string][]multipartFile
+}
+
+type multipartFile struct {
+	filename    string
+	contentType string
+}
+
+// parseMultipartBody parses a raw multipart/form-data body. Repeated text
+// fields become arrays. A body cut off partway through, as when it is
+// truncated to a size limit, keeps the parts read before the cut.
+func parseMultipartBody(contentType string, body any) (*multipartUpload, bool) {
+	raw, ok := body.(string)
+	if !ok {
+		return nil, false
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["boundary"] == "" {
+		return nil, false
+	}
+
+	upload := &multipartUpload{fields: make(map[string]any), files: make(map[string][]multipartFile)}
+	reader := multipart.NewReader(strings.NewReader(raw), params["boundary"])
+	parts := 0
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && parts == 0 {
+				return nil, false
+			}
+			break
+		}
+		parts++
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+		if filename := part.FileName(); filename != "" {
+			upload.files[name] = append(upload.files[name], multipartFile{filename: filename, contentType: part.Header.Get("Content-Type")})
+			continue
+		}
+		value, _ := io.ReadAll(io.LimitReader(part, maxMultipartField))
+		switch existing := upload.fields[name].(type) {
+		case nil:
+			upload.fields[name] = string(value)
+		case []any:
+			upload.fields[name] = append(existing, string(value))
+		default:
+			upload.fields[name] = []any{existing, string(value)}
+		}
+	}
+	return upload, true
+}
+
+// addMultipart records the files of a multipart body.
+func (b *BodyData) addMultipart(upload *multipartUpload) {
+	b.Multipart++
+	if len(upload.files) > 0 && b.FileParts == nil {
+		b.FileParts = make(map[string]*FilePart)
+	}
+	for name, files := range upload.files {
+		part, ok := b.FileParts[name]
+		if !ok {
+			part = &FilePart{ContentTypes: make(map[string]int)}
+			b.FileParts[name] = part
+		}
+		part.Count++
+		if len(files) > 1 {
+			part.Multiple = true
+		}
+		for _, file := range files {
+			if file.contentType != "" {
+				part.ContentTypes[file.contentType]++
+			}
+			part.Filenames = addFilename(part.Filenames, file.filename)
+		}
+	}
+}
+
+// attachmentFilename reports whether a Content-Disposition header value
+// marks a download, and returns its filename, if any.
+func attachmentFilename(disposition string) (string, bool) {
+	if disposition == "" {
+		return "", false
+	}
+	kind, params, err := mime.ParseMediaType(disposition)
+	if err != nil {
+		kind, _, _ = strings.Cut(disposition, ";")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+	}
+	if kind != "attachment" {
+		return "", false
+	}
+	return params["filename"], true
+}
+
+// addDownload records an attachment response.
+func (r *ResponseData) addDownload(filename string) {
+	if r.Download == nil {
+		r.Download = &DownloadData{}
+	}
+	r.Download.Count++
+	r.Download.Filenames = addFilename(r.Download.Filenames, filename)
+}
+
+// addFilename adds a filename to the distinct filenames, up to maxFilenames.
+func addFilename(filenames []string, filename string) []string {
+	if filename == "" || len(filenames) >= maxFilenames || slices.Contains(filenames, filename) {
+		return filenames
+	}
+	return append(filenames, filename)
+}
+
+// contentDisposition returns the Content-Disposition header of a response.
+func contentDisposition(headers map[string]string) string {
+	for name, value := range headers {
+		if strings.EqualFold(name, "Content-Disposition") {
+			return value
+		}
+	}
+	return ""
+}
+
+// clone returns a deep copy of the file part.
+func (p *FilePart) clone() *FilePart {
+	c := *p
+	c.ContentTypes = maps.Clone(p.ContentTypes)
+	c.Filenames = slices.Clone(p.Filenames)
+	return &c
+}
+
+// clone returns a deep copy of the download data.
+func (d *DownloadData) clone() *DownloadData {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	c.Filenames = slices.Clone(d.Filenames)
+	return &c
+}
@@ -0,0 +1,54 @@
+package inference
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestFileUploadsAndDownloads(t *testing.T) {
+	multipart := "multipart/form-data; boundary=XyZ"
+	upload := "--XyZ\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nHoliday\r\n" +
+		"--XyZ\r\nContent-Disposition: form-data; name=\"photo\"; filename=\"a.png\"\r\nContent-Type: image/png\r\n\r\n\x89PNG\r\n" +
+		"--XyZ--\r\n"
+	csv := "text/csv"
+	records := []ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/albums", ContentType: &multipart, Body: upload},
+			Response: ir.Response{Status: 201},
+		},
+		{
+			// Truncated bodies keep the parts read before the cut
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/albums", ContentType: &multipart, Body: upload[:60]},
+			Response: ir.Response{Status: 201},
+		},
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/reports/export"},
+			Response: ir.Response{Status: 200, ContentType: &csv, Body: "a,b\n1,2\n",
+				Headers: map[string]string{"Content-Disposition": `attachment; filename="report.csv"`}},
+		},
+	}
+	result := InferFromRecords(records)
+
+	body := result.Endpoints["POST /albums"].RequestBody
+	if body.Multipart != 2 {
+		t.Errorf("multipart bodies = %d, want 2", body.Multipart)
+	}
+	photo := body.FileParts["photo"]
+	if photo == nil || photo.Count != 1 || photo.Multiple || photo.ContentTypes["image/png"] != 1 || !reflect.DeepEqual(photo.Filenames, []string{"a.png"}) {
+		t.Errorf("photo part = %+v", photo)
+	}
+	tree := BuildSchemaTree(body.Schema)
+	if tree.Type != TypeObject || tree.Properties["title"] == nil || tree.Properties["photo"] != nil {
+		t.Errorf("request schema = %+v, want the title field only", tree)
+	}
+
+	resp := result.Endpoints["GET /reports/export"].Responses[200]
+	if resp.Download == nil || resp.Download.Count != 1 || !reflect.DeepEqual(resp.Download.Filenames, []string{"report.csv"}) {
+		t.Errorf("download = %+v", resp.Download)
+	}
+	if len(resp.Body.Types) != 0 {
+		t.Errorf("download body inferred: %v", resp.Body.Types)
+	}
+}
@@ -0,0 +1,184 @@
+package inference
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// FlowOptions configures common call sequence detection.
+type FlowOptions struct {
+	// MinLength is the minimum number of steps in a flow (default 2).
+	MinLength int
+
+	// MaxLength is the maximum number of steps in a flow (default 4).
+	MaxLength int
+
+	// MinSessions is the minimum number of sessions a flow must occur in
+	// (default 2).
+	MinSessions int
+}
+
+// DefaultFlowOptions returns the default flow detection options.
+func DefaultFlowOptions() FlowOptions {
+	return FlowOptions{
+		MinLength:   2,
+		MaxLength:   4,
+		MinSessions: 2,
+	}
+}
+
+// CallFlow is a sequence of endpoint calls observed across sessions.
+type CallFlow struct {
+	Steps    []string `json:"steps"`    // endpoint keys, e.g. "POST /login"
+	Sessions int      `json:"sessions"` // sessions containing the sequence
+	Count    int      `json:"count"`    // total occurrences
+}
+
+// String returns the flow's steps joined by arrows.
+func (f CallFlow) String() string {
+	return strings.Join(f.Steps, " → ")
+}
+
+// SessionCall is a run of consecutive session requests to one endpoint.
+type SessionCall struct {
+	Key          string       // endpoint key, e.g. "GET /orders/{orderId}"
+	PathTemplate string       // path template of the endpoint
+	Record       *ir.IRRecord // first request of the run
+}
+
+// SessionCalls returns the endpoint calls of a session, in order, with
+// repeated consecutive calls to the same endpoint (e.g., polling) collapsed.
+func SessionCalls(session *ir.Session) []SessionCall {
+	var calls []SessionCall
+	for _, record := range session.Records {
+		var pathTemplate string
+		if record.Request.PathTemplate != nil {
+			pathTemplate = *record.Request.PathTemplate
+		} else {
+			pathTemplate, _ = InferPathTemplate(record.Request.Path)
+		}
+		key := EndpointKey(string(record.Request.Method), pathTemplate)
+		if len(calls) == 0 || calls[len(calls)-1].Key != key {
+			calls = append(calls, SessionCall{Key: key, PathTemplate: pathTemplate, Record: record})
+		}
+	}
+	return calls
+}
+
+// SessionSteps returns the endpoint keys of SessionCalls.
+func SessionSteps(session *ir.Session) []string {
+	calls := SessionCalls(session)
+	steps := make([]string, len(calls))
+	for i, call := range calls {
+		steps[i] = call.Key
+	}
+	return steps
+}
+
+// FlowOccurrences returns the runs of session calls matching the flow's
+// steps, in session order.
+func FlowOccurrences(sessions []*ir.Session, flow CallFlow) [][]SessionCall {
+	var occurrences [][]SessionCall
+	for _, session := range sessions {
+		calls := SessionCalls(session)
+		for i := 0; i+len(flow.Steps) <= len(calls); i++ {
+			match := true
+			for j, step := range flow.Steps {
+				if calls[i+j].Key != step {
+					match = false
+					break
+				}
+			}
+			if match {
+				occurrences = append(occurrences, calls[i:i+len(flow.Steps):i+len(flow.Steps)])
+			}
+		}
+	}
+	return occurrences
+}
+
+// CommonFlows finds the call sequences that occur in at least
+// opts.MinSessions sessions. A flow is left out when a longer flow containing
+// it occurs in as many sessions. Flows are ordered by session count, then
+// length, longest first.
+func CommonFlows(sessions []*ir.Session, opts FlowOptions) []CallFlow {
+	defaults := DefaultFlowOptions()
+	if opts.MinLength < 1 {
+		opts.MinLength = defaults.MinLength
+	}
+	if opts.MaxLength < opts.MinLength {
+		opts.MaxLength = max(defaults.MaxLength, opts.MinLength)
+	}
+	if opts.MinSessions < 1 {
+		opts.MinSessions = defaults.MinSessions
+	}
+
+	type stats struct {
+		steps    []string
+		sessions int
+		count    int
+	}
+	flows := make(map[string]*stats)
+	for _, session := range sessions {
+		steps := SessionSteps(session)
+		seen := make(map[string]bool)
+		for n := opts.MinLength; n <= opts.MaxLength; n++ {
+			for i := 0; i+n <= len(steps); i++ {
+				key := strings.Join(steps[i:i+n], "\n")
+				entry, ok := flows[key]
+				if !ok {
+					entry = &stats{steps: steps[i : i+n : i+n]}
+					flows[key] = entry
+				}
+				entry.count++
+				if !seen[key] {
+					seen[key] = true
+					entry.sessions++
+				}
+			}
+		}
+	}
+
+	var common []CallFlow
+	for _, entry := range flows {
+		if entry.sessions >= opts.MinSessions {
+			common = append(common, CallFlow{Steps: entry.steps, Sessions: entry.sessions, Count: entry.count})
+		}
+	}
+
+	// Drop flows subsumed by a longer flow seen in as many sessions
+	kept := make([]CallFlow, 0, len(common))
+	for _, flow := range common {
+		subsumed := slices.ContainsFunc(common, func(other CallFlow) bool {
+			return len(other.Steps) > len(flow.Steps) && other.Sessions == flow.Sessions && containsSteps(other.Steps, flow.Steps)
+		})
+		if !subsumed {
+			kept = append(kept, flow)
+		}
+	}
+
+	sort.Slice(kept, func(i, j int) bool {
+		a, b := kept[i], kept[j]
+		if a.Sessions != b.Sessions {
+			return a.Sessions > b.Sessions
+		}
+		if len(a.Steps) != len(b.Steps) {
+			return len(a.Steps) > len(b.Steps)
+		}
+		return a.String() < b.String()
+	})
+	return kept
+}
+
+// containsSteps reports whether sub occurs contiguously in steps.
+func containsSteps(steps, sub []string) bool {
+	for i := 0; i+len(sub) <= len(steps); i++ {
+		if slices.Equal(steps[i:i+len(sub)], sub) {
+			return true
+		}
+	}
+	return false
+}
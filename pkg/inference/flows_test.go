@@ -0,0 +1,42 @@
+package inference
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestCommonFlows(t *testing.T) {
+	session := func(paths ...string) *ir.Session {
+		s := &ir.Session{}
+		for _, p := range paths {
+			method, path, _ := strings.Cut(p, " ")
+			s.Records = append(s.Records, &ir.IRRecord{Request: ir.Request{Method: ir.RequestMethod(method), Path: path}})
+		}
+		return s
+	}
+	sessions := []*ir.Session{
+		session("POST /login", "GET /orders", "GET /orders/1", "GET /orders/1"),
+		session("POST /login", "GET /orders", "GET /orders/2"),
+		session("POST /login", "GET /profile"),
+	}
+
+	if steps := SessionSteps(sessions[0]); len(steps) != 3 || steps[2] != "GET /orders/{orderId}" {
+		t.Fatalf("expected repeated calls collapsed into templated steps, got %v", steps)
+	}
+
+	flows := CommonFlows(sessions, DefaultFlowOptions())
+	if len(flows) != 1 {
+		t.Fatalf("expected 1 flow, got %d: %v", len(flows), flows)
+	}
+	want := "POST /login → GET /orders → GET /orders/{orderId}"
+	if flows[0].String() != want || flows[0].Sessions != 2 || flows[0].Count != 2 {
+		t.Errorf("expected %q in 2 sessions, got %q in %d (%d times)", want, flows[0], flows[0].Sessions, flows[0].Count)
+	}
+
+	flows = CommonFlows(sessions, FlowOptions{MinLength: 2, MaxLength: 2, MinSessions: 2})
+	if len(flows) != 2 || flows[0].String() != "GET /orders → GET /orders/{orderId}" && flows[0].String() != "POST /login → GET /orders" {
+		t.Errorf("expected both 2-step flows when longer flows are excluded, got %v", flows)
+	}
+}
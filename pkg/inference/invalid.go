@@ -0,0 +1,30 @@
+package inference
+
+import "net/http"
+
+// IsInvalidRequestStatus reports whether a status rejects the request as
+// malformed or invalid: 400 Bad Request or 422 Unprocessable Entity.
+func IsInvalidRequestStatus(status int) bool {
+	return status == http.StatusBadRequest || status == http.StatusUnprocessableEntity
+}
+
+// SetInvalidExamples sets how many rejected transactions are kept per
+// endpoint as EndpointData.InvalidExamples (0 disables). It must be called
+// before records are added.
+func (c *EndpointClusterer) SetInvalidExamples(n int) {
+	c.invalidExamples = n
+}
+
+// addInvalidExample keeps a transaction answered with an invalid request
+// status, unless limit are already kept or one has the same request body.
+func (e *EndpointData) addInvalidExample(limit int, pair *ExamplePair) {
+	if len(e.InvalidExamples) >= limit {
+		return
+	}
+	for _, p := range e.InvalidExamples {
+		if valuesEqual(p.RequestBody, pair.RequestBody) {
+			return
+		}
+	}
+	e.InvalidExamples = append(e.InvalidExamples, pair)
+}
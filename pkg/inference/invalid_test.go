@@ -0,0 +1,52 @@
+package inference
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestInvalidExamples(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{"name": "Bob", "email": "bob@example.com"}},
+			Response: ir.Response{Status: 201, Body: map[string]any{"id": "1"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{"name": ""}},
+			Response: ir.Response{Status: 422, Body: map[string]any{"error": "email is required"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{"name": ""}},
+			Response: ir.Response{Status: 422, Body: map[string]any{"error": "email is required"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: "not json"},
+			Response: ir.Response{Status: 400},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{"name": "Eve"}},
+			Response: ir.Response{Status: 409},
+		},
+	}
+	if got := InferFromRecords(records).Endpoints["POST /users"].InvalidExamples; got != nil {
+		t.Errorf("invalid examples kept by default: %v", got)
+	}
+
+	opts := DefaultEngineOptions()
+	opts.InvalidExamples = 5
+	engine := NewEngine(opts)
+	engine.ProcessRecords(records)
+	invalid := engine.Finalize().Endpoints["POST /users"].InvalidExamples
+	if len(invalid) != 2 {
+		t.Fatalf("expected 2 distinct invalid examples, got %d", len(invalid))
+	}
+	if invalid[0].Status != 422 || !reflect.DeepEqual(invalid[0].RequestBody, map[string]any{"name": ""}) ||
+		!reflect.DeepEqual(invalid[0].ResponseBody, map[string]any{"error": "email is required"}) {
+		t.Errorf("first invalid example = %+v", invalid[0])
+	}
+	if invalid[1].Status != 400 {
+		t.Errorf("second invalid example status = %d", invalid[1].Status)
+	}
+}
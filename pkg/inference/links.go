@@ -0,0 +1,210 @@
+package inference
+
+import (
+	"sort"
+	"strings"
+)
+
+// Link detection bounds, so memory stays flat on large captures.
+const (
+	maxLinkValues          = 100000 // distinct response values remembered
+	maxLinkSourcesPerValue = 8      // response fields remembered per value
+)
+
+// DefaultLinkMinValues is the number of distinct values a response field
+// must share with a path parameter before a link is reported.
+const DefaultLinkMinValues = 2
+
+// LinkDetector detects response fields whose values are later used as path
+// parameters of another endpoint, e.g. the id returned by POST /users used
+// as {userId} in GET /users/{userId}.
+//
+// A single matching value is often a coincidence, such as a count of 7 and
+// item 7, so a link is only reported once MinValues distinct values of the
+// field were used as the parameter.
+type LinkDetector struct {
+	// MinValues is the number of distinct matching values required to
+	// report a link.
+	MinValues int
+
+	sources map[string][]linkSource // response value -> fields holding it
+	counts  map[linkKey]*linkCount
+}
+
+// linkCount counts the observations of a link and the distinct values seen,
+// remembering values only until MinValues is reached.
+type linkCount struct {
+	observations int
+	values       map[string]bool
+}
+
+// DetectedLink is a response field observed as a path parameter value of a
+// later request to another endpoint.
+type DetectedLink struct {
+	Source    string // producing endpoint key, e.g. "POST /users"
+	Status    int    // producing response status
+	Pointer   string // JSON pointer of the response field, e.g. "/id"
+	Target    string // consuming endpoint key, e.g. "GET /users/{userId}"
+	Parameter string // path parameter of the target
+	Count     int    // observations
+}
+
+type linkSource struct {
+	endpoint string
+	status   int
+	pointer  string
+}
+
+type linkKey struct {
+	source    linkSource
+	target    string
+	parameter string
+}
+
+// NewLinkDetector creates a new LinkDetector requiring minValues distinct
+// matching values per link (DefaultLinkMinValues if minValues <= 0).
+func NewLinkDetector(minValues int) *LinkDetector {
+	if minValues <= 0 {
+		minValues = DefaultLinkMinValues
+	}
+	return &LinkDetector{
+		MinValues: minValues,
+		sources:   make(map[string][]linkSource),
+		counts:    make(map[linkKey]*linkCount),
+	}
+}
+
+// DetectFromRecord matches a request's path parameters against the response
+// fields seen so far, then remembers the fields of a 2xx response (as
+// returned by BodyValues). Only fields named like the parameter or id-like
+// fields of other endpoints are matched.
+func (d *LinkDetector) DetectFromRecord(endpoint string, pathParams map[string]string, status int, responseValues map[string][]string) {
+	for name, value := range pathParams {
+		for _, source := range d.sources[value] {
+			if source.endpoint != endpoint && FieldRank(source.pointer, name) < 2 {
+				d.count(linkKey{source: source, target: endpoint, parameter: name}, value)
+			}
+		}
+	}
+
+	if status < 200 || status >= 300 {
+		return
+	}
+	for value, pointers := range responseValues {
+		existing, ok := d.sources[value]
+		if !ok && len(d.sources) >= maxLinkValues {
+			continue
+		}
+		for _, pointer := range pointers {
+			source := linkSource{endpoint: endpoint, status: status, pointer: pointer}
+			if len(existing) >= maxLinkSourcesPerValue {
+				break
+			}
+			if !containsLinkSource(existing, source) {
+				existing = append(existing, source)
+			}
+		}
+		d.sources[value] = existing
+	}
+}
+
+// count records an observation of a link with a matching value.
+func (d *LinkDetector) count(key linkKey, value string) {
+	c, ok := d.counts[key]
+	if !ok {
+		c = &linkCount{values: make(map[string]bool)}
+		d.counts[key] = c
+	}
+	c.observations++
+	if len(c.values) < d.MinValues {
+		c.values[value] = true
+	}
+}
+
+// GetLinks returns the detected links with at least MinValues distinct
+// matching values. For each producing response and target parameter, the
+// most observed field wins, preferring fields named like the parameter, then
+// id-like fields, then shorter pointers.
+func (d *LinkDetector) GetLinks() []*DetectedLink {
+	best := make(map[linkKey]*DetectedLink) // keyed without the pointer
+	for key, c := range d.counts {
+		if len(c.values) < d.MinValues {
+			continue
+		}
+		count := c.observations
+		group := linkKey{
+			source:    linkSource{endpoint: key.source.endpoint, status: key.source.status},
+			target:    key.target,
+			parameter: key.parameter,
+		}
+		current, ok := best[group]
+		if ok && !betterLink(key.source.pointer, count, current, key.parameter) {
+			continue
+		}
+		best[group] = &DetectedLink{
+			Source:    key.source.endpoint,
+			Status:    key.source.status,
+			Pointer:   key.source.pointer,
+			Target:    key.target,
+			Parameter: key.parameter,
+			Count:     count,
+		}
+	}
+
+	links := make([]*DetectedLink, 0, len(best))
+	for _, link := range best {
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		a, b := links[i], links[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Parameter < b.Parameter
+	})
+	return links
+}
+
+// betterLink reports whether a field observed count times beats current.
+func betterLink(pointer string, count int, current *DetectedLink, parameter string) bool {
+	if count != current.Count {
+		return count > current.Count
+	}
+	if r, cr := FieldRank(pointer, parameter), FieldRank(current.Pointer, parameter); r != cr {
+		return r < cr
+	}
+	if len(pointer) != len(current.Pointer) {
+		return len(pointer) < len(current.Pointer)
+	}
+	return pointer < current.Pointer
+}
+
+// FieldRank ranks how well the JSON pointer of a value's field fits a
+// parameter: 0 when the field is named like the parameter (case-insensitive),
+// 1 when it is id-like ("id", "userId", "user_id"), and 2 otherwise.
+func FieldRank(pointer, parameter string) int {
+	field := strings.ToLower(pointer[strings.LastIndex(pointer, "/")+1:])
+	switch {
+	case field == strings.ToLower(parameter):
+		return 0
+	case strings.HasSuffix(field, "id"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func containsLinkSource(sources []linkSource, source linkSource) bool {
+	for _, s := range sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
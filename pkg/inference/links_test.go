@@ -0,0 +1,55 @@
+package inference
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestLinkDetector(t *testing.T) {
+	d := NewLinkDetector(1)
+	d.DetectFromRecord("POST /users", nil, 201, BodyValues(map[string]interface{}{
+		"id":    float64(42),
+		"count": float64(7),
+		"owner": map[string]interface{}{"userId": float64(42)},
+	}))
+	d.DetectFromRecord("GET /users/{userId}", map[string]string{"userId": "42"}, 200, nil)
+	d.DetectFromRecord("GET /items/{itemId}", map[string]string{"itemId": "7"}, 200, nil)
+	// Error responses are not remembered
+	d.DetectFromRecord("POST /orders", nil, 400, BodyValues(map[string]interface{}{"id": float64(9)}))
+	d.DetectFromRecord("GET /orders/{orderId}", map[string]string{"orderId": "9"}, 200, nil)
+
+	links := d.GetLinks()
+	if len(links) != 1 {
+		t.Fatalf("expected 1 link, got %d", len(links))
+	}
+	link := links[0]
+	// Both /id and /owner/userId hold the value; the field named like the parameter wins
+	if link.Source != "POST /users" || link.Status != 201 || link.Target != "GET /users/{userId}" ||
+		link.Parameter != "userId" || link.Pointer != "/owner/userId" {
+		t.Errorf("unexpected link %+v", link)
+	}
+}
+
+func TestLinkDetectorMinValues(t *testing.T) {
+	d := NewLinkDetector(0)
+	if d.MinValues != DefaultLinkMinValues {
+		t.Fatalf("expected default MinValues %d, got %d", DefaultLinkMinValues, d.MinValues)
+	}
+
+	// A page size that happens to equal a product ID, used twice
+	d.DetectFromRecord("GET /orders", nil, 200, BodyValues(map[string]interface{}{"pageId": float64(25)}))
+	d.DetectFromRecord("GET /products/{productId}", map[string]string{"productId": "25"}, 200, nil)
+	d.DetectFromRecord("GET /products/{productId}", map[string]string{"productId": "25"}, 200, nil)
+	if links := d.GetLinks(); len(links) != 0 {
+		t.Fatalf("expected no link from a single coincidental value, got %+v", links[0])
+	}
+
+	for _, id := range []float64{1, 2} {
+		d.DetectFromRecord("POST /users", nil, 201, BodyValues(map[string]interface{}{"id": id}))
+		d.DetectFromRecord("GET /users/{userId}", map[string]string{"userId": strconv.Itoa(int(id))}, 200, nil)
+	}
+	links := d.GetLinks()
+	if len(links) != 1 || links[0].Source != "POST /users" || links[0].Count != 2 {
+		t.Fatalf("expected a POST /users link from 2 distinct values, got %+v", links)
+	}
+}
@@ -0,0 +1,69 @@
+package inference
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PathStyleMatrix is the serialization style of matrix path parameters
+// (/items;color=red), documented as "{color}" after the segment they follow.
+const PathStyleMatrix = "matrix"
+
+// matrixParam is a parameter of a path segment, such as color in
+// /items;color=red.
+type matrixParam struct {
+	segment int // index of the segment the parameter follows
+	name    string
+	value   string
+}
+
+// splitMatrixParams removes matrix parameters (;key=value or ;key) from the
+// segments of a path, returning the path without them and the parameters.
+func splitMatrixParams(path string) (string, []matrixParam) {
+	if !strings.Contains(path, ";") {
+		return path, nil
+	}
+	segments := strings.Split(path, "/")
+	var params []matrixParam
+	for i, segment := range segments {
+		base, rest, found := strings.Cut(segment, ";")
+		if !found {
+			continue
+		}
+		segments[i] = base
+		for _, pair := range strings.Split(rest, ";") {
+			name, value, _ := strings.Cut(pair, "=")
+			if name == "" {
+				continue
+			}
+			// Segment indexes are counted without the leading empty segment
+			params = append(params, matrixParam{segment: i - 1, name: name, value: value})
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// addMatrixParams appends matrix parameters to the segments of a template
+// they follow, as in /items{color}, and adds their values to params. It
+// returns the template and the names of the matrix parameters, which are
+// numbered if they clash with other parameters.
+func addMatrixParams(template string, params map[string]string, matrix []matrixParam) (string, map[string]bool) {
+	segments := splitPathSegments(template)
+	names := make(map[string]bool, len(matrix))
+	for _, m := range matrix {
+		if m.segment < 0 || m.segment >= len(segments) {
+			continue
+		}
+		name := m.name
+		for n := 2; ; n++ {
+			if _, used := params[name]; !used {
+				break
+			}
+			name = m.name + strconv.Itoa(n)
+		}
+		params[name] = m.value
+		names[name] = true
+		segments[m.segment] += "{" + name + "}"
+	}
+	return "/" + strings.Join(segments, "/"), names
+}
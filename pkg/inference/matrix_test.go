@@ -0,0 +1,47 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestMatrixParams(t *testing.T) {
+	engine := NewEngine(DefaultEngineOptions())
+	for _, path := range []string{"/items;color=red;size=L/42", "/items;color=blue;size=M/7", "/cars;year=2020/12;details"} {
+		engine.ProcessRecord(&ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: path},
+			Response: ir.Response{Status: 200},
+		})
+	}
+	result := engine.Finalize()
+
+	items := result.Endpoints["GET /items{color}{size}/{itemId}"]
+	if items == nil {
+		t.Fatalf("missing matrix endpoint, got %v", result.Endpoints)
+	}
+	if items.RequestCount != 2 {
+		t.Errorf("expected 2 requests, got %d", items.RequestCount)
+	}
+	for _, name := range []string{"color", "size"} {
+		param := items.PathParams[name]
+		if param == nil || param.Style != PathStyleMatrix || !param.Required {
+			t.Errorf("%s = %+v, want a required matrix parameter", name, param)
+		}
+	}
+	if got := items.PathParams["color"].Examples; !slices.Equal(got, []any{"red", "blue"}) {
+		t.Errorf("color examples = %v", got)
+	}
+	if items.PathParams["itemId"].Style != "" {
+		t.Errorf("itemId should be a simple path parameter, got style %q", items.PathParams["itemId"].Style)
+	}
+
+	if result.Endpoints["GET /cars{year}/{carId}{details}"] == nil {
+		t.Errorf("expected a flag-style matrix parameter without a value, got %v", result.Endpoints)
+	}
+
+	if template, params := InferPathTemplate("/items;color=red/42"); template != "/items{color}/{itemId}" || params["color"] != "red" {
+		t.Errorf("InferPathTemplate = %q, %v", template, params)
+	}
+}
@@ -0,0 +1,187 @@
+package inference
+
+import (
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxExampleStringLength is the default length (in bytes) beyond
+	// which string examples are truncated.
+	DefaultMaxExampleStringLength = 1024
+
+	// maxInternedStringLength bounds the strings that are interned; longer
+	// strings are rarely repeated and would only grow the intern table.
+	maxInternedStringLength = 128
+
+	// maxInternedStrings bounds the size of the intern table.
+	maxInternedStrings = 100000
+
+	// truncationSuffix marks a truncated string example.
+	truncationSuffix = "..."
+)
+
+// ExampleMemory bounds the memory held by example values across the
+// SchemaStores of an inference run. It interns repeated strings, truncates
+// long strings, and enforces a global byte budget.
+//
+// When the budget is exceeded, the store receiving a new value first evicts
+// its own surplus examples (every path keeps at least one); if that does not
+// free enough space, the new example is dropped unless its path has none.
+// Eviction is local to the receiving store so stores never lock each other.
+type ExampleMemory struct {
+	mu              sync.Mutex
+	maxStringLength int
+	budget          int64
+	used            int64
+	evicted         int
+	interned        map[string]string
+}
+
+// NewExampleMemory creates an ExampleMemory. maxStringLength <= 0 disables
+// truncation and budget <= 0 disables the byte budget.
+func NewExampleMemory(maxStringLength int, budget int64) *ExampleMemory {
+	return &ExampleMemory{
+		maxStringLength: maxStringLength,
+		budget:          budget,
+		interned:        make(map[string]string),
+	}
+}
+
+// Used returns the approximate number of bytes held by examples.
+func (m *ExampleMemory) Used() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.used
+}
+
+// Evicted returns the number of examples evicted or dropped to stay within the budget.
+func (m *ExampleMemory) Evicted() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.evicted
+}
+
+// prepare truncates and interns a string value before it is stored.
+func (m *ExampleMemory) prepare(value any) any {
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	if m.maxStringLength > 0 && len(str) > m.maxStringLength {
+		cut := m.maxStringLength
+		for cut > 0 && !utf8.RuneStart(str[cut]) {
+			cut--
+		}
+		// Copy so the truncated example doesn't pin the original string
+		return string([]byte(str[:cut])) + truncationSuffix
+	}
+
+	if len(str) > maxInternedStringLength {
+		return str
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if interned, ok := m.interned[str]; ok {
+		return interned
+	}
+	if len(m.interned) < maxInternedStrings {
+		m.interned[str] = str
+	}
+	return str
+}
+
+// reserve accounts for n bytes, reporting whether they fit in the budget.
+// With force, the bytes are accounted even if they exceed the budget.
+func (m *ExampleMemory) reserve(n int64, force bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !force && m.budget > 0 && m.used+n > m.budget {
+		return false
+	}
+	m.used += n
+	return true
+}
+
+// release returns bytes freed by count evicted examples.
+func (m *ExampleMemory) release(n int64, count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.used -= n
+	m.evicted += count
+}
+
+// dropped records an example that was not stored due to the budget.
+func (m *ExampleMemory) dropped() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evicted++
+}
+
+// exampleSize approximates the memory held by an example value.
+func exampleSize(value any) int64 {
+	const overhead = 16 // interface header
+	switch v := value.(type) {
+	case string:
+		return overhead + int64(len(v))
+	case []any:
+		size := int64(overhead)
+		for _, item := range v {
+			size += exampleSize(item)
+		}
+		return size
+	case map[string]any:
+		size := int64(overhead)
+		for k, item := range v {
+			size += int64(len(k)) + exampleSize(item)
+		}
+		return size
+	default:
+		return overhead + 8
+	}
+}
+
+// reserveExample accounts for a new example at path, evicting this store's
+// surplus examples if the budget is exceeded. It reports whether the example
+// should be stored (no lock, internal use).
+func (s *SchemaStore) reserveExample(path string, value any) bool {
+	size := exampleSize(value)
+	if s.memory.reserve(size, false) {
+		return true
+	}
+
+	if freed, count := s.evictSurplus(); count > 0 {
+		s.memory.release(freed, count)
+		if s.memory.reserve(size, false) {
+			return true
+		}
+	}
+
+	// Always keep one example per path
+	if len(s.Examples[path]) == 0 {
+		return s.memory.reserve(size, true)
+	}
+	s.memory.dropped()
+	return false
+}
+
+// evictSurplus trims every path to its first example, returning the bytes
+// freed and number of examples evicted (no lock, internal use).
+func (s *SchemaStore) evictSurplus() (int64, int) {
+	var freed int64
+	count := 0
+	for path, examples := range s.Examples {
+		if len(examples) <= 1 {
+			continue
+		}
+		for _, ex := range examples[1:] {
+			freed += exampleSize(ex)
+			count++
+		}
+		// Copy so the evicted examples can be collected
+		s.Examples[path] = []any{examples[0]}
+	}
+	return freed, count
+}
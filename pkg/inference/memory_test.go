@@ -0,0 +1,64 @@
+package inference
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestExampleMemory(t *testing.T) {
+	t.Run("truncates long strings after format detection", func(t *testing.T) {
+		store := NewSchemaStore()
+		store.SetExampleMemory(NewExampleMemory(16, 0))
+
+		blob := "https://example.com/" + strings.Repeat("a", 100)
+		ProcessBody(store, map[string]any{"url": blob})
+
+		if store.Formats["url"] != FormatURI {
+			t.Errorf("expected uri format from full value, got %q", store.Formats["url"])
+		}
+		got, _ := store.Examples["url"][0].(string)
+		if len(got) != 16+len(truncationSuffix) || !strings.HasSuffix(got, truncationSuffix) {
+			t.Errorf("expected truncated example, got %q", got)
+		}
+	})
+
+	t.Run("budget evicts surplus but keeps one example per path", func(t *testing.T) {
+		memory := NewExampleMemory(0, 200)
+		store := NewSchemaStore()
+		store.SetExampleMemory(memory)
+
+		for i := 0; i < 10; i++ {
+			ProcessBody(store, map[string]any{
+				"a": "value-a-" + strconv.Itoa(i),
+				"b": "value-b-" + strconv.Itoa(i),
+			})
+		}
+
+		if len(store.Examples["a"]) == 0 || len(store.Examples["b"]) == 0 {
+			t.Fatalf("expected every path to keep an example, got %v", store.Examples)
+		}
+		if memory.Evicted() == 0 {
+			t.Error("expected examples to be evicted")
+		}
+		if memory.Used() > 200 {
+			t.Errorf("expected usage within budget, got %d", memory.Used())
+		}
+	})
+
+	t.Run("engine applies default truncation", func(t *testing.T) {
+		engine := NewEngine(DefaultEngineOptions())
+		engine.ProcessRecord(&ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/blobs"},
+			Response: ir.Response{Status: 200, Body: map[string]any{"data": strings.Repeat("x", 5000)}},
+		})
+		result := engine.Finalize()
+
+		example, _ := result.Endpoints["GET /blobs"].Responses[200].Body.Examples["data"][0].(string)
+		if len(example) > DefaultMaxExampleStringLength+len(truncationSuffix) {
+			t.Errorf("expected truncated example, got length %d", len(example))
+		}
+	})
+}
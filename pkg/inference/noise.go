@@ -0,0 +1,167 @@
+package inference
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// NoiseMode controls how suspected bot/scanner traffic is handled.
+type NoiseMode string
+
+const (
+	// NoiseModeOff disables noise filtering (default).
+	NoiseModeOff NoiseMode = ""
+
+	// NoiseModeDrop skips suspicious records before inference.
+	NoiseModeDrop NoiseMode = "drop"
+
+	// NoiseModeTag keeps suspicious records but counts them on the endpoint
+	// (EndpointData.NoiseCount) so the generator can flag them.
+	NoiseModeTag NoiseMode = "tag"
+)
+
+// Noise reasons reported by NoiseDetector.
+const (
+	NoiseReasonScannerAgent = "scanner-user-agent"
+	NoiseReasonBotAgent     = "bot-user-agent"
+	NoiseReasonScanPath     = "scan-path"
+	NoiseReasonFuzzPayload  = "fuzz-payload"
+	NoiseReasonFuzzSequence = "fuzz-sequence"
+)
+
+// Known vulnerability scanner User-Agent fragments (lowercase).
+var scannerAgents = []string{
+	"sqlmap", "nikto", "nmap", "masscan", "zgrab", "nuclei", "dirb",
+	"gobuster", "wpscan", "acunetix", "nessus", "openvas", "wfuzz",
+	"ffuf", "feroxbuster", "netsparker", "qualys", "censys", "shodan",
+}
+
+// Crawler User-Agent tokens (lowercase), matched as whole words so that
+// names merely containing them, such as "Abbott" or "robotics-sdk", are not
+// flagged: known crawlers, then generic self-descriptions.
+var botAgents = []string{
+	"googlebot", "bingbot", "slurp", "duckduckbot", "baiduspider", "yandexbot",
+	"applebot", "facebookexternalhit", "twitterbot", "linkedinbot", "slackbot",
+	"discordbot", "ahrefsbot", "semrushbot", "mj12bot", "dotbot", "petalbot",
+	"bytespider", "gptbot", "ccbot", "amazonbot", "adsbot-google", "mediapartners-google",
+	"bot", "crawler", "spider",
+}
+
+// botAgentPattern matches a botAgents token delimited by non-alphanumeric
+// characters or the ends of the User-Agent.
+var botAgentPattern = regexp.MustCompile(`(^|[^a-z0-9])(` + strings.Join(quoteAll(botAgents), "|") + `)($|[^a-z0-9])`)
+
+// Path prefixes commonly probed by vulnerability scanners (lowercase).
+var scanPathPrefixes = []string{
+	"/wp-admin", "/wp-login", "/wp-content", "/wp-includes", "/xmlrpc.php",
+	"/.env", "/.git", "/.svn", "/.hg", "/.aws", "/.ds_store", "/.htaccess",
+	"/phpmyadmin", "/pma", "/myadmin", "/cgi-bin", "/admin.php", "/config.php",
+	"/server-status", "/vendor/phpunit", "/boaform", "/hnap1", "/solr/admin",
+	"/owa/", "/autodiscover", "/telescope", "/console/login",
+}
+
+// Payload fragments typical of fuzzing (path traversal, injection probes).
+var fuzzPayloadPattern = regexp.MustCompile(`(?i)(\.\./|\.\.%2f|%00|%0d%0a|<script|union(\s|\+|%20)+select|' or '|/etc/passwd|\$\{jndi:)`)
+
+// NoiseDetector identifies bot and scanner traffic.
+type NoiseDetector struct {
+	// FuzzThreshold is the number of consecutive 404 responses for a single
+	// User-Agent after which further 404s are treated as a fuzzing sequence.
+	FuzzThreshold int
+
+	// FuzzEmptyAgent also treats 404 sequences of requests without a
+	// User-Agent as fuzzing. Many API clients send none, so it is off by
+	// default.
+	FuzzEmptyAgent bool
+
+	consecutiveMisses map[string]int
+}
+
+// NewNoiseDetector creates a new NoiseDetector with default settings.
+func NewNoiseDetector() *NoiseDetector {
+	return &NoiseDetector{
+		FuzzThreshold:     5,
+		consecutiveMisses: make(map[string]int),
+	}
+}
+
+// Check reports whether a record looks like bot or scanner traffic and why.
+// Records should be checked in capture order for fuzz sequence detection.
+func (d *NoiseDetector) Check(record *ir.IRRecord) (bool, string) {
+	agent := strings.ToLower(headerValue(record.Request.Headers, "user-agent"))
+
+	for _, fragment := range scannerAgents {
+		if strings.Contains(agent, fragment) {
+			return true, NoiseReasonScannerAgent
+		}
+	}
+
+	path := strings.ToLower(record.Request.Path)
+	for _, prefix := range scanPathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true, NoiseReasonScanPath
+		}
+	}
+
+	if fuzzPayloadPattern.MatchString(record.Request.Path) || queryHasFuzzPayload(record.Request.Query) {
+		return true, NoiseReasonFuzzPayload
+	}
+
+	if d.isFuzzSequence(agent, record.Response.Status) {
+		return true, NoiseReasonFuzzSequence
+	}
+
+	if botAgentPattern.MatchString(agent) {
+		return true, NoiseReasonBotAgent
+	}
+
+	return false, ""
+}
+
+// isFuzzSequence tracks consecutive 404s per User-Agent. Requests without a
+// User-Agent are tracked only with FuzzEmptyAgent.
+func (d *NoiseDetector) isFuzzSequence(agent string, status int) bool {
+	if agent == "" && !d.FuzzEmptyAgent {
+		return false
+	}
+	if status != 404 {
+		d.consecutiveMisses[agent] = 0
+		return false
+	}
+	d.consecutiveMisses[agent]++
+	return d.FuzzThreshold > 0 && d.consecutiveMisses[agent] > d.FuzzThreshold
+}
+
+// quoteAll quotes strings for use as regular expression alternatives.
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = regexp.QuoteMeta(v)
+	}
+	return quoted
+}
+
+// queryHasFuzzPayload checks string query values for fuzzing payloads.
+func queryHasFuzzPayload(query map[string]any) bool {
+	for _, v := range query {
+		if s, ok := v.(string); ok && fuzzPayloadPattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerValue returns a header value using a case-insensitive name match.
+func headerValue(headers map[string]string, name string) string {
+	if v, ok := headers[name]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package inference
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestNoiseDetectorBotAgents(t *testing.T) {
+	for agent, want := range map[string]bool{
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)": true,
+		"Mozilla/5.0 (compatible; bingbot/2.0)":                                    true,
+		"Slackbot-LinkExpanding 1.0":                                               true,
+		"AcmeCrawler crawler/1.0":                                                  true,
+		"my-bot/1.0":                                                               true,
+		"Abbott-Diagnostics/3.2":                                                   false,
+		"robotics-sdk/2.0 (Go)":                                                    false,
+		"Talbot HTTP client":                                                       false,
+		"okhttp/4.12.0":                                                            false,
+	} {
+		record := ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/users", Headers: map[string]string{"User-Agent": agent}},
+			Response: ir.Response{Status: 200},
+		}
+		noisy, reason := NewNoiseDetector().Check(&record)
+		if noisy != want {
+			t.Errorf("Check(%q) = %v (%s), want %v", agent, noisy, reason, want)
+		}
+	}
+}
+
+func TestNoiseDetectorEmptyAgent(t *testing.T) {
+	misses := func(d *NoiseDetector) int {
+		flagged := 0
+		for i := 0; i < 10; i++ {
+			record := ir.IRRecord{
+				Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/orders/missing"},
+				Response: ir.Response{Status: 404},
+			}
+			if noisy, _ := d.Check(&record); noisy {
+				flagged++
+			}
+		}
+		return flagged
+	}
+
+	if got := misses(NewNoiseDetector()); got != 0 {
+		t.Errorf("flagged %d 404s without a User-Agent by default, want 0", got)
+	}
+	d := NewNoiseDetector()
+	d.FuzzEmptyAgent = true
+	if got := misses(d); got != 5 {
+		t.Errorf("flagged %d 404s without a User-Agent with FuzzEmptyAgent, want 5", got)
+	}
+}
+
+func TestNoiseFiltering(t *testing.T) {
+	newRecord := func(path, agent string, status int) ir.IRRecord {
+		return ir.IRRecord{
+			Request: ir.Request{
+				Method:  ir.RequestMethodGET,
+				Path:    path,
+				Headers: map[string]string{"user-agent": agent},
+			},
+			Response: ir.Response{Status: status},
+		}
+	}
+	records := []ir.IRRecord{
+		newRecord("/users", "Mozilla/5.0", 200),
+		newRecord("/wp-admin/setup.php", "Mozilla/5.0", 404),
+		newRecord("/.env", "Mozilla/5.0", 404),
+		newRecord("/users", "sqlmap/1.7", 200),
+		newRecord("/files/..%2f..%2fetc", "Mozilla/5.0", 400),
+	}
+	for i := 0; i < 8; i++ {
+		records = append(records, newRecord("/probe"+strconv.Itoa(i), "fuzzer", 404))
+	}
+
+	opts := DefaultEngineOptions()
+	opts.NoiseMode = NoiseModeDrop
+	engine := NewEngine(opts)
+	engine.ProcessRecords(records)
+	result := engine.Finalize()
+
+	if result.Endpoints["GET /users"] == nil || result.Endpoints["GET /users"].RequestCount != 1 {
+		t.Errorf("expected GET /users with 1 non-scanner request, got %+v", result.Endpoints["GET /users"])
+	}
+	if result.FilteredRecords[NoiseReasonScanPath] != 2 {
+		t.Errorf("expected 2 scan-path records, got %d", result.FilteredRecords[NoiseReasonScanPath])
+	}
+	if result.FilteredRecords[NoiseReasonScannerAgent] != 1 {
+		t.Errorf("expected 1 scanner-agent record, got %d", result.FilteredRecords[NoiseReasonScannerAgent])
+	}
+	if result.FilteredRecords[NoiseReasonFuzzPayload] != 1 {
+		t.Errorf("expected 1 fuzz-payload record, got %d", result.FilteredRecords[NoiseReasonFuzzPayload])
+	}
+	if result.FilteredRecords[NoiseReasonFuzzSequence] != 3 {
+		t.Errorf("expected 3 fuzz-sequence records, got %d", result.FilteredRecords[NoiseReasonFuzzSequence])
+	}
+
+	opts.NoiseMode = NoiseModeTag
+	engine = NewEngine(opts)
+	engine.ProcessRecords(records)
+	result = engine.Finalize()
+
+	if users := result.Endpoints["GET /users"]; users == nil || users.NoiseCount != 1 || users.RequestCount != 2 {
+		t.Errorf("expected tagged GET /users (1 of 2 noisy), got %+v", users)
+	}
+	if len(result.FilteredRecords) != 0 {
+		t.Errorf("expected no dropped records in tag mode, got %v", result.FilteredRecords)
+	}
+}
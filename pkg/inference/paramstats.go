@@ -0,0 +1,77 @@
+package inference
+
+import (
+	"maps"
+	"math"
+	"strconv"
+)
+
+// maxStatsValues is the most distinct values of a path parameter the
+// statistics track; later values only update the numeric and length ranges.
+const maxStatsValues = 1000
+
+// ParamStats summarizes the distinct observed values of a path parameter,
+// such as {userId} always being numeric and between 1 and 98765. Every
+// statistic is over distinct values, so replaying or duplicating records
+// leaves it unchanged.
+type ParamStats struct {
+	Distinct       int            // distinct values, up to maxStatsValues
+	DistinctCapped bool           // more than maxStatsValues distinct values were seen
+	Numeric        bool           // every value was an integer or decimal number
+	Min, Max       float64        // range of the values, zero unless Numeric
+	MinLength      int            // shortest value, in bytes
+	MaxLength      int            // longest value, in bytes
+	Formats        map[string]int // format (uuid, date, ...) -> distinct values with it
+
+	values map[string]bool
+}
+
+// AddValue records an observed value.
+func (s *ParamStats) AddValue(value string) {
+	n, err := strconv.ParseFloat(value, 64)
+	numeric := err == nil && !math.IsInf(n, 0) && !math.IsNaN(n)
+	if s.values == nil {
+		s.values = make(map[string]bool)
+		s.Numeric = numeric
+		s.Min, s.Max = n, n
+		s.MinLength = len(value)
+	}
+
+	s.MinLength = min(s.MinLength, len(value))
+	s.MaxLength = max(s.MaxLength, len(value))
+	switch {
+	case !numeric:
+		s.Numeric = false
+		s.Min, s.Max = 0, 0
+	case s.Numeric:
+		s.Min = min(s.Min, n)
+		s.Max = max(s.Max, n)
+	}
+
+	if s.values[value] {
+		return
+	}
+	if len(s.values) == maxStatsValues {
+		s.DistinctCapped = true
+		return
+	}
+	s.values[value] = true
+	s.Distinct++
+	if format := detectFormat(value); format != "" {
+		if s.Formats == nil {
+			s.Formats = make(map[string]int)
+		}
+		s.Formats[format]++
+	}
+}
+
+// clone returns a deep copy of the statistics.
+func (s *ParamStats) clone() *ParamStats {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	c.Formats = maps.Clone(s.Formats)
+	c.values = maps.Clone(s.values)
+	return &c
+}
@@ -0,0 +1,41 @@
+package inference
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestPathParamStats(t *testing.T) {
+	var records []ir.IRRecord
+	for _, path := range []string{"/users/12", "/users/4096", "/users/12", "/users/980"} {
+		records = append(records, ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: path},
+			Response: ir.Response{Status: 200},
+		})
+	}
+	stats := InferFromRecords(records).Endpoints["GET /users/{userId}"].PathParams["userId"].Stats
+	want := ParamStats{Distinct: 3, Numeric: true, Min: 12, Max: 4096, MinLength: 2, MaxLength: 4}
+	if stats == nil || stats.Distinct != want.Distinct || stats.Numeric != want.Numeric || stats.Min != want.Min ||
+		stats.Max != want.Max || stats.MinLength != want.MinLength || stats.MaxLength != want.MaxLength {
+		t.Fatalf("stats = %+v, want %+v", stats, want)
+	}
+
+	// Replaying the records leaves the statistics unchanged
+	replayed := InferFromRecords(append(records, records...)).Endpoints["GET /users/{userId}"].PathParams["userId"].Stats
+	if !reflect.DeepEqual(replayed, stats) {
+		t.Errorf("replayed stats = %+v, want %+v", replayed, stats)
+	}
+
+	var mixed ParamStats
+	for _, value := range []string{"550e8400-e29b-41d4-a716-446655440000", "7", "2024-01-15"} {
+		mixed.AddValue(value)
+	}
+	if mixed.Numeric || mixed.Min != 0 || mixed.Max != 0 {
+		t.Errorf("mixed values: numeric %v, range %v..%v", mixed.Numeric, mixed.Min, mixed.Max)
+	}
+	if !reflect.DeepEqual(mixed.Formats, map[string]int{FormatUUID: 1, FormatDate: 1}) {
+		t.Errorf("formats = %v", mixed.Formats)
+	}
+}
@@ -45,6 +45,8 @@ const (
 	SegmentBase64ID
 	SegmentDate
 	SegmentUnknownID
+	SegmentLocale
+	SegmentTenant
 )
 
 // PathInferrer handles path template inference.
@@ -52,6 +54,10 @@ type PathInferrer struct {
 	// resourceNames maps parent segments to parameter names
 	// e.g., "users" -> "userId", "posts" -> "postId"
 	resourceNames map[string]string
+
+	// segments selects locale and tenant segments documented as parameters
+	segments PathSegmentOptions
+	tenants  map[string]bool
 }
 
 // NewPathInferrer creates a new PathInferrer with default settings.
@@ -332,6 +338,12 @@ func (p *PathInferrer) InferTemplate(path string) (template string, params map[s
 		return "/", params
 	}
 
+	// Segments are classified and stored decoded; literals are re-encoded
+	decoded := make([]string, len(segments))
+	for i, segment := range segments {
+		decoded[i] = decodeSegment(segment)
+	}
+
 	result := make([]string, len(segments))
 	paramCounts := make(map[string]int) // Track param name usage to avoid duplicates
 
@@ -341,19 +353,19 @@ func (p *PathInferrer) InferTemplate(path string) (template string, params map[s
 			continue
 		}
 
-		segType := p.classifySegment(segment)
+		segType := p.classifyInContext(decoded, i)
 
 		if segType == SegmentLiteral {
-			result[i] = segment
+			result[i] = templateSegment(segment)
 			continue
 		}
 
 		// Determine parameter name
-		paramName := p.inferParamName(segments, i, segType, paramCounts)
+		paramName := p.inferParamName(decoded, i, segType, paramCounts)
 		paramCounts[paramName]++
 
 		// Store the actual value
-		params[paramName] = segment
+		params[paramName] = decoded[i]
 
 		// Replace with parameter placeholder
 		result[i] = "{" + paramName + "}"
@@ -370,9 +382,17 @@ func (p *PathInferrer) classifySegment(segment string) SegmentType {
 		return SegmentLiteral
 	}
 
+	// Configured tenants and locales with a region or script
+	if p.tenants[segment] {
+		return SegmentTenant
+	}
+	if p.segments.Locales && localeSegment(segment, false) {
+		return SegmentLocale
+	}
+
 	// Check for known patterns
 	switch {
-	case uuidPathPattern.MatchString(segment):
+	case uuidPathPattern.MatchString(segment), bracedUUID(segment):
 		return SegmentUUID
 	case objectIdPattern.MatchString(segment):
 		return SegmentObjectID
@@ -395,6 +415,9 @@ func (p *PathInferrer) classifySegment(segment string) SegmentType {
 		return SegmentDate
 	case base64IdPattern.MatchString(segment):
 		return SegmentBase64ID
+	case emailPattern.MatchString(segment):
+		// Email addresses, usually percent-encoded as user%40example.com
+		return SegmentUnknownID
 	default:
 		// Check if it looks like a slug with numbers (e.g., "post-123-title")
 		if looksLikeIDSegment(segment) {
@@ -422,6 +445,15 @@ func looksLikeIDSegment(segment string) bool {
 
 // inferParamName determines the parameter name based on context.
 func (p *PathInferrer) inferParamName(segments []string, idx int, segType SegmentType, counts map[string]int) string {
+	// Locales and configured tenants are named for what they hold, unless
+	// a tenant collection names them
+	switch {
+	case segType == SegmentLocale:
+		return numberedName(localeParamName, counts)
+	case segType == SegmentTenant && p.tenants[segments[idx]] && !p.tenantMember(segments, idx):
+		return numberedName(tenantParamName, counts)
+	}
+
 	// Try to get name from previous segment (resource name)
 	if idx > 0 {
 		prevSegment := strings.ToLower(segments[idx-1])
@@ -432,13 +464,15 @@ func (p *PathInferrer) inferParamName(segments []string, idx int, segType Segmen
 			return paramName
 		}
 
-		// Generate name from previous segment
-		singular := singularize(prevSegment)
-		paramName := singular + "Id"
-		if counts[paramName] > 0 {
-			return paramName + strconv.Itoa(counts[paramName]+1)
+		// Generate name from previous segment, if it is ASCII-safe
+		if isASCII(prevSegment) {
+			singular := singularize(prevSegment)
+			paramName := singular + "Id"
+			if counts[paramName] > 0 {
+				return paramName + strconv.Itoa(counts[paramName]+1)
+			}
+			return paramName
 		}
-		return paramName
 	}
 
 	// Fallback based on segment type
@@ -464,6 +498,14 @@ func (p *PathInferrer) inferParamName(segments []string, idx int, segType Segmen
 	}
 }
 
+// numberedName returns a parameter name, numbered if it is already used.
+func numberedName(name string, counts map[string]int) string {
+	if counts[name] > 0 {
+		return name + strconv.Itoa(counts[name]+1)
+	}
+	return name
+}
+
 // singularize attempts to convert a plural word to singular.
 // This is a simple implementation - not comprehensive.
 func singularize(word string) string {
@@ -515,7 +557,13 @@ func EndpointKey(method, pathTemplate string) string {
 
 // InferPathTemplate is a convenience function for inferring path templates.
 // It creates a new PathInferrer and calls InferTemplate.
+// Matrix parameters are added to the template as in EndpointClusterer.
 func InferPathTemplate(path string) (template string, params map[string]string) {
+	path, matrix := splitMatrixParams(path)
 	inferrer := NewPathInferrer()
-	return inferrer.InferTemplate(path)
+	template, params = inferrer.InferTemplate(path)
+	if len(matrix) > 0 {
+		template, _ = addMatrixParams(template, params, matrix)
+	}
+	return template, params
 }
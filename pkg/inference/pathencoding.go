@@ -0,0 +1,48 @@
+package inference
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// decodeSegment returns a path segment with its percent-encoding decoded,
+// or the segment itself if it is not validly encoded.
+func decodeSegment(segment string) string {
+	if !strings.Contains(segment, "%") {
+		return segment
+	}
+	decoded, err := url.PathUnescape(segment)
+	if err != nil {
+		return segment
+	}
+	return decoded
+}
+
+// templateSegment returns the spelling of a literal segment in a template.
+// Encoded and non-ASCII segments are percent-encoded with uppercase hex
+// digits, so /caf%c3%a9, /caf%C3%A9, and /café share one ASCII-safe
+// template; other segments are kept as they are.
+func templateSegment(segment string) string {
+	if !strings.Contains(segment, "%") && isASCII(segment) {
+		return segment
+	}
+	return url.PathEscape(decodeSegment(segment))
+}
+
+// bracedUUID reports whether a segment is a UUID in braces, as in
+// {550e8400-e29b-41d4-a716-446655440000}.
+func bracedUUID(segment string) bool {
+	return len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' &&
+		uuidPathPattern.MatchString(segment[1:len(segment)-1])
+}
+
+// isASCII reports whether s only contains ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestEncodedPathSegments(t *testing.T) {
+	engine := NewEngine(DefaultEngineOptions())
+	for _, path := range []string{
+		"/menus/caf%C3%A9/items/1",
+		"/menus/caf%c3%a9/items/2",
+		"/menus/café/items/3",
+		"/orders/%7B550e8400-e29b-41d4-a716-446655440000%7D",
+		"/orders/550e8400%2De29b%2D41d4%2Da716%2D446655440001",
+		"/users/ann%40example.com",
+		"/users/bob%40example.com",
+	} {
+		engine.ProcessRecord(&ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: path},
+			Response: ir.Response{Status: 200},
+		})
+	}
+	result := engine.Finalize()
+
+	if len(result.Endpoints) != 3 {
+		t.Errorf("expected 3 endpoints, got %v", result.Endpoints)
+	}
+	if ep := result.Endpoints["GET /menus/caf%C3%A9/items/{itemId}"]; ep == nil || ep.RequestCount != 3 {
+		t.Errorf("expected one ASCII-safe endpoint for each spelling of café, got %v", result.Endpoints)
+	}
+	orders := result.Endpoints["GET /orders/{orderId}"]
+	if orders == nil || orders.RequestCount != 2 {
+		t.Fatalf("expected encoded UUIDs to be parameters, got %v", result.Endpoints)
+	}
+	if got := orders.PathParams["orderId"].Examples; !slices.Contains(got, any("{550e8400-e29b-41d4-a716-446655440000}")) {
+		t.Errorf("orderId examples should be decoded, got %v", got)
+	}
+	users := result.Endpoints["GET /users/{userId}"]
+	if users == nil {
+		t.Fatalf("expected encoded emails to be parameters, got %v", result.Endpoints)
+	}
+	if got := users.PathParams["userId"].Examples; !slices.Equal(got, []any{"ann@example.com", "bob@example.com"}) {
+		t.Errorf("userId examples = %v", got)
+	}
+
+	if template, _ := InferPathTemplate("/catégories/42"); template != "/cat%C3%A9gories/{id}" {
+		t.Errorf("InferPathTemplate = %q, want an ASCII-safe template", template)
+	}
+
+	trie := NewTemplateTrie()
+	trie.Add("/tags/été/{tagId}")
+	if template, params, ok := trie.Match("/tags/%C3%A9t%C3%A9/caf%C3%A9"); !ok || template != "/tags/été/{tagId}" || params["tagId"] != "café" {
+		t.Errorf("Match = %q, %v, %v", template, params, ok)
+	}
+}
@@ -0,0 +1,78 @@
+package inference
+
+import (
+	"maps"
+	"net/http"
+	"strings"
+)
+
+// preconditionHeaders are the request headers making a write conditional on
+// the current version of the resource, most common first.
+var preconditionHeaders = []string{"If-Match", "If-Unmodified-Since"}
+
+// Preconditions describes the optimistic concurrency of an endpoint: writes
+// sent with the resource version they were based on, and rejected when it is
+// stale (412) or missing (428).
+type Preconditions struct {
+	Headers  map[string]int // If-Match or If-Unmodified-Since -> requests carrying it
+	Failed   int            // 412 Precondition Failed responses
+	Required int            // 428 Precondition Required responses
+}
+
+// Header returns the precondition header requests carried most, preferring
+// If-Match, or If-Match when none was seen.
+func (p *Preconditions) Header() string {
+	best := preconditionHeaders[0]
+	for _, name := range preconditionHeaders {
+		if p.Headers[name] > p.Headers[best] {
+			best = name
+		}
+	}
+	return best
+}
+
+// addPreconditions records the precondition headers and responses of a
+// write to the endpoint. Safe methods are skipped: their conditional
+// requests are about caching.
+func (e *EndpointData) addPreconditions(method string, headers map[string]string, status int) {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return
+	}
+
+	var used []string
+	for _, name := range preconditionHeaders {
+		for key, value := range headers {
+			if strings.EqualFold(key, name) && value != "" {
+				used = append(used, name)
+				break
+			}
+		}
+	}
+	if len(used) == 0 && status != http.StatusPreconditionFailed && status != http.StatusPreconditionRequired {
+		return
+	}
+
+	if e.Preconditions == nil {
+		e.Preconditions = &Preconditions{Headers: make(map[string]int)}
+	}
+	for _, name := range used {
+		e.Preconditions.Headers[name]++
+	}
+	switch status {
+	case http.StatusPreconditionFailed:
+		e.Preconditions.Failed++
+	case http.StatusPreconditionRequired:
+		e.Preconditions.Required++
+	}
+}
+
+// clone returns a deep copy of the preconditions.
+func (p *Preconditions) clone() *Preconditions {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.Headers = maps.Clone(p.Headers)
+	return &c
+}
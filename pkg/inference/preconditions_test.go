@@ -0,0 +1,45 @@
+package inference
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestPreconditions(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPUT, Path: "/docs/1", Headers: map[string]string{"if-match": `"v1"`}},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPUT, Path: "/docs/1", Headers: map[string]string{"If-Match": `"v1"`}},
+			Response: ir.Response{Status: 412},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPUT, Path: "/docs/1"},
+			Response: ir.Response{Status: 428},
+		},
+		{
+			// Conditional reads are caching, not preconditions
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/docs/1", Headers: map[string]string{"If-Match": `"v1"`}},
+			Response: ir.Response{Status: 412},
+		},
+	}
+	result := InferFromRecords(records)
+
+	want := &Preconditions{Headers: map[string]int{"If-Match": 2}, Failed: 1, Required: 1}
+	if got := result.Endpoints["PUT /docs/{docId}"].Preconditions; !reflect.DeepEqual(got, want) {
+		t.Errorf("preconditions = %+v, want %+v", got, want)
+	}
+	if got := result.Endpoints["GET /docs/{docId}"].Preconditions; got != nil {
+		t.Errorf("GET preconditions = %+v, want nil", got)
+	}
+	if got := (&Preconditions{Headers: map[string]int{"If-Unmodified-Since": 1}}).Header(); got != "If-Unmodified-Since" {
+		t.Errorf("Header() = %q", got)
+	}
+	if got := (&Preconditions{Required: 1}).Header(); got != "If-Match" {
+		t.Errorf("Header() without headers = %q", got)
+	}
+}
@@ -0,0 +1,100 @@
+package inference
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Exclusion reasons for endpoints removed from an InferenceResult.
+const (
+	ExcludeReasonLowSample = "low-sample"
+	ExcludeReasonNotFound  = "not-found"
+)
+
+// ExcludedEndpoint records an endpoint that was removed from the result and why.
+type ExcludedEndpoint struct {
+	Endpoint *EndpointData
+	Reason   string
+}
+
+// exclude moves an endpoint from the result's endpoints to its excluded set.
+func (r *InferenceResult) exclude(key, reason string) {
+	endpoint, ok := r.Endpoints[key]
+	if !ok {
+		return
+	}
+	delete(r.Endpoints, key)
+	r.Excluded[key] = &ExcludedEndpoint{Endpoint: endpoint, Reason: reason}
+}
+
+// applyMinRequests excludes or flags endpoints seen fewer than minRequests times.
+func applyMinRequests(result *InferenceResult, minRequests int, flagOnly bool) {
+	if minRequests <= 1 {
+		return
+	}
+	for key, endpoint := range result.Endpoints {
+		if endpoint.RequestCount >= minRequests {
+			continue
+		}
+		if flagOnly {
+			endpoint.LowSample = true
+		} else {
+			result.exclude(key, ExcludeReasonLowSample)
+		}
+	}
+}
+
+// applyNotFoundPruning excludes endpoints whose only observed responses are 404 or 405.
+func applyNotFoundPruning(result *InferenceResult) {
+	for key, endpoint := range result.Endpoints {
+		if len(endpoint.Responses) == 0 {
+			continue
+		}
+		onlyNotFound := true
+		for status := range endpoint.Responses {
+			if status != http.StatusNotFound && status != http.StatusMethodNotAllowed {
+				onlyNotFound = false
+				break
+			}
+		}
+		if onlyNotFound {
+			result.exclude(key, ExcludeReasonNotFound)
+		}
+	}
+}
+
+// UnmatchedEntry summarizes an excluded endpoint for the unmatched traffic report.
+type UnmatchedEntry struct {
+	Method       string `json:"method"`
+	PathTemplate string `json:"pathTemplate"`
+	RequestCount int    `json:"requestCount"`
+	StatusCodes  []int  `json:"statusCodes,omitempty"`
+	Reason       string `json:"reason"`
+}
+
+// UnmatchedTraffic returns the excluded endpoints sorted by path and method.
+func (r *InferenceResult) UnmatchedTraffic() []UnmatchedEntry {
+	entries := make([]UnmatchedEntry, 0, len(r.Excluded))
+	for _, excluded := range r.Excluded {
+		endpoint := excluded.Endpoint
+		statuses := make([]int, 0, len(endpoint.Responses))
+		for status := range endpoint.Responses {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		entries = append(entries, UnmatchedEntry{
+			Method:       endpoint.Method,
+			PathTemplate: endpoint.PathTemplate,
+			RequestCount: endpoint.RequestCount,
+			StatusCodes:  statuses,
+			Reason:       excluded.Reason,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].PathTemplate != entries[j].PathTemplate {
+			return entries[i].PathTemplate < entries[j].PathTemplate
+		}
+		return entries[i].Method < entries[j].Method
+	})
+	return entries
+}
@@ -0,0 +1,74 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestMinRequestsPerEndpoint(t *testing.T) {
+	records := []ir.IRRecord{
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users"}, Response: ir.Response{Status: 200}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users"}, Response: ir.Response{Status: 200}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/usrs/123"}, Response: ir.Response{Status: 404}},
+	}
+
+	opts := DefaultEngineOptions()
+	opts.MinRequestsPerEndpoint = 2
+	engine := NewEngine(opts)
+	engine.ProcessRecords(records)
+	result := engine.Finalize()
+
+	if _, ok := result.Endpoints["GET /usrs/{usrId}"]; ok {
+		t.Error("expected low-sample endpoint to be excluded")
+	}
+	if excluded := result.Excluded["GET /usrs/{usrId}"]; excluded == nil || excluded.Reason != ExcludeReasonLowSample {
+		t.Errorf("expected excluded low-sample endpoint, got %v", result.Excluded)
+	}
+	if _, ok := result.Endpoints["GET /users"]; !ok {
+		t.Error("expected GET /users to be kept")
+	}
+
+	opts.FlagLowSampleEndpoints = true
+	engine = NewEngine(opts)
+	engine.ProcessRecords(records)
+	result = engine.Finalize()
+
+	endpoint := result.Endpoints["GET /usrs/{usrId}"]
+	if endpoint == nil || !endpoint.LowSample {
+		t.Errorf("expected flagged low-sample endpoint, got %+v", endpoint)
+	}
+}
+
+func TestPruneNotFoundEndpoints(t *testing.T) {
+	records := []ir.IRRecord{
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users"}, Response: ir.Response{Status: 200}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users"}, Response: ir.Response{Status: 404}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/wp-login.php"}, Response: ir.Response{Status: 404}},
+		{Request: ir.Request{Method: ir.RequestMethodPUT, Path: "/users"}, Response: ir.Response{Status: 405}},
+	}
+
+	opts := DefaultEngineOptions()
+	opts.PruneNotFoundEndpoints = true
+	engine := NewEngine(opts)
+	engine.ProcessRecords(records)
+	result := engine.Finalize()
+
+	if _, ok := result.Endpoints["GET /users"]; !ok {
+		t.Error("expected GET /users with a 200 response to be kept")
+	}
+	if len(result.Endpoints) != 1 {
+		t.Errorf("expected 1 endpoint, got %d", len(result.Endpoints))
+	}
+
+	unmatched := result.UnmatchedTraffic()
+	if len(unmatched) != 2 {
+		t.Fatalf("expected 2 unmatched entries, got %d", len(unmatched))
+	}
+	if unmatched[0].PathTemplate != "/users" || unmatched[0].Method != "PUT" || unmatched[0].Reason != ExcludeReasonNotFound {
+		t.Errorf("unexpected first unmatched entry: %+v", unmatched[0])
+	}
+	if unmatched[1].PathTemplate != "/wp-login.php" || len(unmatched[1].StatusCodes) != 1 || unmatched[1].StatusCodes[0] != 404 {
+		t.Errorf("unexpected second unmatched entry: %+v", unmatched[1])
+	}
+}
@@ -0,0 +1,171 @@
+package inference
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Query parameter serialization styles.
+const (
+	QueryStyleScalar     = ""
+	QueryStyleForm       = "form"
+	QueryStyleDeepObject = "deepObject"
+)
+
+// QueryObservation is a single normalized query parameter observation.
+type QueryObservation struct {
+	Name       string         // base parameter name (brackets stripped)
+	Style      string         // QueryStyleScalar, QueryStyleForm, or QueryStyleDeepObject
+	Explode    bool           // true for repeated keys, false for comma-separated lists
+	Value      any            // scalar value (QueryStyleScalar)
+	Values     []any          // array items (QueryStyleForm)
+	Properties map[string]any // object properties (QueryStyleDeepObject)
+}
+
+// NormalizeQuery groups raw query parameters into observations, detecting
+// repeated keys (?tag=a&tag=b), bracketed arrays (?tag[]=a) and deepObject
+// parameters (?filter[status]=active). Values of the commaLists parameters
+// are split as comma-separated lists (?tag=a,b); other values containing
+// commas, such as free text or CSV-like filters, stay scalars.
+//
+// Raw names are processed in sorted order, so a parameter sent in both
+// scalar and bracketed form (?a=1&a[]=2) is one array holding every value.
+func NormalizeQuery(query map[string]any, commaLists ...string) map[string]*QueryObservation {
+	result := make(map[string]*QueryObservation, len(query))
+
+	rawNames := make([]string, 0, len(query))
+	for rawName := range query {
+		rawNames = append(rawNames, rawName)
+	}
+	sort.Strings(rawNames)
+
+	for _, rawName := range rawNames {
+		value := query[rawName]
+		base, key, bracketed := splitBracketName(rawName)
+		values, isList := toValueList(value)
+
+		// deepObject: filter[status]=active
+		if bracketed && key != "" {
+			obs, exists := result[base]
+			if !exists || obs.Style != QueryStyleDeepObject {
+				obs = &QueryObservation{
+					Name:       base,
+					Style:      QueryStyleDeepObject,
+					Explode:    true,
+					Properties: make(map[string]any),
+				}
+				result[base] = obs
+			}
+			if isList && len(values) > 0 {
+				obs.Properties[key] = values[0]
+			} else {
+				obs.Properties[key] = value
+			}
+			continue
+		}
+
+		// Bracketed array (tag[]=a) or repeated key (tag=a&tag=b)
+		if bracketed || isList {
+			if !isList {
+				values = []any{value}
+			}
+			addFormValues(result, base, values, true)
+			continue
+		}
+
+		// Comma-separated list (tag=a,b) of a declared parameter, unless the
+		// commas are part of an expression ($select=Name,Price)
+		if str, ok := value.(string); ok && slices.Contains(commaLists, base) && !isQueryExpression(base, str) {
+			if parts := splitCommaList(str); parts != nil {
+				addFormValues(result, base, parts, false)
+				continue
+			}
+		}
+
+		if _, exists := result[base]; !exists {
+			result[base] = &QueryObservation{
+				Name:  base,
+				Style: QueryStyleScalar,
+				Value: value,
+			}
+		}
+	}
+
+	return result
+}
+
+// SetCommaQueryParams sets the query parameters whose values are
+// comma-separated lists (style form, explode false), such as ids=1,2,3. It
+// must be called before records are added.
+func (c *EndpointClusterer) SetCommaQueryParams(names []string) {
+	c.commaLists = names
+}
+
+// addFormValues records array values for a parameter, merging with existing
+// observations. A scalar observation of the parameter becomes the first item.
+func addFormValues(result map[string]*QueryObservation, name string, values []any, explode bool) {
+	obs, exists := result[name]
+	if exists && obs.Style == QueryStyleForm {
+		obs.Values = append(obs.Values, values...)
+		obs.Explode = true // several occurrences of the parameter
+		return
+	}
+	if exists && obs.Style == QueryStyleScalar {
+		values = append([]any{obs.Value}, values...)
+		explode = true
+	}
+	result[name] = &QueryObservation{
+		Name:    name,
+		Style:   QueryStyleForm,
+		Explode: explode,
+		Values:  values,
+	}
+}
+
+// splitBracketName splits "filter[status]" into ("filter", "status", true)
+// and "tag[]" into ("tag", "", true). Names without brackets are returned unchanged.
+func splitBracketName(name string) (base, key string, bracketed bool) {
+	open := strings.Index(name, "[")
+	if open <= 0 || !strings.HasSuffix(name, "]") {
+		return name, "", false
+	}
+	key = name[open+1 : len(name)-1]
+	if strings.ContainsAny(key, "[]") {
+		return name, "", false
+	}
+	return name[:open], key, true
+}
+
+// toValueList converts multi-valued query values ([]string or []any) to []any.
+func toValueList(value any) ([]any, bool) {
+	switch v := value.(type) {
+	case []any:
+		return v, true
+	case []string:
+		values := make([]any, len(v))
+		for i, s := range v {
+			values[i] = s
+		}
+		return values, true
+	default:
+		return nil, false
+	}
+}
+
+// splitCommaList splits a comma-separated value into parts.
+// Returns nil if the value does not look like a list (e.g., free text or empty items).
+func splitCommaList(s string) []any {
+	if !strings.Contains(s, ",") || strings.ContainsAny(s, " \t") {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	values := make([]any, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			return nil
+		}
+		values = append(values, part)
+	}
+	return values
+}
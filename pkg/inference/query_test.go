@@ -0,0 +1,111 @@
+package inference
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestNormalizeQueryCommaLists(t *testing.T) {
+	query := map[string]any{"ids": "1,2,3", "q": "red,green", "name": "Smith, John"}
+
+	obs := NormalizeQuery(query)
+	for _, name := range []string{"ids", "q", "name"} {
+		if obs[name].Style != QueryStyleScalar {
+			t.Errorf("%s style = %q without declared comma lists, want scalar", name, obs[name].Style)
+		}
+	}
+
+	obs = NormalizeQuery(query, "ids", "name")
+	ids := obs["ids"]
+	if ids.Style != QueryStyleForm || ids.Explode || !reflect.DeepEqual(ids.Values, []any{"1", "2", "3"}) {
+		t.Errorf("declared ids = %+v, want non-exploded form [1 2 3]", ids)
+	}
+	if obs["q"].Style != QueryStyleScalar {
+		t.Errorf("undeclared q style = %q, want scalar", obs["q"].Style)
+	}
+	if obs["name"].Style != QueryStyleScalar {
+		t.Errorf("free text name style = %q, want scalar", obs["name"].Style)
+	}
+}
+
+func TestNormalizeQueryMixedForms(t *testing.T) {
+	query := map[string]any{"a": "1", "a[]": []any{"2", "3"}, "b[]": "x", "b": "y"}
+
+	// Map iteration order varies between runs; the result must not
+	for i := 0; i < 20; i++ {
+		obs := NormalizeQuery(query)
+		a := obs["a"]
+		if a.Style != QueryStyleForm || !a.Explode || !reflect.DeepEqual(a.Values, []any{"1", "2", "3"}) {
+			t.Fatalf("a = %+v, want exploded form [1 2 3]", a)
+		}
+		b := obs["b"]
+		if b.Style != QueryStyleForm || !reflect.DeepEqual(b.Values, []any{"y", "x"}) {
+			t.Fatalf("b = %+v, want form [y x]", b)
+		}
+	}
+}
+
+func TestQueryArrayAndDeepObjectInference(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{
+				Method: ir.RequestMethodGET,
+				Path:   "/items",
+				Query: map[string]any{
+					"tag":            []any{"a", "b"},
+					"fields":         "id,name",
+					"filter[status]": "active",
+					"filter[owner]":  "42",
+					"q":              "hello, world",
+					"sort":           "name,-created",
+				},
+			},
+			Response: ir.Response{Status: 200},
+		},
+	}
+
+	options := DefaultEngineOptions()
+	options.CommaQueryParams = []string{"fields"}
+	engine := NewEngine(options)
+	engine.ProcessRecords(records)
+	endpoint := engine.Finalize().Endpoints["GET /items"]
+	if endpoint == nil {
+		t.Fatal("GET /items endpoint not found")
+	}
+
+	tag := endpoint.QueryParams["tag"]
+	if tag == nil || tag.Type != TypeArray || tag.Style != "form" || tag.Explode == nil || !*tag.Explode {
+		t.Fatalf("expected exploded form array for tag, got %+v", tag)
+	}
+	if tag.Items == nil || tag.Items.Type != TypeString {
+		t.Errorf("expected string items for tag, got %+v", tag.Items)
+	}
+
+	fields := endpoint.QueryParams["fields"]
+	if fields == nil || fields.Type != TypeArray || fields.Explode == nil || *fields.Explode {
+		t.Errorf("expected non-exploded form array for fields, got %+v", fields)
+	}
+
+	filter := endpoint.QueryParams["filter"]
+	if filter == nil || filter.Type != TypeObject || filter.Style != "deepObject" {
+		t.Fatalf("expected deepObject filter, got %+v", filter)
+	}
+	if filter.Properties["status"] == nil || filter.Properties["owner"] == nil {
+		t.Errorf("expected status and owner properties, got %v", filter.Properties)
+	}
+	if filter.Properties["owner"].Type != TypeString {
+		t.Errorf("expected owner type string, got %s", filter.Properties["owner"].Type)
+	}
+	if _, ok := endpoint.QueryParams["filter[status]"]; ok {
+		t.Error("bracketed name should be grouped under filter")
+	}
+
+	if q := endpoint.QueryParams["q"]; q == nil || q.Type != TypeString {
+		t.Errorf("expected free text q to remain a string, got %+v", q)
+	}
+	if sort := endpoint.QueryParams["sort"]; sort == nil || sort.Type != TypeString {
+		t.Errorf("expected undeclared comma value sort to remain a string, got %+v", sort)
+	}
+}
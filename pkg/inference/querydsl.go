@@ -0,0 +1,137 @@
+package inference
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Query dialects of expression parameters, recorded in ParamData.Dialect.
+const (
+	QueryDialectOData = "odata"
+	QueryDialectRSQL  = "rsql"
+	QueryDialectJSON  = "json"
+)
+
+// queryOption is an OData system query option.
+type queryOption struct {
+	typ         string
+	description string
+}
+
+// odataQueryOptions are the OData system query options. Their types are
+// fixed by the protocol rather than inferred from observed values.
+var odataQueryOptions = map[string]queryOption{
+	"$filter":    {TypeString, "OData filter expression, e.g. Price lt 10 and Category eq 'Books'"},
+	"$select":    {TypeString, "Comma-separated properties to return"},
+	"$expand":    {TypeString, "Comma-separated related entities to include, optionally with nested query options"},
+	"$orderby":   {TypeString, "Comma-separated properties to sort by, each optionally followed by asc or desc"},
+	"$top":       {TypeInteger, "Maximum number of items to return"},
+	"$skip":      {TypeInteger, "Number of items to skip before returning results"},
+	"$count":     {TypeBoolean, "Include the total number of matching items"},
+	"$search":    {TypeString, "Free-text search expression"},
+	"$skiptoken": {TypeString, "Server-issued token for the next page of results"},
+	"$format":    {TypeString, "Response format, such as json"},
+	"$apply":     {TypeString, "Aggregation transformations to apply"},
+	"$compute":   {TypeString, "Computed properties to add to returned items"},
+}
+
+var (
+	// odataExpressionPattern matches OData comparisons (Price lt 10) and
+	// filter functions (contains(Name,'a')).
+	odataExpressionPattern = regexp.MustCompile(`(?i)([\w/.)']\s+(eq|ne|gt|ge|lt|le)\s+\S|[\w/.]\s+in\s+\(|\b(contains|startswith|endswith|substringof|any|all)\()`)
+
+	// rsqlExpressionPattern matches RSQL/FIQL comparisons (name==Ann,
+	// age=gt=30), at the start of the value or after a logical operator.
+	rsqlExpressionPattern = regexp.MustCompile(`(^|[;,(])\s*[\w.]+(==|!=|=[a-z]+=)`)
+)
+
+// QueryExpressionDialect returns the query language of a query parameter
+// value that is a filter or search expression (QueryDialectOData,
+// QueryDialectRSQL, or QueryDialectJSON), or "" for other values.
+func QueryExpressionDialect(value string) string {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return ""
+	case strings.HasPrefix(value, "{") && json.Valid([]byte(value)):
+		return QueryDialectJSON
+	case rsqlExpressionPattern.MatchString(value):
+		return QueryDialectRSQL
+	case odataExpressionPattern.MatchString(value):
+		return QueryDialectOData
+	}
+	return ""
+}
+
+// isQueryExpression reports whether a query parameter is an OData system
+// query option or its value is an expression, which is documented as a
+// plain string instead of being split into a list.
+func isQueryExpression(name, value string) bool {
+	_, known := odataQueryOptions[name]
+	return known || QueryExpressionDialect(value) != ""
+}
+
+// addQueryExpression records the value of an OData system query option or
+// an expression parameter, and reports whether it was one. The parameter's
+// type is the option's, or string for expressions: types and formats are
+// not inferred from the values, which are free-form.
+func addQueryExpression(param *ParamData, value any) bool {
+	option, known := odataQueryOptions[param.Name]
+	dialect := param.Dialect
+	switch {
+	case known:
+		dialect = QueryDialectOData
+	case dialect == "":
+		str, _ := value.(string)
+		dialect = QueryExpressionDialect(str)
+	}
+	if dialect == "" {
+		return false
+	}
+
+	if str, ok := value.(string); ok && known {
+		value = typedOptionValue(option.typ, str)
+	}
+	param.AddValue(value)
+	param.Dialect = dialect
+	param.Type, param.Format = TypeString, ""
+	if known {
+		param.Type = option.typ
+	}
+	return true
+}
+
+// typedOptionValue converts the value of an integer or boolean query option,
+// leaving values that don't parse as strings.
+func typedOptionValue(typ, value string) any {
+	switch typ {
+	case TypeInteger:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case TypeBoolean:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// QueryParamDescription returns a description of an OData system query
+// option or an expression parameter, or "" for other query parameters.
+func QueryParamDescription(param *ParamData) string {
+	if option, ok := odataQueryOptions[param.Name]; ok {
+		return option.description
+	}
+	switch param.Dialect {
+	case QueryDialectOData:
+		return "Query expression in OData syntax"
+	case QueryDialectRSQL:
+		return "Query expression in RSQL (FIQL) syntax"
+	case QueryDialectJSON:
+		return "Query expression as a JSON document"
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestQueryExpressions(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/Products", Query: map[string]any{
+				"$filter": "Price lt 10 and Category eq 'Books'", "$select": "Name,Price", "$top": "20", "$count": "true",
+				"filter": "name==Ann,age=gt=30", "where": `{"age":{"$gt":30},"active":true}`, "tags": "a,b",
+			}},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/Products", Query: map[string]any{
+				"$filter": "2024-01-01", "$top": "5", "filter": "2024-01-01", "q": "contains(Name,'pen')",
+			}},
+			Response: ir.Response{Status: 200},
+		},
+	}
+
+	options := DefaultEngineOptions()
+	options.CommaQueryParams = []string{"tags", "$select", "filter"}
+	engine := NewEngine(options)
+	engine.ProcessRecords(records)
+	endpoint := engine.Finalize().Endpoints["GET /Products"]
+	if endpoint == nil {
+		t.Fatal("missing GET /Products")
+	}
+
+	tests := []struct {
+		name, typ, dialect string
+	}{
+		{"$filter", TypeString, QueryDialectOData},
+		{"$select", TypeString, QueryDialectOData},
+		{"$top", TypeInteger, QueryDialectOData},
+		{"$count", TypeBoolean, QueryDialectOData},
+		{"filter", TypeString, QueryDialectRSQL},
+		{"where", TypeString, QueryDialectJSON},
+		{"q", TypeString, QueryDialectOData},
+		{"tags", TypeArray, ""},
+	}
+	for _, tt := range tests {
+		param := endpoint.QueryParams[tt.name]
+		if param == nil {
+			t.Errorf("missing query parameter %s", tt.name)
+			continue
+		}
+		if param.Type != tt.typ || param.Dialect != tt.dialect {
+			t.Errorf("%s: type %q, dialect %q; want %q, %q", tt.name, param.Type, param.Dialect, tt.typ, tt.dialect)
+		}
+		if tt.dialect != "" && param.Format != "" {
+			t.Errorf("%s: format %q inferred from an expression", tt.name, param.Format)
+		}
+	}
+	if got := endpoint.QueryParams["$top"].Examples; len(got) != 2 || got[0] != int64(20) {
+		t.Errorf("$top examples = %v, want integers", got)
+	}
+	if got := QueryParamDescription(endpoint.QueryParams["filter"]); got == "" {
+		t.Error("expected a description for the RSQL filter parameter")
+	}
+}
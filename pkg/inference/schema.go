@@ -150,9 +150,13 @@ func convertToSchemaNode(node *treeNode, store *SchemaStore, isRoot bool) *Schem
 		}
 	}
 
-	// If root and only has array children with same key, it's a root array
+	// If root and its only child is the unnamed array element, it's a root
+	// array; {"results": [...]} stays an object with an array property
 	if isRoot && allArrays && len(node.children) == 1 {
-		for _, child := range node.children {
+		for key, child := range node.children {
+			if key != "[]" {
+				break
+			}
 			itemSchema := convertToSchemaNode(child, store, false)
 			return &SchemaNode{
 				Type:  TypeArray,
@@ -0,0 +1,41 @@
+package inference
+
+import "testing"
+
+func TestBuildSchemaTreeRootArray(t *testing.T) {
+	store := NewSchemaStore()
+	ProcessBody(store, []any{map[string]any{"id": "1"}, map[string]any{"id": "2"}})
+
+	schema := BuildSchemaTree(store)
+	if schema.Type != TypeArray {
+		t.Fatalf("root array body type = %q, want array", schema.Type)
+	}
+	if schema.Items == nil || schema.Items.Properties["id"] == nil {
+		t.Errorf("root array items = %+v, want object with id", schema.Items)
+	}
+}
+
+func TestBuildSchemaTreeArrayEnvelope(t *testing.T) {
+	// A body whose only property is an array is an envelope object, not a
+	// root array
+	store := NewSchemaStore()
+	ProcessBody(store, map[string]any{"results": []any{map[string]any{"id": "1"}}})
+
+	schema := BuildSchemaTree(store)
+	if schema.Type != TypeObject {
+		t.Fatalf("envelope body type = %q, want object", schema.Type)
+	}
+	results := schema.Properties["results"]
+	if results == nil || results.Type != TypeArray {
+		t.Fatalf("results property = %+v, want array", results)
+	}
+	if results.Items == nil || results.Items.Properties["id"] == nil {
+		t.Errorf("results items = %+v, want object with id", results.Items)
+	}
+
+	store = NewSchemaStore()
+	ProcessBody(store, map[string]any{"tags": []any{"a", "b"}})
+	if schema := BuildSchemaTree(store); schema.Type != TypeObject || schema.Properties["tags"] == nil {
+		t.Errorf("scalar array envelope = %+v, want object with tags", schema)
+	}
+}
@@ -0,0 +1,113 @@
+package inference
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SecurityRule declares a custom credential convention: a header, query
+// parameter, or cookie that authenticates requests, such as X-Org-Token or
+// a signature query parameter. It is detected as an apiKey security scheme
+// under Key, and, like built-in credentials, is not documented as a
+// parameter. Rules take precedence over the built-in detection.
+type SecurityRule struct {
+	Key         string // security scheme key, e.g. "orgToken"
+	In          string // header, query, or cookie
+	Name        string // header (case-insensitive), query parameter, or cookie name
+	Description string // optional description of the scheme
+}
+
+// Validate reports whether the rule is complete.
+func (r SecurityRule) Validate() error {
+	switch {
+	case r.Key == "":
+		return fmt.Errorf("security rule for %s has no scheme key", r.Name)
+	case r.Name == "":
+		return fmt.Errorf("security rule %s has no parameter name", r.Key)
+	case r.In != "header" && r.In != "query" && r.In != "cookie":
+		return fmt.Errorf("security rule %s: unsupported location %q (use header, query, or cookie)", r.Key, r.In)
+	}
+	return nil
+}
+
+// scheme returns the security scheme the rule detects.
+func (r SecurityRule) scheme() *DetectedSecurityScheme {
+	return &DetectedSecurityScheme{
+		Type:        "apiKey",
+		Name:        r.Name,
+		In:          r.In,
+		Description: r.Description,
+	}
+}
+
+// SetRules sets the custom credential rules. It should be called before
+// requests are analyzed.
+func (d *SecurityDetector) SetRules(rules []SecurityRule) {
+	d.rules = rules
+}
+
+// SetSecurityRules sets the custom credential rules of the security
+// detector. It must be called before records are added.
+func (c *EndpointClusterer) SetSecurityRules(rules []SecurityRule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.securityDetector.SetRules(rules)
+}
+
+// HeaderScheme returns the security scheme a request header authenticates
+// with: that of a header rule naming it, else the built-in one (see
+// SecurityHeaderScheme).
+func (d *SecurityDetector) HeaderScheme(name, value string) (string, *DetectedSecurityScheme, bool) {
+	for _, rule := range d.rules {
+		if rule.In == "header" && strings.EqualFold(rule.Name, name) {
+			return rule.Key, rule.scheme(), true
+		}
+	}
+	return SecurityHeaderScheme(name, value)
+}
+
+// IsQueryCredential reports whether a query parameter is a credential
+// declared by a query rule.
+func (d *SecurityDetector) IsQueryCredential(name string) bool {
+	for _, rule := range d.rules {
+		if rule.In == "query" && rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectFromQuery analyzes query parameters for credentials declared by
+// query rules.
+func (d *SecurityDetector) DetectFromQuery(query map[string]any) {
+	for _, rule := range d.rules {
+		if rule.In != "query" {
+			continue
+		}
+		if _, ok := query[rule.Name]; ok {
+			d.addScheme(rule.Key, rule.scheme())
+		}
+	}
+}
+
+// detectFromCookies analyzes a Cookie header for credentials declared by
+// cookie rules.
+func (d *SecurityDetector) detectFromCookies(header string) {
+	cookies, err := http.ParseCookie(header)
+	if err != nil {
+		return
+	}
+	for _, rule := range d.rules {
+		if rule.In != "cookie" {
+			continue
+		}
+		for _, cookie := range cookies {
+			if cookie.Name == rule.Name {
+				d.addScheme(rule.Key, rule.scheme())
+				break
+			}
+		}
+	}
+}
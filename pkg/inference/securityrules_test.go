@@ -0,0 +1,61 @@
+package inference
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestSecurityRules(t *testing.T) {
+	opts := DefaultEngineOptions()
+	opts.SecurityRules = []SecurityRule{
+		{Key: "orgToken", In: "header", Name: "X-Org-Token", Description: "Organization token"},
+		{Key: "signature", In: "query", Name: "signature"},
+		{Key: "session", In: "cookie", Name: "sid"},
+		// Rules take precedence over built-in detection
+		{Key: "partnerKey", In: "header", Name: "X-API-Key"},
+	}
+	engine := NewEngine(opts)
+	engine.ProcessRecords([]ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/a",
+				Query:   map[string]any{"signature": "abc", "page": "1"},
+				Headers: map[string]string{"x-org-token": "o1", "X-API-Key": "k1", "Cookie": "sid=zz; theme=dark"},
+			},
+			Response: ir.Response{Status: 200},
+		},
+	})
+	result := engine.Finalize()
+
+	var keys []string
+	for key := range result.SecuritySchemes {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	if want := []string{"orgToken", "partnerKey", "session", "signature"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("security schemes = %v, want %v", keys, want)
+	}
+	if org := result.SecuritySchemes["orgToken"]; org.Type != "apiKey" || org.In != "header" || org.Description != "Organization token" {
+		t.Errorf("orgToken = %+v", org)
+	}
+
+	endpoint := result.Endpoints["GET /a"]
+	if _, ok := endpoint.QueryParams["signature"]; ok {
+		t.Error("signature documented as a query parameter")
+	}
+	if _, ok := endpoint.QueryParams["page"]; !ok {
+		t.Error("page query parameter missing")
+	}
+	for name := range endpoint.HeaderParams {
+		if strings.EqualFold(name, "x-org-token") || strings.EqualFold(name, "x-api-key") {
+			t.Errorf("credential header %s documented as a parameter", name)
+		}
+	}
+
+	if err := (SecurityRule{Key: "k", In: "body", Name: "x"}).Validate(); err == nil {
+		t.Error("expected an error for an unsupported location")
+	}
+}
@@ -0,0 +1,138 @@
+package inference
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// PathSegmentOptions selects path segments documented as parameters with an
+// enum of their observed values, rather than as literal path variants.
+type PathSegmentOptions struct {
+	// Locales documents locale codes as a {locale} parameter: codes with a
+	// region or script (/en-US/, /pt_br/, /zh-Hant/) anywhere in the path,
+	// and bare language codes (/de/) as the first segment after any api or
+	// version prefix.
+	Locales bool
+
+	// TenantCollections documents the segment after a tenant collection
+	// (/tenants/acme, /orgs/acme, /organizations/acme, /workspaces/acme) as
+	// a parameter, even when it is a slug rather than an ID.
+	TenantCollections bool
+
+	// Tenants lists tenant slugs documented as a {tenant} parameter wherever
+	// they appear, such as a leading /acme/ segment.
+	Tenants []string
+}
+
+// maxEnumValues is the most distinct values an enumerated path parameter
+// may have; parameters with more are documented without an enum.
+const maxEnumValues = 50
+
+// Parameter names of locale and tenant segments.
+const (
+	localeParamName = "locale"
+	tenantParamName = "tenant"
+)
+
+// localePattern matches a language code, optionally followed by a region
+// (US, us, 419) or script (Hant) subtag.
+var localePattern = regexp.MustCompile(`^([a-z]{2})(?:[-_]([A-Za-z]{2}|\d{3}|[A-Z][a-z]{3}))?$`)
+
+// localeLanguages are the language codes recognized in locale segments.
+var localeLanguages = map[string]bool{
+	"ar": true, "bg": true, "bn": true, "ca": true, "cs": true, "da": true,
+	"de": true, "el": true, "en": true, "es": true, "et": true, "fa": true,
+	"fi": true, "fr": true, "he": true, "hi": true, "hr": true, "hu": true,
+	"id": true, "it": true, "ja": true, "ko": true, "lt": true, "lv": true,
+	"ms": true, "nb": true, "nl": true, "no": true, "pl": true, "pt": true,
+	"ro": true, "ru": true, "sk": true, "sl": true, "sr": true, "sv": true,
+	"th": true, "tr": true, "uk": true, "vi": true, "zh": true,
+}
+
+// tenantCollections are the collections whose members are tenants.
+var tenantCollections = map[string]bool{
+	"tenants":       true,
+	"orgs":          true,
+	"organizations": true,
+	"workspaces":    true,
+}
+
+// SetSegmentOptions sets the path segments documented as enumerated
+// parameters. It must be called before paths are inferred.
+func (p *PathInferrer) SetSegmentOptions(opts PathSegmentOptions) {
+	p.segments = opts
+	p.tenants = make(map[string]bool, len(opts.Tenants))
+	for _, tenant := range opts.Tenants {
+		p.tenants[tenant] = true
+	}
+}
+
+// localeSegment reports whether a segment is a locale code. Bare language
+// codes only count when leading is set, since two-letter literals are
+// common elsewhere in paths.
+func localeSegment(segment string, leading bool) bool {
+	m := localePattern.FindStringSubmatch(segment)
+	if m == nil || !localeLanguages[m[1]] {
+		return false
+	}
+	return m[2] != "" || leading
+}
+
+// leadingSegment reports whether the segment at idx only follows api and
+// version prefix segments.
+func leadingSegment(segments []string, idx int) bool {
+	for _, segment := range segments[:idx] {
+		if segment != "api" && !versionPattern.MatchString(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+// classifyInContext classifies the segment at idx, recognizing the locale
+// and tenant segments that depend on their position.
+func (p *PathInferrer) classifyInContext(segments []string, idx int) SegmentType {
+	segment := segments[idx]
+	if p.segments.Locales && localeSegment(segment, leadingSegment(segments, idx)) {
+		return SegmentLocale
+	}
+	if p.tenantMember(segments, idx) {
+		if segType := p.classifySegment(segment); segType != SegmentLiteral {
+			return segType
+		}
+		return SegmentTenant
+	}
+	return p.classifySegment(segment)
+}
+
+// EnumeratedParam reports whether a path parameter of a template holds a
+// locale or tenant, documented with an enum of its observed values.
+func (p *PathInferrer) EnumeratedParam(template, name string) bool {
+	if name == localeParamName || name == tenantParamName {
+		return true
+	}
+	segments := splitPathSegments(template)
+	return p.tenantMember(segments, slices.Index(segments, "{"+name+"}"))
+}
+
+// tenantMember reports whether the segment at idx follows a tenant
+// collection, when TenantCollections is set.
+func (p *PathInferrer) tenantMember(segments []string, idx int) bool {
+	return p.segments.TenantCollections && idx > 0 && tenantCollections[strings.ToLower(segments[idx-1])]
+}
+
+// AddEnumValue records an observed value of an enumerated parameter. Once
+// more than maxEnumValues distinct values are seen, the parameter is no
+// longer enumerated.
+func (p *ParamData) AddEnumValue(value string) {
+	if p.noEnum || slices.Contains(p.Enum, value) {
+		return
+	}
+	if len(p.Enum) == maxEnumValues {
+		p.Enum = nil
+		p.noEnum = true
+		return
+	}
+	p.Enum = append(p.Enum, value)
+}
@@ -0,0 +1,59 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestPathSegmentOptions(t *testing.T) {
+	paths := []string{
+		"/en-US/products", "/de/products", "/pt_br/products",
+		"/api/v1/fr/news", "/users/de",
+		"/orgs/acme/repos", "/orgs/globex/repos",
+		"/acme/reports", "/initech/reports",
+	}
+	process := func(opts EngineOptions) *InferenceResult {
+		engine := NewEngine(opts)
+		for _, path := range paths {
+			engine.ProcessRecord(&ir.IRRecord{
+				Request:  ir.Request{Method: ir.RequestMethodGET, Path: path},
+				Response: ir.Response{Status: 200},
+			})
+		}
+		return engine.Finalize()
+	}
+
+	opts := DefaultEngineOptions()
+	opts.PathSegments = PathSegmentOptions{Locales: true, TenantCollections: true, Tenants: []string{"acme", "initech"}}
+	result := process(opts)
+
+	tests := []struct {
+		key, param string
+		enum       []string
+	}{
+		{"GET /{locale}/products", "locale", []string{"en-US", "de", "pt_br"}},
+		{"GET /api/v1/{locale}/news", "locale", []string{"fr"}},
+		{"GET /orgs/{orgId}/repos", "orgId", []string{"acme", "globex"}},
+		{"GET /{tenant}/reports", "tenant", []string{"acme", "initech"}},
+	}
+	for _, tt := range tests {
+		endpoint := result.Endpoints[tt.key]
+		if endpoint == nil {
+			t.Errorf("missing endpoint %s, got %v", tt.key, result.Endpoints)
+			continue
+		}
+		param := endpoint.PathParams[tt.param]
+		if param == nil || !slices.Equal(param.Enum, tt.enum) {
+			t.Errorf("%s %s = %+v, want enum %v", tt.key, tt.param, param, tt.enum)
+		}
+	}
+	if result.Endpoints["GET /users/de"] == nil {
+		t.Errorf("a bare language code after the first segment should stay literal, got %v", result.Endpoints)
+	}
+
+	if result := process(DefaultEngineOptions()); result.Endpoints["GET /de/products"] == nil || result.Endpoints["GET /acme/reports"] == nil {
+		t.Errorf("expected literal locale and tenant segments by default, got %v", result.Endpoints)
+	}
+}
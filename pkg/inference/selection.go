@@ -0,0 +1,83 @@
+package inference
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ExcludeReasonDeselected marks endpoints removed by an EndpointSelection.
+const ExcludeReasonDeselected = "deselected"
+
+// EndpointSelection chooses which inferred endpoints to document, by
+// "METHOD /path/template" key as in InferenceResult.Endpoints. It is written
+// by the explore command and read by generate --select.
+type EndpointSelection struct {
+	// Include, if set, keeps only these endpoints.
+	Include []string `json:"include,omitempty"`
+
+	// Exclude removes these endpoints.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// ReadEndpointSelection reads an endpoint selection JSON file.
+func ReadEndpointSelection(path string) (*EndpointSelection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading endpoint selection: %w", err)
+	}
+	var s EndpointSelection
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing endpoint selection %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// WriteFile writes the selection as JSON, with keys sorted.
+func (s *EndpointSelection) WriteFile(path string) error {
+	out := EndpointSelection{Include: slices.Sorted(slices.Values(s.Include)), Exclude: slices.Sorted(slices.Values(s.Exclude))}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding endpoint selection: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing endpoint selection: %w", err)
+	}
+	return nil
+}
+
+// Allows reports whether the selection keeps an endpoint key. A nil
+// selection keeps every endpoint. Methods are compared case-insensitively.
+func (s *EndpointSelection) Allows(key string) bool {
+	if s == nil {
+		return true
+	}
+	matches := func(keys []string) bool {
+		return slices.ContainsFunc(keys, func(k string) bool { return sameEndpointKey(k, key) })
+	}
+	if len(s.Include) > 0 && !matches(s.Include) {
+		return false
+	}
+	return !matches(s.Exclude)
+}
+
+// sameEndpointKey compares endpoint keys, ignoring the case of the method.
+func sameEndpointKey(a, b string) bool {
+	methodA, pathA, _ := strings.Cut(strings.TrimSpace(a), " ")
+	methodB, pathB, _ := strings.Cut(strings.TrimSpace(b), " ")
+	return strings.EqualFold(methodA, methodB) && strings.TrimSpace(pathA) == strings.TrimSpace(pathB)
+}
+
+// applySelection excludes the endpoints a selection doesn't keep.
+func applySelection(result *InferenceResult, selection *EndpointSelection) {
+	if selection == nil {
+		return
+	}
+	for key := range result.Endpoints {
+		if !selection.Allows(key) {
+			result.exclude(key, ExcludeReasonDeselected)
+		}
+	}
+}
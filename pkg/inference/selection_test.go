@@ -0,0 +1,47 @@
+package inference
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestEndpointSelection(t *testing.T) {
+	records := []ir.IRRecord{
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users"}, Response: ir.Response{Status: 200}},
+		{Request: ir.Request{Method: ir.RequestMethodPOST, Path: "/users"}, Response: ir.Response{Status: 201}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/health"}, Response: ir.Response{Status: 200}},
+	}
+
+	path := filepath.Join(t.TempDir(), "selection.json")
+	if err := (&EndpointSelection{Exclude: []string{"get /health"}}).WriteFile(path); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	selection, err := ReadEndpointSelection(path)
+	if err != nil {
+		t.Fatalf("ReadEndpointSelection failed: %v", err)
+	}
+
+	opts := DefaultEngineOptions()
+	opts.Selection = selection
+	engine := NewEngine(opts)
+	engine.ProcessRecords(records)
+	result := engine.Finalize()
+
+	if len(result.Endpoints) != 2 {
+		t.Errorf("expected 2 endpoints, got %d", len(result.Endpoints))
+	}
+	if excluded := result.Excluded["GET /health"]; excluded == nil || excluded.Reason != ExcludeReasonDeselected {
+		t.Errorf("expected GET /health deselected, got %+v", result.Excluded)
+	}
+
+	include := &EndpointSelection{Include: []string{"GET /users", "POST /users"}, Exclude: []string{"POST /users"}}
+	if !include.Allows("GET /users") || include.Allows("POST /users") || include.Allows("GET /health") {
+		t.Error("expected only GET /users allowed")
+	}
+	var none *EndpointSelection
+	if !none.Allows("GET /anything") {
+		t.Error("expected nil selection to allow every endpoint")
+	}
+}
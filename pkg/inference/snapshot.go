@@ -0,0 +1,112 @@
+package inference
+
+import (
+	"maps"
+	"slices"
+)
+
+// The clone methods below copy the mutable state of endpoint data so a
+// snapshot can be finalized and read while ingestion continues. Example
+// values are shared: they are never modified once stored.
+
+// clone returns a deep copy of the store.
+func (s *SchemaStore) clone() *SchemaStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	examples := make(map[string][]any, len(s.Examples))
+	for path, values := range s.Examples {
+		examples[path] = append([]any(nil), values...)
+	}
+	return &SchemaStore{
+		Examples:    examples,
+		Types:       maps.Clone(s.Types),
+		Optional:    maps.Clone(s.Optional),
+		Nullable:    maps.Clone(s.Nullable),
+		Formats:     maps.Clone(s.Formats),
+		seenCount:   maps.Clone(s.seenCount),
+		totalCount:  s.totalCount,
+		maxExamples: s.maxExamples,
+	}
+}
+
+// clone returns a deep copy of the parameter.
+func (p *ParamData) clone() *ParamData {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.Examples = append(make([]any, 0, len(p.Examples)), p.Examples...)
+	c.Enum = slices.Clone(p.Enum)
+	c.Aliases = slices.Clone(p.Aliases)
+	c.Stats = p.Stats.clone()
+	if p.Explode != nil {
+		explode := *p.Explode
+		c.Explode = &explode
+	}
+	c.Items = p.Items.clone()
+	if p.Properties != nil {
+		c.Properties = cloneParams(p.Properties)
+	}
+	return &c
+}
+
+func cloneParams(params map[string]*ParamData) map[string]*ParamData {
+	c := make(map[string]*ParamData, len(params))
+	for name, param := range params {
+		c[name] = param.clone()
+	}
+	return c
+}
+
+// clone returns a deep copy of the endpoint.
+func (e *EndpointData) clone() *EndpointData {
+	c := *e
+	c.PathParams = cloneParams(e.PathParams)
+	c.QueryParams = cloneParams(e.QueryParams)
+	c.HeaderParams = cloneParams(e.HeaderParams)
+	if e.RequestBody != nil {
+		c.RequestBody = &BodyData{
+			ContentType:  e.RequestBody.ContentType,
+			ContentTypes: maps.Clone(e.RequestBody.ContentTypes),
+			Schema:       e.RequestBody.Schema.clone(),
+			Count:        e.RequestBody.Count,
+			Multipart:    e.RequestBody.Multipart,
+		}
+		if e.RequestBody.FileParts != nil {
+			c.RequestBody.FileParts = make(map[string]*FilePart, len(e.RequestBody.FileParts))
+			for name, part := range e.RequestBody.FileParts {
+				c.RequestBody.FileParts[name] = part.clone()
+			}
+		}
+	}
+	c.Responses = make(map[int]*ResponseData, len(e.Responses))
+	for status, resp := range e.Responses {
+		c.Responses[status] = &ResponseData{
+			StatusCode:   resp.StatusCode,
+			ContentType:  resp.ContentType,
+			ContentTypes: maps.Clone(resp.ContentTypes),
+			Headers:      cloneParams(resp.Headers),
+			Body:         resp.Body.clone(),
+			MultiStatus:  resp.MultiStatus.clone(),
+			Download:     resp.Download.clone(),
+		}
+	}
+	if e.Metadata != nil {
+		c.Metadata = make(map[string][]string, len(e.Metadata))
+		for key, values := range e.Metadata {
+			c.Metadata[key] = append([]string(nil), values...)
+		}
+	}
+	c.Tags = append([]string(nil), e.Tags...)
+	c.Hosts = append([]string(nil), e.Hosts...)
+	c.EarlyHints = append([]string(nil), e.EarlyHints...)
+	c.Preconditions = e.Preconditions.clone()
+	c.ExamplePairs = append([]*ExamplePair(nil), e.ExamplePairs...)
+	c.InvalidExamples = append([]*ExamplePair(nil), e.InvalidExamples...)
+	if e.ExternalDocs != nil {
+		docs := *e.ExternalDocs
+		c.ExternalDocs = &docs
+	}
+	return &c
+}
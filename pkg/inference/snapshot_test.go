@@ -0,0 +1,40 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestEngineSnapshotIsolated(t *testing.T) {
+	engine := NewEngine(DefaultEngineOptions())
+	record := ir.IRRecord{
+		Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/users", Query: map[string]any{"limit": "10"}},
+		Response: ir.Response{Status: 200, Body: map[string]any{"id": float64(1)}},
+	}
+	engine.ProcessRecord(&record)
+	snapshot := engine.Snapshot()
+
+	record.Request.Query = nil
+	record.Response.Body = map[string]any{"id": float64(2), "name": "Ada"}
+	engine.ProcessRecord(&record)
+
+	endpoint := snapshot.Endpoints["GET /users"]
+	if endpoint == nil {
+		t.Fatalf("expected GET /users in snapshot, got %v", snapshot.Endpoints)
+	}
+	if endpoint.RequestCount != 1 {
+		t.Errorf("expected snapshot to keep 1 request, got %d", endpoint.RequestCount)
+	}
+	if _, ok := endpoint.Responses[200].Body.Types["name"]; ok {
+		t.Error("expected snapshot body schema to be unaffected by later records")
+	}
+
+	final := engine.Finalize()
+	if final.Endpoints["GET /users"].RequestCount != 2 {
+		t.Errorf("expected 2 requests after finalize, got %d", final.Endpoints["GET /users"].RequestCount)
+	}
+	if !final.Endpoints["GET /users"].Responses[200].Body.Optional["name"] {
+		t.Error("expected name to be optional after finalize")
+	}
+}
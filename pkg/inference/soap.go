@@ -0,0 +1,88 @@
+package inference
+
+import (
+	"encoding/xml"
+	"mime"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// soapEnvelopeNamespaces are the envelope namespaces of SOAP 1.1 and 1.2.
+var soapEnvelopeNamespaces = map[string]bool{
+	"http://schemas.xmlsoap.org/soap/envelope/": true,
+	"http://www.w3.org/2003/05/soap-envelope":   true,
+}
+
+// SOAPOperation returns the operation a SOAP request invokes, or "" if the
+// request body is not a SOAP envelope. The operation is named by the
+// SOAPAction header, the action parameter of a SOAP 1.2 content type, or
+// else the root element of the envelope Body.
+func SOAPOperation(record *ir.IRRecord) string {
+	body, ok := record.Request.Body.(string)
+	if !ok {
+		return ""
+	}
+	element, ok := soapBodyElement(body)
+	if !ok {
+		return ""
+	}
+
+	if values := record.Request.HeaderValuesOf("SOAPAction"); len(values) > 0 {
+		if name := soapActionName(values[0]); name != "" {
+			return name
+		}
+	}
+	if record.Request.ContentType != nil {
+		if _, params, err := mime.ParseMediaType(*record.Request.ContentType); err == nil {
+			if name := soapActionName(params["action"]); name != "" {
+				return name
+			}
+		}
+	}
+	return element
+}
+
+// soapBodyElement reports whether an XML document is a SOAP envelope, and
+// returns the local name of the first element in its Body.
+func soapBodyElement(body string) (string, bool) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	depth := 0
+	inBody := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", false
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case depth == 1:
+				if t.Name.Local != "Envelope" || !soapEnvelopeNamespaces[t.Name.Space] {
+					return "", false
+				}
+			case depth == 2 && t.Name.Local == "Body":
+				inBody = true
+			case depth == 3 && inBody:
+				return t.Name.Local, true
+			}
+		case xml.EndElement:
+			if depth == 2 && inBody {
+				// An empty Body
+				return "", true
+			}
+			depth--
+		}
+	}
+}
+
+// soapActionName returns the last segment of a SOAP action URI, such as
+// GetQuote in "http://example.com/stock/GetQuote" or "urn:stock#GetQuote".
+func soapActionName(action string) string {
+	action = strings.Trim(strings.TrimSpace(action), `"`)
+	if i := strings.LastIndexAny(action, "/#:"); i >= 0 {
+		action = action[i+1:]
+	}
+	return action
+}
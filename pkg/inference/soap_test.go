@@ -0,0 +1,66 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestSOAPOperations(t *testing.T) {
+	envelope := func(element string) string {
+		return `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Header><Auth>token</Auth></soap:Header>
+  <soap:Body><m:` + element + ` xmlns:m="urn:stock"><m:Symbol>ACME</m:Symbol></m:` + element + `></soap:Body>
+</soap:Envelope>`
+	}
+	xmlType := "text/xml; charset=utf-8"
+	soap12 := `application/soap+xml; charset=utf-8; action="urn:stock#GetHistory"`
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodPOST, Path: "/ws/stock", ContentType: &xmlType,
+				Headers: map[string]string{"soapaction": `"http://example.com/stock/GetQuote"`}, Body: envelope("GetQuoteRequest")},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/ws/stock", ContentType: &xmlType, Body: envelope("GetPrice")},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/ws/stock", ContentType: &soap12, Body: envelope("History")},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/ws/stock", ContentType: &xmlType, Body: "<Quote><Symbol>ACME</Symbol></Quote>"},
+			Response: ir.Response{Status: 200},
+		},
+	}
+
+	opts := DefaultEngineOptions()
+	opts.SOAPOperations = true
+	engine := NewEngine(opts)
+	engine.ProcessRecords(records)
+	result := engine.Finalize()
+
+	for key, operationID := range map[string]string{
+		"POST /ws/stock#GetQuote":   "GetQuote",
+		"POST /ws/stock#GetPrice":   "GetPrice",
+		"POST /ws/stock#GetHistory": "GetHistory",
+		"POST /ws/stock":            "",
+	} {
+		endpoint := result.Endpoints[key]
+		if endpoint == nil {
+			t.Errorf("missing endpoint %s, got %v", key, result.Endpoints)
+			continue
+		}
+		if endpoint.OperationID != operationID {
+			t.Errorf("%s operation ID = %q, want %q", key, endpoint.OperationID, operationID)
+		}
+	}
+
+	engine = NewEngine(DefaultEngineOptions())
+	engine.ProcessRecords(records)
+	if result := engine.Finalize(); len(result.Endpoints) != 1 {
+		t.Errorf("expected a single endpoint without SOAPOperations, got %v", result.Endpoints)
+	}
+}
@@ -0,0 +1,196 @@
+package inference
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TemplateTrie indexes path templates by segment so concrete paths can be
+// matched against thousands of templates in O(segments).
+//
+// Literal segments take precedence over parameter segments. Templates added
+// with Add match any value in a parameter segment; templates added with
+// AddInferred only match values the PathInferrer would itself classify as
+// dynamic, so matching against inferred templates gives the same result as
+// re-inferring the path.
+type TemplateTrie struct {
+	mu       sync.RWMutex
+	root     *trieNode
+	inferrer *PathInferrer
+	count    int
+}
+
+type trieNode struct {
+	literals map[string]*trieNode
+	param    *trieNode
+
+	// strict is set when only inferred templates pass through this parameter
+	// node, which then only accepts segments classified as dynamic.
+	strict bool
+
+	// template and names are set on terminal nodes; names holds the parameter
+	// name for each segment ("" for literals).
+	template string
+	names    []string
+}
+
+// NewTemplateTrie creates an empty template trie.
+func NewTemplateTrie() *TemplateTrie {
+	return &TemplateTrie{
+		root:     &trieNode{},
+		inferrer: NewPathInferrer(),
+	}
+}
+
+// Add adds a known path template such as "/users/{id}".
+// Parameter segments match any value.
+func (t *TemplateTrie) Add(template string) {
+	t.add(template, false)
+}
+
+// AddInferred adds a template produced by path inference. Its parameter
+// segments only match values classified as dynamic.
+func (t *TemplateTrie) AddInferred(template string) {
+	t.add(template, true)
+}
+
+func (t *TemplateTrie) add(template string, inferred bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	segments := splitPathSegments(template)
+	names := make([]string, len(segments))
+	node := t.root
+	for i, segment := range segments {
+		if name, ok := templateParamName(segment); ok {
+			names[i] = name
+			if node.param == nil {
+				node.param = &trieNode{strict: inferred}
+			} else if !inferred {
+				node.param.strict = false
+			}
+			node = node.param
+			continue
+		}
+
+		if node.literals == nil {
+			node.literals = make(map[string]*trieNode)
+		}
+		key := templateSegment(segment)
+		child, ok := node.literals[key]
+		if !ok {
+			child = &trieNode{}
+			node.literals[key] = child
+		}
+		node = child
+	}
+
+	// The first template wins so results stay consistent across records
+	if node.template == "" {
+		node.template = template
+		node.names = names
+		t.count++
+	}
+}
+
+// Match finds the template matching a concrete path, returning the template
+// and extracted parameter values. Percent-encoded segments match their
+// decoded spelling, and parameter values are returned decoded.
+func (t *TemplateTrie) Match(path string) (template string, params map[string]string, ok bool) {
+	if idx := strings.Index(path, "?"); idx != -1 {
+		path = path[:idx]
+	}
+	segments := splitPathSegments(path)
+	decoded := make([]string, len(segments))
+	for i, segment := range segments {
+		decoded[i] = decodeSegment(segment)
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	node := t.match(t.root, segments, decoded)
+	if node == nil {
+		return "", nil, false
+	}
+
+	params = make(map[string]string)
+	for i, name := range node.names {
+		if name != "" {
+			params[name] = decoded[i]
+		}
+	}
+	return node.template, params, true
+}
+
+func (t *TemplateTrie) match(node *trieNode, segments, decoded []string) *trieNode {
+	if len(segments) == 0 {
+		if node.template != "" {
+			return node
+		}
+		return nil
+	}
+
+	segment := segments[0]
+	if child, ok := node.literals[templateSegment(segment)]; ok {
+		if found := t.match(child, segments[1:], decoded[1:]); found != nil {
+			return found
+		}
+	}
+	if node.param != nil && segment != "" {
+		if node.param.strict && t.inferrer.classifySegment(decoded[0]) == SegmentLiteral {
+			return nil
+		}
+		return t.match(node.param, segments[1:], decoded[1:])
+	}
+	return nil
+}
+
+// Len returns the number of templates in the trie.
+func (t *TemplateTrie) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.count
+}
+
+// Templates returns all templates in the trie, sorted.
+func (t *TemplateTrie) Templates() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	templates := make([]string, 0, t.count)
+	var walk func(*trieNode)
+	walk = func(node *trieNode) {
+		if node.template != "" {
+			templates = append(templates, node.template)
+		}
+		for _, child := range node.literals {
+			walk(child)
+		}
+		if node.param != nil {
+			walk(node.param)
+		}
+	}
+	walk(t.root)
+
+	sort.Strings(templates)
+	return templates
+}
+
+// splitPathSegments splits a path into segments the same way InferTemplate does.
+func splitPathSegments(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "/")
+}
+
+// templateParamName returns the parameter name of a "{name}" segment.
+func templateParamName(segment string) (string, bool) {
+	if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
@@ -0,0 +1,66 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestTemplateTrie(t *testing.T) {
+	trie := NewTemplateTrie()
+	trie.Add("/users/{id}")
+	trie.Add("/users/me")
+	trie.Add("/users/{id}/orders/{orderId}")
+	trie.AddInferred("/products/{productId}")
+
+	tests := []struct {
+		path     string
+		template string
+		params   map[string]string
+		ok       bool
+	}{
+		{"/users/42", "/users/{id}", map[string]string{"id": "42"}, true},
+		{"/users/me", "/users/me", map[string]string{}, true},
+		{"/users/42/orders/7?expand=items", "/users/{id}/orders/{orderId}", map[string]string{"id": "42", "orderId": "7"}, true},
+		{"/products/123", "/products/{productId}", map[string]string{"productId": "123"}, true},
+		// Inferred parameters only match dynamic-looking segments
+		{"/products/featured", "", nil, false},
+		{"/users/42/profile", "", nil, false},
+	}
+
+	for _, tt := range tests {
+		template, params, ok := trie.Match(tt.path)
+		if ok != tt.ok || template != tt.template {
+			t.Errorf("Match(%q) = %q, %v; want %q, %v", tt.path, template, ok, tt.template, tt.ok)
+			continue
+		}
+		for name, want := range tt.params {
+			if params[name] != want {
+				t.Errorf("Match(%q) param %s = %q, want %q", tt.path, name, params[name], want)
+			}
+		}
+	}
+
+	if trie.Len() != 4 {
+		t.Errorf("expected 4 templates, got %d", trie.Len())
+	}
+}
+
+func TestEngineMatchesKnownTemplates(t *testing.T) {
+	engine := NewEngine(DefaultEngineOptions())
+	engine.Templates().Add("/accounts/{accountId}")
+
+	engine.ProcessRecords([]ir.IRRecord{
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/accounts/acme"}, Response: ir.Response{Status: 200}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/accounts/12345"}, Response: ir.Response{Status: 200}},
+	})
+	result := engine.Finalize()
+
+	endpoint, ok := result.Endpoints["GET /accounts/{accountId}"]
+	if !ok {
+		t.Fatalf("expected known template endpoint, got %v", result.Endpoints)
+	}
+	if endpoint.RequestCount != 2 {
+		t.Errorf("expected 2 requests, got %d", endpoint.RequestCount)
+	}
+}
@@ -2,6 +2,8 @@
 package inference
 
 import (
+	"fmt"
+	"slices"
 	"sync"
 )
 
@@ -17,6 +19,7 @@ type SchemaStore struct {
 	seenCount   map[string]int    // path -> number of times seen
 	totalCount  int               // total observations
 	maxExamples int
+	memory      *ExampleMemory // optional shared example memory bounds
 }
 
 // NewSchemaStore creates a new SchemaStore with default settings.
@@ -69,14 +72,27 @@ func (s *SchemaStore) AddValue(path string, value any) {
 		}
 	}
 
+	// Truncate and intern before storing, after format detection on the full value
+	if s.memory != nil {
+		value = s.memory.prepare(value)
+	}
+
 	// Add example if unique and under limit
 	if len(s.Examples[path]) < s.maxExamples {
-		if !s.hasExample(path, value) {
+		if !s.hasExample(path, value) && (s.memory == nil || s.reserveExample(path, value)) {
 			s.Examples[path] = append(s.Examples[path], value)
 		}
 	}
 }
 
+// SetExampleMemory shares example memory bounds with the store.
+// It should be called before any values are added.
+func (s *SchemaStore) SetExampleMemory(m *ExampleMemory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memory = m
+}
+
 // hasExample checks if a value already exists in examples (no lock, internal use).
 func (s *SchemaStore) hasExample(path string, value any) bool {
 	for _, ex := range s.Examples[path] {
@@ -127,6 +143,29 @@ type EndpointData struct {
 	RequestBody  *BodyData             // request body schema
 	Responses    map[int]*ResponseData // status code -> response data
 	RequestCount int                   // number of requests observed
+	LowSample    bool                  // fewer requests than EngineOptions.MinRequestsPerEndpoint
+	NoiseCount   int                   // requests flagged as bot/scanner traffic (NoiseModeTag)
+	Metadata     map[string][]string   // record metadata key -> distinct observed values
+	Hosts        []string              // distinct hosts the endpoint was observed on
+	ExamplePairs []*ExamplePair        // observed transactions (EngineOptions.ExamplePairs)
+	Action       CRUDAction            // CRUD semantics, "" for other operations (see ClassifyCRUD)
+	Bulk         bool                  // operates on many resources at once (see IsBulkPath)
+
+	// InvalidExamples holds transactions answered with 400 or 422, whose
+	// request bodies show what not to send (EngineOptions.InvalidExamples).
+	InvalidExamples []*ExamplePair
+
+	// ConditionalRequests is set when requests used If-Match, If-None-Match,
+	// If-Modified-Since, or If-Unmodified-Since, or received 304 or 412.
+	ConditionalRequests bool
+
+	// Preconditions is set when writes used If-Match or If-Unmodified-Since,
+	// or were answered 412 Precondition Failed or 428 Precondition Required.
+	Preconditions *Preconditions
+
+	// EarlyHints holds the distinct Link header values sent in 103 Early
+	// Hints responses ahead of the endpoint's final responses.
+	EarlyHints []string
 
 	// Documentation fields (from IR records)
 	OperationID  string            // explicit operation ID (e.g., "getUserById")
@@ -137,6 +176,17 @@ type EndpointData struct {
 	ExternalDocs *ExternalDocsData // external documentation reference
 }
 
+// ExamplePair holds the request and response bodies of one observed
+// transaction, so examples in the spec can show a matching request and
+// response.
+type ExamplePair struct {
+	Status              int
+	RequestBody         any
+	RequestContentType  string
+	ResponseBody        any
+	ResponseContentType string
+}
+
 // NewEndpointData creates a new EndpointData.
 func NewEndpointData(method, pathTemplate string) *EndpointData {
 	return &EndpointData{
@@ -149,14 +199,63 @@ func NewEndpointData(method, pathTemplate string) *EndpointData {
 	}
 }
 
+// addExamplePair keeps a transaction unless limit transactions with its status
+// are already kept.
+func (e *EndpointData) addExamplePair(limit int, pair *ExamplePair) {
+	count := 0
+	for _, p := range e.ExamplePairs {
+		if p.Status == pair.Status {
+			count++
+		}
+	}
+	if count < limit {
+		e.ExamplePairs = append(e.ExamplePairs, pair)
+	}
+}
+
+// maxMetadataValues bounds the distinct values kept per metadata key.
+const maxMetadataValues = 20
+
+// AddMetadata records the metadata values of a request to the endpoint.
+// Values are compared as strings; at most maxMetadataValues distinct values
+// are kept per key.
+func (e *EndpointData) AddMetadata(metadata map[string]any) {
+	for key, value := range metadata {
+		if value == nil {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			str = fmt.Sprint(value)
+		}
+		if e.Metadata == nil {
+			e.Metadata = make(map[string][]string)
+		}
+		values := e.Metadata[key]
+		if len(values) >= maxMetadataValues || slices.Contains(values, str) {
+			continue
+		}
+		e.Metadata[key] = append(values, str)
+	}
+}
+
 // ParamData tracks parameter values and infers type/format.
 type ParamData struct {
-	Name      string
-	Examples  []any
-	Type      string // string, integer, number, boolean
-	Format    string // uuid, email, date-time, etc.
-	Required  bool
-	seenCount int
+	Name       string
+	Examples   []any
+	Type       string // string, integer, number, boolean, array, object
+	Format     string // uuid, email, date-time, etc.
+	Required   bool
+	Style      string                // serialization style (form, deepObject, matrix)
+	Explode    *bool                 // serialization explode setting (nil for default)
+	Items      *ParamData            // item data for array parameters
+	Properties map[string]*ParamData // property data for deepObject parameters
+	Dialect    string                // query language of expression parameters (QueryDialectOData, ...)
+	Enum       []string              // observed values of locale and tenant path parameters
+	Aliases    []string              // other spellings of the name, merged into this parameter
+	Stats      *ParamStats           // statistics of the distinct values of path parameters
+	seenCount  int
+	noEnum     bool
 }
 
 // NewParamData creates a new ParamData.
@@ -199,34 +298,125 @@ func (p *ParamData) AddValue(value any) {
 	}
 }
 
+// SeenCount returns the number of observations recorded for the parameter.
+func (p *ParamData) SeenCount() int {
+	return p.seenCount
+}
+
+// Frequency returns the fraction of total requests in which the parameter was observed.
+func (p *ParamData) Frequency(total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(p.seenCount) / float64(total)
+}
+
+// AddArrayValue adds a multi-valued observation (e.g., ?tag=a&tag=b or ?tag=a,b).
+// explode is true for repeated keys and false for comma-separated values.
+func (p *ParamData) AddArrayValue(values []any, explode bool) {
+	p.seenCount++
+
+	// Convert a previously scalar parameter, keeping its examples as item examples
+	if p.Items == nil {
+		p.Items = NewParamData(p.Name)
+		if p.Type != TypeArray {
+			for _, ex := range p.Examples {
+				p.Items.AddValue(ex)
+			}
+			p.Examples = make([]any, 0, 5)
+		}
+	}
+	p.Type = TypeArray
+	p.Style = "form"
+	if p.Explode == nil {
+		p.Explode = &explode
+	}
+
+	for _, v := range values {
+		p.Items.AddValue(v)
+	}
+
+	if len(p.Examples) < 5 {
+		for _, ex := range p.Examples {
+			if valuesEqual(ex, values) {
+				return
+			}
+		}
+		p.Examples = append(p.Examples, values)
+	}
+}
+
+// AddObjectValue adds a deepObject observation (e.g., ?filter[status]=active).
+func (p *ParamData) AddObjectValue(props map[string]any) {
+	p.seenCount++
+	p.Type = TypeObject
+	p.Style = "deepObject"
+	explode := true
+	p.Explode = &explode
+
+	if p.Properties == nil {
+		p.Properties = make(map[string]*ParamData)
+	}
+	for name, v := range props {
+		prop, exists := p.Properties[name]
+		if !exists {
+			prop = NewParamData(name)
+			p.Properties[name] = prop
+		}
+		prop.AddValue(v)
+	}
+
+	if len(p.Examples) < 5 {
+		for _, ex := range p.Examples {
+			if valuesEqual(ex, props) {
+				return
+			}
+		}
+		p.Examples = append(p.Examples, props)
+	}
+}
+
 // BodyData tracks request/response body schema.
 type BodyData struct {
-	ContentType string
-	Schema      *SchemaStore
+	ContentType  string
+	ContentTypes map[string]int // raw observed content type -> request count
+	Schema       *SchemaStore
+	Count        int  // requests observed with a non-empty body
+	Required     bool // every request to the endpoint had a body (set on finalize)
+
+	// Multipart counts the multipart/form-data bodies parsed into text
+	// fields, which feed Schema, and files, recorded in FileParts by field.
+	Multipart int
+	FileParts map[string]*FilePart
 }
 
 // NewBodyData creates a new BodyData.
 func NewBodyData(contentType string) *BodyData {
 	return &BodyData{
-		ContentType: contentType,
-		Schema:      NewSchemaStore(),
+		ContentType:  contentType,
+		ContentTypes: make(map[string]int),
+		Schema:       NewSchemaStore(),
 	}
 }
 
 // ResponseData tracks response information for a status code.
 type ResponseData struct {
-	StatusCode  int
-	ContentType string
-	Headers     map[string]*ParamData
-	Body        *SchemaStore
+	StatusCode   int
+	ContentType  string
+	ContentTypes map[string]int // raw observed content type -> response count
+	Headers      map[string]*ParamData
+	Body         *SchemaStore
+	MultiStatus  *MultiStatusData // per-item-status schemas of 207 bodies, nil otherwise
+	Download     *DownloadData    // Content-Disposition: attachment responses, nil otherwise
 }
 
 // NewResponseData creates a new ResponseData.
 func NewResponseData(statusCode int) *ResponseData {
 	return &ResponseData{
-		StatusCode: statusCode,
-		Headers:    make(map[string]*ParamData),
-		Body:       NewSchemaStore(),
+		StatusCode:   statusCode,
+		ContentTypes: make(map[string]int),
+		Headers:      make(map[string]*ParamData),
+		Body:         NewSchemaStore(),
 	}
 }
 
@@ -266,6 +456,13 @@ type InferenceResult struct {
 	SecuritySchemes  map[string]*DetectedSecurityScheme // detected authentication schemes
 	PaginationParams map[string]*PaginationParam        // detected pagination parameters
 	RateLimitHeaders map[string]*RateLimitHeader        // detected rate limit headers
+	Excluded         map[string]*ExcludedEndpoint       // endpoints removed by pruning options
+	FilteredRecords  map[string]int                     // filter reason -> records dropped before inference
+	ErrorCodes       []*ErrorCode                       // error codes in 4xx/5xx response bodies, most frequent first
+	Links            []*DetectedLink                    // response fields reused as path parameters (EngineOptions.DetectLinks)
+	CORS             []*CORSPolicy                      // Access-Control-* response headers by host and endpoint
+	AsyncOperations  []*AsyncOperation                  // 202 Accepted endpoints and the status monitors polled for them
+	PathVariants     map[string][]string                // path template -> spellings merged into it (EngineOptions.CaseInsensitivePaths, IgnoreTrailingSlash)
 
 	// API metadata (from IR batch metadata)
 	APIMetadata *APIMetadataData
@@ -280,5 +477,7 @@ func NewInferenceResult() *InferenceResult {
 		SecuritySchemes:  make(map[string]*DetectedSecurityScheme),
 		PaginationParams: make(map[string]*PaginationParam),
 		RateLimitHeaders: make(map[string]*RateLimitHeader),
+		Excluded:         make(map[string]*ExcludedEndpoint),
+		FilteredRecords:  make(map[string]int),
 	}
 }
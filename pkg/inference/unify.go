@@ -0,0 +1,86 @@
+package inference
+
+import (
+	"sort"
+	"strings"
+)
+
+// PathUnifier maps path templates that differ only in letter case or a
+// trailing slash to the spelling seen first, recording the spellings it
+// merged. It is not safe for concurrent use.
+type PathUnifier struct {
+	caseInsensitive bool
+	trailingSlash   bool
+	canonical       map[string]string          // folded template -> canonical template
+	variants        map[string]map[string]bool // canonical template -> other spellings
+}
+
+// NewPathUnifier creates a PathUnifier. caseInsensitive merges templates
+// whose literal segments differ only in case; trailingSlash merges templates
+// that differ only in a trailing slash.
+func NewPathUnifier(caseInsensitive, trailingSlash bool) *PathUnifier {
+	return &PathUnifier{
+		caseInsensitive: caseInsensitive,
+		trailingSlash:   trailingSlash,
+		canonical:       make(map[string]string),
+		variants:        make(map[string]map[string]bool),
+	}
+}
+
+// Unify returns the canonical template for a request path and its resolved
+// template.
+func (u *PathUnifier) Unify(path, template string) string {
+	spelling := template
+	if u.trailingSlash {
+		// Inferred templates never keep a trailing slash; remember the path's
+		if len(path) > 1 && strings.HasSuffix(path, "/") && !strings.HasSuffix(template, "/") {
+			spelling = template + "/"
+		}
+		if len(template) > 1 {
+			template = strings.TrimSuffix(template, "/")
+		}
+	}
+
+	key := template
+	if u.caseInsensitive {
+		key = foldTemplate(template)
+	}
+	canonical, ok := u.canonical[key]
+	if !ok {
+		canonical = template
+		u.canonical[key] = canonical
+	}
+	if spelling != canonical {
+		if u.variants[canonical] == nil {
+			u.variants[canonical] = make(map[string]bool)
+		}
+		u.variants[canonical][spelling] = true
+	}
+	return canonical
+}
+
+// Variants returns the spellings merged into each canonical template, sorted.
+func (u *PathUnifier) Variants() map[string][]string {
+	result := make(map[string][]string, len(u.variants))
+	for canonical, spellings := range u.variants {
+		list := make([]string, 0, len(spellings))
+		for spelling := range spellings {
+			list = append(list, spelling)
+		}
+		sort.Strings(list)
+		result[canonical] = list
+	}
+	return result
+}
+
+// foldTemplate lowercases the literal segments of a template, leaving
+// parameter names as they are.
+func foldTemplate(template string) string {
+	segments := strings.Split(template, "/")
+	for i, segment := range segments {
+		if _, ok := templateParamName(segment); !ok {
+			segments[i] = strings.ToLower(segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,62 @@
+package inference
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestPathUnification(t *testing.T) {
+	withTemplate := func(path, template string) ir.IRRecord {
+		record := ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: path},
+			Response: ir.Response{Status: 200},
+		}
+		if template != "" {
+			record.Request.PathTemplate = &template
+		}
+		return record
+	}
+	records := []ir.IRRecord{
+		withTemplate("/Users", ""),
+		withTemplate("/users", ""),
+		withTemplate("/users/", ""),
+		withTemplate("/USERS/42", ""),
+		withTemplate("/users/7", ""),
+		withTemplate("/orders/", "/orders/"),
+		withTemplate("/orders", "/orders"),
+	}
+	process := func(opts EngineOptions) *InferenceResult {
+		engine := NewEngine(opts)
+		engine.ProcessRecords(records)
+		return engine.Finalize()
+	}
+
+	opts := DefaultEngineOptions()
+	opts.CaseInsensitivePaths = true
+	opts.IgnoreTrailingSlash = true
+	result := process(opts)
+
+	want := map[string]int{"GET /Users": 3, "GET /USERS/{userId}": 2, "GET /orders": 2}
+	if len(result.Endpoints) != len(want) {
+		t.Errorf("expected endpoints %v, got %v", want, result.Endpoints)
+	}
+	for key, count := range want {
+		if endpoint := result.Endpoints[key]; endpoint == nil || endpoint.RequestCount != count {
+			t.Errorf("%s = %+v, want %d requests", key, endpoint, count)
+		}
+	}
+	wantVariants := map[string][]string{
+		"/Users":          {"/users", "/users/"},
+		"/USERS/{userId}": {"/users/{userId}"},
+		"/orders":         {"/orders/"},
+	}
+	if !reflect.DeepEqual(result.PathVariants, wantVariants) {
+		t.Errorf("PathVariants = %v, want %v", result.PathVariants, wantVariants)
+	}
+
+	if result := process(DefaultEngineOptions()); len(result.Endpoints) != 6 || result.PathVariants != nil {
+		t.Errorf("expected 6 endpoints and no variants by default, got %v, %v", result.Endpoints, result.PathVariants)
+	}
+}
@@ -0,0 +1,76 @@
+package inference
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxValueArrayItems bounds the array elements BodyValues visits per array.
+const maxValueArrayItems = 20
+
+// BodyValues returns the string and integer values in a decoded JSON body,
+// mapped to the JSON pointers (RFC 6901) where they occur, in sorted key
+// order. Numbers are formatted without a fraction; booleans, nulls, empty
+// strings, and non-integer numbers are skipped. Only the first elements of
+// large arrays are visited.
+func BodyValues(body interface{}) map[string][]string {
+	values := make(map[string][]string)
+	collectBodyValues(body, "", values)
+	return values
+}
+
+func collectBodyValues(node interface{}, pointer string, values map[string][]string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectBodyValues(v[key], pointer+"/"+escapePointer(key), values)
+		}
+	case []interface{}:
+		for i, item := range v {
+			if i == maxValueArrayItems {
+				break
+			}
+			collectBodyValues(item, pointer+"/"+strconv.Itoa(i), values)
+		}
+	default:
+		if value, ok := ScalarString(v); ok && pointer != "" {
+			values[value] = append(values[value], pointer)
+		}
+	}
+}
+
+// ScalarString returns the string form of a string or integer value, as
+// matched against path and query parameters.
+func ScalarString(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, x != ""
+	case float64:
+		if x != float64(int64(x)) {
+			return "", false
+		}
+		return strconv.FormatInt(int64(x), 10), true
+	case int:
+		return strconv.Itoa(x), true
+	case int64:
+		return strconv.FormatInt(x, 10), true
+	case json.Number:
+		if _, err := x.Int64(); err != nil {
+			return "", false
+		}
+		return x.String(), true
+	}
+	return "", false
+}
+
+// escapePointer escapes a JSON pointer reference token.
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,30 @@
+package inference
+
+import "testing"
+
+func TestBodyValues(t *testing.T) {
+	body := map[string]interface{}{
+		"id":    float64(42),
+		"ratio": 0.5,
+		"ok":    true,
+		"a/b":   "slash",
+		"items": []interface{}{
+			map[string]interface{}{"id": "x1"},
+			map[string]interface{}{"id": float64(42)},
+		},
+	}
+	values := BodyValues(body)
+
+	if got := values["42"]; len(got) != 2 || got[0] != "/id" || got[1] != "/items/1/id" {
+		t.Errorf("expected 42 at /id and /items/1/id, got %v", got)
+	}
+	if got := values["slash"]; len(got) != 1 || got[0] != "/a~1b" {
+		t.Errorf("expected escaped pointer /a~1b, got %v", got)
+	}
+	if _, ok := values["0.5"]; ok {
+		t.Error("expected non-integer numbers to be skipped")
+	}
+	if _, ok := values["true"]; ok {
+		t.Error("expected booleans to be skipped")
+	}
+}
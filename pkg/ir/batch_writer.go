@@ -0,0 +1,107 @@
+package ir
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// BatchWriter provides streaming writes for the batch JSON format, so that
+// batch files can be written without holding every record in memory. The
+// batch metadata (record count and generation time) is written after the
+// records, when the count is known.
+type BatchWriter struct {
+	w      *bufio.Writer
+	closer io.Closer
+	count  int
+	closed bool
+}
+
+// NewBatchWriter creates a writer for streaming batch JSON output.
+func NewBatchWriter(w io.Writer) *BatchWriter {
+	return &BatchWriter{w: bufio.NewWriter(w)}
+}
+
+// NewBatchFileWriter creates a writer for streaming batch JSON to a file.
+func NewBatchFileWriter(path string) (*BatchWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating file: %w", err)
+	}
+
+	w := NewBatchWriter(f)
+	w.closer = f
+	return w, nil
+}
+
+// Write writes a single record.
+func (w *BatchWriter) Write(record *IRRecord) error {
+	data, err := json.MarshalIndent(record, "    ", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling record: %w", err)
+	}
+
+	prefix := ",\n    "
+	if w.count == 0 {
+		prefix = fmt.Sprintf("{\n  \"version\": %q,\n  \"records\": [\n    ", Version)
+	}
+	if _, err := w.w.WriteString(prefix); err != nil {
+		return fmt.Errorf("writing record: %w", err)
+	}
+	if _, err := w.w.Write(data); err != nil {
+		return fmt.Errorf("writing record: %w", err)
+	}
+	w.count++
+	return nil
+}
+
+// Flush flushes buffered data.
+func (w *BatchWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// Close writes the end of the batch and the metadata, flushes, and closes
+// the underlying writer if it implements io.Closer.
+func (w *BatchWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if err := w.writeEnd(); err != nil {
+		return err
+	}
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if w.closer != nil {
+		return w.closer.Close()
+	}
+	return nil
+}
+
+func (w *BatchWriter) writeEnd() error {
+	now := time.Now().UTC()
+	count := w.count
+	metadata, err := json.MarshalIndent(&APIMetadata{GeneratedAt: &now, RecordCount: &count}, "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling metadata: %w", err)
+	}
+
+	end := "\n  ],\n  \"metadata\": "
+	if w.count == 0 {
+		end = fmt.Sprintf("{\n  \"version\": %q,\n  \"records\": [],\n  \"metadata\": ", Version)
+	}
+	if _, err := fmt.Fprintf(w.w, "%s%s\n}\n", end, metadata); err != nil {
+		return fmt.Errorf("writing batch: %w", err)
+	}
+	return nil
+}
+
+// Count returns the number of records written.
+func (w *BatchWriter) Count() int {
+	return w.count
+}
@@ -0,0 +1,83 @@
+package ir
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestBatchWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewBatchWriter(&buf)
+
+	paths := []string{"/a", "/b", "/c"}
+	for _, path := range paths {
+		if err := w.Write(NewRecord(RequestMethodGET, path, 200)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if w.Count() != 3 {
+		t.Errorf("expected count 3, got %d", w.Count())
+	}
+
+	var batch Batch
+	if err := json.Unmarshal(buf.Bytes(), &batch); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if batch.Version != Version {
+		t.Errorf("expected version %s, got %s", Version, batch.Version)
+	}
+	if batch.Metadata == nil || batch.Metadata.RecordCount == nil || *batch.Metadata.RecordCount != 3 {
+		t.Errorf("expected record count 3 in metadata, got %+v", batch.Metadata)
+	}
+	if len(batch.Records) != len(paths) {
+		t.Fatalf("expected %d records, got %d", len(paths), len(batch.Records))
+	}
+	for i, path := range paths {
+		if batch.Records[i].Request.Path != path {
+			t.Errorf("record %d: expected %s, got %s", i, path, batch.Records[i].Request.Path)
+		}
+	}
+}
+
+func TestBatchWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewBatchWriter(&buf)
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	records, err := ReadBatch(&buf)
+	if err != nil {
+		t.Fatalf("ReadBatch failed: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records, got %d", len(records))
+	}
+}
+
+func TestBatchFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	w, err := NewBatchFileWriter(path)
+	if err != nil {
+		t.Fatalf("NewBatchFileWriter failed: %v", err)
+	}
+	if err := w.Write(NewRecord(RequestMethodPOST, "/users", 201)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	records, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Request.Path != "/users" {
+		t.Errorf("unexpected records: %+v", records)
+	}
+}
@@ -0,0 +1,109 @@
+package ir
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// benchRecords returns realistic API records with nested JSON bodies.
+func benchRecords(n int) []IRRecord {
+	records := make([]IRRecord, n)
+	for i := range records {
+		id := fmt.Sprintf("req-%06d", i)
+		host := "api.example.com"
+		ct := "application/json"
+		records[i] = IRRecord{
+			Id: &id,
+			Request: Request{
+				Method:  RequestMethodPOST,
+				Host:    &host,
+				Path:    fmt.Sprintf("/api/v1/users/%d/orders", i),
+				Query:   map[string]interface{}{"expand": "items"},
+				Headers: map[string]string{"accept": "application/json", "content-type": ct},
+				Body: map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"sku": "A-100", "quantity": 2, "price": 19.99},
+						map[string]interface{}{"sku": "B-200", "quantity": 1, "price": 5.49},
+					},
+					"note": "Leave at the front door",
+				},
+			},
+			Response: Response{
+				Status:      201,
+				ContentType: &ct,
+				Body: map[string]interface{}{
+					"id":        fmt.Sprintf("ord_%d", i),
+					"status":    "pending",
+					"total":     45.47,
+					"createdAt": "2024-01-15T10:30:00Z",
+				},
+			},
+		}
+	}
+	return records
+}
+
+func BenchmarkNDJSONWriter(b *testing.B) {
+	records := benchRecords(1000)
+	b.ReportAllocs()
+	for b.Loop() {
+		w := NewNDJSONWriter(io.Discard)
+		for i := range records {
+			if err := w.Write(&records[i]); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGzipNDJSONWriter(b *testing.B) {
+	records := benchRecords(1000)
+	b.ReportAllocs()
+	for b.Loop() {
+		w := NewGzipNDJSONWriter(io.Discard)
+		for i := range records {
+			if err := w.Write(&records[i]); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteNDJSON(b *testing.B) {
+	records := benchRecords(1000)
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := WriteNDJSON(io.Discard, records); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNDJSONReader(b *testing.B) {
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, benchRecords(1000)); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		r := NewNDJSONReader(bytes.NewReader(data))
+		for {
+			if _, err := r.Read(); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package ir
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// binaryMediaTypes are application/* media types that carry binary data.
+var binaryMediaTypes = map[string]bool{
+	"application/octet-stream":     true,
+	"application/pdf":              true,
+	"application/zip":              true,
+	"application/gzip":             true,
+	"application/x-gzip":           true,
+	"application/x-tar":            true,
+	"application/x-7z-compressed":  true,
+	"application/x-rar-compressed": true,
+	"application/x-bzip2":          true,
+	"application/msword":           true,
+	"application/vnd.ms-excel":     true,
+	"application/protobuf":         true,
+	"application/x-protobuf":       true,
+	"application/wasm":             true,
+	"application/font-woff":        true,
+}
+
+// IsBinaryContentType reports whether a content type carries binary data:
+// images (except SVG), audio, video, fonts, PDFs, archives, Office
+// documents, protobuf, and application/octet-stream.
+func IsBinaryContentType(contentType string) bool {
+	base, _, _ := strings.Cut(contentType, ";")
+	base = strings.ToLower(strings.TrimSpace(base))
+
+	typ, subtype, ok := strings.Cut(base, "/")
+	if !ok {
+		return false
+	}
+	switch typ {
+	case "image":
+		return subtype != "svg+xml"
+	case "audio", "video", "font":
+		return true
+	case "application":
+		return binaryMediaTypes[base] ||
+			strings.HasPrefix(subtype, "vnd.openxmlformats-officedocument.") ||
+			strings.HasPrefix(subtype, "x-font-")
+	}
+	return false
+}
+
+// IsBinaryData reports whether data looks binary rather than text: it
+// contains NUL bytes or is not valid UTF-8. A multi-byte character cut off at
+// the end, as when a body is truncated to a size limit, is not counted.
+func IsBinaryData(data []byte) bool {
+	if bytes.IndexByte(data, 0) != -1 {
+		return true
+	}
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 {
+			return utf8.FullRune(data)
+		}
+		data = data[size:]
+	}
+	return false
+}
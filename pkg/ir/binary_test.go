@@ -0,0 +1,45 @@
+package ir
+
+import "testing"
+
+func TestIsBinaryContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"image/png", true},
+		{"IMAGE/JPEG; q=0.9", true},
+		{"image/svg+xml", false},
+		{"application/pdf", true},
+		{"application/octet-stream", true},
+		{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", true},
+		{"video/mp4", true},
+		{"font/woff2", true},
+		{"application/json", false},
+		{"text/plain; charset=utf-8", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsBinaryContentType(tt.contentType); got != tt.want {
+			t.Errorf("IsBinaryContentType(%q) = %v, want %v", tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestIsBinaryData(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"text", []byte(`{"name":"café"}`), false},
+		{"nul byte", []byte("ab\x00cd"), true},
+		{"invalid utf-8", []byte{0x89, 'P', 'N', 'G'}, true},
+		{"truncated character", []byte("caf\xc3"), false},
+	}
+	for _, tt := range tests {
+		if got := IsBinaryData(tt.data); got != tt.want {
+			t.Errorf("%s: IsBinaryData = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ir
+
+import (
+	"maps"
+	"slices"
+)
+
+// CloneRecord returns a deep copy of a record: its header, query, and
+// metadata maps, bodies, and slices are copied, so changes to the copy do not
+// affect the original.
+func CloneRecord(record IRRecord) IRRecord {
+	record.Tags = slices.Clone(record.Tags)
+	record.Metadata = cloneObject(record.Metadata)
+
+	record.Request.PathParams = maps.Clone(record.Request.PathParams)
+	record.Request.Query = cloneObject(record.Request.Query)
+	record.Request.Headers = maps.Clone(record.Request.Headers)
+	record.Request.HeaderValues = cloneHeaderValues(record.Request.HeaderValues)
+	record.Request.Trailers = maps.Clone(record.Request.Trailers)
+	record.Request.Body = cloneValue(record.Request.Body)
+
+	record.Response.Headers = maps.Clone(record.Response.Headers)
+	record.Response.HeaderValues = cloneHeaderValues(record.Response.HeaderValues)
+	record.Response.Trailers = maps.Clone(record.Response.Trailers)
+	record.Response.Body = cloneValue(record.Response.Body)
+	if record.Response.Informational != nil {
+		informational := make([]InformationalResponse, len(record.Response.Informational))
+		for i, info := range record.Response.Informational {
+			info.Headers = maps.Clone(info.Headers)
+			info.HeaderValues = cloneHeaderValues(info.HeaderValues)
+			informational[i] = info
+		}
+		record.Response.Informational = informational
+	}
+
+	return record
+}
+
+func cloneHeaderValues(values map[string][]string) map[string][]string {
+	if values == nil {
+		return nil
+	}
+	clone := make(map[string][]string, len(values))
+	for k, v := range values {
+		clone[k] = slices.Clone(v)
+	}
+	return clone
+}
+
+func cloneObject(object map[string]interface{}) map[string]interface{} {
+	if object == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(object))
+	for k, v := range object {
+		clone[k] = cloneValue(v)
+	}
+	return clone
+}
+
+// cloneValue copies decoded JSON values: objects, arrays, and string lists.
+func cloneValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return cloneObject(val)
+	case []interface{}:
+		clone := make([]interface{}, len(val))
+		for i, child := range val {
+			clone[i] = cloneValue(child)
+		}
+		return clone
+	case []string:
+		return slices.Clone(val)
+	case []byte:
+		return slices.Clone(val)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,105 @@
+package ir
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultDedupeWindow is the timestamp bucket width used by SemanticDeduper
+// when none is given.
+const DefaultDedupeWindow = time.Second
+
+// SemanticKey identifies the request of a record independently of how it was
+// captured: method, path, query, and a hash of the request body. Bodies
+// captured as JSON text and as decoded JSON hash the same.
+func SemanticKey(record *IRRecord) string {
+	query, _ := json.Marshal(record.Request.Query) // map keys are sorted
+	return fmt.Sprintf("%s %s?%s %s", record.Request.Method, record.Request.Path, query, bodyHash(record.Request.Body))
+}
+
+// bodyHash returns the ContentHash of a body's JSON encoding, decoding
+// string bodies that hold JSON first, or "" for an empty body.
+func bodyHash(body any) string {
+	if s, ok := body.(string); ok {
+		if s == "" {
+			return ""
+		}
+		var decoded any
+		if json.Unmarshal([]byte(s), &decoded) == nil {
+			body = decoded
+		}
+	}
+	if body == nil {
+		return ""
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return ""
+	}
+	return ContentHash(data)
+}
+
+// SemanticDeduper detects records captured by more than one source, such as
+// a browser HAR export and a server log, which never share record IDs.
+// Records match when their SemanticKey is equal and their timestamps fall in
+// the same or an adjacent bucket of the window width.
+//
+// Repeated requests within a source are real traffic and are kept: a record
+// is a duplicate only while another source has more matching records than
+// its own source has seen so far. Records without a timestamp are never
+// duplicates.
+type SemanticDeduper struct {
+	window time.Duration
+	counts map[semanticBucket]map[int]int
+}
+
+type semanticBucket struct {
+	key    string
+	bucket int64
+}
+
+// NewSemanticDeduper creates a deduper with the given timestamp bucket width
+// (DefaultDedupeWindow if window <= 0).
+func NewSemanticDeduper(window time.Duration) *SemanticDeduper {
+	if window <= 0 {
+		window = DefaultDedupeWindow
+	}
+	return &SemanticDeduper{
+		window: window,
+		counts: make(map[semanticBucket]map[int]int),
+	}
+}
+
+// Duplicate records a record from the source with the given index and
+// reports whether it duplicates a record of another source.
+func (d *SemanticDeduper) Duplicate(record *IRRecord, source int) bool {
+	if record.Timestamp == nil {
+		return false
+	}
+	key := SemanticKey(record)
+	bucket := record.Timestamp.UnixNano() / int64(d.window)
+
+	// Count matching records per source across adjacent buckets
+	matches := make(map[int]int)
+	for b := bucket - 1; b <= bucket+1; b++ {
+		for s, n := range d.counts[semanticBucket{key, b}] {
+			matches[s] += n
+		}
+	}
+	own := matches[source]
+	duplicate := false
+	for s, n := range matches {
+		if s != source && n > own {
+			duplicate = true
+			break
+		}
+	}
+
+	k := semanticBucket{key, bucket}
+	if d.counts[k] == nil {
+		d.counts[k] = make(map[int]int)
+	}
+	d.counts[k][source]++
+	return duplicate
+}
@@ -0,0 +1,68 @@
+package ir
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSemanticKey(t *testing.T) {
+	a := NewRecord(RequestMethodPOST, "/users", 201)
+	a.SetRequestBody(`{"name":"Alice","age":30}`)
+	b := NewRecord(RequestMethodPOST, "/users", 200)
+	b.SetRequestBody(map[string]any{"age": 30.0, "name": "Alice"})
+	if SemanticKey(a) != SemanticKey(b) {
+		t.Errorf("expected JSON text and decoded bodies to match:\n%s\n%s", SemanticKey(a), SemanticKey(b))
+	}
+
+	c := NewRecord(RequestMethodPOST, "/users", 201)
+	c.SetRequestBody(`{"name":"Bob"}`)
+	if SemanticKey(a) == SemanticKey(c) {
+		t.Error("expected different bodies to have different keys")
+	}
+
+	d := NewRecord(RequestMethodGET, "/users", 200)
+	d.SetQuery(map[string]any{"page": "2"})
+	e := NewRecord(RequestMethodGET, "/users", 200)
+	if SemanticKey(d) == SemanticKey(e) {
+		t.Error("expected different queries to have different keys")
+	}
+}
+
+func TestSemanticDeduper(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	record := func(path string, offset time.Duration) *IRRecord {
+		r := NewRecord(RequestMethodGET, path, 200)
+		r.SetTimestamp(start.Add(offset))
+		return r
+	}
+
+	d := NewSemanticDeduper(time.Second)
+
+	// Repeated requests within a source are kept
+	if d.Duplicate(record("/poll", 0), 0) || d.Duplicate(record("/poll", 100*time.Millisecond), 0) {
+		t.Error("expected repeated requests within a source to be kept")
+	}
+
+	// Another source's captures of the same requests are duplicates, even
+	// across a bucket boundary; a third request is not
+	if !d.Duplicate(record("/poll", 900*time.Millisecond), 1) {
+		t.Error("expected first match from another source to be a duplicate")
+	}
+	if !d.Duplicate(record("/poll", 1100*time.Millisecond), 1) {
+		t.Error("expected match in adjacent bucket to be a duplicate")
+	}
+	if d.Duplicate(record("/poll", 1200*time.Millisecond), 1) {
+		t.Error("expected request beyond the other source's count to be kept")
+	}
+
+	// Requests far apart in time are not duplicates
+	if d.Duplicate(record("/poll", time.Minute), 1) {
+		t.Error("expected request outside the window to be kept")
+	}
+
+	// Records without timestamps are never duplicates
+	untimed := NewRecord(RequestMethodGET, "/poll", 200)
+	if d.Duplicate(untimed, 0) || d.Duplicate(untimed, 1) {
+		t.Error("expected records without timestamps to be kept")
+	}
+}
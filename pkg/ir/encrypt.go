@@ -0,0 +1,273 @@
+package ir
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EncryptedValuePrefix starts every value encrypted by a FieldEncryptor.
+const EncryptedValuePrefix = "enc:v1:"
+
+// fieldEncryptionInfo binds derived keys to this encryption scheme.
+const fieldEncryptionInfo = "traffic2openapi field encryption v1"
+
+// FieldEncryptor encrypts individual values for the holder of an X25519
+// private key. Each value is encrypted with AES-256-GCM under a key derived
+// from a fresh ephemeral X25519 key, and encoded as EncryptedValuePrefix
+// followed by the base64url ephemeral public key, nonce, and ciphertext.
+type FieldEncryptor struct {
+	recipient *ecdh.PublicKey
+}
+
+// NewFieldEncryptor creates a FieldEncryptor for an X25519 public key.
+func NewFieldEncryptor(recipient *ecdh.PublicKey) (*FieldEncryptor, error) {
+	if recipient == nil || recipient.Curve() != ecdh.X25519() {
+		return nil, errors.New("field encryption requires an X25519 public key")
+	}
+	return &FieldEncryptor{recipient: recipient}, nil
+}
+
+// Encrypt encrypts the JSON encoding of v, so DecryptValue restores strings,
+// numbers, and objects alike.
+func (e *FieldEncryptor) Encrypt(v any) (string, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encoding value: %w", err)
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("generating ephemeral key: %w", err)
+	}
+	shared, err := ephemeral.ECDH(e.recipient)
+	if err != nil {
+		return "", fmt.Errorf("deriving shared secret: %w", err)
+	}
+	aead, err := fieldAEAD(shared, ephemeral.PublicKey(), e.recipient)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+
+	out := append(ephemeral.PublicKey().Bytes(), nonce...)
+	out = aead.Seal(out, nonce, plaintext, nil)
+	return EncryptedValuePrefix + base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// IsEncryptedValue reports whether v is a value encrypted by a FieldEncryptor.
+func IsEncryptedValue(v any) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, EncryptedValuePrefix)
+}
+
+// DecryptValue decrypts a value encrypted by a FieldEncryptor.
+func DecryptValue(key *ecdh.PrivateKey, value string) (any, error) {
+	encoded, ok := strings.CutPrefix(value, EncryptedValuePrefix)
+	if !ok {
+		return nil, errors.New("value is not encrypted")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decoding value: %w", err)
+	}
+	keySize := len(key.PublicKey().Bytes())
+	if len(data) < keySize {
+		return nil, errors.New("encrypted value is truncated")
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(data[:keySize])
+	if err != nil {
+		return nil, fmt.Errorf("reading ephemeral key: %w", err)
+	}
+	shared, err := key.ECDH(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("deriving shared secret: %w", err)
+	}
+	aead, err := fieldAEAD(shared, ephemeral, key.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	data = data[keySize:]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("encrypted value is truncated")
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting value: %w", err)
+	}
+	var v any
+	if err := json.Unmarshal(plaintext, &v); err != nil {
+		return nil, fmt.Errorf("decoding decrypted value: %w", err)
+	}
+	return v, nil
+}
+
+// fieldAEAD derives the AES-GCM cipher for a value from an X25519 shared
+// secret, salted with the ephemeral and recipient public keys.
+func fieldAEAD(shared []byte, ephemeral, recipient *ecdh.PublicKey) (cipher.AEAD, error) {
+	salt := append(ephemeral.Bytes(), recipient.Bytes()...)
+	key, err := hkdf.Key(sha256.New, shared, salt, fieldEncryptionInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("deriving key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// DecryptRecord returns a copy of the record with encrypted header, query,
+// and body values decrypted. The input record is not modified.
+func DecryptRecord(record IRRecord, key *ecdh.PrivateKey) (IRRecord, error) {
+	var err error
+	if record.Request.Headers, err = decryptHeaders(record.Request.Headers, key); err != nil {
+		return record, err
+	}
+	if record.Response.Headers, err = decryptHeaders(record.Response.Headers, key); err != nil {
+		return record, err
+	}
+	if record.Request.HeaderValues, err = decryptHeaderValues(record.Request.HeaderValues, key); err != nil {
+		return record, err
+	}
+	if record.Response.HeaderValues, err = decryptHeaderValues(record.Response.HeaderValues, key); err != nil {
+		return record, err
+	}
+	if record.Request.Query != nil {
+		query := make(map[string]interface{}, len(record.Request.Query))
+		for k, v := range record.Request.Query {
+			if query[k], err = decryptBody(v, key); err != nil {
+				return record, fmt.Errorf("query parameter %s: %w", k, err)
+			}
+		}
+		record.Request.Query = query
+	}
+	if record.Request.Body, err = decryptBody(record.Request.Body, key); err != nil {
+		return record, fmt.Errorf("request body: %w", err)
+	}
+	if record.Response.Body, err = decryptBody(record.Response.Body, key); err != nil {
+		return record, fmt.Errorf("response body: %w", err)
+	}
+	return record, nil
+}
+
+func decryptHeaders(headers map[string]string, key *ecdh.PrivateKey) (map[string]string, error) {
+	if headers == nil {
+		return nil, nil
+	}
+	decrypted := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if IsEncryptedValue(v) {
+			value, err := DecryptValue(key, v)
+			if err != nil {
+				return nil, fmt.Errorf("header %s: %w", k, err)
+			}
+			v = fmt.Sprint(value)
+		}
+		decrypted[k] = v
+	}
+	return decrypted, nil
+}
+
+func decryptHeaderValues(values map[string][]string, key *ecdh.PrivateKey) (map[string][]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	decrypted := make(map[string][]string, len(values))
+	for k, vals := range values {
+		out := make([]string, len(vals))
+		for i, v := range vals {
+			if IsEncryptedValue(v) {
+				value, err := DecryptValue(key, v)
+				if err != nil {
+					return nil, fmt.Errorf("header %s: %w", k, err)
+				}
+				v = fmt.Sprint(value)
+			}
+			out[i] = v
+		}
+		decrypted[k] = out
+	}
+	return decrypted, nil
+}
+
+// decryptBody copies objects and arrays, decrypting encrypted values.
+func decryptBody(v interface{}, key *ecdh.PrivateKey) (interface{}, error) {
+	switch val := v.(type) {
+	case string:
+		if IsEncryptedValue(val) {
+			return DecryptValue(key, val)
+		}
+		return val, nil
+	case map[string]interface{}:
+		decrypted := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			value, err := decryptBody(child, key)
+			if err != nil {
+				return nil, err
+			}
+			decrypted[k] = value
+		}
+		return decrypted, nil
+	case []interface{}:
+		decrypted := make([]interface{}, len(val))
+		for i, child := range val {
+			value, err := decryptBody(child, key)
+			if err != nil {
+				return nil, err
+			}
+			decrypted[i] = value
+		}
+		return decrypted, nil
+	default:
+		return v, nil
+	}
+}
+
+// ParseEncryptionPublicKey parses a PEM-encoded PKIX X25519 public key, as
+// written by "openssl pkey -pubout" for a key from
+// "openssl genpkey -algorithm X25519".
+func ParseEncryptionPublicKey(data []byte) (*ecdh.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+	pub, ok := key.(*ecdh.PublicKey)
+	if !ok || pub.Curve() != ecdh.X25519() {
+		return nil, errors.New("public key is not an X25519 key")
+	}
+	return pub, nil
+}
+
+// ParseEncryptionPrivateKey parses a PEM-encoded PKCS #8 X25519 private key.
+func ParseEncryptionPrivateKey(data []byte) (*ecdh.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+	priv, ok := key.(*ecdh.PrivateKey)
+	if !ok || priv.Curve() != ecdh.X25519() {
+		return nil, errors.New("private key is not an X25519 key")
+	}
+	return priv, nil
+}
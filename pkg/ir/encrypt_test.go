@@ -0,0 +1,150 @@
+package ir
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+)
+
+func TestRedactEncryptRoundTrip(t *testing.T) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptor, err := NewFieldEncryptor(key.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := IRRecord{
+		Request: Request{
+			Method:  RequestMethodPOST,
+			Path:    "/login",
+			Headers: map[string]string{"Authorization": "Bearer secret", "Accept": "application/json"},
+			Query:   map[string]interface{}{"api_key": "abc"},
+			Body: map[string]interface{}{
+				"user":     "alice",
+				"password": "hunter2",
+				"card":     map[string]interface{}{"number": "4111", "cvv": float64(123)},
+			},
+		},
+		Response: Response{Status: 200},
+	}
+
+	redacted := Redact(record, RedactRules{
+		Headers:     []string{"authorization"},
+		QueryParams: []string{"api_key"},
+		BodyFields:  []string{"password", "card"},
+		Encryptor:   encryptor,
+	})
+
+	auth := redacted.Request.Headers["Authorization"]
+	if !IsEncryptedValue(auth) || strings.Contains(auth, "secret") {
+		t.Errorf("authorization not encrypted: %q", auth)
+	}
+	if redacted.Request.Headers["Accept"] != "application/json" {
+		t.Errorf("accept should be kept: %v", redacted.Request.Headers)
+	}
+	body := redacted.Request.Body.(map[string]interface{})
+	if !IsEncryptedValue(body["password"]) || !IsEncryptedValue(body["card"]) || body["user"] != "alice" {
+		t.Errorf("unexpected request body: %v", body)
+	}
+	if !IsEncryptedValue(redacted.Request.Query["api_key"]) {
+		t.Errorf("query not encrypted: %v", redacted.Request.Query)
+	}
+
+	decrypted, err := DecryptRecord(redacted, key)
+	if err != nil {
+		t.Fatalf("DecryptRecord failed: %v", err)
+	}
+	if decrypted.Request.Headers["Authorization"] != "Bearer secret" {
+		t.Errorf("authorization = %q", decrypted.Request.Headers["Authorization"])
+	}
+	if decrypted.Request.Query["api_key"] != "abc" {
+		t.Errorf("api_key = %v", decrypted.Request.Query["api_key"])
+	}
+	body = decrypted.Request.Body.(map[string]interface{})
+	if body["password"] != "hunter2" {
+		t.Errorf("password = %v", body["password"])
+	}
+	card := body["card"].(map[string]interface{})
+	if card["number"] != "4111" || card["cvv"] != float64(123) {
+		t.Errorf("card = %v", card)
+	}
+
+	// Redacted record is unchanged
+	if !IsEncryptedValue(redacted.Request.Headers["Authorization"]) {
+		t.Error("redacted record was modified")
+	}
+}
+
+func TestDecryptValueWrongKey(t *testing.T) {
+	key, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	other, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	encryptor, err := NewFieldEncryptor(key.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := encryptor.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptValue(other, value); err == nil {
+		t.Error("expected error decrypting with the wrong key")
+	}
+	if _, err := DecryptValue(key, "plain"); err == nil {
+		t.Error("expected error decrypting an unencrypted value")
+	}
+
+	// Values are encrypted with fresh keys, so equal inputs differ
+	again, _ := encryptor.Encrypt("secret")
+	if again == value {
+		t.Error("expected distinct ciphertexts for equal values")
+	}
+}
+
+func TestNewFieldEncryptorRequiresX25519(t *testing.T) {
+	key, _ := ecdh.P256().GenerateKey(rand.Reader)
+	if _, err := NewFieldEncryptor(key.PublicKey()); err == nil {
+		t.Error("expected error for a P-256 key")
+	}
+	if _, err := NewFieldEncryptor(nil); err == nil {
+		t.Error("expected error for a nil key")
+	}
+}
+
+func TestParseEncryptionKeys(t *testing.T) {
+	key, _ := ecdh.X25519().GenerateKey(rand.Reader)
+
+	pubDER, err := x509.MarshalPKIXPublicKey(key.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := ParseEncryptionPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	if err != nil {
+		t.Fatalf("ParseEncryptionPublicKey failed: %v", err)
+	}
+	if !pub.Equal(key.PublicKey()) {
+		t.Error("parsed public key differs")
+	}
+	priv, err := ParseEncryptionPrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}))
+	if err != nil {
+		t.Fatalf("ParseEncryptionPrivateKey failed: %v", err)
+	}
+	if !priv.Equal(key) {
+		t.Error("parsed private key differs")
+	}
+
+	if _, err := ParseEncryptionPublicKey([]byte("not pem")); err == nil {
+		t.Error("expected error for non-PEM input")
+	}
+}
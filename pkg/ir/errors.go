@@ -0,0 +1,38 @@
+package ir
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedVersion is returned when a batch or index file declares an
+// IR version other than Version. It is fatal: the file cannot be read.
+var ErrUnsupportedVersion = errors.New("unsupported IR version")
+
+// ErrInvalidRecord is returned when a line of NDJSON input cannot be decoded
+// as an IR record. Use errors.As to get the line number.
+//
+// It is recoverable for streaming readers: after NDJSONReader.Read returns
+// an ErrInvalidRecord, the next call continues with the following line.
+type ErrInvalidRecord struct {
+	// Line is the 1-based line number of the record.
+	Line int
+
+	// Err is the decoding error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ErrInvalidRecord) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the decoding error.
+func (e *ErrInvalidRecord) Unwrap() error {
+	return e.Err
+}
+
+// unsupportedVersionError reports a file of an unsupported version.
+func unsupportedVersionError(kind, version string) error {
+	return fmt.Errorf("%w: %s %s (expected %s)", ErrUnsupportedVersion, kind, version, Version)
+}
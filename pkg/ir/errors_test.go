@@ -0,0 +1,68 @@
+package ir
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestErrInvalidRecord(t *testing.T) {
+	ndjson := `{"request":{"method":"GET","path":"/a"},"response":{"status":200}}
+{not json}
+{"request":{"method":"GET","path":"/b"},"response":{"status":200}}`
+
+	_, err := ReadNDJSON(strings.NewReader(ndjson))
+	var invalid *ErrInvalidRecord
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected ErrInvalidRecord, got %v", err)
+	}
+	if invalid.Line != 2 {
+		t.Errorf("expected line 2, got %d", invalid.Line)
+	}
+
+	// Streaming readers recover and continue with the next line
+	reader := NewNDJSONReader(strings.NewReader(ndjson))
+	var paths []string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.As(err, &invalid) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		paths = append(paths, record.Request.Path)
+	}
+	if strings.Join(paths, ",") != "/a,/b" {
+		t.Errorf("expected /a,/b after skipping invalid line, got %v", paths)
+	}
+
+	_, errs := StreamNDJSON(strings.NewReader(ndjson))
+	if err := <-errs; !errors.As(err, &invalid) || invalid.Line != 2 {
+		t.Errorf("expected ErrInvalidRecord at line 2 from StreamNDJSON, got %v", err)
+	}
+}
+
+func TestErrUnsupportedVersion(t *testing.T) {
+	_, err := ReadBatch(strings.NewReader(`{"version":"9.9","records":[]}`))
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("expected ErrUnsupportedVersion for batch, got %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "9.9") {
+		t.Errorf("expected error to mention version, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "traffic.ndjson.idx")
+	if err := os.WriteFile(path, []byte(`{"version":"9.9","offsets":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadIndexFile(path); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("expected ErrUnsupportedVersion for index, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package ir
+
+import (
+	"path"
+	"strings"
+)
+
+// Content type prefixes that indicate non-API traffic (pages, assets, media).
+var nonAPIContentTypePrefixes = []string{
+	"text/html",
+	"text/css",
+	"text/javascript",
+	"application/javascript",
+	"application/x-javascript",
+	"image/",
+	"font/",
+	"application/font-",
+	"application/x-font-",
+	"video/",
+	"audio/",
+}
+
+// File extensions that indicate static assets.
+var staticAssetExtensions = map[string]bool{
+	".js": true, ".mjs": true, ".css": true, ".map": true,
+	".html": true, ".htm": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true,
+	".ico": true, ".webp": true, ".avif": true, ".bmp": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+	".mp4": true, ".webm": true, ".mp3": true, ".wav": true,
+}
+
+// IsNonAPIContentType reports whether a content type indicates non-API traffic
+// such as HTML pages, stylesheets, scripts, images, fonts, or media.
+func IsNonAPIContentType(contentType string) bool {
+	ct := strings.ToLower(strings.TrimSpace(contentType))
+	for _, prefix := range nonAPIContentTypePrefixes {
+		if strings.HasPrefix(ct, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaticAssetPath reports whether a URL path ends in a static asset extension.
+func IsStaticAssetPath(p string) bool {
+	return staticAssetExtensions[strings.ToLower(path.Ext(p))]
+}
+
+// IsNonAPIRecord reports whether a record looks like static asset or page traffic
+// rather than an API call, based on its path and response content type.
+func IsNonAPIRecord(r *IRRecord) bool {
+	if IsStaticAssetPath(r.Request.Path) {
+		return true
+	}
+	if r.Response.ContentType != nil && IsNonAPIContentType(*r.Response.ContentType) {
+		return true
+	}
+	return false
+}
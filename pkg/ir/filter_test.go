@@ -0,0 +1,30 @@
+package ir
+
+import "testing"
+
+func TestIsNonAPIRecord(t *testing.T) {
+	tests := []struct {
+		path        string
+		contentType string
+		want        bool
+	}{
+		{"/api/users", "application/json", false},
+		{"/api/users", "", false},
+		{"/main.js", "", true},
+		{"/styles/App.CSS", "", true},
+		{"/", "text/html; charset=utf-8", true},
+		{"/avatar", "image/png", true},
+		{"/fonts/inter", "font/woff2", true},
+		{"/export", "text/csv", false},
+	}
+
+	for _, tt := range tests {
+		record := NewRecord(RequestMethodGET, tt.path, 200)
+		if tt.contentType != "" {
+			record.SetResponseContentType(tt.contentType)
+		}
+		if got := IsNonAPIRecord(record); got != tt.want {
+			t.Errorf("IsNonAPIRecord(%q, %q) = %v, want %v", tt.path, tt.contentType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package ir
+
+import (
+	"math/rand"
+)
+
+// WriterFilter selects the records a FilteringWriter passes on. A record is
+// passed on when it satisfies every configured criterion.
+type WriterFilter struct {
+	// SampleRate is the fraction of records to pass on (0.0 to 1.0). As with
+	// LoggingOptions.SampleRate, values <= 0.0 or >= 1.0 pass every record.
+	SampleRate float64
+
+	// SampleSeed samples records by ID with SampleRequestID instead of at
+	// random, so writers and processes sharing the seed pick the same
+	// records. Records without an ID are sampled at random.
+	SampleSeed string
+
+	// Matcher, if set, passes only the records it allows (see
+	// RequestMatcher.AllowRecord).
+	Matcher *RequestMatcher
+
+	// Match, if set, passes only the records it returns true for.
+	Match func(*IRRecord) bool
+}
+
+// Allow reports whether a record passes the filter.
+func (f WriterFilter) Allow(record *IRRecord) bool {
+	if f.Matcher != nil && !f.Matcher.AllowRecord(record) {
+		return false
+	}
+	if f.Match != nil && !f.Match(record) {
+		return false
+	}
+	if f.SampleRate > 0.0 && f.SampleRate < 1.0 {
+		if f.SampleSeed != "" && record.Id != nil && *record.Id != "" {
+			return SampleRequestID(f.SampleSeed, *record.Id, f.SampleRate)
+		}
+		return rand.Float64() <= f.SampleRate //nolint:gosec // G404: sampling doesn't need crypto rand
+	}
+	return true
+}
+
+// FilteringWriter decorates an IRWriter, writing only the records that pass
+// its filter. Combined with MultiWriter, it gives each destination its own
+// sampling and filters, e.g. every record to a local archive and 1% to a
+// remote collector.
+type FilteringWriter struct {
+	writer IRWriter
+	filter WriterFilter
+}
+
+// NewFilteringWriter creates a FilteringWriter writing the records that pass
+// filter to w.
+func NewFilteringWriter(w IRWriter, filter WriterFilter) *FilteringWriter {
+	return &FilteringWriter{writer: w, filter: filter}
+}
+
+// Write writes the record to the underlying writer if it passes the filter.
+// Records filtered out are dropped without error.
+func (w *FilteringWriter) Write(record *IRRecord) error {
+	if !w.filter.Allow(record) {
+		return nil
+	}
+	return w.writer.Write(record)
+}
+
+// Flush flushes the underlying writer.
+func (w *FilteringWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+// Close closes the underlying writer.
+func (w *FilteringWriter) Close() error {
+	return w.writer.Close()
+}
@@ -0,0 +1,65 @@
+package ir
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFilteringWriter(t *testing.T) {
+	matcher, err := NewRequestMatcher(nil, nil, []string{"/health"})
+	if err != nil {
+		t.Fatalf("NewRequestMatcher failed: %v", err)
+	}
+
+	archive := &testWriter{}
+	remote := &testWriter{}
+	errorsOnly := &testWriter{}
+	multi, err := NewMultiWriter(
+		NewFilteringWriter(archive, WriterFilter{}),
+		NewFilteringWriter(remote, WriterFilter{SampleRate: 0.1, SampleSeed: "seed", Matcher: matcher}),
+		NewFilteringWriter(errorsOnly, WriterFilter{Match: func(r *IRRecord) bool { return r.Response.Status >= 500 }}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create MultiWriter: %v", err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		record := NewRecord(RequestMethodGET, "/users", 200)
+		if i%100 == 0 {
+			record.Response.Status = 503
+		}
+		record.SetID(fmt.Sprintf("req-%d", i))
+		if err := multi.Write(record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	health := NewRecord(RequestMethodGET, "/health", 200).SetID("req-health")
+	if err := multi.Write(health); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if len(archive.records) != 1001 {
+		t.Errorf("expected archive to get every record, got %d", len(archive.records))
+	}
+	if len(remote.records) < 50 || len(remote.records) > 150 {
+		t.Errorf("expected ~100 sampled records, got %d", len(remote.records))
+	}
+	for _, r := range remote.records {
+		if r.Request.Path == "/health" {
+			t.Error("expected /health to be filtered out by the matcher")
+		}
+		if !SampleRequestID("seed", *r.Id, 0.1) {
+			t.Errorf("expected %s to be sampled by ID", *r.Id)
+		}
+	}
+	if len(errorsOnly.records) != 10 {
+		t.Errorf("expected 10 error records, got %d", len(errorsOnly.records))
+	}
+
+	if err := multi.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !archive.closed || !remote.closed || !errorsOnly.closed {
+		t.Error("expected every child writer to be closed")
+	}
+}
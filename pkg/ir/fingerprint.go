@@ -0,0 +1,49 @@
+package ir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StructureFingerprint describes the shape of a decoded JSON value: object
+// keys and value types, with arrays described by their first item. Values
+// themselves are not included.
+func StructureFingerprint(v any) string {
+	switch val := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + ":" + StructureFingerprint(val[k])
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	case []any:
+		if len(val) > 0 {
+			return "[]" + StructureFingerprint(val[0])
+		}
+		return "[]"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// ContentHash returns the SHA-256 hash of data as "sha256:<hex>".
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,32 @@
+package ir
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStructureFingerprint(t *testing.T) {
+	a := map[string]any{"id": float64(1), "tags": []any{"x"}, "owner": map[string]any{"name": "Alice", "admin": true}}
+	b := map[string]any{"owner": map[string]any{"admin": false, "name": "Bob"}, "tags": []any{"y", "z"}, "id": float64(2)}
+
+	want := "{id:number,owner:{admin:bool,name:string},tags:[]string}"
+	if got := StructureFingerprint(a); got != want {
+		t.Errorf("StructureFingerprint = %q, want %q", got, want)
+	}
+	if StructureFingerprint(b) != want {
+		t.Error("expected bodies with the same shape to share a fingerprint")
+	}
+	if StructureFingerprint(map[string]any{"id": "1"}) == StructureFingerprint(map[string]any{"id": float64(1)}) {
+		t.Error("expected value types to change the fingerprint")
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	hash := ContentHash([]byte(`{"id":1}`))
+	if !strings.HasPrefix(hash, "sha256:") || len(hash) != len("sha256:")+64 {
+		t.Errorf("unexpected hash %q", hash)
+	}
+	if hash == ContentHash([]byte(`{"id":2}`)) {
+		t.Error("expected different content to hash differently")
+	}
+}
@@ -2,7 +2,6 @@ package ir
 
 import (
 	"compress/gzip"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -12,9 +11,12 @@ import (
 // Each record is JSON-encoded and written as a newline-delimited line.
 // The output is gzip-compressed for storage efficiency.
 type GzipNDJSONWriter struct {
-	gw     *gzip.Writer
-	closer io.Closer
-	count  int
+	gw        *gzip.Writer
+	closer    io.Closer
+	count     int
+	offset    int64
+	index     *Index
+	indexPath string
 }
 
 // GzipWriterOption configures a GzipNDJSONWriter.
@@ -63,6 +65,19 @@ func NewGzipNDJSONFileWriter(path string) (*GzipNDJSONWriter, error) {
 	return w, nil
 }
 
+// NewIndexedGzipNDJSONFileWriter creates a writer for streaming to a
+// gzip-compressed file that also writes a sidecar record ID index (see
+// IndexPath) on Close. Index offsets are into the decompressed stream.
+func NewIndexedGzipNDJSONFileWriter(path string) (*GzipNDJSONWriter, error) {
+	w, err := NewGzipNDJSONFileWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	w.index = NewIndex()
+	w.indexPath = IndexPath(path)
+	return w, nil
+}
+
 // NewGzipNDJSONFileWriterLevel creates a writer for streaming to a gzip-compressed file
 // with a specific compression level.
 func NewGzipNDJSONFileWriterLevel(path string, level int) (*GzipNDJSONWriter, error) {
@@ -85,19 +100,20 @@ func NewGzipNDJSONFileWriterLevel(path string, level int) (*GzipNDJSONWriter, er
 
 // Write writes a single record.
 func (w *GzipNDJSONWriter) Write(record *IRRecord) error {
-	data, err := json.Marshal(record)
+	buf, err := encodeLine(record)
 	if err != nil {
-		return fmt.Errorf("marshaling record: %w", err)
+		return err
 	}
+	defer putLineBuffer(buf)
 
-	if _, err := w.gw.Write(data); err != nil {
+	if _, err := w.gw.Write(buf.Bytes()); err != nil {
 		return fmt.Errorf("writing record: %w", err)
 	}
 
-	if _, err := w.gw.Write([]byte("\n")); err != nil {
-		return fmt.Errorf("writing newline: %w", err)
+	if w.index != nil {
+		w.index.Add(record, w.offset)
 	}
-
+	w.offset += int64(buf.Len())
 	w.count++
 	return nil
 }
@@ -107,13 +123,19 @@ func (w *GzipNDJSONWriter) Flush() error {
 	return w.gw.Flush()
 }
 
-// Close flushes and closes the gzip writer and underlying file if applicable.
+// Close flushes and closes the gzip writer and underlying file if applicable,
+// then writes the sidecar index if enabled.
 func (w *GzipNDJSONWriter) Close() error {
 	if err := w.gw.Close(); err != nil {
 		return fmt.Errorf("closing gzip writer: %w", err)
 	}
 	if w.closer != nil {
-		return w.closer.Close()
+		if err := w.closer.Close(); err != nil {
+			return err
+		}
+	}
+	if w.index != nil {
+		return WriteIndexFile(w.indexPath, w.index)
 	}
 	return nil
 }
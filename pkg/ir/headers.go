@@ -0,0 +1,64 @@
+package ir
+
+import "strings"
+
+// SplitHeaderValues splits multi-valued headers into the Headers and
+// HeaderValues fields of a Request or Response. Names are lowercased and
+// values of names that differ only in case are combined. Headers holds the
+// first value of each header; HeaderValues holds every value of headers that
+// occurred more than once. Either map is nil when it would be empty.
+func SplitHeaderValues(h map[string][]string) (map[string]string, map[string][]string) {
+	var headers map[string]string
+	var values map[string][]string
+	for name, vals := range h {
+		if len(vals) == 0 {
+			continue
+		}
+		name = strings.ToLower(name)
+		if headers == nil {
+			headers = make(map[string]string, len(h))
+		}
+		if _, ok := headers[name]; !ok {
+			headers[name] = vals[0]
+			if len(vals) == 1 {
+				continue
+			}
+			vals = vals[1:]
+		}
+		if values == nil {
+			values = make(map[string][]string)
+		}
+		if values[name] == nil {
+			values[name] = []string{headers[name]}
+		}
+		values[name] = append(values[name], vals...)
+	}
+	return headers, values
+}
+
+// HeaderValuesOf returns every value of a request header, in order.
+func (r Request) HeaderValuesOf(name string) []string {
+	return headerValuesOf(r.Headers, r.HeaderValues, name)
+}
+
+// HeaderValuesOf returns every value of a response header, in order.
+func (r Response) HeaderValuesOf(name string) []string {
+	return headerValuesOf(r.Headers, r.HeaderValues, name)
+}
+
+// HeaderValuesOf returns every value of an informational response header,
+// in order.
+func (r InformationalResponse) HeaderValuesOf(name string) []string {
+	return headerValuesOf(r.Headers, r.HeaderValues, name)
+}
+
+func headerValuesOf(headers map[string]string, values map[string][]string, name string) []string {
+	name = strings.ToLower(name)
+	if vals, ok := values[name]; ok {
+		return vals
+	}
+	if value, ok := headers[name]; ok {
+		return []string{value}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package ir
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitHeaderValues(t *testing.T) {
+	headers, values := SplitHeaderValues(map[string][]string{
+		"Accept": {"application/json"},
+		"Via":    {"1.1 a", "1.1 b"},
+		"Empty":  {},
+	})
+	if !reflect.DeepEqual(headers, map[string]string{"accept": "application/json", "via": "1.1 a"}) {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+	if !reflect.DeepEqual(values, map[string][]string{"via": {"1.1 a", "1.1 b"}}) {
+		t.Errorf("unexpected header values: %v", values)
+	}
+
+	headers, values = SplitHeaderValues(map[string][]string{"Accept": {"*/*"}})
+	if len(headers) != 1 || values != nil {
+		t.Errorf("expected no header values for single-valued headers, got %v", values)
+	}
+	if headers, values = SplitHeaderValues(nil); headers != nil || values != nil {
+		t.Errorf("expected nil maps for no headers, got %v %v", headers, values)
+	}
+}
+
+func TestHeaderValuesOf(t *testing.T) {
+	req := Request{
+		Headers:      map[string]string{"accept": "*/*", "via": "1.1 a"},
+		HeaderValues: map[string][]string{"via": {"1.1 a", "1.1 b"}},
+	}
+	if got := req.HeaderValuesOf("Via"); !reflect.DeepEqual(got, []string{"1.1 a", "1.1 b"}) {
+		t.Errorf("unexpected via values: %v", got)
+	}
+	if got := req.HeaderValuesOf("accept"); !reflect.DeepEqual(got, []string{"*/*"}) {
+		t.Errorf("unexpected accept values: %v", got)
+	}
+	if got := req.HeaderValuesOf("x-missing"); got != nil {
+		t.Errorf("expected nil for a missing header, got %v", got)
+	}
+}
@@ -0,0 +1,254 @@
+package ir
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// IndexExtension is appended to an NDJSON file path to form its sidecar index path.
+const IndexExtension = ".idx"
+
+// ErrRecordNotFound is returned when a record ID is not present in a file.
+var ErrRecordNotFound = errors.New("record not found")
+
+// Index maps record IDs to the byte offset of their line in an NDJSON file.
+// For gzip-compressed files, offsets are into the decompressed stream.
+type Index struct {
+	Version string           `json:"version"`
+	Offsets map[string]int64 `json:"offsets"`
+}
+
+// NewIndex creates an empty record index.
+func NewIndex() *Index {
+	return &Index{
+		Version: Version,
+		Offsets: make(map[string]int64),
+	}
+}
+
+// Add records the offset of a record. Records without an ID are ignored.
+func (idx *Index) Add(record *IRRecord, offset int64) {
+	if record.Id == nil || *record.Id == "" {
+		return
+	}
+	if _, ok := idx.Offsets[*record.Id]; !ok {
+		idx.Offsets[*record.Id] = offset
+	}
+}
+
+// IndexPath returns the sidecar index path for an NDJSON file.
+func IndexPath(path string) string {
+	return path + IndexExtension
+}
+
+// ReadIndexFile reads a sidecar index file.
+func ReadIndexFile(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("decoding index: %w", err)
+	}
+	if idx.Version != Version {
+		return nil, unsupportedVersionError("index", idx.Version)
+	}
+	if idx.Offsets == nil {
+		idx.Offsets = make(map[string]int64)
+	}
+	return &idx, nil
+}
+
+// WriteIndexFile writes a sidecar index file.
+func WriteIndexFile(path string, idx *Index) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+	return nil
+}
+
+// BuildIndex scans an NDJSON file (gzip-compressed if it ends in .gz) and
+// returns an index of its record IDs.
+func BuildIndex(path string) (*Index, error) {
+	r, err := openNDJSONStream(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	idx := NewIndex()
+	err = scanLines(r, func(line []byte, offset int64) (bool, error) {
+		var record IRRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return false, err
+		}
+		idx.Add(&record, offset)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// FindRecord returns the record with the given ID from an NDJSON file.
+// If an up-to-date sidecar index exists, it is used to locate the record;
+// otherwise the file is scanned. Returns ErrRecordNotFound if no record matches.
+func FindRecord(path, id string) (*IRRecord, error) {
+	if idx, ok := readFreshIndex(path); ok {
+		offset, ok := idx.Offsets[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrRecordNotFound, id)
+		}
+		return readRecordAt(path, offset)
+	}
+
+	r, err := openNDJSONStream(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var found *IRRecord
+	err = scanLines(r, func(line []byte, _ int64) (bool, error) {
+		// Skip full decoding of lines that cannot contain the ID
+		if !strings.Contains(string(line), id) {
+			return true, nil
+		}
+		var record IRRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return false, err
+		}
+		if record.Id != nil && *record.Id == id {
+			found = &record
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", ErrRecordNotFound, id)
+	}
+	return found, nil
+}
+
+// readFreshIndex reads the sidecar index for path if it exists and is not
+// older than the data file.
+func readFreshIndex(path string) (*Index, bool) {
+	dataInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	indexInfo, err := os.Stat(IndexPath(path))
+	if err != nil || indexInfo.ModTime().Before(dataInfo.ModTime()) {
+		return nil, false
+	}
+	idx, err := ReadIndexFile(IndexPath(path))
+	if err != nil {
+		return nil, false
+	}
+	return idx, true
+}
+
+// readRecordAt decodes the record on the line starting at offset.
+func readRecordAt(path string, offset int64) (*IRRecord, error) {
+	r, err := openNDJSONStream(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	if seeker, ok := r.(io.Seeker); ok {
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("seeking to record: %w", err)
+		}
+	} else if _, err := io.CopyN(io.Discard, r, offset); err != nil {
+		return nil, fmt.Errorf("seeking to record: %w", err)
+	}
+
+	line, err := bufio.NewReaderSize(r, 64*1024).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading record: %w", err)
+	}
+
+	var record IRRecord
+	if err := json.Unmarshal(line, &record); err != nil {
+		return nil, fmt.Errorf("decoding record at offset %d: %w", offset, err)
+	}
+	return &record, nil
+}
+
+// openNDJSONStream opens an NDJSON file, decompressing .gz files.
+// The returned reader is seekable for uncompressed files.
+func openNDJSONStream(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	if !strings.HasSuffix(strings.ToLower(path), ".gz") {
+		return f, nil
+	}
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("creating gzip reader: %w", err)
+	}
+	return &gzipFileReader{Reader: gr, file: f}, nil
+}
+
+type gzipFileReader struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (r *gzipFileReader) Close() error {
+	r.Reader.Close()
+	return r.file.Close()
+}
+
+// scanLines calls fn for each non-empty line with the line's byte offset.
+// Scanning stops when fn returns false or an error, which is returned as an
+// ErrInvalidRecord for the line.
+func scanLines(r io.Reader, fn func(line []byte, offset int64) (bool, error)) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	var offset int64
+	lineNum := 0
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			lineNum++
+			start := offset
+			offset += int64(len(line))
+			if trimmed := strings.TrimSpace(string(line)); trimmed != "" {
+				more, fnErr := fn([]byte(trimmed), start)
+				if fnErr != nil {
+					return &ErrInvalidRecord{Line: lineNum, Err: fnErr}
+				}
+				if !more {
+					return nil
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading file: %w", err)
+		}
+	}
+}
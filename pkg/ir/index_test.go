@@ -0,0 +1,109 @@
+package ir
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func indexTestRecords() []IRRecord {
+	ids := []string{"req-1", "req-2", "req-3"}
+	records := make([]IRRecord, len(ids))
+	for i, id := range ids {
+		id := id
+		records[i] = IRRecord{
+			Id:       &id,
+			Request:  Request{Method: RequestMethodGET, Path: "/items/" + id},
+			Response: Response{Status: 200, Body: map[string]interface{}{"id": id}},
+		}
+	}
+	return records
+}
+
+func TestIndexedWriters(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name string
+		path string
+		open func(path string) (IRWriter, error)
+	}{
+		{
+			name: "ndjson",
+			path: filepath.Join(tmpDir, "traffic.ndjson"),
+			open: func(path string) (IRWriter, error) { return NewIndexedNDJSONFileWriter(path) },
+		},
+		{
+			name: "gzip",
+			path: filepath.Join(tmpDir, "traffic.ndjson.gz"),
+			open: func(path string) (IRWriter, error) { return NewIndexedGzipNDJSONFileWriter(path) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := tt.open(tt.path)
+			if err != nil {
+				t.Fatalf("open failed: %v", err)
+			}
+			records := indexTestRecords()
+			for i := range records {
+				if err := w.Write(&records[i]); err != nil {
+					t.Fatalf("write failed: %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("close failed: %v", err)
+			}
+
+			idx, err := ReadIndexFile(IndexPath(tt.path))
+			if err != nil {
+				t.Fatalf("ReadIndexFile failed: %v", err)
+			}
+			if len(idx.Offsets) != 3 {
+				t.Errorf("expected 3 indexed records, got %d", len(idx.Offsets))
+			}
+
+			built, err := BuildIndex(tt.path)
+			if err != nil {
+				t.Fatalf("BuildIndex failed: %v", err)
+			}
+			for id, offset := range idx.Offsets {
+				if built.Offsets[id] != offset {
+					t.Errorf("offset mismatch for %s: written %d, built %d", id, offset, built.Offsets[id])
+				}
+			}
+
+			record, err := FindRecord(tt.path, "req-2")
+			if err != nil {
+				t.Fatalf("FindRecord failed: %v", err)
+			}
+			if record.Request.Path != "/items/req-2" {
+				t.Errorf("expected /items/req-2, got %s", record.Request.Path)
+			}
+		})
+	}
+}
+
+func TestFindRecordWithoutIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.ndjson")
+	if err := WriteFile(path, indexTestRecords()); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := os.Stat(IndexPath(path)); !os.IsNotExist(err) {
+		t.Fatalf("expected no index file, got %v", err)
+	}
+
+	record, err := FindRecord(path, "req-3")
+	if err != nil {
+		t.Fatalf("FindRecord failed: %v", err)
+	}
+	if record.Request.Path != "/items/req-3" {
+		t.Errorf("expected /items/req-3, got %s", record.Request.Path)
+	}
+
+	if _, err := FindRecord(path, "missing"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
@@ -29,6 +29,9 @@ type IRRecord struct {
 	// Round-trip time in milliseconds.
 	DurationMs *float64 `json:"durationMs,omitempty" yaml:"durationMs,omitempty" mapstructure:"durationMs,omitempty"`
 
+	// Timings corresponds to the JSON schema field "timings".
+	Timings *Timings `json:"timings,omitempty" yaml:"timings,omitempty" mapstructure:"timings,omitempty"`
+
 	// Explicit operation identifier (e.g., getUserById). Must be valid identifier.
 	OperationId *string `json:"operationId,omitempty" yaml:"operationId,omitempty" mapstructure:"operationId,omitempty"`
 
@@ -46,6 +49,10 @@ type IRRecord struct {
 
 	// Reference to external documentation.
 	ExternalDocs *ExternalDocs `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty" mapstructure:"externalDocs,omitempty"`
+
+	// Free-form per-record context (e.g., source file, capture host, environment,
+	// user label).
+	Metadata map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty" mapstructure:"meta,omitempty"`
 }
 
 // IRRecordSource represents the adapter/source that generated a record.
@@ -61,6 +68,8 @@ const (
 	IRRecordSourceInsomnia         IRRecordSource = "insomnia"
 	IRRecordSourceOpenAPI          IRRecordSource = "openapi"
 	IRRecordSourceSwagger          IRRecordSource = "swagger"
+	IRRecordSourceCurl             IRRecordSource = "curl"
+	IRRecordSourcePcap             IRRecordSource = "pcap"
 )
 
 var enumValues_IRRecordSource = []interface{}{
@@ -73,6 +82,8 @@ var enumValues_IRRecordSource = []interface{}{
 	"insomnia",
 	"openapi",
 	"swagger",
+	"curl",
+	"pcap",
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -145,11 +156,17 @@ type Request struct {
 	// Request headers (keys should be lowercase).
 	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" mapstructure:"headers,omitempty"`
 
+	// All values, in order, of request headers that occurred more than once (keys should be lowercase). headers holds the first value.
+	HeaderValues map[string][]string `json:"headerValues,omitempty" yaml:"headerValues,omitempty" mapstructure:"headerValues,omitempty"`
+
 	// Content-Type header value (e.g., application/json).
 	ContentType *string `json:"contentType,omitempty" yaml:"contentType,omitempty" mapstructure:"contentType,omitempty"`
 
 	// Parsed request body. Object/array for JSON, string for other content types, null for no body.
 	Body interface{} `json:"body,omitempty" yaml:"body,omitempty" mapstructure:"body,omitempty"`
+
+	// Trailer headers sent after the request body (keys should be lowercase).
+	Trailers map[string]string `json:"trailers,omitempty" yaml:"trailers,omitempty" mapstructure:"trailers,omitempty"`
 }
 
 // RequestMethod represents the HTTP method.
@@ -262,11 +279,20 @@ type Response struct {
 	// Response headers (keys should be lowercase).
 	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" mapstructure:"headers,omitempty"`
 
+	// All values, in order, of response headers that occurred more than once (keys should be lowercase). headers holds the first value.
+	HeaderValues map[string][]string `json:"headerValues,omitempty" yaml:"headerValues,omitempty" mapstructure:"headerValues,omitempty"`
+
 	// Content-Type header value.
 	ContentType *string `json:"contentType,omitempty" yaml:"contentType,omitempty" mapstructure:"contentType,omitempty"`
 
 	// Parsed response body. Object/array for JSON, string for other content types, null for no body.
 	Body interface{} `json:"body,omitempty" yaml:"body,omitempty" mapstructure:"body,omitempty"`
+
+	// Trailer headers sent after the response body (keys should be lowercase).
+	Trailers map[string]string `json:"trailers,omitempty" yaml:"trailers,omitempty" mapstructure:"trailers,omitempty"`
+
+	// Informational (1xx) responses received before the final response, e.g. 103 Early Hints.
+	Informational []InformationalResponse `json:"informational,omitempty" yaml:"informational,omitempty" mapstructure:"informational,omitempty"`
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -293,6 +319,81 @@ func (j *Response) UnmarshalJSON(value []byte) error {
 	return nil
 }
 
+// InformationalResponse represents an informational (1xx) response preceding the final response.
+type InformationalResponse struct {
+	// HTTP status code (e.g., 103).
+	Status int `json:"status" yaml:"status" mapstructure:"status"`
+
+	// Response headers (keys should be lowercase).
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" mapstructure:"headers,omitempty"`
+
+	// All values, in order, of response headers that occurred more than once (keys should be lowercase). headers holds the first value.
+	HeaderValues map[string][]string `json:"headerValues,omitempty" yaml:"headerValues,omitempty" mapstructure:"headerValues,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *InformationalResponse) UnmarshalJSON(value []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return err
+	}
+	if _, ok := raw["status"]; raw != nil && !ok {
+		return fmt.Errorf("field status in InformationalResponse: required")
+	}
+	type Plain InformationalResponse
+	var plain Plain
+	if err := json.Unmarshal(value, &plain); err != nil {
+		return err
+	}
+	if 199 < plain.Status {
+		return fmt.Errorf("field %s: must be <= %v", "status", 199)
+	}
+	if 100 > plain.Status {
+		return fmt.Errorf("field %s: must be >= %v", "status", 100)
+	}
+	*j = InformationalResponse(plain)
+	return nil
+}
+
+// Timings represents timing phases of a request in milliseconds. Phases that did
+// not occur, such as DNS and connect on a reused connection, are omitted.
+type Timings struct {
+	// Time spent resolving the host name.
+	DnsMs *float64 `json:"dnsMs,omitempty" yaml:"dnsMs,omitempty" mapstructure:"dnsMs,omitempty"`
+
+	// Time spent establishing the TCP connection, excluding TLS.
+	ConnectMs *float64 `json:"connectMs,omitempty" yaml:"connectMs,omitempty" mapstructure:"connectMs,omitempty"`
+
+	// Time spent on the TLS handshake.
+	TlsMs *float64 `json:"tlsMs,omitempty" yaml:"tlsMs,omitempty" mapstructure:"tlsMs,omitempty"`
+
+	// Time from the start of the request to the first response byte.
+	TtfbMs *float64 `json:"ttfbMs,omitempty" yaml:"ttfbMs,omitempty" mapstructure:"ttfbMs,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *Timings) UnmarshalJSON(value []byte) error {
+	type Plain Timings
+	var plain Plain
+	if err := json.Unmarshal(value, &plain); err != nil {
+		return err
+	}
+	if plain.DnsMs != nil && 0 > *plain.DnsMs {
+		return fmt.Errorf("field %s: must be >= %v", "dnsMs", 0)
+	}
+	if plain.ConnectMs != nil && 0 > *plain.ConnectMs {
+		return fmt.Errorf("field %s: must be >= %v", "connectMs", 0)
+	}
+	if plain.TlsMs != nil && 0 > *plain.TlsMs {
+		return fmt.Errorf("field %s: must be >= %v", "tlsMs", 0)
+	}
+	if plain.TtfbMs != nil && 0 > *plain.TtfbMs {
+		return fmt.Errorf("field %s: must be >= %v", "ttfbMs", 0)
+	}
+	*j = Timings(plain)
+	return nil
+}
+
 // ExternalDocs represents a reference to external documentation.
 type ExternalDocs struct {
 	// URL to external documentation.
@@ -0,0 +1,67 @@
+package ir
+
+import (
+	"maps"
+	"os"
+	"strings"
+)
+
+// LabelsEnvVar is the environment variable LabelsFromEnv reads labels from,
+// as comma-separated key=value pairs, e.g. "service=checkout,env=prod".
+const LabelsEnvVar = "TRAFFIC2OPENAPI_LABELS"
+
+// LabelingWriter decorates an IRWriter, stamping every record with static
+// labels in its metadata, such as the service and environment it was
+// captured from. Labels a record already has are kept, so a shared capture
+// pipeline can collect traffic from many services and generate, site, and
+// explore can select each one with --meta-filter.
+type LabelingWriter struct {
+	writer IRWriter
+	labels map[string]any
+}
+
+// NewLabelingWriter creates a LabelingWriter adding labels to the records
+// written to w. The caller's records are not modified: each labeled record
+// is a copy with its own metadata map.
+func NewLabelingWriter(w IRWriter, labels map[string]any) *LabelingWriter {
+	return &LabelingWriter{writer: w, labels: labels}
+}
+
+// Write writes a labeled copy of the record to the underlying writer.
+func (w *LabelingWriter) Write(record *IRRecord) error {
+	if len(w.labels) == 0 {
+		return w.writer.Write(record)
+	}
+	labeled := *record
+	labeled.Metadata = maps.Clone(record.Metadata)
+	applyMetadata(&labeled, w.labels)
+	return w.writer.Write(&labeled)
+}
+
+// Flush flushes the underlying writer.
+func (w *LabelingWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+// Close closes the underlying writer.
+func (w *LabelingWriter) Close() error {
+	return w.writer.Close()
+}
+
+// LabelsFromEnv parses the labels in the LabelsEnvVar environment variable.
+// It returns an empty map if the variable is unset.
+func LabelsFromEnv() (map[string]any, error) {
+	return ParseLabels(os.Getenv(LabelsEnvVar))
+}
+
+// ParseLabels parses comma-separated key=value pairs, such as
+// "service=checkout,env=prod", into a metadata map.
+func ParseLabels(s string) (map[string]any, error) {
+	var pairs []string
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+	return ParseMetadata(pairs)
+}
@@ -0,0 +1,54 @@
+package ir
+
+import "testing"
+
+func TestLabelingWriter(t *testing.T) {
+	t.Setenv(LabelsEnvVar, "service=checkout, env=prod")
+	labels, err := LabelsFromEnv()
+	if err != nil {
+		t.Fatalf("LabelsFromEnv failed: %v", err)
+	}
+
+	inner := &testWriter{}
+	w := NewLabelingWriter(inner, labels)
+
+	record := NewRecord(RequestMethodGET, "/cart", 200)
+	relabeled := NewRecord(RequestMethodGET, "/cart", 200)
+	relabeled.SetMeta("env", "staging")
+	for _, r := range []*IRRecord{record, relabeled} {
+		if err := w.Write(r); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	if len(inner.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(inner.records))
+	}
+	if got := inner.records[0].MetaString("service"); got != "checkout" {
+		t.Errorf("expected service=checkout, got %q", got)
+	}
+	if got := inner.records[0].MetaString("env"); got != "prod" {
+		t.Errorf("expected env=prod, got %q", got)
+	}
+	if got := inner.records[1].MetaString("env"); got != "staging" {
+		t.Errorf("expected existing label to be kept, got env=%q", got)
+	}
+	if !MatchesMetadata(inner.records[0], map[string]any{"service": "checkout"}) {
+		t.Error("expected labeled record to match a label filter")
+	}
+
+	// The caller's records are not modified
+	if record.Metadata != nil {
+		t.Errorf("expected written record to keep no metadata, got %v", record.Metadata)
+	}
+	if len(relabeled.Metadata) != 1 {
+		t.Errorf("expected written record to keep its own metadata, got %v", relabeled.Metadata)
+	}
+
+	if _, err := ParseLabels("service"); err == nil {
+		t.Error("expected error for label without value")
+	}
+	if labels, err := ParseLabels(""); err != nil || len(labels) != 0 {
+		t.Errorf("expected no labels from empty string, got %v, %v", labels, err)
+	}
+}
@@ -0,0 +1,279 @@
+package ir
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+// RegexPatternPrefix marks a host or path pattern as a regular expression,
+// e.g. "re:^api[0-9]+\.example\.com$".
+const RegexPatternPrefix = "re:"
+
+// PatternMatcher matches hosts or paths against a list of patterns.
+//
+// A pattern is one of:
+//   - a regular expression prefixed with "re:", matched anywhere in the value
+//     unless anchored with ^ and $
+//   - a wildcard pattern containing "*", which matches any run of characters,
+//     so "*.example.com" matches subdomains of example.com at any depth
+//   - a CIDR block ("10.0.0.0/8") or IP address, matching IP hosts (host
+//     patterns only)
+//   - a literal: a case-insensitive host name, optionally with a port, or a
+//     path prefix
+//
+// Hosts are matched with and without their port. Path patterns can be
+// scoped to methods with a comma-separated method list, e.g. "GET /metrics"
+// or "GET,HEAD */health"; scoped patterns only apply through MatchRequest.
+type PatternMatcher struct {
+	hosts    bool
+	literals []string
+	globs    []*regexp.Regexp
+	regexes  []*regexp.Regexp
+	prefixes []netip.Prefix
+	scoped   map[string]*PatternMatcher // method -> path patterns scoped to it
+}
+
+// NewHostMatcher compiles host patterns.
+func NewHostMatcher(patterns []string) (*PatternMatcher, error) {
+	return newPatternMatcher(patterns, true)
+}
+
+// NewPathMatcher compiles path patterns. Literal patterns match path prefixes.
+func NewPathMatcher(patterns []string) (*PatternMatcher, error) {
+	return newPatternMatcher(patterns, false)
+}
+
+func newPatternMatcher(patterns []string, hosts bool) (*PatternMatcher, error) {
+	m := &PatternMatcher{hosts: hosts}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if !hosts {
+			if methods, rest, ok := cutMethods(pattern); ok {
+				if err := m.addScoped(methods, rest); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		if expr, ok := strings.CutPrefix(pattern, RegexPatternPrefix); ok {
+			if hosts {
+				expr = "(?i)" + expr
+			}
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			m.regexes = append(m.regexes, re)
+			continue
+		}
+		if strings.Contains(pattern, "*") {
+			m.globs = append(m.globs, globPattern(pattern, hosts))
+			continue
+		}
+		if hosts {
+			if prefix, err := netip.ParsePrefix(pattern); err == nil {
+				m.prefixes = append(m.prefixes, prefix.Masked())
+				continue
+			}
+			if addr, err := netip.ParseAddr(strings.Trim(pattern, "[]")); err == nil {
+				m.prefixes = append(m.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+				continue
+			}
+			pattern = strings.ToLower(pattern)
+		}
+		m.literals = append(m.literals, pattern)
+	}
+	return m, nil
+}
+
+// addScoped adds a path pattern that only applies to the given methods.
+func (m *PatternMatcher) addScoped(methods []string, pattern string) error {
+	scoped, err := newPatternMatcher([]string{pattern}, false)
+	if err != nil {
+		return err
+	}
+	if m.scoped == nil {
+		m.scoped = make(map[string]*PatternMatcher)
+	}
+	for _, method := range methods {
+		if m.scoped[method] == nil {
+			m.scoped[method] = &PatternMatcher{}
+		}
+		m.scoped[method].merge(scoped)
+	}
+	return nil
+}
+
+// merge adds the unscoped patterns of other to m.
+func (m *PatternMatcher) merge(other *PatternMatcher) {
+	m.literals = append(m.literals, other.literals...)
+	m.globs = append(m.globs, other.globs...)
+	m.regexes = append(m.regexes, other.regexes...)
+	m.prefixes = append(m.prefixes, other.prefixes...)
+}
+
+// cutMethods splits a leading method list ("GET" or "GET,HEAD") from a path
+// pattern.
+func cutMethods(pattern string) ([]string, string, bool) {
+	list, rest, found := strings.Cut(pattern, " ")
+	rest = strings.TrimSpace(rest)
+	if !found || rest == "" {
+		return nil, "", false
+	}
+	methods := strings.Split(strings.ToUpper(list), ",")
+	for _, method := range methods {
+		if method == "" || strings.IndexFunc(method, func(r rune) bool { return r < 'A' || r > 'Z' }) != -1 {
+			return nil, "", false
+		}
+	}
+	return methods, rest, true
+}
+
+// globPattern compiles a wildcard pattern into an anchored regular expression.
+func globPattern(pattern string, hosts bool) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*") + "$"
+	if hosts {
+		expr = "(?i)" + expr
+	}
+	return regexp.MustCompile(expr)
+}
+
+// Empty reports whether the matcher has no patterns.
+func (m *PatternMatcher) Empty() bool {
+	return m == nil || len(m.literals)+len(m.globs)+len(m.regexes)+len(m.prefixes)+len(m.scoped) == 0
+}
+
+// MatchRequest reports whether a request path matches any pattern,
+// including patterns scoped to the request's method.
+func (m *PatternMatcher) MatchRequest(method, path string) bool {
+	if m == nil {
+		return false
+	}
+	return m.Match(path) || m.scoped[strings.ToUpper(method)].Match(path)
+}
+
+// Match reports whether value matches any pattern.
+func (m *PatternMatcher) Match(value string) bool {
+	if m == nil {
+		return false
+	}
+	if !m.hosts {
+		return m.matchValue(value, false)
+	}
+	hostname := hostWithoutPort(value)
+	if m.matchValue(hostname, true) {
+		return true
+	}
+	return hostname != value && m.matchValue(value, true)
+}
+
+func (m *PatternMatcher) matchValue(value string, host bool) bool {
+	for _, literal := range m.literals {
+		if host && strings.EqualFold(value, literal) || !host && strings.HasPrefix(value, literal) {
+			return true
+		}
+	}
+	for _, re := range m.globs {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	for _, re := range m.regexes {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	if len(m.prefixes) > 0 {
+		if addr, err := netip.ParseAddr(strings.Trim(value, "[]")); err == nil {
+			for _, prefix := range m.prefixes {
+				if prefix.Contains(addr.Unmap()) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hostWithoutPort strips the port, if any, from a host.
+func hostWithoutPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// RequestMatcher decides which requests are captured by host and path.
+// A nil RequestMatcher allows every request.
+type RequestMatcher struct {
+	allowHosts *PatternMatcher
+	skipHosts  *PatternMatcher
+	skipPaths  *PatternMatcher
+}
+
+// NewRequestMatcher compiles host and path patterns (see PatternMatcher).
+// If allowHosts is non-empty only matching hosts are allowed; requests to
+// skipHosts or with a path matching skipPaths are not.
+func NewRequestMatcher(allowHosts, skipHosts, skipPaths []string) (*RequestMatcher, error) {
+	allow, err := NewHostMatcher(allowHosts)
+	if err != nil {
+		return nil, fmt.Errorf("allow hosts: %w", err)
+	}
+	skip, err := NewHostMatcher(skipHosts)
+	if err != nil {
+		return nil, fmt.Errorf("skip hosts: %w", err)
+	}
+	paths, err := NewPathMatcher(skipPaths)
+	if err != nil {
+		return nil, fmt.Errorf("skip paths: %w", err)
+	}
+	return &RequestMatcher{allowHosts: allow, skipHosts: skip, skipPaths: paths}, nil
+}
+
+// Allow reports whether a request should be captured.
+func (m *RequestMatcher) Allow(method, host, path string) bool {
+	if m == nil {
+		return true
+	}
+	if m.skipPaths.MatchRequest(method, path) {
+		return false
+	}
+	if !m.allowHosts.Empty() && !m.allowHosts.Match(host) {
+		return false
+	}
+	return !m.skipHosts.Match(host)
+}
+
+// AllowRecord reports whether a record's request should be captured.
+// Records without a host are matched as the empty host.
+func (m *RequestMatcher) AllowRecord(record *IRRecord) bool {
+	var host string
+	if record.Request.Host != nil {
+		host = *record.Request.Host
+	}
+	return m.Allow(string(record.Request.Method), host, record.Request.Path)
+}
+
+// FilterRecords returns the records the matcher allows.
+func (m *RequestMatcher) FilterRecords(records []IRRecord) []IRRecord {
+	if m == nil {
+		return records
+	}
+	filtered := make([]IRRecord, 0, len(records))
+	for i := range records {
+		if m.AllowRecord(&records[i]) {
+			filtered = append(filtered, records[i])
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,127 @@
+package ir
+
+import "testing"
+
+func TestHostMatcher(t *testing.T) {
+	m, err := NewHostMatcher([]string{
+		"api.example.com",
+		"*.internal.example.com",
+		`re:^svc[0-9]+\.example\.net$`,
+		"10.0.0.0/8",
+		"::1",
+		"localhost:8080",
+	})
+	if err != nil {
+		t.Fatalf("NewHostMatcher: %v", err)
+	}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"api.example.com", true},
+		{"API.Example.com:443", true},
+		{"www.example.com", false},
+		{"a.internal.example.com", true},
+		{"a.b.internal.example.com", true},
+		{"internal.example.com", false},
+		{"svc12.example.net", true},
+		{"svc.example.net", false},
+		{"10.1.2.3", true},
+		{"10.1.2.3:9000", true},
+		{"11.1.2.3", false},
+		{"[::1]:8080", true},
+		{"localhost:8080", true},
+		{"localhost:9090", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.host); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestPathMatcher(t *testing.T) {
+	m, err := NewPathMatcher([]string{"/health", "/internal/*/debug", `re:/v[0-9]+/admin`})
+	if err != nil {
+		t.Fatalf("NewPathMatcher: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/health", true},
+		{"/healthz", true},
+		{"/api/health", false},
+		{"/internal/a/debug", true},
+		{"/internal/a/debug/more", false},
+		{"/api/v2/admin/users", true},
+		{"/api/users", false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.path); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPathMatcherMethodScope(t *testing.T) {
+	m, err := NewPathMatcher([]string{"GET /metrics", "get,head */health", "/debug"})
+	if err != nil {
+		t.Fatalf("NewPathMatcher: %v", err)
+	}
+
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{"GET", "/metrics", true},
+		{"POST", "/metrics", false},
+		{"HEAD", "/api/v1/health", true},
+		{"GET", "/api/v1/health", true},
+		{"DELETE", "/api/v1/health", false},
+		{"POST", "/debug/vars", true},
+	}
+	for _, tt := range tests {
+		if got := m.MatchRequest(tt.method, tt.path); got != tt.want {
+			t.Errorf("MatchRequest(%q, %q) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+	if m.Match("/metrics") {
+		t.Error("expected method-scoped patterns to be ignored by Match")
+	}
+}
+
+func TestRequestMatcher(t *testing.T) {
+	m, err := NewRequestMatcher([]string{"*.example.com"}, []string{"cdn.example.com"}, []string{"/health"})
+	if err != nil {
+		t.Fatalf("NewRequestMatcher: %v", err)
+	}
+
+	tests := []struct {
+		host, path string
+		want       bool
+	}{
+		{"api.example.com", "/users", true},
+		{"api.example.com", "/health", false},
+		{"cdn.example.com", "/logo.png", false},
+		{"api.example.org", "/users", false},
+		{"", "/users", false},
+	}
+	for _, tt := range tests {
+		if got := m.Allow("GET", tt.host, tt.path); got != tt.want {
+			t.Errorf("Allow(%q, %q) = %v, want %v", tt.host, tt.path, got, tt.want)
+		}
+	}
+
+	var unset *RequestMatcher
+	if !unset.Allow("GET", "any.example.org", "/health") {
+		t.Error("expected a nil matcher to allow every request")
+	}
+
+	if _, err := NewRequestMatcher(nil, []string{"re:("}, nil); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
@@ -0,0 +1,125 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Well-known IRRecord.Metadata keys populated by converters and transports.
+const (
+	// MetaSourceFile is the name of the file a record was converted from.
+	MetaSourceFile = "sourceFile"
+
+	// MetaCaptureHost is the host name of the machine that captured a record.
+	MetaCaptureHost = "captureHost"
+
+	// MetaEnvironment identifies the deployment a record was captured against
+	// (e.g., "staging", "production").
+	MetaEnvironment = "environment"
+
+	// MetaLabel is a free-form user label for a capture session.
+	MetaLabel = "label"
+
+	// MetaClientIP is the IP address of the client that sent a request.
+	MetaClientIP = "clientIP"
+
+	// MetaResponsePlaceholder marks records whose response was not captured,
+	// such as curl commands converted without being executed. Their response
+	// is a stand-in that schema inference ignores.
+	MetaResponsePlaceholder = "responsePlaceholder"
+
+	// MetaHARPageRef and MetaHARPageTitle identify the HAR page (user flow)
+	// a record was captured in: its pageref ID and the page's title.
+	MetaHARPageRef   = "har.pageref"
+	MetaHARPageTitle = "har.pageTitle"
+
+	// MetaRequestBodyHash and MetaResponseBodyHash hold the ContentHash of
+	// bodies captured in hash-only mode (LoggingOptions.HashBodies).
+	MetaRequestBodyHash  = "requestBodyHash"
+	MetaResponseBodyHash = "responseBodyHash"
+
+	// MetaRequestBodyShape and MetaResponseBodyShape hold the
+	// StructureFingerprint of bodies captured in hash-only mode.
+	MetaRequestBodyShape  = "requestBodyShape"
+	MetaResponseBodyShape = "responseBodyShape"
+
+	// MetaRequestTruncatedHeaders and MetaResponseTruncatedHeaders list the
+	// headers whose values were cut to LoggingOptions.MaxHeaderValueSize.
+	MetaRequestTruncatedHeaders  = "requestTruncatedHeaders"
+	MetaResponseTruncatedHeaders = "responseTruncatedHeaders"
+
+	// MetaRequestDroppedHeaders and MetaResponseDroppedHeaders list the
+	// headers left out by LoggingOptions.MaxHeaders.
+	MetaRequestDroppedHeaders  = "requestDroppedHeaders"
+	MetaResponseDroppedHeaders = "responseDroppedHeaders"
+)
+
+// SetMeta sets a metadata value, creating the metadata map if needed.
+func (r *IRRecord) SetMeta(key string, value any) {
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]interface{})
+	}
+	r.Metadata[key] = value
+}
+
+// MetaString returns a metadata value formatted as a string, or "" if the key
+// is not set.
+func (r *IRRecord) MetaString(key string) string {
+	value, ok := r.Metadata[key]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// HasPlaceholderResponse reports whether the record's response is a stand-in
+// for one that was not captured (see MetaResponsePlaceholder).
+func (r *IRRecord) HasPlaceholderResponse() bool {
+	placeholder, _ := r.Metadata[MetaResponsePlaceholder].(bool)
+	return placeholder
+}
+
+// ApplyMetadata sets each metadata entry on every record, without overwriting
+// values the records already have.
+func ApplyMetadata(records []IRRecord, metadata map[string]any) {
+	for i := range records {
+		applyMetadata(&records[i], metadata)
+	}
+}
+
+func applyMetadata(record *IRRecord, metadata map[string]any) {
+	for key, value := range metadata {
+		if _, exists := record.Metadata[key]; !exists {
+			record.SetMeta(key, value)
+		}
+	}
+}
+
+// ParseMetadata parses key=value pairs such as "environment=staging" into a
+// metadata map.
+func ParseMetadata(pairs []string) (map[string]any, error) {
+	metadata := make(map[string]any, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid metadata: %s (use key=value)", pair)
+		}
+		metadata[key] = value
+	}
+	return metadata, nil
+}
+
+// MatchesMetadata reports whether a record has every key in filter with the
+// given value (compared as strings).
+func MatchesMetadata(record *IRRecord, filter map[string]any) bool {
+	for key, want := range filter {
+		if _, ok := record.Metadata[key]; !ok || record.MetaString(key) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package ir
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRecordMetadata(t *testing.T) {
+	records := []IRRecord{
+		{Request: Request{Method: RequestMethodGET, Path: "/a"}},
+		{Request: Request{Method: RequestMethodGET, Path: "/b"}, Metadata: map[string]interface{}{MetaEnvironment: "production"}},
+	}
+
+	ApplyMetadata(records, map[string]any{MetaEnvironment: "staging", "attempt": 2})
+	if records[0].MetaString(MetaEnvironment) != "staging" || records[0].MetaString("attempt") != "2" {
+		t.Errorf("expected metadata to be applied, got %v", records[0].Metadata)
+	}
+	if records[1].MetaString(MetaEnvironment) != "production" {
+		t.Errorf("expected existing metadata to be kept, got %v", records[1].Metadata)
+	}
+	if records[0].MetaString(MetaLabel) != "" {
+		t.Error("expected empty string for missing key")
+	}
+
+	filter, err := ParseMetadata([]string{"environment=staging", "attempt=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !MatchesMetadata(&records[0], filter) {
+		t.Error("expected first record to match filter")
+	}
+	if MatchesMetadata(&records[1], filter) {
+		t.Error("expected second record not to match filter")
+	}
+
+	if _, err := ParseMetadata([]string{"environment"}); err == nil {
+		t.Error("expected error for pair without value")
+	}
+}
+
+func TestRecordMetadataRoundTrip(t *testing.T) {
+	record := IRRecord{
+		Request:  Request{Method: RequestMethodGET, Path: "/a"},
+		Response: Response{Status: 200},
+	}
+	record.SetMeta(MetaSourceFile, "capture.har")
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"meta":{"sourceFile":"capture.har"}`) {
+		t.Errorf("expected meta field in %s", data)
+	}
+
+	var decoded IRRecord
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.MetaString(MetaSourceFile) != "capture.har" {
+		t.Errorf("expected source file after round trip, got %v", decoded.Metadata)
+	}
+}
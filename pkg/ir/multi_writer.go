@@ -6,6 +6,18 @@ import (
 
 // MultiWriter fans out writes to multiple IRWriter destinations.
 // Writes are performed sequentially to each writer in order.
+//
+// To sample or filter per destination, wrap a writer in a FilteringWriter:
+//
+//	archive := ir.NewFilteringWriter(gzipWriter, ir.WriterFilter{})
+//	remote := ir.NewFilteringWriter(collector, ir.WriterFilter{SampleRate: 0.01})
+//	multi, err := ir.NewMultiWriter(archive, remote)
+//
+// Each child decides independently on the records MultiWriter receives, so
+// filters upstream, such as LoggingOptions.SampleRate, apply first and the
+// rates multiply. Children sampling with the same SampleSeed pick nested sets
+// of record IDs: the records sampled at 1% are among those sampled at 10%.
+// Flush and Close reach every child whatever its filter.
 type MultiWriter struct {
 	writers []IRWriter
 }
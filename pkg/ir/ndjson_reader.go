@@ -52,7 +52,7 @@ func (r *NDJSONReader) Read() (*IRRecord, error) {
 
 		var record IRRecord
 		if err := json.Unmarshal([]byte(line), &record); err != nil {
-			return nil, fmt.Errorf("line %d: %w", r.lineNum, err)
+			return nil, &ErrInvalidRecord{Line: r.lineNum, Err: err}
 		}
 		return &record, nil
 	}
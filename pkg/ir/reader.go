@@ -53,7 +53,7 @@ func ReadBatch(r io.Reader) ([]IRRecord, error) {
 	}
 
 	if batch.Version != Version {
-		return nil, fmt.Errorf("unsupported IR version: %s (expected %s)", batch.Version, Version)
+		return nil, unsupportedVersionError("batch", batch.Version)
 	}
 
 	return batch.Records, nil
@@ -78,7 +78,7 @@ func ReadNDJSON(r io.Reader) ([]IRRecord, error) {
 
 		var record IRRecord
 		if err := json.Unmarshal([]byte(line), &record); err != nil {
-			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			return nil, &ErrInvalidRecord{Line: lineNum, Err: err}
 		}
 		records = append(records, record)
 	}
@@ -171,7 +171,9 @@ func StreamNDJSON(r io.Reader) (<-chan IRRecord, <-chan error) {
 		buf := make([]byte, 0, 64*1024)
 		scanner.Buffer(buf, 1024*1024)
 
+		lineNum := 0
 		for scanner.Scan() {
+			lineNum++
 			line := strings.TrimSpace(scanner.Text())
 			if line == "" {
 				continue
@@ -179,7 +181,7 @@ func StreamNDJSON(r io.Reader) (<-chan IRRecord, <-chan error) {
 
 			var record IRRecord
 			if err := json.Unmarshal([]byte(line), &record); err != nil {
-				errs <- err
+				errs <- &ErrInvalidRecord{Line: lineNum, Err: err}
 				return
 			}
 			records <- record
@@ -0,0 +1,128 @@
+package ir
+
+import "strings"
+
+// DefaultRedactReplacement is the value substituted for redacted data.
+const DefaultRedactReplacement = "[REDACTED]"
+
+// RedactRules describes which parts of a record to redact.
+// Names are matched case-insensitively.
+type RedactRules struct {
+	// Headers lists request and response header names to redact.
+	Headers []string
+
+	// QueryParams lists query parameter names to redact.
+	QueryParams []string
+
+	// BodyFields lists JSON object keys to redact at any depth in
+	// request and response bodies.
+	BodyFields []string
+
+	// Replacement is the value substituted for redacted data.
+	// Defaults to DefaultRedactReplacement.
+	Replacement string
+
+	// Encryptor, if set, encrypts matching values for its recipient instead
+	// of replacing them, so holders of the private key can recover them with
+	// DecryptRecord. Values that fail to encrypt are replaced.
+	Encryptor *FieldEncryptor
+}
+
+// Redact returns a deep copy of the record (see CloneRecord) with data
+// matching the rules replaced. The input record and its maps and bodies are
+// not modified or shared with the result.
+func Redact(record IRRecord, rules RedactRules) IRRecord {
+	record = CloneRecord(record)
+
+	replacement := rules.Replacement
+	if replacement == "" {
+		replacement = DefaultRedactReplacement
+	}
+
+	replace := func(interface{}) interface{} { return replacement }
+	if rules.Encryptor != nil {
+		replace = func(v interface{}) interface{} {
+			encrypted, err := rules.Encryptor.Encrypt(v)
+			if err != nil {
+				return replacement
+			}
+			return encrypted
+		}
+	}
+
+	headers := nameSet(rules.Headers)
+	query := nameSet(rules.QueryParams)
+	fields := nameSet(rules.BodyFields)
+
+	redactHeaders(record.Request.Headers, headers, replace)
+	redactHeaders(record.Response.Headers, headers, replace)
+	redactHeaderValues(record.Request.HeaderValues, headers, replace)
+	redactHeaderValues(record.Response.HeaderValues, headers, replace)
+
+	for k, v := range record.Request.Query {
+		if query[strings.ToLower(k)] {
+			record.Request.Query[k] = replace(v)
+		}
+	}
+
+	if len(fields) > 0 {
+		record.Request.Body = redactBody(record.Request.Body, fields, replace)
+		record.Response.Body = redactBody(record.Response.Body, fields, replace)
+	}
+
+	return record
+}
+
+// RedactAll returns redacted copies of records.
+func RedactAll(records []IRRecord, rules RedactRules) []IRRecord {
+	result := make([]IRRecord, len(records))
+	for i, record := range records {
+		result[i] = Redact(record, rules)
+	}
+	return result
+}
+
+func nameSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToLower(name)] = true
+	}
+	return set
+}
+
+func redactHeaders(headers map[string]string, names map[string]bool, replace func(interface{}) interface{}) {
+	for k, v := range headers {
+		if names[strings.ToLower(k)] {
+			headers[k], _ = replace(v).(string)
+		}
+	}
+}
+
+func redactHeaderValues(values map[string][]string, names map[string]bool, replace func(interface{}) interface{}) {
+	for k, vals := range values {
+		if names[strings.ToLower(k)] {
+			for i, v := range vals {
+				vals[i], _ = replace(v).(string)
+			}
+		}
+	}
+}
+
+// redactBody replaces values of matching keys in objects at any depth.
+func redactBody(v interface{}, fields map[string]bool, replace func(interface{}) interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if fields[strings.ToLower(k)] {
+				val[k] = replace(child)
+				continue
+			}
+			val[k] = redactBody(child, fields, replace)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = redactBody(child, fields, replace)
+		}
+	}
+	return v
+}
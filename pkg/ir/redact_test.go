@@ -0,0 +1,110 @@
+package ir
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	record := IRRecord{
+		Request: Request{
+			Method:  RequestMethodPOST,
+			Path:    "/login",
+			Headers: map[string]string{"Authorization": "Bearer secret", "Accept": "application/json"},
+			Query:   map[string]interface{}{"api_key": "abc", "page": "1"},
+			Body: map[string]interface{}{
+				"user":     "alice",
+				"password": "hunter2",
+				"devices":  []interface{}{map[string]interface{}{"token": "t1", "name": "phone"}},
+			},
+		},
+		Response: Response{
+			Status:  200,
+			Headers: map[string]string{"set-cookie": "session=1"},
+			Body:    map[string]interface{}{"token": "t2"},
+		},
+	}
+
+	redacted := Redact(record, RedactRules{
+		Headers:     []string{"authorization", "Set-Cookie"},
+		QueryParams: []string{"API_KEY"},
+		BodyFields:  []string{"password", "token"},
+	})
+
+	if redacted.Request.Headers["Authorization"] != DefaultRedactReplacement {
+		t.Errorf("authorization not redacted: %v", redacted.Request.Headers)
+	}
+	if redacted.Request.Headers["Accept"] != "application/json" {
+		t.Errorf("accept should be kept: %v", redacted.Request.Headers)
+	}
+	if redacted.Response.Headers["set-cookie"] != DefaultRedactReplacement {
+		t.Errorf("set-cookie not redacted: %v", redacted.Response.Headers)
+	}
+	if redacted.Request.Query["api_key"] != DefaultRedactReplacement || redacted.Request.Query["page"] != "1" {
+		t.Errorf("unexpected query: %v", redacted.Request.Query)
+	}
+
+	body := redacted.Request.Body.(map[string]interface{})
+	if body["password"] != DefaultRedactReplacement || body["user"] != "alice" {
+		t.Errorf("unexpected request body: %v", body)
+	}
+	device := body["devices"].([]interface{})[0].(map[string]interface{})
+	if device["token"] != DefaultRedactReplacement || device["name"] != "phone" {
+		t.Errorf("nested field not redacted: %v", device)
+	}
+	if redacted.Response.Body.(map[string]interface{})["token"] != DefaultRedactReplacement {
+		t.Errorf("response body not redacted: %v", redacted.Response.Body)
+	}
+
+	// Original is unchanged
+	if record.Request.Headers["Authorization"] != "Bearer secret" {
+		t.Error("original headers were modified")
+	}
+	if record.Request.Body.(map[string]interface{})["password"] != "hunter2" {
+		t.Error("original body was modified")
+	}
+}
+
+func TestRedactHeaderValues(t *testing.T) {
+	record := IRRecord{Request: Request{
+		Headers:      map[string]string{"cookie": "a=1"},
+		HeaderValues: map[string][]string{"cookie": {"a=1", "b=2"}},
+	}}
+	redacted := Redact(record, RedactRules{Headers: []string{"Cookie"}})
+	for _, v := range redacted.Request.HeaderValues["cookie"] {
+		if v != DefaultRedactReplacement {
+			t.Errorf("expected every cookie value redacted, got %v", redacted.Request.HeaderValues["cookie"])
+		}
+	}
+	if record.Request.HeaderValues["cookie"][1] != "b=2" {
+		t.Error("expected the input record to be unchanged")
+	}
+}
+
+func TestRedactDoesNotShareInput(t *testing.T) {
+	record := IRRecord{
+		Request: Request{
+			Headers: map[string]string{"Accept": "application/json"},
+			Body:    map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": "a"}}},
+		},
+		Response: Response{Status: 200, HeaderValues: map[string][]string{"Vary": {"Accept"}}},
+		Metadata: map[string]interface{}{"label": "run-1"},
+	}
+
+	// No rule matches, so nothing is replaced, but nothing may be shared either
+	redacted := Redact(record, RedactRules{Headers: []string{"Authorization"}})
+	redacted.Request.Headers["Accept"] = "text/plain"
+	redacted.Request.Body.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["name"] = "b"
+	redacted.Response.HeaderValues["Vary"][0] = "Origin"
+	redacted.Metadata["label"] = "run-2"
+
+	if record.Request.Headers["Accept"] != "application/json" {
+		t.Error("request headers are shared with the input")
+	}
+	if record.Request.Body.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["name"] != "a" {
+		t.Error("request body is shared with the input")
+	}
+	if record.Response.HeaderValues["Vary"][0] != "Accept" {
+		t.Error("response header values are shared with the input")
+	}
+	if record.MetaString("label") != "run-1" {
+		t.Error("metadata is shared with the input")
+	}
+}
@@ -0,0 +1,173 @@
+package ir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSessionGap is the idle time after which a client's next request
+// starts a new session.
+const DefaultSessionGap = 30 * time.Minute
+
+// DefaultAPIKeyHeaders are the request headers checked for an API key.
+var DefaultAPIKeyHeaders = []string{"x-api-key", "api-key", "apikey", "x-auth-token"}
+
+// SessionOptions configures how records are grouped into sessions.
+type SessionOptions struct {
+	// Gap is the idle time that ends a session (DefaultSessionGap if <= 0).
+	Gap time.Duration
+
+	// APIKeyHeaders are the request headers checked for an API key
+	// (DefaultAPIKeyHeaders if empty).
+	APIKeyHeaders []string
+}
+
+// Session is a sequence of requests made by one client identity without an
+// idle gap longer than SessionOptions.Gap.
+type Session struct {
+	ID       string // "session-1", "session-2", ... in order of first request
+	Identity string // e.g., "bearer:3f2a9c1b0d4e", "apikey:...", or "ip:203.0.113.7"
+	Start    time.Time
+	End      time.Time // zero when records have no timestamps
+	Records  []*IRRecord
+}
+
+// SessionIdentity returns the client identity of a record: a hash of its
+// bearer token, else a hash of its API key, else its client IP (from
+// MetaClientIP or the X-Forwarded-For and X-Real-IP headers). It returns ""
+// when the record carries none of these, as when converters filtered the
+// authorization headers.
+func SessionIdentity(record *IRRecord, opts SessionOptions) string {
+	headers := record.Request.Headers
+	if auth := headerValue(headers, "authorization"); auth != "" {
+		if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "bearer") && token != "" {
+			return "bearer:" + identityHash(token)
+		}
+	}
+
+	keyHeaders := opts.APIKeyHeaders
+	if len(keyHeaders) == 0 {
+		keyHeaders = DefaultAPIKeyHeaders
+	}
+	for _, name := range keyHeaders {
+		if key := headerValue(headers, name); key != "" {
+			return "apikey:" + identityHash(key)
+		}
+	}
+
+	if ip := record.MetaString(MetaClientIP); ip != "" {
+		return "ip:" + ip
+	}
+	if forwarded := headerValue(headers, "x-forwarded-for"); forwarded != "" {
+		first, _, _ := strings.Cut(forwarded, ",")
+		return "ip:" + strings.TrimSpace(first)
+	}
+	if ip := headerValue(headers, "x-real-ip"); ip != "" {
+		return "ip:" + ip
+	}
+	return ""
+}
+
+// Sessionizer groups a stream of records into sessions. Records must be
+// added in time order.
+type Sessionizer struct {
+	opts     SessionOptions
+	open     map[string]*Session // identity -> current session
+	sessions []*Session
+}
+
+// NewSessionizer creates a new Sessionizer.
+func NewSessionizer(opts SessionOptions) *Sessionizer {
+	if opts.Gap <= 0 {
+		opts.Gap = DefaultSessionGap
+	}
+	return &Sessionizer{
+		opts: opts,
+		open: make(map[string]*Session),
+	}
+}
+
+// Add assigns a record to its identity's session, starting a new session
+// after an idle gap. It returns the session, or nil for records without an
+// identity. Records without a timestamp continue the current session.
+func (s *Sessionizer) Add(record *IRRecord) *Session {
+	identity := SessionIdentity(record, s.opts)
+	if identity == "" {
+		return nil
+	}
+
+	session, ok := s.open[identity]
+	if ok && record.Timestamp != nil && !session.End.IsZero() && record.Timestamp.Sub(session.End) > s.opts.Gap {
+		ok = false
+	}
+	if !ok {
+		session = &Session{
+			ID:       fmt.Sprintf("session-%d", len(s.sessions)+1),
+			Identity: identity,
+		}
+		s.open[identity] = session
+		s.sessions = append(s.sessions, session)
+	}
+
+	session.Records = append(session.Records, record)
+	if record.Timestamp != nil {
+		if session.Start.IsZero() {
+			session.Start = *record.Timestamp
+		}
+		session.End = *record.Timestamp
+	}
+	return session
+}
+
+// Sessions returns the sessions in order of their first request.
+func (s *Sessionizer) Sessions() []*Session {
+	return s.sessions
+}
+
+// Sessionize groups records into sessions by client identity. Records are
+// ordered by timestamp when all of them have one, and otherwise taken in
+// input order. Records without an identity are left out. Sessions reference
+// the records in the input slice.
+func Sessionize(records []IRRecord, opts SessionOptions) []*Session {
+	ordered := make([]*IRRecord, len(records))
+	timed := true
+	for i := range records {
+		ordered[i] = &records[i]
+		timed = timed && records[i].Timestamp != nil
+	}
+	if timed {
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].Timestamp.Before(*ordered[j].Timestamp)
+		})
+	}
+
+	s := NewSessionizer(opts)
+	for _, record := range ordered {
+		s.Add(record)
+	}
+	return s.Sessions()
+}
+
+// identityHash returns a short hash of a credential, so sessions can be told
+// apart without exposing it.
+func identityHash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// headerValue returns the value of a header, matching its name case-insensitively.
+func headerValue(headers map[string]string, name string) string {
+	if v, ok := headers[name]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
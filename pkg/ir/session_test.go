@@ -0,0 +1,90 @@
+package ir
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionIdentity(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		meta    map[string]interface{}
+		prefix  string
+	}{
+		{"bearer", map[string]string{"Authorization": "Bearer abc123", "X-Api-Key": "k"}, nil, "bearer:"},
+		{"api key", map[string]string{"x-api-key": "k"}, nil, "apikey:"},
+		{"client ip metadata", map[string]string{"x-forwarded-for": "198.51.100.1"}, map[string]interface{}{MetaClientIP: "203.0.113.7"}, "ip:203.0.113.7"},
+		{"forwarded for", map[string]string{"X-Forwarded-For": "198.51.100.1, 10.0.0.1"}, nil, "ip:198.51.100.1"},
+		{"basic auth ignored", map[string]string{"authorization": "Basic dXNlcg=="}, nil, ""},
+		{"none", nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &IRRecord{Request: Request{Headers: tt.headers}, Metadata: tt.meta}
+			got := SessionIdentity(record, SessionOptions{})
+			if tt.prefix == "" {
+				if got != "" {
+					t.Errorf("expected no identity, got %q", got)
+				}
+				return
+			}
+			if len(got) < len(tt.prefix) || got[:len(tt.prefix)] != tt.prefix {
+				t.Errorf("expected identity starting %q, got %q", tt.prefix, got)
+			}
+		})
+	}
+
+	a := &IRRecord{Request: Request{Headers: map[string]string{"authorization": "Bearer secret-token"}}}
+	if id := SessionIdentity(a, SessionOptions{}); id == "bearer:secret-token" || len(id) != len("bearer:")+12 {
+		t.Errorf("expected hashed bearer identity, got %q", id)
+	}
+}
+
+func TestSessionize(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	record := func(offset time.Duration, key, path string) IRRecord {
+		ts := start.Add(offset)
+		return IRRecord{
+			Timestamp: &ts,
+			Request: Request{
+				Method:  RequestMethodGET,
+				Path:    path,
+				Headers: map[string]string{"x-api-key": key},
+			},
+		}
+	}
+	records := []IRRecord{
+		record(2*time.Minute, "alice", "/a2"),
+		record(0, "alice", "/a1"),
+		record(time.Minute, "bob", "/b1"),
+		record(time.Hour, "alice", "/a3"), // after the gap
+		{Request: Request{Method: RequestMethodGET, Path: "/anonymous"}},
+	}
+
+	// Missing timestamp on the anonymous record keeps input order
+	sessions := Sessionize(records[:4], SessionOptions{Gap: 10 * time.Minute})
+	if len(sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %d", len(sessions))
+	}
+	first := sessions[0]
+	if first.ID != "session-1" || len(first.Records) != 2 {
+		t.Fatalf("expected session-1 with 2 records, got %s with %d", first.ID, len(first.Records))
+	}
+	if first.Records[0].Request.Path != "/a1" || first.Records[1].Request.Path != "/a2" {
+		t.Errorf("expected records in time order, got %s, %s", first.Records[0].Request.Path, first.Records[1].Request.Path)
+	}
+	if !first.Start.Equal(start) || !first.End.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("unexpected session bounds %v - %v", first.Start, first.End)
+	}
+	if sessions[1].Identity == first.Identity {
+		t.Error("expected bob's session to have a different identity")
+	}
+	if sessions[2].Identity != first.Identity || sessions[2].Records[0].Request.Path != "/a3" {
+		t.Error("expected the idle gap to start a new session for alice")
+	}
+
+	if got := Sessionize(records, SessionOptions{Gap: 10 * time.Minute}); len(got) != 3 {
+		t.Errorf("expected records without an identity to be skipped, got %d sessions", len(got))
+	}
+}
@@ -0,0 +1,339 @@
+package ir
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// DefaultSortChunkSize is the number of records a TimestampSorter holds in
+// memory before spilling them to a temporary file.
+const DefaultSortChunkSize = 50000
+
+// sortFanIn is the most chunk files a TimestampSorter merges at once, which
+// keeps the files it has open well under common open-file limits.
+const sortFanIn = 64
+
+// TimestampSorter sorts records by timestamp with an external merge sort, so
+// that inputs larger than memory can be sorted. Records are buffered up to
+// the chunk size, sorted, and spilled to temporary NDJSON files, which are
+// merged when the records are read back by Each. When there are more than
+// 64 chunk files, they are first merged in groups into longer runs, so that
+// no more than 64 files are open at once.
+//
+// The sort is stable: records with equal timestamps keep the order in which
+// they were added. Records without a timestamp sort after all others.
+type TimestampSorter struct {
+	chunkSize int
+	fanIn     int
+	tempDir   string
+	buf       []IRRecord
+	chunks    []string
+}
+
+// NewTimestampSorter creates a sorter that holds up to chunkSize records in
+// memory (DefaultSortChunkSize if chunkSize <= 0) and spills the rest to
+// tempDir (os.TempDir() if empty). Close removes the temporary files.
+func NewTimestampSorter(chunkSize int, tempDir string) *TimestampSorter {
+	if chunkSize <= 0 {
+		chunkSize = DefaultSortChunkSize
+	}
+	return &TimestampSorter{chunkSize: chunkSize, fanIn: sortFanIn, tempDir: tempDir}
+}
+
+// Add adds a record to the sorter.
+func (s *TimestampSorter) Add(record IRRecord) error {
+	s.buf = append(s.buf, record)
+	if len(s.buf) >= s.chunkSize {
+		return s.spill()
+	}
+	return nil
+}
+
+// Each calls fn for every added record in timestamp order, stopping at the
+// first error.
+func (s *TimestampSorter) Each(fn func(*IRRecord) error) error {
+	sortChunk(s.buf)
+	if len(s.chunks) == 0 {
+		for i := range s.buf {
+			if err := fn(&s.buf[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := s.mergeRuns(); err != nil {
+		return err
+	}
+	h, err := openChunks(s.chunks)
+	if err != nil {
+		return err
+	}
+	defer h.close()
+	if len(s.buf) > 0 {
+		if err := h.push(&mergeCursor{chunk: len(s.chunks), records: s.buf}); err != nil {
+			return err
+		}
+	}
+	return h.drain(fn)
+}
+
+// WriteTo writes every added record to w in timestamp order and returns the
+// number of records written.
+func (s *TimestampSorter) WriteTo(w IRWriter) (int, error) {
+	count := 0
+	err := s.Each(func(record *IRRecord) error {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// Close removes the sorter's temporary files.
+func (s *TimestampSorter) Close() error {
+	var errs []error
+	for _, path := range s.chunks {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	s.chunks = nil
+	s.buf = nil
+	return errors.Join(errs...)
+}
+
+// spill sorts the buffered records and writes them to a temporary file.
+func (s *TimestampSorter) spill() error {
+	sortChunk(s.buf)
+
+	w, path, err := s.createChunk()
+	if err != nil {
+		return err
+	}
+	s.chunks = append(s.chunks, path)
+	for i := range s.buf {
+		if err := w.Write(&s.buf[i]); err != nil {
+			_ = w.Close()
+			return fmt.Errorf("writing sort chunk: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("writing sort chunk: %w", err)
+	}
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// mergeRuns merges consecutive groups of up to fanIn chunk files into runs,
+// repeating until the chunks can be merged in one pass by Each. Merging
+// consecutive chunks keeps the sort stable.
+func (s *TimestampSorter) mergeRuns() error {
+	for len(s.chunks) > s.fanIn {
+		var runs []string
+		for start := 0; start < len(s.chunks); start += s.fanIn {
+			group := s.chunks[start:min(start+s.fanIn, len(s.chunks))]
+			if len(group) == 1 {
+				runs = append(runs, group[0])
+				continue
+			}
+			run, err := s.mergeGroup(group)
+			if err != nil {
+				// Close removes the runs and the chunks not merged yet
+				s.chunks = append(runs, s.chunks[start:]...)
+				return err
+			}
+			runs = append(runs, run)
+		}
+		s.chunks = runs
+	}
+	return nil
+}
+
+// mergeGroup merges chunk files into a new run file and removes them.
+func (s *TimestampSorter) mergeGroup(chunks []string) (string, error) {
+	h, err := openChunks(chunks)
+	if err != nil {
+		return "", err
+	}
+	defer h.close()
+
+	w, path, err := s.createChunk()
+	if err != nil {
+		return "", err
+	}
+	if err := h.drain(w.Write); err != nil {
+		_ = w.Close()
+		_ = os.Remove(path)
+		return "", fmt.Errorf("writing sort run: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("writing sort run: %w", err)
+	}
+	for _, chunk := range chunks {
+		_ = os.Remove(chunk)
+	}
+	return path, nil
+}
+
+// createChunk creates a temporary chunk file and returns a writer that
+// closes it, with the file's path.
+func (s *TimestampSorter) createChunk() (*NDJSONWriter, string, error) {
+	f, err := os.CreateTemp(s.tempDir, "traffic2openapi-sort-*.ndjson")
+	if err != nil {
+		return nil, "", fmt.Errorf("creating sort chunk: %w", err)
+	}
+	w := NewNDJSONWriter(f)
+	w.closer = f
+	return w, f.Name(), nil
+}
+
+// sortChunk stably sorts records by timestamp, placing records without a
+// timestamp last.
+func sortChunk(records []IRRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return timestampLess(&records[i], &records[j])
+	})
+}
+
+func timestampLess(a, b *IRRecord) bool {
+	if a.Timestamp == nil {
+		return false
+	}
+	if b.Timestamp == nil {
+		return true
+	}
+	return a.Timestamp.Before(*b.Timestamp)
+}
+
+// mergeCursor reads the sorted records of one chunk, from a spilled file or
+// from memory.
+type mergeCursor struct {
+	chunk   int
+	record  *IRRecord
+	reader  *NDJSONReader
+	records []IRRecord
+}
+
+// next advances to the chunk's next record and reports whether there is one.
+func (c *mergeCursor) next() (bool, error) {
+	if c.reader == nil {
+		if len(c.records) == 0 {
+			return false, nil
+		}
+		c.record, c.records = &c.records[0], c.records[1:]
+		return true, nil
+	}
+	record, err := c.reader.Read()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading sort chunk: %w", err)
+	}
+	c.record = record
+	return true, nil
+}
+
+func (c *mergeCursor) close() {
+	if c.reader != nil {
+		_ = c.reader.Close()
+	}
+}
+
+// mergeHeap orders chunk cursors by their current record, breaking ties by
+// chunk so that the merge is stable.
+type mergeHeap []*mergeCursor
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	if timestampLess(h[i].record, h[j].record) {
+		return true
+	}
+	if timestampLess(h[j].record, h[i].record) {
+		return false
+	}
+	return h[i].chunk < h[j].chunk
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x any) { *h = append(*h, x.(*mergeCursor)) }
+
+func (h *mergeHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// push adds a cursor positioned at its first record, closing empty ones.
+func (h *mergeHeap) push(c *mergeCursor) error {
+	more, err := c.next()
+	if err != nil {
+		c.close()
+		return err
+	}
+	if !more {
+		c.close()
+		return nil
+	}
+	heap.Push(h, c)
+	return nil
+}
+
+// openChunks opens a merge heap over chunk files, ordered by their position
+// in chunks.
+func openChunks(chunks []string) (*mergeHeap, error) {
+	h := &mergeHeap{}
+	for i, path := range chunks {
+		r, err := NewNDJSONFileReader(path)
+		if err != nil {
+			h.close()
+			return nil, fmt.Errorf("opening sort chunk: %w", err)
+		}
+		if err := h.push(&mergeCursor{chunk: i, reader: r}); err != nil {
+			h.close()
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// drain calls fn for every record of the heap's cursors in merged order,
+// closing each cursor as soon as it is exhausted.
+func (h *mergeHeap) drain(fn func(*IRRecord) error) error {
+	for h.Len() > 0 {
+		c := (*h)[0]
+		if err := fn(c.record); err != nil {
+			return err
+		}
+		more, err := c.next()
+		if err != nil {
+			return err
+		}
+		if more {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+			c.close()
+		}
+	}
+	return nil
+}
+
+// close closes the cursors left in the heap.
+func (h *mergeHeap) close() {
+	for _, c := range *h {
+		c.close()
+	}
+	*h = nil
+}
@@ -0,0 +1,146 @@
+package ir
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTimestampSorter(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	// Minutes from start, in input order; -1 means no timestamp.
+	offsets := []int{5, 1, -1, 3, 1, 9, 0, -1, 3, 7, 2}
+
+	for _, chunkSize := range []int{0, 1, 3, 100} {
+		t.Run(fmt.Sprintf("chunk=%d", chunkSize), func(t *testing.T) {
+			dir := t.TempDir()
+			sorter := NewTimestampSorter(chunkSize, dir)
+			for i, offset := range offsets {
+				record := NewRecord(RequestMethodGET, fmt.Sprintf("/items/%d", i), 200)
+				record.SetID(fmt.Sprintf("r%d", i))
+				if offset >= 0 {
+					record.SetTimestamp(start.Add(time.Duration(offset) * time.Minute))
+				}
+				if err := sorter.Add(*record); err != nil {
+					t.Fatalf("Add failed: %v", err)
+				}
+			}
+
+			var ids []string
+			err := sorter.Each(func(record *IRRecord) error {
+				ids = append(ids, *record.Id)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Each failed: %v", err)
+			}
+
+			want := []string{"r6", "r1", "r4", "r10", "r3", "r8", "r0", "r9", "r5", "r2", "r7"}
+			if fmt.Sprint(ids) != fmt.Sprint(want) {
+				t.Errorf("expected order %v, got %v", want, ids)
+			}
+
+			if err := sorter.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("expected temporary files to be removed, found %d", len(entries))
+			}
+		})
+	}
+}
+
+func TestTimestampSorterManyChunks(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	const n = 1000
+	// Minutes from start for each record; many records share a minute
+	offset := func(i int) int { return (i * 7919) % 250 }
+
+	for _, fanIn := range []int{sortFanIn, 3} {
+		t.Run(fmt.Sprintf("fanIn=%d", fanIn), func(t *testing.T) {
+			dir := t.TempDir()
+			sorter := NewTimestampSorter(1, dir)
+			sorter.fanIn = fanIn
+			for i := range n {
+				record := NewRecord(RequestMethodGET, "/items", 200)
+				record.SetID(fmt.Sprintf("r%d", i))
+				record.SetTimestamp(start.Add(time.Duration(offset(i)) * time.Minute))
+				if err := sorter.Add(*record); err != nil {
+					t.Fatalf("Add failed: %v", err)
+				}
+			}
+
+			count := 0
+			lastOffset, lastIndex := -1, -1
+			err := sorter.Each(func(record *IRRecord) error {
+				var i int
+				if _, err := fmt.Sscanf(*record.Id, "r%d", &i); err != nil {
+					return err
+				}
+				if o := offset(i); o < lastOffset || (o == lastOffset && i < lastIndex) {
+					t.Errorf("record r%d (minute %d) after r%d (minute %d)", i, o, lastIndex, lastOffset)
+				}
+				lastOffset, lastIndex = offset(i), i
+				count++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Each failed: %v", err)
+			}
+			if count != n {
+				t.Errorf("expected %d records, got %d", n, count)
+			}
+
+			// Merged chunks are removed, leaving at most fanIn runs
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) > fanIn || len(entries) != len(sorter.chunks) {
+				t.Errorf("expected at most %d sort runs, found %d files for %d runs", fanIn, len(entries), len(sorter.chunks))
+			}
+			if err := sorter.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("expected temporary files to be removed, found %d", len(entries))
+			}
+		})
+	}
+}
+
+func TestTimestampSorterWriteTo(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	sorter := NewTimestampSorter(2, t.TempDir())
+	defer sorter.Close()
+	for i := 5; i > 0; i-- {
+		record := NewRecord(RequestMethodGET, "/items", 200)
+		record.SetTimestamp(start.Add(time.Duration(i) * time.Second))
+		if err := sorter.Add(*record); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	w := NewChannelWriter(WithChannelBufferSize(10))
+	n, err := sorter.WriteTo(w)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("expected 5 records written, got %d", n)
+	}
+	_ = w.Close()
+
+	var last time.Time
+	for record := range w.Channel() {
+		if record.Timestamp.Before(last) {
+			t.Errorf("record at %v written after %v", record.Timestamp, last)
+		}
+		last = *record.Timestamp
+	}
+}
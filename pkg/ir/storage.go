@@ -124,7 +124,7 @@ func (r *StorageReader) Read() (*IRRecord, error) {
 
 	var record IRRecord
 	if err := json.Unmarshal(data, &record); err != nil {
-		return nil, fmt.Errorf("line %d: %w", r.lineNum, err)
+		return nil, &ErrInvalidRecord{Line: r.lineNum, Err: err}
 	}
 
 	return &record, nil
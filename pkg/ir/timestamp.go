@@ -0,0 +1,256 @@
+package ir
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultClockSkewThreshold is the apparent clock difference between two
+// sources above which DetectClockSkew reports skew.
+const DefaultClockSkewThreshold = 2 * time.Second
+
+// DefaultClockSkewMinMatches is the number of records two sources must have
+// in common before DetectClockSkew compares their clocks.
+const DefaultClockSkewMinMatches = 5
+
+// RecordSource is a named set of records from one capture source, such as a
+// browser HAR export, a server log, or a client transport.
+type RecordSource struct {
+	Name    string
+	Records []IRRecord
+
+	// Offset is added to every timestamp to correct the source's clock.
+	Offset time.Duration
+}
+
+// NormalizeTimestamps converts record timestamps to UTC after adding offset
+// to correct for the source's clock. Records without a timestamp are left
+// unchanged. It returns the number of timestamps updated.
+func NormalizeTimestamps(records []IRRecord, offset time.Duration) int {
+	count := 0
+	for i := range records {
+		if NormalizeTimestamp(&records[i], offset) {
+			count++
+		}
+	}
+	return count
+}
+
+// NormalizeTimestamp normalizes one record's timestamp like
+// NormalizeTimestamps and reports whether the record has a timestamp.
+func NormalizeTimestamp(record *IRRecord, offset time.Duration) bool {
+	if record.Timestamp == nil {
+		return false
+	}
+	ts := record.Timestamp.Add(offset).UTC()
+	record.Timestamp = &ts
+	return true
+}
+
+// SortByTimestamp sorts records by timestamp, keeping the input order of
+// records with equal timestamps. Records are left unchanged unless all of
+// them have a timestamp; it reports whether they were sorted.
+func SortByTimestamp(records []IRRecord) bool {
+	for i := range records {
+		if records[i].Timestamp == nil {
+			return false
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(*records[j].Timestamp)
+	})
+	return true
+}
+
+// ClockSkew describes an apparent clock difference between two sources that
+// captured the same traffic.
+type ClockSkew struct {
+	Source  string        // reference source
+	Other   string        // source whose clock differs
+	Offset  time.Duration // median Other - Source timestamp difference
+	Matched int           // number of records captured by both sources
+}
+
+// Correction returns the offset to apply to Other to align it with Source.
+func (s ClockSkew) Correction() time.Duration {
+	return -s.Offset
+}
+
+// String returns a human-readable description of the skew.
+func (s ClockSkew) String() string {
+	relation := "ahead of"
+	if s.Offset < 0 {
+		relation = "behind"
+	}
+	return fmt.Sprintf("%s is %s %s %s (%d matched records)",
+		s.Other, s.Offset.Abs(), relation, s.Source, s.Matched)
+}
+
+// DetectClockSkew compares every pair of sources and reports those whose
+// clocks disagree by more than threshold (DefaultClockSkewThreshold if
+// threshold <= 0). Source offsets are applied before comparing.
+//
+// Records are matched across sources by ID when both have one, and otherwise
+// by method, path, query, status, and body hashes when the record has a
+// request or response body and that combination occurs exactly once in each
+// source. Records without IDs or bodies are too easily confused with
+// unrelated requests and are not matched.
+// Pairs of sources with fewer than minMatches matched records
+// (DefaultClockSkewMinMatches if minMatches <= 0) are not compared.
+func DetectClockSkew(sources []RecordSource, threshold time.Duration, minMatches int) []ClockSkew {
+	detector := NewClockSkewDetector(threshold, minMatches)
+	for _, source := range sources {
+		index := detector.AddSource(source.Name, source.Offset)
+		for i := range source.Records {
+			detector.Add(index, &source.Records[i])
+		}
+	}
+	return detector.Skews()
+}
+
+// ClockSkewDetector detects clock skew between sources read one record at a
+// time, as DetectClockSkew does for sources held in memory. It keeps only
+// the timestamps and match keys of the records it is given, but keeps one
+// per record, so its memory grows with the number of records.
+type ClockSkewDetector struct {
+	threshold  time.Duration
+	minMatches int
+	names      []string
+	offsets    []time.Duration
+	indexes    []timestampIndex
+}
+
+// NewClockSkewDetector creates a detector reporting skew above threshold
+// between sources with at least minMatches records in common (see
+// DetectClockSkew for the defaults).
+func NewClockSkewDetector(threshold time.Duration, minMatches int) *ClockSkewDetector {
+	if threshold <= 0 {
+		threshold = DefaultClockSkewThreshold
+	}
+	if minMatches <= 0 {
+		minMatches = DefaultClockSkewMinMatches
+	}
+	return &ClockSkewDetector{threshold: threshold, minMatches: minMatches}
+}
+
+// AddSource registers a source whose timestamps are corrected by offset and
+// returns its index for Add.
+func (d *ClockSkewDetector) AddSource(name string, offset time.Duration) int {
+	d.names = append(d.names, name)
+	d.offsets = append(d.offsets, offset)
+	d.indexes = append(d.indexes, newTimestampIndex())
+	return len(d.names) - 1
+}
+
+// Add records the timestamp of a record read from the source at index. The
+// record's timestamp must not have had the source offset applied yet.
+func (d *ClockSkewDetector) Add(source int, record *IRRecord) {
+	d.indexes[source].add(record, d.offsets[source])
+}
+
+// Skews compares every pair of sources added so far.
+func (d *ClockSkewDetector) Skews() []ClockSkew {
+	var skews []ClockSkew
+	for i := range d.indexes {
+		for j := i + 1; j < len(d.indexes); j++ {
+			deltas := d.indexes[i].deltas(d.indexes[j])
+			if len(deltas) < d.minMatches {
+				continue
+			}
+			median := medianDuration(deltas)
+			if median.Abs() > d.threshold {
+				skews = append(skews, ClockSkew{
+					Source:  d.names[i],
+					Other:   d.names[j],
+					Offset:  median,
+					Matched: len(deltas),
+				})
+			}
+		}
+	}
+	return skews
+}
+
+// timestampIndex holds a source's timestamps by record ID and by unique
+// request key.
+type timestampIndex struct {
+	byID       map[string]time.Time
+	byKey      map[string]keyedTimestamp
+	duplicates map[string]bool
+}
+
+type keyedTimestamp struct {
+	ts    time.Time
+	hasID bool
+}
+
+func newTimestampIndex() timestampIndex {
+	return timestampIndex{
+		byID:       make(map[string]time.Time),
+		byKey:      make(map[string]keyedTimestamp),
+		duplicates: make(map[string]bool),
+	}
+}
+
+func (idx timestampIndex) add(record *IRRecord, offset time.Duration) {
+	if record.Timestamp == nil {
+		return
+	}
+	ts := record.Timestamp.Add(offset)
+	hasID := record.Id != nil && *record.Id != ""
+	if hasID {
+		idx.byID[*record.Id] = ts
+	}
+	key, ok := timestampKey(record)
+	if !ok {
+		return
+	}
+	if _, exists := idx.byKey[key]; exists || idx.duplicates[key] {
+		delete(idx.byKey, key)
+		idx.duplicates[key] = true
+		return
+	}
+	idx.byKey[key] = keyedTimestamp{ts: ts, hasID: hasID}
+}
+
+// deltas returns the timestamp differences (other - idx) of matched records.
+func (idx timestampIndex) deltas(other timestampIndex) []time.Duration {
+	var deltas []time.Duration
+	for id, ts := range idx.byID {
+		if otherTS, ok := other.byID[id]; ok {
+			deltas = append(deltas, otherTS.Sub(ts))
+		}
+	}
+	for key, entry := range idx.byKey {
+		otherEntry, ok := other.byKey[key]
+		// Records with IDs in both sources are matched by ID only
+		if ok && !(entry.hasID && otherEntry.hasID) {
+			deltas = append(deltas, otherEntry.ts.Sub(entry.ts))
+		}
+	}
+	return deltas
+}
+
+// timestampKey identifies a request for matching records without IDs. Records
+// without a request or response body have no key.
+func timestampKey(record *IRRecord) (string, bool) {
+	requestBody := bodyHash(record.Request.Body)
+	responseBody := bodyHash(record.Response.Body)
+	if requestBody == "" && responseBody == "" {
+		return "", false
+	}
+	query, _ := json.Marshal(record.Request.Query) // map keys are sorted
+	return fmt.Sprintf("%s %s?%s %d %s %s", record.Request.Method, record.Request.Path, query,
+		record.Response.Status, requestBody, responseBody), true
+}
+
+func medianDuration(values []time.Duration) time.Duration {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
@@ -0,0 +1,107 @@
+package ir
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNormalizeTimestamps(t *testing.T) {
+	local := time.Date(2024, 1, 15, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	records := []IRRecord{
+		{Timestamp: &local, Request: Request{Method: RequestMethodGET, Path: "/a"}},
+		{Request: Request{Method: RequestMethodGET, Path: "/b"}},
+	}
+
+	if n := NormalizeTimestamps(records, 30*time.Second); n != 1 {
+		t.Errorf("expected 1 timestamp updated, got %d", n)
+	}
+	want := time.Date(2024, 1, 15, 11, 0, 30, 0, time.UTC)
+	if !records[0].Timestamp.Equal(want) || records[0].Timestamp.Location() != time.UTC {
+		t.Errorf("expected %v, got %v", want, records[0].Timestamp)
+	}
+	if local.Location() == time.UTC {
+		t.Error("original timestamp should not be modified")
+	}
+	if records[1].Timestamp != nil {
+		t.Error("missing timestamp should stay nil")
+	}
+}
+
+func TestDetectClockSkew(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	makeRecords := func(skew time.Duration, withIDs bool) []IRRecord {
+		records := make([]IRRecord, 5)
+		for i := range records {
+			ts := start.Add(time.Duration(i)*time.Minute + skew)
+			records[i] = IRRecord{
+				Timestamp: &ts,
+				Request:   Request{Method: RequestMethodGET, Path: fmt.Sprintf("/users/%d", i)},
+				Response:  Response{Status: 200, Body: map[string]interface{}{"id": float64(i)}},
+			}
+			if withIDs {
+				id := fmt.Sprintf("req-%d", i)
+				records[i].Id = &id
+			}
+		}
+		return records
+	}
+
+	browser := RecordSource{Name: "browser.har", Records: makeRecords(0, true)}
+	server := RecordSource{Name: "server.ndjson", Records: makeRecords(-90*time.Second, true)}
+	client := RecordSource{Name: "client.ndjson", Records: makeRecords(time.Second, false)}
+
+	skews := DetectClockSkew([]RecordSource{browser, server, client}, 0, 0)
+	if len(skews) != 2 {
+		t.Fatalf("expected 2 skews, got %v", skews)
+	}
+	if skews[0].Other != "server.ndjson" || skews[0].Offset != -90*time.Second || skews[0].Matched != 5 {
+		t.Errorf("unexpected browser/server skew: %+v", skews[0])
+	}
+	if skews[0].Correction() != 90*time.Second {
+		t.Errorf("expected correction of 90s, got %v", skews[0].Correction())
+	}
+	// client has no IDs, so it matches server by request key only
+	if skews[1].Source != "server.ndjson" || skews[1].Other != "client.ndjson" || skews[1].Offset != 91*time.Second {
+		t.Errorf("unexpected server/client skew: %+v", skews[1])
+	}
+
+	// Applying the suggested correction removes the skew
+	server.Offset = skews[0].Correction()
+	client.Offset = -time.Second
+	if skews := DetectClockSkew([]RecordSource{browser, server, client}, 0, 0); len(skews) != 0 {
+		t.Errorf("expected no skew after correction, got %v", skews)
+	}
+}
+
+func TestDetectClockSkewIgnoresWeakMatches(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	record := func(path string, at time.Duration, body interface{}) IRRecord {
+		ts := start.Add(at)
+		return IRRecord{
+			Timestamp: &ts,
+			Request:   Request{Method: RequestMethodGET, Path: path},
+			Response:  Response{Status: 200, Body: body},
+		}
+	}
+
+	// Unrelated polls of the same bodyless endpoint an hour apart
+	a := RecordSource{Name: "a.ndjson", Records: []IRRecord{
+		record("/health", 0, nil),
+		record("/users/1", 0, map[string]interface{}{"id": float64(1)}),
+		record("/users/2", time.Minute, map[string]interface{}{"id": float64(2)}),
+	}}
+	b := RecordSource{Name: "b.ndjson", Records: []IRRecord{
+		record("/health", time.Hour, nil),
+		record("/users/1", time.Hour, map[string]interface{}{"id": float64(1)}),
+		record("/users/2", time.Hour+time.Minute, map[string]interface{}{"id": float64(2)}),
+	}}
+
+	if skews := DetectClockSkew([]RecordSource{a, b}, 0, 0); len(skews) != 0 {
+		t.Errorf("expected no skew from 2 matched records, got %v", skews)
+	}
+	skews := DetectClockSkew([]RecordSource{a, b}, 0, 2)
+	if len(skews) != 1 || skews[0].Matched != 2 {
+		t.Fatalf("expected skew from the 2 records with bodies, got %v", skews)
+	}
+}
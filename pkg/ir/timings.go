@@ -0,0 +1,91 @@
+package ir
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Milliseconds returns d in milliseconds, keeping sub-millisecond precision.
+func Milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// phaseTimer measures the timing phases of a request from httptrace hooks.
+// Hooks may run on other goroutines, e.g. when dialing several addresses.
+type phaseTimer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timings      Timings
+}
+
+func newPhaseTimer(start time.Time) *phaseTimer {
+	return &phaseTimer{start: start}
+}
+
+// hook adds the timer's hooks to trace.
+func (p *phaseTimer) hook(trace *httptrace.ClientTrace) {
+	trace.DNSStart = func(httptrace.DNSStartInfo) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.dnsStart = time.Now()
+	}
+	trace.DNSDone = func(httptrace.DNSDoneInfo) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.timings.DnsMs = p.since(p.dnsStart)
+	}
+	trace.ConnectStart = func(network, addr string) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.connectStart.IsZero() {
+			p.connectStart = time.Now()
+		}
+	}
+	trace.ConnectDone = func(network, addr string, err error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if err == nil && p.timings.ConnectMs == nil {
+			p.timings.ConnectMs = p.since(p.connectStart)
+		}
+	}
+	trace.TLSHandshakeStart = func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.tlsStart = time.Now()
+	}
+	trace.TLSHandshakeDone = func(tls.ConnectionState, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.timings.TlsMs = p.since(p.tlsStart)
+	}
+	trace.GotFirstResponseByte = func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.timings.TtfbMs = p.since(p.start)
+	}
+}
+
+// since returns the milliseconds elapsed since t, or nil if t is unset.
+func (p *phaseTimer) since(t time.Time) *float64 {
+	if t.IsZero() {
+		return nil
+	}
+	ms := Milliseconds(time.Since(t))
+	return &ms
+}
+
+// result returns the measured phases, or nil if none were measured.
+func (p *phaseTimer) result() *Timings {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timings == (Timings{}) {
+		return nil
+	}
+	timings := p.timings
+	return &timings
+}
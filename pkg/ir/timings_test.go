@@ -0,0 +1,62 @@
+package ir
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMilliseconds(t *testing.T) {
+	if got := Milliseconds(1500 * time.Microsecond); got != 1.5 {
+		t.Errorf("Milliseconds(1.5ms) = %v", got)
+	}
+	if got := Milliseconds(250 * time.Microsecond); got != 0.25 {
+		t.Errorf("expected sub-millisecond durations to be kept, got %v", got)
+	}
+}
+
+func TestLoggingTransportCaptureTimings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	writer := &MemoryWriter{}
+	opts := DefaultLoggingOptions()
+	opts.CaptureTimings = true
+	client := &http.Client{Transport: NewLoggingTransport(writer, WithLoggingOptions(opts))}
+
+	for range 2 {
+		resp, err := client.Get(server.URL + "/items")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	first := writer.Records[0]
+	if first.DurationMs == nil || *first.DurationMs <= 0 {
+		t.Errorf("expected a sub-millisecond duration to be non-zero, got %v", first.DurationMs)
+	}
+	if first.Timings == nil || first.Timings.ConnectMs == nil || first.Timings.TtfbMs == nil {
+		t.Fatalf("expected connect and TTFB timings, got %+v", first.Timings)
+	}
+	if first.Timings.TlsMs != nil {
+		t.Error("expected no TLS timing for plain HTTP")
+	}
+	if reused := writer.Records[1].Timings; reused == nil || reused.ConnectMs != nil || reused.TtfbMs == nil {
+		t.Errorf("expected only TTFB on a reused connection, got %+v", reused)
+	}
+
+	writer = &MemoryWriter{}
+	client = &http.Client{Transport: NewLoggingTransport(writer)}
+	resp, err := client.Get(server.URL + "/items")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if writer.Records[0].Timings != nil {
+		t.Error("expected no timings unless CaptureTimings is set")
+	}
+}
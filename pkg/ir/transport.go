@@ -2,12 +2,21 @@ package ir
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"io"
+	"maps"
 	"math/rand"
 	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"slices"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -23,9 +32,14 @@ type LoggingTransport struct {
 	// Options configures logging behavior.
 	Options LoggingOptions
 
-	// ErrorHandler is called when writing an IR record fails.
+	// ErrorHandler is called when writing an IR record fails, or once when
+	// the host and path patterns in Options are invalid.
 	// If nil, write errors are silently ignored (HTTP request still succeeds).
 	ErrorHandler ErrorHandler
+
+	matcherOnce sync.Once
+	matcher     *RequestMatcher
+	matcherErr  error
 }
 
 // LoggingOptions configures the LoggingTransport behavior.
@@ -43,26 +57,76 @@ type LoggingOptions struct {
 	// MaxBodySize limits body capture size. 0 means no limit.
 	MaxBodySize int64
 
+	// MaxHeaderValueSize limits the size in bytes of each captured header
+	// value, so huge cookies or tracing baggage don't bloat records. Longer
+	// values are cut and their headers listed in record metadata
+	// (MetaRequestTruncatedHeaders, MetaResponseTruncatedHeaders).
+	// 0 means no limit.
+	MaxHeaderValueSize int
+
+	// MaxHeaders limits the number of headers captured per request and per
+	// response. Headers beyond the limit, in name order, are dropped and
+	// listed in record metadata (MetaRequestDroppedHeaders,
+	// MetaResponseDroppedHeaders). 0 means no limit.
+	MaxHeaders int
+
+	// HashBodies stores a content hash and structure fingerprint of each
+	// captured body in record metadata (MetaRequestBodyHash,
+	// MetaRequestBodyShape, and the response equivalents) instead of the
+	// body, for environments where payloads must not be stored. Path, query,
+	// and header inference is unaffected; body schemas are not inferred.
+	HashBodies bool
+
+	// CaptureTimings records the DNS, connect, TLS, and time-to-first-byte
+	// phases of each request in IRRecord.Timings. Phases that did not occur,
+	// as on a reused connection, are left out.
+	CaptureTimings bool
+
+	// IncludeBinaryBodies captures binary bodies (images, PDFs,
+	// octet-stream) base64-encoded. By default they are left out.
+	IncludeBinaryBodies bool
+
 	// Source is the source identifier for IR records.
 	Source IRRecordSource
 
 	// --- Request Filtering ---
 
-	// SkipPaths are path prefixes to skip logging (e.g., "/health", "/metrics").
-	// If a request path starts with any of these prefixes, it won't be logged.
+	// SkipPaths are path patterns to skip logging (e.g., "/health", "/metrics").
+	// Literal patterns match path prefixes; wildcard ("*/health") and "re:"
+	// regular expression patterns are also supported, and a pattern can be
+	// scoped to methods, as in "GET /metrics" (see PatternMatcher).
 	SkipPaths []string
 
 	// AllowMethods limits logging to specific HTTP methods (e.g., "GET", "POST").
 	// If empty, all methods are logged.
 	AllowMethods []string
 
-	// AllowHosts limits logging to specific hosts.
+	// AllowHosts limits logging to hosts matching these patterns: host
+	// names, wildcards ("*.example.com"), "re:" regular expressions, and
+	// CIDR blocks or IP addresses (see PatternMatcher).
 	// If empty, all hosts are logged.
 	AllowHosts []string
 
+	// SkipHosts are host patterns to skip logging, with the same syntax as
+	// AllowHosts. They take precedence over AllowHosts.
+	SkipHosts []string
+
 	// SkipStatusCodes are status codes to skip logging (e.g., 404, 500).
 	SkipStatusCodes []int
 
+	// CaptureOnlyStatusCodes limits captured records to responses with these
+	// status codes. With CaptureOnErrors, responses matching either are
+	// captured. Records are buffered until the response status is known.
+	CaptureOnlyStatusCodes []int
+
+	// CaptureOnErrors limits captured records to 4xx and 5xx responses.
+	CaptureOnErrors bool
+
+	// HeadersOnlyOtherwise keeps responses that don't match
+	// CaptureOnlyStatusCodes or CaptureOnErrors as records without request
+	// and response bodies, instead of dropping them.
+	HeadersOnlyOtherwise bool
+
 	// SampleRate is the percentage of requests to log (0.0 to 1.0).
 	// Values > 0.0 and < 1.0 enable probabilistic sampling (e.g., 0.5 = 50%).
 	// Values <= 0.0 or >= 1.0 log all requests.
@@ -70,6 +134,14 @@ type LoggingOptions struct {
 	// making it safe to use partial LoggingOptions without setting SampleRate.
 	SampleRate float64
 
+	// SampleSeed makes sampling deterministic: when set, a request with an
+	// ID from RequestIDHeaders is sampled if the hash of the seed and its ID
+	// falls below SampleRate (see SampleRequestID). Replicas, and other
+	// services sharing the seed and rate, then capture the same request IDs,
+	// so their records can be joined later. Requests without an ID are
+	// sampled randomly.
+	SampleSeed string
+
 	// --- Context Support ---
 
 	// RequestIDHeaders are headers to check for request ID (in order of priority).
@@ -77,6 +149,16 @@ type LoggingOptions struct {
 	// If empty or no header found, a UUID is generated.
 	// Common headers: "X-Request-ID", "X-Correlation-ID", "X-Trace-ID"
 	RequestIDHeaders []string
+
+	// Metadata is copied into the metadata of every record, e.g. to tag
+	// captures with MetaEnvironment or MetaCaptureHost.
+	Metadata map[string]any
+}
+
+// Validate checks that the host and path patterns compile.
+func (o LoggingOptions) Validate() error {
+	_, err := NewRequestMatcher(o.AllowHosts, o.SkipHosts, o.SkipPaths)
+	return err
 }
 
 // DefaultLoggingOptions returns sensible defaults for logging.
@@ -92,6 +174,8 @@ func DefaultLoggingOptions() LoggingOptions {
 		IncludeRequestBody:  true,
 		IncludeResponseBody: true,
 		MaxBodySize:         1 << 20, // 1MB
+		MaxHeaderValueSize:  8 << 10, // 8KB
+		MaxHeaders:          100,
 		Source:              IRRecordSourceProxy,
 		SampleRate:          1.0, // Log all requests by default
 	}
@@ -153,6 +237,22 @@ func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		req.Body = io.NopCloser(bytes.NewReader(reqBody))
 	}
 
+	// Record informational responses, such as 103 Early Hints
+	var informational []InformationalResponse
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			headers, values := t.filterHeaders(http.Header(header))
+			informational = append(informational, InformationalResponse{Status: code, Headers: headers, HeaderValues: values})
+			return nil
+		},
+	}
+	var timer *phaseTimer
+	if t.Options.CaptureTimings {
+		timer = newPhaseTimer(startTime)
+		timer.hook(trace)
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
 	// Execute actual request
 	resp, err := t.Base.RoundTrip(req)
 	if err != nil {
@@ -164,10 +264,20 @@ func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		return resp, nil
 	}
 
+	// Keep bodies only for responses matching the capture status filters
+	withBodies := t.captureStatusMatches(resp.StatusCode)
+	if !withBodies {
+		if !t.Options.HeadersOnlyOtherwise {
+			return resp, nil
+		}
+		irReq.Body = nil
+	}
+
 	duration := time.Since(startTime)
 
 	// Capture response
-	irResp, respBody := t.captureResponse(resp)
+	irResp, respBody := t.captureResponse(resp, withBodies)
+	irResp.Informational = informational
 
 	// Restore response body
 	if respBody != nil {
@@ -179,6 +289,13 @@ func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 
 	// Build and write IR record
 	record := t.buildRecord(irReq, irResp, startTime, duration, requestID)
+	t.limitHeaders(record)
+	if timer != nil {
+		record.Timings = timer.result()
+	}
+	if t.Options.HashBodies && withBodies {
+		hashBodies(record, reqBody, respBody, req.Header.Get("Content-Type"), resp.Header.Get("Content-Type"))
+	}
 	if err := t.Writer.Write(record); err != nil && t.ErrorHandler != nil {
 		t.ErrorHandler(err)
 	}
@@ -193,20 +310,11 @@ func (t *LoggingTransport) shouldLogRequest(req *http.Request) bool {
 	// SampleRate between 0.0 and 1.0 enables probabilistic sampling.
 	// SampleRate >= 1.0 logs all requests.
 	if t.Options.SampleRate > 0.0 && t.Options.SampleRate < 1.0 {
-		if rand.Float64() > t.Options.SampleRate { //nolint:gosec // G404: sampling doesn't need crypto rand
+		if !t.sampled(req) {
 			return false
 		}
 	}
 
-	// Check path filters
-	if len(t.Options.SkipPaths) > 0 {
-		for _, prefix := range t.Options.SkipPaths {
-			if strings.HasPrefix(req.URL.Path, prefix) {
-				return false
-			}
-		}
-	}
-
 	// Check method filters
 	if len(t.Options.AllowMethods) > 0 {
 		allowed := false
@@ -221,27 +329,93 @@ func (t *LoggingTransport) shouldLogRequest(req *http.Request) bool {
 		}
 	}
 
-	// Check host filters
-	if len(t.Options.AllowHosts) > 0 {
-		host := req.URL.Host
-		if host == "" {
-			host = req.Host
-		}
-		allowed := false
-		for _, h := range t.Options.AllowHosts {
-			if strings.EqualFold(host, h) {
-				allowed = true
-				break
-			}
-		}
-		if !allowed {
-			return false
-		}
+	// Check host and path filters
+	host := req.URL.Host
+	if host == "" {
+		host = req.Host
+	}
+	matcher, err := t.requestMatcher()
+	if err != nil {
+		return false
+	}
+	if !matcher.Allow(req.Method, host, req.URL.Path) {
+		return false
 	}
 
 	return true
 }
 
+// sampled makes the sampling decision for a request, by its request ID if
+// SampleSeed is set.
+func (t *LoggingTransport) sampled(req *http.Request) bool {
+	if t.Options.SampleSeed != "" {
+		if id := t.extractRequestID(req); id != "" {
+			return SampleRequestID(t.Options.SampleSeed, id, t.Options.SampleRate)
+		}
+	}
+	return rand.Float64() <= t.Options.SampleRate //nolint:gosec // G404: sampling doesn't need crypto rand
+}
+
+// SampleRequestID reports whether a request ID is in the sample of the given
+// rate (0.0 to 1.0): whether the first 8 bytes of the SHA-256 hash of the
+// seed and ID, as a fraction of 2^64, are below rate. The decision depends
+// only on its arguments, so every process using the same seed and rate
+// samples the same request IDs, and the IDs sampled at a rate are also
+// sampled at any higher rate.
+func SampleRequestID(seed, requestID string, rate float64) bool {
+	sum := sha256.Sum256([]byte(seed + "\x00" + requestID))
+	return float64(binary.BigEndian.Uint64(sum[:8]))/(1<<64) < rate
+}
+
+// hashBodies replaces the bodies of a record with their content hashes and
+// structure fingerprints in metadata.
+func hashBodies(record *IRRecord, reqBody, respBody []byte, reqContentType, respContentType string) {
+	if len(reqBody) > 0 {
+		record.SetMeta(MetaRequestBodyHash, ContentHash(reqBody))
+		record.SetMeta(MetaRequestBodyShape, bodyShape(record.Request.Body, reqBody, reqContentType))
+		record.Request.Body = nil
+	}
+	if len(respBody) > 0 {
+		record.SetMeta(MetaResponseBodyHash, ContentHash(respBody))
+		record.SetMeta(MetaResponseBodyShape, bodyShape(record.Response.Body, respBody, respContentType))
+		record.Response.Body = nil
+	}
+}
+
+// bodyShape returns the structure fingerprint of a parsed body, or "binary"
+// for binary data.
+func bodyShape(body any, data []byte, contentType string) string {
+	if IsBinaryContentType(contentType) || IsBinaryData(data) {
+		return "binary"
+	}
+	return StructureFingerprint(body)
+}
+
+// captureStatusMatches reports whether a response status matches the
+// CaptureOnlyStatusCodes and CaptureOnErrors filters. Without them every
+// status matches.
+func (t *LoggingTransport) captureStatusMatches(status int) bool {
+	if len(t.Options.CaptureOnlyStatusCodes) == 0 && !t.Options.CaptureOnErrors {
+		return true
+	}
+	if t.Options.CaptureOnErrors && status >= 400 {
+		return true
+	}
+	return slices.Contains(t.Options.CaptureOnlyStatusCodes, status)
+}
+
+// requestMatcher compiles the host and path patterns of Options on first
+// use. Invalid patterns disable logging, and are reported to ErrorHandler.
+func (t *LoggingTransport) requestMatcher() (*RequestMatcher, error) {
+	t.matcherOnce.Do(func() {
+		t.matcher, t.matcherErr = NewRequestMatcher(t.Options.AllowHosts, t.Options.SkipHosts, t.Options.SkipPaths)
+		if t.matcherErr != nil && t.ErrorHandler != nil {
+			t.ErrorHandler(t.matcherErr)
+		}
+	})
+	return t.matcher, t.matcherErr
+}
+
 // shouldLogResponse checks if a response should be logged based on filters.
 func (t *LoggingTransport) shouldLogResponse(resp *http.Response) bool {
 	// Check status code filters
@@ -287,17 +461,19 @@ func (t *LoggingTransport) captureRequest(req *http.Request) (Request, []byte) {
 			if len(v) == 1 {
 				query[k] = v[0]
 			} else {
-				query[k] = v
+				// Store repeated keys as a list so they match decoded JSON records
+				list := make([]interface{}, len(v))
+				for i, s := range v {
+					list[i] = s
+				}
+				query[k] = list
 			}
 		}
 		irReq.Query = query
 	}
 
 	// Headers
-	headers := t.filterHeaders(req.Header)
-	if len(headers) > 0 {
-		irReq.Headers = headers
-	}
+	irReq.Headers, irReq.HeaderValues = t.filterHeaders(req.Header)
 
 	// Content-Type
 	if ct := req.Header.Get("Content-Type"); ct != "" {
@@ -312,20 +488,18 @@ func (t *LoggingTransport) captureRequest(req *http.Request) (Request, []byte) {
 			irReq.Body = t.parseBody(bodyBytes, req.Header.Get("Content-Type"))
 		}
 	}
+	irReq.Trailers, _ = t.filterHeaders(req.Trailer)
 
 	return irReq, bodyBytes
 }
 
-func (t *LoggingTransport) captureResponse(resp *http.Response) (Response, []byte) {
+func (t *LoggingTransport) captureResponse(resp *http.Response, includeBody bool) (Response, []byte) {
 	irResp := Response{
 		Status: resp.StatusCode,
 	}
 
 	// Headers
-	headers := t.filterHeaders(resp.Header)
-	if len(headers) > 0 {
-		irResp.Headers = headers
-	}
+	irResp.Headers, irResp.HeaderValues = t.filterHeaders(resp.Header)
 
 	// Content-Type
 	if ct := resp.Header.Get("Content-Type"); ct != "" {
@@ -334,12 +508,14 @@ func (t *LoggingTransport) captureResponse(resp *http.Response) (Response, []byt
 
 	// Response body
 	var bodyBytes []byte
-	if t.Options.IncludeResponseBody && resp.Body != nil {
+	if includeBody && t.Options.IncludeResponseBody && resp.Body != nil {
 		bodyBytes = t.readBody(resp.Body, t.Options.MaxBodySize)
 		if len(bodyBytes) > 0 {
 			irResp.Body = t.parseBody(bodyBytes, resp.Header.Get("Content-Type"))
 		}
 	}
+	// Trailer values are only known once the body has been read to the end
+	irResp.Trailers, _ = t.filterHeaders(resp.Trailer)
 
 	return irResp, bodyBytes
 }
@@ -363,7 +539,7 @@ func (t *LoggingTransport) buildRecord(req Request, resp Response, startTime tim
 		id = uuid.New().String()
 	}
 	ts := startTime.UTC()
-	durationMs := float64(duration.Milliseconds())
+	durationMs := Milliseconds(duration)
 	source := t.Options.Source
 
 	return &IRRecord{
@@ -373,12 +549,15 @@ func (t *LoggingTransport) buildRecord(req Request, resp Response, startTime tim
 		Request:    req,
 		Response:   resp,
 		DurationMs: &durationMs,
+		Metadata:   maps.Clone(t.Options.Metadata),
 	}
 }
 
-func (t *LoggingTransport) filterHeaders(h http.Header) map[string]string {
+// filterHeaders returns the headers not excluded by FilterHeaders, split into
+// first values and the values of repeated headers.
+func (t *LoggingTransport) filterHeaders(h http.Header) (map[string]string, map[string][]string) {
 	if h == nil {
-		return nil
+		return nil, nil
 	}
 
 	filterSet := make(map[string]bool)
@@ -386,18 +565,73 @@ func (t *LoggingTransport) filterHeaders(h http.Header) map[string]string {
 		filterSet[strings.ToLower(f)] = true
 	}
 
-	result := make(map[string]string)
+	kept := make(map[string][]string, len(h))
 	for k, v := range h {
-		key := strings.ToLower(k)
-		if !filterSet[key] && len(v) > 0 {
-			result[key] = v[0]
+		if !filterSet[strings.ToLower(k)] {
+			kept[k] = v
 		}
 	}
+	return SplitHeaderValues(kept)
+}
 
-	if len(result) == 0 {
-		return nil
+// limitHeaders applies MaxHeaders and MaxHeaderValueSize to a record's
+// request and response headers, listing affected headers in its metadata.
+func (t *LoggingTransport) limitHeaders(record *IRRecord) {
+	limit := func(headers map[string]string, values map[string][]string, droppedKey, truncatedKey string) {
+		dropped, truncated := limitHeaderMaps(headers, values, t.Options.MaxHeaders, t.Options.MaxHeaderValueSize)
+		if len(dropped) > 0 {
+			record.SetMeta(droppedKey, dropped)
+		}
+		if len(truncated) > 0 {
+			record.SetMeta(truncatedKey, truncated)
+		}
+	}
+	limit(record.Request.Headers, record.Request.HeaderValues, MetaRequestDroppedHeaders, MetaRequestTruncatedHeaders)
+	limit(record.Response.Headers, record.Response.HeaderValues, MetaResponseDroppedHeaders, MetaResponseTruncatedHeaders)
+}
+
+// limitHeaderMaps drops headers beyond maxHeaders, in name order, and cuts
+// values longer than maxValueSize, in place. It returns the names of the
+// dropped and truncated headers.
+func limitHeaderMaps(headers map[string]string, values map[string][]string, maxHeaders, maxValueSize int) (dropped, truncated []string) {
+	names := slices.Sorted(maps.Keys(headers))
+
+	if maxHeaders > 0 && len(names) > maxHeaders {
+		dropped = names[maxHeaders:]
+		names = names[:maxHeaders]
+		for _, name := range dropped {
+			delete(headers, name)
+			delete(values, name)
+		}
+	}
+
+	if maxValueSize > 0 {
+		for _, name := range names {
+			cut := false
+			if v := headers[name]; len(v) > maxValueSize {
+				headers[name] = truncateUTF8(v, maxValueSize)
+				cut = true
+			}
+			for i, v := range values[name] {
+				if len(v) > maxValueSize {
+					values[name][i] = truncateUTF8(v, maxValueSize)
+					cut = true
+				}
+			}
+			if cut {
+				truncated = append(truncated, name)
+			}
+		}
+	}
+	return dropped, truncated
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncateUTF8(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
 	}
-	return result
+	return s[:n]
 }
 
 func (t *LoggingTransport) readBody(body io.ReadCloser, maxSize int64) []byte {
@@ -423,6 +657,13 @@ func (t *LoggingTransport) parseBody(data []byte, contentType string) interface{
 		return nil
 	}
 
+	if IsBinaryContentType(contentType) || IsBinaryData(data) {
+		if !t.Options.IncludeBinaryBodies {
+			return nil
+		}
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
 	// Try to parse as JSON
 	if strings.Contains(contentType, "application/json") || strings.Contains(contentType, "+json") {
 		var v interface{}
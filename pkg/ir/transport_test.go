@@ -7,9 +7,11 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
+	"unicode/utf8"
 )
 
 // MemoryWriter collects IR records in memory for testing.
@@ -179,6 +181,131 @@ func TestLoggingTransportHeaderFiltering(t *testing.T) {
 	}
 }
 
+func TestLoggingTransportMultiValueHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Link", "</items?page=2>; rel=\"next\"")
+		w.Header().Add("Link", "</items?page=9>; rel=\"last\"")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	writer := &MemoryWriter{}
+	client := &http.Client{Transport: NewLoggingTransport(writer)}
+
+	req, _ := http.NewRequest("GET", server.URL+"/items", nil)
+	req.Header.Add("Via", "1.1 proxy-a")
+	req.Header.Add("Via", "1.1 proxy-b")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	record := writer.Records[0]
+	if record.Request.Headers["via"] != "1.1 proxy-a" {
+		t.Errorf("expected first via value in headers, got %q", record.Request.Headers["via"])
+	}
+	if via := record.Request.HeaderValuesOf("Via"); len(via) != 2 || via[1] != "1.1 proxy-b" {
+		t.Errorf("expected both via values, got %v", via)
+	}
+	if _, ok := record.Request.HeaderValues["accept"]; ok {
+		t.Error("single-valued headers should not be in header values")
+	}
+	if links := record.Response.HeaderValues["link"]; len(links) != 2 {
+		t.Errorf("expected both link values, got %v", links)
+	}
+}
+
+func TestLoggingTransportHeaderLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Baggage", strings.Repeat("é", 20))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	writer := &MemoryWriter{}
+	opts := DefaultLoggingOptions()
+	opts.FilterHeaders = []string{}
+	opts.MaxHeaderValueSize = 15
+	opts.MaxHeaders = 2
+	client := &http.Client{Transport: NewLoggingTransport(writer, WithLoggingOptions(opts))}
+
+	req, _ := http.NewRequest("GET", server.URL+"/items", nil)
+	req.Header.Set("A-First", "short")
+	req.Header.Add("B-Second", strings.Repeat("x", 40))
+	req.Header.Add("B-Second", "ok")
+	req.Header.Set("C-Third", "dropped")
+	req.Header.Set("User-Agent", "test")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	record := writer.Records[0]
+	if len(record.Request.Headers) != 2 || record.Request.Headers["a-first"] != "short" {
+		t.Errorf("expected the first two headers by name, got %v", record.Request.Headers)
+	}
+	if v := record.Request.Headers["b-second"]; v != strings.Repeat("x", 15) {
+		t.Errorf("expected value cut to 15 bytes, got %q", v)
+	}
+	if vals := record.Request.HeaderValues["b-second"]; len(vals) != 2 || len(vals[0]) != 15 || vals[1] != "ok" {
+		t.Errorf("expected repeated values to be cut too, got %v", vals)
+	}
+	if dropped, _ := record.Metadata[MetaRequestDroppedHeaders].([]string); len(dropped) != 2 || dropped[0] != "c-third" {
+		t.Errorf("expected dropped headers in metadata, got %v", record.Metadata[MetaRequestDroppedHeaders])
+	}
+	if truncated, _ := record.Metadata[MetaRequestTruncatedHeaders].([]string); len(truncated) != 1 || truncated[0] != "b-second" {
+		t.Errorf("expected truncated headers in metadata, got %v", record.Metadata[MetaRequestTruncatedHeaders])
+	}
+
+	baggage := record.Response.Headers["baggage"]
+	if len(baggage) != 14 || !utf8.ValidString(baggage) {
+		t.Errorf("expected value cut at a character boundary, got %q", baggage)
+	}
+	if _, ok := record.Metadata[MetaResponseTruncatedHeaders]; !ok {
+		t.Error("expected truncated response headers in metadata")
+	}
+}
+
+func TestLoggingTransportEarlyHintsAndTrailers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Link", "</style.css>; rel=preload")
+		w.WriteHeader(http.StatusEarlyHints)
+		w.Header().Del("Link")
+		w.Header().Set("Trailer", "X-Checksum")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+		w.Header().Set("X-Checksum", "abc123")
+	}))
+	defer server.Close()
+
+	writer := &MemoryWriter{}
+	client := &http.Client{Transport: NewLoggingTransport(writer, WithLoggingOptions(DefaultLoggingOptions()))}
+
+	resp, err := client.Get(server.URL + "/page")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" || resp.Trailer.Get("X-Checksum") != "abc123" {
+		t.Errorf("expected body and trailer to reach the client, got %q %v", body, resp.Trailer)
+	}
+
+	record := writer.Records[0]
+	info := record.Response.Informational
+	if len(info) != 1 || info[0].Status != http.StatusEarlyHints || info[0].Headers["link"] != "</style.css>; rel=preload" {
+		t.Errorf("expected a 103 informational response, got %+v", info)
+	}
+	if record.Response.Trailers["x-checksum"] != "abc123" {
+		t.Errorf("expected trailer, got %v", record.Response.Trailers)
+	}
+}
+
 func TestLoggingTransportErrorHandler(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -315,6 +442,54 @@ func TestLoggingTransportSkipPaths(t *testing.T) {
 	}
 }
 
+func TestLoggingTransportHostPatterns(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		allow     []string
+		skip      []string
+		wantCount int
+	}{
+		{"cidr", []string{"127.0.0.0/8"}, nil, 1},
+		{"wildcard", []string{"*.example.com"}, nil, 0},
+		{"regex", []string{`re:^127\.`}, nil, 1},
+		{"skip", nil, []string{"127.0.0.1"}, 0},
+		{"invalid", []string{"re:("}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &MemoryWriter{}
+			opts := DefaultLoggingOptions()
+			opts.AllowHosts = tt.allow
+			opts.SkipHosts = tt.skip
+
+			var handlerErr error
+			transport := NewLoggingTransport(writer, WithLoggingOptions(opts), WithTransportErrorHandler(func(err error) {
+				handlerErr = err
+			}))
+			client := &http.Client{Transport: transport}
+
+			resp, err := client.Get(server.URL + "/api")
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if len(writer.Records) != tt.wantCount {
+				t.Errorf("expected %d records, got %d", tt.wantCount, len(writer.Records))
+			}
+			if (opts.Validate() != nil) != (handlerErr != nil) {
+				t.Errorf("expected invalid patterns to be reported, got %v", handlerErr)
+			}
+		})
+	}
+}
+
 func TestLoggingTransportAllowMethods(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -351,6 +526,116 @@ func TestLoggingTransportAllowMethods(t *testing.T) {
 	}
 }
 
+func TestLoggingTransportCaptureStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte(`{"ok":false}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		codes       []int
+		onErrors    bool
+		headersOnly bool
+		want        map[string]bool // path -> captured with bodies
+	}{
+		{"errors", nil, true, false, map[string]bool{"/fail": true, "/missing": true}},
+		{"codes", []int{502}, false, false, map[string]bool{"/fail": true}},
+		{"headers only otherwise", []int{502}, false, true, map[string]bool{"/fail": true, "/missing": false, "/ok": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &MemoryWriter{}
+			opts := DefaultLoggingOptions()
+			opts.CaptureOnlyStatusCodes = tt.codes
+			opts.CaptureOnErrors = tt.onErrors
+			opts.HeadersOnlyOtherwise = tt.headersOnly
+
+			client := &http.Client{Transport: NewLoggingTransport(writer, WithLoggingOptions(opts))}
+			for _, path := range []string{"/ok", "/missing", "/fail"} {
+				resp, err := client.Post(server.URL+path, "application/json", strings.NewReader(`{"a":1}`))
+				if err != nil {
+					t.Fatalf("request failed: %v", err)
+				}
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if string(body) != `{"ok":false}` {
+					t.Errorf("expected response body to reach the client, got %q", body)
+				}
+			}
+
+			if len(writer.Records) != len(tt.want) {
+				t.Fatalf("expected %d records, got %d", len(tt.want), len(writer.Records))
+			}
+			for _, record := range writer.Records {
+				withBodies, ok := tt.want[record.Request.Path]
+				if !ok {
+					t.Errorf("unexpected record for %s", record.Request.Path)
+					continue
+				}
+				if got := record.Request.Body != nil && record.Response.Body != nil; got != withBodies {
+					t.Errorf("%s: expected bodies %v, got request %v, response %v",
+						record.Request.Path, withBodies, record.Request.Body, record.Response.Body)
+				}
+				if record.Response.Headers == nil {
+					t.Errorf("%s: expected response headers", record.Request.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingTransportHashBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":1,"name":"Alice"}`))
+	}))
+	defer server.Close()
+
+	writer := &MemoryWriter{}
+	opts := DefaultLoggingOptions()
+	opts.HashBodies = true
+
+	client := &http.Client{Transport: NewLoggingTransport(writer, WithLoggingOptions(opts))}
+	resp, err := client.Post(server.URL+"/users", "application/json", strings.NewReader(`{"name":"Alice"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"id":1,"name":"Alice"}` {
+		t.Errorf("expected response body to reach the client, got %q", body)
+	}
+
+	if len(writer.Records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(writer.Records))
+	}
+	record := writer.Records[0]
+	if record.Request.Body != nil || record.Response.Body != nil {
+		t.Errorf("expected bodies to be left out, got %v and %v", record.Request.Body, record.Response.Body)
+	}
+	if got := record.MetaString(MetaRequestBodyHash); got != ContentHash([]byte(`{"name":"Alice"}`)) {
+		t.Errorf("unexpected request body hash %q", got)
+	}
+	if got := record.MetaString(MetaRequestBodyShape); got != "{name:string}" {
+		t.Errorf("unexpected request body shape %q", got)
+	}
+	if got := record.MetaString(MetaResponseBodyShape); got != "{id:number,name:string}" {
+		t.Errorf("unexpected response body shape %q", got)
+	}
+	if record.MetaString(MetaResponseBodyHash) == "" {
+		t.Error("expected a response body hash")
+	}
+}
+
 func TestLoggingTransportSkipStatusCodes(t *testing.T) {
 	statusCode := 200
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -543,4 +828,70 @@ func TestLoggingTransportSampling(t *testing.T) {
 			t.Errorf("expected ~500 records with 50%% sampling, got %d", len(writer.Records))
 		}
 	})
+
+	// Test that seeded sampling picks the same request IDs in every transport
+	t.Run("seeded sampling", func(t *testing.T) {
+		capture := func() []string {
+			writer := &MemoryWriter{}
+			opts := DefaultLoggingOptions()
+			opts.SampleRate = 0.3
+			opts.SampleSeed = "checkout"
+			opts.RequestIDHeaders = []string{"X-Request-ID"}
+
+			transport := NewLoggingTransport(writer, WithLoggingOptions(opts))
+			client := &http.Client{Transport: transport}
+			for i := 0; i < 200; i++ {
+				req, _ := http.NewRequest(http.MethodGet, server.URL+"/test", nil)
+				req.Header.Set("X-Request-ID", fmt.Sprintf("req-%d", i))
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatalf("request failed: %v", err)
+				}
+				resp.Body.Close()
+			}
+
+			ids := make([]string, len(writer.Records))
+			for i, r := range writer.Records {
+				ids[i] = *r.Id
+			}
+			return ids
+		}
+
+		first, second := capture(), capture()
+		if len(first) < 30 || len(first) > 90 {
+			t.Errorf("expected ~60 records with 30%% sampling, got %d", len(first))
+		}
+		if !slices.Equal(first, second) {
+			t.Errorf("expected the same request IDs to be sampled, got %v and %v", first, second)
+		}
+	})
+}
+
+func TestSampleRequestID(t *testing.T) {
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		id := fmt.Sprintf("req-%d", i)
+		low := SampleRequestID("seed", id, 0.1)
+		if low {
+			sampled++
+		}
+		if low && !SampleRequestID("seed", id, 0.5) {
+			t.Fatalf("expected %s sampled at 10%% to also be sampled at 50%%", id)
+		}
+		if low != SampleRequestID("seed", id, 0.1) {
+			t.Fatalf("expected the decision for %s to be deterministic", id)
+		}
+	}
+	if sampled < 800 || sampled > 1200 {
+		t.Errorf("expected ~1000 of 10000 IDs sampled at 10%%, got %d", sampled)
+	}
+
+	differ := false
+	for i := 0; i < 100 && !differ; i++ {
+		id := fmt.Sprintf("req-%d", i)
+		differ = SampleRequestID("a", id, 0.5) != SampleRequestID("b", id, 0.5)
+	}
+	if !differ {
+		t.Error("expected different seeds to sample different IDs")
+	}
 }
@@ -2,12 +2,14 @@ package ir
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // IRWriter is the interface for writing IR records to any destination.
@@ -69,29 +71,57 @@ func WriteNDJSON(w io.Writer, records []IRRecord) error {
 	bw := bufio.NewWriter(w)
 	defer bw.Flush()
 
-	for i, record := range records {
-		data, err := json.Marshal(record)
+	for i := range records {
+		buf, err := encodeLine(&records[i])
 		if err != nil {
 			return fmt.Errorf("record %d: %w", i, err)
 		}
 
-		if _, err := bw.Write(data); err != nil {
+		_, err = bw.Write(buf.Bytes())
+		putLineBuffer(buf)
+		if err != nil {
 			return fmt.Errorf("writing record %d: %w", i, err)
 		}
-
-		if _, err := bw.WriteString("\n"); err != nil {
-			return fmt.Errorf("writing newline: %w", err)
-		}
 	}
 
 	return nil
 }
 
+// lineBufferPool reuses encoding buffers across NDJSON writes.
+var lineBufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// maxPooledBufferSize bounds buffers returned to the pool so one huge
+// record doesn't pin its buffer in memory.
+const maxPooledBufferSize = 1 << 20
+
+// encodeLine encodes a record as a newline-terminated JSON line into a
+// pooled buffer. Callers must release the buffer with putLineBuffer.
+func encodeLine(record *IRRecord) (*bytes.Buffer, error) {
+	buf := lineBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	if err := json.NewEncoder(buf).Encode(record); err != nil {
+		putLineBuffer(buf)
+		return nil, fmt.Errorf("marshaling record: %w", err)
+	}
+	return buf, nil
+}
+
+func putLineBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBufferSize {
+		lineBufferPool.Put(buf)
+	}
+}
+
 // NDJSONWriter provides streaming writes for NDJSON format.
 type NDJSONWriter struct {
-	w      *bufio.Writer
-	closer io.Closer
-	count  int
+	w         *bufio.Writer
+	closer    io.Closer
+	count     int
+	offset    int64
+	index     *Index
+	indexPath string
 }
 
 // NewNDJSONWriter creates a writer for streaming NDJSON output.
@@ -114,21 +144,34 @@ func NewNDJSONFileWriter(path string) (*NDJSONWriter, error) {
 	return w, nil
 }
 
+// NewIndexedNDJSONFileWriter creates a writer for streaming to a file that
+// also writes a sidecar record ID index (see IndexPath) on Close.
+func NewIndexedNDJSONFileWriter(path string) (*NDJSONWriter, error) {
+	w, err := NewNDJSONFileWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	w.index = NewIndex()
+	w.indexPath = IndexPath(path)
+	return w, nil
+}
+
 // Write writes a single record.
 func (w *NDJSONWriter) Write(record *IRRecord) error {
-	data, err := json.Marshal(record)
+	buf, err := encodeLine(record)
 	if err != nil {
-		return fmt.Errorf("marshaling record: %w", err)
+		return err
 	}
+	defer putLineBuffer(buf)
 
-	if _, err := w.w.Write(data); err != nil {
+	if _, err := w.w.Write(buf.Bytes()); err != nil {
 		return fmt.Errorf("writing record: %w", err)
 	}
 
-	if _, err := w.w.WriteString("\n"); err != nil {
-		return fmt.Errorf("writing newline: %w", err)
+	if w.index != nil {
+		w.index.Add(record, w.offset)
 	}
-
+	w.offset += int64(buf.Len())
 	w.count++
 	return nil
 }
@@ -138,13 +181,19 @@ func (w *NDJSONWriter) Flush() error {
 	return w.w.Flush()
 }
 
-// Close flushes and closes the underlying writer if it implements io.Closer.
+// Close flushes and closes the underlying writer if it implements io.Closer,
+// then writes the sidecar index if enabled.
 func (w *NDJSONWriter) Close() error {
 	if err := w.w.Flush(); err != nil {
 		return err
 	}
 	if w.closer != nil {
-		return w.closer.Close()
+		if err := w.closer.Close(); err != nil {
+			return err
+		}
+	}
+	if w.index != nil {
+		return WriteIndexFile(w.indexPath, w.index)
 	}
 	return nil
 }
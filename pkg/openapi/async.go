@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// addAsyncOperations documents long-running operations: the 202 response of
+// the initiating operation links to the polling operation, and both
+// operation descriptions say how they relate.
+func addAsyncOperations(spec *Spec, result *inference.InferenceResult) {
+	for _, async := range result.AsyncOperations {
+		source, monitor := result.Endpoints[async.Source], result.Endpoints[async.Monitor]
+		if source == nil || monitor == nil {
+			continue
+		}
+		op := operationForMethod(spec.Paths[source.PathTemplate], source.Method)
+		monitorOp := operationForMethod(spec.Paths[monitor.PathTemplate], monitor.Method)
+		if op == nil || monitorOp == nil || monitorOp.OperationID == "" {
+			continue
+		}
+		resp, ok := op.Responses["202"]
+		if !ok {
+			continue
+		}
+
+		if resp.Links == nil {
+			resp.Links = make(map[string]Link)
+		}
+		name := capitalize(monitorOp.OperationID)
+		link := resp.Links[name]
+		link.OperationID = monitorOp.OperationID
+		if link.Description == "" {
+			link.Description = fmt.Sprintf("Poll the status of the operation at the URL in the %s header.", async.Header)
+		}
+		resp.Links[name] = link
+		op.Responses["202"] = resp
+
+		op.Description = appendSentence(op.Description, fmt.Sprintf(
+			"Long-running operation: responds 202 Accepted with a status monitor URL in the %s header, polled with `%s` (%s).",
+			async.Header, monitorOp.OperationID, async.Monitor))
+		if op.OperationID != "" {
+			monitorOp.Description = appendSentence(monitorOp.Description, fmt.Sprintf(
+				"Status monitor of the long-running `%s` (%s) operation.", op.OperationID, async.Source))
+		}
+	}
+}
+
+// appendSentence appends a paragraph to a description, unless it already
+// holds it.
+func appendSentence(description, sentence string) string {
+	switch {
+	case description == "":
+		return sentence
+	case strings.Contains(description, sentence):
+		return description
+	default:
+		return description + "\n\n" + sentence
+	}
+}
@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestAsyncOperations(t *testing.T) {
+	result := inference.InferFromRecords([]ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/reports"},
+			Response: ir.Response{Status: 202, Headers: map[string]string{"Operation-Location": "/operations/11111111-1111-4111-8111-111111111111"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/operations/11111111-1111-4111-8111-111111111111"},
+			Response: ir.Response{Status: 200, Body: map[string]any{"status": "running"}},
+		},
+	})
+	spec := GenerateFromInference(result, DefaultGeneratorOptions())
+
+	post := spec.Paths["/reports"].Post
+	poll := spec.Paths["/operations/{operationId}"].Get
+	link, ok := post.Responses["202"].Links[capitalize(poll.OperationID)]
+	if !ok {
+		t.Fatalf("202 links = %v, want a link to %s", post.Responses["202"].Links, poll.OperationID)
+	}
+	if link.OperationID != poll.OperationID || !strings.Contains(link.Description, "Operation-Location") {
+		t.Errorf("link = %+v", link)
+	}
+	if !strings.Contains(post.Description, "Long-running operation") || !strings.Contains(post.Description, poll.OperationID) {
+		t.Errorf("initiating description = %q", post.Description)
+	}
+	if !strings.Contains(poll.Description, post.OperationID) {
+		t.Errorf("monitor description = %q", poll.Description)
+	}
+}
@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// multiStatusSchema models the items of a 207 Multi-Status body as a oneOf
+// of the item schemas observed per item status, each with its status
+// pinned by an enum, in place of the items merged across statuses.
+func (g *Generator) multiStatusSchema(schema *Schema, m *inference.MultiStatusData) {
+	if schema == nil || m == nil || len(m.Items) == 0 {
+		return
+	}
+	array := schema
+	if m.ItemsPath != "" {
+		array = schema.Properties[m.ItemsPath]
+	}
+	if array == nil || array.Items == nil {
+		return // not the inferred item array, e.g. a binary body
+	}
+
+	statuses := make([]int, 0, len(m.Items))
+	for status := range m.Items {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	variants := make([]*Schema, 0, len(statuses))
+	for _, status := range statuses {
+		item := g.convertSchemaNode(inference.BuildSchemaTree(m.Items[status]))
+		item.Description = fmt.Sprintf("Item answered with %d %s.", status, http.StatusText(status))
+		if prop := item.Properties[m.StatusField]; prop != nil {
+			if prop.Type == "string" {
+				prop.Enum = []any{strconv.Itoa(status)}
+			} else {
+				prop.Enum = []any{status}
+			}
+			prop.Examples = nil
+		}
+		variants = append(variants, item)
+	}
+	if len(variants) == 1 {
+		array.Items = variants[0]
+		return
+	}
+	array.Items = &Schema{OneOf: variants}
+}
@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestMultiStatusResponses(t *testing.T) {
+	result := inference.InferFromRecords([]ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodPOST, Path: "/users/batch", Body: []any{map[string]any{"name": "a"}}},
+			Response: ir.Response{Status: 207, Body: []any{
+				map[string]any{"status": 201, "id": "1"},
+				map[string]any{"status": 409, "error": "exists"},
+			}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/tags/bulk"},
+			Response: ir.Response{Status: 207, Body: map[string]any{"items": []any{map[string]any{"code": "200", "id": "1"}}}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/users"},
+			Response: ir.Response{Status: 200},
+		},
+	})
+	spec := GenerateFromInference(result, DefaultGeneratorOptions())
+
+	batch := spec.Paths["/users/batch"].Post
+	if batch.Extensions["x-bulk"] != true {
+		t.Errorf("x-bulk = %v, want true", batch.Extensions["x-bulk"])
+	}
+	if _, ok := spec.Paths["/users"].Get.Extensions["x-bulk"]; ok {
+		t.Error("x-bulk set on GET /users")
+	}
+
+	items := batch.Responses["207"].Content["application/json"].Schema.Items
+	if items == nil || len(items.OneOf) != 2 {
+		t.Fatalf("207 items = %+v, want oneOf per item status", items)
+	}
+	created, conflict := items.OneOf[0], items.OneOf[1]
+	if !reflect.DeepEqual(created.Properties["status"].Enum, []any{201}) || created.Properties["error"] != nil {
+		t.Errorf("201 item = %+v", created.Properties)
+	}
+	if !reflect.DeepEqual(conflict.Properties["status"].Enum, []any{409}) || conflict.Properties["error"] == nil {
+		t.Errorf("409 item = %+v", conflict.Properties)
+	}
+
+	envelope := spec.Paths["/tags/bulk"].Post.Responses["207"].Content["application/json"].Schema
+	item := envelope.Properties["items"].Items
+	if item == nil || item.OneOf != nil || !reflect.DeepEqual(item.Properties["code"].Enum, []any{"200"}) {
+		t.Errorf("single-status item = %+v, want the item schema with code enum", item)
+	}
+}
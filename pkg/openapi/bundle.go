@@ -0,0 +1,528 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadOptions configures ReadFileWithOptions and Bundle.
+type ReadOptions struct {
+	// ResolveRefs bundles external $refs, to other files and (with
+	// AllowRemote) URLs, into the spec. Bundle always resolves them.
+	ResolveRefs bool
+
+	// AllowRemote permits the spec and its refs to be http and https URLs.
+	// Without it, only file refs are resolved.
+	AllowRemote bool
+
+	// Client fetches remote documents. If nil, a client with a 30 second
+	// timeout is used.
+	Client *http.Client
+}
+
+// ReadFileWithOptions reads an OpenAPI spec from a file like ReadFile,
+// optionally resolving external $refs into a single in-memory Spec.
+func ReadFileWithOptions(path string, opts ReadOptions) (*Spec, error) {
+	if !opts.ResolveRefs {
+		return ReadFile(path)
+	}
+	doc, err := Bundle(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	var spec Spec
+	if err := doc.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("%w: decoding bundled spec: %w", ErrInvalidSpec, err)
+	}
+	return &spec, nil
+}
+
+// Bundle reads the spec at location, a file path or (with AllowRemote) a
+// URL, and resolves its external $refs so the document stands alone.
+//
+// Refs to reusable components in other documents
+// (other.yaml#/components/schemas/Pet) are copied into the spec's own
+// components, renamed if the name is taken, and referenced locally. Other
+// external refs are replaced by the referenced content, except where that
+// would recurse forever, in which case the content becomes a schema
+// component. Local refs ("#/...") are kept.
+//
+// The document is returned as a YAML node, so key order and fields that
+// Spec does not model are preserved; see WriteDocument.
+func Bundle(location string, opts ReadOptions) (*yaml.Node, error) {
+	b := &bundler{
+		opts:      opts,
+		docs:      make(map[string]*yaml.Node),
+		hoisted:   make(map[string]string),
+		resolving: make(map[string]bool),
+		names:     make(map[string]bool),
+	}
+	root, err := b.absLocation(location, "")
+	if err != nil {
+		return nil, err
+	}
+	doc, err := b.load(root)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: %s is not a YAML or JSON object", ErrInvalidSpec, location)
+	}
+	b.root = root
+	b.doc = copyNode(doc)
+	b.reserveComponentNames()
+
+	if err := b.resolve(b.doc, root); err != nil {
+		return nil, err
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{b.doc}}, nil
+}
+
+// WriteDocument writes a document returned by Bundle as JSON or YAML.
+func WriteDocument(w io.Writer, doc *yaml.Node, format Format) error {
+	if format == FormatJSON {
+		var buf bytes.Buffer
+		if err := writeNodeJSON(&buf, doc); err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		out.WriteByte('\n')
+		_, err := w.Write(out.Bytes())
+		return err
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(blockStyle(doc)); err != nil {
+		return fmt.Errorf("encoding YAML: %w", err)
+	}
+	return encoder.Close()
+}
+
+// bundler resolves the external refs of one root document.
+type bundler struct {
+	opts ReadOptions
+	root string     // absolute location of the root document
+	doc  *yaml.Node // root mapping being bundled
+
+	// docs caches loaded documents by absolute location.
+	docs map[string]*yaml.Node
+
+	// hoisted maps ref keys (location#pointer) to the components they were
+	// copied to, as "section/name".
+	hoisted map[string]string
+
+	// resolving holds the ref keys being inlined, to detect cycles.
+	resolving map[string]bool
+
+	// names holds the "section/name" components in use.
+	names map[string]bool
+}
+
+// resolve replaces the external refs in node, a part of the document at base.
+func (b *bundler) resolve(node *yaml.Node, base string) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := b.resolve(child, base); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		if ref := mappingValue(node, "$ref"); ref != nil && ref.Kind == yaml.ScalarNode {
+			return b.resolveRef(node, ref.Value, base)
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := b.resolve(node.Content[i], base); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveRef resolves the $ref in the mapping node, found in the document at base.
+func (b *bundler) resolveRef(node *yaml.Node, ref, base string) error {
+	docRef, pointer, _ := strings.Cut(ref, "#")
+	location := base
+	if docRef != "" {
+		var err error
+		if location, err = b.absLocation(docRef, base); err != nil {
+			return fmt.Errorf("resolving $ref %q: %w", ref, err)
+		}
+	}
+	if location == b.root {
+		setMappingValue(node, "$ref", "#"+pointer)
+		return nil
+	}
+
+	key := location + "#" + pointer
+	if component, ok := b.hoisted[key]; ok {
+		replaceWithRef(node, component)
+		return nil
+	}
+	if b.resolving[key] {
+		// Inlining would recurse forever, so make it a schema component
+		b.hoisted[key] = b.componentName("schemas", refName(location, pointer))
+		replaceWithRef(node, b.hoisted[key])
+		return nil
+	}
+
+	target, err := b.lookup(location, pointer)
+	if err != nil {
+		return fmt.Errorf("resolving $ref %q in %s: %w", ref, base, err)
+	}
+
+	section, name, isComponent := componentPointer(pointer)
+	if isComponent {
+		b.hoisted[key] = b.componentName(section, name)
+	}
+	b.resolving[key] = true
+	err = b.resolve(target, location)
+	delete(b.resolving, key)
+	if err != nil {
+		return err
+	}
+
+	if component, ok := b.hoisted[key]; ok {
+		b.addComponent(component, target)
+		replaceWithRef(node, component)
+		return nil
+	}
+	inline(node, target)
+	return nil
+}
+
+// absLocation resolves a document reference against the location of the
+// document containing it, returning a URL or an absolute file path.
+func (b *bundler) absLocation(ref, base string) (string, error) {
+	if u, err := url.Parse(ref); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return u.String(), b.checkRemote(u.String())
+	}
+	if strings.HasPrefix(base, "http://") || strings.HasPrefix(base, "https://") {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		u, err := baseURL.Parse(ref)
+		if err != nil {
+			return "", err
+		}
+		return u.String(), nil
+	}
+
+	path, err := url.PathUnescape(ref)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) && base != "" {
+		path = filepath.Join(filepath.Dir(base), path)
+	}
+	return filepath.Abs(path)
+}
+
+// checkRemote reports an error if remote documents are not allowed.
+func (b *bundler) checkRemote(location string) error {
+	if !b.opts.AllowRemote {
+		return fmt.Errorf("remote $ref %s not allowed", location)
+	}
+	return nil
+}
+
+// load reads and parses the document at an absolute location, once.
+func (b *bundler) load(location string) (*yaml.Node, error) {
+	if doc, ok := b.docs[location]; ok {
+		return doc, nil
+	}
+
+	var data []byte
+	var err error
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		data, err = b.fetch(location)
+	} else {
+		data, err = os.ReadFile(location)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", location, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: parsing %s: %w", ErrInvalidSpec, location, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("%w: %s is empty", ErrInvalidSpec, location)
+	}
+	b.docs[location] = doc.Content[0]
+	return doc.Content[0], nil
+}
+
+// fetch downloads a remote document.
+func (b *bundler) fetch(location string) ([]byte, error) {
+	client := b.opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// lookup returns a copy of the node a JSON pointer selects in a document.
+func (b *bundler) lookup(location, pointer string) (*yaml.Node, error) {
+	node, err := b.load(location)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range pointerTokens(pointer) {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			next = mappingValue(node, token)
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("%s not found", pointer)
+		}
+		node = next
+	}
+	return copyNode(node), nil
+}
+
+// reserveComponentNames records the components the root already defines.
+func (b *bundler) reserveComponentNames() {
+	components := mappingValue(b.doc, "components")
+	if components == nil || components.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(components.Content); i += 2 {
+		section := components.Content[i+1]
+		if section.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j < len(section.Content); j += 2 {
+			b.names[components.Content[i].Value+"/"+section.Content[j].Value] = true
+		}
+	}
+}
+
+// componentName reserves a unique component name in a section, returning
+// it as "section/name".
+func (b *bundler) componentName(section, name string) string {
+	candidate := section + "/" + name
+	for n := 2; b.names[candidate]; n++ {
+		candidate = section + "/" + name + strconv.Itoa(n)
+	}
+	b.names[candidate] = true
+	return candidate
+}
+
+// addComponent adds a component to the root, given as "section/name".
+func (b *bundler) addComponent(component string, value *yaml.Node) {
+	section, name, _ := strings.Cut(component, "/")
+	components := mappingValue(b.doc, "components")
+	if components == nil {
+		components = &yaml.Node{Kind: yaml.MappingNode}
+		b.doc.Content = append(b.doc.Content, scalarNode("components"), components)
+	}
+	sectionNode := mappingValue(components, section)
+	if sectionNode == nil {
+		sectionNode = &yaml.Node{Kind: yaml.MappingNode}
+		components.Content = append(components.Content, scalarNode(section), sectionNode)
+	}
+	sectionNode.Content = append(sectionNode.Content, scalarNode(name), value)
+}
+
+// componentPointer reports whether a JSON pointer selects a reusable
+// component, as in /components/schemas/Pet.
+func componentPointer(pointer string) (section, name string, ok bool) {
+	tokens := pointerTokens(pointer)
+	if len(tokens) != 3 || tokens[0] != "components" {
+		return "", "", false
+	}
+	return tokens[1], tokens[2], true
+}
+
+// refName derives a component name from a ref's location and pointer.
+func refName(location, pointer string) string {
+	if tokens := pointerTokens(pointer); len(tokens) > 0 {
+		return tokens[len(tokens)-1]
+	}
+	name := location[strings.LastIndexAny(location, `/\`)+1:]
+	if ext := filepath.Ext(name); ext != "" {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// pointerTokens splits and unescapes a JSON pointer fragment.
+func pointerTokens(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return nil
+	}
+	tokens := strings.Split(pointer, "/")
+	for i, token := range tokens {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+// inline replaces a $ref mapping with the referenced content. Keys next to
+// the $ref, such as description, override the referenced object's.
+func inline(node, target *yaml.Node) {
+	siblings := node.Content
+	*node = *target
+	if target.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(siblings); i += 2 {
+		if siblings[i].Value == "$ref" {
+			continue
+		}
+		if existing := mappingValue(node, siblings[i].Value); existing != nil {
+			*existing = *siblings[i+1]
+			continue
+		}
+		node.Content = append(node.Content, siblings[i], siblings[i+1])
+	}
+}
+
+// replaceWithRef sets the $ref of a mapping to a root component.
+func replaceWithRef(node *yaml.Node, component string) {
+	setMappingValue(node, "$ref", "#/components/"+component)
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets a mapping key to a string value.
+func setMappingValue(node *yaml.Node, key, value string) {
+	if existing := mappingValue(node, key); existing != nil {
+		*existing = *scalarNode(value)
+		return
+	}
+	node.Content = append(node.Content, scalarNode(key), scalarNode(value))
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// copyNode deep-copies a node, resolving aliases.
+func copyNode(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return copyNode(node.Alias)
+	}
+	c := *node
+	c.Anchor = ""
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
+
+// blockStyle returns a copy of a node in block style with plain scalars, so
+// documents read from JSON are written as conventional YAML. Scalars that
+// need quoting are still quoted by the encoder.
+func blockStyle(node *yaml.Node) *yaml.Node {
+	c := copyNode(node)
+	var walk func(*yaml.Node)
+	walk = func(n *yaml.Node) {
+		n.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(c)
+	return c
+}
+
+// writeNodeJSON writes a node as compact JSON, keeping mapping key order.
+func writeNodeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeNodeJSON(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeNodeJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeNodeJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeNodeJSON(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	return nil
+}
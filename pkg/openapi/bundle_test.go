@@ -0,0 +1,152 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"openapi.yaml": `openapi: 3.1.0
+info:
+  title: Pets
+  version: "1.0"
+paths:
+  /pets:
+    $ref: paths/pets.yaml
+components:
+  schemas:
+    Tag:
+      type: string
+`,
+		"paths/pets.yaml": `get:
+  x-owner: pets-team
+  responses:
+    "200":
+      description: OK
+      content:
+        application/json:
+          schema:
+            $ref: ../schemas/common.yaml#/components/schemas/Pet
+`,
+		"schemas/common.yaml": `components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        tag:
+          $ref: "#/components/schemas/Tag"
+        tree:
+          $ref: node.json
+        error:
+          $ref: ../openapi.yaml#/components/schemas/Tag
+    Tag:
+      type: object
+`,
+		"schemas/node.json": `{"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "node.json"}}}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec, err := ReadFileWithOptions(filepath.Join(dir, "openapi.yaml"), ReadOptions{ResolveRefs: true})
+	if err != nil {
+		t.Fatalf("ReadFileWithOptions failed: %v", err)
+	}
+
+	get := spec.Paths["/pets"].Get
+	if get == nil {
+		t.Fatal("expected GET /pets inlined from paths/pets.yaml")
+	}
+	if get.Extensions["x-owner"] != "pets-team" {
+		t.Errorf("expected x-owner extension preserved, got %v", get.Extensions)
+	}
+	if ref := get.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/Pet" {
+		t.Errorf("expected Pet hoisted to components, got ref %q", ref)
+	}
+
+	schemas := spec.Components.Schemas
+	pet := schemas["Pet"]
+	if pet == nil {
+		t.Fatalf("expected Pet component, got %v", schemas)
+	}
+	// common.yaml's Tag is renamed to avoid the existing Tag
+	if ref := pet.Properties["tag"].Ref; ref != "#/components/schemas/Tag2" || schemas["Tag2"] == nil {
+		t.Errorf("expected tag ref to renamed Tag2 component, got %q", ref)
+	}
+	if ref := pet.Properties["error"].Ref; ref != "#/components/schemas/Tag" {
+		t.Errorf("expected ref back to root to become local, got %q", ref)
+	}
+	// The recursive node schema becomes a component
+	if ref := pet.Properties["tree"].Ref; ref != "#/components/schemas/node" {
+		t.Errorf("expected recursive schema hoisted as node, got %q", ref)
+	}
+	if node := schemas["node"]; node == nil || node.Properties["children"].Items.Ref != "#/components/schemas/node" {
+		t.Errorf("expected node component referencing itself, got %+v", node)
+	}
+
+	// Without ResolveRefs the external ref is kept as is
+	plain, err := ReadFileWithOptions(filepath.Join(dir, "openapi.yaml"), ReadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Paths["/pets"].Get != nil {
+		t.Error("expected path item ref left unresolved without ResolveRefs")
+	}
+
+	// Bundled JSON keeps document key order
+	doc, err := Bundle(filepath.Join(dir, "openapi.yaml"), ReadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	if err := WriteDocument(&buf, doc, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "{\n  \"openapi\": \"3.1.0\",\n  \"info\"") {
+		t.Errorf("expected key order preserved, got %s", out[:min(len(out), 80)])
+	}
+	if strings.Contains(out, ".yaml") || strings.Contains(out, "node.json") {
+		t.Errorf("expected no external refs in bundle, got %s", out)
+	}
+}
+
+func TestBundleRemote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("components:\n  schemas:\n    User:\n      type: object\n"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	spec := "openapi: 3.1.0\ninfo:\n  title: Users\n  version: \"1.0\"\npaths: {}\ncomponents:\n  schemas:\n    Account:\n      $ref: " + server.URL + "/common.yaml#/components/schemas/User\n"
+	if err := os.WriteFile(path, []byte(spec), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadFileWithOptions(path, ReadOptions{ResolveRefs: true}); err == nil {
+		t.Error("expected error for remote ref without AllowRemote")
+	}
+
+	resolved, err := ReadFileWithOptions(path, ReadOptions{ResolveRefs: true, AllowRemote: true})
+	if err != nil {
+		t.Fatalf("ReadFileWithOptions failed: %v", err)
+	}
+	if ref := resolved.Components.Schemas["Account"].Ref; ref != "#/components/schemas/User" {
+		t.Errorf("expected remote User hoisted, got ref %q", ref)
+	}
+	if resolved.Components.Schemas["User"] == nil {
+		t.Error("expected User component from remote document")
+	}
+}
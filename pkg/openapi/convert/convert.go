@@ -79,11 +79,25 @@ func ToMultipleVersions(spec *openapi.Spec, targets ...TargetVersion) (map[strin
 
 // convertTo30 converts a spec to OpenAPI 3.0.x format.
 func convertTo30(spec *openapi.Spec) {
+	// Drop fields added in 3.1
+	spec.JSONSchemaDialect = ""
+	spec.Webhooks = nil
+	spec.Info.Summary = ""
+	if spec.Info.License != nil {
+		spec.Info.License.Identifier = ""
+	}
+
 	// Convert component schemas
 	if spec.Components != nil {
+		spec.Components.PathItems = nil
 		for _, schema := range spec.Components.Schemas {
 			convertSchemaTo30(schema)
 		}
+		for _, callback := range spec.Components.Callbacks {
+			for _, pathItem := range callbackPathItems(callback) {
+				convertPathItemTo30(pathItem)
+			}
+		}
 	}
 
 	// Convert path schemas
@@ -99,12 +113,23 @@ func convertTo31Plus(spec *openapi.Spec) {
 		for _, schema := range spec.Components.Schemas {
 			convertSchemaTo31Plus(schema)
 		}
+		for _, callback := range spec.Components.Callbacks {
+			for _, pathItem := range callbackPathItems(callback) {
+				convertPathItemTo31Plus(pathItem)
+			}
+		}
+		for _, pathItem := range spec.Components.PathItems {
+			convertPathItemTo31Plus(pathItem)
+		}
 	}
 
-	// Convert path schemas
+	// Convert path and webhook schemas
 	for _, pathItem := range spec.Paths {
 		convertPathItemTo31Plus(pathItem)
 	}
+	for _, pathItem := range spec.Webhooks {
+		convertPathItemTo31Plus(pathItem)
+	}
 }
 
 // convertSchemaTo30 converts a schema to OpenAPI 3.0 format.
@@ -165,6 +190,14 @@ func convertSchemaTo30(schema *openapi.Schema) {
 		schema.Examples = nil
 	}
 
+	// contentMediaType is 3.1+; 3.0 describes binary strings with format: binary
+	if schema.ContentMediaType != "" {
+		if schema.Format == "" {
+			schema.Format = "binary"
+		}
+		schema.ContentMediaType = ""
+	}
+
 	// Recursively convert nested schemas
 	if schema.Items != nil {
 		convertSchemaTo30(schema.Items)
@@ -303,6 +336,12 @@ func convertOperationTo30(op *openapi.Operation) {
 			convertSchemaTo30(h.Schema)
 		}
 	}
+
+	for _, callback := range op.Callbacks {
+		for _, pathItem := range callbackPathItems(callback) {
+			convertPathItemTo30(pathItem)
+		}
+	}
 }
 
 // convertOperationTo31Plus converts an operation's schemas to 3.1+ format.
@@ -331,6 +370,12 @@ func convertOperationTo31Plus(op *openapi.Operation) {
 			convertSchemaTo31Plus(h.Schema)
 		}
 	}
+
+	for _, callback := range op.Callbacks {
+		for _, pathItem := range callbackPathItems(callback) {
+			convertPathItemTo31Plus(pathItem)
+		}
+	}
 }
 
 // deepCopy creates a deep copy of the spec using JSON marshaling.
@@ -343,5 +388,14 @@ func deepCopy(spec *openapi.Spec) (*openapi.Spec, error) {
 	if err := json.Unmarshal(data, &copied); err != nil {
 		return nil, err
 	}
+	copied.PathOrder = append([]string(nil), spec.PathOrder...) // not encoded
 	return &copied, nil
 }
+
+// callbackPathItems returns a callback's path items, or nil for a nil callback.
+func callbackPathItems(callback *openapi.Callback) map[string]*openapi.PathItem {
+	if callback == nil {
+		return nil
+	}
+	return callback.Expressions
+}
@@ -69,6 +69,51 @@ func TestToVersion30(t *testing.T) {
 	}
 }
 
+func TestToVersion30DropsNewerFields(t *testing.T) {
+	callback := &openapi.Callback{Expressions: map[string]*openapi.PathItem{
+		"{$request.body#/url}": {Post: &openapi.Operation{
+			Responses: map[string]openapi.Response{"200": {
+				Description: "OK",
+				Content: map[string]openapi.MediaType{"application/json": {
+					Schema: &openapi.Schema{Type: []string{"string", "null"}},
+				}},
+			}},
+		}},
+	}}
+	spec := &openapi.Spec{
+		OpenAPI:           "3.1.0",
+		JSONSchemaDialect: "https://spec.openapis.org/oas/3.1/dialect/base",
+		Info: openapi.Info{
+			Title:   "Test API",
+			Summary: "Summary",
+			Version: "1.0.0",
+			License: &openapi.License{Name: "MIT", Identifier: "MIT"},
+		},
+		Paths: map[string]*openapi.PathItem{
+			"/subscribe": {Post: &openapi.Operation{
+				Responses: map[string]openapi.Response{"201": {Description: "Created"}},
+				Callbacks: map[string]*openapi.Callback{"event": callback},
+			}},
+		},
+		Webhooks: map[string]*openapi.PathItem{"ping": {Post: &openapi.Operation{}}},
+	}
+
+	converted, err := ToVersion(spec, Version303)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if converted.Webhooks != nil || converted.JSONSchemaDialect != "" || converted.Info.Summary != "" || converted.Info.License.Identifier != "" {
+		t.Errorf("expected 3.1-only fields dropped, got %+v", converted)
+	}
+	schema := converted.Paths["/subscribe"].Post.Callbacks["event"].Expressions["{$request.body#/url}"].Post.Responses["200"].Content["application/json"].Schema
+	if schema.Type != "string" || !schema.Nullable {
+		t.Errorf("expected callback schema converted to nullable string, got %+v", schema)
+	}
+	if spec.Webhooks == nil {
+		t.Error("expected original spec unchanged")
+	}
+}
+
 func TestToVersion31(t *testing.T) {
 	// Create a 3.0-style spec with nullable keyword
 	spec := &openapi.Spec{
@@ -0,0 +1,53 @@
+package openapi
+
+import "github.com/grokify/traffic2openapi/pkg/inference"
+
+// addCORSExtensions records the CORS policy observed for each operation as
+// an x-cors extension, merging the policies of an endpoint across hosts.
+func addCORSExtensions(spec *Spec, result *inference.InferenceResult) {
+	byEndpoint := make(map[string][]*inference.CORSPolicy)
+	var keys []string
+	for _, policy := range result.CORS {
+		if _, ok := byEndpoint[policy.Endpoint]; !ok {
+			keys = append(keys, policy.Endpoint)
+		}
+		byEndpoint[policy.Endpoint] = append(byEndpoint[policy.Endpoint], policy)
+	}
+
+	for _, key := range keys {
+		endpoint := result.Endpoints[key]
+		if endpoint == nil {
+			continue
+		}
+		op := operationForMethod(spec.Paths[endpoint.PathTemplate], endpoint.Method)
+		if op == nil {
+			continue
+		}
+		if op.Extensions == nil {
+			op.Extensions = Extensions{}
+		}
+		op.Extensions["x-cors"] = corsExtension(inference.MergeCORSPolicies(byEndpoint[key]))
+	}
+}
+
+// corsExtension returns the x-cors extension value of a policy.
+func corsExtension(policy *inference.CORSPolicy) map[string]any {
+	ext := make(map[string]any)
+	if len(policy.AllowedOrigins) > 0 {
+		ext["allowedOrigins"] = policy.AllowedOrigins
+	}
+	if len(policy.AllowedMethods) > 0 {
+		ext["allowedMethods"] = policy.AllowedMethods
+	}
+	if len(policy.AllowedHeaders) > 0 {
+		ext["allowedHeaders"] = policy.AllowedHeaders
+	}
+	if len(policy.ExposedHeaders) > 0 {
+		ext["exposedHeaders"] = policy.ExposedHeaders
+	}
+	ext["allowCredentials"] = policy.AllowCredentials
+	if policy.MaxAge > 0 {
+		ext["maxAge"] = policy.MaxAge
+	}
+	return ext
+}
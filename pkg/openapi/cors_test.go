@@ -0,0 +1,44 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestCORSExtension(t *testing.T) {
+	host := "api.example.com"
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Host: &host, Path: "/items"},
+			Response: ir.Response{Status: 200, Headers: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+			}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Host: &host, Path: "/items"},
+			Response: ir.Response{Status: 201},
+		},
+	}
+	spec := GenerateFromInference(inference.InferFromRecords(records), DefaultGeneratorOptions())
+	item := spec.Paths["/items"]
+
+	cors, ok := item.Get.Extensions["x-cors"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected x-cors extension on GET, got %v", item.Get.Extensions)
+	}
+	if origins, _ := cors["allowedOrigins"].([]string); len(origins) != 1 || origins[0] != "https://app.example.com" {
+		t.Errorf("unexpected allowed origins: %v", cors["allowedOrigins"])
+	}
+	if cors["allowCredentials"] != true {
+		t.Errorf("expected credentials to be allowed, got %v", cors["allowCredentials"])
+	}
+	if _, ok := item.Post.Extensions["x-cors"]; ok {
+		t.Error("expected no x-cors extension on POST")
+	}
+	if _, ok := item.Get.Responses["200"].Headers["Access-Control-Allow-Origin"]; ok {
+		t.Error("expected CORS headers to stay out of response headers")
+	}
+}
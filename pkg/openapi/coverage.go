@@ -0,0 +1,210 @@
+package openapi
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// maxCoverageRecordIDs bounds the example record IDs kept per undocumented status.
+const maxCoverageRecordIDs = 5
+
+// CoverageReport compares observed traffic against the operations and
+// responses a spec declares.
+type CoverageReport struct {
+	Operations           []OperationCoverage  `json:"operations"`
+	Covered              int                  `json:"covered"`
+	Total                int                  `json:"total"`
+	UndocumentedStatuses []UndocumentedStatus `json:"undocumentedStatuses,omitempty"`
+	UnmatchedRecords     int                  `json:"unmatchedRecords"` // records matching no spec operation
+}
+
+// OperationCoverage describes the traffic observed for one spec operation.
+type OperationCoverage struct {
+	Method   string      `json:"method"`
+	Path     string      `json:"path"`
+	Requests int         `json:"requests"`
+	Statuses map[int]int `json:"statuses,omitempty"` // status code -> record count
+}
+
+// UndocumentedStatus is a status code observed for an operation that the
+// spec declares no response for, not even by range or default.
+type UndocumentedStatus struct {
+	Method    string   `json:"method"`
+	Path      string   `json:"path"`
+	Status    int      `json:"status"`
+	Count     int      `json:"count"`
+	RecordIDs []string `json:"recordIds,omitempty"` // up to 5 example record IDs
+}
+
+// Percent returns the percentage of operations observed in traffic.
+func (r *CoverageReport) Percent() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Covered) / float64(r.Total) * 100
+}
+
+// Uncovered returns the operations with no observed traffic.
+func (r *CoverageReport) Uncovered() []OperationCoverage {
+	var uncovered []OperationCoverage
+	for _, op := range r.Operations {
+		if op.Requests == 0 {
+			uncovered = append(uncovered, op)
+		}
+	}
+	return uncovered
+}
+
+// Coverage matches records to spec operations by method and path template
+// and reports which operations were exercised and which observed status codes
+// the spec doesn't declare. Record paths are also matched with the base path
+// of the spec's servers removed.
+func Coverage(spec *Spec, records []ir.IRRecord) *CoverageReport {
+	report := &CoverageReport{}
+	if spec == nil {
+		return report
+	}
+
+	trie := inference.NewTemplateTrie()
+	index := make(map[string]int) // "METHOD /path" -> report.Operations index
+	for _, path := range sortedPaths(spec) {
+		trie.Add(path)
+		for _, m := range pathItemOperations(spec.Paths[path]) {
+			index[m.method+" "+path] = len(report.Operations)
+			report.Operations = append(report.Operations, OperationCoverage{Method: m.method, Path: path})
+		}
+	}
+	report.Total = len(report.Operations)
+	basePaths := serverBasePaths(spec)
+
+	undocumented := make(map[string]*UndocumentedStatus)
+	for i := range records {
+		record := &records[i]
+		method := strings.ToUpper(string(record.Request.Method))
+		path, ok := matchSpecPath(trie, record.Request.Path, basePaths)
+		if !ok {
+			report.UnmatchedRecords++
+			continue
+		}
+		opIndex, ok := index[method+" "+path]
+		if !ok {
+			report.UnmatchedRecords++
+			continue
+		}
+
+		cov := &report.Operations[opIndex]
+		cov.Requests++
+		if cov.Statuses == nil {
+			cov.Statuses = make(map[int]int)
+		}
+		status := record.Response.Status
+		cov.Statuses[status]++
+
+		op := operationForMethod(spec.Paths[path], method)
+		if _, declared := responseCode(op.Responses, status); declared {
+			continue
+		}
+		key := method + " " + path + " " + strconv.Itoa(status)
+		entry, ok := undocumented[key]
+		if !ok {
+			entry = &UndocumentedStatus{Method: method, Path: path, Status: status}
+			undocumented[key] = entry
+		}
+		entry.Count++
+		if record.Id != nil && *record.Id != "" && len(entry.RecordIDs) < maxCoverageRecordIDs {
+			entry.RecordIDs = append(entry.RecordIDs, *record.Id)
+		}
+	}
+
+	for _, cov := range report.Operations {
+		if cov.Requests > 0 {
+			report.Covered++
+		}
+	}
+
+	for _, entry := range undocumented {
+		report.UndocumentedStatuses = append(report.UndocumentedStatuses, *entry)
+	}
+	sort.Slice(report.UndocumentedStatuses, func(i, j int) bool {
+		a, b := report.UndocumentedStatuses[i], report.UndocumentedStatuses[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Status < b.Status
+	})
+
+	return report
+}
+
+// AddUndocumentedResponses declares each undocumented status in the report
+// as a response on its operation, returning the number of responses added.
+func AddUndocumentedResponses(spec *Spec, report *CoverageReport) int {
+	if spec == nil || report == nil {
+		return 0
+	}
+	added := 0
+	for _, entry := range report.UndocumentedStatuses {
+		op := operationForMethod(spec.Paths[entry.Path], entry.Method)
+		if op == nil {
+			continue
+		}
+		code := strconv.Itoa(entry.Status)
+		if _, exists := op.Responses[code]; exists {
+			continue
+		}
+		if op.Responses == nil {
+			op.Responses = make(map[string]Response)
+		}
+		op.Responses[code] = Response{Description: ResponseDescription(entry.Status, nil) + " (observed in traffic)"}
+		added++
+	}
+	return added
+}
+
+// matchSpecPath matches a record path against spec path templates, trying
+// the path as-is and then with each server base path removed.
+func matchSpecPath(trie *inference.TemplateTrie, path string, basePaths []string) (string, bool) {
+	if template, _, ok := trie.Match(path); ok {
+		return template, true
+	}
+	for _, base := range basePaths {
+		if rest, found := strings.CutPrefix(path, base); found && (rest == "" || rest[0] == '/' || rest[0] == '?') {
+			if template, _, ok := trie.Match("/" + strings.TrimPrefix(rest, "/")); ok {
+				return template, true
+			}
+		}
+	}
+	return "", false
+}
+
+// serverBasePaths returns the non-root paths of the spec's server URLs.
+func serverBasePaths(spec *Spec) []string {
+	var paths []string
+	for _, server := range spec.Servers {
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			continue
+		}
+		if base := strings.TrimSuffix(u.Path, "/"); base != "" {
+			paths = append(paths, base)
+		}
+	}
+	return paths
+}
+
+func sortedPaths(spec *Spec) []string {
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
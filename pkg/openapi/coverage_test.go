@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestCoverage(t *testing.T) {
+	spec := &Spec{
+		Servers: []Server{{URL: "https://api.example.com/v1"}},
+		Paths: map[string]*PathItem{
+			"/users/{id}": {
+				Get: &Operation{Responses: map[string]Response{
+					"200": {Description: "OK"},
+					"404": {Description: "Not found"},
+				}},
+				Delete: &Operation{Responses: map[string]Response{
+					"204":     {Description: "Deleted"},
+					"default": {Description: "Error"},
+				}},
+			},
+			"/users": {
+				Post: &Operation{Responses: map[string]Response{"2XX": {Description: "Created"}}},
+			},
+		},
+	}
+
+	record := func(id string, method ir.RequestMethod, path string, status int) ir.IRRecord {
+		r := ir.IRRecord{
+			Request:  ir.Request{Method: method, Path: path},
+			Response: ir.Response{Status: status},
+		}
+		if id != "" {
+			r.Id = &id
+		}
+		return r
+	}
+	records := []ir.IRRecord{
+		record("r1", ir.RequestMethodGET, "/v1/users/1", 200),
+		record("r2", ir.RequestMethodGET, "/v1/users/2", 409),
+		record("r3", ir.RequestMethodGET, "/users/3", 409),
+		record("", ir.RequestMethodGET, "/users/4", 409),
+		record("r5", ir.RequestMethodPOST, "/users", 201),
+		record("r6", ir.RequestMethodPOST, "/users", 400),
+		record("r7", ir.RequestMethodGET, "/orders", 200),
+	}
+
+	report := Coverage(spec, records)
+
+	if report.Total != 3 || report.Covered != 2 {
+		t.Errorf("expected 2/3 operations covered, got %d/%d", report.Covered, report.Total)
+	}
+	if uncovered := report.Uncovered(); len(uncovered) != 1 || uncovered[0].Method != "DELETE" {
+		t.Errorf("expected DELETE uncovered, got %+v", uncovered)
+	}
+	if report.UnmatchedRecords != 1 {
+		t.Errorf("expected 1 unmatched record, got %d", report.UnmatchedRecords)
+	}
+
+	if len(report.UndocumentedStatuses) != 2 {
+		t.Fatalf("expected 2 undocumented statuses, got %+v", report.UndocumentedStatuses)
+	}
+	if bad := report.UndocumentedStatuses[0]; bad.Method != "POST" || bad.Status != 400 {
+		t.Errorf("expected POST 400 undocumented (2XX does not cover it), got %+v", bad)
+	}
+	conflict := report.UndocumentedStatuses[1]
+	if conflict.Path != "/users/{id}" || conflict.Status != 409 || conflict.Count != 3 {
+		t.Errorf("unexpected undocumented status: %+v", conflict)
+	}
+	if len(conflict.RecordIDs) != 2 || conflict.RecordIDs[0] != "r2" {
+		t.Errorf("expected example record IDs r2, r3, got %v", conflict.RecordIDs)
+	}
+
+	if added := AddUndocumentedResponses(spec, report); added != 2 {
+		t.Errorf("expected 2 responses added, got %d", added)
+	}
+	if resp, ok := spec.Paths["/users/{id}"].Get.Responses["409"]; !ok || !strings.HasPrefix(resp.Description, "Conflict") {
+		t.Errorf("expected 409 response added, got %+v", spec.Paths["/users/{id}"].Get.Responses)
+	}
+	if again := Coverage(spec, records); len(again.UndocumentedStatuses) != 0 {
+		t.Errorf("expected no undocumented statuses after patching, got %+v", again.UndocumentedStatuses)
+	}
+}
@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// crudName is the operation ID and summary of an endpoint named by its CRUD
+// action, such as listUsers and "List users".
+type crudName struct {
+	operationID string
+	summary     string
+}
+
+// crudNames names the endpoints of a result by their CRUD action. Endpoints
+// without an action, and those whose operation ID would clash with another
+// operation's, keep the path-based name.
+func crudNames(result *inference.InferenceResult) map[*inference.EndpointData]crudName {
+	names := make(map[*inference.EndpointData]crudName)
+	counts := make(map[string]int)
+	for _, endpoint := range result.Endpoints {
+		name, ok := endpointCRUDName(endpoint)
+		if ok && endpoint.OperationID == "" {
+			names[endpoint] = name
+			counts[name.operationID]++
+			continue
+		}
+		id := endpoint.OperationID
+		if id == "" {
+			id = generateOperationID(endpoint.Method, endpoint.PathTemplate)
+		}
+		counts[id]++
+	}
+	for endpoint, name := range names {
+		if counts[name.operationID] > 1 {
+			delete(names, endpoint)
+		}
+	}
+	return names
+}
+
+// endpointCRUDName returns the CRUD name of an endpoint, if it has an action.
+func endpointCRUDName(endpoint *inference.EndpointData) (crudName, bool) {
+	if endpoint.Action == "" {
+		return crudName{}, false
+	}
+	resource := inference.CRUDResource(endpoint.Action, endpoint.PathTemplate)
+	var id strings.Builder
+	id.WriteString(string(endpoint.Action))
+	var words []string
+	for _, word := range resource {
+		for _, part := range strings.FieldsFunc(word, func(r rune) bool { return !isIdentRune(r) }) {
+			id.WriteString(capitalize(part))
+			words = append(words, part)
+		}
+	}
+	if len(words) == 0 {
+		return crudName{}, false
+	}
+	return crudName{
+		operationID: id.String(),
+		summary:     capitalize(string(endpoint.Action)) + " " + strings.Join(words, " "),
+	}, true
+}
+
+// isIdentRune reports whether a rune may appear in an operation ID.
+func isIdentRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
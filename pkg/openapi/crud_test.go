@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestCRUDNames(t *testing.T) {
+	var records []ir.IRRecord
+	for _, r := range []struct {
+		method ir.RequestMethod
+		path   string
+		status int
+	}{
+		{ir.RequestMethodGET, "/users", 200},
+		{ir.RequestMethodPOST, "/users", 201},
+		{ir.RequestMethodGET, "/users/1", 200},
+		{ir.RequestMethodPATCH, "/users/1", 200},
+		{ir.RequestMethodDELETE, "/users/1", 204},
+		{ir.RequestMethodGET, "/users/1/posts", 200},
+		{ir.RequestMethodPOST, "/users/1/activate", 200},
+		{ir.RequestMethodGET, "/v1/orders", 200},
+		{ir.RequestMethodGET, "/v2/orders", 200},
+	} {
+		records = append(records, ir.IRRecord{
+			Request:  ir.Request{Method: r.method, Path: r.path},
+			Response: ir.Response{Status: r.status},
+		})
+	}
+	result := inference.InferFromRecords(records)
+
+	opts := DefaultGeneratorOptions()
+	opts.CRUDNames = true
+	spec := GenerateFromInference(result, opts)
+
+	tests := []struct {
+		op      *Operation
+		id      string
+		summary string
+	}{
+		{spec.Paths["/users"].Get, "listUsers", "List users"},
+		{spec.Paths["/users"].Post, "createUser", "Create user"},
+		{spec.Paths["/users/{userId}"].Get, "getUser", "Get user"},
+		{spec.Paths["/users/{userId}"].Patch, "updateUser", "Update user"},
+		{spec.Paths["/users/{userId}"].Delete, "deleteUser", "Delete user"},
+		{spec.Paths["/users/{userId}/posts"].Get, "listUserPosts", "List user posts"},
+		// Operations without a CRUD action, and clashing names, keep the path-based name
+		{spec.Paths["/users/{userId}/activate"].Post, "postUsersByUserIdActivate", "POST /users/{userId}/activate"},
+		{spec.Paths["/v1/orders"].Get, "getV1Orders", "GET /v1/orders"},
+	}
+	for _, tt := range tests {
+		if tt.op.OperationID != tt.id || tt.op.Summary != tt.summary {
+			t.Errorf("got %q %q, want %q %q", tt.op.OperationID, tt.op.Summary, tt.id, tt.summary)
+		}
+	}
+
+	if id := GenerateFromInference(result, DefaultGeneratorOptions()).Paths["/users"].Get.OperationID; id != "getUsers" {
+		t.Errorf("expected path-based names by default, got %q", id)
+	}
+}
@@ -0,0 +1,212 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// Schema drift types.
+const (
+	DriftTypeMismatch    = "type-mismatch"    // observed value type the schema doesn't allow
+	DriftMissingRequired = "missing-required" // required response field never observed
+)
+
+// DriftReport describes where observed traffic departs from a golden spec.
+type DriftReport struct {
+	Operations            int                  `json:"operations"`                      // spec operations matched by traffic
+	UndocumentedEndpoints []string             `json:"undocumentedEndpoints,omitempty"` // observed "METHOD /path" with no spec operation
+	UndocumentedStatuses  []UndocumentedStatus `json:"undocumentedStatuses,omitempty"`
+	UndocumentedFields    []ObservedField      `json:"undocumentedFields,omitempty"`
+	Breaking              []SchemaDrift        `json:"breaking,omitempty"`
+}
+
+// SchemaDrift is an observed body that contradicts its declared schema.
+type SchemaDrift struct {
+	Kind     string `json:"kind"` // DriftTypeMismatch or DriftMissingRequired
+	Method   string `json:"method"`
+	Path     string `json:"path"`            // spec path template
+	Location string `json:"location"`        // "request" or "response <status>"
+	Field    string `json:"field,omitempty"` // dotted field path; empty for the body itself
+	Declared string `json:"declared,omitempty"`
+	Observed string `json:"observed,omitempty"`
+}
+
+// HasDrift reports whether the report lists any findings.
+func (r *DriftReport) HasDrift() bool {
+	return len(r.UndocumentedEndpoints) > 0 || len(r.UndocumentedStatuses) > 0 ||
+		len(r.UndocumentedFields) > 0 || len(r.Breaking) > 0
+}
+
+// Drift checks traffic against a golden spec without modifying it. result
+// must be inferred from records. Besides the undocumented endpoints, status
+// codes, and body fields that Coverage and Enrich find, it reports breaking
+// drift: observed values whose type the declared schema doesn't allow, and
+// required response fields that no observed response contained.
+func Drift(spec *Spec, result *inference.InferenceResult, records []ir.IRRecord) *DriftReport {
+	report := &DriftReport{}
+	if spec == nil || result == nil {
+		return report
+	}
+
+	enriched := enrich(spec, result, EnrichOptions{}, report)
+	report.Operations = enriched.Operations
+	report.UndocumentedEndpoints = enriched.Undocumented
+	report.UndocumentedFields = enriched.ObservedFields
+	report.UndocumentedStatuses = Coverage(spec, records).UndocumentedStatuses
+
+	return report
+}
+
+// schemaDrift records breaking drift between an observed and a declared
+// body schema when the enricher collects drift.
+func (e *enricher) schemaDrift(op *Operation, path, method, location string, observed, declared *Schema) {
+	if e.drift == nil {
+		return
+	}
+	seen := e.seen[op]
+	if seen == nil {
+		seen = make(map[string]bool)
+		e.seen[op] = seen
+	}
+	// Only responses promise their required fields to clients
+	checkRequired := strings.HasPrefix(location, "response")
+
+	e.walkDrift(observed, declared, "", 0, checkRequired, func(kind, field, declaredType, observedType string) {
+		key := "drift " + kind + " " + location + " " + field
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		e.drift.Breaking = append(e.drift.Breaking, SchemaDrift{
+			Kind:     kind,
+			Method:   method,
+			Path:     path,
+			Location: location,
+			Field:    field,
+			Declared: declaredType,
+			Observed: observedType,
+		})
+	})
+}
+
+// walkDrift calls report for each observed value whose type declared doesn't
+// allow and, if checkRequired is set, each required property never observed,
+// recursing into declared objects and array items. Schemas using oneOf/anyOf
+// are not checked.
+func (e *enricher) walkDrift(observed, declared *Schema, prefix string, depth int, checkRequired bool, report func(kind, field, declaredType, observedType string)) {
+	declared = e.resolveSchema(declared, 0)
+	if observed == nil || declared == nil || depth > maxExampleDepth {
+		return
+	}
+	if len(declared.OneOf) > 0 || len(declared.AnyOf) > 0 {
+		return
+	}
+
+	observedType := schemaType(observed)
+	allowed := e.declaredTypes(declared, 0)
+	if !typeAllowed(allowed, observedType) {
+		report(DriftTypeMismatch, prefix, strings.Join(allowed, "|"), observedType)
+		return
+	}
+
+	if observedType == "array" {
+		e.walkDrift(observed.Items, declared.Items, prefix+"[]", depth+1, checkRequired, report)
+		return
+	}
+	if len(observed.Properties) == 0 {
+		return
+	}
+
+	properties := e.declaredProperties(declared, 0)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := e.declaredRequired(declared, 0)
+	for _, name := range names {
+		field := name
+		if prefix != "" {
+			field = prefix + "." + name
+		}
+		prop, ok := observed.Properties[name]
+		if !ok {
+			if checkRequired && required[name] {
+				report(DriftMissingRequired, field, schemaType(e.resolveSchema(properties[name], 0)), "")
+			}
+			continue
+		}
+		e.walkDrift(prop, properties[name], field, depth+1, checkRequired, report)
+	}
+}
+
+// declaredTypes returns the non-null types a schema allows, including those
+// constrained by allOf members. An empty result allows any type.
+func (e *enricher) declaredTypes(schema *Schema, depth int) []string {
+	schema = e.resolveSchema(schema, 0)
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	var types []string
+	switch t := schema.Type.(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if str, ok := v.(string); ok && str != "null" {
+				types = append(types, str)
+			}
+		}
+	case []string:
+		for _, str := range t {
+			if str != "null" {
+				types = append(types, str)
+			}
+		}
+	}
+	if len(types) == 0 {
+		for _, sub := range schema.AllOf {
+			if types = e.declaredTypes(sub, depth+1); len(types) > 0 {
+				break
+			}
+		}
+	}
+	return types
+}
+
+// declaredRequired returns a schema's required properties, including those
+// of allOf members.
+func (e *enricher) declaredRequired(schema *Schema, depth int) map[string]bool {
+	schema = e.resolveSchema(schema, 0)
+	required := make(map[string]bool)
+	if schema == nil || depth > maxExampleDepth {
+		return required
+	}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	for _, sub := range schema.AllOf {
+		for name := range e.declaredRequired(sub, depth+1) {
+			required[name] = true
+		}
+	}
+	return required
+}
+
+// typeAllowed reports whether an observed type satisfies the declared types.
+// Integers satisfy number.
+func typeAllowed(declared []string, observed string) bool {
+	if len(declared) == 0 || observed == "" {
+		return true
+	}
+	for _, t := range declared {
+		if t == observed || (t == "number" && observed == "integer") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestDrift(t *testing.T) {
+	specYAML := `openapi: 3.1.0
+info:
+  title: Users API
+  version: 1.0.0
+paths:
+  /users/{userId}:
+    get:
+      responses:
+        "200":
+          description: A user
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+components:
+  schemas:
+    User:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+        score:
+          type: number
+        tags:
+          type: array
+          items:
+            type: string
+`
+	spec, err := FromYAML([]byte(specYAML))
+	if err != nil {
+		t.Fatalf("FromYAML failed: %v", err)
+	}
+
+	ct := "application/json"
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users/7"},
+			Response: ir.Response{
+				Status:      200,
+				ContentType: &ct,
+				Body: map[string]any{
+					"id":       "usr_7",
+					"score":    float64(3),
+					"tags":     []any{float64(1)},
+					"nickname": "ada",
+				},
+			},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/users/8"},
+			Response: ir.Response{Status: 500},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/health"},
+			Response: ir.Response{Status: 200},
+		},
+	}
+
+	report := Drift(spec, inference.InferFromRecords(records), records)
+
+	if report.Operations != 1 {
+		t.Errorf("expected 1 matched operation, got %d", report.Operations)
+	}
+	if len(report.UndocumentedEndpoints) != 1 || report.UndocumentedEndpoints[0] != "GET /health" {
+		t.Errorf("expected GET /health undocumented, got %v", report.UndocumentedEndpoints)
+	}
+	if len(report.UndocumentedStatuses) != 1 || report.UndocumentedStatuses[0].Status != 500 {
+		t.Errorf("expected undocumented 500, got %+v", report.UndocumentedStatuses)
+	}
+	if len(report.UndocumentedFields) != 1 || report.UndocumentedFields[0].Field != "nickname" {
+		t.Errorf("expected nickname undocumented, got %+v", report.UndocumentedFields)
+	}
+
+	got := make(map[string]SchemaDrift)
+	for _, d := range report.Breaking {
+		got[d.Kind+" "+d.Field] = d
+	}
+	if len(got) != 3 {
+		t.Errorf("expected 3 breaking findings, got %+v", report.Breaking)
+	}
+	if d, ok := got["type-mismatch id"]; !ok || d.Declared != "integer" || d.Observed != "string" {
+		t.Errorf("expected id type mismatch, got %+v", report.Breaking)
+	}
+	if _, ok := got["type-mismatch tags[]"]; !ok {
+		t.Errorf("expected tags item type mismatch, got %+v", report.Breaking)
+	}
+	if d, ok := got["missing-required name"]; !ok || d.Location != "response 200" {
+		t.Errorf("expected missing required name, got %+v", report.Breaking)
+	}
+	if !report.HasDrift() {
+		t.Error("expected HasDrift")
+	}
+
+	if props := spec.Components.Schemas["User"].Properties; len(props) != 4 {
+		t.Errorf("expected schema to be unchanged, got %d properties", len(props))
+	}
+	if media := spec.Paths["/users/{userId}"].Get.Responses["200"].Content["application/json"]; media.Example != nil {
+		t.Error("expected no examples to be added")
+	}
+}
@@ -0,0 +1,471 @@
+package openapi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// EnrichOptions configures spec enrichment from observed traffic.
+type EnrichOptions struct {
+	// Examples adds observed examples to parameters and media types that have none.
+	Examples bool
+
+	// ResponseHeaders adds observed response headers missing from documented responses.
+	ResponseHeaders bool
+
+	// ObservedFieldExtensions records observed body fields that the spec's
+	// schemas don't declare as x-observed-fields operation extensions.
+	// These fields are always reported in EnrichReport.ObservedFields.
+	ObservedFieldExtensions bool
+
+	// ExampleSelection controls which observed example is used.
+	ExampleSelection ExampleSelection
+}
+
+// DefaultEnrichOptions returns default enrichment options.
+func DefaultEnrichOptions() EnrichOptions {
+	return EnrichOptions{
+		Examples:         true,
+		ResponseHeaders:  true,
+		ExampleSelection: ExampleSelectionFirst,
+	}
+}
+
+// EnrichReport summarizes the changes made by Enrich.
+type EnrichReport struct {
+	Operations     int             // spec operations matched by observed traffic
+	Examples       int             // examples added
+	Headers        int             // response headers added
+	ObservedFields []ObservedField // observed body fields not declared in the spec
+	Undocumented   []string        // observed "METHOD /path" endpoints with no spec operation
+}
+
+// ObservedField is a body field seen in traffic but not declared in the spec.
+type ObservedField struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`     // spec path template
+	Location string `json:"location"` // "request" or "response <status>"
+	Field    string `json:"field"`    // dotted field path; "[]" marks array items
+	Type     string `json:"type,omitempty"`
+}
+
+// Enrich adds examples, response headers, and observed-field annotations
+// from inference results to a hand-written spec without changing its
+// structural contract: no paths, operations, parameters, responses, or schema
+// properties are added or removed, and existing examples are kept.
+//
+// Observed endpoints are matched to spec operations by path template and
+// method. Observed responses are matched by exact status code, then by range
+// (e.g., "2XX"), then "default".
+func Enrich(spec *Spec, result *inference.InferenceResult, options EnrichOptions) *EnrichReport {
+	return enrich(spec, result, options, nil)
+}
+
+// enrich implements Enrich, also collecting schema drift into drift if set.
+func enrich(spec *Spec, result *inference.InferenceResult, options EnrichOptions, drift *DriftReport) *EnrichReport {
+	report := &EnrichReport{}
+	if spec == nil || result == nil {
+		return report
+	}
+
+	e := &enricher{
+		spec:    spec,
+		options: options,
+		report:  report,
+		drift:   drift,
+		// Leaf examples are only kept in 3.1 schemas
+		generator: NewGenerator(GeneratorOptions{Version: Version31, ExampleSelection: options.ExampleSelection}),
+		trie:      inference.NewTemplateTrie(),
+		seen:      make(map[*Operation]map[string]bool),
+	}
+	for _, path := range sortedPaths(spec) {
+		e.trie.Add(path)
+	}
+
+	// Sorted so the first matching endpoint wins consistently
+	keys := make([]string, 0, len(result.Endpoints))
+	for key := range result.Endpoints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	matched := make(map[*Operation]bool)
+	for _, key := range keys {
+		endpoint := result.Endpoints[key]
+		path, params, ok := e.trie.Match(endpoint.PathTemplate)
+		var item *PathItem
+		var op *Operation
+		if ok {
+			item = spec.Paths[path]
+			op = operationForMethod(item, endpoint.Method)
+		}
+		if op == nil {
+			report.Undocumented = append(report.Undocumented, endpoint.Method+" "+endpoint.PathTemplate)
+			continue
+		}
+		matched[op] = true
+		e.enrichOperation(path, item, op, endpoint, params)
+	}
+	report.Operations = len(matched)
+
+	return report
+}
+
+type enricher struct {
+	spec      *Spec
+	options   EnrichOptions
+	report    *EnrichReport
+	drift     *DriftReport
+	generator *Generator
+	trie      *inference.TemplateTrie
+	seen      map[*Operation]map[string]bool // reported observed fields per operation
+}
+
+// operationForMethod returns the path item's operation for an HTTP method.
+func operationForMethod(item *PathItem, method string) *Operation {
+	if item == nil {
+		return nil
+	}
+	for _, m := range pathItemOperations(item) {
+		if m.method == strings.ToUpper(method) {
+			return m.op
+		}
+	}
+	return nil
+}
+
+// enrichOperation applies an observed endpoint to a spec operation.
+// segments maps spec path parameter names to the observed path segments.
+func (e *enricher) enrichOperation(path string, item *PathItem, op *Operation, endpoint *inference.EndpointData, segments map[string]string) {
+	if e.options.Examples {
+		for _, params := range [][]Parameter{item.Parameters, op.Parameters} {
+			for i := range params {
+				if e.parameterExample(&params[i], endpoint, segments) {
+					e.report.Examples++
+				}
+			}
+		}
+	}
+
+	method := strings.ToUpper(endpoint.Method)
+
+	if op.RequestBody != nil && endpoint.RequestBody != nil {
+		observed := e.observedSchema(endpoint.RequestBody.Schema)
+		e.enrichContent(op.RequestBody.Content, endpoint.RequestBody.ContentType, observed)
+		if observed != nil {
+			if _, media, ok := matchingMedia(op.RequestBody.Content, endpoint.RequestBody.ContentType); ok {
+				e.observedFields(op, path, method, "request", observed, media.Schema)
+				e.schemaDrift(op, path, method, "request", observed, media.Schema)
+			}
+		}
+	}
+
+	statuses := make([]int, 0, len(endpoint.Responses))
+	for status := range endpoint.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	for _, status := range statuses {
+		respData := endpoint.Responses[status]
+		code, ok := responseCode(op.Responses, status)
+		if !ok {
+			continue
+		}
+		resp := op.Responses[code]
+
+		if e.options.ResponseHeaders {
+			e.report.Headers += addObservedHeaders(&resp, respData.Headers)
+		}
+
+		if observed := e.observedSchema(respData.Body); observed != nil {
+			e.enrichContent(resp.Content, respData.ContentType, observed)
+			if _, media, ok := matchingMedia(resp.Content, respData.ContentType); ok {
+				e.observedFields(op, path, method, "response "+code, observed, media.Schema)
+				e.schemaDrift(op, path, method, "response "+code, observed, media.Schema)
+			}
+		}
+
+		op.Responses[code] = resp
+	}
+}
+
+// parameterExample sets a parameter's example from observed values, reporting
+// whether one was added.
+func (e *enricher) parameterExample(param *Parameter, endpoint *inference.EndpointData, segments map[string]string) bool {
+	if param.Example != nil || (param.Schema != nil && (param.Schema.Example != nil || len(param.Schema.Examples) > 0)) {
+		return false
+	}
+
+	var observed *inference.ParamData
+	switch param.In {
+	case "path":
+		segment, ok := segments[param.Name]
+		if !ok {
+			return false
+		}
+		name, isParam := templateParamName(segment)
+		if !isParam {
+			// The observed path had a literal value here
+			param.Example = segment
+			return true
+		}
+		observed = endpoint.PathParams[name]
+	case "query":
+		observed = endpoint.QueryParams[param.Name]
+	case "header":
+		for name, data := range endpoint.HeaderParams {
+			if strings.EqualFold(name, param.Name) {
+				observed = data
+				break
+			}
+		}
+	}
+	if observed == nil || len(observed.Examples) == 0 {
+		return false
+	}
+
+	param.Example = bestExample(observed.Examples, e.options.ExampleSelection)
+	return param.Example != nil
+}
+
+// observedSchema converts an observed body to a schema with examples, or nil
+// if no body was observed.
+func (e *enricher) observedSchema(store *inference.SchemaStore) *Schema {
+	if store == nil || len(store.Types) == 0 {
+		return nil
+	}
+	return e.generator.convertSchemaNode(inference.BuildSchemaTree(store))
+}
+
+// enrichContent adds an example built from the observed schema to the media
+// type matching contentType, if it has none.
+func (e *enricher) enrichContent(content map[string]MediaType, contentType string, observed *Schema) {
+	if !e.options.Examples || observed == nil {
+		return
+	}
+	ct, media, ok := matchingMedia(content, contentType)
+	if !ok || media.Example != nil || len(media.Examples) > 0 {
+		return
+	}
+
+	synth := &synthesizer{spec: e.spec}
+	if example := synth.example(observed, 0); example != nil {
+		media.Example = example
+		content[ct] = media
+		e.report.Examples++
+	}
+}
+
+// observedFields reports observed fields the spec schema doesn't declare and
+// records them on the operation when ObservedFieldExtensions is set.
+func (e *enricher) observedFields(op *Operation, path, method, location string, observed, declared *Schema) {
+	var fields []ObservedField
+	e.walkObservedFields(observed, declared, "", 0, func(field, typ string) {
+		fields = append(fields, ObservedField{
+			Method:   method,
+			Path:     path,
+			Location: location,
+			Field:    field,
+			Type:     typ,
+		})
+	})
+
+	seen := e.seen[op]
+	if seen == nil {
+		seen = make(map[string]bool)
+		e.seen[op] = seen
+	}
+	for _, f := range fields {
+		key := f.Location + " " + f.Field
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		e.report.ObservedFields = append(e.report.ObservedFields, f)
+
+		if e.options.ObservedFieldExtensions {
+			if op.Extensions == nil {
+				op.Extensions = Extensions{}
+			}
+			list, _ := op.Extensions["x-observed-fields"].([]map[string]any)
+			entry := map[string]any{"location": f.Location, "field": f.Field}
+			if f.Type != "" {
+				entry["type"] = f.Type
+			}
+			op.Extensions["x-observed-fields"] = append(list, entry)
+		}
+	}
+}
+
+// walkObservedFields calls report for each observed property missing from
+// declared, recursing into declared objects and array items. Schemas that
+// allow additional properties or use oneOf/anyOf are not checked.
+func (e *enricher) walkObservedFields(observed, declared *Schema, prefix string, depth int, report func(field, typ string)) {
+	declared = e.resolveSchema(declared, 0)
+	if observed == nil || declared == nil || depth > maxExampleDepth {
+		return
+	}
+
+	if schemaType(observed) == "array" {
+		if items := e.resolveSchema(declared.Items, 0); items != nil {
+			e.walkObservedFields(observed.Items, items, prefix+"[]", depth+1, report)
+		}
+		return
+	}
+
+	if len(observed.Properties) == 0 || len(declared.OneOf) > 0 || len(declared.AnyOf) > 0 {
+		return
+	}
+	if declared.AdditionalProperties != nil && declared.AdditionalProperties != false {
+		return
+	}
+
+	properties := e.declaredProperties(declared, 0)
+	if len(properties) == 0 {
+		// Free-form object
+		return
+	}
+
+	names := make([]string, 0, len(observed.Properties))
+	for name := range observed.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := name
+		if prefix != "" {
+			field = prefix + "." + name
+		}
+		prop, ok := properties[name]
+		if !ok {
+			report(field, schemaType(observed.Properties[name]))
+			continue
+		}
+		e.walkObservedFields(observed.Properties[name], prop, field, depth+1, report)
+	}
+}
+
+// declaredProperties returns a schema's properties, including those of allOf members.
+func (e *enricher) declaredProperties(schema *Schema, depth int) map[string]*Schema {
+	schema = e.resolveSchema(schema, 0)
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	properties := make(map[string]*Schema, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		properties[name] = prop
+	}
+	for _, sub := range schema.AllOf {
+		for name, prop := range e.declaredProperties(sub, depth+1) {
+			if _, exists := properties[name]; !exists {
+				properties[name] = prop
+			}
+		}
+	}
+	return properties
+}
+
+// resolveSchema follows local component references.
+func (e *enricher) resolveSchema(schema *Schema, depth int) *Schema {
+	synth := &synthesizer{spec: e.spec}
+	for schema != nil && schema.Ref != "" && depth <= maxExampleDepth {
+		schema = synth.resolveRef(schema.Ref)
+		depth++
+	}
+	return schema
+}
+
+// addObservedHeaders adds observed headers missing from a response, returning
+// the number added. Header names are compared case-insensitively.
+func addObservedHeaders(resp *Response, observed map[string]*inference.ParamData) int {
+	names := make([]string, 0, len(observed))
+	for name := range observed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	added := 0
+	for _, name := range names {
+		// Content-Type is described by the response content
+		if strings.EqualFold(name, "content-type") || hasHeader(resp.Headers, name) {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]Header)
+		}
+		typ := observed[name].Type
+		if typ == "" {
+			typ = "string"
+		}
+		description := inference.HeaderDescription(name)
+		if description == "" {
+			description = "Observed in traffic"
+		}
+		resp.Headers[name] = Header{
+			Description: description,
+			Schema:      &Schema{Type: typ},
+		}
+		added++
+	}
+	return added
+}
+
+func hasHeader(headers map[string]Header, name string) bool {
+	for existing := range headers {
+		if strings.EqualFold(existing, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// responseCode finds the documented response for a status: the exact code,
+// the status range (e.g., "2XX"), or "default".
+func responseCode(responses map[string]Response, status int) (string, bool) {
+	code := strconv.Itoa(status)
+	if _, ok := responses[code]; ok {
+		return code, true
+	}
+	for _, key := range []string{code[:1] + "XX", code[:1] + "xx", "default"} {
+		if _, ok := responses[key]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// matchingMedia returns the media type for an observed content type: an
+// exact match ignoring parameters, else any JSON media type for JSON bodies.
+func matchingMedia(content map[string]MediaType, contentType string) (string, MediaType, bool) {
+	if len(content) == 0 {
+		return "", MediaType{}, false
+	}
+	want := mediaTypeBase(contentType)
+	if want == "" {
+		want = "application/json"
+	}
+	for ct, media := range content {
+		if mediaTypeBase(ct) == want {
+			return ct, media, true
+		}
+	}
+	if strings.Contains(want, "json") {
+		if ct, media, ok := preferredMedia(content); ok && strings.Contains(ct, "json") {
+			return ct, media, true
+		}
+	}
+	return "", MediaType{}, false
+}
+
+// templateParamName returns the parameter name of a "{name}" segment.
+func templateParamName(segment string) (string, bool) {
+	if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
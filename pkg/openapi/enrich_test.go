@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestEnrich(t *testing.T) {
+	specYAML := `openapi: 3.1.0
+info:
+  title: Users API
+  version: 1.0.0
+paths:
+  /users/{userId}:
+    parameters:
+      - name: userId
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      parameters:
+        - name: expand
+          in: query
+          schema:
+            type: string
+          example: profile
+      responses:
+        "200":
+          description: A user
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+        default:
+          description: Error
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+`
+	spec, err := FromYAML([]byte(specYAML))
+	if err != nil {
+		t.Fatalf("FromYAML failed: %v", err)
+	}
+
+	ct := "application/json"
+	engine := inference.NewEngine(inference.DefaultEngineOptions())
+	for _, id := range []int{7, 8} {
+		engine.ProcessRecord(&ir.IRRecord{
+			Request: ir.Request{
+				Method: ir.RequestMethodGET,
+				Path:   fmt.Sprintf("/users/%d", id),
+				Query:  map[string]any{"expand": "teams"},
+			},
+			Response: ir.Response{
+				Status:      200,
+				ContentType: &ct,
+				Headers:     map[string]string{"x-rate-limit-remaining": "99"},
+				Body:        map[string]any{"id": float64(id), "name": "Ada", "nickname": "ada"},
+			},
+		})
+	}
+	engine.ProcessRecord(&ir.IRRecord{
+		Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/health"},
+		Response: ir.Response{Status: 200},
+	})
+	result := engine.Finalize()
+
+	opts := DefaultEnrichOptions()
+	opts.ObservedFieldExtensions = true
+	report := Enrich(spec, result, opts)
+
+	if report.Operations != 1 {
+		t.Errorf("expected 1 matched operation, got %d", report.Operations)
+	}
+	if len(report.Undocumented) != 1 || report.Undocumented[0] != "GET /health" {
+		t.Errorf("expected GET /health undocumented, got %v", report.Undocumented)
+	}
+	if _, ok := spec.Paths["/health"]; ok {
+		t.Error("expected no paths to be added")
+	}
+
+	item := spec.Paths["/users/{userId}"]
+	if item.Parameters[0].Example == nil {
+		t.Error("expected path parameter example from traffic")
+	}
+	if got := item.Get.Parameters[0].Example; got != "profile" {
+		t.Errorf("expected existing example to be kept, got %v", got)
+	}
+
+	resp := item.Get.Responses["200"]
+	body, ok := resp.Content["application/json"].Example.(map[string]any)
+	if !ok || body["name"] != "Ada" {
+		t.Errorf("expected observed response example, got %v", resp.Content["application/json"].Example)
+	}
+	if _, ok := resp.Headers["x-rate-limit-remaining"]; !ok {
+		t.Errorf("expected observed response header, got %v", resp.Headers)
+	}
+	if props := spec.Components.Schemas["User"].Properties; len(props) != 2 {
+		t.Errorf("expected schema to be unchanged, got %d properties", len(props))
+	}
+
+	if len(report.ObservedFields) != 1 || report.ObservedFields[0].Field != "nickname" || report.ObservedFields[0].Location != "response 200" {
+		t.Errorf("expected nickname observed field, got %+v", report.ObservedFields)
+	}
+	fields, ok := item.Get.Extensions["x-observed-fields"].([]map[string]any)
+	if !ok || len(fields) != 1 || fields[0]["field"] != "nickname" {
+		t.Errorf("expected x-observed-fields extension, got %v", item.Get.Extensions)
+	}
+}
@@ -0,0 +1,89 @@
+package openapi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// ErrorCodeSchemaName is the component schema holding observed error codes.
+const ErrorCodeSchemaName = "ErrorCode"
+
+// addErrorCodeEnum declares the observed error codes as a shared string
+// enum component and references it from the error response fields the
+// codes were observed in.
+func addErrorCodeEnum(spec *Spec, result *inference.InferenceResult) {
+	enum := make([]any, 0, len(result.ErrorCodes))
+	for _, code := range result.ErrorCodes {
+		enum = append(enum, code.Code)
+	}
+	sort.Slice(enum, func(i, j int) bool { return enum[i].(string) < enum[j].(string) })
+
+	if spec.Components == nil {
+		spec.Components = &Components{}
+	}
+	if spec.Components.Schemas == nil {
+		spec.Components.Schemas = make(map[string]*Schema)
+	}
+	spec.Components.Schemas[ErrorCodeSchemaName] = &Schema{
+		Type:        "string",
+		Description: "Error codes observed in 4xx and 5xx responses.",
+		Enum:        enum,
+	}
+
+	ref := &Schema{Ref: "#/components/schemas/" + ErrorCodeSchemaName}
+	for _, code := range result.ErrorCodes {
+		for _, use := range code.Endpoints {
+			endpoint := result.Endpoints[use.Endpoint]
+			if endpoint == nil {
+				continue
+			}
+			op := operationForMethod(spec.Paths[endpoint.PathTemplate], endpoint.Method)
+			if op == nil {
+				continue
+			}
+			for _, mt := range op.Responses[strconv.Itoa(use.Status)].Content {
+				replaceField(mt.Schema, strings.Split(use.Field, "."), ref)
+			}
+		}
+	}
+}
+
+// replaceField replaces the string property at a schema path with ref.
+func replaceField(schema *Schema, parts []string, ref *Schema) {
+	if schema == nil {
+		return
+	}
+	name, isArray := strings.CutSuffix(parts[0], "[]")
+	prop, ok := schema.Properties[name]
+	if !ok {
+		return
+	}
+	if isArray {
+		if len(parts) > 1 {
+			replaceField(prop.Items, parts[1:], ref)
+		}
+		return
+	}
+	if len(parts) > 1 {
+		replaceField(prop, parts[1:], ref)
+		return
+	}
+	if isStringSchema(prop) {
+		schema.Properties[name] = ref
+	}
+}
+
+// isStringSchema reports whether a schema's type is string, or string and
+// null.
+func isStringSchema(schema *Schema) bool {
+	switch t := schema.Type.(type) {
+	case string:
+		return t == "string"
+	case []string:
+		return len(t) > 0 && t[0] == "string"
+	}
+	return false
+}
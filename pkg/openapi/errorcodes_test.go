@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestErrorCodeEnum(t *testing.T) {
+	errorBody := func(code string) map[string]any {
+		return map[string]any{"error": map[string]any{"code": code, "message": "failed"}}
+	}
+	records := []ir.IRRecord{
+		{Request: ir.Request{Method: ir.RequestMethodPOST, Path: "/users"}, Response: ir.Response{Status: 400, Body: errorBody("missing_field")}},
+		{Request: ir.Request{Method: ir.RequestMethodPOST, Path: "/users"}, Response: ir.Response{Status: 409, Body: errorBody("duplicate_email")}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/status"}, Response: ir.Response{Status: 503, Body: map[string]any{"error": "Service is down"}}},
+	}
+	result := inference.InferFromRecords(records)
+
+	if spec := GenerateFromInference(result, DefaultGeneratorOptions()); spec.Components != nil && spec.Components.Schemas[ErrorCodeSchemaName] != nil {
+		t.Error("expected no ErrorCode component by default")
+	}
+
+	opts := DefaultGeneratorOptions()
+	opts.ErrorCodeEnum = true
+	spec := GenerateFromInference(result, opts)
+
+	enum := spec.Components.Schemas[ErrorCodeSchemaName]
+	if enum == nil || fmt.Sprint(enum.Enum) != "[duplicate_email missing_field]" {
+		t.Fatalf("unexpected ErrorCode component %+v", enum)
+	}
+	for _, code := range []string{"400", "409"} {
+		errSchema := spec.Paths["/users"].Post.Responses[code].Content["application/json"].Schema.Properties["error"]
+		if got := errSchema.Properties["code"].Ref; got != "#/components/schemas/ErrorCode" {
+			t.Errorf("expected %s error.code to reference ErrorCode, got %q", code, got)
+		}
+	}
+	if got := spec.Paths["/status"].Get.Responses["503"].Content["application/json"].Schema.Properties["error"]; got.Ref != "" {
+		t.Error("expected error messages to keep their schema")
+	}
+}
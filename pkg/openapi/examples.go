@@ -0,0 +1,212 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// ExampleSelection controls how observed examples are ordered in the spec.
+type ExampleSelection string
+
+const (
+	// ExampleSelectionFirst keeps examples in observation order (default).
+	ExampleSelectionFirst ExampleSelection = ""
+
+	// ExampleSelectionRealistic orders examples by realism score so the first
+	// (surfaced) example is complete, non-null, non-empty, and median-length.
+	ExampleSelectionRealistic ExampleSelection = "realistic"
+)
+
+// selectExamples orders examples according to the selection strategy.
+// The input slice is not modified.
+func selectExamples(examples []any, selection ExampleSelection) []any {
+	if selection != ExampleSelectionRealistic || len(examples) < 2 {
+		return examples
+	}
+
+	// Median length is taken over non-degenerate examples only
+	lengths := make([]int, len(examples))
+	var candidates []int
+	for i, ex := range examples {
+		lengths[i] = exampleLength(ex)
+		if !isDegenerate(ex) {
+			candidates = append(candidates, lengths[i])
+		}
+	}
+	median := medianInt(candidates)
+
+	type scored struct {
+		value any
+		score float64
+	}
+	ranked := make([]scored, len(examples))
+	for i, ex := range examples {
+		ranked[i] = scored{value: ex, score: realismScore(ex, lengths[i], median)}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	result := make([]any, len(ranked))
+	for i, r := range ranked {
+		result[i] = r.value
+	}
+	return result
+}
+
+// bestExample returns the highest-ranked example, or nil if there are none.
+func bestExample(examples []any, selection ExampleSelection) any {
+	if len(examples) == 0 {
+		return nil
+	}
+	return selectExamples(examples, selection)[0]
+}
+
+// addExamplePairs adds observed transactions as named examples
+// "<operationId>-1", "<operationId>-2", ..., so the request and response
+// examples of one transaction share a name.
+func (g *Generator) addExamplePairs(op *Operation, pairs []*inference.ExamplePair) {
+	for i, pair := range pairs {
+		name := op.OperationID + "-" + strconv.Itoa(i+1)
+		summary := fmt.Sprintf("Status %d transaction", pair.Status)
+		if op.RequestBody != nil && pair.RequestBody != nil {
+			g.addNamedExample(op.RequestBody.Content, pair.RequestContentType, name, summary, pair.RequestBody)
+		}
+		if resp, ok := op.Responses[strconv.Itoa(pair.Status)]; ok && pair.ResponseBody != nil {
+			g.addNamedExample(resp.Content, pair.ResponseContentType, name, summary, pair.ResponseBody)
+		}
+	}
+}
+
+// addInvalidExamples adds the request bodies of rejected transactions as
+// named examples "invalid-example", "invalid-example-2", ..., with the
+// error response of each under the same name on its 400 or 422 response.
+func (g *Generator) addInvalidExamples(op *Operation, pairs []*inference.ExamplePair) {
+	if op.RequestBody == nil {
+		return
+	}
+	for i, pair := range pairs {
+		name := "invalid-example"
+		if i > 0 {
+			name += "-" + strconv.Itoa(i+1)
+		}
+		summary := fmt.Sprintf("Invalid request, rejected with %d %s", pair.Status, http.StatusText(pair.Status))
+		g.addNamedExample(op.RequestBody.Content, pair.RequestContentType, name, summary, pair.RequestBody)
+		if resp, ok := op.Responses[strconv.Itoa(pair.Status)]; ok && pair.ResponseBody != nil {
+			g.addNamedExample(resp.Content, pair.ResponseContentType, name, summary, pair.ResponseBody)
+		}
+	}
+}
+
+// addNamedExample adds an example to the media type of contentType, or to
+// the only media type when contentType has no entry. Binary media types
+// and downloads, and raw multipart bodies, are skipped.
+func (g *Generator) addNamedExample(content map[string]MediaType, contentType, name, summary string, value any) {
+	key := normalizeMediaType(contentType, g.options.KeepMediaTypeParameters, g.options.CollapseVendorMediaTypes)
+	mt, ok := content[key]
+	if !ok {
+		if len(content) != 1 {
+			return
+		}
+		for k, v := range content {
+			key, mt = k, v
+		}
+	}
+	if ir.IsBinaryContentType(key) || isBinarySchema(mt.Schema) {
+		return
+	}
+	if _, raw := value.(string); raw && inference.IsMultipartFormData(key) {
+		return
+	}
+	if mt.Examples == nil {
+		mt.Examples = make(map[string]Example)
+	}
+	mt.Examples[name] = Example{Summary: summary, Value: value}
+	content[key] = mt
+}
+
+// realismScore rates how representative an example is. Degenerate values
+// (null, empty strings, arrays, and objects) score lowest; complete values
+// score higher, with a penalty for lengths far from the median.
+func realismScore(v any, length, median int) float64 {
+	if isDegenerate(v) {
+		return 0
+	}
+
+	score := 10.0 + 5.0*completeness(v)
+
+	if median > 0 {
+		deviation := math.Abs(float64(length-median)) / float64(median)
+		score -= 3.0 * math.Min(deviation, 1.0)
+	}
+
+	return score
+}
+
+// isDegenerate reports whether a value is null or empty.
+func isDegenerate(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case []any:
+		return len(val) == 0
+	case map[string]any:
+		return len(val) == 0
+	}
+	return false
+}
+
+// completeness returns the fraction (0-1) of non-degenerate members of an
+// object or array. Scalars are complete.
+func completeness(v any) float64 {
+	switch val := v.(type) {
+	case map[string]any:
+		filled := 0
+		for _, member := range val {
+			if !isDegenerate(member) {
+				filled++
+			}
+		}
+		return float64(filled) / float64(len(val))
+	case []any:
+		filled := 0
+		for _, item := range val {
+			if !isDegenerate(item) {
+				filled++
+			}
+		}
+		return float64(filled) / float64(len(val))
+	}
+	return 1
+}
+
+// exampleLength returns the serialized length of an example.
+func exampleLength(v any) int {
+	if s, ok := v.(string); ok {
+		return len(s)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return len(fmt.Sprint(v))
+	}
+	return len(data)
+}
+
+func medianInt(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+	return sorted[len(sorted)/2]
+}
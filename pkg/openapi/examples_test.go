@@ -0,0 +1,139 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestSelectExamplesRealistic(t *testing.T) {
+	examples := []any{
+		nil,
+		[]any{},
+		map[string]any{"name": "", "email": nil},
+		map[string]any{"name": "Alice", "email": "alice@example.com"},
+		map[string]any{"name": "Bob", "email": "bob@example.com", "bio": strings.Repeat("x", 500)},
+	}
+
+	got := selectExamples(examples, ExampleSelectionRealistic)
+	best, ok := got[0].(map[string]any)
+	if !ok || best["name"] != "Alice" {
+		t.Errorf("expected complete median-length example first, got %v", got[0])
+	}
+	if got[len(got)-1] != nil && !isDegenerate(got[len(got)-1]) {
+		t.Errorf("expected degenerate example last, got %v", got[len(got)-1])
+	}
+
+	// Observation order is kept without realistic selection
+	if first := selectExamples(examples, ExampleSelectionFirst); first[0] != nil {
+		t.Errorf("expected observation order, got %v", first[0])
+	}
+
+	if v := bestExample([]any{"", "active"}, ExampleSelectionRealistic); v != "active" {
+		t.Errorf("expected non-empty parameter example, got %v", v)
+	}
+
+	// Realistic selection is opt-in
+	if sel := DefaultGeneratorOptions().ExampleSelection; sel != ExampleSelectionFirst {
+		t.Errorf("default generator example selection = %q, want first", sel)
+	}
+	if sel := DefaultEnrichOptions().ExampleSelection; sel != ExampleSelectionFirst {
+		t.Errorf("default enrich example selection = %q, want first", sel)
+	}
+}
+
+func TestExamplePairs(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{"name": "Alice"}},
+			Response: ir.Response{Status: 201, Body: map[string]any{"id": float64(1), "name": "Alice"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{"name": "Bob"}},
+			Response: ir.Response{Status: 201, Body: map[string]any{"id": float64(2), "name": "Bob"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{"name": "Carol"}},
+			Response: ir.Response{Status: 201, Body: map[string]any{"id": float64(3), "name": "Carol"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", Body: map[string]any{}},
+			Response: ir.Response{Status: 400, Body: map[string]any{"error": "name is required"}},
+		},
+	}
+
+	if spec := GenerateFromInference(inference.InferFromRecords(records), DefaultGeneratorOptions()); spec.Paths["/users"].Post.RequestBody.Content["application/json"].Examples != nil {
+		t.Error("expected no named examples by default")
+	}
+
+	opts := inference.DefaultEngineOptions()
+	opts.ExamplePairs = 2
+	engine := inference.NewEngine(opts)
+	engine.ProcessRecords(records)
+	spec := GenerateFromInference(engine.Finalize(), DefaultGeneratorOptions())
+
+	op := spec.Paths["/users"].Post
+	requests := op.RequestBody.Content["application/json"].Examples
+	created := op.Responses["201"].Content["application/json"].Examples
+	failed := op.Responses["400"].Content["application/json"].Examples
+	if len(requests) != 3 || len(created) != 2 || len(failed) != 1 {
+		t.Fatalf("expected 3 request, 2 created, and 1 failed examples, got %d, %d, %d", len(requests), len(created), len(failed))
+	}
+
+	for name, example := range created {
+		request, ok := requests[name]
+		if !ok {
+			t.Fatalf("expected request example %q paired with response", name)
+		}
+		reqName := request.Value.(map[string]any)["name"]
+		respName := example.Value.(map[string]any)["name"]
+		if reqName != respName {
+			t.Errorf("example %q pairs request %v with response %v", name, reqName, respName)
+		}
+	}
+	if _, ok := created["postUsers-1"]; !ok {
+		t.Errorf("expected examples named after the operation, got %v", created)
+	}
+	var failedName string
+	for name := range failed {
+		failedName = name
+	}
+	if body := requests[failedName].Value.(map[string]any); len(body) != 0 {
+		t.Errorf("expected the 400 example to pair with the empty request, got %v", body)
+	}
+}
+
+func TestInvalidExamples(t *testing.T) {
+	ct := "application/json"
+	opts := inference.DefaultEngineOptions()
+	opts.InvalidExamples = 2
+	engine := inference.NewEngine(opts)
+	engine.ProcessRecords([]ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", ContentType: &ct, Body: map[string]any{"name": "Bob"}},
+			Response: ir.Response{Status: 201, ContentType: &ct, Body: map[string]any{"id": "1"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users", ContentType: &ct, Body: map[string]any{"name": ""}},
+			Response: ir.Response{Status: 422, ContentType: &ct, Body: map[string]any{"error": "name is empty"}},
+		},
+	})
+	op := GenerateFromInference(engine.Finalize(), DefaultGeneratorOptions()).Paths["/users"].Post
+
+	example, ok := op.RequestBody.Content["application/json"].Examples["invalid-example"]
+	if !ok || !reflect.DeepEqual(example.Value, map[string]any{"name": ""}) {
+		t.Fatalf("request invalid-example = %+v", op.RequestBody.Content["application/json"].Examples)
+	}
+	if example.Summary != "Invalid request, rejected with 422 Unprocessable Entity" {
+		t.Errorf("summary = %q", example.Summary)
+	}
+	if got := op.Responses["422"].Content["application/json"].Examples["invalid-example"].Value; !reflect.DeepEqual(got, map[string]any{"error": "name is empty"}) {
+		t.Errorf("response invalid-example = %v", got)
+	}
+	if _, ok := op.Responses["201"].Content["application/json"].Examples["invalid-example"]; ok {
+		t.Error("unexpected invalid-example on the 201 response")
+	}
+}
@@ -0,0 +1,892 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Extensions holds specification extensions (fields prefixed with "x-").
+type Extensions map[string]any
+
+// IsExtensionKey reports whether a field name is a specification extension.
+func IsExtensionKey(key string) bool {
+	return strings.HasPrefix(key, "x-")
+}
+
+// sortedKeys returns extension keys in sorted order for deterministic output.
+func (e Extensions) sortedKeys() []string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		if IsExtensionKey(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// marshalJSONWithExtensions marshals v (a JSON object) and appends extension fields,
+// preserving the struct field order of v.
+func marshalJSONWithExtensions(v any, ext Extensions) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	keys := ext.sortedKeys()
+	if len(keys) == 0 {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	trimmed := bytes.TrimRight(data, " \n")
+	buf.Write(trimmed[:len(trimmed)-1])
+	needComma := len(bytes.TrimSpace(trimmed[1:len(trimmed)-1])) > 0
+	for _, k := range keys {
+		val, err := json.Marshal(ext[k])
+		if err != nil {
+			return nil, err
+		}
+		if needComma {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(k)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(val)
+		needComma = true
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// unmarshalJSONExtensions extracts extension fields from a JSON object.
+func unmarshalJSONExtensions(data []byte) (Extensions, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var ext Extensions
+	for k, v := range raw {
+		if !IsExtensionKey(k) {
+			continue
+		}
+		var val any
+		if err := json.Unmarshal(v, &val); err != nil {
+			return nil, err
+		}
+		if ext == nil {
+			ext = make(Extensions)
+		}
+		ext[k] = val
+	}
+	return ext, nil
+}
+
+// marshalYAMLWithExtensions encodes v as a YAML mapping node and appends extension fields.
+func marshalYAMLWithExtensions(v any, ext Extensions) (any, error) {
+	keys := ext.sortedKeys()
+	if len(keys) == 0 {
+		return v, nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		var valNode yaml.Node
+		if err := valNode.Encode(ext[k]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k},
+			&valNode,
+		)
+	}
+	return &node, nil
+}
+
+// unmarshalYAMLExtensions extracts extension fields from a YAML mapping node.
+func unmarshalYAMLExtensions(node *yaml.Node) (Extensions, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	var ext Extensions
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if !IsExtensionKey(key) {
+			continue
+		}
+		var val any
+		if err := node.Content[i+1].Decode(&val); err != nil {
+			return nil, err
+		}
+		if ext == nil {
+			ext = make(Extensions)
+		}
+		ext[key] = val
+	}
+	return ext, nil
+}
+
+// specDocument mirrors Spec for encoding, writing paths in PathOrder.
+type specDocument struct {
+	OpenAPI           string                `json:"openapi" yaml:"openapi"`
+	Info              Info                  `json:"info" yaml:"info"`
+	JSONSchemaDialect string                `json:"jsonSchemaDialect,omitempty" yaml:"jsonSchemaDialect,omitempty"`
+	Servers           []Server              `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths             orderedPaths          `json:"paths" yaml:"paths"`
+	Webhooks          map[string]*PathItem  `json:"webhooks,omitempty" yaml:"webhooks,omitempty"`
+	Components        *Components           `json:"components,omitempty" yaml:"components,omitempty"`
+	Security          []SecurityRequirement `json:"security,omitempty" yaml:"security,omitempty"`
+	Tags              []Tag                 `json:"tags,omitempty" yaml:"tags,omitempty"`
+	ExternalDocs      *ExternalDocs         `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+}
+
+func (s Spec) document() specDocument {
+	return specDocument{
+		OpenAPI:           s.OpenAPI,
+		Info:              s.Info,
+		JSONSchemaDialect: s.JSONSchemaDialect,
+		Servers:           s.Servers,
+		Paths:             orderedPaths{paths: s.Paths, order: s.PathOrder},
+		Webhooks:          s.Webhooks,
+		Components:        s.Components,
+		Security:          s.Security,
+		Tags:              s.Tags,
+		ExternalDocs:      s.ExternalDocs,
+	}
+}
+
+// orderedPaths encodes a paths map with the listed paths first.
+type orderedPaths struct {
+	paths map[string]*PathItem
+	order []string
+}
+
+// keys returns the listed paths present in the map, then the rest sorted.
+func (p orderedPaths) keys() []string {
+	keys := make([]string, 0, len(p.paths))
+	seen := make(map[string]bool, len(p.paths))
+	for _, path := range p.order {
+		if _, ok := p.paths[path]; ok && !seen[path] {
+			seen[path] = true
+			keys = append(keys, path)
+		}
+	}
+	rest := make([]string, 0, len(p.paths)-len(keys))
+	for path := range p.paths {
+		if !seen[path] {
+			rest = append(rest, path)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p orderedPaths) MarshalJSON() ([]byte, error) {
+	if p.paths == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, path := range p.keys() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(path)
+		val, err := json.Marshal(p.paths[path])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (p orderedPaths) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, path := range p.keys() {
+		var valNode yaml.Node
+		if err := valNode.Encode(p.paths[path]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path},
+			&valNode,
+		)
+	}
+	return node, nil
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (s Spec) MarshalJSON() ([]byte, error) {
+	return marshalJSONWithExtensions(s.document(), s.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (s *Spec) UnmarshalJSON(data []byte) error {
+	type plain Spec
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	ext, err := unmarshalJSONExtensions(data)
+	if err != nil {
+		return err
+	}
+	*s = Spec(p)
+	s.Extensions = ext
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (s Spec) MarshalYAML() (any, error) {
+	return marshalYAMLWithExtensions(s.document(), s.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
+	type plain Spec
+	var p plain
+	if err := node.Decode(&p); err != nil {
+		return err
+	}
+	ext, err := unmarshalYAMLExtensions(node)
+	if err != nil {
+		return err
+	}
+	*s = Spec(p)
+	s.Extensions = ext
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type plain Operation
+	return marshalJSONWithExtensions(plain(o), o.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	type plain Operation
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	ext, err := unmarshalJSONExtensions(data)
+	if err != nil {
+		return err
+	}
+	*o = Operation(p)
+	o.Extensions = ext
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (o Operation) MarshalYAML() (any, error) {
+	type plain Operation
+	return marshalYAMLWithExtensions(plain(o), o.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (o *Operation) UnmarshalYAML(node *yaml.Node) error {
+	type plain Operation
+	var p plain
+	if err := node.Decode(&p); err != nil {
+		return err
+	}
+	ext, err := unmarshalYAMLExtensions(node)
+	if err != nil {
+		return err
+	}
+	*o = Operation(p)
+	o.Extensions = ext
+	return nil
+}
+
+// The decode helpers below unmarshal into v, a pointer to a type without
+// methods, and return the extension fields of the same object.
+
+func decodeJSONWithExtensions(data []byte, v any) (Extensions, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	return unmarshalJSONExtensions(data)
+}
+
+func decodeYAMLWithExtensions(node *yaml.Node, v any) (Extensions, error) {
+	if err := node.Decode(v); err != nil {
+		return nil, err
+	}
+	return unmarshalYAMLExtensions(node)
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	type plain Tag
+	return marshalJSONWithExtensions(plain(t), t.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	type plain Tag
+	ext, err := decodeJSONWithExtensions(data, (*plain)(t))
+	t.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (t Tag) MarshalYAML() (any, error) {
+	type plain Tag
+	return marshalYAMLWithExtensions(plain(t), t.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (t *Tag) UnmarshalYAML(node *yaml.Node) error {
+	type plain Tag
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(t))
+	t.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (d ExternalDocs) MarshalJSON() ([]byte, error) {
+	type plain ExternalDocs
+	return marshalJSONWithExtensions(plain(d), d.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (d *ExternalDocs) UnmarshalJSON(data []byte) error {
+	type plain ExternalDocs
+	ext, err := decodeJSONWithExtensions(data, (*plain)(d))
+	d.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (d ExternalDocs) MarshalYAML() (any, error) {
+	type plain ExternalDocs
+	return marshalYAMLWithExtensions(plain(d), d.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (d *ExternalDocs) UnmarshalYAML(node *yaml.Node) error {
+	type plain ExternalDocs
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(d))
+	d.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (i Info) MarshalJSON() ([]byte, error) {
+	type plain Info
+	return marshalJSONWithExtensions(plain(i), i.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (i *Info) UnmarshalJSON(data []byte) error {
+	type plain Info
+	ext, err := decodeJSONWithExtensions(data, (*plain)(i))
+	i.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (i Info) MarshalYAML() (any, error) {
+	type plain Info
+	return marshalYAMLWithExtensions(plain(i), i.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (i *Info) UnmarshalYAML(node *yaml.Node) error {
+	type plain Info
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(i))
+	i.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (c Contact) MarshalJSON() ([]byte, error) {
+	type plain Contact
+	return marshalJSONWithExtensions(plain(c), c.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (c *Contact) UnmarshalJSON(data []byte) error {
+	type plain Contact
+	ext, err := decodeJSONWithExtensions(data, (*plain)(c))
+	c.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (c Contact) MarshalYAML() (any, error) {
+	type plain Contact
+	return marshalYAMLWithExtensions(plain(c), c.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (c *Contact) UnmarshalYAML(node *yaml.Node) error {
+	type plain Contact
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(c))
+	c.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (l License) MarshalJSON() ([]byte, error) {
+	type plain License
+	return marshalJSONWithExtensions(plain(l), l.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (l *License) UnmarshalJSON(data []byte) error {
+	type plain License
+	ext, err := decodeJSONWithExtensions(data, (*plain)(l))
+	l.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (l License) MarshalYAML() (any, error) {
+	type plain License
+	return marshalYAMLWithExtensions(plain(l), l.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (l *License) UnmarshalYAML(node *yaml.Node) error {
+	type plain License
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(l))
+	l.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (s Server) MarshalJSON() ([]byte, error) {
+	type plain Server
+	return marshalJSONWithExtensions(plain(s), s.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (s *Server) UnmarshalJSON(data []byte) error {
+	type plain Server
+	ext, err := decodeJSONWithExtensions(data, (*plain)(s))
+	s.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (s Server) MarshalYAML() (any, error) {
+	type plain Server
+	return marshalYAMLWithExtensions(plain(s), s.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (s *Server) UnmarshalYAML(node *yaml.Node) error {
+	type plain Server
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(s))
+	s.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (v ServerVariable) MarshalJSON() ([]byte, error) {
+	type plain ServerVariable
+	return marshalJSONWithExtensions(plain(v), v.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (v *ServerVariable) UnmarshalJSON(data []byte) error {
+	type plain ServerVariable
+	ext, err := decodeJSONWithExtensions(data, (*plain)(v))
+	v.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (v ServerVariable) MarshalYAML() (any, error) {
+	type plain ServerVariable
+	return marshalYAMLWithExtensions(plain(v), v.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (v *ServerVariable) UnmarshalYAML(node *yaml.Node) error {
+	type plain ServerVariable
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(v))
+	v.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (p PathItem) MarshalJSON() ([]byte, error) {
+	type plain PathItem
+	return marshalJSONWithExtensions(plain(p), p.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (p *PathItem) UnmarshalJSON(data []byte) error {
+	type plain PathItem
+	ext, err := decodeJSONWithExtensions(data, (*plain)(p))
+	p.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (p PathItem) MarshalYAML() (any, error) {
+	type plain PathItem
+	return marshalYAMLWithExtensions(plain(p), p.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (p *PathItem) UnmarshalYAML(node *yaml.Node) error {
+	type plain PathItem
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(p))
+	p.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (l Link) MarshalJSON() ([]byte, error) {
+	type plain Link
+	return marshalJSONWithExtensions(plain(l), l.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (l *Link) UnmarshalJSON(data []byte) error {
+	type plain Link
+	ext, err := decodeJSONWithExtensions(data, (*plain)(l))
+	l.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (l Link) MarshalYAML() (any, error) {
+	type plain Link
+	return marshalYAMLWithExtensions(plain(l), l.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (l *Link) UnmarshalYAML(node *yaml.Node) error {
+	type plain Link
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(l))
+	l.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (h Header) MarshalJSON() ([]byte, error) {
+	type plain Header
+	return marshalJSONWithExtensions(plain(h), h.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (h *Header) UnmarshalJSON(data []byte) error {
+	type plain Header
+	ext, err := decodeJSONWithExtensions(data, (*plain)(h))
+	h.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (h Header) MarshalYAML() (any, error) {
+	type plain Header
+	return marshalYAMLWithExtensions(plain(h), h.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (h *Header) UnmarshalYAML(node *yaml.Node) error {
+	type plain Header
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(h))
+	h.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (m MediaType) MarshalJSON() ([]byte, error) {
+	type plain MediaType
+	return marshalJSONWithExtensions(plain(m), m.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (m *MediaType) UnmarshalJSON(data []byte) error {
+	type plain MediaType
+	ext, err := decodeJSONWithExtensions(data, (*plain)(m))
+	m.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (m MediaType) MarshalYAML() (any, error) {
+	type plain MediaType
+	return marshalYAMLWithExtensions(plain(m), m.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (m *MediaType) UnmarshalYAML(node *yaml.Node) error {
+	type plain MediaType
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(m))
+	m.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (e Encoding) MarshalJSON() ([]byte, error) {
+	type plain Encoding
+	return marshalJSONWithExtensions(plain(e), e.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (e *Encoding) UnmarshalJSON(data []byte) error {
+	type plain Encoding
+	ext, err := decodeJSONWithExtensions(data, (*plain)(e))
+	e.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (e Encoding) MarshalYAML() (any, error) {
+	type plain Encoding
+	return marshalYAMLWithExtensions(plain(e), e.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (e *Encoding) UnmarshalYAML(node *yaml.Node) error {
+	type plain Encoding
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(e))
+	e.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (e Example) MarshalJSON() ([]byte, error) {
+	type plain Example
+	return marshalJSONWithExtensions(plain(e), e.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (e *Example) UnmarshalJSON(data []byte) error {
+	type plain Example
+	ext, err := decodeJSONWithExtensions(data, (*plain)(e))
+	e.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (e Example) MarshalYAML() (any, error) {
+	type plain Example
+	return marshalYAMLWithExtensions(plain(e), e.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (e *Example) UnmarshalYAML(node *yaml.Node) error {
+	type plain Example
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(e))
+	e.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	return marshalJSONWithExtensions(plain(s), s.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	ext, err := decodeJSONWithExtensions(data, (*plain)(s))
+	s.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (s Schema) MarshalYAML() (any, error) {
+	type plain Schema
+	return marshalYAMLWithExtensions(plain(s), s.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	type plain Schema
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(s))
+	s.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (d Discriminator) MarshalJSON() ([]byte, error) {
+	type plain Discriminator
+	return marshalJSONWithExtensions(plain(d), d.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (d *Discriminator) UnmarshalJSON(data []byte) error {
+	type plain Discriminator
+	ext, err := decodeJSONWithExtensions(data, (*plain)(d))
+	d.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (d Discriminator) MarshalYAML() (any, error) {
+	type plain Discriminator
+	return marshalYAMLWithExtensions(plain(d), d.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (d *Discriminator) UnmarshalYAML(node *yaml.Node) error {
+	type plain Discriminator
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(d))
+	d.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (x XML) MarshalJSON() ([]byte, error) {
+	type plain XML
+	return marshalJSONWithExtensions(plain(x), x.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (x *XML) UnmarshalJSON(data []byte) error {
+	type plain XML
+	ext, err := decodeJSONWithExtensions(data, (*plain)(x))
+	x.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (x XML) MarshalYAML() (any, error) {
+	type plain XML
+	return marshalYAMLWithExtensions(plain(x), x.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (x *XML) UnmarshalYAML(node *yaml.Node) error {
+	type plain XML
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(x))
+	x.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (c Components) MarshalJSON() ([]byte, error) {
+	type plain Components
+	return marshalJSONWithExtensions(plain(c), c.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (c *Components) UnmarshalJSON(data []byte) error {
+	type plain Components
+	ext, err := decodeJSONWithExtensions(data, (*plain)(c))
+	c.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (c Components) MarshalYAML() (any, error) {
+	type plain Components
+	return marshalYAMLWithExtensions(plain(c), c.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (c *Components) UnmarshalYAML(node *yaml.Node) error {
+	type plain Components
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(c))
+	c.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	type plain SecurityScheme
+	return marshalJSONWithExtensions(plain(s), s.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (s *SecurityScheme) UnmarshalJSON(data []byte) error {
+	type plain SecurityScheme
+	ext, err := decodeJSONWithExtensions(data, (*plain)(s))
+	s.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (s SecurityScheme) MarshalYAML() (any, error) {
+	type plain SecurityScheme
+	return marshalYAMLWithExtensions(plain(s), s.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (s *SecurityScheme) UnmarshalYAML(node *yaml.Node) error {
+	type plain SecurityScheme
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(s))
+	s.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (f OAuthFlows) MarshalJSON() ([]byte, error) {
+	type plain OAuthFlows
+	return marshalJSONWithExtensions(plain(f), f.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (f *OAuthFlows) UnmarshalJSON(data []byte) error {
+	type plain OAuthFlows
+	ext, err := decodeJSONWithExtensions(data, (*plain)(f))
+	f.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (f OAuthFlows) MarshalYAML() (any, error) {
+	type plain OAuthFlows
+	return marshalYAMLWithExtensions(plain(f), f.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (f *OAuthFlows) UnmarshalYAML(node *yaml.Node) error {
+	type plain OAuthFlows
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(f))
+	f.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (f OAuthFlow) MarshalJSON() ([]byte, error) {
+	type plain OAuthFlow
+	return marshalJSONWithExtensions(plain(f), f.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (f *OAuthFlow) UnmarshalJSON(data []byte) error {
+	type plain OAuthFlow
+	ext, err := decodeJSONWithExtensions(data, (*plain)(f))
+	f.Extensions = ext
+	return err
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (f OAuthFlow) MarshalYAML() (any, error) {
+	type plain OAuthFlow
+	return marshalYAMLWithExtensions(plain(f), f.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (f *OAuthFlow) UnmarshalYAML(node *yaml.Node) error {
+	type plain OAuthFlow
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(f))
+	f.Extensions = ext
+	return err
+}
@@ -0,0 +1,158 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOperationExtensions(t *testing.T) {
+	spec := &Spec{
+		OpenAPI: "3.1.0",
+		Info:    Info{Title: "Test API", Version: "1.0.0"},
+		Paths: map[string]*PathItem{
+			"/test": {
+				Get: &Operation{
+					Summary:    "Test endpoint",
+					Responses:  map[string]Response{"200": {Description: "OK"}},
+					Extensions: Extensions{"x-low-sample": true},
+				},
+			},
+		},
+	}
+
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		out, err := ToString(spec, format)
+		if err != nil {
+			t.Fatalf("ToString(%s) failed: %v", format, err)
+		}
+		if !strings.Contains(out, "x-low-sample") {
+			t.Errorf("expected x-low-sample in %s output:\n%s", format, out)
+		}
+
+		var parsed *Spec
+		if format == FormatJSON {
+			parsed, err = FromJSON([]byte(out))
+		} else {
+			parsed, err = FromYAML([]byte(out))
+		}
+		if err != nil {
+			t.Fatalf("parsing %s failed: %v", format, err)
+		}
+		if parsed.Paths["/test"].Get.Extensions["x-low-sample"] != true {
+			t.Errorf("expected x-low-sample to round-trip in %s, got %v", format, parsed.Paths["/test"].Get.Extensions)
+		}
+		if parsed.Paths["/test"].Get.Summary != "Test endpoint" {
+			t.Errorf("expected summary to round-trip in %s", format)
+		}
+	}
+}
+
+func TestNestedExtensions(t *testing.T) {
+	input := `openapi: 3.1.0
+info:
+  title: Test
+  version: "1.0"
+  x-audience: internal
+servers:
+  - url: https://api.example.com
+    x-region: eu
+tags:
+  - name: users
+    x-display-name: Users
+paths:
+  /users:
+    x-path-owner: identity
+    get:
+      parameters:
+        - name: limit
+          in: query
+          x-max: 100
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: OK
+          x-cache: 60
+          content:
+            application/json:
+              x-media: true
+              schema:
+                type: object
+                x-schema-level: top
+                properties:
+                  id:
+                    type: string
+                    x-go-type: uuid.UUID
+      callbacks:
+        changed:
+          x-callback-note: async
+          '{$request.query.url}':
+            post:
+              responses:
+                "200":
+                  description: OK
+components:
+  x-components-note: shared
+  securitySchemes:
+    key:
+      type: apiKey
+      name: X-Key
+      in: header
+      x-key-rotation: 90d
+`
+	spec, err := FromYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("FromYAML failed: %v", err)
+	}
+
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		out, err := ToString(spec, format)
+		if err != nil {
+			t.Fatalf("ToString(%s) failed: %v", format, err)
+		}
+		var parsed *Spec
+		if format == FormatJSON {
+			parsed, err = FromJSON([]byte(out))
+		} else {
+			parsed, err = FromYAML([]byte(out))
+		}
+		if err != nil {
+			t.Fatalf("parsing %s failed: %v", format, err)
+		}
+
+		item := parsed.Paths["/users"]
+		resp := item.Get.Responses["200"]
+		schema := resp.Content["application/json"].Schema
+		checks := []struct {
+			name string
+			ext  Extensions
+			key  string
+			want any
+		}{
+			{"info", parsed.Info.Extensions, "x-audience", "internal"},
+			{"server", parsed.Servers[0].Extensions, "x-region", "eu"},
+			{"tag", parsed.Tags[0].Extensions, "x-display-name", "Users"},
+			{"path item", item.Extensions, "x-path-owner", "identity"},
+			{"parameter", item.Get.Parameters[0].Extensions, "x-max", float64(100)},
+			{"response", resp.Extensions, "x-cache", float64(60)},
+			{"media type", resp.Content["application/json"].Extensions, "x-media", true},
+			{"schema", schema.Extensions, "x-schema-level", "top"},
+			{"property", schema.Properties["id"].Extensions, "x-go-type", "uuid.UUID"},
+			{"callback", item.Get.Callbacks["changed"].Extensions, "x-callback-note", "async"},
+			{"components", parsed.Components.Extensions, "x-components-note", "shared"},
+			{"security scheme", parsed.Components.SecuritySchemes["key"].Extensions, "x-key-rotation", "90d"},
+		}
+		for _, c := range checks {
+			got := c.ext[c.key]
+			if n, ok := got.(int); ok {
+				got = float64(n) // YAML decodes integers as int
+			}
+			if got != c.want {
+				t.Errorf("%s: expected %s %s = %v, got %v", format, c.name, c.key, c.want, c.ext[c.key])
+			}
+		}
+		if len(item.Get.Callbacks["changed"].Expressions) != 1 {
+			t.Errorf("%s: expected callback extension kept out of expressions", format)
+		}
+	}
+}
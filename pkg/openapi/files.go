@@ -0,0 +1,111 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// addFileParts declares the file fields of multipart/form-data request
+// bodies as binary properties, with an encoding naming the content types
+// the files were sent as. Fields sent in every multipart body are required.
+func (g *Generator) addFileParts(content map[string]MediaType, body *inference.BodyData) {
+	if len(body.FileParts) == 0 {
+		return
+	}
+	names := make([]string, 0, len(body.FileParts))
+	for name := range body.FileParts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for key, mt := range content {
+		if !inference.IsMultipartFormData(key) {
+			continue
+		}
+		if mt.Schema == nil {
+			mt.Schema = &Schema{Type: "object"}
+		}
+		if mt.Schema.Properties == nil {
+			mt.Schema.Properties = make(map[string]*Schema)
+		}
+		for _, name := range names {
+			part := body.FileParts[name]
+			contentTypes := make([]string, 0, len(part.ContentTypes))
+			for ct := range part.ContentTypes {
+				contentTypes = append(contentTypes, ct)
+			}
+			sort.Strings(contentTypes)
+
+			fileType := "application/octet-stream"
+			if len(contentTypes) == 1 {
+				fileType = contentTypes[0]
+			}
+			schema := g.binarySchema(fileType)
+			if len(contentTypes) > 1 {
+				schema.ContentMediaType = "" // listed in the encoding
+			}
+			if len(part.Filenames) > 0 {
+				schema.Description = "Uploaded file, e.g. " + strings.Join(part.Filenames, ", ") + "."
+			}
+			if part.Multiple {
+				schema = &Schema{Type: "array", Items: schema}
+			}
+			mt.Schema.Properties[name] = schema
+
+			if part.Count == body.Multipart && !slices.Contains(mt.Schema.Required, name) {
+				mt.Schema.Required = append(mt.Schema.Required, name)
+			}
+			if len(contentTypes) > 0 {
+				if mt.Encoding == nil {
+					mt.Encoding = make(map[string]Encoding)
+				}
+				mt.Encoding[name] = Encoding{ContentType: strings.Join(contentTypes, ", ")}
+			}
+		}
+		sort.Strings(mt.Schema.Required)
+		content[key] = mt
+	}
+}
+
+// downloadContent returns the content of a file download response: a binary
+// schema for every media type, whether or not the body was captured.
+func (g *Generator) downloadContent(keys []string) map[string]MediaType {
+	content := make(map[string]MediaType, len(keys))
+	for _, key := range keys {
+		content[key] = MediaType{Schema: g.binarySchema(key)}
+	}
+	return content
+}
+
+// addDownloadHeader documents the Content-Disposition header of a file
+// download response, with an observed filename as its example.
+func addDownloadHeader(resp *Response, download *inference.DownloadData) {
+	name := "Content-Disposition"
+	for key := range resp.Headers {
+		if strings.EqualFold(key, name) {
+			name = key
+		}
+	}
+	if resp.Headers == nil {
+		resp.Headers = make(map[string]Header)
+	}
+	header := resp.Headers[name]
+	if header.Schema == nil {
+		header.Schema = &Schema{Type: "string"}
+	}
+	header.Description = "Sent as a file download (attachment), with the suggested filename"
+	if len(download.Filenames) > 0 {
+		header.Example = fmt.Sprintf("attachment; filename=%q", download.Filenames[0])
+	}
+	resp.Headers[name] = header
+}
+
+// isBinarySchema reports whether a schema describes binary content, which
+// has no example values.
+func isBinarySchema(schema *Schema) bool {
+	return schema != nil && schema.Format == "binary"
+}
@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestFileUploadsAndDownloads(t *testing.T) {
+	multipart := "multipart/form-data; boundary=XyZ"
+	upload := func(files ...string) string {
+		body := "--XyZ\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nHoliday\r\n"
+		for _, file := range files {
+			body += "--XyZ\r\nContent-Disposition: form-data; name=\"photos\"; filename=\"" + file + "\"\r\nContent-Type: image/png\r\n\r\nPNG\r\n"
+		}
+		return body + "--XyZ--\r\n"
+	}
+	pdf := "application/pdf"
+	result := inference.InferFromRecords([]ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/albums", ContentType: &multipart, Body: upload("a.png", "b.png")},
+			Response: ir.Response{Status: 201},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/albums", ContentType: &multipart, Body: upload()},
+			Response: ir.Response{Status: 201},
+		},
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/invoices/latest"},
+			Response: ir.Response{Status: 200, ContentType: &pdf,
+				Headers: map[string]string{"content-disposition": `attachment; filename="invoice.pdf"`}},
+		},
+	})
+	spec := GenerateFromInference(result, DefaultGeneratorOptions())
+
+	mt := spec.Paths["/albums"].Post.RequestBody.Content["multipart/form-data"]
+	photos := mt.Schema.Properties["photos"]
+	if photos == nil || photos.Type != "array" || photos.Items.Format != "binary" || photos.Items.ContentMediaType != "image/png" {
+		t.Fatalf("photos schema = %+v", photos)
+	}
+	if !reflect.DeepEqual(mt.Schema.Required, []string{"title"}) {
+		t.Errorf("required = %v, want photos optional", mt.Schema.Required)
+	}
+	if mt.Encoding["photos"].ContentType != "image/png" {
+		t.Errorf("encoding = %+v", mt.Encoding)
+	}
+
+	resp := spec.Paths["/invoices/latest"].Get.Responses["200"]
+	if schema := resp.Content["application/pdf"].Schema; schema == nil || schema.Format != "binary" {
+		t.Errorf("download schema = %+v", schema)
+	}
+	if header := resp.Headers["content-disposition"]; header.Example != `attachment; filename="invoice.pdf"` {
+		t.Errorf("Content-Disposition header = %+v", header)
+	}
+}
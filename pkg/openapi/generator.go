@@ -24,14 +24,64 @@ type GeneratorOptions struct {
 	Description string
 	APIVersion  string
 	Servers     []string
+
+	// ExampleSelection controls which observed example is surfaced first.
+	ExampleSelection ExampleSelection
+
+	// MetadataExtensions lists record metadata keys (e.g., "environment") whose
+	// observed values are emitted as x-meta-<key> operation extensions.
+	MetadataExtensions []string
+
+	// KeepMediaTypeParameters keeps content type parameters such as charset in
+	// media type keys. By default they are stripped, so
+	// "application/json; charset=utf-8" and "application/json" share an entry.
+	KeepMediaTypeParameters bool
+
+	// CollapseVendorMediaTypes maps vendor types such as
+	// "application/vnd.foo+json" to their suffix type ("application/json").
+	// By default they are kept as distinct content entries.
+	CollapseVendorMediaTypes bool
+
+	// KeepOperationPathParameters declares path parameters on every
+	// operation. By default path parameters shared by all operations under
+	// a path are hoisted to the path item.
+	KeepOperationPathParameters bool
+
+	// TagGroups emits the x-tagGroups extension (Redoc) grouping tags by
+	// top-level resource or host, and orders paths by tag then path.
+	TagGroups TagGroupBy
+
+	// ErrorCodeEnum declares the error codes observed in 4xx/5xx response
+	// bodies as a shared ErrorCode enum component, referenced from the code
+	// fields of error responses.
+	ErrorCodeEnum bool
+
+	// ResponseDescriptions overrides response descriptions by status code
+	// ("404") or class ("4XX"); see ResponseDescription. Other responses
+	// are described by their HTTP reason phrase.
+	ResponseDescriptions map[string]string
+
+	// CRUDNames names operations by their CRUD action (EndpointData.Action),
+	// as in listUsers, getUser, and createUser with summaries such as "List
+	// users", instead of by method and path (getUsers, getUsersByUserId).
+	// Operations whose name would clash keep the path-based name.
+	CRUDNames bool
+
+	// ParamStats emits the x-param-stats extension on path parameters with
+	// statistics of their observed values (inference.ParamStats), such as
+	// {"distinct": 42, "minimum": 1, "maximum": 98765}. Operations whose
+	// statistics differ keep their own path parameters rather than sharing
+	// them on the path item.
+	ParamStats bool
 }
 
 // DefaultGeneratorOptions returns default options.
 func DefaultGeneratorOptions() GeneratorOptions {
 	return GeneratorOptions{
-		Version:    Version31,
-		Title:      "Generated API",
-		APIVersion: "1.0.0",
+		Version:          Version31,
+		Title:            "Generated API",
+		APIVersion:       "1.0.0",
+		ExampleSelection: ExampleSelectionFirst,
 	}
 }
 
@@ -101,13 +151,7 @@ func (g *Generator) Generate(result *inference.InferenceResult) *Spec {
 		}
 	} else if len(result.Hosts) > 0 && len(result.Schemes) > 0 {
 		// Generate servers from observed hosts/schemes
-		for _, host := range result.Hosts {
-			for _, scheme := range result.Schemes {
-				spec.Servers = append(spec.Servers, Server{
-					URL: fmt.Sprintf("%s://%s", scheme, host),
-				})
-			}
-		}
+		spec.Servers = observedServers(result.Hosts, result.Schemes)
 	}
 
 	// Add detected security schemes
@@ -119,7 +163,8 @@ func (g *Generator) Generate(result *inference.InferenceResult) *Spec {
 
 		for key, detected := range result.SecuritySchemes {
 			scheme := &SecurityScheme{
-				Type: detected.Type,
+				Type:        detected.Type,
+				Description: detected.Description,
 			}
 
 			switch detected.Type {
@@ -145,9 +190,34 @@ func (g *Generator) Generate(result *inference.InferenceResult) *Spec {
 	sort.Strings(securityKeys)
 
 	// Generate paths
+	var names map[*inference.EndpointData]crudName
+	if g.options.CRUDNames {
+		names = crudNames(result)
+	}
 	for _, endpoint := range result.Endpoints {
-		g.addEndpoint(spec, endpoint, securityKeys)
+		g.addEndpoint(spec, endpoint, securityKeys, names[endpoint])
+	}
+	if len(g.options.Servers) == 0 && len(result.Hosts) > 1 && len(result.Schemes) > 0 {
+		addEndpointServers(spec, result)
+	}
+	if !g.options.KeepOperationPathParameters {
+		for _, pathItem := range spec.Paths {
+			hoistPathParameters(pathItem)
+		}
+	}
+	if len(result.Links) > 0 {
+		addLinks(spec, result)
+	}
+	if len(result.AsyncOperations) > 0 {
+		addAsyncOperations(spec, result)
+	}
+	if len(result.CORS) > 0 {
+		addCORSExtensions(spec, result)
 	}
+	if g.options.ErrorCodeEnum && len(result.ErrorCodes) > 0 {
+		addErrorCodeEnum(spec, result)
+	}
+	addProblemDetails(spec)
 
 	// Add tag definitions from API metadata
 	if result.APIMetadata != nil && len(result.APIMetadata.TagDefinitions) > 0 {
@@ -174,11 +244,15 @@ func (g *Generator) Generate(result *inference.InferenceResult) *Spec {
 		}
 	}
 
+	if g.options.TagGroups != TagGroupNone {
+		g.addTagGroups(spec, result)
+	}
+
 	return spec
 }
 
 // addEndpoint adds an endpoint to the spec.
-func (g *Generator) addEndpoint(spec *Spec, endpoint *inference.EndpointData, securityKeys []string) {
+func (g *Generator) addEndpoint(spec *Spec, endpoint *inference.EndpointData, securityKeys []string, name crudName) {
 	path := endpoint.PathTemplate
 
 	// Get or create path item
@@ -189,7 +263,7 @@ func (g *Generator) addEndpoint(spec *Spec, endpoint *inference.EndpointData, se
 	}
 
 	// Create operation
-	operation := g.createOperation(endpoint, securityKeys)
+	operation := g.createOperation(endpoint, securityKeys, name)
 
 	// Assign to correct method
 	switch strings.ToUpper(endpoint.Method) {
@@ -212,15 +286,22 @@ func (g *Generator) addEndpoint(spec *Spec, endpoint *inference.EndpointData, se
 	}
 }
 
-// createOperation creates an Operation from endpoint data.
-func (g *Generator) createOperation(endpoint *inference.EndpointData, securityKeys []string) *Operation {
+// createOperation creates an Operation from endpoint data. name is the
+// endpoint's CRUD name, if it is named by its action.
+func (g *Generator) createOperation(endpoint *inference.EndpointData, securityKeys []string, name crudName) *Operation {
 	// Use documentation from endpoint if available, otherwise generate
 	summary := endpoint.Summary
+	if summary == "" {
+		summary = name.summary
+	}
 	if summary == "" {
 		summary = fmt.Sprintf("%s %s", endpoint.Method, endpoint.PathTemplate)
 	}
 
 	operationID := endpoint.OperationID
+	if operationID == "" {
+		operationID = name.operationID
+	}
 	if operationID == "" {
 		operationID = generateOperationID(endpoint.Method, endpoint.PathTemplate)
 	}
@@ -247,6 +328,62 @@ func (g *Generator) createOperation(endpoint *inference.EndpointData, securityKe
 		op.Deprecated = true
 	}
 
+	// Flag endpoints with too few observations to be trusted
+	if endpoint.LowSample {
+		op.Extensions = Extensions{
+			"x-low-sample":    true,
+			"x-request-count": endpoint.RequestCount,
+		}
+	}
+
+	// Flag endpoints only reached by suspected bot/scanner traffic
+	if endpoint.NoiseCount > 0 && endpoint.NoiseCount >= endpoint.RequestCount {
+		if op.Extensions == nil {
+			op.Extensions = Extensions{}
+		}
+		op.Extensions["x-suspected-noise"] = true
+	}
+
+	// Flag bulk endpoints, which act on many resources per request
+	if endpoint.Bulk {
+		if op.Extensions == nil {
+			op.Extensions = Extensions{}
+		}
+		op.Extensions["x-bulk"] = true
+	}
+
+	// Flag endpoints observed serving conditional requests
+	if endpoint.ConditionalRequests {
+		if op.Extensions == nil {
+			op.Extensions = Extensions{}
+		}
+		op.Extensions["x-conditional-requests"] = true
+	}
+
+	// Document the Link values sent in 103 Early Hints responses
+	if len(endpoint.EarlyHints) > 0 {
+		if op.Extensions == nil {
+			op.Extensions = Extensions{}
+		}
+		links := append([]string(nil), endpoint.EarlyHints...)
+		sort.Strings(links)
+		op.Extensions["x-early-hints"] = links
+	}
+
+	// Surface selected record metadata, e.g. which environments an endpoint was seen in
+	for _, key := range g.options.MetadataExtensions {
+		values := endpoint.Metadata[key]
+		if len(values) == 0 {
+			continue
+		}
+		if op.Extensions == nil {
+			op.Extensions = Extensions{}
+		}
+		sorted := append([]string(nil), values...)
+		sort.Strings(sorted)
+		op.Extensions["x-meta-"+key] = sorted
+	}
+
 	// Add security requirements if any were detected
 	if len(securityKeys) > 0 {
 		op.Security = make([]SecurityRequirement, 0, len(securityKeys))
@@ -270,6 +407,10 @@ func (g *Generator) createOperation(endpoint *inference.EndpointData, securityKe
 		op.Parameters = append(op.Parameters, g.createParameter(param, "header", param.Required))
 	}
 
+	// Document preconditions of writes (observed 412 responses replace the
+	// generic one below)
+	g.addPreconditions(op, endpoint)
+
 	// Sort parameters for consistent output
 	sort.Slice(op.Parameters, func(i, j int) bool {
 		if op.Parameters[i].In != op.Parameters[j].In {
@@ -279,7 +420,7 @@ func (g *Generator) createOperation(endpoint *inference.EndpointData, securityKe
 	})
 
 	// Add request body
-	if endpoint.RequestBody != nil && len(endpoint.RequestBody.Schema.Examples) > 0 {
+	if endpoint.RequestBody != nil && (len(endpoint.RequestBody.Schema.Examples) > 0 || len(endpoint.RequestBody.FileParts) > 0 || hasBinaryMediaType(endpoint.RequestBody.ContentTypes)) {
 		op.RequestBody = g.createRequestBody(endpoint.RequestBody)
 	}
 
@@ -293,6 +434,13 @@ func (g *Generator) createOperation(endpoint *inference.EndpointData, securityKe
 		op.Responses["200"] = Response{Description: "Successful response"}
 	}
 
+	if len(endpoint.ExamplePairs) > 0 {
+		g.addExamplePairs(op, endpoint.ExamplePairs)
+	}
+	if len(endpoint.InvalidExamples) > 0 {
+		g.addInvalidExamples(op, endpoint.InvalidExamples)
+	}
+
 	return op
 }
 
@@ -302,65 +450,131 @@ func (g *Generator) createParameter(param *inference.ParamData, in string, requi
 		Name:     param.Name,
 		In:       in,
 		Required: required,
-		Schema:   &Schema{Type: param.Type},
+		Schema:   g.paramSchema(param),
+	}
+	switch in {
+	case "header":
+		p.Description = inference.HeaderDescription(param.Name)
+	case "query":
+		p.Description = inference.QueryParamDescription(param)
 	}
 
-	if param.Format != "" {
-		p.Schema.Format = param.Format
+	// Serialization settings for array and deepObject parameters
+	if param.Style != "" {
+		p.Style = param.Style
+	}
+	if param.Explode != nil {
+		explode := *param.Explode
+		p.Explode = &explode
 	}
 
 	// Add example
 	if len(param.Examples) > 0 {
-		p.Example = param.Examples[0]
+		p.Example = bestExample(param.Examples, g.options.ExampleSelection)
+	}
+
+	// Other spellings accepted for the parameter
+	if len(param.Aliases) > 0 {
+		p.Extensions = Extensions{"x-aliases": append([]string(nil), param.Aliases...)}
+	}
+
+	// Statistics of observed path parameter values
+	if in == "path" && g.options.ParamStats && param.Stats != nil {
+		if p.Extensions == nil {
+			p.Extensions = Extensions{}
+		}
+		p.Extensions["x-param-stats"] = paramStatsExtension(param.Stats)
 	}
 
 	return p
 }
 
+// paramSchema creates a Schema from param data, including array items and object properties.
+func (g *Generator) paramSchema(param *inference.ParamData) *Schema {
+	schema := &Schema{Type: param.Type}
+
+	if param.Format != "" {
+		schema.Format = param.Format
+	}
+	if len(param.Enum) > 0 {
+		values := append([]string(nil), param.Enum...)
+		sort.Strings(values)
+		for _, v := range values {
+			schema.Enum = append(schema.Enum, v)
+		}
+	}
+
+	switch param.Type {
+	case "array":
+		if param.Items != nil {
+			schema.Items = g.paramSchema(param.Items)
+		} else {
+			schema.Items = &Schema{Type: "string"}
+		}
+	case "object":
+		if len(param.Properties) > 0 {
+			schema.Properties = make(map[string]*Schema, len(param.Properties))
+			for name, prop := range param.Properties {
+				schema.Properties[name] = g.paramSchema(prop)
+			}
+		}
+	}
+
+	return schema
+}
+
 // createRequestBody creates a RequestBody from body data.
 func (g *Generator) createRequestBody(body *inference.BodyData) *RequestBody {
-	contentType := body.ContentType
-	if contentType == "" {
-		contentType = "application/json"
+	var tree *inference.SchemaNode
+	if len(body.Schema.Examples) > 0 {
+		tree = inference.BuildSchemaTree(body.Schema)
 	}
 
-	schema := g.convertSchemaNode(inference.BuildSchemaTree(body.Schema))
-
+	content := g.mediaTypeContent(g.mediaTypeKeys(body.ContentType, body.ContentTypes), tree)
+	g.addFileParts(content, body)
 	return &RequestBody{
-		Required: true,
-		Content: map[string]MediaType{
-			contentType: {Schema: schema},
-		},
+		Required: body.Required,
+		Content:  content,
 	}
 }
 
 // createResponse creates a Response from response data.
 func (g *Generator) createResponse(respData *inference.ResponseData) Response {
 	resp := Response{
-		Description: fmt.Sprintf("Status %d response", respData.StatusCode),
+		Description: ResponseDescription(respData.StatusCode, g.options.ResponseDescriptions),
 	}
 
 	// Add headers
 	if len(respData.Headers) > 0 {
 		resp.Headers = make(map[string]Header)
 		for name, param := range respData.Headers {
+			schema := &Schema{Type: param.Type}
+			if param.Items != nil {
+				// Repeated headers (Link, Via) are documented as arrays
+				schema.Items = &Schema{Type: param.Items.Type}
+			}
 			resp.Headers[name] = Header{
-				Schema: &Schema{Type: param.Type},
+				Description: inference.HeaderDescription(name),
+				Schema:      schema,
 			}
 		}
 	}
 
-	// Add content
-	if len(respData.Body.Examples) > 0 || len(respData.Body.Types) > 0 {
-		contentType := respData.ContentType
-		if contentType == "" {
-			contentType = "application/json"
+	// Add content (downloads are files, whatever their media type)
+	if respData.Download != nil {
+		resp.Content = g.downloadContent(g.mediaTypeKeys(respData.ContentType, respData.ContentTypes))
+		addDownloadHeader(&resp, respData.Download)
+		return resp
+	}
+	hasBody := len(respData.Body.Examples) > 0 || len(respData.Body.Types) > 0
+	if hasBody || hasBinaryMediaType(respData.ContentTypes) {
+		var tree *inference.SchemaNode
+		if hasBody {
+			tree = inference.BuildSchemaTree(respData.Body)
 		}
-
-		schema := g.convertSchemaNode(inference.BuildSchemaTree(respData.Body))
-
-		resp.Content = map[string]MediaType{
-			contentType: {Schema: schema},
+		resp.Content = g.mediaTypeContent(g.mediaTypeKeys(respData.ContentType, respData.ContentTypes), tree)
+		for _, mt := range resp.Content {
+			g.multiStatusSchema(mt.Schema, respData.MultiStatus)
 		}
 	}
 
@@ -398,7 +612,7 @@ func (g *Generator) convertSchemaNode(node *inference.SchemaNode) *Schema {
 	// Set examples (OpenAPI 3.1) or example (OpenAPI 3.0)
 	if len(node.Examples) > 0 {
 		if g.options.Version == Version31 {
-			schema.Examples = node.Examples
+			schema.Examples = selectExamples(node.Examples, g.options.ExampleSelection)
 		} else {
 			// OpenAPI 3.0 uses singular example at the schema level
 			// We don't add it here as it's not standard
@@ -425,6 +639,12 @@ func (g *Generator) convertSchemaNode(node *inference.SchemaNode) *Schema {
 	return schema
 }
 
+// OperationID returns the operation ID generated for an endpoint without an
+// explicit one, e.g. "getUsersByUserId" for GET /users/{userId}.
+func OperationID(method, pathTemplate string) string {
+	return generateOperationID(method, pathTemplate)
+}
+
 // generateOperationID creates an operation ID from method and path.
 func generateOperationID(method, path string) string {
 	// Convert path to camelCase
@@ -443,12 +663,20 @@ func generateOperationID(method, path string) string {
 			continue
 		}
 
-		// Handle path parameters
-		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
-			paramName := seg[1 : len(seg)-1]
-			parts = append(parts, "By"+capitalize(paramName))
-		} else {
-			parts = append(parts, capitalize(seg))
+		// Handle path parameters, including matrix parameters after a
+		// literal (items{color})
+		for seg != "" {
+			open := strings.Index(seg, "{")
+			end := strings.Index(seg, "}")
+			if open < 0 || end < open {
+				parts = append(parts, capitalize(seg))
+				break
+			}
+			if open > 0 {
+				parts = append(parts, capitalize(seg[:open]))
+			}
+			parts = append(parts, "By"+capitalize(seg[open+1:end]))
+			seg = seg[end+1:]
 		}
 	}
 
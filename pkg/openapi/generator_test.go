@@ -3,10 +3,12 @@ package openapi
 import (
 	"encoding/json"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
 )
 
 func TestGenerateFromExamples(t *testing.T) {
@@ -65,7 +67,7 @@ func TestGenerateFromExamples(t *testing.T) {
 		// Check path parameter
 		if userPath.Get != nil {
 			hasPathParam := false
-			for _, param := range userPath.Get.Parameters {
+			for _, param := range OperationParameters(userPath, userPath.Get) {
 				if param.In == "path" && param.Name == "id" {
 					hasPathParam = true
 					if !param.Required {
@@ -251,3 +253,194 @@ func TestSchemaConversion(t *testing.T) {
 		t.Error("expected email to have email format")
 	}
 }
+
+func TestGenerateArrayQueryParams(t *testing.T) {
+	result := inference.NewInferenceResult()
+	endpoint := inference.NewEndpointData("GET", "/items")
+	tag := inference.NewParamData("tag")
+	tag.AddArrayValue([]any{"a", "b"}, true)
+	endpoint.QueryParams["tag"] = tag
+	filter := inference.NewParamData("filter")
+	filter.AddObjectValue(map[string]any{"status": "active"})
+	endpoint.QueryParams["filter"] = filter
+	result.Endpoints["GET /items"] = endpoint
+
+	spec := GenerateFromInference(result, DefaultGeneratorOptions())
+	op := spec.Paths["/items"].Get
+	if op == nil {
+		t.Fatal("expected GET /items operation")
+	}
+
+	params := make(map[string]Parameter)
+	for _, p := range op.Parameters {
+		params[p.Name] = p
+	}
+
+	tagParam := params["tag"]
+	if tagParam.Schema == nil || tagParam.Schema.Type != "array" || tagParam.Schema.Items == nil {
+		t.Fatalf("expected array schema for tag, got %+v", tagParam.Schema)
+	}
+	if tagParam.Style != "form" || tagParam.Explode == nil || !*tagParam.Explode {
+		t.Errorf("expected style=form explode=true, got style=%q explode=%v", tagParam.Style, tagParam.Explode)
+	}
+
+	filterParam := params["filter"]
+	if filterParam.Style != "deepObject" {
+		t.Errorf("expected deepObject style, got %q", filterParam.Style)
+	}
+	if filterParam.Schema == nil || filterParam.Schema.Properties["status"] == nil {
+		t.Errorf("expected status property in filter schema, got %+v", filterParam.Schema)
+	}
+}
+
+func TestMetadataExtensions(t *testing.T) {
+	engine := inference.NewEngine(inference.DefaultEngineOptions())
+	for _, env := range []string{"staging", "production", "staging"} {
+		record := ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/users"},
+			Response: ir.Response{Status: 200},
+		}
+		record.SetMeta(ir.MetaEnvironment, env)
+		record.SetMeta(ir.MetaLabel, "session-1")
+		engine.ProcessRecord(&record)
+	}
+
+	opts := DefaultGeneratorOptions()
+	opts.MetadataExtensions = []string{ir.MetaEnvironment, "missing"}
+	spec := GenerateFromInference(engine.Finalize(), opts)
+
+	op := spec.Paths["/users"].Get
+	got, ok := op.Extensions["x-meta-environment"].([]string)
+	if !ok || len(got) != 2 || got[0] != "production" || got[1] != "staging" {
+		t.Errorf("expected sorted environments, got %v", op.Extensions)
+	}
+	if _, ok := op.Extensions["x-meta-label"]; ok {
+		t.Error("expected only requested metadata keys to be emitted")
+	}
+	if _, ok := op.Extensions["x-meta-missing"]; ok {
+		t.Error("expected no extension for unobserved metadata keys")
+	}
+}
+
+func TestConditionalRequestDocs(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPUT, Path: "/items", Headers: map[string]string{"if-match": `"v1"`}},
+			Response: ir.Response{Status: 412, Headers: map[string]string{"etag": `"v2"`}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items"},
+			Response: ir.Response{Status: 200, Headers: map[string]string{"cache-control": "max-age=60"}},
+		},
+	}
+	spec := GenerateFromInference(inference.InferFromRecords(records), DefaultGeneratorOptions())
+	item := spec.Paths["/items"]
+
+	put := item.Put
+	if put.Extensions["x-conditional-requests"] != true {
+		t.Errorf("expected PUT to be flagged for conditional requests, got %v", put.Extensions)
+	}
+	if len(put.Parameters) != 1 || put.Parameters[0].Name != "if-match" || put.Parameters[0].Description == "" {
+		t.Errorf("expected described if-match parameter, got %+v", put.Parameters)
+	}
+	if put.Responses["412"].Headers["etag"].Description == "" {
+		t.Error("expected described etag response header")
+	}
+
+	get := item.Get
+	if _, ok := get.Extensions["x-conditional-requests"]; ok {
+		t.Error("expected GET not to be flagged for conditional requests")
+	}
+	if get.Responses["200"].Headers["cache-control"].Description == "" {
+		t.Error("expected described cache-control response header")
+	}
+}
+
+func TestEarlyHintsExtension(t *testing.T) {
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/page"},
+			Response: ir.Response{Status: 200, Informational: []ir.InformationalResponse{{
+				Status:       103,
+				Headers:      map[string]string{"link": "</style.css>; rel=preload"},
+				HeaderValues: map[string][]string{"link": {"</style.css>; rel=preload", "</app.js>; rel=preload"}},
+			}}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items"},
+			Response: ir.Response{Status: 200},
+		},
+	}
+	spec := GenerateFromInference(inference.InferFromRecords(records), DefaultGeneratorOptions())
+
+	links, ok := spec.Paths["/page"].Get.Extensions["x-early-hints"].([]string)
+	if !ok || len(links) != 2 || links[0] != "</app.js>; rel=preload" {
+		t.Errorf("expected sorted early hint links, got %v", spec.Paths["/page"].Get.Extensions["x-early-hints"])
+	}
+	if _, ok := spec.Paths["/items"].Get.Extensions["x-early-hints"]; ok {
+		t.Error("expected no x-early-hints without 103 responses")
+	}
+}
+
+func TestQueryParamAliasesExtension(t *testing.T) {
+	opts := inference.DefaultEngineOptions()
+	opts.QueryParamAliases = true
+	engine := inference.NewEngine(opts)
+	engine.ProcessRecords([]ir.IRRecord{
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/items", Query: map[string]any{"pageSize": "10"}}, Response: ir.Response{Status: 200}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/items", Query: map[string]any{"page_size": "20"}}, Response: ir.Response{Status: 200}},
+	})
+	spec := GenerateFromInference(engine.Finalize(), DefaultGeneratorOptions())
+
+	params := spec.Paths["/items"].Get.Parameters
+	if len(params) != 1 || params[0].Name != "pageSize" {
+		t.Fatalf("expected one pageSize parameter, got %+v", params)
+	}
+	if aliases := params[0].Extensions["x-aliases"]; !reflect.DeepEqual(aliases, []string{"page_size"}) {
+		t.Errorf("x-aliases = %v", params[0].Extensions["x-aliases"])
+	}
+}
+
+func TestSecurityHeadersNotParameters(t *testing.T) {
+	result := inference.InferFromRecords([]ir.IRRecord{{
+		Request: ir.Request{Method: ir.RequestMethodGET, Path: "/a", Headers: map[string]string{
+			"X-API-Key": "k1", "X-Tenant": "t",
+		}},
+		Response: ir.Response{Status: 200},
+	}})
+	spec := GenerateFromInference(result, DefaultGeneratorOptions())
+
+	if scheme := spec.Components.SecuritySchemes["apiKeyHeader"]; scheme == nil || scheme.Name != "X-API-Key" {
+		t.Fatalf("apiKeyHeader scheme = %+v", scheme)
+	}
+	var names []string
+	for _, param := range spec.Paths["/a"].Get.Parameters {
+		names = append(names, param.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"X-Tenant"}) {
+		t.Errorf("parameters = %v, want the API key left to its security scheme", names)
+	}
+}
+
+func TestSecurityRuleSchemes(t *testing.T) {
+	opts := inference.DefaultEngineOptions()
+	opts.SecurityRules = []inference.SecurityRule{{Key: "signature", In: "query", Name: "sig", Description: "HMAC request signature"}}
+	engine := inference.NewEngine(opts)
+	engine.ProcessRecords([]ir.IRRecord{{
+		Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/a", Query: map[string]any{"sig": "abc"}},
+		Response: ir.Response{Status: 200},
+	}})
+	spec := GenerateFromInference(engine.Finalize(), DefaultGeneratorOptions())
+
+	want := &SecurityScheme{Type: "apiKey", Description: "HMAC request signature", Name: "sig", In: "query"}
+	if got := spec.Components.SecuritySchemes["signature"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("signature scheme = %+v, want %+v", got, want)
+	}
+	op := spec.Paths["/a"].Get
+	if len(op.Parameters) != 0 {
+		t.Errorf("parameters = %+v, want the signature left to its security scheme", op.Parameters)
+	}
+	if !reflect.DeepEqual(op.Security, []SecurityRequirement{{"signature": []string{}}}) {
+		t.Errorf("security = %v", op.Security)
+	}
+}
@@ -0,0 +1,161 @@
+package idl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+)
+
+func testSpec() *openapi.Spec {
+	return &openapi.Spec{
+		OpenAPI: "3.1.0",
+		Info:    openapi.Info{Title: "Pet Store", Version: "2.0.0"},
+		Servers: []openapi.Server{{URL: "https://pets.example.com"}},
+		Paths: map[string]*openapi.PathItem{
+			"/pets/{pet-id}": {
+				Parameters: []openapi.Parameter{
+					{Name: "pet-id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+				},
+				Get: &openapi.Operation{
+					OperationID: "getPet",
+					Description: "Get a pet by ID.",
+					Parameters: []openapi.Parameter{
+						{Name: "X-Request-Id", In: "header", Schema: &openapi.Schema{Type: "string"}},
+						{Name: "fields", In: "query", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}},
+					},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "The pet", Content: map[string]openapi.MediaType{
+							"application/json": {Schema: &openapi.Schema{Ref: "#/components/schemas/Pet"}},
+						}},
+						"404": {Description: "Not found", Content: map[string]openapi.MediaType{
+							"application/json": {Schema: &openapi.Schema{Ref: "#/components/schemas/Error"}},
+						}},
+						"5XX":     {Description: "Server error"},
+						"default": {Description: "Unexpected error"},
+					},
+				},
+				Delete: &openapi.Operation{
+					Responses: map[string]openapi.Response{"204": {Description: "Deleted"}},
+				},
+			},
+		},
+		Components: &openapi.Components{
+			Schemas: map[string]*openapi.Schema{
+				"Pet": {
+					Type:        "object",
+					Description: "A pet.",
+					Required:    []string{"id", "kind"},
+					Properties: map[string]*openapi.Schema{
+						"id":         {Type: "integer", Format: "int64"},
+						"kind":       {Ref: "#/components/schemas/Kind"},
+						"born-at":    {Type: "string", Format: "date-time"},
+						"nickname":   {Type: []string{"string", "null"}},
+						"labels":     {Type: "object", AdditionalProperties: &openapi.Schema{Type: "string"}},
+						"owner":      {Type: "object", Properties: map[string]*openapi.Schema{"name": {Type: "string"}}},
+						"attributes": {OneOf: []*openapi.Schema{{Type: "string"}, {Type: "number"}}},
+					},
+				},
+				"Kind":  {Type: "string", Enum: []any{"dog", "cat", "guinea-pig"}},
+				"Error": {Type: "object", Properties: map[string]*openapi.Schema{"message": {Type: "string"}}},
+			},
+		},
+	}
+}
+
+func TestWriteTypeSpec(t *testing.T) {
+	var out strings.Builder
+	if err := WriteTypeSpec(&out, testSpec(), Options{}); err != nil {
+		t.Fatalf("WriteTypeSpec failed: %v", err)
+	}
+	got := out.String()
+
+	for _, want := range []string{
+		`@service(#{ title: "Pet Store" })`,
+		`@server("https://pets.example.com")`,
+		"namespace PetStore;",
+		"/** A pet. */\nmodel Pet {",
+		"  id: int64;",
+		"  kind: Kind;",
+		"  `born-at`?: utcDateTime;",
+		"  nickname?: string | null;",
+		"  labels?: Record<string>;",
+		"  owner?: PetOwner;",
+		"  attributes?: string | float64;",
+		"model PetOwner {\n  name?: string;\n}",
+		"enum Kind {\n  dog,\n  cat,\n  `guinea-pig`,\n}",
+		"/** Get a pet by ID. */\n@route(\"/pets/{pet-id}\")\n@get\nop getPet(",
+		`  @path("pet-id") petId: int64,`,
+		`  @header("X-Request-Id") xRequestId?: string,`,
+		`  @query fields?: string[]`,
+		"  @statusCode statusCode: 200;\n  @body body: Pet;",
+		"  @statusCode statusCode: 404;\n  @body body: Error;",
+		"  @minValue(500) @maxValue(599) @statusCode statusCode: int32;",
+		"@delete\nop deletePetsPetId(\n  @path(\"pet-id\") petId: int64\n): {\n  @statusCode statusCode: 204;\n};",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("TypeSpec output missing %q\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Unexpected error") {
+		t.Errorf("TypeSpec output includes the default response\n%s", got)
+	}
+}
+
+func TestWriteSmithy(t *testing.T) {
+	var out strings.Builder
+	if err := WriteSmithy(&out, testSpec(), Options{Namespace: "example.pets"}); err != nil {
+		t.Fatalf("WriteSmithy failed: %v", err)
+	}
+	got := out.String()
+
+	for _, want := range []string{
+		"$version: \"2\"\n\nnamespace example.pets\n",
+		"@title(\"Pet Store\")\nservice PetStore {\n    version: \"2.0.0\"\n    operations: [\n        GetPet\n        DeletePetsPetId\n    ]\n}",
+		"@http(method: \"GET\", uri: \"/pets/{petId}\", code: 200)\n@readonly\noperation GetPet {",
+		"        @required\n        @httpLabel\n        petId: Long",
+		"        @httpHeader(\"X-Request-Id\")\n        xRequestId: String",
+		"        @httpQuery(\"fields\")\n        fields: StringList",
+		"    output := {\n        @httpPayload\n        body: Pet\n    }",
+		"    errors: [\n        GetPet404Error\n    ]",
+		"@error(\"client\")\n@httpError(404)\nstructure GetPet404Error {\n    @httpPayload\n    body: Error\n}",
+		"@http(method: \"DELETE\", uri: \"/pets/{petId}\", code: 204)\n@idempotent\noperation DeletePetsPetId {",
+		"/// A pet.\nstructure Pet {",
+		"    @jsonName(\"born-at\")\n    @timestampFormat(\"date-time\")\n    bornAt: Timestamp",
+		"    labels: StringMap",
+		"    attributes: Document",
+		"    @required\n    kind: Kind",
+		"enum Kind {\n    DOG = \"dog\"\n    CAT = \"cat\"\n    GUINEA_PIG = \"guinea-pig\"\n}",
+		"list StringList {\n    member: String\n}",
+		"map StringMap {\n    key: String\n    value: String\n}",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Smithy output missing %q\n%s", want, got)
+		}
+	}
+}
+
+func TestBuildAPIUniqueNames(t *testing.T) {
+	spec := &openapi.Spec{
+		Info: openapi.Info{Title: "Names"},
+		Paths: map[string]*openapi.PathItem{
+			"/users": {Get: &openapi.Operation{
+				OperationID: "GetUsersResponse",
+				Responses: map[string]openapi.Response{"200": {Content: map[string]openapi.MediaType{
+					"application/json": {Schema: &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{"id": {Type: "string"}}}},
+				}}},
+			}},
+		},
+		Components: &openapi.Components{Schemas: map[string]*openapi.Schema{
+			"GetUsersResponse": {Type: "string"},
+		}},
+	}
+
+	a := buildAPI(spec)
+	if got := a.operations[0].name; got != "GetUsersResponse2" {
+		t.Errorf("operation name = %q, want GetUsersResponse2", got)
+	}
+	if got := a.operations[0].responses[0].body.shape.name; got != "GetUsersResponse2Response" {
+		t.Errorf("response shape name = %q, want GetUsersResponse2Response", got)
+	}
+}
@@ -0,0 +1,628 @@
+// Package idl exports OpenAPI specs as TypeSpec and Smithy API definitions,
+// for teams whose source of truth is a modeling language rather than OpenAPI
+// documents.
+package idl
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+)
+
+// Options configures an export.
+type Options struct {
+	// Namespace is the namespace of the definitions. By default it is derived
+	// from the spec title: UsersApi for TypeSpec, usersapi for Smithy.
+	Namespace string
+}
+
+// Neutral scalar types, mapped to each language's built-in types.
+const (
+	scalarString   = "string"
+	scalarInt32    = "int32"
+	scalarInt64    = "int64"
+	scalarFloat64  = "float64"
+	scalarBoolean  = "boolean"
+	scalarDateTime = "date-time"
+	scalarDate     = "date"
+	scalarBytes    = "bytes"
+	scalarAny      = "any"
+)
+
+type shapeKind int
+
+const (
+	kindStructure shapeKind = iota
+	kindEnum
+	kindList
+	kindMap
+	kindUnion
+	kindScalar // named scalar, e.g. a component schema of type string
+)
+
+// shape is a named type: a component schema, or an inline object or enum
+// schema named after where it appears.
+type shape struct {
+	name     string
+	doc      string
+	kind     shapeKind
+	fields   []*field   // kindStructure
+	values   []string   // kindEnum
+	member   *typeRef   // kindList items, kindMap values, kindScalar type
+	variants []*typeRef // kindUnion
+}
+
+// field is a structure member.
+type field struct {
+	name     string // JSON property name
+	doc      string
+	required bool
+	typ      *typeRef
+}
+
+// typeRef is the type of a field, parameter, or body: a scalar, a named
+// shape, or an inline list, map, or union.
+type typeRef struct {
+	scalar   string
+	shape    *shape
+	list     *typeRef
+	mapOf    *typeRef
+	union    []*typeRef
+	nullable bool
+}
+
+// api is the language-neutral model rendered by both exporters.
+type api struct {
+	title      string
+	version    string
+	doc        string
+	server     string
+	operations []*operation
+	shapes     []*shape
+	names      map[string]bool // shape and operation names in use
+}
+
+type operation struct {
+	name         string // PascalCase; renderers adjust the case
+	method       string // upper-case HTTP method
+	path         string
+	summary      string
+	doc          string
+	deprecated   bool
+	params       []*param
+	body         *typeRef
+	bodyRequired bool
+	contentType  string
+	responses    []*response
+}
+
+type param struct {
+	name     string
+	in       string // path, query, or header
+	doc      string
+	required bool
+	typ      *typeRef
+}
+
+type response struct {
+	status      string // status code, range such as 2XX, or default
+	doc         string
+	body        *typeRef
+	contentType string
+}
+
+// code returns the response's numeric status code, or 0 for ranges and
+// default.
+func (r *response) code() int {
+	code, err := strconv.Atoi(r.status)
+	if err != nil {
+		return 0
+	}
+	return code
+}
+
+// builder converts a spec to the neutral model.
+type builder struct {
+	spec       *openapi.Spec
+	api        *api
+	components map[string]*shape
+}
+
+// buildAPI converts a spec to the neutral model. Component schemas become
+// shapes of the same name; inline object and enum schemas become shapes named
+// after their operation or parent, such as GetUserResponse or UserAddress.
+func buildAPI(spec *openapi.Spec) *api {
+	b := &builder{
+		spec: spec,
+		api: &api{
+			title:   spec.Info.Title,
+			version: spec.Info.Version,
+			doc:     spec.Info.Description,
+			names:   make(map[string]bool),
+		},
+		components: make(map[string]*shape),
+	}
+	if len(spec.Servers) > 0 {
+		b.api.server = spec.Servers[0].URL
+	}
+
+	var schemas map[string]*openapi.Schema
+	if spec.Components != nil {
+		schemas = spec.Components.Schemas
+	}
+	names := sortedKeys(schemas)
+	for _, name := range names {
+		b.components[name] = b.newShape(pascalCase(name))
+	}
+	for _, name := range names {
+		b.fillShape(b.components[name], schemas[name])
+	}
+
+	for _, path := range sortedKeys(spec.Paths) {
+		item := spec.Paths[path]
+		if item == nil {
+			continue
+		}
+		for _, mo := range pathItemOperations(item) {
+			b.api.operations = append(b.api.operations, b.operation(path, mo.method, item, mo.op))
+		}
+	}
+	return b.api
+}
+
+// newShape adds a shape with a name not yet in use.
+func (b *builder) newShape(name string) *shape {
+	s := &shape{name: b.uniqueName(name)}
+	b.api.shapes = append(b.api.shapes, s)
+	return s
+}
+
+func (b *builder) uniqueName(name string) string {
+	unique := name
+	for i := 2; b.api.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	b.api.names[unique] = true
+	return unique
+}
+
+// fillShape defines a named shape from its schema.
+func (b *builder) fillShape(s *shape, schema *openapi.Schema) {
+	if schema == nil {
+		s.kind, s.member = kindScalar, &typeRef{scalar: scalarAny}
+		return
+	}
+	s.doc = schema.Description
+
+	switch {
+	case schema.Ref != "" || len(schema.OneOf) > 0 || len(schema.AnyOf) > 0:
+		ref := b.typeOf(schema, s.name)
+		if len(ref.union) > 0 {
+			s.kind, s.variants = kindUnion, ref.union
+		} else {
+			s.kind, s.member = kindScalar, ref
+		}
+	case isObject(schema) && (len(schema.Properties) > 0 || len(schema.AllOf) > 0 || additionalSchema(schema) == nil):
+		s.kind = kindStructure
+		b.fillFields(s, schema)
+	case isObject(schema):
+		s.kind, s.member = kindMap, b.typeOf(additionalSchema(schema), s.name+"Value")
+	case stringEnum(schema) != nil:
+		s.kind, s.values = kindEnum, stringEnum(schema)
+	case schemaType(schema) == "array":
+		s.kind, s.member = kindList, b.typeOf(schema.Items, s.name+"Item")
+	default:
+		s.kind, s.member = kindScalar, b.typeOf(schema, s.name)
+	}
+}
+
+// fillFields adds the properties of an object schema, including those of
+// its allOf members, to a structure.
+func (b *builder) fillFields(s *shape, schema *openapi.Schema) {
+	properties := make(map[string]*openapi.Schema)
+	required := make(map[string]bool)
+	b.collectProperties(schema, properties, required, 0)
+
+	for _, name := range sortedKeys(properties) {
+		prop := properties[name]
+		f := &field{name: name, required: required[name], typ: b.typeOf(prop, s.name+pascalCase(name))}
+		if prop != nil {
+			f.doc = prop.Description
+		}
+		s.fields = append(s.fields, f)
+	}
+}
+
+func (b *builder) collectProperties(schema *openapi.Schema, properties map[string]*openapi.Schema, required map[string]bool, depth int) {
+	if schema == nil || depth > 8 {
+		return
+	}
+	if schema.Ref != "" {
+		b.collectProperties(b.componentSchema(schema.Ref), properties, required, depth+1)
+		return
+	}
+	for _, member := range schema.AllOf {
+		b.collectProperties(member, properties, required, depth+1)
+	}
+	for name, prop := range schema.Properties {
+		properties[name] = prop
+	}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+}
+
+// componentSchema returns the component schema a $ref points to.
+func (b *builder) componentSchema(ref string) *openapi.Schema {
+	name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+	if !ok || b.spec.Components == nil {
+		return nil
+	}
+	return b.spec.Components.Schemas[name]
+}
+
+// typeOf returns the type of a schema, adding a shape named hint for an
+// inline object or enum.
+func (b *builder) typeOf(schema *openapi.Schema, hint string) *typeRef {
+	if schema == nil {
+		return &typeRef{scalar: scalarAny}
+	}
+	if schema.Ref != "" {
+		name, _ := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		if s, ok := b.components[name]; ok {
+			return &typeRef{shape: s}
+		}
+		return &typeRef{scalar: scalarAny}
+	}
+
+	ref := b.valueType(schema, hint)
+	ref.nullable = schema.Nullable || slices.Contains(schemaTypes(schema), "null")
+	return ref
+}
+
+func (b *builder) valueType(schema *openapi.Schema, hint string) *typeRef {
+	if len(schema.AllOf) == 1 && len(schema.Properties) == 0 {
+		return b.typeOf(schema.AllOf[0], hint)
+	}
+	if variants := append(slices.Clone(schema.OneOf), schema.AnyOf...); len(variants) > 0 {
+		ref := &typeRef{}
+		for i, variant := range variants {
+			ref.union = append(ref.union, b.typeOf(variant, hint+"Option"+strconv.Itoa(i+1)))
+		}
+		return ref
+	}
+
+	switch {
+	case isObject(schema) && (len(schema.Properties) > 0 || len(schema.AllOf) > 0):
+		s := b.newShape(hint)
+		s.kind = kindStructure
+		s.doc = schema.Description
+		b.fillFields(s, schema)
+		return &typeRef{shape: s}
+	case isObject(schema):
+		return &typeRef{mapOf: b.typeOf(additionalSchema(schema), hint+"Value")}
+	case stringEnum(schema) != nil:
+		s := b.newShape(hint)
+		s.kind, s.values, s.doc = kindEnum, stringEnum(schema), schema.Description
+		return &typeRef{shape: s}
+	}
+
+	switch schemaType(schema) {
+	case "array":
+		return &typeRef{list: b.typeOf(schema.Items, hint+"Item")}
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return &typeRef{scalar: scalarDateTime}
+		case "date":
+			return &typeRef{scalar: scalarDate}
+		case "byte", "binary":
+			return &typeRef{scalar: scalarBytes}
+		}
+		return &typeRef{scalar: scalarString}
+	case "integer":
+		if schema.Format == "int32" {
+			return &typeRef{scalar: scalarInt32}
+		}
+		return &typeRef{scalar: scalarInt64}
+	case "number":
+		return &typeRef{scalar: scalarFloat64}
+	case "boolean":
+		return &typeRef{scalar: scalarBoolean}
+	}
+	return &typeRef{scalar: scalarAny}
+}
+
+func (b *builder) operation(path, method string, item *openapi.PathItem, op *openapi.Operation) *operation {
+	name := pascalCase(op.OperationID)
+	if name == "" {
+		name = pascalCase(strings.ToLower(method) + " " + strings.NewReplacer("{", "", "}", "").Replace(path))
+	}
+	o := &operation{
+		name:       b.uniqueName(name),
+		method:     method,
+		path:       path,
+		summary:    op.Summary,
+		doc:        op.Description,
+		deprecated: op.Deprecated,
+	}
+
+	// Operation parameters override path item parameters of the same name
+	var params []openapi.Parameter
+	seen := make(map[string]int)
+	for _, p := range append(slices.Clone(item.Parameters), op.Parameters...) {
+		p = b.resolveParameter(p)
+		key := p.In + " " + p.Name
+		if i, ok := seen[key]; ok {
+			params[i] = p
+			continue
+		}
+		seen[key] = len(params)
+		params = append(params, p)
+	}
+	for _, p := range params {
+		if p.In != "path" && p.In != "query" && p.In != "header" {
+			continue
+		}
+		schema := p.Schema
+		if schema == nil {
+			if _, mt, ok := preferredContent(p.Content); ok {
+				schema = mt.Schema
+			}
+		}
+		o.params = append(o.params, &param{
+			name:     p.Name,
+			in:       p.In,
+			doc:      p.Description,
+			required: p.Required || p.In == "path",
+			typ:      b.typeOf(schema, o.name+pascalCase(p.Name)),
+		})
+	}
+
+	if body := b.resolveRequestBody(op.RequestBody); body != nil {
+		if contentType, mt, ok := preferredContent(body.Content); ok {
+			o.body = b.typeOf(mt.Schema, o.name+"Request")
+			o.bodyRequired = body.Required
+			o.contentType = contentType
+		}
+	}
+
+	statuses := sortedStatuses(op.Responses)
+	success := ""
+	for _, status := range statuses {
+		if strings.HasPrefix(status, "2") {
+			success = status
+			break
+		}
+	}
+	for _, status := range statuses {
+		resp := b.resolveResponse(op.Responses[status])
+		r := &response{status: status, doc: resp.Description}
+		if contentType, mt, ok := preferredContent(resp.Content); ok {
+			hint := o.name + "Response"
+			if status != success {
+				hint = o.name + pascalCase(status) + "Response"
+			}
+			r.body = b.typeOf(mt.Schema, hint)
+			r.contentType = contentType
+		}
+		o.responses = append(o.responses, r)
+	}
+	return o
+}
+
+func (b *builder) resolveParameter(p openapi.Parameter) openapi.Parameter {
+	name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	if ok && b.spec.Components != nil && b.spec.Components.Parameters[name] != nil {
+		return *b.spec.Components.Parameters[name]
+	}
+	return p
+}
+
+func (b *builder) resolveRequestBody(body *openapi.RequestBody) *openapi.RequestBody {
+	if body == nil {
+		return nil
+	}
+	name, ok := strings.CutPrefix(body.Ref, "#/components/requestBodies/")
+	if ok && b.spec.Components != nil && b.spec.Components.RequestBodies[name] != nil {
+		return b.spec.Components.RequestBodies[name]
+	}
+	return body
+}
+
+func (b *builder) resolveResponse(resp openapi.Response) openapi.Response {
+	name, ok := strings.CutPrefix(resp.Ref, "#/components/responses/")
+	if ok && b.spec.Components != nil && b.spec.Components.Responses[name] != nil {
+		return *b.spec.Components.Responses[name]
+	}
+	return resp
+}
+
+// preferredContent returns the JSON media type of a content map, or else
+// the first by name.
+func preferredContent(content map[string]openapi.MediaType) (string, openapi.MediaType, bool) {
+	if len(content) == 0 {
+		return "", openapi.MediaType{}, false
+	}
+	keys := sortedKeys(content)
+	for _, key := range keys {
+		if key == "application/json" || strings.HasSuffix(key, "+json") {
+			return key, content[key], true
+		}
+	}
+	return keys[0], content[keys[0]], true
+}
+
+// sortedStatuses returns response codes in numeric order, then ranges such
+// as 4XX, then default.
+func sortedStatuses(responses map[string]openapi.Response) []string {
+	statuses := sortedKeys(responses)
+	rank := func(status string) int {
+		switch {
+		case status == "default":
+			return 2
+		case strings.HasSuffix(strings.ToUpper(status), "XX"):
+			return 1
+		}
+		return 0
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return rank(statuses[i]) < rank(statuses[j]) })
+	return statuses
+}
+
+type methodOperation struct {
+	method string
+	op     *openapi.Operation
+}
+
+// pathItemOperations returns a path item's operations in a stable method order.
+func pathItemOperations(item *openapi.PathItem) []methodOperation {
+	all := []methodOperation{
+		{"GET", item.Get}, {"POST", item.Post}, {"PUT", item.Put}, {"PATCH", item.Patch},
+		{"DELETE", item.Delete}, {"HEAD", item.Head}, {"OPTIONS", item.Options},
+	}
+	ops := make([]methodOperation, 0, len(all))
+	for _, mo := range all {
+		if mo.op != nil {
+			ops = append(ops, mo)
+		}
+	}
+	return ops
+}
+
+// schemaTypes returns the types a schema declares.
+func schemaTypes(schema *openapi.Schema) []string {
+	switch t := schema.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// schemaType returns a schema's non-null type.
+func schemaType(schema *openapi.Schema) string {
+	for _, t := range schemaTypes(schema) {
+		if t != "null" {
+			return t
+		}
+	}
+	return ""
+}
+
+func isObject(schema *openapi.Schema) bool {
+	t := schemaType(schema)
+	return t == "object" || (t == "" && (len(schema.Properties) > 0 || len(schema.AllOf) > 0 || schema.AdditionalProperties != nil))
+}
+
+// additionalSchema returns the schema of additionalProperties, or nil if it
+// isn't a schema.
+func additionalSchema(schema *openapi.Schema) *openapi.Schema {
+	switch v := schema.AdditionalProperties.(type) {
+	case *openapi.Schema:
+		return v
+	case map[string]any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var s openapi.Schema
+		if json.Unmarshal(data, &s) != nil {
+			return nil
+		}
+		return &s
+	}
+	return nil
+}
+
+// stringEnum returns the values of a string enum schema, or nil if the
+// schema isn't one.
+func stringEnum(schema *openapi.Schema) []string {
+	if len(schema.Enum) == 0 || (schemaType(schema) != "string" && schemaType(schema) != "") {
+		return nil
+	}
+	values := make([]string, 0, len(schema.Enum))
+	for _, v := range schema.Enum {
+		s, ok := v.(string)
+		if !ok {
+			return nil
+		}
+		values = append(values, s)
+	}
+	return values
+}
+
+// pascalCase converts a name such as "get-user_by id" to GetUserById. Names
+// starting with a digit get a leading underscore.
+func pascalCase(s string) string {
+	var out strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		out.WriteRune(r)
+	}
+	name := out.String()
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// camelCase converts a name to camelCase.
+func camelCase(s string) string {
+	name := pascalCase(s)
+	if name == "" || name[0] == '_' {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// isIdentifier reports whether s is a valid TypeSpec and Smithy identifier.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r > unicode.MaxASCII || !(r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
+
+// quote returns s as a double-quoted string literal.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(s) + `"`
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
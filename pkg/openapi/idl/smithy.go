@@ -0,0 +1,371 @@
+package idl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+)
+
+// smithyScalars maps neutral scalars to Smithy prelude shapes.
+var smithyScalars = map[string]string{
+	scalarString:   "String",
+	scalarInt32:    "Integer",
+	scalarInt64:    "Long",
+	scalarFloat64:  "Double",
+	scalarBoolean:  "Boolean",
+	scalarDateTime: "Timestamp",
+	scalarDate:     "String",
+	scalarBytes:    "Blob",
+	scalarAny:      "Document",
+}
+
+// WriteSmithy writes a spec as a Smithy 2.0 IDL model: a service shape
+// listing an operation per spec operation, with @http bindings, and a shape
+// per schema. Each operation's output is its first success response; 4xx and
+// 5xx responses become @error structures. Smithy has no unions of arbitrary
+// shapes, so oneOf and anyOf schemas, and payloads that aren't structures,
+// become documents.
+func WriteSmithy(w io.Writer, spec *openapi.Spec, opts Options) error {
+	a := buildAPI(spec)
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = strings.ToLower(pascalCase(a.title))
+		namespace = strings.TrimLeft(namespace, "_")
+	}
+	if namespace == "" {
+		namespace = "api"
+	}
+	service := pascalCase(a.title)
+	if service == "" || a.names[service] {
+		service += "Service"
+	}
+	service = uniqueSmithyName(a, service)
+	version := a.version
+	if version == "" {
+		version = "1.0.0"
+	}
+
+	r := &smithyRenderer{api: a, collections: make(map[string]string)}
+	for _, op := range a.operations {
+		r.writeOperation(op)
+	}
+	for _, s := range a.shapes {
+		r.writeShape(s)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "$version: \"2\"\n\nnamespace %s\n\n", namespace)
+	smithyDoc(&out, "", a.doc)
+	if a.title != "" {
+		fmt.Fprintf(&out, "@title(%s)\n", quote(a.title))
+	}
+	fmt.Fprintf(&out, "service %s {\n    version: %s\n", service, quote(version))
+	if len(a.operations) > 0 {
+		out.WriteString("    operations: [\n")
+		for _, op := range a.operations {
+			fmt.Fprintf(&out, "        %s\n", op.name)
+		}
+		out.WriteString("    ]\n")
+	}
+	out.WriteString("}\n")
+	out.WriteString(r.out.String())
+	out.WriteString(r.collectionShapes.String())
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// smithyRenderer writes shapes, adding list and map shapes for the
+// collections they use.
+type smithyRenderer struct {
+	api              *api
+	out              strings.Builder
+	collectionShapes strings.Builder
+	collections      map[string]string // collection name → shape name
+}
+
+func (r *smithyRenderer) writeOperation(op *operation) {
+	out := &r.out
+	out.WriteString("\n")
+	smithyDoc(out, "", op.doc)
+	if op.summary != "" && op.doc == "" {
+		smithyDoc(out, "", op.summary)
+	}
+	if op.deprecated {
+		out.WriteString("@deprecated\n")
+	}
+
+	var success *response
+	var errors []*response
+	for _, resp := range op.responses {
+		switch code := resp.code(); {
+		case code >= 200 && code < 300 && success == nil:
+			success = resp
+		case code >= 400 && code < 600:
+			errors = append(errors, resp)
+		}
+	}
+	code := 200
+	if success != nil {
+		code = success.code()
+	}
+	// Labels bind to members of the same name, so rename labels that aren't
+	// identifiers
+	uri := op.path
+	for _, p := range op.params {
+		if p.in == "path" {
+			uri = strings.ReplaceAll(uri, "{"+p.name+"}", "{"+smithyMemberName(p.name)+"}")
+		}
+	}
+	fmt.Fprintf(out, "@http(method: %s, uri: %s, code: %d)\n", quote(op.method), quote(uri), code)
+	switch op.method {
+	case "GET", "HEAD":
+		out.WriteString("@readonly\n")
+	case "PUT", "DELETE":
+		out.WriteString("@idempotent\n")
+	}
+	fmt.Fprintf(out, "operation %s {\n", op.name)
+
+	out.WriteString("    input := {")
+	var members []string
+	for _, p := range op.params {
+		var m strings.Builder
+		smithyDoc(&m, "        ", p.doc)
+		if p.required {
+			m.WriteString("        @required\n")
+		}
+		switch p.in {
+		case "path":
+			m.WriteString("        @httpLabel\n")
+		case "query":
+			fmt.Fprintf(&m, "        @httpQuery(%s)\n", quote(p.name))
+		case "header":
+			fmt.Fprintf(&m, "        @httpHeader(%s)\n", quote(p.name))
+		}
+		fmt.Fprintf(&m, "        %s: %s\n", smithyMemberName(p.name), r.target(p.typ))
+		members = append(members, m.String())
+	}
+	if op.body != nil {
+		members = append(members, r.payloadMember(op.body, op.bodyRequired, "        "))
+	}
+	writeSmithyMembers(out, members, "    ")
+
+	out.WriteString("    output := {")
+	members = nil
+	if success != nil && success.body != nil {
+		members = append(members, r.payloadMember(success.body, false, "        "))
+	}
+	writeSmithyMembers(out, members, "    ")
+
+	if len(errors) > 0 {
+		out.WriteString("    errors: [\n")
+		for _, resp := range errors {
+			fmt.Fprintf(out, "        %s\n", smithyErrorName(op, resp))
+		}
+		out.WriteString("    ]\n")
+	}
+	out.WriteString("}\n")
+
+	for _, resp := range errors {
+		out.WriteString("\n")
+		smithyDoc(out, "", resp.doc)
+		fault := "client"
+		if resp.code() >= 500 {
+			fault = "server"
+		}
+		fmt.Fprintf(out, "@error(%s)\n@httpError(%d)\nstructure %s {", quote(fault), resp.code(), smithyErrorName(op, resp))
+		members = nil
+		if resp.body != nil {
+			members = append(members, r.payloadMember(resp.body, false, "    "))
+		}
+		writeSmithyMembers(out, members, "")
+	}
+}
+
+// smithyErrorName returns the name of the error structure of a response,
+// such as GetUser404Error.
+func smithyErrorName(op *operation, resp *response) string {
+	return op.name + resp.status + "Error"
+}
+
+// payloadMember returns an @httpPayload member for a body. Payloads must
+// target a structure, string, blob, or document, so other types become
+// documents.
+func (r *smithyRenderer) payloadMember(body *typeRef, required bool, indent string) string {
+	target := "Document"
+	switch {
+	case body.shape != nil && (body.shape.kind == kindStructure || body.shape.kind == kindEnum):
+		target = body.shape.name
+	case body.scalar == scalarString || body.scalar == scalarBytes:
+		target = smithyScalars[body.scalar]
+	}
+	var m strings.Builder
+	if required {
+		m.WriteString(indent + "@required\n")
+	}
+	fmt.Fprintf(&m, "%s@httpPayload\n%sbody: %s\n", indent, indent, target)
+	return m.String()
+}
+
+func (r *smithyRenderer) writeShape(s *shape) {
+	out := &r.out
+	out.WriteString("\n")
+	smithyDoc(out, "", s.doc)
+	switch s.kind {
+	case kindStructure:
+		fmt.Fprintf(out, "structure %s {", s.name)
+		members := make([]string, 0, len(s.fields))
+		for _, f := range s.fields {
+			var m strings.Builder
+			smithyDoc(&m, "    ", f.doc)
+			if f.required {
+				m.WriteString("    @required\n")
+			}
+			name := smithyMemberName(f.name)
+			if name != f.name {
+				fmt.Fprintf(&m, "    @jsonName(%s)\n", quote(f.name))
+			}
+			if f.typ.scalar == scalarDateTime {
+				m.WriteString("    @timestampFormat(\"date-time\")\n")
+			}
+			fmt.Fprintf(&m, "    %s: %s\n", name, r.target(f.typ))
+			members = append(members, m.String())
+		}
+		writeSmithyMembers(out, members, "")
+	case kindEnum:
+		fmt.Fprintf(out, "enum %s {\n", s.name)
+		seen := make(map[string]bool)
+		for _, value := range s.values {
+			name := smithyEnumName(value)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			fmt.Fprintf(out, "    %s = %s\n", name, quote(value))
+		}
+		out.WriteString("}\n")
+	case kindList:
+		fmt.Fprintf(out, "list %s {\n    member: %s\n}\n", s.name, r.target(s.member))
+	case kindMap:
+		fmt.Fprintf(out, "map %s {\n    key: String\n    value: %s\n}\n", s.name, r.target(s.member))
+	case kindUnion:
+		variants := make([]string, len(s.variants))
+		for i, variant := range s.variants {
+			variants[i] = r.target(variant)
+		}
+		if s.doc == "" {
+			smithyDoc(out, "", "One of: "+strings.Join(variants, ", "))
+		}
+		fmt.Fprintf(out, "document %s\n", s.name)
+	default:
+		target := r.target(s.member)
+		if s.member.scalar == "" || s.member.scalar == scalarAny {
+			fmt.Fprintf(out, "document %s\n", s.name)
+			return
+		}
+		if s.member.scalar == scalarDateTime {
+			out.WriteString("@timestampFormat(\"date-time\")\n")
+		}
+		fmt.Fprintf(out, "%s %s\n", strings.ToLower(target), s.name)
+	}
+}
+
+// target returns the shape ID a member of a type targets, adding list and
+// map shapes as needed.
+func (r *smithyRenderer) target(t *typeRef) string {
+	switch {
+	case t.shape != nil:
+		return t.shape.name
+	case t.list != nil:
+		member := r.target(t.list)
+		return r.collection(member+"List", fmt.Sprintf("list %%s {\n    member: %s\n}\n", member))
+	case t.mapOf != nil:
+		value := r.target(t.mapOf)
+		return r.collection(value+"Map", fmt.Sprintf("map %%s {\n    key: String\n    value: %s\n}\n", value))
+	case len(t.union) > 0:
+		return "Document"
+	}
+	return smithyScalars[t.scalar]
+}
+
+// collection adds a list or map shape the first time it is used. shape is
+// a format string for the shape's definition given its name.
+func (r *smithyRenderer) collection(name, shape string) string {
+	if unique, ok := r.collections[name]; ok {
+		return unique
+	}
+	unique := uniqueSmithyName(r.api, name)
+	r.collections[name] = unique
+	r.collectionShapes.WriteString("\n" + fmt.Sprintf(shape, unique))
+	return unique
+}
+
+func uniqueSmithyName(a *api, name string) string {
+	unique := name
+	for i := 2; a.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	a.names[unique] = true
+	return unique
+}
+
+// writeSmithyMembers writes members separated by blank lines and closes
+// the brace opened by the caller, indented by indent.
+func writeSmithyMembers(out *strings.Builder, members []string, indent string) {
+	if len(members) == 0 {
+		out.WriteString("}\n")
+		return
+	}
+	out.WriteString("\n")
+	for i, m := range members {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(m)
+	}
+	out.WriteString(indent + "}\n")
+}
+
+// smithyMemberName returns a JSON property name as an identifier.
+func smithyMemberName(name string) string {
+	if isIdentifier(name) {
+		return name
+	}
+	if member := camelCase(name); member != "" {
+		return member
+	}
+	return "_member"
+}
+
+// smithyEnumName returns an enum value as an upper snake case name, such as
+// IN_PROGRESS for in-progress.
+func smithyEnumName(value string) string {
+	var out strings.Builder
+	for _, r := range value {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			out.WriteRune(unicode.ToUpper(r))
+		case out.Len() > 0 && !strings.HasSuffix(out.String(), "_"):
+			out.WriteByte('_')
+		}
+	}
+	name := strings.TrimRight(out.String(), "_")
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// smithyDoc writes a documentation comment.
+func smithyDoc(out *strings.Builder, indent, doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		fmt.Fprintf(out, "%s/// %s\n", indent, strings.TrimRight(line, " "))
+	}
+}
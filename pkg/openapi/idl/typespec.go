@@ -0,0 +1,243 @@
+package idl
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+)
+
+// typeSpecKeywords are reserved words that must be escaped with backticks
+// when used as names.
+var typeSpecKeywords = []string{
+	"alias", "dec", "else", "enum", "extends", "extern", "false", "fn", "if", "import",
+	"interface", "is", "model", "namespace", "never", "null", "op", "projection",
+	"return", "scalar", "true", "typeof", "union", "unknown", "using", "valueof", "void",
+}
+
+// typeSpecScalars maps neutral scalars to TypeSpec built-in types.
+var typeSpecScalars = map[string]string{
+	scalarString:   "string",
+	scalarInt32:    "int32",
+	scalarInt64:    "int64",
+	scalarFloat64:  "float64",
+	scalarBoolean:  "boolean",
+	scalarDateTime: "utcDateTime",
+	scalarDate:     "plainDate",
+	scalarBytes:    "bytes",
+	scalarAny:      "unknown",
+}
+
+// WriteTypeSpec writes a spec as a TypeSpec definition using the
+// @typespec/http library: a model, enum, or union per schema and an op per
+// operation, with its parameters and a response union by status code.
+// Responses for status ranges are constrained with @minValue and @maxValue;
+// default responses are left out.
+func WriteTypeSpec(w io.Writer, spec *openapi.Spec, opts Options) error {
+	a := buildAPI(spec)
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = pascalCase(a.title)
+	}
+	if namespace == "" {
+		namespace = "Api"
+	}
+
+	var out strings.Builder
+	out.WriteString("import \"@typespec/http\";\n\nusing Http;\n\n")
+	typeSpecDoc(&out, "", a.doc)
+	fmt.Fprintf(&out, "@service(#{ title: %s })\n", quote(a.title))
+	if a.server != "" {
+		fmt.Fprintf(&out, "@server(%s)\n", quote(a.server))
+	}
+	fmt.Fprintf(&out, "namespace %s;\n", namespace)
+
+	for _, s := range a.shapes {
+		out.WriteString("\n")
+		writeTypeSpecShape(&out, s)
+	}
+	for _, op := range a.operations {
+		out.WriteString("\n")
+		writeTypeSpecOperation(&out, op)
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func writeTypeSpecShape(out *strings.Builder, s *shape) {
+	typeSpecDoc(out, "", s.doc)
+	switch s.kind {
+	case kindStructure:
+		fmt.Fprintf(out, "model %s {\n", s.name)
+		for _, f := range s.fields {
+			typeSpecDoc(out, "  ", f.doc)
+			optional := "?"
+			if f.required {
+				optional = ""
+			}
+			fmt.Fprintf(out, "  %s%s: %s;\n", typeSpecName(f.name), optional, typeSpecType(f.typ))
+		}
+		out.WriteString("}\n")
+	case kindEnum:
+		fmt.Fprintf(out, "enum %s {\n", s.name)
+		for _, value := range s.values {
+			fmt.Fprintf(out, "  %s,\n", typeSpecName(value))
+		}
+		out.WriteString("}\n")
+	case kindUnion:
+		fmt.Fprintf(out, "union %s {\n", s.name)
+		for _, variant := range s.variants {
+			fmt.Fprintf(out, "  %s,\n", typeSpecType(variant))
+		}
+		out.WriteString("}\n")
+	case kindList:
+		fmt.Fprintf(out, "model %s is Array<%s>;\n", s.name, typeSpecType(s.member))
+	case kindMap:
+		fmt.Fprintf(out, "model %s is Record<%s>;\n", s.name, typeSpecType(s.member))
+	default:
+		if s.member.scalar != "" && !s.member.nullable {
+			fmt.Fprintf(out, "scalar %s extends %s;\n", s.name, typeSpecScalars[s.member.scalar])
+		} else {
+			fmt.Fprintf(out, "alias %s = %s;\n", s.name, typeSpecType(s.member))
+		}
+	}
+}
+
+func writeTypeSpecOperation(out *strings.Builder, op *operation) {
+	typeSpecDoc(out, "", op.doc)
+	if op.summary != "" {
+		fmt.Fprintf(out, "@summary(%s)\n", quote(op.summary))
+	}
+	if op.deprecated {
+		out.WriteString("#deprecated \"deprecated\"\n")
+	}
+	fmt.Fprintf(out, "@route(%s)\n@%s\n", quote(op.path), strings.ToLower(op.method))
+
+	var params []string
+	for _, p := range op.params {
+		name := camelCase(p.name)
+		decorator := "@" + p.in
+		if name != p.name {
+			decorator += "(" + quote(p.name) + ")"
+		}
+		optional := "?"
+		if p.required {
+			optional = ""
+		}
+		params = append(params, fmt.Sprintf("%s %s%s: %s", decorator, typeSpecName(name), optional, typeSpecType(p.typ)))
+	}
+	if op.body != nil {
+		if !isJSON(op.contentType) {
+			params = append(params, "@header contentType: "+quote(op.contentType))
+		}
+		optional := "?"
+		if op.bodyRequired {
+			optional = ""
+		}
+		params = append(params, fmt.Sprintf("@body body%s: %s", optional, typeSpecType(op.body)))
+	}
+
+	fmt.Fprintf(out, "op %s(", typeSpecName(camelCase(op.name)))
+	if len(params) > 0 {
+		out.WriteString("\n")
+		for i, p := range params {
+			separator := ","
+			if i == len(params)-1 {
+				separator = ""
+			}
+			fmt.Fprintf(out, "  %s%s\n", p, separator)
+		}
+	}
+	out.WriteString("): ")
+
+	var responses []string
+	for _, r := range op.responses {
+		if r.status == "default" {
+			continue
+		}
+		var body strings.Builder
+		body.WriteString("{\n")
+		if code := r.code(); code > 0 {
+			fmt.Fprintf(&body, "  @statusCode statusCode: %d;\n", code)
+		} else {
+			low := int(r.status[0]-'0') * 100
+			fmt.Fprintf(&body, "  @minValue(%d) @maxValue(%d) @statusCode statusCode: int32;\n", low, low+99)
+		}
+		if r.body != nil {
+			if !isJSON(r.contentType) {
+				fmt.Fprintf(&body, "  @header contentType: %s;\n", quote(r.contentType))
+			}
+			fmt.Fprintf(&body, "  @body body: %s;\n", typeSpecType(r.body))
+		}
+		body.WriteString("}")
+		responses = append(responses, body.String())
+	}
+	if len(responses) == 0 {
+		out.WriteString("void;\n")
+		return
+	}
+	out.WriteString(strings.Join(responses, " | ") + ";\n")
+}
+
+// typeSpecType returns the TypeSpec type expression of a type.
+func typeSpecType(t *typeRef) string {
+	var expr string
+	switch {
+	case t.shape != nil:
+		expr = t.shape.name
+	case t.list != nil:
+		element := typeSpecType(t.list)
+		if strings.Contains(element, " | ") {
+			element = "(" + element + ")"
+		}
+		expr = element + "[]"
+	case t.mapOf != nil:
+		expr = "Record<" + typeSpecType(t.mapOf) + ">"
+	case len(t.union) > 0:
+		variants := make([]string, len(t.union))
+		for i, variant := range t.union {
+			variants[i] = typeSpecType(variant)
+		}
+		expr = strings.Join(variants, " | ")
+	default:
+		expr = typeSpecScalars[t.scalar]
+	}
+	if t.nullable {
+		expr += " | null"
+	}
+	return expr
+}
+
+// typeSpecName returns a name as an identifier, escaped with backticks if
+// it isn't a plain identifier.
+func typeSpecName(name string) string {
+	if isIdentifier(name) && !slices.Contains(typeSpecKeywords, name) {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
+
+// typeSpecDoc writes a doc comment.
+func typeSpecDoc(out *strings.Builder, indent, doc string) {
+	doc = strings.TrimSpace(strings.ReplaceAll(doc, "*/", "*\\/"))
+	if doc == "" {
+		return
+	}
+	lines := strings.Split(doc, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(out, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(out, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(out, "%s * %s\n", indent, strings.TrimRight(line, " "))
+	}
+	fmt.Fprintf(out, "%s */\n", indent)
+}
+
+func isJSON(contentType string) bool {
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	"strconv"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// addLinks declares the detected links on the producing responses. Links
+// from one response to the same operation are merged into a single link
+// with a parameter per path parameter.
+func addLinks(spec *Spec, result *inference.InferenceResult) {
+	for _, detected := range result.Links {
+		source, target := result.Endpoints[detected.Source], result.Endpoints[detected.Target]
+		if source == nil || target == nil {
+			continue
+		}
+		op := operationForMethod(spec.Paths[source.PathTemplate], source.Method)
+		targetOp := operationForMethod(spec.Paths[target.PathTemplate], target.Method)
+		if op == nil || targetOp == nil || targetOp.OperationID == "" {
+			continue
+		}
+		code := strconv.Itoa(detected.Status)
+		resp, ok := op.Responses[code]
+		if !ok {
+			continue
+		}
+
+		if resp.Links == nil {
+			resp.Links = make(map[string]Link)
+		}
+		name := capitalize(targetOp.OperationID)
+		link := resp.Links[name]
+		link.OperationID = targetOp.OperationID
+		if link.Parameters == nil {
+			link.Parameters = make(map[string]any)
+		}
+		link.Parameters[detected.Parameter] = "$response.body#" + detected.Pointer
+		resp.Links[name] = link
+		op.Responses[code] = resp
+	}
+}
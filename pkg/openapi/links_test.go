@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestResponseLinks(t *testing.T) {
+	opts := inference.DefaultEngineOptions()
+	opts.DetectLinks = true
+	engine := inference.NewEngine(opts)
+	for _, id := range []float64{1234, 5678} {
+		path := fmt.Sprintf("/users/%d", int(id))
+		engine.ProcessRecord(&ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users"},
+			Response: ir.Response{Status: 201, Body: map[string]any{"id": id, "age": float64(30)}},
+		})
+		engine.ProcessRecord(&ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: path},
+			Response: ir.Response{Status: 200, Body: map[string]any{"id": id}},
+		})
+		engine.ProcessRecord(&ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodDELETE, Path: path},
+			Response: ir.Response{Status: 204},
+		})
+	}
+	result := engine.Finalize()
+	if len(result.Links) != 3 {
+		t.Fatalf("expected 3 detected links, got %d", len(result.Links))
+	}
+
+	spec := GenerateFromInference(result, DefaultGeneratorOptions())
+	links := spec.Paths["/users"].Post.Responses["201"].Links
+	if len(links) != 2 {
+		t.Fatalf("expected links to GET and DELETE, got %v", links)
+	}
+	link, ok := links["GetUsersByUserId"]
+	if !ok || link.OperationID != "getUsersByUserId" || link.Parameters["userId"] != "$response.body#/id" {
+		t.Errorf("unexpected link %+v", link)
+	}
+	// GET responses link to DELETE but not to GET itself
+	getLinks := spec.Paths["/users/{userId}"].Get.Responses["200"].Links
+	if _, ok := getLinks["DeleteUsersByUserId"]; !ok || len(getLinks) != 1 {
+		t.Errorf("expected only a DELETE link from GET, got %v", getLinks)
+	}
+
+	if result := inference.InferFromRecords(nil); result.Links != nil {
+		t.Error("expected no links without DetectLinks")
+	}
+}
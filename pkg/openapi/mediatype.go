@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// vendorSubtypePrefixes are the registration trees whose structured-syntax
+// types (e.g., "application/vnd.github+json") CollapseVendorMediaTypes maps
+// to their suffix type. Standard suffix types such as application/problem+json
+// are always kept.
+var vendorSubtypePrefixes = []string{"vnd.", "prs.", "x."}
+
+// normalizeMediaType lower-cases a content type's type and subtype and trims
+// whitespace. Parameters (e.g., charset) are dropped unless keepParameters is
+// set, and vendor types are mapped to their suffix type with collapseVendor.
+func normalizeMediaType(contentType string, keepParameters, collapseVendor bool) string {
+	base, params, hasParams := strings.Cut(contentType, ";")
+	base = strings.ToLower(strings.TrimSpace(base))
+
+	if collapseVendor {
+		if typ, subtype, ok := strings.Cut(base, "/"); ok {
+			if i := strings.LastIndex(subtype, "+"); i != -1 && hasVendorPrefix(subtype) {
+				base = typ + "/" + subtype[i+1:]
+			}
+		}
+	}
+
+	if !keepParameters || !hasParams {
+		return base
+	}
+
+	var parts []string
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || name == "" {
+			continue
+		}
+		parts = append(parts, strings.ToLower(strings.TrimSpace(name))+"="+strings.TrimSpace(value))
+	}
+	if len(parts) == 0 {
+		return base
+	}
+	return base + "; " + strings.Join(parts, "; ")
+}
+
+func hasVendorPrefix(subtype string) bool {
+	for _, prefix := range vendorSubtypePrefixes {
+		if strings.HasPrefix(subtype, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaTypeBase returns a content type without parameters, lower-cased.
+func mediaTypeBase(contentType string) string {
+	return normalizeMediaType(contentType, false, false)
+}
+
+// mediaTypeKeys returns the sorted, distinct media type keys for the observed
+// content types, falling back to primary (or application/json) when none
+// were recorded.
+func (g *Generator) mediaTypeKeys(primary string, observed map[string]int) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(contentType string) {
+		key := normalizeMediaType(contentType, g.options.KeepMediaTypeParameters, g.options.CollapseVendorMediaTypes)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for contentType := range observed {
+		add(contentType)
+	}
+	if len(keys) == 0 {
+		add(primary)
+	}
+	if len(keys) == 0 {
+		add("application/json")
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mediaTypeContent builds the content entries for media type keys. Binary
+// media types get a binary string schema; the others share the inferred body
+// schema and are left out when no body was inferred.
+func (g *Generator) mediaTypeContent(keys []string, tree *inference.SchemaNode) map[string]MediaType {
+	content := make(map[string]MediaType, len(keys))
+	for _, key := range keys {
+		switch {
+		case ir.IsBinaryContentType(key):
+			content[key] = MediaType{Schema: g.binarySchema(key)}
+		case tree != nil:
+			content[key] = MediaType{Schema: g.convertSchemaNode(tree)}
+		}
+	}
+	return content
+}
+
+// binarySchema returns the schema for binary content: a string with format
+// binary, plus contentMediaType for 3.1+.
+func (g *Generator) binarySchema(mediaType string) *Schema {
+	schema := &Schema{Type: "string", Format: "binary"}
+	if g.options.Version != Version30 {
+		schema.ContentMediaType = mediaTypeBase(mediaType)
+	}
+	return schema
+}
+
+// hasBinaryMediaType reports whether any observed content type is binary.
+func hasBinaryMediaType(observed map[string]int) bool {
+	for contentType := range observed {
+		if ir.IsBinaryContentType(contentType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestMediaTypeNormalization(t *testing.T) {
+	engine := inference.NewEngine(inference.DefaultEngineOptions())
+	for _, ct := range []string{"application/json; charset=utf-8", "Application/JSON", "application/vnd.acme.user+json"} {
+		contentType := ct
+		engine.ProcessRecord(&ir.IRRecord{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users"},
+			Response: ir.Response{
+				Status:      200,
+				ContentType: &contentType,
+				Body:        map[string]any{"id": float64(1)},
+			},
+		})
+	}
+	result := engine.Finalize()
+
+	tests := []struct {
+		name string
+		opts func(*GeneratorOptions)
+		want []string
+	}{
+		{"default", func(*GeneratorOptions) {}, []string{"application/json", "application/vnd.acme.user+json"}},
+		{"keep parameters", func(o *GeneratorOptions) { o.KeepMediaTypeParameters = true },
+			[]string{"application/json", "application/json; charset=utf-8", "application/vnd.acme.user+json"}},
+		{"collapse vendor", func(o *GeneratorOptions) { o.CollapseVendorMediaTypes = true }, []string{"application/json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultGeneratorOptions()
+			tt.opts(&opts)
+			content := GenerateFromInference(result, opts).Paths["/users"].Get.Responses["200"].Content
+
+			var got []string
+			for key := range content {
+				got = append(got, key)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected media types %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if got := normalizeMediaType("application/problem+json", false, true); got != "application/problem+json" {
+		t.Errorf("expected standard suffix type to be kept, got %s", got)
+	}
+}
+
+func TestBinaryContent(t *testing.T) {
+	engine := inference.NewEngine(inference.DefaultEngineOptions())
+	png := "image/png"
+	engine.ProcessRecord(&ir.IRRecord{
+		Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/uploads", ContentType: &png},
+		Response: ir.Response{Status: 201, ContentType: &png},
+	})
+	result := engine.Finalize()
+
+	for _, version := range []Version{Version30, Version31} {
+		t.Run(string(version), func(t *testing.T) {
+			opts := DefaultGeneratorOptions()
+			opts.Version = version
+			op := GenerateFromInference(result, opts).Paths["/uploads"].Post
+
+			if op.RequestBody == nil {
+				t.Fatal("expected request body for binary upload")
+			}
+			for name, content := range map[string]map[string]MediaType{
+				"request":  op.RequestBody.Content,
+				"response": op.Responses["201"].Content,
+			} {
+				schema := content[png].Schema
+				if schema == nil || schema.Type != "string" || schema.Format != "binary" {
+					t.Fatalf("expected %s string/binary schema, got %+v", name, schema)
+				}
+				wantMediaType := png
+				if version == Version30 {
+					wantMediaType = ""
+				}
+				if schema.ContentMediaType != wantMediaType {
+					t.Errorf("expected %s contentMediaType %q, got %q", name, wantMediaType, schema.ContentMediaType)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,482 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MergeStrategy selects how SpecMerger resolves operations and components
+// defined differently by more than one spec.
+type MergeStrategy string
+
+const (
+	// MergeFirstWins keeps the definition from the first spec.
+	MergeFirstWins MergeStrategy = "first-wins"
+	// MergeLastWins replaces the definition with the one from the later spec.
+	MergeLastWins MergeStrategy = "last-wins"
+	// MergeDeep combines both definitions: objects are merged recursively,
+	// arrays are combined, and values defined by both keep the first spec's.
+	MergeDeep MergeStrategy = "deep-merge"
+	// MergeFail merges like MergeFirstWins, but Add returns a
+	// *MergeConflictError when the spec conflicts with earlier ones.
+	MergeFail MergeStrategy = "fail"
+)
+
+// MergeStrategies lists the supported merge strategies.
+var MergeStrategies = []MergeStrategy{MergeFirstWins, MergeLastWins, MergeDeep, MergeFail}
+
+// MergeConflict describes an operation or component defined differently by
+// two merged specs.
+type MergeConflict struct {
+	Kind   string // operation, path, webhook, or component kind such as schema
+	Name   string // "GET /users" for operations, otherwise the path, webhook, or component name
+	First  string // spec whose definition was merged first
+	Second string // spec whose definition differed
+}
+
+// String returns a human-readable description of the conflict.
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("%s %s differs between %s and %s", c.Kind, c.Name, c.First, c.Second)
+}
+
+// MergeConflictError is returned by SpecMerger.Add with MergeFail when the
+// added spec conflicts with earlier ones.
+type MergeConflictError struct {
+	Conflicts []MergeConflict
+}
+
+func (e *MergeConflictError) Error() string {
+	if len(e.Conflicts) == 1 {
+		return "merge conflict: " + e.Conflicts[0].String()
+	}
+	return fmt.Sprintf("%d merge conflicts, first: %s", len(e.Conflicts), e.Conflicts[0])
+}
+
+// SpecMerger merges OpenAPI specs into a single spec. Paths, webhooks, and
+// every components section are combined, resolving conflicts per Strategy.
+// Servers and tags are deduplicated by URL and name, and top-level security
+// comes from the first spec that sets it.
+type SpecMerger struct {
+	Strategy MergeStrategy
+
+	spec      *Spec
+	origins   map[string]string // conflict key to the spec that defined it
+	conflicts []MergeConflict
+}
+
+// NewSpecMerger creates a merger using the given strategy
+// (MergeFirstWins if empty).
+func NewSpecMerger(strategy MergeStrategy) *SpecMerger {
+	if strategy == "" {
+		strategy = MergeFirstWins
+	}
+	return &SpecMerger{Strategy: strategy, origins: make(map[string]string)}
+}
+
+// Spec returns the merged spec, or nil if no spec was added.
+func (m *SpecMerger) Spec() *Spec {
+	return m.spec
+}
+
+// Conflicts returns the conflicts found so far, in the order found.
+func (m *SpecMerger) Conflicts() []MergeConflict {
+	return m.conflicts
+}
+
+// Add merges spec, identified by name in conflicts, into the merged spec.
+// The first spec added becomes the base of the merged spec.
+func (m *SpecMerger) Add(name string, spec *Spec) error {
+	switch m.Strategy {
+	case MergeFirstWins, MergeLastWins, MergeDeep, MergeFail:
+	default:
+		return fmt.Errorf("unsupported merge strategy: %s", m.Strategy)
+	}
+
+	if m.spec == nil {
+		m.spec = spec
+		m.recordOrigins(name, spec)
+		return nil
+	}
+
+	found := len(m.conflicts)
+	if err := m.mergeSpec(name, spec); err != nil {
+		return err
+	}
+	if m.Strategy == MergeFail && len(m.conflicts) > found {
+		return &MergeConflictError{Conflicts: m.conflicts[found:]}
+	}
+	return nil
+}
+
+// recordOrigins records name as the origin of the base spec's definitions.
+func (m *SpecMerger) recordOrigins(name string, spec *Spec) {
+	for path, item := range spec.Paths {
+		m.recordPathItemOrigins("operation", path, name, item)
+	}
+	for hook, item := range spec.Webhooks {
+		m.recordPathItemOrigins("webhook", hook, name, item)
+	}
+	if spec.Components != nil {
+		for _, section := range componentSections(spec.Components, &Components{}) {
+			for _, component := range section.names() {
+				m.origins[conflictKey(section.kind, component)] = name
+			}
+		}
+	}
+}
+
+func (m *SpecMerger) mergeSpec(name string, spec *Spec) error {
+	merged := m.spec
+
+	if merged.Paths == nil && len(spec.Paths) > 0 {
+		merged.Paths = make(map[string]*PathItem)
+	}
+	for _, path := range sortedKeys(spec.Paths) {
+		if err := m.mergePathItem("operation", path, name, merged.Paths, spec.Paths[path]); err != nil {
+			return err
+		}
+	}
+	if merged.Webhooks == nil && len(spec.Webhooks) > 0 {
+		merged.Webhooks = make(map[string]*PathItem)
+	}
+	for _, hook := range sortedKeys(spec.Webhooks) {
+		if err := m.mergePathItem("webhook", hook, name, merged.Webhooks, spec.Webhooks[hook]); err != nil {
+			return err
+		}
+	}
+
+	// Merge servers (dedupe by URL)
+	serverURLs := make(map[string]bool)
+	for _, s := range merged.Servers {
+		serverURLs[s.URL] = true
+	}
+	for _, s := range spec.Servers {
+		if !serverURLs[s.URL] {
+			merged.Servers = append(merged.Servers, s)
+			serverURLs[s.URL] = true
+		}
+	}
+
+	// Merge tags (dedupe by name) and keep the first spec's security
+	tagNames := make(map[string]bool)
+	for _, t := range merged.Tags {
+		tagNames[t.Name] = true
+	}
+	for _, t := range spec.Tags {
+		if !tagNames[t.Name] {
+			merged.Tags = append(merged.Tags, t)
+			tagNames[t.Name] = true
+		}
+	}
+	if len(merged.Security) == 0 {
+		merged.Security = spec.Security
+	}
+
+	if spec.Components != nil {
+		if merged.Components == nil {
+			merged.Components = &Components{}
+		}
+		for _, section := range componentSections(merged.Components, spec.Components) {
+			if err := section.merge(m, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mergePathItem merges item into items[key]: its path-level fields
+// (summary, description, servers, parameters, and extensions) as one
+// definition, and each of its operations.
+func (m *SpecMerger) mergePathItem(kind, key, name string, items map[string]*PathItem, item *PathItem) error {
+	target, ok := items[key]
+	if !ok {
+		items[key] = item
+		m.recordPathItemOrigins(kind, key, name, item)
+		return nil
+	}
+
+	if added := pathItemFields(item); added != nil {
+		fields, err := mergeValue(m, pathItemKind(kind), key, name, pathItemFields(target), added, true)
+		if err != nil {
+			return err
+		}
+		setPathItemFields(target, fields)
+	}
+
+	targetSlots := operationSlots(target)
+	for i, slot := range operationSlots(item) {
+		if *slot.op == nil {
+			continue
+		}
+		op, err := mergeValue(m, kind, slot.method+" "+key, name, *targetSlots[i].op, *slot.op, true)
+		if err != nil {
+			return err
+		}
+		*targetSlots[i].op = op
+	}
+	return nil
+}
+
+// recordPathItemOrigins records name as the origin of a path item's
+// path-level fields and operations.
+func (m *SpecMerger) recordPathItemOrigins(kind, key, name string, item *PathItem) {
+	if pathItemFields(item) != nil {
+		m.origins[conflictKey(pathItemKind(kind), key)] = name
+	}
+	for _, mo := range pathItemOperations(item) {
+		m.origins[conflictKey(kind, mo.method+" "+key)] = name
+	}
+}
+
+// pathItemKind returns the conflict kind of the path-level fields of path
+// items whose operations have the given kind.
+func pathItemKind(kind string) string {
+	if kind == "webhook" {
+		return "webhook"
+	}
+	return "path"
+}
+
+// pathItemFields returns a path item without its operations, or nil if it
+// has no path-level fields.
+func pathItemFields(item *PathItem) *PathItem {
+	if item.Ref == "" && item.Summary == "" && item.Description == "" &&
+		len(item.Servers) == 0 && len(item.Parameters) == 0 && len(item.Extensions) == 0 {
+		return nil
+	}
+	return &PathItem{
+		Ref:         item.Ref,
+		Summary:     item.Summary,
+		Description: item.Description,
+		Servers:     item.Servers,
+		Parameters:  item.Parameters,
+		Extensions:  item.Extensions,
+	}
+}
+
+// setPathItemFields sets the path-level fields of item from fields.
+func setPathItemFields(item, fields *PathItem) {
+	item.Ref = fields.Ref
+	item.Summary = fields.Summary
+	item.Description = fields.Description
+	item.Servers = fields.Servers
+	item.Parameters = fields.Parameters
+	item.Extensions = fields.Extensions
+}
+
+// mergeValue resolves a definition present in the merged spec (existing,
+// unless exists is false) and in the spec being added, recording a conflict
+// when they differ.
+func mergeValue[T any](m *SpecMerger, kind, key, name string, existing, added T, exists bool) (T, error) {
+	ck := conflictKey(kind, key)
+	if !exists || isNil(existing) {
+		m.origins[ck] = name
+		return added, nil
+	}
+	if sameDefinition(existing, added) {
+		return existing, nil
+	}
+
+	m.conflicts = append(m.conflicts, MergeConflict{Kind: kind, Name: key, First: m.origins[ck], Second: name})
+	switch m.Strategy {
+	case MergeLastWins:
+		m.origins[ck] = name
+		return added, nil
+	case MergeDeep:
+		merged, err := deepMergeValue(existing, added)
+		if err != nil {
+			return existing, fmt.Errorf("merging %s %s: %w", kind, key, err)
+		}
+		return merged, nil
+	default:
+		return existing, nil
+	}
+}
+
+// componentSection merges one section of Components, such as schemas.
+type componentSection struct {
+	kind  string
+	names func() []string
+	merge func(m *SpecMerger, name string) error
+}
+
+// componentSections returns the sections of target, each merging the
+// matching section of source into it.
+func componentSections(target, source *Components) []componentSection {
+	return []componentSection{
+		section("schema", &target.Schemas, source.Schemas),
+		section("response", &target.Responses, source.Responses),
+		section("parameter", &target.Parameters, source.Parameters),
+		section("example", &target.Examples, source.Examples),
+		section("requestBody", &target.RequestBodies, source.RequestBodies),
+		section("header", &target.Headers, source.Headers),
+		section("securityScheme", &target.SecuritySchemes, source.SecuritySchemes),
+		section("link", &target.Links, source.Links),
+		section("callback", &target.Callbacks, source.Callbacks),
+		section("pathItem", &target.PathItems, source.PathItems),
+	}
+}
+
+func section[V any](kind string, target *map[string]V, source map[string]V) componentSection {
+	return componentSection{
+		kind:  kind,
+		names: func() []string { return sortedKeys(*target) },
+		merge: func(m *SpecMerger, name string) error {
+			if len(source) == 0 {
+				return nil
+			}
+			if *target == nil {
+				*target = make(map[string]V, len(source))
+			}
+			for _, key := range sortedKeys(source) {
+				existing, exists := (*target)[key]
+				merged, err := mergeValue(m, kind, key, name, existing, source[key], exists)
+				if err != nil {
+					return err
+				}
+				(*target)[key] = merged
+			}
+			return nil
+		},
+	}
+}
+
+// operationSlot points to one of a path item's operation fields.
+type operationSlot struct {
+	method string
+	op     **Operation
+}
+
+func operationSlots(item *PathItem) []operationSlot {
+	return []operationSlot{
+		{"GET", &item.Get}, {"POST", &item.Post}, {"PUT", &item.Put}, {"PATCH", &item.Patch},
+		{"DELETE", &item.Delete}, {"HEAD", &item.Head}, {"OPTIONS", &item.Options}, {"TRACE", &item.Trace},
+	}
+}
+
+func conflictKey(kind, name string) string {
+	return kind + " " + name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isNil(v any) bool {
+	rv := reflect.ValueOf(v)
+	return !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil())
+}
+
+// sameDefinition reports whether a and b encode to the same JSON value,
+// ignoring key order.
+func sameDefinition(a, b any) bool {
+	av, aErr := toJSONValue(a)
+	bv, bErr := toJSONValue(b)
+	return aErr == nil && bErr == nil && reflect.DeepEqual(av, bv)
+}
+
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+// deepMergeValue merges the JSON encodings of first and second with
+// deepMerge and decodes the result.
+func deepMergeValue[T any](first, second T) (T, error) {
+	var merged T
+	fv, err := toJSONValue(first)
+	if err != nil {
+		return merged, err
+	}
+	sv, err := toJSONValue(second)
+	if err != nil {
+		return merged, err
+	}
+	data, err := json.Marshal(deepMerge(fv, sv))
+	if err != nil {
+		return merged, err
+	}
+	err = json.Unmarshal(data, &merged)
+	return merged, err
+}
+
+// deepMerge merges decoded JSON values: objects are merged recursively,
+// arrays are combined, and other values keep first.
+func deepMerge(first, second any) any {
+	switch f := first.(type) {
+	case map[string]any:
+		s, ok := second.(map[string]any)
+		if !ok {
+			return first
+		}
+		for key, sv := range s {
+			if fv, exists := f[key]; exists {
+				f[key] = deepMerge(fv, sv)
+			} else {
+				f[key] = sv
+			}
+		}
+		return f
+	case []any:
+		s, ok := second.([]any)
+		if !ok {
+			return first
+		}
+		return mergeArrays(f, s)
+	default:
+		return first
+	}
+}
+
+// mergeArrays appends the items of second missing from first. Objects with
+// a name, such as parameters, are matched by name and location and merged.
+func mergeArrays(first, second []any) []any {
+	for _, item := range second {
+		key, named := itemKey(item)
+		merged := false
+		for i, existing := range first {
+			if named {
+				if existingKey, ok := itemKey(existing); ok && existingKey == key {
+					first[i] = deepMerge(existing, item)
+					merged = true
+					break
+				}
+			} else if reflect.DeepEqual(existing, item) {
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			first = append(first, item)
+		}
+	}
+	return first
+}
+
+// itemKey returns the name and location of a named array item.
+func itemKey(item any) (string, bool) {
+	obj, ok := item.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	name, ok := obj["name"].(string)
+	if !ok {
+		return "", false
+	}
+	in, _ := obj["in"].(string)
+	return strings.Join([]string{in, name}, ":"), true
+}
@@ -0,0 +1,182 @@
+package openapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSpecMerger(t *testing.T) {
+	newSpec := func(summary string, param string, props map[string]*Schema) *Spec {
+		return &Spec{
+			OpenAPI: "3.1.0",
+			Paths: map[string]*PathItem{
+				"/users": {Get: &Operation{
+					Summary:    summary,
+					Parameters: []Parameter{{Name: param, In: "query", Schema: &Schema{Type: "string"}}},
+					Responses:  map[string]Response{"200": {Description: "OK"}},
+				}},
+			},
+			Components: &Components{Schemas: map[string]*Schema{
+				"User": {Type: "object", Properties: props},
+			}},
+		}
+	}
+	first := func() *Spec {
+		return newSpec("List users", "page", map[string]*Schema{"id": {Type: "integer"}})
+	}
+	second := func() *Spec {
+		return newSpec("Search users", "q", map[string]*Schema{"name": {Type: "string"}})
+	}
+
+	tests := []struct {
+		strategy    MergeStrategy
+		wantSummary string
+		wantParams  int
+		wantProps   int
+		wantErr     bool
+	}{
+		{MergeFirstWins, "List users", 1, 1, false},
+		{MergeLastWins, "Search users", 1, 1, false},
+		{MergeDeep, "List users", 2, 2, false},
+		{MergeFail, "List users", 1, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			m := NewSpecMerger(tt.strategy)
+			if err := m.Add("a.yaml", first()); err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+			err := m.Add("b.yaml", second())
+			var conflictErr *MergeConflictError
+			if tt.wantErr != errors.As(err, &conflictErr) {
+				t.Fatalf("expected conflict error %v, got %v", tt.wantErr, err)
+			}
+
+			conflicts := m.Conflicts()
+			if len(conflicts) != 2 {
+				t.Fatalf("expected 2 conflicts, got %v", conflicts)
+			}
+			if got := conflicts[0].String(); got != "operation GET /users differs between a.yaml and b.yaml" {
+				t.Errorf("unexpected conflict: %s", got)
+			}
+			if conflicts[1].Kind != "schema" || conflicts[1].Name != "User" {
+				t.Errorf("expected schema User conflict, got %v", conflicts[1])
+			}
+
+			spec := m.Spec()
+			op := spec.Paths["/users"].Get
+			if op.Summary != tt.wantSummary {
+				t.Errorf("expected summary %q, got %q", tt.wantSummary, op.Summary)
+			}
+			if len(op.Parameters) != tt.wantParams {
+				t.Errorf("expected %d parameters, got %v", tt.wantParams, op.Parameters)
+			}
+			if props := spec.Components.Schemas["User"].Properties; len(props) != tt.wantProps {
+				t.Errorf("expected %d properties, got %v", tt.wantProps, props)
+			}
+		})
+	}
+
+	// Identical definitions do not conflict
+	m := NewSpecMerger(MergeFail)
+	_ = m.Add("a.yaml", first())
+	if err := m.Add("c.yaml", first()); err != nil || len(m.Conflicts()) != 0 {
+		t.Errorf("expected no conflicts for identical specs, got %v %v", err, m.Conflicts())
+	}
+
+	if err := NewSpecMerger("newest").Add("a.yaml", first()); err == nil {
+		t.Error("expected error for unsupported strategy")
+	}
+}
+
+func TestSpecMergerPathItemFields(t *testing.T) {
+	first := func() *Spec {
+		return &Spec{
+			OpenAPI: "3.1.0",
+			Paths: map[string]*PathItem{
+				"/users/{id}": {
+					Summary:    "A user",
+					Parameters: []Parameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}},
+					Get:        &Operation{Responses: map[string]Response{"200": {Description: "OK"}}},
+				},
+			},
+		}
+	}
+	second := func() *Spec {
+		return &Spec{
+			OpenAPI: "3.1.0",
+			Paths: map[string]*PathItem{
+				"/users/{id}": {
+					Parameters: []Parameter{
+						{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer"}},
+						{Name: "X-Tenant", In: "header", Required: true, Schema: &Schema{Type: "string"}},
+					},
+					Servers:    []Server{{URL: "https://users.example.com"}},
+					Extensions: Extensions{"x-owner": "users-team"},
+					Get:        &Operation{Responses: map[string]Response{"200": {Description: "OK"}}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		strategy    MergeStrategy
+		wantIDType  string
+		wantParams  int
+		wantServers int
+		wantErr     bool
+	}{
+		{MergeFirstWins, "string", 1, 0, false},
+		{MergeLastWins, "integer", 2, 1, false},
+		{MergeDeep, "string", 2, 1, false},
+		{MergeFail, "string", 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			m := NewSpecMerger(tt.strategy)
+			if err := m.Add("a.yaml", first()); err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+			err := m.Add("b.yaml", second())
+			var conflictErr *MergeConflictError
+			if tt.wantErr != errors.As(err, &conflictErr) {
+				t.Fatalf("expected conflict error %v, got %v", tt.wantErr, err)
+			}
+
+			conflicts := m.Conflicts()
+			if len(conflicts) != 1 {
+				t.Fatalf("expected 1 conflict, got %v", conflicts)
+			}
+			if got := conflicts[0].String(); got != "path /users/{id} differs between a.yaml and b.yaml" {
+				t.Errorf("unexpected conflict: %s", got)
+			}
+
+			item := m.Spec().Paths["/users/{id}"]
+			if len(item.Parameters) != tt.wantParams {
+				t.Fatalf("expected %d parameters, got %v", tt.wantParams, item.Parameters)
+			}
+			if got := item.Parameters[0].Schema.Type; got != tt.wantIDType {
+				t.Errorf("expected id type %s, got %v", tt.wantIDType, got)
+			}
+			if len(item.Servers) != tt.wantServers {
+				t.Errorf("expected %d servers, got %v", tt.wantServers, item.Servers)
+			}
+			if item.Get == nil {
+				t.Error("expected GET operation to be kept")
+			}
+		})
+	}
+
+	// Path-level fields defined by only one spec are kept without conflict
+	m := NewSpecMerger(MergeFail)
+	bare := first()
+	bare.Paths["/users/{id}"].Summary = ""
+	bare.Paths["/users/{id}"].Parameters = nil
+	_ = m.Add("a.yaml", bare)
+	if err := m.Add("b.yaml", second()); err != nil {
+		t.Fatalf("expected no conflict, got %v", err)
+	}
+	if params := m.Spec().Paths["/users/{id}"].Parameters; len(params) != 2 {
+		t.Errorf("expected 2 parameters from b.yaml, got %v", params)
+	}
+}
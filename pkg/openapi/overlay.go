@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OverlayVersion is the Overlay Specification version of generated overlays.
+const OverlayVersion = "1.0.0"
+
+// Overlay is an OpenAPI Overlay document: a list of actions that update or
+// remove parts of a target spec, applied with standard overlay tooling.
+type Overlay struct {
+	Overlay string          `json:"overlay" yaml:"overlay"`
+	Info    OverlayInfo     `json:"info" yaml:"info"`
+	Extends string          `json:"extends,omitempty" yaml:"extends,omitempty"` // URI of the target spec
+	Actions []OverlayAction `json:"actions" yaml:"actions"`
+}
+
+// OverlayInfo describes an overlay.
+type OverlayInfo struct {
+	Title   string `json:"title" yaml:"title"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// OverlayAction updates or removes the nodes selected by a JSONPath target.
+// An update object is merged into target objects and appended to target
+// arrays.
+type OverlayAction struct {
+	Target      string `json:"target" yaml:"target"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Update      any    `json:"update,omitempty" yaml:"update,omitempty"`
+	Remove      bool   `json:"remove,omitempty" yaml:"remove,omitempty"`
+}
+
+// DiffOverlay returns an overlay that turns base into updated, so changes
+// such as those made by Enrich can be published without modifying the base
+// spec. Added and changed values are merged into their parent object with
+// one update action per object. Objects in arrays, such as parameters, are
+// updated by index, and elements appended to an array are appended by
+// targeting the array. Arrays changed in other ways are removed and set
+// again, and removed values get remove actions.
+func DiffOverlay(base, updated *Spec, info OverlayInfo) (*Overlay, error) {
+	baseTree, err := specTree(base)
+	if err != nil {
+		return nil, err
+	}
+	updatedTree, err := specTree(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay := &Overlay{Overlay: OverlayVersion, Info: info, Actions: []OverlayAction{}}
+	diffOverlayObject(overlay, "$", baseTree, updatedTree)
+	return overlay, nil
+}
+
+// specTree converts a spec to its generic JSON form.
+func specTree(spec *Spec) (map[string]any, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("encoding spec: %w", err)
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("decoding spec: %w", err)
+	}
+	return tree, nil
+}
+
+func diffOverlayObject(overlay *Overlay, target string, base, updated map[string]any) {
+	update := make(map[string]any)
+	var nested []func()
+
+	for _, key := range slices.Sorted(maps.Keys(base)) {
+		if _, ok := updated[key]; !ok {
+			overlay.Actions = append(overlay.Actions, OverlayAction{Target: jsonPathChild(target, key), Remove: true})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(updated)) {
+		oldValue, ok := base[key]
+		newValue := updated[key]
+		if !ok {
+			update[key] = newValue
+			continue
+		}
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		child := jsonPathChild(target, key)
+		oldObject, oldIsObject := oldValue.(map[string]any)
+		newObject, newIsObject := newValue.(map[string]any)
+		oldArray, oldIsArray := oldValue.([]any)
+		newArray, newIsArray := newValue.([]any)
+		switch {
+		case oldIsObject && newIsObject:
+			nested = append(nested, func() { diffOverlayObject(overlay, child, oldObject, newObject) })
+		case oldIsArray && newIsArray && objectsChangedInPlace(oldArray, newArray):
+			nested = append(nested, func() {
+				for i := range newArray {
+					oldElement, _ := oldArray[i].(map[string]any)
+					newElement, _ := newArray[i].(map[string]any)
+					if !reflect.DeepEqual(oldElement, newElement) {
+						diffOverlayObject(overlay, child+"["+strconv.Itoa(i)+"]", oldElement, newElement)
+					}
+				}
+			})
+		case oldIsArray && newIsArray && len(newArray) > len(oldArray) && reflect.DeepEqual(oldArray, newArray[:len(oldArray)]):
+			nested = append(nested, func() {
+				for _, element := range newArray[len(oldArray):] {
+					overlay.Actions = append(overlay.Actions, OverlayAction{Target: child, Update: element})
+				}
+			})
+		case oldIsArray || oldIsObject:
+			// Merging into an array or object would keep its old contents
+			overlay.Actions = append(overlay.Actions, OverlayAction{Target: child, Remove: true})
+			update[key] = newValue
+		default:
+			update[key] = newValue
+		}
+	}
+
+	if len(update) > 0 {
+		overlay.Actions = append(overlay.Actions, OverlayAction{Target: target, Update: update})
+	}
+	for _, f := range nested {
+		f()
+	}
+}
+
+// objectsChangedInPlace reports whether two arrays have the same length and
+// differ only in elements that are objects in both, so the elements can be
+// updated by index.
+func objectsChangedInPlace(base, updated []any) bool {
+	if len(base) != len(updated) {
+		return false
+	}
+	for i := range base {
+		if reflect.DeepEqual(base[i], updated[i]) {
+			continue
+		}
+		_, oldIsObject := base[i].(map[string]any)
+		_, newIsObject := updated[i].(map[string]any)
+		if !oldIsObject || !newIsObject {
+			return false
+		}
+	}
+	return true
+}
+
+var jsonPathIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPathChild returns the JSONPath of a member of the object at target,
+// using bracket notation for names that aren't identifiers, such as paths
+// and status codes.
+func jsonPathChild(target, name string) string {
+	if jsonPathIdentifier.MatchString(name) {
+		return target + "." + name
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(name)
+	return target + "['" + escaped + "']"
+}
+
+// WriteOverlay writes an overlay as JSON or YAML.
+func WriteOverlay(w io.Writer, overlay *Overlay, format Format) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(overlay); err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		return nil
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(overlay); err != nil {
+		return fmt.Errorf("encoding YAML: %w", err)
+	}
+	return nil
+}
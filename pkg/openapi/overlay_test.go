@@ -0,0 +1,150 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiffOverlay(t *testing.T) {
+	specYAML := `openapi: 3.1.0
+info:
+  title: Users API
+  version: 1.0.0
+tags:
+  - name: users
+paths:
+  /users/{userId}:
+    get:
+      parameters:
+        - name: expand
+          in: query
+          schema:
+            type: string
+      responses:
+        "200":
+          description: A user
+`
+	base, err := FromYAML([]byte(specYAML))
+	if err != nil {
+		t.Fatalf("parsing spec: %v", err)
+	}
+	updated, err := FromYAML([]byte(specYAML))
+	if err != nil {
+		t.Fatalf("parsing spec: %v", err)
+	}
+	op := updated.Paths["/users/{userId}"].Get
+	op.Parameters[0].Example = "profile"
+	resp := op.Responses["200"]
+	resp.Headers = map[string]Header{"X-Request-Id": {Schema: &Schema{Type: "string"}}}
+	op.Responses["200"] = resp
+	op.Extensions = Extensions{"x-observed-fields": []any{"nickname"}}
+	updated.Tags = append(updated.Tags, Tag{Name: "orders"})
+	updated.Info.Version = "1.1.0"
+
+	overlay, err := DiffOverlay(base, updated, OverlayInfo{Title: "Traffic enrichment", Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("DiffOverlay: %v", err)
+	}
+	if overlay.Overlay != OverlayVersion {
+		t.Errorf("expected overlay version %s, got %s", OverlayVersion, overlay.Overlay)
+	}
+
+	targets := make(map[string]bool)
+	for _, action := range overlay.Actions {
+		targets[action.Target] = true
+	}
+	for _, want := range []string{
+		"$.info",
+		"$.tags",
+		"$.paths['/users/{userId}'].get",
+		"$.paths['/users/{userId}'].get.parameters[0]",
+		"$.paths['/users/{userId}'].get.responses['200']",
+	} {
+		if !targets[want] {
+			t.Errorf("expected an action targeting %s, got %+v", want, overlay.Actions)
+		}
+	}
+
+	// Applying the overlay to the base spec gives the updated spec
+	tree, err := specTree(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range overlay.Actions {
+		applyOverlayAction(t, tree, action)
+	}
+	want, err := specTree(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tree, want) {
+		got, _ := json.Marshal(tree)
+		wantJSON, _ := json.Marshal(want)
+		t.Errorf("applied overlay:\n got %s\nwant %s", got, wantJSON)
+	}
+
+	unchanged, err := DiffOverlay(base, base, OverlayInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unchanged.Actions) != 0 {
+		t.Errorf("expected no actions for identical specs, got %+v", unchanged.Actions)
+	}
+}
+
+var overlayTargetSegment = regexp.MustCompile(`\.([A-Za-z_][A-Za-z0-9_]*)|\['((?:[^'\\]|\\.)*)'\]|\[(\d+)\]`)
+
+// applyOverlayAction applies an overlay action with a single-node target to
+// a spec tree, as overlay tooling would.
+func applyOverlayAction(t *testing.T, tree map[string]any, action OverlayAction) {
+	t.Helper()
+	var node any = tree
+	set := func(any) { t.Fatalf("cannot replace the root") }
+	for _, m := range overlayTargetSegment.FindAllStringSubmatch(strings.TrimPrefix(action.Target, "$"), -1) {
+		parent := node
+		switch {
+		case m[3] != "":
+			i, _ := strconv.Atoi(m[3])
+			arr := parent.([]any)
+			node = arr[i]
+			set = func(v any) { arr[i] = v }
+		default:
+			key := m[1] + strings.ReplaceAll(m[2], `\'`, `'`)
+			obj := parent.(map[string]any)
+			node = obj[key]
+			set = func(v any) { obj[key] = v }
+			if action.Remove {
+				set = func(any) { delete(obj, key) }
+			}
+		}
+	}
+
+	switch {
+	case action.Remove:
+		set(nil)
+	case node == nil:
+		t.Fatalf("target %s not found", action.Target)
+	default:
+		if arr, ok := node.([]any); ok {
+			set(append(arr, action.Update))
+		} else {
+			mergeOverlayUpdate(node.(map[string]any), action.Update.(map[string]any))
+		}
+	}
+}
+
+func mergeOverlayUpdate(target, update map[string]any) {
+	for key, value := range update {
+		if obj, ok := value.(map[string]any); ok {
+			if existing, ok := target[key].(map[string]any); ok {
+				mergeOverlayUpdate(existing, obj)
+				continue
+			}
+		}
+		target[key] = value
+	}
+}
@@ -0,0 +1,29 @@
+package openapi
+
+import "github.com/grokify/traffic2openapi/pkg/inference"
+
+// paramStatsExtension returns the x-param-stats extension value of a path
+// parameter's statistics: its distinct value count, numeric range, value
+// lengths, and the formats of its values.
+func paramStatsExtension(stats *inference.ParamStats) map[string]any {
+	ext := map[string]any{
+		"distinct":  stats.Distinct,
+		"minLength": stats.MinLength,
+		"maxLength": stats.MaxLength,
+	}
+	if stats.DistinctCapped {
+		ext["distinctCapped"] = true
+	}
+	if stats.Numeric {
+		ext["minimum"] = stats.Min
+		ext["maximum"] = stats.Max
+	}
+	if len(stats.Formats) > 0 {
+		formats := make(map[string]any, len(stats.Formats))
+		for format, n := range stats.Formats {
+			formats[format] = n
+		}
+		ext["formats"] = formats
+	}
+	return ext
+}
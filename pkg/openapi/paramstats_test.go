@@ -0,0 +1,27 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestParamStatsExtension(t *testing.T) {
+	result := inference.InferFromRecords([]ir.IRRecord{
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users/12"}, Response: ir.Response{Status: 200}},
+		{Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users/4096"}, Response: ir.Response{Status: 200}},
+	})
+	if params := GenerateFromInference(result, DefaultGeneratorOptions()).Paths["/users/{userId}"].Get.Parameters; params[0].Extensions != nil {
+		t.Errorf("unexpected extensions without ParamStats: %v", params[0].Extensions)
+	}
+
+	opts := DefaultGeneratorOptions()
+	opts.ParamStats = true
+	params := GenerateFromInference(result, opts).Paths["/users/{userId}"].Get.Parameters
+	want := map[string]any{"distinct": 2, "minLength": 2, "maxLength": 4, "minimum": 12.0, "maximum": 4096.0}
+	if got := params[0].Extensions["x-param-stats"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("x-param-stats = %v, want %v", got, want)
+	}
+}
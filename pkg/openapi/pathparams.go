@@ -0,0 +1,68 @@
+package openapi
+
+import "reflect"
+
+// hoistPathParameters moves path parameters shared by every operation under
+// a path item to PathItem.Parameters. Parameters are shared when they match
+// in everything but their example; the first operation's example is kept.
+// Path items with a single operation are left as they are.
+func hoistPathParameters(item *PathItem) {
+	ops := pathItemOperations(item)
+	if len(ops) < 2 {
+		return
+	}
+
+	shared := pathParameters(ops[0].op)
+	if len(shared) == 0 {
+		return
+	}
+	for _, mo := range ops[1:] {
+		params := pathParameters(mo.op)
+		if len(params) != len(shared) {
+			return
+		}
+		for i := range params {
+			if !sameParameter(params[i], shared[i]) {
+				return
+			}
+		}
+	}
+
+	item.Parameters = append(item.Parameters, shared...)
+	for _, mo := range ops {
+		kept := make([]Parameter, 0, len(mo.op.Parameters)-len(shared))
+		for _, p := range mo.op.Parameters {
+			if p.In != "path" {
+				kept = append(kept, p)
+			}
+		}
+		mo.op.Parameters = kept
+	}
+}
+
+// pathParameters returns an operation's path parameters in declared order.
+func pathParameters(op *Operation) []Parameter {
+	var params []Parameter
+	for _, p := range op.Parameters {
+		if p.In == "path" {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// sameParameter reports whether two parameters match, ignoring examples.
+func sameParameter(a, b Parameter) bool {
+	a.Example, b.Example = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+// OperationParameters returns the parameters that apply to an operation: the
+// path item's parameters, overridden by the operation's own parameters of the
+// same name and location.
+func OperationParameters(item *PathItem, op *Operation) []Parameter {
+	if item == nil {
+		return op.Parameters
+	}
+	return mergeParameters(item.Parameters, op.Parameters)
+}
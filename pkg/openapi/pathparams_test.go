@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestHoistPathParameters(t *testing.T) {
+	engine := inference.NewEngine(inference.DefaultEngineOptions())
+	for _, method := range []ir.RequestMethod{ir.RequestMethodGET, ir.RequestMethodDELETE} {
+		for _, id := range []string{"123", "456"} {
+			engine.ProcessRecord(&ir.IRRecord{
+				Request:  ir.Request{Method: method, Path: "/users/" + id},
+				Response: ir.Response{Status: 200},
+			})
+		}
+	}
+	engine.ProcessRecord(&ir.IRRecord{
+		Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/users/123/orders", Query: map[string]any{"limit": "10"}},
+		Response: ir.Response{Status: 200},
+	})
+	result := engine.Finalize()
+
+	spec := GenerateFromInference(result, DefaultGeneratorOptions())
+	item := spec.Paths["/users/{userId}"]
+	if item == nil {
+		t.Fatal("expected /users/{userId} path")
+	}
+	if len(item.Parameters) != 1 || item.Parameters[0].Name != "userId" || item.Parameters[0].In != "path" {
+		t.Fatalf("expected userId hoisted to path item, got %+v", item.Parameters)
+	}
+	for _, op := range []*Operation{item.Get, item.Delete} {
+		if len(pathParameters(op)) != 0 {
+			t.Errorf("expected no operation-level path parameters, got %+v", op.Parameters)
+		}
+	}
+
+	// A single operation keeps its parameters
+	orders := spec.Paths["/users/{userId}/orders"]
+	if len(orders.Parameters) != 0 || len(pathParameters(orders.Get)) != 1 {
+		t.Errorf("expected single operation to keep its path parameter, got %+v / %+v", orders.Parameters, orders.Get.Parameters)
+	}
+
+	opts := DefaultGeneratorOptions()
+	opts.KeepOperationPathParameters = true
+	item = GenerateFromInference(result, opts).Paths["/users/{userId}"]
+	if len(item.Parameters) != 0 || len(pathParameters(item.Get)) != 1 || len(pathParameters(item.Delete)) != 1 {
+		t.Errorf("expected path parameters kept on operations, got %+v", item.Parameters)
+	}
+}
@@ -0,0 +1,58 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// preconditionVersions names what each precondition header carries.
+var preconditionVersions = map[string]string{
+	"If-Match":            "resource's current ETag",
+	"If-Unmodified-Since": "resource's Last-Modified date",
+}
+
+// addPreconditions documents the optimistic concurrency of a write: the
+// precondition header parameter, required when requests without it were
+// rejected with 428 or every request sent it, the 412 response a stale
+// version gets, and a description sentence saying how they fit together.
+func (g *Generator) addPreconditions(op *Operation, endpoint *inference.EndpointData) {
+	pre := endpoint.Preconditions
+	if pre == nil {
+		return
+	}
+	header := pre.Header()
+	required := pre.Required > 0 || pre.Headers[header] == endpoint.RequestCount
+
+	found := false
+	for i, param := range op.Parameters {
+		if param.In == "header" && strings.EqualFold(param.Name, header) {
+			op.Parameters[i].Required = param.Required || required
+			found = true
+		}
+	}
+	if !found {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        header,
+			In:          "header",
+			Description: inference.HeaderDescription(header),
+			Required:    required,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	code := strconv.Itoa(http.StatusPreconditionFailed)
+	if _, ok := op.Responses[code]; !ok {
+		op.Responses[code] = Response{Description: ResponseDescription(http.StatusPreconditionFailed, g.options.ResponseDescriptions)}
+	}
+
+	sentence := fmt.Sprintf("Optimistic concurrency: send the %s in the %s header; a stale value is rejected with 412 Precondition Failed.",
+		preconditionVersions[header], header)
+	if pre.Required > 0 {
+		sentence += " Requests without it are rejected with 428 Precondition Required."
+	}
+	op.Description = appendSentence(op.Description, sentence)
+}
@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestPreconditions(t *testing.T) {
+	result := inference.InferFromRecords([]ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPATCH, Path: "/docs/1", Headers: map[string]string{"If-Match": `"v1"`}},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPATCH, Path: "/docs/1"},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodDELETE, Path: "/docs/2"},
+			Response: ir.Response{Status: 428},
+		},
+	})
+	opts := DefaultGeneratorOptions()
+	spec := GenerateFromInference(result, opts)
+	item := spec.Paths["/docs/{docId}"]
+
+	var ifMatch *Parameter
+	for i, param := range item.Patch.Parameters {
+		if strings.EqualFold(param.Name, "If-Match") {
+			ifMatch = &item.Patch.Parameters[i]
+		}
+	}
+	if ifMatch == nil || ifMatch.Required {
+		t.Errorf("PATCH If-Match = %+v, want an optional parameter", ifMatch)
+	}
+	if _, ok := item.Patch.Responses["412"]; !ok {
+		t.Error("PATCH has no 412 response")
+	}
+	if !strings.Contains(item.Patch.Description, "Optimistic concurrency") {
+		t.Errorf("PATCH description = %q", item.Patch.Description)
+	}
+
+	// 428 without any precondition sent: If-Match is required
+	del := item.Delete
+	if len(del.Parameters) != 1 || del.Parameters[0].Name != "If-Match" || !del.Parameters[0].Required {
+		t.Errorf("DELETE parameters = %+v, want a required If-Match", del.Parameters)
+	}
+	if !strings.Contains(del.Description, "428 Precondition Required") {
+		t.Errorf("DELETE description = %q", del.Description)
+	}
+}
@@ -0,0 +1,127 @@
+package openapi
+
+// ProblemDetailsSchemaName is the component schema of RFC 9457 problem
+// details, referenced from application/problem+json responses.
+const ProblemDetailsSchemaName = "ProblemDetails"
+
+// problemMediaType is the media type of JSON problem details.
+const problemMediaType = "application/problem+json"
+
+// problemMembers are the members RFC 9457 defines for problem details.
+var problemMembers = map[string]bool{
+	"type":     true,
+	"title":    true,
+	"status":   true,
+	"detail":   true,
+	"instance": true,
+}
+
+// problemDetailsSchema returns the ProblemDetails component schema.
+func problemDetailsSchema() *Schema {
+	minStatus, maxStatus := 100.0, 599.0
+	return &Schema{
+		Type:        "object",
+		Description: "Problem details for HTTP APIs (RFC 9457).",
+		Properties: map[string]*Schema{
+			"type": {
+				Type:        "string",
+				Format:      "uri-reference",
+				Description: "A URI reference identifying the problem type.",
+				Default:     "about:blank",
+			},
+			"title": {
+				Type:        "string",
+				Description: "A short, human-readable summary of the problem type.",
+			},
+			"status": {
+				Type:        "integer",
+				Description: "The HTTP status code generated by the origin server for this occurrence.",
+				Minimum:     &minStatus,
+				Maximum:     &maxStatus,
+			},
+			"detail": {
+				Type:        "string",
+				Description: "A human-readable explanation specific to this occurrence of the problem.",
+			},
+			"instance": {
+				Type:        "string",
+				Format:      "uri-reference",
+				Description: "A URI reference identifying this occurrence of the problem.",
+			},
+		},
+	}
+}
+
+// addProblemDetails declares the ProblemDetails component and references it
+// from every application/problem+json response in place of the inferred
+// object. Extension members observed beside the standard ones are kept in
+// an allOf with the reference.
+func addProblemDetails(spec *Spec) {
+	ref := "#/components/schemas/" + ProblemDetailsSchemaName
+	found := false
+	for _, item := range spec.Paths {
+		for _, mo := range pathItemOperations(item) {
+			for _, resp := range mo.op.Responses {
+				for key, mt := range resp.Content {
+					if mediaTypeBase(key) != problemMediaType || !isObjectSchema(mt.Schema) {
+						continue
+					}
+					mt.Schema = problemSchema(mt.Schema, ref)
+					resp.Content[key] = mt
+					found = true
+				}
+			}
+		}
+	}
+	if !found {
+		return
+	}
+
+	if spec.Components == nil {
+		spec.Components = &Components{}
+	}
+	if spec.Components.Schemas == nil {
+		spec.Components.Schemas = make(map[string]*Schema)
+	}
+	spec.Components.Schemas[ProblemDetailsSchemaName] = problemDetailsSchema()
+}
+
+// problemSchema returns the schema of an inferred problem details object:
+// a reference to the component, with any extension members alongside.
+func problemSchema(inferred *Schema, ref string) *Schema {
+	extensions := make(map[string]*Schema)
+	for name, prop := range inferred.Properties {
+		if !problemMembers[name] {
+			extensions[name] = prop
+		}
+	}
+	if len(extensions) == 0 {
+		return &Schema{Ref: ref}
+	}
+
+	var required []string
+	for _, name := range inferred.Required {
+		if !problemMembers[name] {
+			required = append(required, name)
+		}
+	}
+	return &Schema{AllOf: []*Schema{
+		{Ref: ref},
+		{Type: "object", Properties: extensions, Required: required},
+	}}
+}
+
+// isObjectSchema reports whether a schema's type is object, or object and
+// null.
+func isObjectSchema(schema *Schema) bool {
+	if schema == nil {
+		return false
+	}
+	switch t := schema.Type.(type) {
+	case string:
+		return t == "object"
+	case []string:
+		return len(t) > 0 && t[0] == "object"
+	}
+	return false
+}
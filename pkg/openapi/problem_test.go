@@ -0,0 +1,50 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestProblemDetails(t *testing.T) {
+	problem := "application/problem+json; charset=utf-8"
+	result := inference.InferFromRecords([]ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users/1"},
+			Response: ir.Response{Status: 404, ContentType: &problem, Body: map[string]any{
+				"type": "https://example.com/probs/not-found", "title": "Not Found", "status": 404, "detail": "User 1 not found",
+			}},
+		},
+		{
+			Request: ir.Request{Method: ir.RequestMethodPOST, Path: "/users"},
+			Response: ir.Response{Status: 422, ContentType: &problem, Body: map[string]any{
+				"title": "Invalid", "status": 422, "errors": []any{map[string]any{"field": "name"}},
+			}},
+		},
+	})
+	spec := GenerateFromInference(result, DefaultGeneratorOptions())
+
+	component := spec.Components.Schemas[ProblemDetailsSchemaName]
+	if component == nil {
+		t.Fatal("expected a ProblemDetails component")
+	}
+	for _, name := range []string{"type", "title", "status", "detail", "instance"} {
+		if component.Properties[name] == nil {
+			t.Errorf("ProblemDetails has no %s member", name)
+		}
+	}
+
+	ref := "#/components/schemas/" + ProblemDetailsSchemaName
+	notFound := spec.Paths["/users/{userId}"].Get.Responses["404"].Content["application/problem+json"].Schema
+	if notFound == nil || notFound.Ref != ref {
+		t.Errorf("404 schema = %+v, want a reference to ProblemDetails", notFound)
+	}
+	invalid := spec.Paths["/users"].Post.Responses["422"].Content["application/problem+json"].Schema
+	if invalid == nil || len(invalid.AllOf) != 2 || invalid.AllOf[0].Ref != ref {
+		t.Fatalf("422 schema = %+v, want allOf with ProblemDetails", invalid)
+	}
+	if ext := invalid.AllOf[1]; len(ext.Properties) != 1 || ext.Properties["errors"] == nil {
+		t.Errorf("extension members = %v, want only errors", ext.Properties)
+	}
+}
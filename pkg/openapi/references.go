@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// refObject is a Reference Object, encoded in place of an object that has
+// a Ref so its required fields aren't written empty.
+type refObject struct {
+	Ref         string `json:"$ref" yaml:"$ref"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding references as refObjects
+// and including extension fields otherwise.
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	if p.Ref != "" {
+		return json.Marshal(refObject{Ref: p.Ref, Description: p.Description})
+	}
+	type plain Parameter
+	return marshalJSONWithExtensions(plain(p), p.Extensions)
+}
+
+// MarshalYAML implements yaml.Marshaler, encoding references as refObjects
+// and including extension fields otherwise.
+func (p Parameter) MarshalYAML() (any, error) {
+	if p.Ref != "" {
+		return refObject{Ref: p.Ref, Description: p.Description}, nil
+	}
+	type plain Parameter
+	return marshalYAMLWithExtensions(plain(p), p.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (p *Parameter) UnmarshalJSON(data []byte) error {
+	type plain Parameter
+	ext, err := decodeJSONWithExtensions(data, (*plain)(p))
+	p.Extensions = ext
+	return err
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (p *Parameter) UnmarshalYAML(node *yaml.Node) error {
+	type plain Parameter
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(p))
+	p.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, encoding references as refObjects
+// and including extension fields otherwise.
+func (r RequestBody) MarshalJSON() ([]byte, error) {
+	if r.Ref != "" {
+		return json.Marshal(refObject{Ref: r.Ref, Description: r.Description})
+	}
+	type plain RequestBody
+	return marshalJSONWithExtensions(plain(r), r.Extensions)
+}
+
+// MarshalYAML implements yaml.Marshaler, encoding references as refObjects
+// and including extension fields otherwise.
+func (r RequestBody) MarshalYAML() (any, error) {
+	if r.Ref != "" {
+		return refObject{Ref: r.Ref, Description: r.Description}, nil
+	}
+	type plain RequestBody
+	return marshalYAMLWithExtensions(plain(r), r.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (r *RequestBody) UnmarshalJSON(data []byte) error {
+	type plain RequestBody
+	ext, err := decodeJSONWithExtensions(data, (*plain)(r))
+	r.Extensions = ext
+	return err
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (r *RequestBody) UnmarshalYAML(node *yaml.Node) error {
+	type plain RequestBody
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(r))
+	r.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, encoding references as refObjects
+// and including extension fields otherwise.
+func (r Response) MarshalJSON() ([]byte, error) {
+	if r.Ref != "" {
+		return json.Marshal(refObject{Ref: r.Ref, Description: r.Description})
+	}
+	type plain Response
+	return marshalJSONWithExtensions(plain(r), r.Extensions)
+}
+
+// MarshalYAML implements yaml.Marshaler, encoding references as refObjects
+// and including extension fields otherwise.
+func (r Response) MarshalYAML() (any, error) {
+	if r.Ref != "" {
+		return refObject{Ref: r.Ref, Description: r.Description}, nil
+	}
+	type plain Response
+	return marshalYAMLWithExtensions(plain(r), r.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (r *Response) UnmarshalJSON(data []byte) error {
+	type plain Response
+	ext, err := decodeJSONWithExtensions(data, (*plain)(r))
+	r.Extensions = ext
+	return err
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (r *Response) UnmarshalYAML(node *yaml.Node) error {
+	type plain Response
+	ext, err := decodeYAMLWithExtensions(node, (*plain)(r))
+	r.Extensions = ext
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, including extension fields.
+func (c Callback) MarshalJSON() ([]byte, error) {
+	if c.Ref != "" {
+		return json.Marshal(refObject{Ref: c.Ref})
+	}
+	expressions := c.Expressions
+	if expressions == nil {
+		expressions = map[string]*PathItem{}
+	}
+	return marshalJSONWithExtensions(expressions, c.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, capturing extension fields.
+func (c *Callback) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Callback{}
+	for expr, value := range raw {
+		if expr == "$ref" {
+			if err := json.Unmarshal(value, &c.Ref); err != nil {
+				return err
+			}
+			continue
+		}
+		if IsExtensionKey(expr) {
+			var v any
+			if err := json.Unmarshal(value, &v); err != nil {
+				return err
+			}
+			if c.Extensions == nil {
+				c.Extensions = make(Extensions)
+			}
+			c.Extensions[expr] = v
+			continue
+		}
+		var item PathItem
+		if err := json.Unmarshal(value, &item); err != nil {
+			return err
+		}
+		if c.Expressions == nil {
+			c.Expressions = make(map[string]*PathItem)
+		}
+		c.Expressions[expr] = &item
+	}
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler, including extension fields.
+func (c Callback) MarshalYAML() (any, error) {
+	if c.Ref != "" {
+		return refObject{Ref: c.Ref}, nil
+	}
+	expressions := c.Expressions
+	if expressions == nil {
+		expressions = map[string]*PathItem{}
+	}
+	return marshalYAMLWithExtensions(expressions, c.Extensions)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, capturing extension fields.
+func (c *Callback) UnmarshalYAML(node *yaml.Node) error {
+	var raw map[string]yaml.Node
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	*c = Callback{}
+	for expr, value := range raw {
+		if expr == "$ref" {
+			if err := value.Decode(&c.Ref); err != nil {
+				return err
+			}
+			continue
+		}
+		if IsExtensionKey(expr) {
+			var v any
+			if err := value.Decode(&v); err != nil {
+				return err
+			}
+			if c.Extensions == nil {
+				c.Extensions = make(Extensions)
+			}
+			c.Extensions[expr] = v
+			continue
+		}
+		var item PathItem
+		if err := value.Decode(&item); err != nil {
+			return err
+		}
+		if c.Expressions == nil {
+			c.Expressions = make(map[string]*PathItem)
+		}
+		c.Expressions[expr] = &item
+	}
+	return nil
+}
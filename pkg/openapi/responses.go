@@ -0,0 +1,29 @@
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResponseDescription returns the description of a response status. An
+// entry in descriptions for the status code ("404") or its class ("4XX")
+// takes precedence over the HTTP reason phrase ("Not Found"); entries may
+// use the {code} and {reason} placeholders, as in "4XX": "{reason} error".
+// Statuses without a reason phrase are described as "Status <code> response".
+func ResponseDescription(status int, descriptions map[string]string) string {
+	code := strconv.Itoa(status)
+	reason := http.StatusText(status)
+	if reason == "" {
+		reason = "Status " + code + " response"
+	}
+
+	description, ok := descriptions[code]
+	if !ok && len(code) == 3 {
+		description, ok = descriptions[code[:1]+"XX"]
+	}
+	if !ok {
+		return reason
+	}
+	return strings.NewReplacer("{code}", code, "{reason}", reason).Replace(description)
+}
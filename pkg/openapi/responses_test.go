@@ -0,0 +1,35 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestResponseDescriptions(t *testing.T) {
+	var records []ir.IRRecord
+	for _, status := range []int{200, 404, 503, 599} {
+		records = append(records, ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items"},
+			Response: ir.Response{Status: status},
+		})
+	}
+	result := inference.InferFromRecords(records)
+
+	responses := GenerateFromInference(result, DefaultGeneratorOptions()).Paths["/items"].Get.Responses
+	for code, want := range map[string]string{"200": "OK", "404": "Not Found", "503": "Service Unavailable", "599": "Status 599 response"} {
+		if got := responses[code].Description; got != want {
+			t.Errorf("default description of %s = %q, want %q", code, got, want)
+		}
+	}
+
+	opts := DefaultGeneratorOptions()
+	opts.ResponseDescriptions = map[string]string{"404": "Resource not found", "5XX": "{reason} ({code}), retry later"}
+	responses = GenerateFromInference(result, opts).Paths["/items"].Get.Responses
+	for code, want := range map[string]string{"200": "OK", "404": "Resource not found", "503": "Service Unavailable (503), retry later"} {
+		if got := responses[code].Description; got != want {
+			t.Errorf("custom description of %s = %q, want %q", code, got, want)
+		}
+	}
+}
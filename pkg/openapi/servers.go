@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// observedServers returns a server for each scheme and host combination,
+// sorted by host and then scheme.
+func observedServers(hosts, schemes []string) []Server {
+	servers := make([]Server, 0, len(hosts)*len(schemes))
+	for _, host := range slices.Sorted(slices.Values(hosts)) {
+		for _, scheme := range slices.Sorted(slices.Values(schemes)) {
+			servers = append(servers, Server{
+				URL: fmt.Sprintf("%s://%s", scheme, host),
+			})
+		}
+	}
+	return servers
+}
+
+// addEndpointServers overrides the global servers on operations observed on
+// only some of the traffic's hosts. When every operation under a path item
+// has the same servers, they are moved to PathItem.Servers.
+func addEndpointServers(spec *Spec, result *inference.InferenceResult) {
+	for _, endpoint := range result.Endpoints {
+		if len(endpoint.Hosts) == 0 || len(endpoint.Hosts) >= len(result.Hosts) {
+			continue
+		}
+		op := operationForMethod(spec.Paths[endpoint.PathTemplate], endpoint.Method)
+		if op == nil {
+			continue
+		}
+		op.Servers = observedServers(endpoint.Hosts, result.Schemes)
+	}
+
+	for _, item := range spec.Paths {
+		hoistPathServers(item)
+	}
+}
+
+// hoistPathServers moves servers shared by every operation under a path item
+// to PathItem.Servers. Path items with a single operation are left as they
+// are.
+func hoistPathServers(item *PathItem) {
+	ops := pathItemOperations(item)
+	if len(ops) < 2 || len(ops[0].op.Servers) == 0 {
+		return
+	}
+	shared := ops[0].op.Servers
+	for _, mo := range ops[1:] {
+		if !slices.EqualFunc(mo.op.Servers, shared, func(a, b Server) bool { return a.URL == b.URL }) {
+			return
+		}
+	}
+
+	item.Servers = shared
+	for _, mo := range ops {
+		mo.op.Servers = nil
+	}
+}
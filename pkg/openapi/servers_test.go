@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestEndpointServers(t *testing.T) {
+	users, billing := "users.example.com", "billing.example.com"
+	records := []ir.IRRecord{
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Host: &users, Path: "/users"},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Host: &users, Path: "/users"},
+			Response: ir.Response{Status: 201},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Host: &billing, Path: "/invoices"},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Host: &users, Path: "/health"},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Host: &billing, Path: "/health"},
+			Response: ir.Response{Status: 200},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodDELETE, Host: &billing, Path: "/health"},
+			Response: ir.Response{Status: 204},
+		},
+	}
+	result := inference.InferFromRecords(records)
+	spec := GenerateFromInference(result, DefaultGeneratorOptions())
+
+	if len(spec.Servers) != 2 || spec.Servers[0].URL != "https://billing.example.com" {
+		t.Fatalf("expected sorted global servers for both hosts, got %v", spec.Servers)
+	}
+
+	usersItem := spec.Paths["/users"]
+	if len(usersItem.Servers) != 1 || usersItem.Servers[0].URL != "https://users.example.com" {
+		t.Errorf("expected path-level users server, got %v", usersItem.Servers)
+	}
+	if usersItem.Get.Servers != nil || usersItem.Post.Servers != nil {
+		t.Error("expected shared servers to be moved off the operations")
+	}
+	if invoices := spec.Paths["/invoices"].Get; len(invoices.Servers) != 1 || invoices.Servers[0].URL != "https://billing.example.com" {
+		t.Errorf("expected operation-level billing server, got %v", invoices.Servers)
+	}
+
+	health := spec.Paths["/health"]
+	if health.Servers != nil || health.Get.Servers != nil {
+		t.Errorf("expected GET /health to use the global servers, got %v %v", health.Servers, health.Get.Servers)
+	}
+	if len(health.Delete.Servers) != 1 || health.Delete.Servers[0].URL != "https://billing.example.com" {
+		t.Errorf("expected DELETE /health to override servers, got %v", health.Delete.Servers)
+	}
+
+	opts := DefaultGeneratorOptions()
+	opts.Servers = []string{"https://api.example.com"}
+	spec = GenerateFromInference(result, opts)
+	if spec.Paths["/invoices"].Get.Servers != nil || spec.Paths["/users"].Servers != nil {
+		t.Error("expected no overrides when servers are given explicitly")
+	}
+}
@@ -0,0 +1,184 @@
+package openapi
+
+// SpecStats summarizes the size and documentation completeness of a spec.
+type SpecStats struct {
+	Paths      int `json:"paths"`
+	Operations int `json:"operations"`
+	Parameters int `json:"parameters"` // path item and operation parameters
+	Schemas    int `json:"schemas"`    // component schemas
+
+	// Components counts each non-empty components section, keyed by its
+	// name in the spec, such as schemas or securitySchemes.
+	Components map[string]int `json:"components,omitempty"`
+
+	// ResponseCodes counts the operations declaring each response code,
+	// including ranges (2XX) and default.
+	ResponseCodes map[string]int `json:"responseCodes,omitempty"`
+
+	// Operations with a summary and with a description are counted
+	// separately: many specs document operations with a summary only.
+	OperationsWithSummary     int     `json:"operationsWithSummary"`
+	OperationsWithDescription int     `json:"operationsWithDescription"`
+	OperationsWithExamples    int     `json:"operationsWithExamples"`
+	SummaryPercent            float64 `json:"summaryPercent"`
+	DescriptionPercent        float64 `json:"descriptionPercent"`
+	ExamplePercent            float64 `json:"examplePercent"`
+
+	// AverageSchemaDepth is the mean nesting depth of the component schemas
+	// and of the inline request and response body schemas. A scalar has depth
+	// 1 and each level of object properties or array items adds 1; a $ref
+	// counts as 1 and is not followed.
+	AverageSchemaDepth float64 `json:"averageSchemaDepth"`
+	MaxSchemaDepth     int     `json:"maxSchemaDepth"`
+}
+
+// Statistics computes statistics for a spec, for tracking how completely it
+// is documented over time.
+func Statistics(spec *Spec) *SpecStats {
+	stats := &SpecStats{}
+	if spec == nil {
+		return stats
+	}
+
+	var depths []int
+	addSchema := func(schema *Schema) {
+		if schema != nil {
+			depths = append(depths, schemaDepth(schema))
+		}
+	}
+
+	stats.Paths = len(spec.Paths)
+	for _, path := range sortedPaths(spec) {
+		item := spec.Paths[path]
+		if item == nil {
+			continue
+		}
+		stats.Parameters += len(item.Parameters)
+		for _, mo := range pathItemOperations(item) {
+			op := mo.op
+			stats.Operations++
+			stats.Parameters += len(op.Parameters)
+			if op.Summary != "" {
+				stats.OperationsWithSummary++
+			}
+			if op.Description != "" {
+				stats.OperationsWithDescription++
+			}
+			if operationHasExamples(op) {
+				stats.OperationsWithExamples++
+			}
+			for code := range op.Responses {
+				if stats.ResponseCodes == nil {
+					stats.ResponseCodes = make(map[string]int)
+				}
+				stats.ResponseCodes[code]++
+			}
+
+			if op.RequestBody != nil {
+				for _, mt := range op.RequestBody.Content {
+					addSchema(mt.Schema)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, mt := range resp.Content {
+					addSchema(mt.Schema)
+				}
+			}
+		}
+	}
+
+	if c := spec.Components; c != nil {
+		stats.Schemas = len(c.Schemas)
+		for _, name := range sortedKeys(c.Schemas) {
+			addSchema(c.Schemas[name])
+		}
+		for name, n := range map[string]int{
+			"schemas":         len(c.Schemas),
+			"responses":       len(c.Responses),
+			"parameters":      len(c.Parameters),
+			"examples":        len(c.Examples),
+			"requestBodies":   len(c.RequestBodies),
+			"headers":         len(c.Headers),
+			"securitySchemes": len(c.SecuritySchemes),
+			"links":           len(c.Links),
+			"callbacks":       len(c.Callbacks),
+			"pathItems":       len(c.PathItems),
+		} {
+			if n == 0 {
+				continue
+			}
+			if stats.Components == nil {
+				stats.Components = make(map[string]int)
+			}
+			stats.Components[name] = n
+		}
+	}
+
+	if stats.Operations > 0 {
+		stats.SummaryPercent = float64(stats.OperationsWithSummary) / float64(stats.Operations) * 100
+		stats.DescriptionPercent = float64(stats.OperationsWithDescription) / float64(stats.Operations) * 100
+		stats.ExamplePercent = float64(stats.OperationsWithExamples) / float64(stats.Operations) * 100
+	}
+	if len(depths) > 0 {
+		total := 0
+		for _, d := range depths {
+			total += d
+			stats.MaxSchemaDepth = max(stats.MaxSchemaDepth, d)
+		}
+		stats.AverageSchemaDepth = float64(total) / float64(len(depths))
+	}
+	return stats
+}
+
+// operationHasExamples reports whether an operation has an example for any
+// parameter, request body, or response body.
+func operationHasExamples(op *Operation) bool {
+	for _, p := range op.Parameters {
+		if p.Example != nil || len(p.Examples) > 0 {
+			return true
+		}
+	}
+	hasExample := func(content map[string]MediaType) bool {
+		for _, mt := range content {
+			if mt.Example != nil || len(mt.Examples) > 0 {
+				return true
+			}
+		}
+		return false
+	}
+	if op.RequestBody != nil && hasExample(op.RequestBody.Content) {
+		return true
+	}
+	for _, resp := range op.Responses {
+		if hasExample(resp.Content) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaDepth returns the nesting depth of a schema. Composition keywords
+// (allOf, oneOf, anyOf) take the depth of their deepest member without adding
+// a level.
+func schemaDepth(schema *Schema) int {
+	if schema == nil {
+		return 0
+	}
+	if schema.Ref != "" {
+		return 1
+	}
+
+	child := 0
+	for _, prop := range schema.Properties {
+		child = max(child, schemaDepth(prop))
+	}
+	child = max(child, schemaDepth(schema.Items))
+
+	depth := 1 + child
+	for _, members := range [][]*Schema{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for _, member := range members {
+			depth = max(depth, schemaDepth(member))
+		}
+	}
+	return depth
+}
@@ -0,0 +1,59 @@
+package openapi
+
+import "testing"
+
+func TestStatistics(t *testing.T) {
+	spec := &Spec{
+		OpenAPI: "3.1.0",
+		Paths: map[string]*PathItem{
+			"/users/{id}": {
+				Parameters: []Parameter{{Name: "id", In: "path", Required: true}},
+				Get: &Operation{
+					Description: "Get a user by ID.",
+					Responses: map[string]Response{
+						"200": {Description: "OK", Content: map[string]MediaType{
+							"application/json": {Schema: &Schema{Ref: "#/components/schemas/User"}, Example: map[string]any{"id": 1}},
+						}},
+						"404": {Description: "Not found"},
+					},
+				},
+				Delete: &Operation{
+					Summary:    "Delete a user",
+					Parameters: []Parameter{{Name: "force", In: "query"}},
+					Responses:  map[string]Response{"204": {Description: "Deleted"}, "404": {Description: "Not found"}},
+				},
+			},
+		},
+		Components: &Components{
+			Schemas: map[string]*Schema{
+				"User": {Type: "object", Properties: map[string]*Schema{
+					"id":   {Type: "integer"},
+					"tags": {Type: "array", Items: &Schema{Type: "string"}},
+				}},
+			},
+			SecuritySchemes: map[string]*SecurityScheme{"bearer": {Type: "http", Scheme: "bearer"}},
+		},
+	}
+
+	stats := Statistics(spec)
+	if stats.Paths != 1 || stats.Operations != 2 || stats.Parameters != 2 || stats.Schemas != 1 {
+		t.Errorf("unexpected counts: %+v", stats)
+	}
+	if stats.Components["schemas"] != 1 || stats.Components["securitySchemes"] != 1 || len(stats.Components) != 2 {
+		t.Errorf("unexpected components: %v", stats.Components)
+	}
+	if stats.ResponseCodes["404"] != 2 || stats.ResponseCodes["200"] != 1 {
+		t.Errorf("unexpected response codes: %v", stats.ResponseCodes)
+	}
+	if stats.DescriptionPercent != 50 || stats.ExamplePercent != 50 {
+		t.Errorf("expected 50%% descriptions and examples, got %.1f and %.1f", stats.DescriptionPercent, stats.ExamplePercent)
+	}
+	// Summaries are counted separately from descriptions
+	if stats.OperationsWithSummary != 1 || stats.SummaryPercent != 50 || stats.OperationsWithDescription != 1 {
+		t.Errorf("expected 1 operation with a summary and 1 with a description, got %+v", stats)
+	}
+	// User has depth 3 (object > array > string); the $ref response schema 1
+	if stats.MaxSchemaDepth != 3 || stats.AverageSchemaDepth != 2 {
+		t.Errorf("expected max depth 3 and average 2, got %d and %.2f", stats.MaxSchemaDepth, stats.AverageSchemaDepth)
+	}
+}
@@ -0,0 +1,462 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// SynthesizeOptions configures synthetic traffic generation from a spec.
+type SynthesizeOptions struct {
+	// AllResponses emits one record per documented response status code.
+	// When false, only the primary (lowest 2xx) response is used.
+	AllResponses bool
+
+	// IncludeOptional includes optional parameters without examples.
+	// Optional parameters with examples are always included.
+	IncludeOptional bool
+
+	// ServerURL overrides the spec's first server URL for scheme, host, and base path.
+	ServerURL string
+}
+
+// DefaultSynthesizeOptions returns default synthesis options.
+func DefaultSynthesizeOptions() SynthesizeOptions {
+	return SynthesizeOptions{}
+}
+
+// maxExampleDepth bounds schema recursion when building examples.
+const maxExampleDepth = 8
+
+// Synthesize generates IR records from an OpenAPI spec, one per operation
+// (or per documented response with AllResponses), using spec examples where
+// available and schema-derived values otherwise.
+func Synthesize(spec *Spec, options SynthesizeOptions) ([]ir.IRRecord, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	serverURL := options.ServerURL
+	if serverURL == "" && len(spec.Servers) > 0 {
+		serverURL = spec.Servers[0].URL
+	}
+	server, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing server URL: %w", err)
+	}
+	basePath := strings.TrimSuffix(server.Path, "/")
+
+	s := &synthesizer{spec: spec, options: options}
+
+	paths := make([]string, 0, len(spec.Paths))
+	for p := range spec.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var records []ir.IRRecord
+	for _, pathTemplate := range paths {
+		item := spec.Paths[pathTemplate]
+		if item == nil {
+			continue
+		}
+		for _, mo := range pathItemOperations(item) {
+			for _, status := range s.responseCodes(mo.op) {
+				record := s.record(basePath+pathTemplate, mo.method, item, mo.op, status)
+				if server.Host != "" {
+					record.Request.Host = &server.Host
+				}
+				if server.Scheme != "" {
+					record.Request.Scheme = ir.RequestScheme(server.Scheme)
+				}
+				records = append(records, record)
+			}
+		}
+	}
+
+	return records, nil
+}
+
+type methodOperation struct {
+	method string
+	op     *Operation
+}
+
+// pathItemOperations returns a path item's operations in a stable method order.
+func pathItemOperations(item *PathItem) []methodOperation {
+	all := []methodOperation{
+		{"GET", item.Get}, {"POST", item.Post}, {"PUT", item.Put}, {"PATCH", item.Patch},
+		{"DELETE", item.Delete}, {"HEAD", item.Head}, {"OPTIONS", item.Options}, {"TRACE", item.Trace},
+	}
+	ops := make([]methodOperation, 0, len(all))
+	for _, mo := range all {
+		if mo.op != nil {
+			ops = append(ops, mo)
+		}
+	}
+	return ops
+}
+
+type synthesizer struct {
+	spec    *Spec
+	options SynthesizeOptions
+}
+
+// responseCodes returns the response codes to synthesize for an operation.
+func (s *synthesizer) responseCodes(op *Operation) []string {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if code != "default" {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	if len(codes) == 0 {
+		return []string{"default"}
+	}
+	if s.options.AllResponses {
+		return codes
+	}
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			return []string{code}
+		}
+	}
+	return codes[:1]
+}
+
+// record builds a single IR record for an operation and response code.
+func (s *synthesizer) record(pathTemplate, method string, item *PathItem, op *Operation, code string) ir.IRRecord {
+	source := ir.IRRecordSourceOpenAPI
+	record := ir.IRRecord{
+		Source: &source,
+		Request: ir.Request{
+			Method:       ir.RequestMethod(method),
+			PathTemplate: &pathTemplate,
+		},
+		Response: ir.Response{
+			Status: statusFromCode(code),
+		},
+	}
+
+	if op.OperationID != "" {
+		record.OperationId = ptrStr(op.OperationID)
+	}
+	if op.Summary != "" {
+		record.Summary = ptrStr(op.Summary)
+	}
+	if op.Description != "" {
+		record.Description = ptrStr(op.Description)
+	}
+	if len(op.Tags) > 0 {
+		record.Tags = op.Tags
+	}
+	if op.Deprecated {
+		record.Deprecated = &op.Deprecated
+	}
+
+	path := pathTemplate
+	for _, param := range mergeParameters(item.Parameters, op.Parameters) {
+		if param.In != "path" && !param.Required && param.Example == nil &&
+			!s.options.IncludeOptional && (param.Schema == nil || param.Schema.Example == nil) {
+			continue
+		}
+		value := param.Example
+		if value == nil {
+			value = s.example(param.Schema, 0)
+		}
+		if value == nil {
+			value = "example"
+		}
+
+		switch param.In {
+		case "path":
+			str := scalarToString(value)
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(str))
+			if record.Request.PathParams == nil {
+				record.Request.PathParams = make(map[string]string)
+			}
+			record.Request.PathParams[param.Name] = str
+		case "query":
+			if record.Request.Query == nil {
+				record.Request.Query = make(map[string]interface{})
+			}
+			if list, ok := value.([]any); ok {
+				record.Request.Query[param.Name] = list
+			} else {
+				record.Request.Query[param.Name] = scalarToString(value)
+			}
+		case "header":
+			if record.Request.Headers == nil {
+				record.Request.Headers = make(map[string]string)
+			}
+			record.Request.Headers[strings.ToLower(param.Name)] = scalarToString(value)
+		}
+	}
+	record.Request.Path = path
+
+	if op.RequestBody != nil {
+		if contentType, media, ok := preferredMedia(op.RequestBody.Content); ok {
+			record.Request.ContentType = ptrStr(contentType)
+			record.Request.Body = s.mediaExample(media)
+		}
+	}
+
+	if resp, ok := op.Responses[code]; ok {
+		if contentType, media, ok := preferredMedia(resp.Content); ok {
+			record.Response.ContentType = ptrStr(contentType)
+			record.Response.Body = s.mediaExample(media)
+		}
+		for _, name := range sortedHeaderNames(resp.Headers) {
+			value := s.example(resp.Headers[name].Schema, 0)
+			if value == nil {
+				continue
+			}
+			if record.Response.Headers == nil {
+				record.Response.Headers = make(map[string]string)
+			}
+			record.Response.Headers[strings.ToLower(name)] = scalarToString(value)
+		}
+	}
+
+	return record
+}
+
+// mediaExample returns the example for a media type: explicit example, first
+// named example, or a schema-derived value.
+func (s *synthesizer) mediaExample(media MediaType) any {
+	if media.Example != nil {
+		return media.Example
+	}
+	if len(media.Examples) > 0 {
+		names := make([]string, 0, len(media.Examples))
+		for name := range media.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if v := media.Examples[names[0]].Value; v != nil {
+			return v
+		}
+	}
+	return s.example(media.Schema, 0)
+}
+
+// example builds an example value for a schema.
+func (s *synthesizer) example(schema *Schema, depth int) any {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		return s.example(s.resolveRef(schema.Ref), depth+1)
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case len(schema.Examples) > 0:
+		return schema.Examples[0]
+	case schema.Default != nil:
+		return schema.Default
+	case schema.Const != nil:
+		return schema.Const
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+
+	if len(schema.AllOf) > 0 {
+		merged := make(map[string]any)
+		for _, sub := range schema.AllOf {
+			if obj, ok := s.example(sub, depth+1).(map[string]any); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	if len(schema.OneOf) > 0 {
+		return s.example(schema.OneOf[0], depth+1)
+	}
+	if len(schema.AnyOf) > 0 {
+		return s.example(schema.AnyOf[0], depth+1)
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		obj := make(map[string]any, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			if v := s.example(prop, depth+1); v != nil {
+				obj[name] = v
+			}
+		}
+		return obj
+	case "array":
+		if item := s.example(schema.Items, depth+1); item != nil {
+			return []any{item}
+		}
+		return []any{}
+	case "string":
+		return stringExample(schema.Format)
+	case "integer":
+		if schema.Minimum != nil {
+			return int(*schema.Minimum)
+		}
+		return 1
+	case "number":
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+		return 1.5
+	case "boolean":
+		return true
+	}
+
+	return nil
+}
+
+// resolveRef resolves a local component schema reference.
+func (s *synthesizer) resolveRef(ref string) *Schema {
+	const prefix = "#/components/schemas/"
+	if s.spec.Components == nil || !strings.HasPrefix(ref, prefix) {
+		return nil
+	}
+	return s.spec.Components.Schemas[strings.TrimPrefix(ref, prefix)]
+}
+
+// schemaType returns the primary type of a schema, inferring object/array
+// from properties/items when type is omitted.
+func schemaType(schema *Schema) string {
+	switch t := schema.Type.(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if str, ok := v.(string); ok && str != "null" {
+				return str
+			}
+		}
+	case []string:
+		for _, str := range t {
+			if str != "null" {
+				return str
+			}
+		}
+	}
+	if len(schema.Properties) > 0 {
+		return "object"
+	}
+	if schema.Items != nil {
+		return "array"
+	}
+	return ""
+}
+
+// stringExample returns a plausible value for a string format.
+func stringExample(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "time":
+		return "00:00:00"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-4000-8000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	case "hostname":
+		return "example.com"
+	case "ipv4":
+		return "192.0.2.1"
+	case "ipv6":
+		return "2001:db8::1"
+	default:
+		return "string"
+	}
+}
+
+// preferredMedia picks JSON content when present, else the first media type.
+func preferredMedia(content map[string]MediaType) (string, MediaType, bool) {
+	if len(content) == 0 {
+		return "", MediaType{}, false
+	}
+	types := make([]string, 0, len(content))
+	for ct := range content {
+		types = append(types, ct)
+	}
+	sort.Strings(types)
+	for _, ct := range types {
+		if strings.Contains(ct, "json") {
+			return ct, content[ct], true
+		}
+	}
+	return types[0], content[types[0]], true
+}
+
+// mergeParameters combines path-level and operation-level parameters;
+// operation parameters override path parameters with the same name and location.
+func mergeParameters(pathParams, opParams []Parameter) []Parameter {
+	merged := make([]Parameter, 0, len(pathParams)+len(opParams))
+	index := make(map[string]int)
+	for _, list := range [][]Parameter{pathParams, opParams} {
+		for _, p := range list {
+			key := p.In + ":" + p.Name
+			if i, ok := index[key]; ok {
+				merged[i] = p
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+// statusFromCode converts a response code ("200", "4XX", "default") to a status.
+func statusFromCode(code string) int {
+	if status, err := strconv.Atoi(code); err == nil {
+		return status
+	}
+	if len(code) == 3 && strings.HasSuffix(strings.ToUpper(code), "XX") {
+		if class, err := strconv.Atoi(code[:1]); err == nil {
+			return class * 100
+		}
+	}
+	return 200
+}
+
+func sortedHeaderNames(headers map[string]Header) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func scalarToString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []any:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = scalarToString(item)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func ptrStr(s string) *string {
+	return &s
+}
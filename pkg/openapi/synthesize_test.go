@@ -0,0 +1,110 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+func TestSynthesize(t *testing.T) {
+	spec, err := FromYAML([]byte(`
+openapi: 3.1.0
+info: {title: Users, version: "1.0"}
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users/{userId}:
+    parameters:
+      - {name: userId, in: path, required: true, schema: {type: integer, example: 42}}
+    get:
+      operationId: getUser
+      tags: [users]
+      parameters:
+        - {name: fields, in: query, schema: {type: string}}
+        - {name: X-Request-Id, in: header, required: true, schema: {type: string, format: uuid}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/User"}
+        "404":
+          description: Not found
+  /users:
+    post:
+      requestBody:
+        content:
+          application/json:
+            example: {name: Alice}
+      responses:
+        "201": {description: Created}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id: {type: integer}
+        email: {type: string, format: email}
+        roles: {type: array, items: {type: string, enum: [admin, user]}}
+`))
+	if err != nil {
+		t.Fatalf("FromYAML failed: %v", err)
+	}
+
+	records, err := Synthesize(spec, DefaultSynthesizeOptions())
+	if err != nil {
+		t.Fatalf("Synthesize failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	post, get := records[0], records[1]
+	if post.Request.Path != "/v1/users" || post.Response.Status != 201 {
+		t.Errorf("unexpected POST record: %s %d", post.Request.Path, post.Response.Status)
+	}
+	if body, ok := post.Request.Body.(map[string]any); !ok || body["name"] != "Alice" {
+		t.Errorf("expected request body example, got %v", post.Request.Body)
+	}
+
+	if get.Request.Path != "/v1/users/42" {
+		t.Errorf("expected /v1/users/42, got %s", get.Request.Path)
+	}
+	if get.Request.Host == nil || *get.Request.Host != "api.example.com" {
+		t.Errorf("unexpected host: %v", get.Request.Host)
+	}
+	if get.OperationId == nil || *get.OperationId != "getUser" {
+		t.Errorf("expected operationId getUser, got %v", get.OperationId)
+	}
+	if _, ok := get.Request.Query["fields"]; ok {
+		t.Error("optional query param without example should be skipped")
+	}
+	if get.Request.Headers["x-request-id"] == "" {
+		t.Error("expected required header to be synthesized")
+	}
+	body, ok := get.Response.Body.(map[string]any)
+	if !ok || body["email"] != "user@example.com" {
+		t.Fatalf("expected schema-derived response body, got %v", get.Response.Body)
+	}
+	if roles, ok := body["roles"].([]any); !ok || len(roles) != 1 || roles[0] != "admin" {
+		t.Errorf("expected roles [admin], got %v", body["roles"])
+	}
+
+	options := DefaultSynthesizeOptions()
+	options.AllResponses = true
+	all, err := Synthesize(spec, options)
+	if err != nil {
+		t.Fatalf("Synthesize failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 records with AllResponses, got %d", len(all))
+	}
+
+	// Synthesized traffic should infer back to the same operations
+	engine := inference.NewEngine(inference.DefaultEngineOptions())
+	engine.ProcessRecords(all)
+	regenerated := GenerateFromInference(engine.Finalize(), DefaultGeneratorOptions())
+	if item := regenerated.Paths["/v1/users/{userId}"]; item == nil || item.Get == nil {
+		t.Errorf("expected GET /v1/users/{userId} in regenerated spec, got paths %v", regenerated.Paths)
+	}
+}
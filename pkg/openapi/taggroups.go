@@ -0,0 +1,123 @@
+package openapi
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// TagGroupBy controls how tags are grouped in the x-tagGroups extension.
+type TagGroupBy string
+
+const (
+	// TagGroupNone emits no x-tagGroups.
+	TagGroupNone TagGroupBy = ""
+
+	// TagGroupResource groups tags by the top-level resource of their paths.
+	TagGroupResource TagGroupBy = "resource"
+
+	// TagGroupHost groups tags by the host their operations were observed on.
+	TagGroupHost TagGroupBy = "host"
+)
+
+// defaultTagGroup names the group or tag for operations with no resource or host.
+const defaultTagGroup = "default"
+
+// versionSegment matches path prefixes skipped when finding the top-level resource.
+var versionSegment = regexp.MustCompile(`^(api|v\d+(\.\d+)*)$`)
+
+// TagGroup is an entry of the x-tagGroups extension (Redoc).
+type TagGroup struct {
+	Name string   `json:"name" yaml:"name"`
+	Tags []string `json:"tags" yaml:"tags"`
+}
+
+// addTagGroups emits x-tagGroups and orders paths by tag then path.
+// Operations without tags are tagged with their top-level resource, since
+// Redoc hides untagged operations once tag groups are present.
+func (g *Generator) addTagGroups(spec *Spec, result *inference.InferenceResult) {
+	keys := make([]string, 0, len(result.Endpoints))
+	for key := range result.Endpoints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	groupTags := make(map[string][]string)
+	tagGroup := make(map[string]string)
+	pathTag := make(map[string]string) // path -> first tag, for ordering
+	for _, key := range keys {
+		endpoint := result.Endpoints[key]
+		op := operationForMethod(spec.Paths[endpoint.PathTemplate], endpoint.Method)
+		if op == nil {
+			continue
+		}
+		if len(op.Tags) == 0 {
+			op.Tags = []string{topLevelResource(endpoint.PathTemplate)}
+		}
+
+		group := g.tagGroupName(endpoint)
+		for _, tag := range op.Tags {
+			if _, ok := tagGroup[tag]; !ok {
+				tagGroup[tag] = group
+				groupTags[group] = append(groupTags[group], tag)
+			}
+		}
+		if tag, ok := pathTag[endpoint.PathTemplate]; !ok || op.Tags[0] < tag {
+			pathTag[endpoint.PathTemplate] = op.Tags[0]
+		}
+	}
+	if len(groupTags) == 0 {
+		return
+	}
+
+	groups := make([]TagGroup, 0, len(groupTags))
+	for name, tags := range groupTags {
+		sort.Strings(tags)
+		groups = append(groups, TagGroup{Name: name, Tags: tags})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	if spec.Extensions == nil {
+		spec.Extensions = Extensions{}
+	}
+	spec.Extensions["x-tagGroups"] = groups
+
+	// Declare every grouped tag
+	for _, group := range groups {
+		for _, tag := range group.Tags {
+			if !slices.ContainsFunc(spec.Tags, func(t Tag) bool { return t.Name == tag }) {
+				spec.Tags = append(spec.Tags, Tag{Name: tag})
+			}
+		}
+	}
+
+	spec.PathOrder = sortedPaths(spec)
+	sort.SliceStable(spec.PathOrder, func(i, j int) bool {
+		return pathTag[spec.PathOrder[i]] < pathTag[spec.PathOrder[j]]
+	})
+}
+
+// tagGroupName returns the group an endpoint's tags belong to.
+func (g *Generator) tagGroupName(endpoint *inference.EndpointData) string {
+	if g.options.TagGroups == TagGroupHost {
+		if len(endpoint.Hosts) == 0 {
+			return defaultTagGroup
+		}
+		return slices.Min(endpoint.Hosts)
+	}
+	return topLevelResource(endpoint.PathTemplate)
+}
+
+// topLevelResource returns the first static path segment, skipping "api"
+// and version prefixes: /api/v1/users/{id} -> users.
+func topLevelResource(path string) string {
+	for _, seg := range strings.Split(path, "/") {
+		if seg == "" || strings.HasPrefix(seg, "{") || versionSegment.MatchString(seg) {
+			continue
+		}
+		return seg
+	}
+	return defaultTagGroup
+}
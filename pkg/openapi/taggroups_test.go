@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestTagGroups(t *testing.T) {
+	engine := inference.NewEngine(inference.DefaultEngineOptions())
+	records := []struct {
+		host, path string
+		tags       []string
+	}{
+		{"api.example.com", "/api/v1/users", nil},
+		{"api.example.com", "/api/v1/users/123", nil},
+		{"billing.example.com", "/invoices", []string{"Billing"}},
+		{"api.example.com", "/accounts", nil},
+	}
+	for _, r := range records {
+		host := r.host
+		engine.ProcessRecord(&ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Host: &host, Path: r.path},
+			Response: ir.Response{Status: 200},
+			Tags:     r.tags,
+		})
+	}
+	result := engine.Finalize()
+
+	if spec := GenerateFromInference(result, DefaultGeneratorOptions()); spec.Extensions != nil || spec.PathOrder != nil {
+		t.Errorf("expected no tag groups by default, got %v", spec.Extensions)
+	}
+
+	opts := DefaultGeneratorOptions()
+	opts.TagGroups = TagGroupHost
+	spec := GenerateFromInference(result, opts)
+
+	groups, ok := spec.Extensions["x-tagGroups"].([]TagGroup)
+	if !ok {
+		t.Fatalf("expected x-tagGroups, got %v", spec.Extensions)
+	}
+	want := []TagGroup{
+		{Name: "api.example.com", Tags: []string{"accounts", "users"}},
+		{Name: "billing.example.com", Tags: []string{"Billing"}},
+	}
+	if fmt.Sprint(groups) != fmt.Sprint(want) {
+		t.Errorf("expected groups %v, got %v", want, groups)
+	}
+	if got := spec.Paths["/api/v1/users/{userId}"].Get.Tags; len(got) != 1 || got[0] != "users" {
+		t.Errorf("expected untagged operation tagged users, got %v", got)
+	}
+
+	// Paths are ordered by tag, then path
+	data, err := ToJSON(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := []string{`"/invoices"`, `"/accounts"`, `"/api/v1/users"`, `"/api/v1/users/{userId}"`, `"x-tagGroups"`}
+	last := -1
+	for _, key := range order {
+		i := strings.Index(string(data), key)
+		if i <= last {
+			t.Fatalf("expected %s after previous keys in %v", key, order)
+		}
+		last = i
+	}
+}
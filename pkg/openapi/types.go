@@ -3,13 +3,21 @@ package openapi
 
 // Spec represents an OpenAPI 3.x specification.
 type Spec struct {
-	OpenAPI      string               `json:"openapi" yaml:"openapi"`
-	Info         Info                 `json:"info" yaml:"info"`
-	Servers      []Server             `json:"servers,omitempty" yaml:"servers,omitempty"`
-	Paths        map[string]*PathItem `json:"paths" yaml:"paths"`
-	Components   *Components          `json:"components,omitempty" yaml:"components,omitempty"`
-	Tags         []Tag                `json:"tags,omitempty" yaml:"tags,omitempty"`
-	ExternalDocs *ExternalDocs        `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	OpenAPI           string                `json:"openapi" yaml:"openapi"`
+	Info              Info                  `json:"info" yaml:"info"`
+	JSONSchemaDialect string                `json:"jsonSchemaDialect,omitempty" yaml:"jsonSchemaDialect,omitempty"` // 3.1+
+	Servers           []Server              `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths             map[string]*PathItem  `json:"paths" yaml:"paths"`
+	Webhooks          map[string]*PathItem  `json:"webhooks,omitempty" yaml:"webhooks,omitempty"` // 3.1+
+	Components        *Components           `json:"components,omitempty" yaml:"components,omitempty"`
+	Security          []SecurityRequirement `json:"security,omitempty" yaml:"security,omitempty"`
+	Tags              []Tag                 `json:"tags,omitempty" yaml:"tags,omitempty"`
+	ExternalDocs      *ExternalDocs         `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	Extensions        Extensions            `json:"-" yaml:"-"` // x- fields, see extensions.go
+
+	// PathOrder lists paths in the order they are written. Paths not listed
+	// follow in sorted order. It is not read back from documents.
+	PathOrder []string `json:"-" yaml:"-"`
 }
 
 // Tag represents a tag for grouping operations.
@@ -17,45 +25,63 @@ type Tag struct {
 	Name         string        `json:"name" yaml:"name"`
 	Description  string        `json:"description,omitempty" yaml:"description,omitempty"`
 	ExternalDocs *ExternalDocs `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	Extensions   Extensions    `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // ExternalDocs represents external documentation.
 type ExternalDocs struct {
-	URL         string `json:"url" yaml:"url"`
-	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	URL         string     `json:"url" yaml:"url"`
+	Description string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Extensions  Extensions `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // Info provides metadata about the API.
 type Info struct {
-	Title          string   `json:"title" yaml:"title"`
-	Description    string   `json:"description,omitempty" yaml:"description,omitempty"`
-	TermsOfService string   `json:"termsOfService,omitempty" yaml:"termsOfService,omitempty"`
-	Contact        *Contact `json:"contact,omitempty" yaml:"contact,omitempty"`
-	License        *License `json:"license,omitempty" yaml:"license,omitempty"`
-	Version        string   `json:"version" yaml:"version"`
+	Title          string     `json:"title" yaml:"title"`
+	Summary        string     `json:"summary,omitempty" yaml:"summary,omitempty"` // 3.1+
+	Description    string     `json:"description,omitempty" yaml:"description,omitempty"`
+	TermsOfService string     `json:"termsOfService,omitempty" yaml:"termsOfService,omitempty"`
+	Contact        *Contact   `json:"contact,omitempty" yaml:"contact,omitempty"`
+	License        *License   `json:"license,omitempty" yaml:"license,omitempty"`
+	Version        string     `json:"version" yaml:"version"`
+	Extensions     Extensions `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // Contact information for the API.
 type Contact struct {
-	Name  string `json:"name,omitempty" yaml:"name,omitempty"`
-	URL   string `json:"url,omitempty" yaml:"url,omitempty"`
-	Email string `json:"email,omitempty" yaml:"email,omitempty"`
+	Name       string     `json:"name,omitempty" yaml:"name,omitempty"`
+	URL        string     `json:"url,omitempty" yaml:"url,omitempty"`
+	Email      string     `json:"email,omitempty" yaml:"email,omitempty"`
+	Extensions Extensions `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // License information for the API.
 type License struct {
-	Name string `json:"name" yaml:"name"`
-	URL  string `json:"url,omitempty" yaml:"url,omitempty"`
+	Name       string     `json:"name" yaml:"name"`
+	Identifier string     `json:"identifier,omitempty" yaml:"identifier,omitempty"` // SPDX expression, 3.1+
+	URL        string     `json:"url,omitempty" yaml:"url,omitempty"`
+	Extensions Extensions `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // Server represents an API server.
 type Server struct {
-	URL         string `json:"url" yaml:"url"`
-	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	URL         string                    `json:"url" yaml:"url"`
+	Description string                    `json:"description,omitempty" yaml:"description,omitempty"`
+	Variables   map[string]ServerVariable `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Extensions  Extensions                `json:"-" yaml:"-"` // x- fields, see extensions.go
+}
+
+// ServerVariable is a substitution for a variable in a server URL template.
+type ServerVariable struct {
+	Enum        []string   `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Default     string     `json:"default" yaml:"default"`
+	Description string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Extensions  Extensions `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // PathItem describes operations available on a single path.
 type PathItem struct {
+	Ref         string      `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 	Summary     string      `json:"summary,omitempty" yaml:"summary,omitempty"`
 	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
 	Get         *Operation  `json:"get,omitempty" yaml:"get,omitempty"`
@@ -66,68 +92,134 @@ type PathItem struct {
 	Head        *Operation  `json:"head,omitempty" yaml:"head,omitempty"`
 	Patch       *Operation  `json:"patch,omitempty" yaml:"patch,omitempty"`
 	Trace       *Operation  `json:"trace,omitempty" yaml:"trace,omitempty"`
+	Servers     []Server    `json:"servers,omitempty" yaml:"servers,omitempty"`
 	Parameters  []Parameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Extensions  Extensions  `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // Operation describes a single API operation on a path.
 type Operation struct {
-	Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
-	Summary     string                `json:"summary,omitempty" yaml:"summary,omitempty"`
-	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
-	OperationID string                `json:"operationId,omitempty" yaml:"operationId,omitempty"`
-	Parameters  []Parameter           `json:"parameters,omitempty" yaml:"parameters,omitempty"`
-	RequestBody *RequestBody          `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
-	Responses   map[string]Response   `json:"responses" yaml:"responses"`
-	Deprecated  bool                  `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
-	Security    []SecurityRequirement `json:"security,omitempty" yaml:"security,omitempty"`
-}
-
-// Parameter describes a single operation parameter.
+	Tags         []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary      string                `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description  string                `json:"description,omitempty" yaml:"description,omitempty"`
+	ExternalDocs *ExternalDocs         `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	OperationID  string                `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Parameters   []Parameter           `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody  *RequestBody          `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses    map[string]Response   `json:"responses" yaml:"responses"`
+	Callbacks    map[string]*Callback  `json:"callbacks,omitempty" yaml:"callbacks,omitempty"`
+	Deprecated   bool                  `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Security     []SecurityRequirement `json:"security,omitempty" yaml:"security,omitempty"`
+	Servers      []Server              `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Extensions   Extensions            `json:"-" yaml:"-"` // x- fields, see extensions.go
+}
+
+// Callback maps runtime expressions, such as {$request.body#/callbackUrl},
+// to the path items of requests the API may send. A Callback with a Ref is
+// a reference to a component and has no expressions.
+type Callback struct {
+	Ref         string
+	Expressions map[string]*PathItem
+	Extensions  Extensions // x- fields, see extensions.go
+}
+
+// Parameter describes a single operation parameter. A Parameter with a Ref
+// is a reference to a component and is encoded as {$ref, description}.
 type Parameter struct {
-	Name            string  `json:"name" yaml:"name"`
-	In              string  `json:"in" yaml:"in"` // query, header, path, cookie
-	Description     string  `json:"description,omitempty" yaml:"description,omitempty"`
-	Required        bool    `json:"required,omitempty" yaml:"required,omitempty"`
-	Deprecated      bool    `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
-	AllowEmptyValue bool    `json:"allowEmptyValue,omitempty" yaml:"allowEmptyValue,omitempty"`
-	Schema          *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
-	Example         any     `json:"example,omitempty" yaml:"example,omitempty"`
+	Ref             string               `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Name            string               `json:"name" yaml:"name"`
+	In              string               `json:"in" yaml:"in"` // query, header, path, cookie
+	Description     string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Required        bool                 `json:"required,omitempty" yaml:"required,omitempty"`
+	Deprecated      bool                 `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	AllowEmptyValue bool                 `json:"allowEmptyValue,omitempty" yaml:"allowEmptyValue,omitempty"`
+	Style           string               `json:"style,omitempty" yaml:"style,omitempty"` // form, simple, deepObject, etc.
+	Explode         *bool                `json:"explode,omitempty" yaml:"explode,omitempty"`
+	AllowReserved   bool                 `json:"allowReserved,omitempty" yaml:"allowReserved,omitempty"`
+	Schema          *Schema              `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example         any                  `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples        map[string]Example   `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Content         map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"` // instead of Schema for complex values
+	Extensions      Extensions           `json:"-" yaml:"-"`                                 // x- fields, see extensions.go
 }
 
-// RequestBody describes a single request body.
+// RequestBody describes a single request body. A RequestBody with a Ref is
+// a reference to a component.
 type RequestBody struct {
+	Ref         string               `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
 	Content     map[string]MediaType `json:"content" yaml:"content"`
 	Required    bool                 `json:"required,omitempty" yaml:"required,omitempty"`
+	Extensions  Extensions           `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
-// Response describes a single response from an API operation.
+// Response describes a single response from an API operation. A Response
+// with a Ref is a reference to a component.
 type Response struct {
+	Ref         string               `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 	Description string               `json:"description" yaml:"description"`
 	Headers     map[string]Header    `json:"headers,omitempty" yaml:"headers,omitempty"`
 	Content     map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+	Links       map[string]Link      `json:"links,omitempty" yaml:"links,omitempty"`
+	Extensions  Extensions           `json:"-" yaml:"-"` // x- fields, see extensions.go
+}
+
+// Link describes how values from a response can be used as parameters of
+// another operation.
+type Link struct {
+	Ref          string         `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	OperationRef string         `json:"operationRef,omitempty" yaml:"operationRef,omitempty"`
+	OperationID  string         `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Parameters   map[string]any `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody  any            `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Description  string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Server       *Server        `json:"server,omitempty" yaml:"server,omitempty"`
+	Extensions   Extensions     `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // Header describes a single header.
 type Header struct {
-	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
-	Required    bool    `json:"required,omitempty" yaml:"required,omitempty"`
-	Schema      *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Ref         string               `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty" yaml:"required,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Style       string               `json:"style,omitempty" yaml:"style,omitempty"`
+	Explode     *bool                `json:"explode,omitempty" yaml:"explode,omitempty"`
+	Schema      *Schema              `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example     any                  `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples    map[string]Example   `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+	Extensions  Extensions           `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // MediaType provides schema and examples for the media type.
 type MediaType struct {
-	Schema   *Schema            `json:"schema,omitempty" yaml:"schema,omitempty"`
-	Example  any                `json:"example,omitempty" yaml:"example,omitempty"`
-	Examples map[string]Example `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Schema     *Schema             `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example    any                 `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples   map[string]Example  `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Encoding   map[string]Encoding `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	Extensions Extensions          `json:"-" yaml:"-"` // x- fields, see extensions.go
+}
+
+// Encoding describes how a property of a multipart or form request body is
+// serialized.
+type Encoding struct {
+	ContentType   string            `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+	Headers       map[string]Header `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Style         string            `json:"style,omitempty" yaml:"style,omitempty"`
+	Explode       *bool             `json:"explode,omitempty" yaml:"explode,omitempty"`
+	AllowReserved bool              `json:"allowReserved,omitempty" yaml:"allowReserved,omitempty"`
+	Extensions    Extensions        `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // Example describes an example value.
 type Example struct {
-	Summary       string `json:"summary,omitempty" yaml:"summary,omitempty"`
-	Description   string `json:"description,omitempty" yaml:"description,omitempty"`
-	Value         any    `json:"value,omitempty" yaml:"value,omitempty"`
-	ExternalValue string `json:"externalValue,omitempty" yaml:"externalValue,omitempty"`
+	Ref           string     `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Summary       string     `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description   string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Value         any        `json:"value,omitempty" yaml:"value,omitempty"`
+	ExternalValue string     `json:"externalValue,omitempty" yaml:"externalValue,omitempty"`
+	Extensions    Extensions `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // Schema represents a JSON Schema (OpenAPI 3.1 uses JSON Schema 2020-12).
@@ -163,6 +255,9 @@ type Schema struct {
 	MinLength *int   `json:"minLength,omitempty" yaml:"minLength,omitempty"`
 	Pattern   string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
 
+	// ContentMediaType is the media type of string content (3.1+)
+	ContentMediaType string `json:"contentMediaType,omitempty" yaml:"contentMediaType,omitempty"`
+
 	// Array
 	Items       *Schema `json:"items,omitempty" yaml:"items,omitempty"`
 	MaxItems    *int    `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
@@ -185,6 +280,11 @@ type Schema struct {
 	// Reference
 	Ref string `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 
+	// Polymorphism and documentation
+	Discriminator *Discriminator `json:"discriminator,omitempty" yaml:"discriminator,omitempty"`
+	XML           *XML           `json:"xml,omitempty" yaml:"xml,omitempty"`
+	ExternalDocs  *ExternalDocs  `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+
 	// Examples (OpenAPI 3.1)
 	Examples []any `json:"examples,omitempty" yaml:"examples,omitempty"`
 
@@ -194,6 +294,26 @@ type Schema struct {
 	// Read/Write only
 	ReadOnly  bool `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
 	WriteOnly bool `json:"writeOnly,omitempty" yaml:"writeOnly,omitempty"`
+
+	// Extensions holds x- fields, see extensions.go
+	Extensions Extensions `json:"-" yaml:"-"`
+}
+
+// Discriminator selects the schema of a oneOf or anyOf by a property value.
+type Discriminator struct {
+	PropertyName string            `json:"propertyName" yaml:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty" yaml:"mapping,omitempty"`
+	Extensions   Extensions        `json:"-" yaml:"-"` // x- fields, see extensions.go
+}
+
+// XML describes how a schema is represented in XML.
+type XML struct {
+	Name       string     `json:"name,omitempty" yaml:"name,omitempty"`
+	Namespace  string     `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Prefix     string     `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Attribute  bool       `json:"attribute,omitempty" yaml:"attribute,omitempty"`
+	Wrapped    bool       `json:"wrapped,omitempty" yaml:"wrapped,omitempty"`
+	Extensions Extensions `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // Components holds reusable objects.
@@ -205,6 +325,10 @@ type Components struct {
 	RequestBodies   map[string]*RequestBody    `json:"requestBodies,omitempty" yaml:"requestBodies,omitempty"`
 	Headers         map[string]*Header         `json:"headers,omitempty" yaml:"headers,omitempty"`
 	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+	Links           map[string]*Link           `json:"links,omitempty" yaml:"links,omitempty"`
+	Callbacks       map[string]*Callback       `json:"callbacks,omitempty" yaml:"callbacks,omitempty"`
+	PathItems       map[string]*PathItem       `json:"pathItems,omitempty" yaml:"pathItems,omitempty"` // 3.1+
+	Extensions      Extensions                 `json:"-" yaml:"-"`                                     // x- fields, see extensions.go
 }
 
 // SecurityScheme defines a security scheme.
@@ -217,6 +341,7 @@ type SecurityScheme struct {
 	BearerFormat     string      `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`         // for http bearer
 	Flows            *OAuthFlows `json:"flows,omitempty" yaml:"flows,omitempty"`                       // for oauth2
 	OpenIdConnectUrl string      `json:"openIdConnectUrl,omitempty" yaml:"openIdConnectUrl,omitempty"` // for openIdConnect
+	Extensions       Extensions  `json:"-" yaml:"-"`                                                   // x- fields, see extensions.go
 }
 
 // OAuthFlows defines OAuth 2.0 flows.
@@ -225,6 +350,7 @@ type OAuthFlows struct {
 	Password          *OAuthFlow `json:"password,omitempty" yaml:"password,omitempty"`
 	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty" yaml:"clientCredentials,omitempty"`
 	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty" yaml:"authorizationCode,omitempty"`
+	Extensions        Extensions `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // OAuthFlow defines a single OAuth 2.0 flow.
@@ -233,6 +359,7 @@ type OAuthFlow struct {
 	TokenUrl         string            `json:"tokenUrl,omitempty" yaml:"tokenUrl,omitempty"`
 	RefreshUrl       string            `json:"refreshUrl,omitempty" yaml:"refreshUrl,omitempty"`
 	Scopes           map[string]string `json:"scopes" yaml:"scopes"`
+	Extensions       Extensions        `json:"-" yaml:"-"` // x- fields, see extensions.go
 }
 
 // SecurityRequirement defines security requirements for an operation.
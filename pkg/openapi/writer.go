@@ -2,6 +2,7 @@ package openapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +12,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// ErrInvalidSpec is returned when a spec cannot be parsed. The parser's
+// error is wrapped as well, so errors.As still finds it.
+var ErrInvalidSpec = errors.New("invalid OpenAPI spec")
+
 // Format represents the output format.
 type Format string
 
@@ -118,7 +123,7 @@ func ReadFile(path string) (*Spec, error) {
 func FromJSON(data []byte) (*Spec, error) {
 	var spec Spec
 	if err := json.Unmarshal(data, &spec); err != nil {
-		return nil, fmt.Errorf("parsing JSON: %w", err)
+		return nil, fmt.Errorf("%w: parsing JSON: %w", ErrInvalidSpec, err)
 	}
 	return &spec, nil
 }
@@ -127,7 +132,7 @@ func FromJSON(data []byte) (*Spec, error) {
 func FromYAML(data []byte) (*Spec, error) {
 	var spec Spec
 	if err := yaml.Unmarshal(data, &spec); err != nil {
-		return nil, fmt.Errorf("parsing YAML: %w", err)
+		return nil, fmt.Errorf("%w: parsing YAML: %w", ErrInvalidSpec, err)
 	}
 	return &spec, nil
 }
@@ -0,0 +1,182 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrInvalidSpec(t *testing.T) {
+	_, err := FromJSON([]byte(`{"openapi": 3.1,`))
+	if !errors.Is(err, ErrInvalidSpec) {
+		t.Errorf("expected ErrInvalidSpec for JSON, got %v", err)
+	}
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("expected wrapped json.SyntaxError, got %v", err)
+	}
+
+	if _, err := FromYAML([]byte("openapi: [3.1")); !errors.Is(err, ErrInvalidSpec) {
+		t.Errorf("expected ErrInvalidSpec for YAML, got %v", err)
+	}
+}
+
+func TestSpecRoundTrip(t *testing.T) {
+	input := `openapi: 3.1.0
+info:
+  title: Orders
+  summary: Order management
+  version: "1.0"
+  license:
+    name: Apache 2.0
+    identifier: Apache-2.0
+jsonSchemaDialect: https://spec.openapis.org/oas/3.1/dialect/base
+servers:
+  - url: https://{region}.example.com
+    variables:
+      region:
+        enum: [eu, us]
+        default: us
+security:
+  - apiKey: []
+tags:
+  - name: orders
+    externalDocs:
+      url: https://docs.example.com/orders
+paths:
+  /orders:
+    servers:
+      - url: https://orders.example.com
+    parameters:
+      - $ref: '#/components/parameters/Tenant'
+    post:
+      externalDocs:
+        url: https://docs.example.com/create
+      parameters:
+        - name: filter
+          in: query
+          style: deepObject
+          explode: true
+          content:
+            application/json:
+              schema:
+                type: object
+      requestBody:
+        $ref: '#/components/requestBodies/Order'
+      responses:
+        "201":
+          description: Created
+          links:
+            GetOrder:
+              operationRef: '#/paths/~1orders~1{id}/get'
+              parameters:
+                id: $response.body#/id
+        "404":
+          $ref: '#/components/responses/NotFound'
+      callbacks:
+        shipped:
+          '{$request.body#/callbackUrl}':
+            post:
+              responses:
+                "200":
+                  description: OK
+        audit:
+          $ref: '#/components/callbacks/Audit'
+webhooks:
+  orderCancelled:
+    post:
+      responses:
+        "200":
+          description: OK
+components:
+  parameters:
+    Tenant:
+      name: X-Tenant
+      in: header
+  requestBodies:
+    Order:
+      content:
+        application/json:
+          schema:
+            type: object
+  responses:
+    NotFound:
+      description: Not found
+  callbacks:
+    Audit:
+      '{$request.body#/auditUrl}':
+        post:
+          responses:
+            "204":
+              description: Received
+  links:
+    Self:
+      operationId: getOrder
+  pathItems:
+    Ping:
+      get:
+        responses:
+          "200":
+            description: OK
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: kind
+      xml:
+        name: pet
+`
+	spec, err := FromYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("FromYAML failed: %v", err)
+	}
+
+	for _, format := range []Format{FormatYAML, FormatJSON} {
+		out, err := ToString(spec, format)
+		if err != nil {
+			t.Fatalf("ToString(%s) failed: %v", format, err)
+		}
+		var again *Spec
+		if format == FormatJSON {
+			again, err = FromJSON([]byte(out))
+		} else {
+			again, err = FromYAML([]byte(out))
+		}
+		if err != nil {
+			t.Fatalf("re-reading %s failed: %v", format, err)
+		}
+
+		for _, want := range []string{"summary", "identifier", "jsonSchemaDialect", "variables", "security", "externalDocs",
+			"deepObject", "operationRef", "callbacks", "webhooks", "orderCancelled", "pathItems", "discriminator", "xml"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output missing %s", format, want)
+			}
+		}
+
+		orders := again.Paths["/orders"]
+		if len(orders.Servers) != 1 || orders.Parameters[0].Ref != "#/components/parameters/Tenant" {
+			t.Errorf("%s: expected path servers and parameter ref, got %+v", format, orders)
+		}
+		if strings.Contains(out, `name: ""`) || strings.Contains(out, `"name": ""`) {
+			t.Errorf("%s: parameter ref written with empty name", format)
+		}
+		post := orders.Post
+		if post.RequestBody.Ref != "#/components/requestBodies/Order" || post.Responses["404"].Ref != "#/components/responses/NotFound" {
+			t.Errorf("%s: expected request body and response refs preserved", format)
+		}
+		if post.Parameters[0].Content["application/json"].Schema == nil {
+			t.Errorf("%s: expected parameter content preserved", format)
+		}
+		if post.Callbacks["audit"].Ref != "#/components/callbacks/Audit" || post.Callbacks["shipped"].Expressions["{$request.body#/callbackUrl}"].Post == nil {
+			t.Errorf("%s: expected callbacks preserved, got %+v", format, post.Callbacks)
+		}
+		if again.Webhooks["orderCancelled"].Post == nil || len(again.Security) != 1 {
+			t.Errorf("%s: expected webhooks and security preserved", format)
+		}
+		if again.Components.Schemas["Pet"].Discriminator.PropertyName != "kind" {
+			t.Errorf("%s: expected discriminator preserved", format)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package pcap
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+// Converter converts packet captures to IR records.
+type Converter struct {
+	// KeyLog holds the TLS secrets used to decrypt HTTPS sessions. Secrets
+	// embedded in pcapng files are used as well.
+	KeyLog *KeyLog
+
+	// IncludeHeaders controls whether to include HTTP headers in output.
+	IncludeHeaders bool
+
+	// FilterHeaders is a list of header names to exclude (case-insensitive).
+	FilterHeaders []string
+
+	// APIOnly skips static assets and page loads (HTML, scripts, stylesheets,
+	// images, fonts, media).
+	APIOnly bool
+
+	// Filter, if set, skips requests whose host or path it does not allow.
+	Filter *ir.RequestMatcher
+
+	// IncludeBinaryBodies keeps binary bodies (images, PDFs, octet-stream)
+	// base64-encoded. By default they are left out of the IR.
+	IncludeBinaryBodies bool
+
+	// Metadata is copied into the metadata of every converted record.
+	Metadata map[string]any
+}
+
+// Result is the outcome of converting a capture.
+type Result struct {
+	// Records are the converted exchanges, sorted by timestamp.
+	Records []ir.IRRecord
+
+	// Connections is the number of TCP connections that carried data.
+	Connections int
+
+	// TLSSessions is the number of connections that carried TLS, and
+	// DecryptedSessions the number of those decrypted with key log secrets.
+	TLSSessions       int
+	DecryptedSessions int
+}
+
+// NewConverter creates a new capture to IR converter with default settings.
+func NewConverter() *Converter {
+	return &Converter{
+		IncludeHeaders: true,
+		FilterHeaders: []string{
+			"authorization",
+			"cookie",
+			"set-cookie",
+			"x-api-key",
+			"x-auth-token",
+			"x-csrf-token",
+			"proxy-authorization",
+		},
+	}
+}
+
+// ConvertFile converts a pcap or pcapng file, recording the file name in
+// each record's metadata.
+func (c *Converter) ConvertFile(path string) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+
+	result, err := c.Convert(f)
+	if err != nil {
+		return nil, err
+	}
+	ir.ApplyMetadata(result.Records, map[string]any{ir.MetaSourceFile: filepath.Base(path)})
+	return result, nil
+}
+
+// Convert converts a pcap or pcapng capture. Each HTTP/1.x request with a
+// captured response becomes a record; HTTPS sessions without key log
+// secrets are counted but skipped.
+func (c *Converter) Convert(r io.Reader) (*Result, error) {
+	capture, err := readCapture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := c.KeyLog
+	if len(capture.keyLog) > 0 {
+		keys = c.KeyLog.clone()
+		if err := keys.Add(bytes.NewReader(capture.keyLog)); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Result{}
+	for _, conn := range assemble(capture.packets) {
+		if len(conn.clientData.data) == 0 {
+			continue
+		}
+		result.Connections++
+
+		client, server := &conn.clientData.stream, &conn.serverData.stream
+		scheme := ir.RequestSchemeHTTP
+		if isTLS(client.data) {
+			result.TLSSessions++
+			if client, server, err = decryptTLS(conn, keys); err != nil {
+				continue
+			}
+			result.DecryptedSessions++
+			scheme = ir.RequestSchemeHTTPS
+		}
+
+		for _, ex := range readExchanges(client, server) {
+			if ex.response == nil {
+				continue
+			}
+			record := c.convertExchange(ex, scheme, conn)
+			if c.APIOnly && ir.IsNonAPIRecord(record) {
+				continue
+			}
+			if !c.Filter.AllowRecord(record) {
+				continue
+			}
+			result.Records = append(result.Records, *record)
+		}
+	}
+	ir.SortByTimestamp(result.Records)
+	return result, nil
+}
+
+// convertExchange converts a request and its response to an IR record.
+func (c *Converter) convertExchange(ex exchange, scheme ir.RequestScheme, conn *connection) *ir.IRRecord {
+	req, resp := ex.request, ex.response
+
+	host := req.Host
+	if req.URL.Host != "" {
+		host = req.URL.Host // absolute-form request through a proxy
+	}
+	if host == "" {
+		host = conn.server.String()
+	}
+	path := req.URL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	ts := ex.start
+	duration := ir.Milliseconds(ex.end.Sub(ex.start))
+	source := ir.IRRecordSourcePcap
+	record := &ir.IRRecord{
+		Timestamp: &ts,
+		Source:    &source,
+		Request: ir.Request{
+			Method: ir.RequestMethod(req.Method),
+			Scheme: scheme,
+			Host:   &host,
+			Path:   path,
+		},
+		Response: ir.Response{
+			Status: resp.StatusCode,
+		},
+		DurationMs: &duration,
+		Metadata:   maps.Clone(c.Metadata),
+	}
+	record.SetMeta(ir.MetaClientIP, conn.client.Addr().String())
+
+	if query := req.URL.Query(); len(query) > 0 {
+		record.Request.Query = make(map[string]interface{}, len(query))
+		for k, v := range query {
+			record.Request.Query[k] = queryValue(v)
+		}
+	}
+
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		record.Request.ContentType = &ct
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		record.Response.ContentType = &ct
+	}
+	if c.IncludeHeaders {
+		record.Request.Headers, record.Request.HeaderValues = c.convertHeaders(req.Header)
+		record.Response.Headers, record.Response.HeaderValues = c.convertHeaders(resp.Header)
+		record.Request.Trailers, _ = c.convertHeaders(req.Trailer)
+		record.Response.Trailers, _ = c.convertHeaders(resp.Trailer)
+	}
+	for _, interim := range ex.interim {
+		info := ir.InformationalResponse{Status: interim.StatusCode}
+		if c.IncludeHeaders {
+			info.Headers, info.HeaderValues = c.convertHeaders(interim.Header)
+		}
+		record.Response.Informational = append(record.Response.Informational, info)
+	}
+	record.Request.Body = c.convertBody(ex.requestBody, req.Header)
+	record.Response.Body = c.convertBody(ex.responseBody, resp.Header)
+
+	return record
+}
+
+// convertHeaders lowercases header names and splits the headers not
+// filtered out into first values and the values of repeated headers.
+func (c *Converter) convertHeaders(h http.Header) (map[string]string, map[string][]string) {
+	filtered := make(map[string]bool, len(c.FilterHeaders))
+	for _, name := range c.FilterHeaders {
+		filtered[strings.ToLower(name)] = true
+	}
+
+	kept := make(map[string][]string, len(h))
+	for k, v := range h {
+		if !filtered[strings.ToLower(k)] {
+			kept[k] = v
+		}
+	}
+	return ir.SplitHeaderValues(kept)
+}
+
+// convertBody decodes a body's content encoding and parses it. Binary
+// bodies are left out unless IncludeBinaryBodies is set, in which case they
+// are kept base64-encoded.
+func (c *Converter) convertBody(data []byte, h http.Header) interface{} {
+	data = decodeContent(data, h.Get("Content-Encoding"))
+	if len(data) == 0 {
+		return nil
+	}
+
+	contentType := h.Get("Content-Type")
+	if ir.IsBinaryContentType(contentType) || ir.IsBinaryData(data) {
+		if !c.IncludeBinaryBodies {
+			return nil
+		}
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
+	if strings.Contains(contentType, "json") {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err == nil {
+			return v
+		}
+	}
+	return string(data)
+}
+
+// decodeContent reverses gzip and deflate content encodings. Bodies with
+// other encodings, or that fail to decode, are returned unchanged.
+func decodeContent(data []byte, encoding string) []byte {
+	var r io.Reader
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return data
+		}
+		r = gz
+	case "deflate":
+		// Usually zlib-wrapped, though some servers send raw deflate
+		if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+			r = zr
+		} else {
+			r = flate.NewReader(bytes.NewReader(data))
+		}
+	default:
+		return data
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return data
+	}
+	return decoded
+}
+
+// queryValue returns a single query value as a string and repeated values
+// as a list.
+func queryValue(values []string) interface{} {
+	if len(values) == 1 {
+		return values[0]
+	}
+	list := make([]interface{}, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	return list
+}
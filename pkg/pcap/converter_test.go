@@ -0,0 +1,455 @@
+package pcap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestConvertPlainHTTP(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte(`{"users":[{"id":1}],"total":1}`))
+	_ = zw.Close()
+
+	f := newTestFlow(80)
+	f.handshake()
+	f.send(true, []byte("GET /users?limit=10&tag=a&tag=b HTTP/1.1\r\nHost: api.example.com\r\nAuthorization: Bearer x\r\n\r\n"))
+	f.send(false, []byte(fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Encoding: gzip\r\nContent-Length: %d\r\n\r\n", gz.Len())))
+	f.send(false, gz.Bytes())
+	f.send(true, []byte("DELETE /users/1 HTTP/1.1\r\nHost: api.example.com\r\n\r\n"))
+	f.send(false, []byte("HTTP/1.1 204 No Content\r\n\r\n"))
+
+	result, err := NewConverter().Convert(bytes.NewReader(writePcap(f.frames)))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if result.Connections != 1 || result.TLSSessions != 0 {
+		t.Errorf("connections = %d, TLS sessions = %d", result.Connections, result.TLSSessions)
+	}
+	if len(result.Records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(result.Records))
+	}
+
+	get := result.Records[0]
+	if get.Request.Method != ir.RequestMethodGET || get.Request.Path != "/users" || get.Request.Scheme != ir.RequestSchemeHTTP {
+		t.Errorf("unexpected request: %+v", get.Request)
+	}
+	if *get.Request.Host != "api.example.com" || *get.Source != ir.IRRecordSourcePcap {
+		t.Errorf("host = %s, source = %s", *get.Request.Host, *get.Source)
+	}
+	if get.Request.Query["limit"] != "10" || len(get.Request.Query["tag"].([]interface{})) != 2 {
+		t.Errorf("unexpected query: %v", get.Request.Query)
+	}
+	if _, ok := get.Request.Headers["authorization"]; ok {
+		t.Error("authorization header should be filtered")
+	}
+	body, ok := get.Response.Body.(map[string]interface{})
+	if !ok || body["total"] != float64(1) {
+		t.Errorf("gzip body not decoded: %v", get.Response.Body)
+	}
+	if get.MetaString(ir.MetaClientIP) != "10.0.0.1" {
+		t.Errorf("client IP = %q", get.MetaString(ir.MetaClientIP))
+	}
+	if get.Timestamp == nil || get.DurationMs == nil || *get.DurationMs < 0 {
+		t.Errorf("missing timing: %v %v", get.Timestamp, get.DurationMs)
+	}
+
+	if del := result.Records[1]; del.Request.Method != ir.RequestMethodDELETE || del.Response.Status != 204 {
+		t.Errorf("unexpected second record: %s %d", del.Request.Method, del.Response.Status)
+	}
+}
+
+func TestConvertReordersSegments(t *testing.T) {
+	f := newTestFlow(80)
+	f.handshake()
+	request := []byte("POST /items HTTP/1.1\r\nHost: api.example.com\r\nContent-Type: application/json\r\nContent-Length: 13\r\n\r\n{\"name\":\"a\"}\n")
+	f.send(true, request[:40])
+	first := f.frames[len(f.frames)-1]
+	f.send(true, request[40:])
+	// Deliver the second segment first, then retransmit the first
+	f.frames[len(f.frames)-2], f.frames[len(f.frames)-1] = f.frames[len(f.frames)-1], first
+	f.frames = append(f.frames, first)
+	f.send(false, []byte("HTTP/1.1 201 Created\r\nContent-Length: 0\r\n\r\n"))
+
+	result, err := NewConverter().Convert(bytes.NewReader(writePcap(f.frames)))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if len(result.Records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(result.Records))
+	}
+	record := result.Records[0]
+	if record.Response.Status != 201 || record.Request.Body.(map[string]interface{})["name"] != "a" {
+		t.Errorf("unexpected record: %d %v", record.Response.Status, record.Request.Body)
+	}
+}
+
+func TestConvertEarlyHintsAndTrailers(t *testing.T) {
+	f := newTestFlow(80)
+	f.handshake()
+	f.send(true, []byte("GET /page HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	f.send(false, []byte("HTTP/1.1 103 Early Hints\r\nLink: </style.css>; rel=preload\r\nLink: </app.js>; rel=preload\r\n\r\n"))
+	f.send(false, []byte("HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nTransfer-Encoding: chunked\r\nTrailer: X-Checksum\r\n\r\n"))
+	f.send(false, []byte("2\r\nok\r\n0\r\nX-Checksum: abc123\r\n\r\n"))
+
+	result, err := NewConverter().Convert(bytes.NewReader(writePcap(f.frames)))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if len(result.Records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(result.Records))
+	}
+	resp := result.Records[0].Response
+	if resp.Status != 200 {
+		t.Errorf("expected the final response, got %d", resp.Status)
+	}
+	if len(resp.Informational) != 1 || resp.Informational[0].Status != 103 {
+		t.Fatalf("expected a 103 informational response, got %+v", resp.Informational)
+	}
+	if links := resp.Informational[0].HeaderValuesOf("link"); len(links) != 2 {
+		t.Errorf("expected both early hint links, got %v", links)
+	}
+	if resp.Trailers["x-checksum"] != "abc123" {
+		t.Errorf("expected trailer, got %v", resp.Trailers)
+	}
+}
+
+func TestConvertTLS12(t *testing.T) {
+	session := runTLSSession(t, &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	})
+	assertTLSRecord(t, session, writePcap(session.frames), session.keyLog)
+}
+
+func TestConvertTLS13(t *testing.T) {
+	session := runTLSSession(t, &tls.Config{MinVersion: tls.VersionTLS13})
+	if session.state.CipherSuite == tls.TLS_CHACHA20_POLY1305_SHA256 {
+		t.Skip("negotiated ChaCha20-Poly1305, which is not supported")
+	}
+	assertTLSRecord(t, session, writePcap(session.frames), session.keyLog)
+}
+
+func TestConvertPcapNGEmbeddedSecrets(t *testing.T) {
+	session := runTLSSession(t, &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384},
+	})
+	assertTLSRecord(t, session, writePcapNG(session.frames, session.keyLog), nil)
+}
+
+func TestConvertTLSWithoutSecrets(t *testing.T) {
+	session := runTLSSession(t, &tls.Config{MaxVersion: tls.VersionTLS12})
+
+	result, err := NewConverter().Convert(bytes.NewReader(writePcap(session.frames)))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if result.TLSSessions != 1 || result.DecryptedSessions != 0 || len(result.Records) != 0 {
+		t.Errorf("sessions = %d, decrypted = %d, records = %d",
+			result.TLSSessions, result.DecryptedSessions, len(result.Records))
+	}
+}
+
+func TestReadCaptureInvalid(t *testing.T) {
+	if _, err := NewConverter().Convert(bytes.NewReader([]byte("not a capture file at all"))); err == nil {
+		t.Error("expected error for invalid capture")
+	}
+}
+
+func assertTLSRecord(t *testing.T, session *tlsSessionCapture, capture, keyLog []byte) {
+	t.Helper()
+	converter := NewConverter()
+	if keyLog != nil {
+		keys, err := ParseKeyLog(bytes.NewReader(keyLog))
+		if err != nil {
+			t.Fatal(err)
+		}
+		converter.KeyLog = keys
+	}
+
+	result, err := converter.Convert(bytes.NewReader(capture))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if result.TLSSessions != 1 || result.DecryptedSessions != 1 {
+		t.Fatalf("sessions = %d, decrypted = %d (suite 0x%04x)",
+			result.TLSSessions, result.DecryptedSessions, session.state.CipherSuite)
+	}
+	if len(result.Records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(result.Records))
+	}
+
+	record := result.Records[0]
+	if record.Request.Scheme != ir.RequestSchemeHTTPS || record.Request.Method != ir.RequestMethodPOST || record.Request.Path != "/v1/users" {
+		t.Errorf("unexpected request: %s %s %s", record.Request.Scheme, record.Request.Method, record.Request.Path)
+	}
+	if record.Request.Body.(map[string]interface{})["name"] != "alice" {
+		t.Errorf("unexpected request body: %v", record.Request.Body)
+	}
+	if record.Response.Status != 201 || record.Response.Body.(map[string]interface{})["id"] != "u1" {
+		t.Errorf("unexpected response: %d %v", record.Response.Status, record.Response.Body)
+	}
+}
+
+// testFlow builds the IPv4 packets of one TCP connection.
+type testFlow struct {
+	client, server netip.AddrPort
+	clientSeq      uint32
+	serverSeq      uint32
+	ts             time.Time
+	frames         []testFrame
+}
+
+type testFrame struct {
+	ts   time.Time
+	data []byte
+}
+
+func newTestFlow(port uint16) *testFlow {
+	return &testFlow{
+		client:    netip.MustParseAddrPort("10.0.0.1:52000"),
+		server:    netip.AddrPortFrom(netip.MustParseAddr("10.0.0.2"), port),
+		clientSeq: 1000,
+		serverSeq: 0xfffffff0, // wraps around during the test
+		ts:        time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func (f *testFlow) handshake() {
+	f.packet(true, tcpSYN, nil)
+	f.packet(false, tcpSYN|tcpACK, nil)
+	f.packet(true, tcpACK, nil)
+}
+
+// send sends data in segments of at most 1000 bytes.
+func (f *testFlow) send(fromClient bool, data []byte) {
+	for len(data) > 0 {
+		n := min(len(data), 1000)
+		f.packet(fromClient, tcpACK, data[:n])
+		data = data[n:]
+	}
+}
+
+func (f *testFlow) packet(fromClient bool, flags byte, payload []byte) {
+	src, dst, seq := f.client, f.server, &f.clientSeq
+	if !fromClient {
+		src, dst, seq = f.server, f.client, &f.serverSeq
+	}
+
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp, src.Port())
+	binary.BigEndian.PutUint16(tcp[2:], dst.Port())
+	binary.BigEndian.PutUint32(tcp[4:], *seq)
+	tcp[12] = 5 << 4
+	tcp[13] = flags
+	binary.BigEndian.PutUint16(tcp[14:], 65535)
+
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:], uint16(20+len(tcp)+len(payload)))
+	ip[8], ip[9] = 64, 6
+	copy(ip[12:], src.Addr().AsSlice())
+	copy(ip[16:], dst.Addr().AsSlice())
+
+	data := append(append(ip, tcp...), payload...)
+	f.frames = append(f.frames, testFrame{ts: f.ts, data: data})
+	f.ts = f.ts.Add(time.Millisecond)
+
+	*seq += uint32(len(payload))
+	if flags&tcpSYN != 0 {
+		*seq++
+	}
+}
+
+// writePcap writes frames as a classic pcap file of raw IP packets.
+func writePcap(frames []testFrame) []byte {
+	var buf bytes.Buffer
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header, 0xa1b2c3d4)
+	binary.LittleEndian.PutUint16(header[4:], 2)
+	binary.LittleEndian.PutUint16(header[6:], 4)
+	binary.LittleEndian.PutUint32(header[16:], 65535)
+	binary.LittleEndian.PutUint32(header[20:], linkTypeRaw)
+	buf.Write(header)
+	for _, frame := range frames {
+		record := make([]byte, 16)
+		binary.LittleEndian.PutUint32(record, uint32(frame.ts.Unix()))
+		binary.LittleEndian.PutUint32(record[4:], uint32(frame.ts.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(record[8:], uint32(len(frame.data)))
+		binary.LittleEndian.PutUint32(record[12:], uint32(len(frame.data)))
+		buf.Write(record)
+		buf.Write(frame.data)
+	}
+	return buf.Bytes()
+}
+
+// writePcapNG writes frames as a pcapng file with an embedded key log.
+func writePcapNG(frames []testFrame, keyLog []byte) []byte {
+	var buf bytes.Buffer
+	block := func(blockType uint32, body []byte) {
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+		total := uint32(12 + len(body))
+		_ = binary.Write(&buf, binary.LittleEndian, blockType)
+		_ = binary.Write(&buf, binary.LittleEndian, total)
+		buf.Write(body)
+		_ = binary.Write(&buf, binary.LittleEndian, total)
+	}
+
+	shb := binary.LittleEndian.AppendUint32(nil, 0x1A2B3C4D)
+	shb = binary.LittleEndian.AppendUint16(shb, 1)
+	shb = binary.LittleEndian.AppendUint16(shb, 0)
+	shb = binary.LittleEndian.AppendUint64(shb, ^uint64(0))
+	block(blockSectionHeader, shb)
+
+	idb := binary.LittleEndian.AppendUint16(nil, linkTypeRaw)
+	idb = binary.LittleEndian.AppendUint16(idb, 0)
+	idb = binary.LittleEndian.AppendUint32(idb, 65535)
+	block(blockInterface, idb)
+
+	dsb := binary.LittleEndian.AppendUint32(nil, secretsTypeTLSKeyLog)
+	dsb = binary.LittleEndian.AppendUint32(dsb, uint32(len(keyLog)))
+	block(blockDecryptionSecrets, append(dsb, keyLog...))
+
+	for _, frame := range frames {
+		ts := uint64(frame.ts.UnixMicro())
+		epb := binary.LittleEndian.AppendUint32(nil, 0)
+		epb = binary.LittleEndian.AppendUint32(epb, uint32(ts>>32))
+		epb = binary.LittleEndian.AppendUint32(epb, uint32(ts))
+		epb = binary.LittleEndian.AppendUint32(epb, uint32(len(frame.data)))
+		epb = binary.LittleEndian.AppendUint32(epb, uint32(len(frame.data)))
+		block(blockEnhancedPacket, append(epb, frame.data...))
+	}
+	return buf.Bytes()
+}
+
+// tlsSessionCapture is a TLS session recorded as packets, with its key log.
+type tlsSessionCapture struct {
+	frames []testFrame
+	keyLog []byte
+	state  tls.ConnectionState
+}
+
+// recordingConn records the bytes written through it into a shared flow.
+type recordingConn struct {
+	net.Conn
+	mu         *sync.Mutex
+	flow       *testFlow
+	fromClient bool
+}
+
+func (c *recordingConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	c.flow.send(c.fromClient, p)
+	c.mu.Unlock()
+	return c.Conn.Write(p)
+}
+
+// runTLSSession runs one HTTPS request over loopback, recording the
+// encrypted bytes each side sends.
+func runTLSSession(t *testing.T, clientConfig *tls.Config) *tlsSessionCapture {
+	t.Helper()
+	cert := testCertificate(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	flow := newTestFlow(443)
+	flow.handshake()
+	var mu sync.Mutex
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		srv := tls.Server(&recordingConn{Conn: conn, mu: &mu, flow: flow}, &tls.Config{Certificates: []tls.Certificate{cert}})
+		defer srv.Close()
+		req, err := http.ReadRequest(bufio.NewReader(srv))
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		_, _ = io.ReadAll(req.Body)
+		body := `{"id":"u1"}`
+		_, err = fmt.Fprintf(srv, "HTTP/1.1 201 Created\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+		serverErr <- err
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keyLog bytes.Buffer
+	config := clientConfig.Clone()
+	config.ServerName = "api.example.com"
+	config.InsecureSkipVerify = true
+	config.KeyLogWriter = &keyLog
+	client := tls.Client(&recordingConn{Conn: conn, mu: &mu, flow: flow, fromClient: true}, config)
+	defer client.Close()
+
+	body := `{"name":"alice"}`
+	if _, err := fmt.Fprintf(client, "POST /v1/users HTTP/1.1\r\nHost: api.example.com\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(client), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.ReadAll(resp.Body)
+	if err := <-serverErr; err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return &tlsSessionCapture{
+		frames: append([]testFrame(nil), flow.frames...),
+		keyLog: keyLog.Bytes(),
+		state:  client.ConnectionState(),
+	}
+}
+
+func testCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"api.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
@@ -0,0 +1,91 @@
+package pcap
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// exchange is an HTTP request and its response read from a connection.
+type exchange struct {
+	request      *http.Request
+	requestBody  []byte
+	response     *http.Response
+	responseBody []byte
+	interim      []*http.Response // informational (1xx) responses
+	start, end   time.Time
+}
+
+// streamReader reads HTTP messages from a stream, tracking the offset of
+// the next unread byte.
+type streamReader struct {
+	s  *stream
+	r  *bytes.Reader
+	br *bufio.Reader
+}
+
+func newStreamReader(s *stream) *streamReader {
+	r := bytes.NewReader(s.data)
+	return &streamReader{s: s, r: r, br: bufio.NewReader(r)}
+}
+
+func (sr *streamReader) offset() int {
+	return len(sr.s.data) - sr.r.Len() - sr.br.Buffered()
+}
+
+func (sr *streamReader) more() bool {
+	_, err := sr.br.Peek(1)
+	return err == nil
+}
+
+// readExchanges pairs the HTTP/1.x requests a client sent with the server's
+// responses, in order. Requests without a complete response are returned
+// without one. Parsing stops at the first malformed message or protocol
+// upgrade.
+func readExchanges(client, server *stream) []exchange {
+	var exchanges []exchange
+	requests := newStreamReader(client)
+	for requests.more() {
+		start := client.timeAt(requests.offset())
+		req, err := http.ReadRequest(requests.br)
+		if err != nil {
+			break
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			break
+		}
+		exchanges = append(exchanges, exchange{
+			request:     req,
+			requestBody: body,
+			start:       start,
+			end:         client.timeAt(requests.offset() - 1),
+		})
+	}
+
+	responses := newStreamReader(server)
+	for i := 0; i < len(exchanges) && responses.more(); {
+		ex := &exchanges[i]
+		resp, err := http.ReadResponse(responses.br, ex.request)
+		if err != nil {
+			break
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil && len(body) == 0 {
+			break
+		}
+		if resp.StatusCode >= 100 && resp.StatusCode < 200 && resp.StatusCode != http.StatusSwitchingProtocols {
+			ex.interim = append(ex.interim, resp) // e.g. 100 Continue, 103 Early Hints
+			continue
+		}
+		ex.response, ex.responseBody = resp, body
+		ex.end = server.timeAt(responses.offset() - 1)
+		i++
+		if resp.StatusCode == http.StatusSwitchingProtocols {
+			break
+		}
+	}
+	return exchanges
+}
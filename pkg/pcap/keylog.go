@@ -0,0 +1,108 @@
+package pcap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+)
+
+// Key log labels (https://datatracker.ietf.org/doc/draft-ietf-tls-keylogfile/).
+const (
+	labelClientRandom          = "CLIENT_RANDOM"
+	labelClientHandshakeSecret = "CLIENT_HANDSHAKE_TRAFFIC_SECRET"
+	labelServerHandshakeSecret = "SERVER_HANDSHAKE_TRAFFIC_SECRET"
+	labelClientTrafficSecret   = "CLIENT_TRAFFIC_SECRET_0"
+	labelServerTrafficSecret   = "SERVER_TRAFFIC_SECRET_0"
+)
+
+// KeyLog holds the TLS secrets of an NSS key log file, the format written to
+// SSLKEYLOGFILE by curl, browsers, and Go's tls.Config.KeyLogWriter.
+type KeyLog struct {
+	secrets map[string][]byte // label + " " + hex client random -> secret
+	randoms map[string]bool
+}
+
+// NewKeyLog creates an empty KeyLog.
+func NewKeyLog() *KeyLog {
+	return &KeyLog{
+		secrets: make(map[string][]byte),
+		randoms: make(map[string]bool),
+	}
+}
+
+// ParseKeyLog parses an NSS key log. Comments and malformed lines are
+// skipped, as Wireshark does.
+func ParseKeyLog(r io.Reader) (*KeyLog, error) {
+	k := NewKeyLog()
+	if err := k.Add(r); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// ReadKeyLogFile reads an NSS key log file.
+func ReadKeyLogFile(path string) (*KeyLog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening key log: %w", err)
+	}
+	defer f.Close()
+	return ParseKeyLog(f)
+}
+
+// Add adds the secrets of an NSS key log to k.
+func (k *KeyLog) Add(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		random, err := hex.DecodeString(fields[1])
+		if err != nil || len(random) != 32 {
+			continue
+		}
+		secret, err := hex.DecodeString(fields[2])
+		if err != nil || len(secret) == 0 {
+			continue
+		}
+		id := strings.ToLower(fields[1])
+		k.secrets[fields[0]+" "+id] = secret
+		k.randoms[id] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading key log: %w", err)
+	}
+	return nil
+}
+
+// Len returns the number of TLS sessions with secrets.
+func (k *KeyLog) Len() int {
+	if k == nil {
+		return 0
+	}
+	return len(k.randoms)
+}
+
+// clone returns a copy of k, or an empty KeyLog if k is nil.
+func (k *KeyLog) clone() *KeyLog {
+	c := NewKeyLog()
+	if k != nil {
+		maps.Copy(c.secrets, k.secrets)
+		maps.Copy(c.randoms, k.randoms)
+	}
+	return c
+}
+
+// secret returns the secret logged under label for a client random.
+func (k *KeyLog) secret(label string, clientRandom []byte) []byte {
+	if k == nil {
+		return nil
+	}
+	return bytes.Clone(k.secrets[label+" "+hex.EncodeToString(clientRandom)])
+}
@@ -0,0 +1,49 @@
+package pcap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseKeyLog(t *testing.T) {
+	random := strings.Repeat("ab", 32)
+	input := strings.Join([]string{
+		"# SSL/TLS secrets log file",
+		"CLIENT_RANDOM " + random + " " + strings.Repeat("01", 48),
+		"CLIENT_TRAFFIC_SECRET_0 " + strings.ToUpper(random) + " " + strings.Repeat("02", 32),
+		"CLIENT_RANDOM nothex " + strings.Repeat("01", 48),
+		"CLIENT_RANDOM " + strings.Repeat("cd", 16) + " 01",
+		"garbage",
+		"",
+	}, "\n")
+
+	keys, err := ParseKeyLog(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseKeyLog failed: %v", err)
+	}
+	if keys.Len() != 1 {
+		t.Errorf("Len = %d, want 1", keys.Len())
+	}
+
+	clientRandom := bytes.Repeat([]byte{0xab}, 32)
+	if got := keys.secret(labelClientRandom, clientRandom); !bytes.Equal(got, bytes.Repeat([]byte{0x01}, 48)) {
+		t.Errorf("master secret = %x", got)
+	}
+	if got := keys.secret(labelClientTrafficSecret, clientRandom); len(got) != 32 {
+		t.Errorf("traffic secret = %x", got)
+	}
+	if keys.secret(labelServerTrafficSecret, clientRandom) != nil {
+		t.Error("expected no server traffic secret")
+	}
+}
+
+func TestKeyLogNil(t *testing.T) {
+	var keys *KeyLog
+	if keys.Len() != 0 || keys.secret(labelClientRandom, make([]byte, 32)) != nil {
+		t.Error("nil KeyLog should be empty")
+	}
+	if keys.clone().Len() != 0 {
+		t.Error("clone of nil KeyLog should be empty")
+	}
+}
@@ -0,0 +1,289 @@
+// Package pcap converts packet captures of HTTP/1.x traffic to IR format.
+//
+// HTTPS sessions are decrypted with the TLS secrets recorded in an NSS key
+// log file (SSLKEYLOGFILE), as written by curl, browsers, Go's
+// tls.Config.KeyLogWriter, and eBPF keylog tools. Traffic can then be
+// captured with tcpdump or Wireshark without modifying application code or
+// trusting a proxy CA.
+//
+// Supported inputs:
+//   - classic pcap and pcapng files, including pcapng decryption secrets
+//     blocks (editcap --inject-secrets)
+//   - Ethernet, Linux cooked (SLL and SLL2), raw IP, and loopback links
+//   - IPv4 and IPv6 TCP, reassembled per connection
+//   - TLS 1.2 and 1.3 sessions using AES-GCM cipher suites
+//
+// HTTP/2, QUIC, and fragmented IP packets are not decoded.
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"time"
+)
+
+// Link types (https://www.tcpdump.org/linktypes.html).
+const (
+	linkTypeNull     = 0
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLoop     = 108
+	linkTypeLinuxSLL = 113
+	linkTypeIPv4     = 228
+	linkTypeIPv6     = 229
+	linkTypeSLL2     = 276
+)
+
+// maxPacketSize bounds the captured length of a single packet.
+const maxPacketSize = 256 * 1024
+
+// pcapng block types.
+const (
+	blockSectionHeader     = 0x0A0D0D0A
+	blockInterface         = 0x00000001
+	blockPacket            = 0x00000002
+	blockSimplePacket      = 0x00000003
+	blockEnhancedPacket    = 0x00000006
+	blockDecryptionSecrets = 0x0000000A
+)
+
+// secretsTypeTLSKeyLog marks a decryption secrets block holding an NSS key log.
+const secretsTypeTLSKeyLog = 0x544c534b
+
+// packet is a captured link-layer frame.
+type packet struct {
+	ts       time.Time
+	linkType uint32
+	data     []byte
+}
+
+// capture holds the packets of a capture file and any TLS key log embedded
+// in it.
+type capture struct {
+	packets []packet
+	keyLog  []byte
+}
+
+// readCapture reads a classic pcap or pcapng file.
+func readCapture(r io.Reader) (*capture, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, fmt.Errorf("reading capture header: %w", err)
+	}
+	if binary.LittleEndian.Uint32(magic) == blockSectionHeader {
+		return readPcapNG(br)
+	}
+	return readPcap(br)
+}
+
+// readPcap reads a classic libpcap file.
+func readPcap(r io.Reader) (*capture, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("reading capture header: %w", err)
+	}
+
+	var order binary.ByteOrder
+	nanos := false
+	switch binary.LittleEndian.Uint32(header) {
+	case 0xa1b2c3d4:
+		order = binary.LittleEndian
+	case 0xa1b23c4d:
+		order, nanos = binary.LittleEndian, true
+	case 0xd4c3b2a1:
+		order = binary.BigEndian
+	case 0x4d3cb2a1:
+		order, nanos = binary.BigEndian, true
+	default:
+		return nil, errors.New("not a pcap or pcapng file")
+	}
+	linkType := order.Uint32(header[20:]) & 0x0fffffff
+
+	c := &capture{}
+	record := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, record); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return c, nil // a truncated final packet ends the capture
+			}
+			return nil, fmt.Errorf("reading packet header: %w", err)
+		}
+		sec, frac := order.Uint32(record), order.Uint32(record[4:])
+		length := order.Uint32(record[8:])
+		if length > maxPacketSize {
+			return nil, fmt.Errorf("packet of %d bytes exceeds maximum size", length)
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return c, nil
+		}
+		nsec := int64(frac) * 1000
+		if nanos {
+			nsec = int64(frac)
+		}
+		c.packets = append(c.packets, packet{
+			ts:       time.Unix(int64(sec), nsec).UTC(),
+			linkType: linkType,
+			data:     data,
+		})
+	}
+}
+
+// pcapngInterface describes a pcapng interface description block.
+type pcapngInterface struct {
+	linkType    uint32
+	unitsPerSec uint64
+}
+
+// readPcapNG reads a pcapng file, which may contain several sections.
+func readPcapNG(r io.Reader) (*capture, error) {
+	c := &capture{}
+	var order binary.ByteOrder = binary.LittleEndian
+	var interfaces []pcapngInterface
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return c, nil
+			}
+			return nil, fmt.Errorf("reading block header: %w", err)
+		}
+
+		blockType := order.Uint32(header)
+		if binary.LittleEndian.Uint32(header) == blockSectionHeader {
+			// The byte-order magic after the header selects the section's order
+			bom := make([]byte, 4)
+			if _, err := io.ReadFull(r, bom); err != nil {
+				return c, nil
+			}
+			switch binary.LittleEndian.Uint32(bom) {
+			case 0x1A2B3C4D:
+				order = binary.LittleEndian
+			case 0x4D3C2B1A:
+				order = binary.BigEndian
+			default:
+				return nil, errors.New("invalid pcapng byte-order magic")
+			}
+			blockType = blockSectionHeader
+			interfaces = nil
+		}
+
+		total := order.Uint32(header[4:])
+		bodyLen := int64(total) - 12
+		if blockType == blockSectionHeader {
+			bodyLen -= 4 // byte-order magic already read
+		}
+		if total%4 != 0 || bodyLen < 0 || bodyLen > maxPacketSize+1024 {
+			return nil, fmt.Errorf("invalid pcapng block length %d", total)
+		}
+		body := make([]byte, bodyLen+4) // body and trailing length
+		if _, err := io.ReadFull(r, body); err != nil {
+			return c, nil
+		}
+		body = body[:bodyLen]
+
+		switch blockType {
+		case blockInterface:
+			if len(body) < 8 {
+				return nil, errors.New("truncated interface description block")
+			}
+			iface := pcapngInterface{
+				linkType:    uint32(order.Uint16(body)),
+				unitsPerSec: 1_000_000,
+			}
+			if resol, ok := pcapngOption(body[8:], 9, order); ok && len(resol) > 0 {
+				iface.unitsPerSec = timestampUnits(resol[0])
+			}
+			interfaces = append(interfaces, iface)
+		case blockEnhancedPacket, blockPacket:
+			if len(body) < 20 {
+				continue
+			}
+			// Both blocks have a 4-byte interface field (the obsolete packet
+			// block splits it into interface ID and drop count)
+			id := order.Uint32(body)
+			if blockType == blockPacket {
+				id = uint32(order.Uint16(body))
+			}
+			rest := body[4:]
+			if int(id) >= len(interfaces) {
+				continue
+			}
+			ts := uint64(order.Uint32(rest))<<32 | uint64(order.Uint32(rest[4:]))
+			length := int(order.Uint32(rest[8:]))
+			data := rest[16:]
+			if length > len(data) {
+				continue
+			}
+			c.packets = append(c.packets, packet{
+				ts:       pcapngTime(ts, interfaces[id].unitsPerSec),
+				linkType: interfaces[id].linkType,
+				data:     data[:length],
+			})
+		case blockSimplePacket:
+			if len(interfaces) == 0 || len(body) < 4 {
+				continue
+			}
+			length := int(order.Uint32(body))
+			data := body[4:]
+			if length < len(data) {
+				data = data[:length]
+			}
+			c.packets = append(c.packets, packet{linkType: interfaces[0].linkType, data: data})
+		case blockDecryptionSecrets:
+			if len(body) < 8 {
+				continue
+			}
+			length := int(order.Uint32(body[4:]))
+			if order.Uint32(body) == secretsTypeTLSKeyLog && length <= len(body)-8 {
+				c.keyLog = append(c.keyLog, body[8:8+length]...)
+				c.keyLog = append(c.keyLog, '\n')
+			}
+		}
+	}
+}
+
+// pcapngOption returns the value of the first option with the given code.
+func pcapngOption(options []byte, code uint16, order binary.ByteOrder) ([]byte, bool) {
+	for len(options) >= 4 {
+		optCode, length := order.Uint16(options), int(order.Uint16(options[2:]))
+		if optCode == 0 || 4+length > len(options) {
+			return nil, false
+		}
+		if optCode == code {
+			return options[4 : 4+length], true
+		}
+		options = options[4+(length+3)&^3:]
+	}
+	return nil, false
+}
+
+// timestampUnits converts an if_tsresol option to timestamp units per second.
+func timestampUnits(resol byte) uint64 {
+	exp := uint64(resol & 0x7f)
+	if resol&0x80 != 0 {
+		if exp > 63 {
+			exp = 63
+		}
+		return 1 << exp
+	}
+	units := uint64(1)
+	for i := uint64(0); i < exp && i < 19; i++ {
+		units *= 10
+	}
+	return units
+}
+
+// pcapngTime converts a pcapng timestamp to a time.
+func pcapngTime(ts, unitsPerSec uint64) time.Time {
+	sec, frac := ts/unitsPerSec, ts%unitsPerSec
+	hi, lo := bits.Mul64(frac, uint64(time.Second))
+	nsec, _ := bits.Div64(hi, lo, unitsPerSec)
+	return time.Unix(int64(sec), int64(nsec)).UTC()
+}
@@ -0,0 +1,286 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"sort"
+	"time"
+)
+
+// maxPendingSegments bounds the out-of-order segments buffered per
+// direction. Streams with a larger gap (lost packets) end at the gap.
+const maxPendingSegments = 1024
+
+// TCP flags.
+const (
+	tcpFIN = 0x01
+	tcpSYN = 0x02
+	tcpRST = 0x04
+	tcpACK = 0x10
+)
+
+// segment is a decoded TCP segment.
+type segment struct {
+	src, dst netip.AddrPort
+	seq      uint32
+	flags    byte
+	payload  []byte
+	ts       time.Time
+}
+
+// decodeSegment decodes the TCP segment of a link-layer frame.
+func decodeSegment(p packet) (segment, bool) {
+	data := p.data
+	switch p.linkType {
+	case linkTypeEthernet:
+		if len(data) < 14 {
+			return segment{}, false
+		}
+		etherType := binary.BigEndian.Uint16(data[12:])
+		data = data[14:]
+		for (etherType == 0x8100 || etherType == 0x88a8) && len(data) >= 4 {
+			etherType = binary.BigEndian.Uint16(data[2:])
+			data = data[4:]
+		}
+		if etherType != 0x0800 && etherType != 0x86dd {
+			return segment{}, false
+		}
+	case linkTypeLinuxSLL:
+		if len(data) < 16 {
+			return segment{}, false
+		}
+		data = data[16:]
+	case linkTypeSLL2:
+		if len(data) < 20 {
+			return segment{}, false
+		}
+		data = data[20:]
+	case linkTypeNull, linkTypeLoop:
+		if len(data) < 4 {
+			return segment{}, false
+		}
+		data = data[4:]
+	case linkTypeRaw, linkTypeIPv4, linkTypeIPv6:
+	default:
+		return segment{}, false
+	}
+	return decodeIP(data, p.ts)
+}
+
+// decodeIP decodes the TCP segment of an IPv4 or IPv6 packet.
+func decodeIP(data []byte, ts time.Time) (segment, bool) {
+	if len(data) < 1 {
+		return segment{}, false
+	}
+	var src, dst netip.Addr
+	switch data[0] >> 4 {
+	case 4:
+		if len(data) < 20 {
+			return segment{}, false
+		}
+		headerLen := int(data[0]&0x0f) * 4
+		total := int(binary.BigEndian.Uint16(data[2:]))
+		fragment := binary.BigEndian.Uint16(data[6:])
+		if data[9] != 6 || fragment&0x3fff != 0 || headerLen < 20 || total < headerLen || total > len(data) {
+			return segment{}, false // not TCP, fragmented, or truncated
+		}
+		src = netip.AddrFrom4([4]byte(data[12:16]))
+		dst = netip.AddrFrom4([4]byte(data[16:20]))
+		data = data[headerLen:total]
+	case 6:
+		if len(data) < 40 {
+			return segment{}, false
+		}
+		total := 40 + int(binary.BigEndian.Uint16(data[4:]))
+		if total > len(data) {
+			return segment{}, false
+		}
+		next := data[6]
+		src = netip.AddrFrom16([16]byte(data[8:24]))
+		dst = netip.AddrFrom16([16]byte(data[24:40]))
+		data = data[40:total]
+		// Skip hop-by-hop, routing, and destination options headers
+		for next == 0 || next == 43 || next == 60 {
+			if len(data) < 8 {
+				return segment{}, false
+			}
+			extLen := (int(data[1]) + 1) * 8
+			if extLen > len(data) {
+				return segment{}, false
+			}
+			next, data = data[0], data[extLen:]
+		}
+		if next != 6 {
+			return segment{}, false
+		}
+	default:
+		return segment{}, false
+	}
+
+	if len(data) < 20 {
+		return segment{}, false
+	}
+	offset := int(data[12]>>4) * 4
+	if offset < 20 || offset > len(data) {
+		return segment{}, false
+	}
+	return segment{
+		src:     netip.AddrPortFrom(src, binary.BigEndian.Uint16(data)),
+		dst:     netip.AddrPortFrom(dst, binary.BigEndian.Uint16(data[2:])),
+		seq:     binary.BigEndian.Uint32(data[4:]),
+		flags:   data[13],
+		payload: data[offset:],
+		ts:      ts,
+	}, true
+}
+
+// stream is the reassembled data sent in one direction of a connection,
+// with the capture time of each chunk.
+type stream struct {
+	data   []byte
+	chunks []chunk
+}
+
+type chunk struct {
+	offset int
+	ts     time.Time
+}
+
+func (s *stream) write(p []byte, ts time.Time) {
+	if len(p) == 0 {
+		return
+	}
+	s.chunks = append(s.chunks, chunk{offset: len(s.data), ts: ts})
+	s.data = append(s.data, p...)
+}
+
+// timeAt returns the capture time of the byte at offset.
+func (s *stream) timeAt(offset int) time.Time {
+	i := sort.Search(len(s.chunks), func(i int) bool { return s.chunks[i].offset > offset })
+	if i == 0 {
+		if len(s.chunks) == 0 {
+			return time.Time{}
+		}
+		i = 1
+	}
+	return s.chunks[i-1].ts
+}
+
+// halfConnection reassembles one direction of a TCP connection.
+type halfConnection struct {
+	stream
+	next    uint32
+	synced  bool
+	gap     bool
+	pending map[uint32]segment
+}
+
+func (h *halfConnection) add(seg segment) {
+	seq := seg.seq
+	if seg.flags&tcpSYN != 0 {
+		seq++
+		if !h.synced {
+			h.next, h.synced = seq, true
+		}
+	}
+	if len(seg.payload) == 0 || h.gap {
+		return
+	}
+	if !h.synced {
+		h.next, h.synced = seq, true
+	}
+	seg.seq = seq
+	if !h.insert(seg) {
+		if len(h.pending) >= maxPendingSegments {
+			h.gap, h.pending = true, nil
+			return
+		}
+		if h.pending == nil {
+			h.pending = make(map[uint32]segment)
+		}
+		h.pending[seq] = seg
+		return
+	}
+	for progress := true; progress && len(h.pending) > 0; {
+		progress = false
+		for seq, pending := range h.pending {
+			if int32(seq-h.next) <= 0 {
+				delete(h.pending, seq)
+				h.insert(pending)
+				progress = true
+			}
+		}
+	}
+}
+
+// insert appends the part of seg not yet received. It reports false if seg
+// starts after the next expected sequence number.
+func (h *halfConnection) insert(seg segment) bool {
+	diff := int32(seg.seq - h.next)
+	if diff > 0 {
+		return false
+	}
+	payload := seg.payload
+	if int(-diff) >= len(payload) {
+		return true // retransmission
+	}
+	payload = payload[-diff:]
+	h.write(payload, seg.ts)
+	h.next += uint32(len(payload))
+	return true
+}
+
+// connection is a reassembled TCP connection.
+type connection struct {
+	client, server netip.AddrPort
+	clientData     halfConnection
+	serverData     halfConnection
+	closed         bool
+}
+
+// assemble reassembles the TCP connections of a capture, in the order they
+// were first seen. The client of a connection is the side that sent the
+// first SYN, or without one, the first data.
+func assemble(packets []packet) []*connection {
+	type flowKey struct{ a, b netip.AddrPort }
+	flows := make(map[flowKey]*connection)
+	var conns []*connection
+
+	for _, p := range packets {
+		seg, ok := decodeSegment(p)
+		if !ok {
+			continue
+		}
+		key := flowKey{seg.src, seg.dst}
+		if seg.dst.Addr().Less(seg.src.Addr()) || seg.dst.Addr() == seg.src.Addr() && seg.dst.Port() < seg.src.Port() {
+			key = flowKey{seg.dst, seg.src}
+		}
+
+		syn := seg.flags&tcpSYN != 0 && seg.flags&tcpACK == 0
+		conn := flows[key]
+		if conn != nil && syn && (conn.closed || len(conn.clientData.data) > 0) {
+			conn = nil // port reuse starts a new connection
+		}
+		if conn == nil {
+			if !syn && len(seg.payload) == 0 {
+				continue
+			}
+			conn = &connection{client: seg.src, server: seg.dst}
+			if seg.flags&(tcpSYN|tcpACK) == tcpSYN|tcpACK {
+				conn.client, conn.server = seg.dst, seg.src
+			}
+			flows[key] = conn
+			conns = append(conns, conn)
+		}
+
+		if seg.src == conn.client {
+			conn.clientData.add(seg)
+		} else {
+			conn.serverData.add(seg)
+		}
+		if seg.flags&(tcpFIN|tcpRST) != 0 {
+			conn.closed = true
+		}
+	}
+	return conns
+}
@@ -0,0 +1,404 @@
+package pcap
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// TLS record content types.
+const (
+	recordChangeCipherSpec = 20
+	recordAlert            = 21
+	recordHandshake        = 22
+	recordApplicationData  = 23
+)
+
+// TLS handshake message types.
+const (
+	handshakeClientHello = 1
+	handshakeServerHello = 2
+)
+
+const (
+	versionTLS13          = 0x0304
+	extSupportedVersions  = 43
+	maxRecordFragmentSize = 1<<14 + 2048
+)
+
+var (
+	errTLSHandshake     = errors.New("TLS handshake not captured")
+	errNoSecrets        = errors.New("no key log secrets for TLS session")
+	errUnsupportedSuite = errors.New("unsupported TLS cipher suite")
+)
+
+// helloRetryRandom is the ServerHello random of a TLS 1.3 HelloRetryRequest.
+var helloRetryRandom, _ = hex.DecodeString("cf21ad74e59a6111be1d8c021e65b891c2a211167abb8c5e079e09e2c8a8339c")
+
+// cipherSuite describes a supported AES-GCM cipher suite.
+type cipherSuite struct {
+	keyLen int
+	hash   func() hash.Hash
+}
+
+var cipherSuites = map[uint16]cipherSuite{
+	0x009c: {16, sha256.New},    // TLS_RSA_WITH_AES_128_GCM_SHA256
+	0x009d: {32, sha512.New384}, // TLS_RSA_WITH_AES_256_GCM_SHA384
+	0x009e: {16, sha256.New},    // TLS_DHE_RSA_WITH_AES_128_GCM_SHA256
+	0x009f: {32, sha512.New384}, // TLS_DHE_RSA_WITH_AES_256_GCM_SHA384
+	0xc02b: {16, sha256.New},    // TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256
+	0xc02c: {32, sha512.New384}, // TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384
+	0xc02f: {16, sha256.New},    // TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+	0xc030: {32, sha512.New384}, // TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384
+	0x1301: {16, sha256.New},    // TLS_AES_128_GCM_SHA256
+	0x1302: {32, sha512.New384}, // TLS_AES_256_GCM_SHA384
+}
+
+// isTLS reports whether a client stream starts with a TLS handshake record.
+func isTLS(data []byte) bool {
+	return len(data) >= 3 && data[0] == recordHandshake && data[1] == 3
+}
+
+// tlsRecord is a TLS record and the stream offset where it ends.
+type tlsRecord struct {
+	typ      byte
+	header   []byte
+	fragment []byte
+	end      int
+}
+
+// parseRecords splits a stream into TLS records, stopping at the first
+// incomplete or malformed record.
+func parseRecords(data []byte) []tlsRecord {
+	var records []tlsRecord
+	offset := 0
+	for len(data)-offset >= 5 {
+		header := data[offset : offset+5]
+		length := int(binary.BigEndian.Uint16(header[3:]))
+		if header[1] != 3 || length > maxRecordFragmentSize || offset+5+length > len(data) {
+			break
+		}
+		records = append(records, tlsRecord{
+			typ:      header[0],
+			header:   header,
+			fragment: data[offset+5 : offset+5+length],
+			end:      offset + 5 + length,
+		})
+		offset += 5 + length
+	}
+	return records
+}
+
+// hello holds the fields of a ClientHello or ServerHello used for decryption.
+type hello struct {
+	random  []byte
+	version uint16
+	suite   uint16
+}
+
+// findHello returns the first hello of the given type in the plaintext
+// handshake records, skipping HelloRetryRequests.
+func findHello(records []tlsRecord, msgType byte) *hello {
+	var buf []byte
+	for _, rec := range records {
+		if rec.typ == recordChangeCipherSpec {
+			continue
+		}
+		if rec.typ != recordHandshake {
+			break
+		}
+		buf = append(buf, rec.fragment...)
+	}
+	for len(buf) >= 4 {
+		length := int(buf[1])<<16 | int(buf[2])<<8 | int(buf[3])
+		if 4+length > len(buf) {
+			return nil
+		}
+		msg := buf[4 : 4+length]
+		if buf[0] == msgType {
+			if h := parseHello(msg, msgType); h != nil && !bytes.Equal(h.random, helloRetryRandom) {
+				return h
+			}
+		}
+		buf = buf[4+length:]
+	}
+	return nil
+}
+
+// parseHello parses the body of a ClientHello or ServerHello message.
+func parseHello(msg []byte, msgType byte) *hello {
+	if len(msg) < 35 {
+		return nil
+	}
+	h := &hello{version: binary.BigEndian.Uint16(msg), random: msg[2:34]}
+	if msgType != handshakeServerHello {
+		return h
+	}
+
+	rest := msg[34:]
+	sessionLen := int(rest[0])
+	if len(rest) < 1+sessionLen+3 {
+		return nil
+	}
+	rest = rest[1+sessionLen:]
+	h.suite = binary.BigEndian.Uint16(rest)
+	rest = rest[3:] // cipher suite and compression method
+	if len(rest) < 2 {
+		return h
+	}
+	extLen := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if extLen < len(rest) {
+		rest = rest[:extLen]
+	}
+	for len(rest) >= 4 {
+		extType, length := binary.BigEndian.Uint16(rest), int(binary.BigEndian.Uint16(rest[2:]))
+		if 4+length > len(rest) {
+			break
+		}
+		if extType == extSupportedVersions && length == 2 {
+			h.version = binary.BigEndian.Uint16(rest[4:])
+		}
+		rest = rest[4+length:]
+	}
+	return h
+}
+
+// recordCipher decrypts AES-GCM records under one traffic key.
+type recordCipher struct {
+	aead  cipher.AEAD
+	iv    []byte // 4-byte salt in TLS 1.2, 12-byte IV in TLS 1.3
+	tls13 bool
+	seq   uint64
+}
+
+func newRecordCipher(key, iv []byte, tls13 bool) (*recordCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &recordCipher{aead: aead, iv: iv, tls13: tls13}, nil
+}
+
+// open decrypts a record, returning its content type and plaintext.
+func (c *recordCipher) open(rec tlsRecord) (byte, []byte, error) {
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], c.seq)
+
+	if !c.tls13 {
+		overhead := 8 + c.aead.Overhead()
+		if len(rec.fragment) < overhead {
+			return 0, nil, errors.New("record too short")
+		}
+		nonce := append(append([]byte(nil), c.iv...), rec.fragment[:8]...)
+		aad := append(seq[:], rec.typ, rec.header[1], rec.header[2], 0, 0)
+		binary.BigEndian.PutUint16(aad[11:], uint16(len(rec.fragment)-overhead))
+		plaintext, err := c.aead.Open(nil, nonce, rec.fragment[8:], aad)
+		if err != nil {
+			return 0, nil, err
+		}
+		c.seq++
+		return rec.typ, plaintext, nil
+	}
+
+	nonce := append([]byte(nil), c.iv...)
+	for i := range seq {
+		nonce[len(nonce)-8+i] ^= seq[i]
+	}
+	plaintext, err := c.aead.Open(nil, nonce, rec.fragment, rec.header)
+	if err != nil {
+		return 0, nil, err
+	}
+	c.seq++
+	// The inner content type follows the content and precedes zero padding
+	i := len(plaintext) - 1
+	for i >= 0 && plaintext[i] == 0 {
+		i--
+	}
+	if i < 0 {
+		return 0, nil, errors.New("record has no content type")
+	}
+	return plaintext[i], plaintext[:i], nil
+}
+
+// recordDecrypter decrypts the records one side of a session sends. Its
+// ciphers are the session's successive traffic keys (the handshake and
+// application keys in TLS 1.3); decryption moves to a later key when the
+// current one fails to authenticate a record.
+type recordDecrypter struct {
+	ciphers []*recordCipher
+	current int
+}
+
+func (d *recordDecrypter) open(rec tlsRecord) (byte, []byte, error) {
+	typ, plaintext, err := d.ciphers[d.current].open(rec)
+	if err == nil {
+		return typ, plaintext, nil
+	}
+	for i := d.current + 1; i < len(d.ciphers); i++ {
+		if typ, plaintext, err := d.ciphers[i].open(rec); err == nil {
+			d.current = i
+			return typ, plaintext, nil
+		}
+	}
+	return 0, nil, err
+}
+
+// decryptTLS returns the decrypted application data each side of a TLS
+// connection sent.
+func decryptTLS(conn *connection, keys *KeyLog) (client, server *stream, err error) {
+	clientRecords := parseRecords(conn.clientData.data)
+	serverRecords := parseRecords(conn.serverData.data)
+	clientHello := findHello(clientRecords, handshakeClientHello)
+	serverHello := findHello(serverRecords, handshakeServerHello)
+	if clientHello == nil || serverHello == nil {
+		return nil, nil, errTLSHandshake
+	}
+	suite, ok := cipherSuites[serverHello.suite]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w 0x%04x", errUnsupportedSuite, serverHello.suite)
+	}
+
+	tls13 := serverHello.version == versionTLS13
+	var clientDec, serverDec *recordDecrypter
+	if tls13 {
+		clientDec, err = tls13Decrypter(keys, clientHello.random, suite, labelClientHandshakeSecret, labelClientTrafficSecret)
+		if err != nil {
+			return nil, nil, err
+		}
+		serverDec, err = tls13Decrypter(keys, clientHello.random, suite, labelServerHandshakeSecret, labelServerTrafficSecret)
+	} else {
+		clientDec, serverDec, err = tls12Decrypters(keys, clientHello.random, serverHello.random, suite)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client = decryptRecords(clientRecords, &conn.clientData.stream, clientDec, tls13)
+	server = decryptRecords(serverRecords, &conn.serverData.stream, serverDec, tls13)
+	return client, server, nil
+}
+
+// decryptRecords decrypts the application data records of one side of a
+// session. Records that fail to decrypt are skipped.
+func decryptRecords(records []tlsRecord, in *stream, d *recordDecrypter, tls13 bool) *stream {
+	out := &stream{}
+	encrypted := tls13
+	for _, rec := range records {
+		if rec.typ == recordChangeCipherSpec {
+			encrypted = true // TLS 1.2 records are encrypted after ChangeCipherSpec
+			continue
+		}
+		if !encrypted || tls13 && rec.typ != recordApplicationData {
+			continue
+		}
+		typ, plaintext, err := d.open(rec)
+		if err != nil {
+			continue
+		}
+		if typ == recordApplicationData {
+			out.write(plaintext, in.timeAt(rec.end-1))
+		}
+	}
+	return out
+}
+
+// tls12Decrypters derives the TLS 1.2 traffic keys from the logged master
+// secret.
+func tls12Decrypters(keys *KeyLog, clientRandom, serverRandom []byte, suite cipherSuite) (*recordDecrypter, *recordDecrypter, error) {
+	master := keys.secret(labelClientRandom, clientRandom)
+	if master == nil {
+		return nil, nil, errNoSecrets
+	}
+	seed := append(append([]byte(nil), serverRandom...), clientRandom...)
+	block := prf12(suite.hash, master, "key expansion", seed, 2*suite.keyLen+8)
+
+	clientKey, block := block[:suite.keyLen], block[suite.keyLen:]
+	serverKey, block := block[:suite.keyLen], block[suite.keyLen:]
+	clientIV, serverIV := block[:4], block[4:8]
+
+	client, err := newRecordCipher(clientKey, clientIV, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	server, err := newRecordCipher(serverKey, serverIV, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &recordDecrypter{ciphers: []*recordCipher{client}}, &recordDecrypter{ciphers: []*recordCipher{server}}, nil
+}
+
+// prf12 is the TLS 1.2 PRF (RFC 5246 section 5).
+func prf12(h func() hash.Hash, secret []byte, label string, seed []byte, n int) []byte {
+	seed = append([]byte(label), seed...)
+	mac := hmac.New(h, secret)
+	mac.Write(seed)
+	a := mac.Sum(nil)
+
+	var out []byte
+	for len(out) < n {
+		mac.Reset()
+		mac.Write(a)
+		mac.Write(seed)
+		out = mac.Sum(out)
+
+		mac.Reset()
+		mac.Write(a)
+		a = mac.Sum(nil)
+	}
+	return out[:n]
+}
+
+// tls13Decrypter builds a decrypter from the logged handshake and
+// application traffic secrets of one side. The handshake secret is optional.
+func tls13Decrypter(keys *KeyLog, clientRandom []byte, suite cipherSuite, handshakeLabel, trafficLabel string) (*recordDecrypter, error) {
+	d := &recordDecrypter{}
+	for _, label := range []string{handshakeLabel, trafficLabel} {
+		secret := keys.secret(label, clientRandom)
+		if secret == nil {
+			continue
+		}
+		key, err := hkdfExpandLabel(suite.hash, secret, "key", suite.keyLen)
+		if err != nil {
+			return nil, err
+		}
+		iv, err := hkdfExpandLabel(suite.hash, secret, "iv", 12)
+		if err != nil {
+			return nil, err
+		}
+		c, err := newRecordCipher(key, iv, true)
+		if err != nil {
+			return nil, err
+		}
+		d.ciphers = append(d.ciphers, c)
+	}
+	if keys.secret(trafficLabel, clientRandom) == nil {
+		return nil, errNoSecrets
+	}
+	return d, nil
+}
+
+// hkdfExpandLabel is TLS 1.3 HKDF-Expand-Label with an empty context
+// (RFC 8446 section 7.1).
+func hkdfExpandLabel(h func() hash.Hash, secret []byte, label string, length int) ([]byte, error) {
+	label = "tls13 " + label
+	info := make([]byte, 0, 4+len(label))
+	info = binary.BigEndian.AppendUint16(info, uint16(length))
+	info = append(info, byte(len(label)))
+	info = append(info, label...)
+	info = append(info, 0)
+	return hkdf.Expand(h, secret, string(info), length)
+}
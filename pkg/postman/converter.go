@@ -13,6 +13,7 @@ package postman
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"regexp"
 	"strings"
 	"time"
@@ -48,6 +49,9 @@ type Converter struct {
 
 	// PreserveAuth converts Postman auth configurations to request headers.
 	PreserveAuth bool
+
+	// Metadata is copied into the metadata of every converted record.
+	Metadata map[string]any
 }
 
 // NewConverter creates a new Postman to IR converter with default settings.
@@ -257,6 +261,7 @@ func (c *Converter) convertItem(item *postman.Items, ctx *walkContext, records *
 			Response:    ir.Response{Status: 200},
 			OperationId: ptrString(operationId),
 			Summary:     ptrString(item.Name),
+			Metadata:    maps.Clone(c.Metadata),
 		}
 
 		if item.Description != "" {
@@ -298,6 +303,7 @@ func (c *Converter) convertItem(item *postman.Items, ctx *walkContext, records *
 			Response:    respDetails,
 			OperationId: ptrString(operationId),
 			Summary:     ptrString(item.Name),
+			Metadata:    maps.Clone(c.Metadata),
 		}
 
 		// Add response name to description if different from item name
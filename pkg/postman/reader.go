@@ -3,7 +3,9 @@ package postman
 import (
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"path/filepath"
 
 	postman "github.com/rbretecher/go-postman-collection"
 
@@ -31,6 +33,7 @@ func Read(r io.Reader) (*postman.Collection, error) {
 }
 
 // ConvertFile is a convenience function that reads and converts a Postman collection file.
+// The file name is recorded in each record's metadata.
 func ConvertFile(path string, opts ...ConverterOption) (*ConvertResult, error) {
 	collection, err := ReadFile(path)
 	if err != nil {
@@ -42,7 +45,12 @@ func ConvertFile(path string, opts ...ConverterOption) (*ConvertResult, error) {
 		opt(converter)
 	}
 
-	return converter.Convert(collection)
+	result, err := converter.Convert(collection)
+	if err != nil {
+		return nil, err
+	}
+	ir.ApplyMetadata(result.Records, map[string]any{ir.MetaSourceFile: filepath.Base(path)})
+	return result, nil
 }
 
 // ConvertFileToRecords is a convenience function that reads and converts to IR records.
@@ -66,7 +74,12 @@ func ConvertFileToBatch(path string, opts ...ConverterOption) (*ir.Batch, error)
 		opt(converter)
 	}
 
-	return converter.ConvertToBatch(collection)
+	batch, err := converter.ConvertToBatch(collection)
+	if err != nil {
+		return nil, err
+	}
+	ir.ApplyMetadata(batch.Records, map[string]any{ir.MetaSourceFile: filepath.Base(path)})
+	return batch, nil
 }
 
 // ConverterOption is a functional option for configuring the converter.
@@ -123,6 +136,16 @@ func WithoutAuth() ConverterOption {
 	}
 }
 
+// WithMetadata adds metadata to every converted record.
+func WithMetadata(metadata map[string]any) ConverterOption {
+	return func(c *Converter) {
+		if c.Metadata == nil {
+			c.Metadata = make(map[string]any, len(metadata))
+		}
+		maps.Copy(c.Metadata, metadata)
+	}
+}
+
 // WithoutIDs disables automatic ID generation.
 func WithoutIDs() ConverterOption {
 	return func(c *Converter) {
@@ -0,0 +1,801 @@
+// Package raml reads RAML API definitions as OpenAPI specs, so legacy RAML
+// documents can be diffed against generated specs and checked for coverage
+// by captured traffic.
+//
+// RAML 1.0 is supported, along with the parts of RAML 0.8 it shares. Types,
+// traits, resource types (with parameters), and !include tags are resolved;
+// libraries (uses), annotations, and security schemes are ignored.
+package raml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grokify/traffic2openapi/pkg/openapi"
+)
+
+// ErrNotRAML is returned for documents without a #%RAML header.
+var ErrNotRAML = errors.New("not a RAML document")
+
+// maxIncludeDepth limits nested !include tags, which could otherwise recurse
+// forever.
+const maxIncludeDepth = 16
+
+// ReadFile reads a RAML document as an OpenAPI spec. !include tags are
+// resolved relative to the document's directory.
+func ReadFile(path string) (*openapi.Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	return parse(data, filepath.Dir(path))
+}
+
+// Parse parses a RAML document as an OpenAPI spec. !include tags are
+// resolved relative to the current directory.
+func Parse(data []byte) (*openapi.Spec, error) {
+	return parse(data, ".")
+}
+
+func parse(data []byte, dir string) (*openapi.Spec, error) {
+	header, _, _ := bytes.Cut(bytes.TrimSpace(data), []byte("\n"))
+	if !bytes.HasPrefix(header, []byte("#%RAML")) {
+		return nil, ErrNotRAML
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing RAML: %w", err)
+	}
+	if err := resolveIncludes(&root, dir, 0); err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := root.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing RAML: %w", err)
+	}
+	m, ok := normalize(doc).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("parsing RAML: document is not a map")
+	}
+
+	c := &converter{
+		version08:     bytes.HasPrefix(header, []byte("#%RAML 0.8")),
+		types:         declarations(m["types"], m["schemas"]),
+		traits:        declarations(m["traits"]),
+		resourceTypes: declarations(m["resourceTypes"]),
+	}
+	return c.convert(m), nil
+}
+
+// resolveIncludes replaces !include nodes with the included document: parsed
+// for RAML and YAML files, as a string otherwise (e.g. JSON schemas).
+func resolveIncludes(node *yaml.Node, dir string, depth int) error {
+	if node.Tag == "!include" {
+		if depth >= maxIncludeDepth {
+			return fmt.Errorf("resolving !include %s: too many nested includes", node.Value)
+		}
+		path := filepath.Join(dir, node.Value)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("resolving !include %s: %w", node.Value, err)
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".raml", ".yaml", ".yml":
+			var included yaml.Node
+			if err := yaml.Unmarshal(data, &included); err != nil {
+				return fmt.Errorf("resolving !include %s: %w", node.Value, err)
+			}
+			if len(included.Content) == 0 {
+				*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+				return nil
+			}
+			*node = *included.Content[0]
+			return resolveIncludes(node, filepath.Dir(path), depth+1)
+		default:
+			*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(data)}
+			return nil
+		}
+	}
+	for _, child := range node.Content {
+		if err := resolveIncludes(child, dir, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalize converts maps with non-string keys, such as response codes, to
+// map[string]any.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			v[k] = normalize(value)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, value := range v {
+			m[fmt.Sprint(k)] = normalize(value)
+		}
+		return m
+	case []any:
+		for i, value := range v {
+			v[i] = normalize(value)
+		}
+	}
+	return v
+}
+
+// declarations merges named declarations, given as maps or, in RAML 0.8, as
+// lists of single-entry maps.
+func declarations(sections ...any) map[string]any {
+	decls := make(map[string]any)
+	for _, section := range sections {
+		switch section := section.(type) {
+		case map[string]any:
+			for name, decl := range section {
+				decls[name] = decl
+			}
+		case []any:
+			for _, entry := range section {
+				if m, ok := entry.(map[string]any); ok {
+					for name, decl := range m {
+						decls[name] = decl
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// converter converts a decoded RAML document to a spec.
+type converter struct {
+	version08     bool
+	mediaTypes    []string
+	types         map[string]any
+	traits        map[string]any
+	resourceTypes map[string]any
+	spec          *openapi.Spec
+}
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+func (c *converter) convert(doc map[string]any) *openapi.Spec {
+	version := str(doc["version"])
+	c.spec = &openapi.Spec{
+		OpenAPI: string(openapi.Version31),
+		Info: openapi.Info{
+			Title:       str(doc["title"]),
+			Description: str(doc["description"]),
+			Version:     version,
+		},
+		Paths: make(map[string]*openapi.PathItem),
+	}
+	if c.spec.Info.Version == "" {
+		c.spec.Info.Version = "1.0.0"
+	}
+	if baseURI := str(doc["baseUri"]); baseURI != "" {
+		c.spec.Servers = []openapi.Server{{URL: strings.ReplaceAll(baseURI, "{version}", version)}}
+	}
+	switch mediaType := doc["mediaType"].(type) {
+	case string:
+		c.mediaTypes = []string{mediaType}
+	case []any:
+		for _, m := range mediaType {
+			c.mediaTypes = append(c.mediaTypes, str(m))
+		}
+	}
+	if len(c.mediaTypes) == 0 {
+		c.mediaTypes = []string{"application/json"}
+	}
+
+	if len(c.types) > 0 {
+		c.spec.Components = &openapi.Components{Schemas: make(map[string]*openapi.Schema)}
+		for _, name := range sortedKeys(c.types) {
+			c.spec.Components.Schemas[name] = c.schema(c.types[name])
+		}
+	}
+
+	for _, key := range sortedKeys(doc) {
+		if strings.HasPrefix(key, "/") {
+			resource, _ := doc[key].(map[string]any)
+			c.resource(key, resource, nil)
+		}
+	}
+	return c.spec
+}
+
+// resource adds the operations of a resource and its nested resources.
+func (c *converter) resource(path string, decl map[string]any, uriParams []openapi.Parameter) {
+	if decl == nil {
+		decl = map[string]any{}
+	}
+	decl = c.applyResourceType(path, decl, 0)
+
+	params := slices.Clone(uriParams)
+	if declared, ok := decl["uriParameters"].(map[string]any); ok {
+		for _, name := range sortedKeys(declared) {
+			p := c.parameter(name, "path", declared[name])
+			p.Required = true
+			params = append(params, p)
+		}
+	}
+	// Declare template parameters RAML leaves implicit as strings
+	for _, name := range templateParams(path) {
+		if !slices.ContainsFunc(params, func(p openapi.Parameter) bool { return p.Name == name }) {
+			params = append(params, openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}})
+		}
+	}
+	var pathParams []openapi.Parameter
+	for _, p := range params {
+		if slices.Contains(templateParams(path), p.Name) {
+			pathParams = append(pathParams, p)
+		}
+	}
+
+	item := &openapi.PathItem{Description: str(decl["description"]), Parameters: pathParams}
+	for _, method := range methods {
+		methodDecl, ok := decl[method]
+		if !ok {
+			continue
+		}
+		m, _ := methodDecl.(map[string]any)
+		if m == nil {
+			m = map[string]any{}
+		}
+		m = c.applyTraits(m, append(list(decl["is"]), list(m["is"])...), map[string]string{
+			"resourcePath":     path,
+			"resourcePathName": resourcePathName(path),
+			"methodName":       method,
+		})
+		op := c.operation(m)
+		switch method {
+		case "get":
+			item.Get = op
+		case "put":
+			item.Put = op
+		case "post":
+			item.Post = op
+		case "delete":
+			item.Delete = op
+		case "options":
+			item.Options = op
+		case "head":
+			item.Head = op
+		case "patch":
+			item.Patch = op
+		}
+	}
+	if item.Get != nil || item.Put != nil || item.Post != nil || item.Delete != nil || item.Options != nil || item.Head != nil || item.Patch != nil {
+		c.spec.Paths[path] = item
+	}
+
+	for _, key := range sortedKeys(decl) {
+		if strings.HasPrefix(key, "/") {
+			nested, _ := decl[key].(map[string]any)
+			c.resource(path+key, nested, params)
+		}
+	}
+}
+
+// applyResourceType merges the resource type a resource is declared with
+// under the resource's own declarations.
+func (c *converter) applyResourceType(path string, decl map[string]any, depth int) map[string]any {
+	name, params := reference(decl["type"])
+	rt, ok := c.resourceTypes[name].(map[string]any)
+	if !ok || depth > 8 {
+		return decl
+	}
+	params["resourcePath"] = path
+	params["resourcePathName"] = resourcePathName(path)
+	rt = substitute(rt, params).(map[string]any)
+	rt = c.applyResourceType(path, rt, depth+1)
+
+	// Optional methods, such as get?, apply only if the resource has them
+	for _, method := range methods {
+		if optional, ok := rt[method+"?"]; ok {
+			delete(rt, method+"?")
+			if _, ok := decl[method]; ok {
+				rt[method] = optional
+			}
+		}
+	}
+	merged := merge(rt, decl)
+	delete(merged, "type")
+	return merged
+}
+
+// applyTraits merges the traits a method is declared with under the method's
+// own declarations.
+func (c *converter) applyTraits(decl map[string]any, refs []any, reserved map[string]string) map[string]any {
+	for _, ref := range slices.Backward(refs) {
+		name, params := reference(ref)
+		trait, ok := c.traits[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		for k, v := range reserved {
+			params[k] = v
+		}
+		decl = merge(substitute(trait, params).(map[string]any), decl)
+	}
+	return decl
+}
+
+func (c *converter) operation(decl map[string]any) *openapi.Operation {
+	op := &openapi.Operation{
+		Summary:     str(decl["displayName"]),
+		Description: str(decl["description"]),
+		Responses:   make(map[string]openapi.Response),
+	}
+	for _, section := range []struct{ key, in string }{{"queryParameters", "query"}, {"headers", "header"}} {
+		params, _ := decl[section.key].(map[string]any)
+		for _, name := range sortedKeys(params) {
+			op.Parameters = append(op.Parameters, c.parameter(name, section.in, params[name]))
+		}
+	}
+
+	if content := c.content(decl["body"]); len(content) > 0 {
+		op.RequestBody = &openapi.RequestBody{Content: content, Required: true}
+	}
+
+	responses, _ := decl["responses"].(map[string]any)
+	for _, code := range sortedKeys(responses) {
+		r, _ := responses[code].(map[string]any)
+		resp := openapi.Response{Description: str(r["description"])}
+		if resp.Description == "" {
+			status, _ := strconv.Atoi(code)
+			resp.Description = http.StatusText(status)
+		}
+		headers, _ := r["headers"].(map[string]any)
+		for _, name := range sortedKeys(headers) {
+			p := c.parameter(name, "header", headers[name])
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]openapi.Header)
+			}
+			resp.Headers[p.Name] = openapi.Header{Description: p.Description, Required: p.Required, Schema: p.Schema}
+		}
+		resp.Content = c.content(r["body"])
+		op.Responses[code] = resp
+	}
+	return op
+}
+
+// content converts a body declaration, keyed by media type or a type for
+// the default media types.
+func (c *converter) content(body any) map[string]openapi.MediaType {
+	if body == nil {
+		return nil
+	}
+	content := make(map[string]openapi.MediaType)
+	if m, ok := body.(map[string]any); ok {
+		for _, key := range sortedKeys(m) {
+			if strings.Contains(key, "/") {
+				content[key] = openapi.MediaType{Schema: c.schema(m[key])}
+			}
+		}
+		if len(content) > 0 {
+			return content
+		}
+	}
+	for _, mediaType := range c.mediaTypes {
+		content[mediaType] = openapi.MediaType{Schema: c.schema(body)}
+	}
+	return content
+}
+
+// parameter converts a named parameter. Parameters are required unless
+// their name ends in ? or they set required: false, except in RAML 0.8,
+// where query parameters and headers are optional by default.
+func (c *converter) parameter(name, in string, decl any) openapi.Parameter {
+	p := openapi.Parameter{Name: name, In: in, Required: !c.version08}
+	if trimmed, ok := strings.CutSuffix(name, "?"); ok {
+		p.Name, p.Required = trimmed, false
+	}
+	if m, ok := decl.(map[string]any); ok {
+		if required, ok := m["required"].(bool); ok {
+			p.Required = required
+		}
+		p.Description = str(m["description"])
+		p.Example = m["example"]
+	}
+	p.Schema = c.schema(decl)
+	p.Schema.Description = ""
+	p.Schema.Example = nil
+	return p
+}
+
+// schema converts a type declaration: a type expression such as User[] or
+// string | nil, or a map of facets.
+func (c *converter) schema(decl any) *openapi.Schema {
+	m, ok := decl.(map[string]any)
+	if !ok {
+		if decl == nil {
+			return &openapi.Schema{Type: "string"}
+		}
+		return c.typeExpression(str(decl))
+	}
+
+	typ, ok := m["type"]
+	if !ok {
+		typ = m["schema"]
+	}
+	var schema *openapi.Schema
+	switch t := typ.(type) {
+	case nil:
+		switch {
+		case m["properties"] != nil:
+			schema = &openapi.Schema{Type: "object"}
+		case m["items"] != nil:
+			schema = &openapi.Schema{Type: "array"}
+		default:
+			schema = &openapi.Schema{Type: "string"}
+		}
+	case map[string]any:
+		schema = c.schema(t)
+	case []any:
+		schema = &openapi.Schema{}
+		for _, parent := range t {
+			schema.AllOf = append(schema.AllOf, c.typeExpression(str(parent)))
+		}
+	default:
+		schema = c.typeExpression(str(t))
+	}
+
+	if properties, ok := m["properties"].(map[string]any); ok {
+		object := schema
+		if schema.Ref != "" || len(schema.AllOf) > 0 {
+			// A subtype of a declared type adds its properties alongside
+			object = &openapi.Schema{Type: "object"}
+			if schema.Ref != "" {
+				schema = &openapi.Schema{AllOf: []*openapi.Schema{schema}}
+			}
+			schema.AllOf = append(schema.AllOf, object)
+		}
+		object.Properties = make(map[string]*openapi.Schema)
+		for _, key := range sortedKeys(properties) {
+			name, required := key, true
+			if trimmed, ok := strings.CutSuffix(key, "?"); ok {
+				name, required = trimmed, false
+			}
+			if strings.HasPrefix(name, "/") && strings.HasSuffix(name, "/") && len(name) > 1 {
+				// Pattern properties constrain additional properties
+				object.AdditionalProperties = c.schema(properties[key])
+				continue
+			}
+			if p, ok := properties[key].(map[string]any); ok {
+				if r, ok := p["required"].(bool); ok {
+					required = r
+				}
+			}
+			object.Properties[name] = c.schema(properties[key])
+			if required {
+				object.Required = append(object.Required, name)
+			}
+		}
+		if additional, ok := m["additionalProperties"].(bool); ok && !additional {
+			object.AdditionalProperties = false
+		}
+	}
+	if items, ok := m["items"]; ok && schema.Items == nil {
+		schema.Type = "array"
+		schema.Items = c.schema(items)
+	}
+
+	schema.Description = str(m["description"])
+	if enum, ok := m["enum"].([]any); ok {
+		schema.Enum = enum
+	}
+	schema.Pattern = str(m["pattern"])
+	if format := str(m["format"]); format != "" && schema.Format == "" {
+		schema.Format = format
+	}
+	schema.MinLength = intFacet(m["minLength"])
+	schema.MaxLength = intFacet(m["maxLength"])
+	schema.MinItems = intFacet(m["minItems"])
+	schema.MaxItems = intFacet(m["maxItems"])
+	schema.Minimum = floatFacet(m["minimum"])
+	schema.Maximum = floatFacet(m["maximum"])
+	if unique, ok := m["uniqueItems"].(bool); ok {
+		schema.UniqueItems = unique
+	}
+	schema.Default = m["default"]
+	schema.Example = m["example"]
+	return schema
+}
+
+// typeExpression converts a RAML type expression.
+func (c *converter) typeExpression(expr string) *openapi.Schema {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") && balanced(expr[1:len(expr)-1]) {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	if strings.HasPrefix(expr, "{") {
+		return jsonSchema(expr)
+	}
+
+	if variants := splitUnion(expr); len(variants) > 1 {
+		var schemas []*openapi.Schema
+		nullable := false
+		for _, variant := range variants {
+			if variant == "nil" {
+				nullable = true
+				continue
+			}
+			schemas = append(schemas, c.typeExpression(variant))
+		}
+		schema := &openapi.Schema{OneOf: schemas}
+		if len(schemas) == 1 {
+			schema = schemas[0]
+		}
+		if nullable {
+			return nullableSchema(schema)
+		}
+		return schema
+	}
+	if element, ok := strings.CutSuffix(expr, "[]"); ok {
+		return &openapi.Schema{Type: "array", Items: c.typeExpression(element)}
+	}
+	if base, ok := strings.CutSuffix(expr, "?"); ok {
+		return nullableSchema(c.typeExpression(base))
+	}
+
+	switch expr {
+	case "", "string":
+		return &openapi.Schema{Type: "string"}
+	case "number", "integer", "boolean", "object", "array":
+		return &openapi.Schema{Type: expr}
+	case "date-only":
+		return &openapi.Schema{Type: "string", Format: "date"}
+	case "datetime":
+		return &openapi.Schema{Type: "string", Format: "date-time"}
+	case "datetime-only", "time-only":
+		return &openapi.Schema{Type: "string"}
+	case "file":
+		return &openapi.Schema{Type: "string", Format: "binary"}
+	case "nil":
+		return &openapi.Schema{Type: "null"}
+	case "any":
+		return &openapi.Schema{}
+	}
+	if _, ok := c.types[expr]; ok {
+		return &openapi.Schema{Ref: "#/components/schemas/" + expr}
+	}
+	// Library types and unknown names can't be resolved
+	return &openapi.Schema{}
+}
+
+// jsonSchema parses a JSON Schema given as a type, as RAML 0.8 schemas are.
+func jsonSchema(s string) *openapi.Schema {
+	var schema openapi.Schema
+	if err := yaml.Unmarshal([]byte(s), &schema); err != nil {
+		return &openapi.Schema{}
+	}
+	return &schema
+}
+
+func nullableSchema(schema *openapi.Schema) *openapi.Schema {
+	if t, ok := schema.Type.(string); ok && schema.Ref == "" {
+		schema.Type = []string{t, "null"}
+		return schema
+	}
+	return &openapi.Schema{OneOf: []*openapi.Schema{schema, {Type: "null"}}}
+}
+
+// splitUnion splits a type expression on | outside parentheses.
+func splitUnion(expr string) []string {
+	var variants []string
+	depth, start := 0, 0
+	for i, r := range expr {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '|':
+			if depth == 0 {
+				variants = append(variants, strings.TrimSpace(expr[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(variants, strings.TrimSpace(expr[start:]))
+}
+
+func balanced(s string) bool {
+	depth := 0
+	for _, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// reference returns the name and parameters of a trait or resource type
+// reference: a name, or a map of a name to its parameters.
+func reference(ref any) (string, map[string]string) {
+	params := make(map[string]string)
+	switch ref := ref.(type) {
+	case string:
+		return ref, params
+	case map[string]any:
+		for name, values := range ref {
+			if m, ok := values.(map[string]any); ok {
+				for k, v := range m {
+					params[k] = str(v)
+				}
+			}
+			return name, params
+		}
+	}
+	return "", params
+}
+
+var templateParam = regexp.MustCompile(`<<\s*([A-Za-z0-9_]+)\s*((?:\|\s*![A-Za-z]+\s*)*)>>`)
+
+// substitute returns a copy of a trait or resource type with <<parameter>>
+// references, optionally transformed (<<name | !singularize>>), replaced.
+func substitute(v any, params map[string]string) any {
+	switch v := v.(type) {
+	case string:
+		return templateParam.ReplaceAllStringFunc(v, func(match string) string {
+			groups := templateParam.FindStringSubmatch(match)
+			value := params[groups[1]]
+			for _, fn := range strings.Split(groups[2], "|") {
+				value = transform(strings.TrimPrefix(strings.TrimSpace(fn), "!"), value)
+			}
+			return value
+		})
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, value := range v {
+			m[substitute(k, params).(string)] = substitute(value, params)
+		}
+		return m
+	case []any:
+		l := make([]any, len(v))
+		for i, value := range v {
+			l[i] = substitute(value, params)
+		}
+		return l
+	}
+	return v
+}
+
+// transform applies a RAML parameter function.
+func transform(fn, value string) string {
+	switch fn {
+	case "singularize":
+		switch {
+		case strings.HasSuffix(value, "ies"):
+			return strings.TrimSuffix(value, "ies") + "y"
+		case strings.HasSuffix(value, "s"):
+			return strings.TrimSuffix(value, "s")
+		}
+	case "pluralize":
+		switch {
+		case strings.HasSuffix(value, "y"):
+			return strings.TrimSuffix(value, "y") + "ies"
+		case !strings.HasSuffix(value, "s"):
+			return value + "s"
+		}
+	case "uppercase":
+		return strings.ToUpper(value)
+	case "lowercase":
+		return strings.ToLower(value)
+	case "uppercamelcase":
+		return strings.ToUpper(value[:min(1, len(value))]) + value[min(1, len(value)):]
+	case "lowercamelcase":
+		return strings.ToLower(value[:min(1, len(value))]) + value[min(1, len(value)):]
+	}
+	return value
+}
+
+// merge returns base with override merged into it, recursively for maps.
+func merge(base, override map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		baseMap, baseIsMap := merged[k].(map[string]any)
+		overrideMap, overrideIsMap := v.(map[string]any)
+		if baseIsMap && overrideIsMap {
+			merged[k] = merge(baseMap, overrideMap)
+		} else if v != nil || merged[k] == nil {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// resourcePathName returns the rightmost path segment that isn't a URI
+// parameter.
+func resourcePathName(path string) string {
+	segments := strings.Split(path, "/")
+	for _, segment := range slices.Backward(segments) {
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			return segment
+		}
+	}
+	return ""
+}
+
+var uriParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// templateParams returns the parameters of a path template.
+func templateParams(path string) []string {
+	var names []string
+	for _, m := range uriParam.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+func list(v any) []any {
+	switch v := v.(type) {
+	case []any:
+		return v
+	case nil:
+		return nil
+	}
+	return []any{v}
+}
+
+func str(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+func intFacet(v any) *int {
+	if n, ok := v.(int); ok {
+		return &n
+	}
+	return nil
+}
+
+func floatFacet(v any) *float64 {
+	switch n := v.(type) {
+	case int:
+		f := float64(n)
+		return &f
+	case float64:
+		return &n
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
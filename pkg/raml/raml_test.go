@@ -0,0 +1,202 @@
+package raml
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const usersRAML = `#%RAML 1.0
+title: Users API
+version: v2
+baseUri: https://api.example.com/{version}
+mediaType: application/json
+
+types:
+  User:
+    type: object
+    properties:
+      id: integer
+      name: string
+      email?: string
+      nickname: string | nil
+      role:
+        enum: [admin, member]
+      tags: string[]
+  Admin:
+    type: User
+    properties:
+      permissions: string[]
+
+traits:
+  paged:
+    queryParameters:
+      limit?:
+        type: integer
+        description: Max <<resourcePathName>> per page
+
+resourceTypes:
+  collection:
+    get?:
+      responses:
+        200:
+          body:
+            type: <<item>>[]
+    post?:
+      body:
+        type: <<item>>
+      responses:
+        201:
+          body:
+            type: <<item>>
+
+/users:
+  type: { collection: { item: User } }
+  get:
+    is: [paged]
+    displayName: List users
+  post:
+  /{userId}:
+    uriParameters:
+      userId: integer
+    get:
+      headers:
+        X-Request-Id:
+          required: false
+      responses:
+        200:
+          body:
+            application/json:
+              type: User
+        404:
+          description: No such user
+    delete:
+      responses:
+        204:
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(usersRAML))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if spec.Info.Title != "Users API" || spec.Info.Version != "v2" {
+		t.Errorf("Info = %+v", spec.Info)
+	}
+	if len(spec.Servers) != 1 || spec.Servers[0].URL != "https://api.example.com/v2" {
+		t.Errorf("Servers = %+v", spec.Servers)
+	}
+
+	user := spec.Components.Schemas["User"]
+	if user == nil {
+		t.Fatal("missing User schema")
+	}
+	if want := []string{"id", "name", "nickname", "role", "tags"}; !reflect.DeepEqual(user.Required, want) {
+		t.Errorf("User required = %v, want %v", user.Required, want)
+	}
+	if got := user.Properties["nickname"].Type; !reflect.DeepEqual(got, []string{"string", "null"}) {
+		t.Errorf("nickname type = %v, want [string null]", got)
+	}
+	if got := user.Properties["role"].Enum; !reflect.DeepEqual(got, []any{"admin", "member"}) {
+		t.Errorf("role enum = %v", got)
+	}
+	if tags := user.Properties["tags"]; tags.Type != "array" || tags.Items.Type != "string" {
+		t.Errorf("tags = %+v", tags)
+	}
+	admin := spec.Components.Schemas["Admin"]
+	if len(admin.AllOf) != 2 || admin.AllOf[0].Ref != "#/components/schemas/User" || admin.AllOf[1].Properties["permissions"] == nil {
+		t.Errorf("Admin = %+v", admin)
+	}
+
+	users := spec.Paths["/users"]
+	if users == nil || users.Get == nil || users.Post == nil {
+		t.Fatalf("/users = %+v", users)
+	}
+	if users.Get.Summary != "List users" {
+		t.Errorf("GET /users summary = %q", users.Get.Summary)
+	}
+	if len(users.Get.Parameters) != 1 || users.Get.Parameters[0].Name != "limit" || users.Get.Parameters[0].Required {
+		t.Errorf("GET /users parameters = %+v", users.Get.Parameters)
+	}
+	if got := users.Get.Parameters[0].Description; got != "Max users per page" {
+		t.Errorf("limit description = %q", got)
+	}
+	list := users.Get.Responses["200"].Content["application/json"].Schema
+	if list == nil || list.Type != "array" || list.Items.Ref != "#/components/schemas/User" {
+		t.Errorf("GET /users 200 schema = %+v", list)
+	}
+	if body := users.Post.RequestBody; body == nil || body.Content["application/json"].Schema.Ref != "#/components/schemas/User" {
+		t.Errorf("POST /users body = %+v", body)
+	}
+	if _, ok := users.Post.Responses["201"]; !ok {
+		t.Errorf("POST /users responses = %v", users.Post.Responses)
+	}
+
+	user1 := spec.Paths["/users/{userId}"]
+	if user1 == nil || user1.Get == nil || user1.Delete == nil {
+		t.Fatalf("/users/{userId} = %+v", user1)
+	}
+	if len(user1.Parameters) != 1 || user1.Parameters[0].In != "path" || user1.Parameters[0].Schema.Type != "integer" {
+		t.Errorf("path parameters = %+v", user1.Parameters)
+	}
+	if len(user1.Get.Parameters) != 1 || user1.Get.Parameters[0].In != "header" || user1.Get.Parameters[0].Required {
+		t.Errorf("GET /users/{userId} parameters = %+v", user1.Get.Parameters)
+	}
+	if got := user1.Get.Responses["404"].Description; got != "No such user" {
+		t.Errorf("404 description = %q", got)
+	}
+	if got := user1.Delete.Responses["204"].Description; got != "No Content" {
+		t.Errorf("204 description = %q", got)
+	}
+}
+
+func TestReadFileInclude(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("api.raml", `#%RAML 0.8
+title: Legacy
+schemas:
+  - Order: !include order.json
+/orders:
+  get:
+    queryParameters:
+      status:
+        type: string
+    responses:
+      200:
+        body:
+          application/json:
+            schema: Order
+`)
+	write("order.json", `{"type": "object", "properties": {"id": {"type": "string"}}}`)
+
+	spec, err := ReadFile(filepath.Join(dir, "api.raml"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	order := spec.Components.Schemas["Order"]
+	if order == nil || order.Properties["id"] == nil {
+		t.Errorf("Order = %+v", order)
+	}
+	get := spec.Paths["/orders"].Get
+	if len(get.Parameters) != 1 || get.Parameters[0].Required {
+		t.Errorf("RAML 0.8 query parameters should be optional: %+v", get.Parameters)
+	}
+	if got := get.Responses["200"].Content["application/json"].Schema.Ref; got != "#/components/schemas/Order" {
+		t.Errorf("200 schema ref = %q", got)
+	}
+}
+
+func TestParseNotRAML(t *testing.T) {
+	_, err := Parse([]byte("openapi: 3.1.0\n"))
+	if !errors.Is(err, ErrNotRAML) {
+		t.Errorf("Parse error = %v, want ErrNotRAML", err)
+	}
+}
@@ -0,0 +1,115 @@
+package resources
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes the graph as JSON.
+func WriteJSON(w io.Writer, g *Graph) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(g); err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	return nil
+}
+
+// WriteMermaid writes the graph as a Mermaid erDiagram. Each resource is an
+// entity listing its fields, with formats as comments; a relation is drawn
+// as many-to-one, or many-to-many for fields holding several references.
+func WriteMermaid(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "erDiagram")
+	for _, res := range g.Resources {
+		fmt.Fprintf(bw, "    %s {\n", mermaidName(res.Name))
+		for _, field := range res.Fields {
+			typ := field.Type
+			if typ == "" {
+				typ = "any"
+			}
+			fmt.Fprintf(bw, "        %s %s", mermaidName(typ), mermaidName(field.Name))
+			if field.Format != "" {
+				fmt.Fprintf(bw, " %q", field.Format)
+			}
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintln(bw, "    }")
+	}
+	for _, rel := range g.Relations {
+		cardinality := "}o--||"
+		if rel.Many {
+			cardinality = "}o--o{"
+		}
+		fmt.Fprintf(bw, "    %s %s %s : %q\n", mermaidName(rel.From), cardinality, mermaidName(rel.To), rel.Field)
+	}
+	return bw.Flush()
+}
+
+// WriteDOT writes the graph as a Graphviz digraph of record nodes, with an
+// edge from each resource to those it refers to.
+func WriteDOT(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph resources {")
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	fmt.Fprintln(bw, "  node [shape=record];")
+	for _, res := range g.Resources {
+		var label strings.Builder
+		label.WriteString("{" + recordEscape(res.Name) + "|")
+		for _, field := range res.Fields {
+			label.WriteString(recordEscape(field.Name+": "+fieldType(field)) + `\l`)
+		}
+		label.WriteString("}")
+		fmt.Fprintf(bw, "  %s [label=%s];\n", dotQuote(res.Name), dotQuote(label.String()))
+	}
+	for _, rel := range g.Relations {
+		attrs := "label=" + dotQuote(rel.Field)
+		if rel.Many {
+			attrs += ", arrowhead=crow"
+		}
+		fmt.Fprintf(bw, "  %s -> %s [%s];\n", dotQuote(rel.From), dotQuote(rel.To), attrs)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// fieldType returns the type shown for a field in DOT labels, with its
+// format if any.
+func fieldType(field *Field) string {
+	switch {
+	case field.Type == "":
+		return "any"
+	case field.Format != "":
+		return field.Type + "(" + field.Format + ")"
+	}
+	return field.Type
+}
+
+// mermaidName makes a name usable as a Mermaid entity, attribute, or type,
+// which may hold only letters, digits, '-', and '_'.
+func mermaidName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// dotQuote returns s as a quoted DOT ID. Backslashes are kept, so record
+// labels may use escapes such as \l.
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// recordEscape escapes the characters with a meaning in DOT record labels.
+func recordEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "{", `\{`, "}", `\}`, "|", `\|`, "<", `\<`, ">", `\>`).Replace(s)
+}
@@ -0,0 +1,299 @@
+// Package resources derives a resource model from inferred endpoints: the
+// REST resources an API exposes, their fields, and the relations between
+// them, exported as JSON or as an entity-relationship diagram in Mermaid or
+// Graphviz DOT syntax.
+//
+// Resources are the collections and singletons of endpoints with a CRUD
+// action (see inference.ClassifyCRUD), named in the singular (/users gives
+// user). Their fields are the top-level properties of the request and 2xx
+// response bodies of those endpoints, unwrapping arrays and list envelopes
+// such as {"data": [...]} and {"users": [...], "total": 1}. Relations come from nested paths
+// (/users/{userId}/posts) and foreign-key-like fields (userId, user_id,
+// tagIds, or an embedded user object) naming another resource.
+package resources
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+)
+
+// Graph is the resource model of an API.
+type Graph struct {
+	Resources []*Resource `json:"resources"`
+	Relations []*Relation `json:"relations"`
+}
+
+// Resource is a REST resource, such as the users of /users and
+// /users/{userId}.
+type Resource struct {
+	Name       string       `json:"name"`  // singular, e.g. "user"
+	Paths      []string     `json:"paths"` // collection paths, e.g. "/users"
+	Operations []*Operation `json:"operations"`
+	Fields     []*Field     `json:"fields"`
+}
+
+// Operation is an endpoint acting on a resource.
+type Operation struct {
+	Method string               `json:"method"`
+	Path   string               `json:"path"`
+	Action inference.CRUDAction `json:"action"`
+}
+
+// Field is a top-level property of a resource's bodies.
+type Field struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Format string `json:"format,omitempty"`
+}
+
+// Relation kinds, recorded in Relation.Via.
+const (
+	ViaPath  = "path"  // the resource is nested under the other in paths
+	ViaField = "field" // a field of the resource refers to the other
+)
+
+// Relation is a reference from one resource to another, such as a post's
+// userId. Many is set when the field holds several references (tagIds).
+type Relation struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Field string `json:"field"` // path parameter or field naming the other resource
+	Many  bool   `json:"many,omitempty"`
+	Via   string `json:"via"`
+}
+
+// listEnvelopes are the properties of list responses holding the items.
+var listEnvelopes = []string{"data", "items", "results", "records"}
+
+// Build derives the resource graph of an inference result.
+func Build(result *inference.InferenceResult) *Graph {
+	keys := make([]string, 0, len(result.Endpoints))
+	for key := range result.Endpoints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	byName := make(map[string]*Resource)
+	fields := make(map[string]map[string]*Field)
+	var relations []*Relation
+	for _, key := range keys {
+		endpoint := result.Endpoints[key]
+		if endpoint.Action == "" {
+			continue
+		}
+		path, parentParam := collectionPath(endpoint.PathTemplate)
+		words := inference.CRUDResource(inference.CRUDGet, path)
+		if len(words) == 0 {
+			continue
+		}
+		name := words[len(words)-1]
+
+		res, ok := byName[name]
+		if !ok {
+			res = &Resource{Name: name}
+			byName[name] = res
+			fields[name] = make(map[string]*Field)
+		}
+		if !slices.Contains(res.Paths, path) {
+			res.Paths = append(res.Paths, path)
+		}
+		res.Operations = append(res.Operations, &Operation{
+			Method: endpoint.Method,
+			Path:   endpoint.PathTemplate,
+			Action: endpoint.Action,
+		})
+		for _, node := range entityNodes(endpoint) {
+			addFields(fields[name], node)
+		}
+
+		if parentParam != "" && len(words) > 1 {
+			relations = append(relations, &Relation{From: name, To: words[len(words)-2], Field: parentParam, Via: ViaPath})
+		}
+	}
+
+	graph := &Graph{Resources: make([]*Resource, 0, len(byName)), Relations: []*Relation{}}
+	for name, res := range byName {
+		res.Fields = sortedFields(fields[name])
+		graph.Resources = append(graph.Resources, res)
+		for _, field := range res.Fields {
+			if to, many, ok := fieldReference(field, byName); ok && to != name {
+				relations = append(relations, &Relation{From: name, To: to, Field: field.Name, Many: many, Via: ViaField})
+			}
+		}
+	}
+	sort.Slice(graph.Resources, func(i, j int) bool { return graph.Resources[i].Name < graph.Resources[j].Name })
+
+	// A field duplicating a path relation, as the userId of posts under
+	// /users/{userId}/posts, is recorded once, as the path relation
+	seen := make(map[[3]string]bool)
+	for _, rel := range relations {
+		key := [3]string{rel.From, rel.To, rel.Field}
+		if _, ok := byName[rel.To]; !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		graph.Relations = append(graph.Relations, rel)
+	}
+	sort.Slice(graph.Relations, func(i, j int) bool {
+		a, b := graph.Relations[i], graph.Relations[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Field < b.Field
+	})
+	return graph
+}
+
+// collectionPath returns the collection or singleton path of an endpoint
+// template, without a trailing item parameter, and the parameter of the
+// parent item it is nested under, if any: /users/{userId}/posts/{postId}
+// gives /users/{userId}/posts and userId.
+func collectionPath(template string) (path, parentParam string) {
+	segments := strings.Split(strings.Trim(template, "/"), "/")
+	if n := len(segments); n > 0 && isParam(segments[n-1]) {
+		segments = segments[:n-1]
+	}
+	if n := len(segments); n >= 2 && isParam(segments[n-2]) {
+		parentParam = strings.Trim(segments[n-2], "{}")
+	}
+	return "/" + strings.Join(segments, "/"), parentParam
+}
+
+// isParam reports whether a template segment is a parameter.
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// entityNodes returns the schemas of the resource in an endpoint's request
+// body and 2xx response bodies.
+func entityNodes(endpoint *inference.EndpointData) []*inference.SchemaNode {
+	var nodes []*inference.SchemaNode
+	if endpoint.RequestBody != nil && endpoint.Action != inference.CRUDList {
+		if node := entityNode(endpoint.RequestBody.Schema, false); node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	statuses := make([]int, 0, len(endpoint.Responses))
+	for status := range endpoint.Responses {
+		if status >= 200 && status < 300 {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		if node := entityNode(endpoint.Responses[status].Body, endpoint.Action == inference.CRUDList); node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// entityNode returns the object schema of a resource in a body: the body
+// itself, the items of an array, or the contents of a data envelope. list
+// selects the items of list envelopes.
+func entityNode(store *inference.SchemaStore, list bool) *inference.SchemaNode {
+	if store == nil || len(store.Examples) == 0 {
+		return nil
+	}
+	node := inference.BuildSchemaTree(store)
+	if node.Type == inference.TypeArray {
+		node = node.Items
+	} else if inner := envelopeContents(node, list); inner != nil {
+		node = inner
+	}
+	if node == nil || node.Type != inference.TypeObject || len(node.Properties) == 0 {
+		return nil
+	}
+	return node
+}
+
+// envelopeContents returns the items of a list envelope ({"data": [...]},
+// or any object with a single array property, as {"users": [...], "total":
+// 1}) when list is set, or the object of a data envelope ({"data": {...}}).
+func envelopeContents(node *inference.SchemaNode, list bool) *inference.SchemaNode {
+	for _, name := range listEnvelopes {
+		prop := node.Properties[name]
+		switch {
+		case prop == nil:
+		case list && prop.Type == inference.TypeArray:
+			return prop.Items
+		case name == "data" && prop.Type == inference.TypeObject:
+			return prop
+		}
+	}
+	if !list {
+		return nil
+	}
+	var items *inference.SchemaNode
+	for _, prop := range node.Properties {
+		if prop.Type == inference.TypeArray {
+			if items != nil {
+				return nil
+			}
+			items = prop.Items
+		}
+	}
+	return items
+}
+
+// addFields records the top-level properties of an object schema; the first
+// type seen for a field is kept.
+func addFields(fields map[string]*Field, node *inference.SchemaNode) {
+	for name, prop := range node.Properties {
+		if _, ok := fields[name]; !ok {
+			fields[name] = &Field{Name: name, Type: prop.Type, Format: prop.Format}
+		}
+	}
+}
+
+// sortedFields returns fields sorted by name, with id first.
+func sortedFields(fields map[string]*Field) []*Field {
+	list := make([]*Field, 0, len(fields))
+	for _, field := range fields {
+		list = append(list, field)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].Name == "id") != (list[j].Name == "id") {
+			return list[i].Name == "id"
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// referenceSuffixes are the suffixes of fields referring to another
+// resource, and whether they hold several references.
+var referenceSuffixes = []struct {
+	suffix string
+	many   bool
+}{
+	{"Ids", true}, {"_ids", true}, {"IDs", true},
+	{"Id", false}, {"_id", false}, {"ID", false},
+}
+
+// fieldReference returns the resource a field refers to: userId, user_id,
+// and an embedded user object refer to user, and tagIds to several tags.
+func fieldReference(field *Field, resources map[string]*Resource) (string, bool, bool) {
+	for _, s := range referenceSuffixes {
+		prefix, ok := strings.CutSuffix(field.Name, s.suffix)
+		if !ok || prefix == "" {
+			continue
+		}
+		name := strings.ToLower(prefix)
+		if _, ok := resources[name]; ok {
+			return name, s.many || field.Type == inference.TypeArray, true
+		}
+	}
+	if field.Type == inference.TypeObject {
+		if _, ok := resources[field.Name]; ok {
+			return field.Name, false, true
+		}
+	}
+	return "", false, false
+}
@@ -0,0 +1,104 @@
+package resources
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/inference"
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func testGraph() *Graph {
+	records := []ir.IRRecord{
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users"},
+			Response: ir.Response{Status: 200, Body: map[string]any{
+				"users": []any{map[string]any{"id": "1", "name": "Alice"}},
+				"total": 1,
+			}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/users/1"},
+			Response: ir.Response{Status: 200, Body: map[string]any{"id": "1", "name": "Alice", "email": "alice@example.com"}},
+		},
+		{
+			Request: ir.Request{Method: ir.RequestMethodGET, Path: "/users/1/posts"},
+			Response: ir.Response{Status: 200, Body: map[string]any{
+				"data": []any{map[string]any{"id": "7", "userId": "1", "title": "Hi", "tagIds": []any{"3"}}},
+			}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/tags", Body: map[string]any{"label": "go"}},
+			Response: ir.Response{Status: 201, Body: map[string]any{"id": "3", "label": "go"}},
+		},
+		{
+			Request:  ir.Request{Method: ir.RequestMethodPOST, Path: "/users/1/activate"},
+			Response: ir.Response{Status: 200},
+		},
+	}
+	return Build(inference.InferFromRecords(records))
+}
+
+func TestBuild(t *testing.T) {
+	g := testGraph()
+
+	var names []string
+	for _, res := range g.Resources {
+		names = append(names, res.Name)
+	}
+	if want := []string{"post", "tag", "user"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("resources = %v, want %v", names, want)
+	}
+
+	user := g.Resources[2]
+	if !reflect.DeepEqual(user.Paths, []string{"/users"}) {
+		t.Errorf("user paths = %v", user.Paths)
+	}
+	if len(user.Operations) != 2 {
+		t.Errorf("user operations = %d, want 2 (activate has no CRUD action)", len(user.Operations))
+	}
+	var fields []string
+	for _, field := range user.Fields {
+		fields = append(fields, field.Name)
+	}
+	if want := []string{"id", "email", "name"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("user fields = %v, want %v", fields, want)
+	}
+
+	want := []*Relation{
+		{From: "post", To: "tag", Field: "tagIds", Many: true, Via: ViaField},
+		{From: "post", To: "user", Field: "userId", Via: ViaPath},
+	}
+	if !reflect.DeepEqual(g.Relations, want) {
+		for _, rel := range g.Relations {
+			t.Logf("relation %+v", *rel)
+		}
+		t.Errorf("unexpected relations")
+	}
+}
+
+func TestWriteDiagrams(t *testing.T) {
+	g := testGraph()
+
+	var mermaid bytes.Buffer
+	if err := WriteMermaid(&mermaid, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"erDiagram\n", "    user {\n", "        string email \"email\"\n", `    post }o--o{ tag : "tagIds"`, `    post }o--|| user : "userId"`} {
+		if !strings.Contains(mermaid.String(), want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, mermaid.String())
+		}
+	}
+
+	var dot bytes.Buffer
+	if err := WriteDOT(&dot, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"digraph resources {", `"tag" [label="{tag|id: string\llabel: string\l}"];`, `"post" -> "tag" [label="tagIds", arrowhead=crow];`} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("DOT output missing %q:\n%s", want, dot.String())
+		}
+	}
+}
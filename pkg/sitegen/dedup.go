@@ -4,15 +4,51 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 
+	"github.com/grokify/traffic2openapi/pkg/inference"
 	"github.com/grokify/traffic2openapi/pkg/ir"
 )
 
+// DedupOptions configures which parts of a record make up its dedup key.
+// The zero value gives the default key: method, path template, query keys,
+// request body structure, and status.
+type DedupOptions struct {
+	// IncludeQueryKeys limits the query keys considered to these, if set.
+	IncludeQueryKeys []string
+
+	// ExcludeQueryKeys are query keys ignored, such as cache busters.
+	ExcludeQueryKeys []string
+
+	// RequestHeaderStructure adds the names (not values) of request headers.
+	RequestHeaderStructure bool
+
+	// RequestHeaderValues adds the values of these request headers
+	// (case-insensitive), such as a feature-flag header.
+	RequestHeaderValues []string
+
+	// ResponseStructure adds the response body structure.
+	ResponseStructure bool
+
+	// KeyFunc replaces the key computation entirely, if set.
+	KeyFunc func(record *ir.IRRecord, pathTemplate string) string
+}
+
 // ComputeDedupKey computes a deduplication key for a request.
 // The key is based on: method + pathTemplate + sorted query keys + body structure + status.
 func ComputeDedupKey(record *ir.IRRecord, pathTemplate string) string {
+	return DedupOptions{}.Key(record, pathTemplate)
+}
+
+// Key computes the deduplication key for a request.
+func (o DedupOptions) Key(record *ir.IRRecord, pathTemplate string) string {
+	if o.KeyFunc != nil {
+		return o.KeyFunc(record, pathTemplate)
+	}
+
 	h := sha256.New()
 
 	// Method + path template
@@ -23,56 +59,55 @@ func ComputeDedupKey(record *ir.IRRecord, pathTemplate string) string {
 	if record.Request.Query != nil {
 		queryKeys := sortedMapKeys(record.Request.Query)
 		for _, k := range queryKeys {
-			h.Write([]byte(k))
+			if o.includesQueryKey(k) {
+				h.Write([]byte(k))
+			}
 		}
 	}
 
 	// Body structure fingerprint
 	if record.Request.Body != nil {
-		h.Write([]byte(structureFingerprint(record.Request.Body)))
+		h.Write([]byte(ir.StructureFingerprint(record.Request.Body)))
 	}
 
 	// Response status
 	h.Write([]byte(strconv.Itoa(record.Response.Status)))
 
+	// Optional parts follow, so the default key is unchanged
+	if o.RequestHeaderStructure {
+		names := make([]string, 0, len(record.Request.Headers))
+		for name := range record.Request.Headers {
+			names = append(names, strings.ToLower(name))
+		}
+		sort.Strings(names)
+		h.Write([]byte("headers:" + strings.Join(names, ",")))
+	}
+	for _, name := range o.RequestHeaderValues {
+		h.Write([]byte("header:" + strings.ToLower(name) + "=" + headerValue(record.Request.Headers, name)))
+	}
+	if o.ResponseStructure && record.Response.Body != nil {
+		h.Write([]byte("response:" + ir.StructureFingerprint(record.Response.Body)))
+	}
+
 	return hex.EncodeToString(h.Sum(nil))[:16]
 }
 
-// structureFingerprint computes a fingerprint of a JSON structure.
-// It captures the shape (keys, types) but not the actual values.
-func structureFingerprint(v any) string {
-	switch val := v.(type) {
-	case map[string]any:
-		keys := sortedMapKeys(val)
-		parts := make([]string, len(keys))
-		for i, k := range keys {
-			parts[i] = k + ":" + structureFingerprint(val[k])
-		}
-		result := "{"
-		for i, p := range parts {
-			if i > 0 {
-				result += ","
-			}
-			result += p
-		}
-		result += "}"
-		return result
-	case []any:
-		if len(val) > 0 {
-			return "[]" + structureFingerprint(val[0])
+// includesQueryKey reports whether a query key is part of the key.
+func (o DedupOptions) includesQueryKey(key string) bool {
+	if len(o.IncludeQueryKeys) > 0 && !slices.Contains(o.IncludeQueryKeys, key) {
+		return false
+	}
+	return !slices.Contains(o.ExcludeQueryKeys, key)
+}
+
+// headerValue returns the value of a header, matching its name case-insensitively.
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
 		}
-		return "[]"
-	case string:
-		return "string"
-	case float64:
-		return "number"
-	case bool:
-		return "bool"
-	case nil:
-		return "null"
-	default:
-		return fmt.Sprintf("%T", v)
 	}
+	return ""
 }
 
 // sortedMapKeys returns the keys of a map sorted alphabetically.
@@ -151,6 +186,9 @@ func recordToRequestView(rec *StoredRecord) *RequestView {
 		ContentType:     contentType,
 		DurationMs:      durationMs,
 		DedupKey:        rec.DedupKey,
+
+		RequestHeaderValues:  r.Request.HeaderValues,
+		ResponseHeaderValues: r.Response.HeaderValues,
 	}
 }
 
@@ -217,3 +255,82 @@ func createDedupedView(records []*StoredRecord) *DedupedView {
 
 	return dv
 }
+
+// Deduper reduces a stream of records to at most PerGroup representatives per
+// (endpoint, status, structure) group. Groups use the dedup key extended
+// with the response body structure so that schema coverage is preserved.
+type Deduper struct {
+	// PerGroup is the maximum number of records kept per group.
+	PerGroup int
+
+	// Options configures the dedup key. The response body structure is
+	// always included unless Options.KeyFunc is set.
+	Options DedupOptions
+
+	counts map[string]int
+	seen   int
+	kept   int
+}
+
+// NewDeduper creates a new Deduper keeping up to perGroup records per group.
+func NewDeduper(perGroup int) *Deduper {
+	if perGroup < 1 {
+		perGroup = 1
+	}
+	return &Deduper{
+		PerGroup: perGroup,
+		counts:   make(map[string]int),
+	}
+}
+
+// Keep reports whether a record should be kept. Records must be passed in
+// capture order; the first PerGroup records of each group are kept.
+func (d *Deduper) Keep(record *ir.IRRecord) bool {
+	d.seen++
+
+	var pathTemplate string
+	if record.Request.PathTemplate != nil {
+		pathTemplate = *record.Request.PathTemplate
+	} else {
+		pathTemplate, _ = inference.InferPathTemplate(record.Request.Path)
+	}
+
+	opts := d.Options
+	opts.ResponseStructure = true
+	key := opts.Key(record, pathTemplate)
+
+	if d.counts[key] >= d.PerGroup {
+		return false
+	}
+	d.counts[key]++
+	d.kept++
+	return true
+}
+
+// Seen returns the number of records checked.
+func (d *Deduper) Seen() int {
+	return d.seen
+}
+
+// Kept returns the number of records kept.
+func (d *Deduper) Kept() int {
+	return d.kept
+}
+
+// Groups returns the number of distinct groups seen.
+func (d *Deduper) Groups() int {
+	return len(d.counts)
+}
+
+// DedupeRecords returns at most perGroup representative records per
+// (endpoint, status, structure) group, preserving input order.
+func DedupeRecords(records []ir.IRRecord, perGroup int) []ir.IRRecord {
+	d := NewDeduper(perGroup)
+	result := make([]ir.IRRecord, 0, len(records))
+	for i := range records {
+		if d.Keep(&records[i]) {
+			result = append(result, records[i])
+		}
+	}
+	return result
+}
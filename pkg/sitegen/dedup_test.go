@@ -0,0 +1,91 @@
+package sitegen
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func dedupRecord(query map[string]any, headers map[string]string, body any) *ir.IRRecord {
+	return &ir.IRRecord{
+		Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items", Query: query, Headers: headers},
+		Response: ir.Response{Status: 200, Body: body},
+	}
+}
+
+func TestDedupOptionsKey(t *testing.T) {
+	plain := dedupRecord(map[string]any{"page": "1"}, map[string]string{"X-Flags": "a"}, map[string]any{"id": 1})
+	busted := dedupRecord(map[string]any{"page": "1", "_": "123"}, map[string]string{"X-Flags": "a"}, map[string]any{"id": 1})
+	flagged := dedupRecord(map[string]any{"page": "1"}, map[string]string{"X-Flags": "b"}, map[string]any{"id": 1})
+	traced := dedupRecord(map[string]any{"page": "1"}, map[string]string{"X-Flags": "a", "Traceparent": "t"}, map[string]any{"id": 1})
+	listed := dedupRecord(map[string]any{"page": "1"}, map[string]string{"X-Flags": "a"}, []any{map[string]any{"id": 1}})
+
+	tests := []struct {
+		name  string
+		opts  DedupOptions
+		a, b  *ir.IRRecord
+		equal bool
+	}{
+		{"default keeps query keys apart", DedupOptions{}, plain, busted, false},
+		{"excluded query key", DedupOptions{ExcludeQueryKeys: []string{"_"}}, plain, busted, true},
+		{"included query keys", DedupOptions{IncludeQueryKeys: []string{"page"}}, plain, busted, true},
+		{"default ignores header values", DedupOptions{}, plain, flagged, true},
+		{"header value", DedupOptions{RequestHeaderValues: []string{"x-flags"}}, plain, flagged, false},
+		{"default ignores header names", DedupOptions{}, plain, traced, true},
+		{"header structure", DedupOptions{RequestHeaderStructure: true}, plain, traced, false},
+		{"default ignores response structure", DedupOptions{}, plain, listed, true},
+		{"response structure", DedupOptions{ResponseStructure: true}, plain, listed, false},
+		{"key func", DedupOptions{KeyFunc: func(*ir.IRRecord, string) string { return "same" }}, plain, listed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal := tt.opts.Key(tt.a, "/items") == tt.opts.Key(tt.b, "/items")
+			if equal != tt.equal {
+				t.Errorf("keys equal = %v, want %v", equal, tt.equal)
+			}
+		})
+	}
+
+	if ComputeDedupKey(plain, "/items") != (DedupOptions{}).Key(plain, "/items") {
+		t.Error("expected ComputeDedupKey to use the default options")
+	}
+}
+
+func TestDeduper(t *testing.T) {
+	var records []ir.IRRecord
+	for i := 0; i < 5; i++ {
+		records = append(records, *dedupRecord(nil, nil, map[string]any{"id": i}))
+	}
+	// A different response structure is a separate group, so its schema is kept
+	records = append(records, *dedupRecord(nil, nil, map[string]any{"error": "gone"}))
+	// Different IDs share a path template
+	records = append(records, ir.IRRecord{
+		Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items/42"},
+		Response: ir.Response{Status: 200},
+	}, ir.IRRecord{
+		Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/items/43"},
+		Response: ir.Response{Status: 200},
+	})
+
+	d := NewDeduper(2)
+	var kept []ir.IRRecord
+	for i := range records {
+		if d.Keep(&records[i]) {
+			kept = append(kept, records[i])
+		}
+	}
+	if d.Seen() != 8 || d.Kept() != 5 || d.Groups() != 3 {
+		t.Errorf("seen/kept/groups = %d/%d/%d, want 8/5/3", d.Seen(), d.Kept(), d.Groups())
+	}
+	if kept[0].Response.Body.(map[string]any)["id"] != 0 || kept[1].Response.Body.(map[string]any)["id"] != 1 {
+		t.Error("expected the first records of a group to be kept")
+	}
+
+	if got := DedupeRecords(records, 1); len(got) != 3 {
+		t.Errorf("DedupeRecords kept %d records, want 3", len(got))
+	}
+	if got := NewDeduper(0).PerGroup; got != 1 {
+		t.Errorf("expected PerGroup of at least 1, got %d", got)
+	}
+}
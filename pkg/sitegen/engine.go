@@ -7,14 +7,22 @@ import (
 
 	"github.com/grokify/traffic2openapi/pkg/inference"
 	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/resources"
 )
 
 // Engine processes IR records and stores them for site generation.
 type Engine struct {
 	mu      sync.RWMutex
-	records map[string][]*StoredRecord // endpointKey -> records
+	records map[string][]*StoredRecord // pageKey -> records
+	flows   map[string][]*StoredRecord // flow ID -> records, in capture order
+	pageIDs []string                   // flow IDs in order of first appearance
+	titles  map[string]string          // flow ID -> HAR page title
 	hosts   map[string]bool
+	changed map[string]bool // pageKeys with records added since takeChanged
 	options *Options
+
+	sessionizer *ir.Sessionizer            // nil unless Options.Sessions
+	sessions    map[string][]*StoredRecord // session ID -> records
 }
 
 // NewEngine creates a new site generation engine.
@@ -22,15 +30,27 @@ func NewEngine(opts *Options) *Engine {
 	if opts == nil {
 		opts = DefaultOptions()
 	}
-	return &Engine{
+	e := &Engine{
 		records: make(map[string][]*StoredRecord),
+		flows:   make(map[string][]*StoredRecord),
+		titles:  make(map[string]string),
 		hosts:   make(map[string]bool),
+		changed: make(map[string]bool),
 		options: opts,
 	}
+	if opts.Sessions {
+		e.sessionizer = ir.NewSessionizer(ir.SessionOptions{Gap: opts.SessionGap})
+		e.sessions = make(map[string][]*StoredRecord)
+	}
+	return e
 }
 
 // ProcessRecord processes a single IR record.
 func (e *Engine) ProcessRecord(record *ir.IRRecord) {
+	if len(e.options.MetadataFilter) > 0 && !ir.MatchesMetadata(record, e.options.MetadataFilter) {
+		return
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -40,8 +60,18 @@ func (e *Engine) ProcessRecord(record *ir.IRRecord) {
 	// Create endpoint key
 	endpointKey := inference.EndpointKey(string(record.Request.Method), pathTemplate)
 
+	// Keep endpoints of different hosts on separate pages if configured
+	var host string
+	if record.Request.Host != nil {
+		host = *record.Request.Host
+	}
+	pageKey := endpointKey
+	if e.options.GroupByHost {
+		pageKey = host + " " + endpointKey
+	}
+
 	// Compute dedup key
-	dedupKey := ComputeDedupKey(record, pathTemplate)
+	dedupKey := e.options.Dedup.Key(record, pathTemplate)
 
 	// Store the record
 	stored := &StoredRecord{
@@ -49,15 +79,46 @@ func (e *Engine) ProcessRecord(record *ir.IRRecord) {
 		PathTemplate: pathTemplate,
 		PathParams:   pathParams,
 		EndpointKey:  endpointKey,
+		PageKey:      pageKey,
 		DedupKey:     dedupKey,
 	}
 
-	e.records[endpointKey] = append(e.records[endpointKey], stored)
+	e.records[pageKey] = append(e.records[pageKey], stored)
+	e.changed[pageKey] = true
+
+	// Track page/user flow
+	if flowID, title := e.flowID(record); flowID != "" {
+		if _, exists := e.flows[flowID]; !exists {
+			e.pageIDs = append(e.pageIDs, flowID)
+		}
+		e.flows[flowID] = append(e.flows[flowID], stored)
+		if title != "" && e.titles[flowID] == "" {
+			e.titles[flowID] = title
+		}
+	}
+
+	// Track client sessions
+	if e.sessionizer != nil {
+		if session := e.sessionizer.Add(record); session != nil {
+			e.sessions[session.ID] = append(e.sessions[session.ID], stored)
+		}
+	}
 
 	// Track hosts
-	if record.Request.Host != nil {
-		e.hosts[*record.Request.Host] = true
+	if host != "" {
+		e.hosts[host] = true
+	}
+}
+
+// flowID returns the user flow a record belongs to, or "" if none, and the
+// title of its HAR page, if any.
+func (e *Engine) flowID(record *ir.IRRecord) (string, string) {
+	if e.options.FlowMetaKey != "" {
+		if value := record.MetaString(e.options.FlowMetaKey); value != "" {
+			return e.options.FlowMetaKey + ": " + value, ""
+		}
 	}
+	return record.MetaString(ir.MetaHARPageRef), record.MetaString(ir.MetaHARPageTitle)
 }
 
 // ProcessRecords processes multiple IR records.
@@ -67,6 +128,16 @@ func (e *Engine) ProcessRecords(records []ir.IRRecord) {
 	}
 }
 
+// takeChanged returns the pageKeys with records added since the last call,
+// and resets them.
+func (e *Engine) takeChanged() map[string]bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	changed := e.changed
+	e.changed = make(map[string]bool)
+	return changed
+}
+
 // BuildSiteData builds the SiteData from processed records.
 func (e *Engine) BuildSiteData() *SiteData {
 	e.mu.RLock()
@@ -88,9 +159,23 @@ func (e *Engine) BuildSiteData() *SiteData {
 	// Build endpoint pages
 	endpoints := e.buildEndpointPages()
 
+	var hostGroups []*HostGroup
+	var resourceGroups []*ResourceGroup
+	if e.options.GroupByHost {
+		hostGroups = buildHostGroups(endpoints)
+	} else if e.options.GroupByResource {
+		resourceGroups = buildResourceGroups(endpoints)
+	}
+
 	return &SiteData{
 		Title:     e.options.Title,
 		Endpoints: endpoints,
+		Flows:     e.buildFlowGroups(),
+		Hosts:     hostGroups,
+		Resources: resourceGroups,
+		Sessions:  e.buildSessionGroups(),
+		CORS:      e.buildCORSGroups(),
+		Model:     e.buildResourceModel(),
 		Stats: &SiteStats{
 			TotalRequests:  totalRequests,
 			TotalEndpoints: len(endpoints),
@@ -116,29 +201,285 @@ func (e *Engine) buildEndpointPages() []*EndpointPage {
 			continue
 		}
 
-		// Parse endpoint key (e.g., "GET /users/{userId}")
-		parts := strings.SplitN(key, " ", 2)
-		method := parts[0]
-		pathTemplate := "/"
-		if len(parts) > 1 {
-			pathTemplate = parts[1]
+		first := records[0]
+		method := string(first.Record.Request.Method)
+		pathTemplate := first.PathTemplate
+		var host string
+		if e.options.GroupByHost && first.Record.Request.Host != nil {
+			host = *first.Record.Request.Host
 		}
 
 		// Group by status code
 		statusGroups := e.buildStatusGroups(records)
+		statuses := make([]int, len(statusGroups))
+		for i, sg := range statusGroups {
+			statuses[i] = sg.StatusCode
+		}
 
 		pages = append(pages, &EndpointPage{
+			pageKey:      key,
+			Host:         host,
 			Method:       method,
 			PathTemplate: pathTemplate,
-			Slug:         makeSlug(method, pathTemplate),
+			Slug:         e.pageSlug(first),
 			RequestCount: len(records),
 			StatusGroups: statusGroups,
+			Action:       inference.ClassifyCRUD(method, pathTemplate, statuses),
 		})
 	}
 
 	return pages
 }
 
+// buildFlowGroups builds FlowGroup structures from records with page refs.
+func (e *Engine) buildFlowGroups() []*FlowGroup {
+	flows := make([]*FlowGroup, 0, len(e.pageIDs))
+
+	for _, pageRef := range e.pageIDs {
+		records := e.flows[pageRef]
+		flow := &FlowGroup{
+			PageRef:      pageRef,
+			Title:        e.titles[pageRef],
+			RequestCount: len(records),
+		}
+		if flow.Title == "" {
+			flow.Title = pageRef
+		}
+
+		byKey := make(map[string]*FlowEndpoint)
+		for _, rec := range records {
+			if ep, exists := byKey[rec.PageKey]; exists {
+				ep.RequestCount++
+				continue
+			}
+			ep := &FlowEndpoint{
+				Method:       string(rec.Record.Request.Method),
+				PathTemplate: rec.PathTemplate,
+				Slug:         e.pageSlug(rec),
+				RequestCount: 1,
+			}
+			byKey[rec.PageKey] = ep
+			flow.Endpoints = append(flow.Endpoints, ep)
+		}
+
+		flows = append(flows, flow)
+	}
+
+	return flows
+}
+
+// buildSessionGroups builds SessionGroup structures from client sessions.
+func (e *Engine) buildSessionGroups() []*SessionGroup {
+	if e.sessionizer == nil {
+		return nil
+	}
+
+	sessions := e.sessionizer.Sessions()
+	groups := make([]*SessionGroup, 0, len(sessions))
+	for _, session := range sessions {
+		records := e.sessions[session.ID]
+		group := &SessionGroup{
+			ID:           session.ID,
+			Identity:     session.Identity,
+			Start:        session.Start,
+			End:          session.End,
+			RequestCount: len(records),
+		}
+
+		var last string
+		for _, rec := range records {
+			if rec.PageKey == last {
+				group.Steps[len(group.Steps)-1].RequestCount++
+				continue
+			}
+			last = rec.PageKey
+			group.Steps = append(group.Steps, &FlowEndpoint{
+				Method:       string(rec.Record.Request.Method),
+				PathTemplate: rec.PathTemplate,
+				Slug:         e.pageSlug(rec),
+				RequestCount: 1,
+			})
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// buildCORSGroups summarizes the Access-Control-* response headers of each
+// endpoint, grouped by host.
+func (e *Engine) buildCORSGroups() []*CORSGroup {
+	pageKeys := make([]string, 0, len(e.records))
+	for key := range e.records {
+		pageKeys = append(pageKeys, key)
+	}
+	sort.Strings(pageKeys)
+
+	detector := inference.NewCORSDetector()
+	firstRecords := make(map[string]*StoredRecord) // host + " " + endpoint key -> first record
+	for _, key := range pageKeys {
+		for _, rec := range e.records[key] {
+			var host string
+			if rec.Record.Request.Host != nil {
+				host = *rec.Record.Request.Host
+			}
+			detector.DetectFromResponse(host, rec.EndpointKey, rec.Record.Response.Headers)
+			if _, ok := firstRecords[host+" "+rec.EndpointKey]; !ok {
+				firstRecords[host+" "+rec.EndpointKey] = rec
+			}
+		}
+	}
+
+	var groups []*CORSGroup
+	for _, policy := range detector.GetPolicies() {
+		if len(groups) == 0 || groups[len(groups)-1].Host != policy.Host {
+			groups = append(groups, &CORSGroup{Host: policy.Host})
+		}
+		rec := firstRecords[policy.Host+" "+policy.Endpoint]
+		group := groups[len(groups)-1]
+		group.Policies = append(group.Policies, &CORSView{
+			CORSPolicy:   policy,
+			Method:       string(rec.Record.Request.Method),
+			PathTemplate: rec.PathTemplate,
+			Slug:         e.pageSlug(rec),
+		})
+	}
+	return groups
+}
+
+// buildResourceModel infers the API from the stored records and returns its
+// resource model, or nil unless Options.ResourceModel is set.
+func (e *Engine) buildResourceModel() *resources.Graph {
+	if !e.options.ResourceModel {
+		return nil
+	}
+	pageKeys := make([]string, 0, len(e.records))
+	for key := range e.records {
+		pageKeys = append(pageKeys, key)
+	}
+	sort.Strings(pageKeys)
+
+	engine := inference.NewEngine(inference.DefaultEngineOptions())
+	for _, key := range pageKeys {
+		for _, rec := range e.records[key] {
+			engine.ProcessRecord(rec.Record)
+		}
+	}
+	return resources.Build(engine.Finalize())
+}
+
+// buildHostGroups groups endpoint pages by host, in host order.
+func buildHostGroups(endpoints []*EndpointPage) []*HostGroup {
+	byHost := make(map[string]*HostGroup)
+	var hosts []string
+	for _, ep := range endpoints {
+		group, exists := byHost[ep.Host]
+		if !exists {
+			anchor := "host-none"
+			if ep.Host != "" {
+				anchor = "host-" + strings.ReplaceAll(makeSlug(ep.Host, ""), ".", "-")
+			}
+			group = &HostGroup{
+				Host:   ep.Host,
+				Anchor: anchor,
+			}
+			byHost[ep.Host] = group
+			hosts = append(hosts, ep.Host)
+		}
+		group.Endpoints = append(group.Endpoints, ep)
+		group.EndpointCount++
+		group.RequestCount += ep.RequestCount
+		for _, sg := range ep.StatusGroups {
+			if sg.StatusCode >= 400 && sg.Deduped != nil {
+				group.ErrorCount += sg.Deduped.Count
+			}
+		}
+	}
+	sort.Strings(hosts)
+
+	// Records without a host are listed last
+	if len(hosts) > 1 && hosts[0] == "" {
+		hosts = append(hosts[1:], "")
+	}
+
+	groups := make([]*HostGroup, 0, len(hosts))
+	for _, host := range hosts {
+		groups = append(groups, byHost[host])
+	}
+	return groups
+}
+
+// crudOrder is the order of endpoints within a resource section.
+var crudOrder = map[inference.CRUDAction]int{
+	inference.CRUDList:    0,
+	inference.CRUDCreate:  1,
+	inference.CRUDGet:     2,
+	inference.CRUDReplace: 3,
+	inference.CRUDUpdate:  4,
+	inference.CRUDDelete:  5,
+	"":                    6,
+}
+
+// buildResourceGroups groups endpoint pages by resource, in path order, with
+// the endpoints of each resource in CRUD order.
+func buildResourceGroups(endpoints []*EndpointPage) []*ResourceGroup {
+	byPath := make(map[string]*ResourceGroup)
+	var paths []string
+	for _, ep := range endpoints {
+		path := resourcePath(ep.PathTemplate, ep.Action)
+		group, exists := byPath[path]
+		if !exists {
+			group = &ResourceGroup{
+				Path:   path,
+				Anchor: "resource-" + makeSlug("", path),
+			}
+			byPath[path] = group
+			paths = append(paths, path)
+		}
+		group.Endpoints = append(group.Endpoints, ep)
+	}
+	sort.Strings(paths)
+
+	groups := make([]*ResourceGroup, 0, len(paths))
+	for _, path := range paths {
+		group := byPath[path]
+		sort.SliceStable(group.Endpoints, func(i, j int) bool {
+			return crudOrder[group.Endpoints[i].Action] < crudOrder[group.Endpoints[j].Action]
+		})
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// resourcePath returns the collection path of the resource an endpoint
+// belongs to: /users for /users, /users/{userId}, and non-CRUD actions on
+// an item such as POST /users/{userId}/activate.
+func resourcePath(template string, action inference.CRUDAction) string {
+	segments := strings.Split(strings.Trim(template, "/"), "/")
+	isParam := func(i int) bool { return i >= 0 && strings.HasPrefix(segments[i], "{") }
+	n := len(segments)
+	if action == "" && n >= 2 && !isParam(n-1) && isParam(n-2) {
+		n--
+	}
+	if isParam(n - 1) {
+		n--
+	}
+	if n == 0 {
+		return "/"
+	}
+	return "/" + strings.Join(segments[:n], "/")
+}
+
+// pageSlug returns the endpoint page slug for a stored record.
+func (e *Engine) pageSlug(rec *StoredRecord) string {
+	method := string(rec.Record.Request.Method)
+	if e.options.GroupByHost && rec.Record.Request.Host != nil {
+		return makeSlug(strings.ReplaceAll(*rec.Record.Request.Host, ".", "-")+"-"+method, rec.PathTemplate)
+	}
+	return makeSlug(method, rec.PathTemplate)
+}
+
 // buildStatusGroups groups records by status code.
 func (e *Engine) buildStatusGroups(records []*StoredRecord) []*StatusGroup {
 	// Group by status code
@@ -163,6 +504,7 @@ func (e *Engine) buildStatusGroups(records []*StoredRecord) []*StatusGroup {
 			StatusCode: status,
 			Distinct:   distinct,
 			Deduped:    deduped,
+			Invalid:    inference.IsInvalidRequestStatus(status),
 		})
 	}
 
@@ -0,0 +1,103 @@
+package sitegen
+
+import (
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func TestFlowGroupsUseHARPageTitles(t *testing.T) {
+	newRecord := func(path, pageRef, title string) ir.IRRecord {
+		record := ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: path},
+			Response: ir.Response{Status: 200},
+		}
+		if pageRef != "" {
+			record.SetMeta(ir.MetaHARPageRef, pageRef)
+		}
+		if title != "" {
+			record.SetMeta(ir.MetaHARPageTitle, title)
+		}
+		return record
+	}
+
+	engine := NewEngine(nil)
+	engine.ProcessRecords([]ir.IRRecord{
+		newRecord("/login", "page_1", "Sign in"),
+		newRecord("/cart", "page_2", ""),
+		newRecord("/users", "", ""),
+	})
+
+	flows := engine.buildFlowGroups()
+	if len(flows) != 2 {
+		t.Fatalf("expected 2 flows, got %d", len(flows))
+	}
+	if flows[0].PageRef != "page_1" || flows[0].Title != "Sign in" {
+		t.Errorf("first flow = %s %q, want page_1 \"Sign in\"", flows[0].PageRef, flows[0].Title)
+	}
+	if flows[1].Title != "page_2" {
+		t.Errorf("untitled flow title = %q, want its pageref", flows[1].Title)
+	}
+}
+
+func TestGroupByHost(t *testing.T) {
+	newRecord := func(host, path string, status int) ir.IRRecord {
+		record := ir.IRRecord{
+			Request:  ir.Request{Method: ir.RequestMethodGET, Path: path},
+			Response: ir.Response{Status: status},
+		}
+		if host != "" {
+			record.Request.Host = &host
+		}
+		return record
+	}
+	records := []ir.IRRecord{
+		newRecord("users.example.com", "/health", 200),
+		newRecord("orders.example.com", "/health", 200),
+		newRecord("orders.example.com", "/health", 503),
+		newRecord("orders.example.com", "/orders", 200),
+		newRecord("", "/local", 200),
+	}
+
+	// Without grouping, the same path on different hosts is one endpoint
+	flat := NewEngine(nil)
+	flat.ProcessRecords(records)
+	data := flat.BuildSiteData()
+	if len(data.Hosts) != 0 || len(data.Endpoints) != 3 {
+		t.Fatalf("expected 3 endpoints and no host groups, got %d and %d", len(data.Endpoints), len(data.Hosts))
+	}
+
+	grouped := NewEngine(&Options{GroupByHost: true})
+	grouped.ProcessRecords(records)
+	data = grouped.BuildSiteData()
+	if len(data.Endpoints) != 4 {
+		t.Fatalf("expected 4 endpoints, got %d", len(data.Endpoints))
+	}
+	if len(data.Hosts) != 3 {
+		t.Fatalf("expected 3 host groups, got %d", len(data.Hosts))
+	}
+
+	orders, users, none := data.Hosts[0], data.Hosts[1], data.Hosts[2]
+	if orders.Host != "orders.example.com" || orders.Anchor != "host-orders-example-com" {
+		t.Errorf("unexpected first host group: %s %s", orders.Host, orders.Anchor)
+	}
+	if orders.EndpointCount != 2 || orders.RequestCount != 3 || orders.ErrorCount != 1 {
+		t.Errorf("orders stats = %d endpoints, %d requests, %d errors, want 2, 3, 1",
+			orders.EndpointCount, orders.RequestCount, orders.ErrorCount)
+	}
+	if users.Host != "users.example.com" || users.RequestCount != 1 {
+		t.Errorf("unexpected second host group: %s with %d requests", users.Host, users.RequestCount)
+	}
+	if none.Host != "" || none.Anchor != "host-none" {
+		t.Errorf("expected records without a host last, got %q", none.Host)
+	}
+
+	// Endpoint pages of different hosts don't collide
+	slugs := make(map[string]bool)
+	for _, ep := range data.Endpoints {
+		if slugs[ep.Slug] {
+			t.Errorf("duplicate endpoint slug %s", ep.Slug)
+		}
+		slugs[ep.Slug] = true
+	}
+}
@@ -1,14 +1,17 @@
 package sitegen
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"os"
 	"path/filepath"
+	"text/template/parse"
 	"time"
 
 	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/resources"
 )
 
 // Generator generates static HTML sites from IR records.
@@ -16,6 +19,14 @@ type Generator struct {
 	engine    *Engine
 	outputDir string
 	options   *Options
+	hashes    map[string]string // path -> content hash of files written
+	stats     WriteStats
+}
+
+// WriteStats counts the files of a site generation.
+type WriteStats struct {
+	Written   int // files created or rewritten
+	Unchanged int // files that already had the generated content
 }
 
 // NewGenerator creates a new site generator.
@@ -27,6 +38,7 @@ func NewGenerator(outputDir string, opts *Options) *Generator {
 		engine:    NewEngine(opts),
 		outputDir: outputDir,
 		options:   opts,
+		hashes:    make(map[string]string),
 	}
 }
 
@@ -37,90 +49,252 @@ func (g *Generator) ProcessRecords(records []ir.IRRecord) {
 
 // Generate generates the static HTML site.
 func (g *Generator) Generate() error {
+	g.engine.takeChanged()
+	_, err := g.generate(nil)
+	return err
+}
+
+// GenerateChanged regenerates the index and other summary pages, and the
+// endpoint pages of endpoints with records processed since the last call to
+// Generate or GenerateChanged. It returns the number of endpoint pages whose
+// content changed. Pages of unchanged endpoints are left as they are, so the
+// site must have been generated in full first.
+func (g *Generator) GenerateChanged() (int, error) {
+	return g.generate(g.engine.takeChanged())
+}
+
+// generate writes the site, including only the endpoint pages in changed
+// unless it is nil. It returns the number of endpoint pages written.
+func (g *Generator) generate(changed map[string]bool) (int, error) {
+	g.stats = WriteStats{}
+
 	// Build site data
 	siteData := g.engine.BuildSiteData()
 	siteData.GeneratedAt = time.Now()
 
 	// Create output directory
 	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
-		return fmt.Errorf("creating output directory: %w", err)
+		return 0, fmt.Errorf("creating output directory: %w", err)
 	}
 
 	// Create assets directory and write static files
 	assetsDir := filepath.Join(g.outputDir, "assets")
 	if err := os.MkdirAll(assetsDir, 0755); err != nil {
-		return fmt.Errorf("creating assets directory: %w", err)
+		return 0, fmt.Errorf("creating assets directory: %w", err)
 	}
 
-	//nolint:gosec // G306: Static web assets need to be readable by web servers
-	if err := os.WriteFile(filepath.Join(assetsDir, "style.css"), []byte(styleCSS), 0644); err != nil {
-		return fmt.Errorf("writing style.css: %w", err)
+	if _, err := g.writeFile(filepath.Join(assetsDir, "style.css"), []byte(styleCSS)); err != nil {
+		return 0, fmt.Errorf("writing style.css: %w", err)
 	}
 
-	//nolint:gosec // G306: Static web assets need to be readable by web servers
-	if err := os.WriteFile(filepath.Join(assetsDir, "script.js"), []byte(scriptJS), 0644); err != nil {
-		return fmt.Errorf("writing script.js: %w", err)
+	if _, err := g.writeFile(filepath.Join(assetsDir, "script.js"), []byte(scriptJS)); err != nil {
+		return 0, fmt.Errorf("writing script.js: %w", err)
 	}
 
 	// Parse templates
-	funcMap := template.FuncMap{
-		"json":          toJSON,
-		"jsonPretty":    toJSONPretty,
-		"statusClass":   statusClass,
-		"methodClass":   methodClass,
-		"truncate":      truncate,
-		"joinStrings":   joinStrings,
-		"hasContent":    hasContent,
-		"formatHeaders": formatHeaders,
-	}
-
-	indexTmpl, err := template.New("index").Funcs(funcMap).Parse(indexTemplate)
+	indexTmpl, err := g.parseTemplate("index", indexTemplate)
 	if err != nil {
-		return fmt.Errorf("parsing index template: %w", err)
+		return 0, err
 	}
 
-	endpointTmpl, err := template.New("endpoint").Funcs(funcMap).Parse(endpointTemplate)
+	endpointTmpl, err := g.parseTemplate("endpoint", endpointTemplate)
 	if err != nil {
-		return fmt.Errorf("parsing endpoint template: %w", err)
+		return 0, err
 	}
 
 	// Generate index page
-	indexPath := filepath.Join(g.outputDir, "index.html")
-	indexFile, err := os.Create(indexPath)
-	if err != nil {
-		return fmt.Errorf("creating index.html: %w", err)
+	var buf bytes.Buffer
+	if err := indexTmpl.Execute(&buf, siteData); err != nil {
+		return 0, fmt.Errorf("executing index template: %w", err)
 	}
-	defer indexFile.Close()
-
-	if err := indexTmpl.Execute(indexFile, siteData); err != nil {
-		return fmt.Errorf("executing index template: %w", err)
+	if _, err := g.writeFile(filepath.Join(g.outputDir, "index.html"), buf.Bytes()); err != nil {
+		return 0, fmt.Errorf("writing index.html: %w", err)
 	}
 
 	// Generate endpoint pages
+	written := 0
 	for _, ep := range siteData.Endpoints {
-		epPath := filepath.Join(g.outputDir, ep.Slug+".html")
-		epFile, err := os.Create(epPath)
-		if err != nil {
-			return fmt.Errorf("creating %s.html: %w", ep.Slug, err)
+		if changed != nil && !changed[ep.pageKey] {
+			continue
 		}
 
-		data := struct {
-			*EndpointPage
-			SiteTitle string
-			BaseURL   string
-		}{
+		data := &EndpointPageData{
 			EndpointPage: ep,
 			SiteTitle:    siteData.Title,
 			BaseURL:      g.options.BaseURL,
 		}
 
-		if err := endpointTmpl.Execute(epFile, data); err != nil {
-			epFile.Close()
-			return fmt.Errorf("executing endpoint template for %s: %w", ep.Slug, err)
+		buf.Reset()
+		if err := endpointTmpl.Execute(&buf, data); err != nil {
+			return 0, fmt.Errorf("executing endpoint template for %s: %w", ep.Slug, err)
+		}
+		wrote, err := g.writeFile(filepath.Join(g.outputDir, ep.Slug+".html"), buf.Bytes())
+		if err != nil {
+			return 0, fmt.Errorf("writing %s.html: %w", ep.Slug, err)
+		}
+		if wrote {
+			written++
+		}
+	}
+
+	// Generate CORS policy page
+	if len(siteData.CORS) > 0 {
+		corsTmpl, err := g.parseTemplate("cors", corsTemplate)
+		if err != nil {
+			return 0, err
+		}
+
+		data := &CORSPageData{
+			Groups:    siteData.CORS,
+			SiteTitle: siteData.Title,
+			BaseURL:   g.options.BaseURL,
+		}
+		buf.Reset()
+		if err := corsTmpl.Execute(&buf, data); err != nil {
+			return 0, fmt.Errorf("executing cors template: %w", err)
+		}
+		if _, err := g.writeFile(filepath.Join(g.outputDir, "cors.html"), buf.Bytes()); err != nil {
+			return 0, fmt.Errorf("writing cors.html: %w", err)
+		}
+	}
+
+	// Generate resource model page
+	if siteData.Model != nil {
+		resourcesTmpl, err := g.parseTemplate("resources", resourcesTemplate)
+		if err != nil {
+			return 0, err
+		}
+
+		var mermaid bytes.Buffer
+		if err := resources.WriteMermaid(&mermaid, siteData.Model); err != nil {
+			return 0, fmt.Errorf("writing resource diagram: %w", err)
+		}
+		data := &ResourcesPageData{
+			Graph:     siteData.Model,
+			Mermaid:   mermaid.String(),
+			SiteTitle: siteData.Title,
+			BaseURL:   g.options.BaseURL,
+		}
+		buf.Reset()
+		if err := resourcesTmpl.Execute(&buf, data); err != nil {
+			return 0, fmt.Errorf("executing resources template: %w", err)
+		}
+		if _, err := g.writeFile(filepath.Join(g.outputDir, "resources.html"), buf.Bytes()); err != nil {
+			return 0, fmt.Errorf("writing resources.html: %w", err)
+		}
+	}
+
+	return written, nil
+}
+
+// writeFile writes data to path unless the file already holds it, so that
+// unchanged pages keep their modification time and are skipped by tools
+// such as rsync. Content hashes of written files are kept across
+// generations; files not yet seen are hashed from disk. It reports whether
+// the file was written.
+func (g *Generator) writeFile(path string, data []byte) (bool, error) {
+	hash := ir.ContentHash(data)
+	known, ok := g.hashes[path]
+	if !ok {
+		if existing, err := os.ReadFile(path); err == nil {
+			known = ir.ContentHash(existing)
+		}
+	}
+	if known == hash {
+		g.hashes[path] = hash
+		g.stats.Unchanged++
+		return false, nil
+	}
+
+	//nolint:gosec // G306: Static web pages need to be readable by web servers
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		delete(g.hashes, path)
+		return false, err
+	}
+	g.hashes[path] = hash
+	g.stats.Written++
+	return true, nil
+}
+
+// Stats returns the files written and left unchanged by the last call to
+// Generate or GenerateChanged.
+func (g *Generator) Stats() WriteStats {
+	return g.stats
+}
+
+// templateFuncs are the helper functions available to page templates.
+var templateFuncs = template.FuncMap{
+	"json":          toJSON,
+	"jsonPretty":    toJSONPretty,
+	"statusClass":   statusClass,
+	"methodClass":   methodClass,
+	"truncate":      truncate,
+	"joinStrings":   joinStrings,
+	"hasContent":    hasContent,
+	"formatHeaders": formatHeaders,
+}
+
+// parseTemplate parses the embedded default page template, then any partials
+// and the <name>.html page from Options.TemplateDir, which override the
+// defaults' named templates and page body respectively. Partials may only
+// define named templates; text outside {{define}} blocks is an error rather
+// than replacing the page body.
+func (g *Generator) parseTemplate(name, defaultText string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(defaultText)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template: %w", name, err)
+	}
+	if g.options.TemplateDir == "" {
+		return tmpl, nil
+	}
+	if info, err := os.Stat(g.options.TemplateDir); err != nil {
+		return nil, fmt.Errorf("reading template directory: %w", err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("template directory %s is not a directory", g.options.TemplateDir)
+	}
+
+	partials, err := filepath.Glob(filepath.Join(g.options.TemplateDir, "partials", "*.html"))
+	if err != nil {
+		return nil, fmt.Errorf("listing partials: %w", err)
+	}
+	for _, file := range partials {
+		if err := addPartial(tmpl, file); err != nil {
+			return nil, err
+		}
+	}
+
+	page := filepath.Join(g.options.TemplateDir, name+".html")
+	if _, err := os.Stat(page); err == nil {
+		text, err := os.ReadFile(page)
+		if err != nil {
+			return nil, fmt.Errorf("reading template: %w", err)
+		}
+		if _, err := tmpl.Parse(string(text)); err != nil {
+			return nil, fmt.Errorf("parsing template %s: %w", page, err)
 		}
-		epFile.Close()
 	}
+	return tmpl, nil
+}
 
+// addPartial parses a partial file on its own and adds the templates it
+// defines to tmpl.
+func addPartial(tmpl *template.Template, file string) error {
+	base := filepath.Base(file)
+	partial, err := template.New(base).Funcs(templateFuncs).ParseFiles(file)
+	if err != nil {
+		return fmt.Errorf("parsing template %s: %w", file, err)
+	}
+	for _, t := range partial.Templates() {
+		if t.Name() == base {
+			if t.Tree != nil && !parse.IsEmptyTree(t.Tree.Root) {
+				return fmt.Errorf("partial %s has content outside {{define}} blocks", file)
+			}
+			continue
+		}
+		if _, err := tmpl.AddParseTree(t.Name(), t.Tree); err != nil {
+			return fmt.Errorf("adding template %s from %s: %w", t.Name(), file, err)
+		}
+	}
 	return nil
 }
 
@@ -213,14 +387,22 @@ func hasContent(v any) bool {
 	}
 }
 
-func formatHeaders(headers map[string]string) string {
+// formatHeaders renders headers one per line, repeating the name for each
+// value of repeated headers.
+func formatHeaders(headers map[string]string, values map[string][]string) string {
 	if len(headers) == 0 {
 		return ""
 	}
 	result := ""
 	keys := sortedMapKeys(headers)
 	for _, k := range keys {
-		result += fmt.Sprintf("%s: %s\n", k, headers[k])
+		vals, ok := values[k]
+		if !ok {
+			vals = []string{headers[k]}
+		}
+		for _, v := range vals {
+			result += fmt.Sprintf("%s: %s\n", k, v)
+		}
 	}
 	return result
 }
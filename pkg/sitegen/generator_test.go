@@ -0,0 +1,98 @@
+package sitegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grokify/traffic2openapi/pkg/ir"
+)
+
+func writeTemplateFile(t *testing.T, path, text string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func generateSite(t *testing.T, opts *Options) (string, error) {
+	t.Helper()
+	outputDir := t.TempDir()
+	gen := NewGenerator(outputDir, opts)
+	gen.ProcessRecords([]ir.IRRecord{{
+		Request:  ir.Request{Method: ir.RequestMethodGET, Path: "/users"},
+		Response: ir.Response{Status: 200},
+	}})
+	return outputDir, gen.Generate()
+}
+
+func readSiteFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCustomTemplates(t *testing.T) {
+	templateDir := t.TempDir()
+	writeTemplateFile(t, filepath.Join(templateDir, "partials", "table.html"),
+		`{{define "endpointTable"}}{{range .}}<p class="custom-row">{{.Method}} {{.PathTemplate}}</p>{{end}}{{end}}`)
+
+	outputDir, err := generateSite(t, &Options{Title: "API", TemplateDir: templateDir})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	index := readSiteFile(t, outputDir, "index.html")
+	if !strings.Contains(index, `<p class="custom-row">GET /users</p>`) {
+		t.Error("expected the partial to override endpointTable")
+	}
+	if !strings.Contains(index, "<!DOCTYPE html>") {
+		t.Error("expected the default page body to be kept")
+	}
+
+	// A page template replaces the default body and can use the partials
+	writeTemplateFile(t, filepath.Join(templateDir, "index.html"),
+		`<main>{{.Title}}: {{template "endpointTable" .Endpoints}}</main>`)
+	outputDir, err = generateSite(t, &Options{Title: "API", TemplateDir: templateDir})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	index = readSiteFile(t, outputDir, "index.html")
+	if index != `<main>API: <p class="custom-row">GET /users</p></main>` {
+		t.Errorf("unexpected custom index: %s", index)
+	}
+}
+
+func TestCustomTemplatePartialOutsideDefine(t *testing.T) {
+	templateDir := t.TempDir()
+	writeTemplateFile(t, filepath.Join(templateDir, "partials", "footer.html"),
+		`<footer>stray</footer>{{define "footer"}}<footer>ok</footer>{{end}}`)
+
+	_, err := generateSite(t, &Options{TemplateDir: templateDir})
+	if err == nil || !strings.Contains(err.Error(), "outside {{define}}") {
+		t.Fatalf("expected an error for content outside define blocks, got %v", err)
+	}
+
+	// Whitespace and comments around define blocks are allowed
+	writeTemplateFile(t, filepath.Join(templateDir, "partials", "footer.html"),
+		"{{/* site footer */}}\n{{define \"footer\"}}<footer>ok</footer>{{end}}\n")
+	outputDir, err := generateSite(t, &Options{TemplateDir: templateDir})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(readSiteFile(t, outputDir, "index.html"), "<!DOCTYPE html>") {
+		t.Error("expected the default page body to be kept")
+	}
+}
+
+func TestCustomTemplateDirMissing(t *testing.T) {
+	if _, err := generateSite(t, &Options{TemplateDir: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("expected an error for a missing template directory")
+	}
+}
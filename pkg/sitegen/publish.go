@@ -0,0 +1,119 @@
+package sitegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/grokify/omnistorage"
+)
+
+// PublishOptions configures Publish.
+type PublishOptions struct {
+	// Prefix is prepended to every object path, e.g. "docs/api".
+	Prefix string
+
+	// CacheControl, if set, is the Cache-Control header of every object. It
+	// requires a HeaderBackend.
+	CacheControl string
+}
+
+// ObjectHeaders are the HTTP headers a published object is served with.
+type ObjectHeaders struct {
+	ContentType  string
+	CacheControl string
+}
+
+// HeaderBackend is implemented by backends that can set the HTTP headers of
+// the objects they write. omnistorage writer options only carry a content
+// type and user metadata, which S3 stores as x-amz-meta-* headers that are
+// never served as Cache-Control.
+type HeaderBackend interface {
+	omnistorage.Backend
+	NewHeaderWriter(ctx context.Context, path string, headers ObjectHeaders) (io.WriteCloser, error)
+}
+
+// ErrCacheControlUnsupported is returned by Publish when
+// PublishOptions.CacheControl is set for a backend that is not a
+// HeaderBackend.
+var ErrCacheControlUnsupported = errors.New("backend cannot set Cache-Control headers")
+
+// Publish copies every file of a generated site directory to an omnistorage
+// backend, such as a bucket, keeping the directory layout. Each object is
+// written with a content type derived from its extension, so HTML, CSS, and
+// JavaScript are served correctly by backends that honor it. Objects are
+// written through NewHeaderWriter when the backend is a HeaderBackend. It
+// returns the number of files published.
+func Publish(ctx context.Context, dir string, backend omnistorage.Backend, opts PublishOptions) (int, error) {
+	if _, ok := backend.(HeaderBackend); opts.CacheControl != "" && !ok {
+		return 0, ErrCacheControlUnsupported
+	}
+	count := 0
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		key := path.Join(opts.Prefix, filepath.ToSlash(rel))
+		if err := publishFile(ctx, backend, p, key, opts); err != nil {
+			return fmt.Errorf("publishing %s: %w", key, err)
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+func publishFile(ctx context.Context, backend omnistorage.Backend, src, key string, opts PublishOptions) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var w io.WriteCloser
+	if headerBackend, ok := backend.(HeaderBackend); ok {
+		w, err = headerBackend.NewHeaderWriter(ctx, key, ObjectHeaders{
+			ContentType:  ContentType(src),
+			CacheControl: opts.CacheControl,
+		})
+	} else {
+		w, err = backend.NewWriter(ctx, key, omnistorage.WithContentType(ContentType(src)))
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// ContentType returns the content type of a site file from its extension,
+// or application/octet-stream if it is unknown.
+func ContentType(name string) string {
+	switch filepath.Ext(name) {
+	case ".html":
+		return "text/html; charset=utf-8"
+	case ".css":
+		return "text/css; charset=utf-8"
+	case ".js":
+		return "text/javascript; charset=utf-8"
+	case ".json":
+		return "application/json"
+	}
+	if ct := mime.TypeByExtension(filepath.Ext(name)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
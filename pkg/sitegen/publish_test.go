@@ -0,0 +1,114 @@
+package sitegen
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/grokify/omnistorage"
+	"github.com/grokify/omnistorage/backend/memory"
+)
+
+// headerBackend records the headers each object is written with, which the
+// memory backend does not keep.
+type headerBackend struct {
+	*memory.Backend
+	headers map[string]ObjectHeaders
+}
+
+func (b *headerBackend) NewHeaderWriter(ctx context.Context, p string, headers ObjectHeaders) (io.WriteCloser, error) {
+	b.headers[p] = headers
+	return b.Backend.NewWriter(ctx, p, omnistorage.WithContentType(headers.ContentType))
+}
+
+func TestPublish(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, filepath.Join(dir, "index.html"), "<html></html>")
+	writeTemplateFile(t, filepath.Join(dir, "assets", "style.css"), "body {}")
+	writeTemplateFile(t, filepath.Join(dir, "users", "get.json"), "{}")
+
+	ctx := context.Background()
+	backend := &headerBackend{Backend: memory.New(), headers: make(map[string]ObjectHeaders)}
+	count, err := Publish(ctx, dir, backend, PublishOptions{Prefix: "docs/api", CacheControl: "max-age=300"})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 files, got %d", count)
+	}
+
+	want := map[string]string{
+		"docs/api/index.html":       "text/html; charset=utf-8",
+		"docs/api/assets/style.css": "text/css; charset=utf-8",
+		"docs/api/users/get.json":   "application/json",
+	}
+	for key, contentType := range want {
+		info, err := backend.Stat(ctx, key)
+		if err != nil {
+			t.Errorf("%s not published: %v", key, err)
+			continue
+		}
+		if info.ContentType() != contentType {
+			t.Errorf("%s: expected content type %s, got %s", key, contentType, info.ContentType())
+		}
+		if got := backend.headers[key]; got != (ObjectHeaders{ContentType: contentType, CacheControl: "max-age=300"}) {
+			t.Errorf("%s: unexpected headers %+v", key, got)
+		}
+	}
+
+	r, err := backend.NewReader(ctx, "docs/api/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if data, _ := io.ReadAll(r); string(data) != "<html></html>" {
+		t.Errorf("unexpected index.html content: %q", data)
+	}
+}
+
+func TestPublishWithoutCacheControl(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, filepath.Join(dir, "index.html"), "<html></html>")
+
+	backend := memory.New()
+	if _, err := Publish(context.Background(), dir, backend, PublishOptions{}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	info, err := backend.Stat(context.Background(), "index.html")
+	if err != nil {
+		t.Fatalf("index.html not published: %v", err)
+	}
+	if info.ContentType() != "text/html; charset=utf-8" {
+		t.Errorf("unexpected content type %s", info.ContentType())
+	}
+}
+
+func TestPublishCacheControlUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, filepath.Join(dir, "index.html"), "<html></html>")
+
+	backend := memory.New()
+	_, err := Publish(context.Background(), dir, backend, PublishOptions{CacheControl: "max-age=300"})
+	if !errors.Is(err, ErrCacheControlUnsupported) {
+		t.Fatalf("expected ErrCacheControlUnsupported, got %v", err)
+	}
+	if exists, _ := backend.Exists(context.Background(), "index.html"); exists {
+		t.Error("expected nothing to be published")
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := map[string]string{
+		"index.html": "text/html; charset=utf-8",
+		"app.js":     "text/javascript; charset=utf-8",
+		"logo.png":   "image/png",
+		"data.bin":   "application/octet-stream",
+	}
+	for name, want := range tests {
+		if got := ContentType(name); got != want {
+			t.Errorf("ContentType(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
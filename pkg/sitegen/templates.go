@@ -37,15 +37,67 @@ const indexTemplate = `<!DOCTYPE html>
             {{end}}
         </section>
 
+        {{if .Hosts}}
+        <nav class="toc hosts-toc">
+            <h2>Hosts</h2>
+            <ul>
+                {{range .Hosts}}
+                <li>
+                    <a href="#{{.Anchor}}">
+                        <span class="host-name">{{if .Host}}{{.Host}}{{else}}(no host){{end}}</span>
+                        <span class="count">({{.EndpointCount}} endpoints)</span>
+                    </a>
+                </li>
+                {{end}}
+            </ul>
+        </nav>
+
+        {{range .Hosts}}
+        <section class="endpoints host-section" id="{{.Anchor}}">
+            <h2>{{if .Host}}{{.Host}}{{else}}(no host){{end}}</h2>
+            <p class="host-stats">{{.EndpointCount}} endpoints · {{.RequestCount}} requests · {{.ErrorCount}} errors</p>
+            {{template "endpointTable" .Endpoints}}
+        </section>
+        {{end}}
+        {{else if .Resources}}
+        <nav class="toc resources-toc">
+            <h2>Resources</h2>
+            <ul>
+                {{range .Resources}}
+                <li>
+                    <a href="#{{.Anchor}}">
+                        <span class="resource-path">{{.Path}}</span>
+                        <span class="count">({{len .Endpoints}} endpoints)</span>
+                    </a>
+                </li>
+                {{end}}
+            </ul>
+        </nav>
+
+        {{range .Resources}}
+        <section class="endpoints resource-section" id="{{.Anchor}}">
+            <h2>{{.Path}}</h2>
+            {{template "endpointTable" .Endpoints}}
+        </section>
+        {{end}}
+        {{else}}
         <section class="endpoints">
             <h2>Endpoints</h2>
+            {{template "endpointTable" .Endpoints}}
+        </section>
+        {{end}}
+
+        {{if .Flows}}
+        <section class="endpoints flows">
+            <h2>User Flows</h2>
+            {{range .Flows}}
+            <h3>{{.Title}} <span class="count">({{.RequestCount}} requests)</span></h3>
             <table class="endpoints-table">
                 <thead>
                     <tr>
                         <th>Method</th>
                         <th>Path</th>
                         <th>Requests</th>
-                        <th>Status Codes</th>
                     </tr>
                 </thead>
                 <tbody>
@@ -54,16 +106,54 @@ const indexTemplate = `<!DOCTYPE html>
                         <td><span class="method-badge {{methodClass .Method}}">{{.Method}}</span></td>
                         <td><a href="{{.Slug}}.html" class="endpoint-link">{{.PathTemplate}}</a></td>
                         <td class="count">{{.RequestCount}}</td>
-                        <td class="status-codes">
-                            {{range .StatusGroups}}
-                            <span class="status-badge {{statusClass .StatusCode}}">{{.StatusCode}}</span>
-                            {{end}}
-                        </td>
                     </tr>
                     {{end}}
                 </tbody>
             </table>
+            {{end}}
+        </section>
+        {{end}}
+
+        {{if .Sessions}}
+        <section class="endpoints sessions">
+            <h2>Sessions</h2>
+            {{range .Sessions}}
+            <h3>{{.ID}} <span class="count">({{.Identity}} · {{.RequestCount}} requests{{if not .Start.IsZero}} · {{.Start.Format "2006-01-02 15:04:05"}} – {{.End.Format "15:04:05"}}{{end}})</span></h3>
+            <table class="endpoints-table">
+                <thead>
+                    <tr>
+                        <th>Method</th>
+                        <th>Path</th>
+                        <th>Requests</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Steps}}
+                    <tr>
+                        <td><span class="method-badge {{methodClass .Method}}">{{.Method}}</span></td>
+                        <td><a href="{{.Slug}}.html" class="endpoint-link">{{.PathTemplate}}</a></td>
+                        <td class="count">{{.RequestCount}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{end}}
+        </section>
+        {{end}}
+
+        {{if .Model}}
+        <section class="endpoints resource-model">
+            <h2>Resource Model</h2>
+            <p><a href="resources.html" class="endpoint-link">Resources</a>, their fields, and the relations between them, with an ER diagram</p>
         </section>
+        {{end}}
+
+        {{if .CORS}}
+        <section class="endpoints cors">
+            <h2>CORS</h2>
+            <p><a href="cors.html" class="endpoint-link">CORS policies</a> of endpoints returning Access-Control-* headers</p>
+        </section>
+        {{end}}
 
         <footer>
             <p>Generated by <a href="https://github.com/grokify/traffic2openapi">traffic2openapi</a> on {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}</p>
@@ -72,7 +162,33 @@ const indexTemplate = `<!DOCTYPE html>
 
     <script src="assets/script.js"></script>
 </body>
-</html>`
+</html>
+{{define "endpointTable"}}
+<table class="endpoints-table">
+    <thead>
+        <tr>
+            <th>Method</th>
+            <th>Path</th>
+            <th>Requests</th>
+            <th>Status Codes</th>
+        </tr>
+    </thead>
+    <tbody>
+        {{range .}}
+        <tr>
+            <td><span class="method-badge {{methodClass .Method}}">{{.Method}}</span></td>
+            <td><a href="{{.Slug}}.html" class="endpoint-link">{{.PathTemplate}}</a>{{if .Action}} <span class="action-badge">{{.Action}}</span>{{end}}</td>
+            <td class="count">{{.RequestCount}}</td>
+            <td class="status-codes">
+                {{range .StatusGroups}}
+                <span class="status-badge {{statusClass .StatusCode}}">{{.StatusCode}}</span>
+                {{end}}
+            </td>
+        </tr>
+        {{end}}
+    </tbody>
+</table>
+{{end}}`
 
 const endpointTemplate = `<!DOCTYPE html>
 <html lang="en">
@@ -87,6 +203,10 @@ const endpointTemplate = `<!DOCTYPE html>
         <div class="header-content">
             <nav class="breadcrumb">
                 <a href="index.html">{{.SiteTitle}}</a>
+                {{if .Host}}
+                <span class="separator">/</span>
+                <span class="host-name">{{.Host}}</span>
+                {{end}}
                 <span class="separator">/</span>
                 <span class="current">{{.Method}} {{.PathTemplate}}</span>
             </nav>
@@ -168,7 +288,7 @@ const endpointTemplate = `<!DOCTYPE html>
 
                     {{if hasContent .Deduped.RequestBodyExample}}
                     <div class="body-section">
-                        <h4>Request Body (Example)</h4>
+                        <h4>{{if $sg.Invalid}}Invalid Request Body (Example){{else}}Request Body (Example){{end}}</h4>
                         <div class="code-block">
                             <button class="copy-btn" data-copy-target="deduped-req-{{$sg.StatusCode}}">Copy</button>
                             <pre id="deduped-req-{{$sg.StatusCode}}"><code class="json">{{jsonPretty .Deduped.RequestBodyExample}}</code></pre>
@@ -214,13 +334,13 @@ const endpointTemplate = `<!DOCTYPE html>
                     {{if hasContent $req.RequestHeaders}}
                     <details class="headers-section">
                         <summary>Request Headers</summary>
-                        <pre><code>{{formatHeaders $req.RequestHeaders}}</code></pre>
+                        <pre><code>{{formatHeaders $req.RequestHeaders $req.RequestHeaderValues}}</code></pre>
                     </details>
                     {{end}}
 
                     {{if hasContent $req.RequestBody}}
                     <div class="body-section">
-                        <h4>Request Body</h4>
+                        <h4>{{if $sg.Invalid}}Invalid Request Body{{else}}Request Body{{end}}</h4>
                         <div class="code-block">
                             <button class="copy-btn" data-copy-target="distinct-req-{{$sg.StatusCode}}-{{$idx}}">Copy</button>
                             <pre id="distinct-req-{{$sg.StatusCode}}-{{$idx}}"><code class="json">{{jsonPretty $req.RequestBody}}</code></pre>
@@ -231,7 +351,7 @@ const endpointTemplate = `<!DOCTYPE html>
                     {{if hasContent $req.ResponseHeaders}}
                     <details class="headers-section">
                         <summary>Response Headers</summary>
-                        <pre><code>{{formatHeaders $req.ResponseHeaders}}</code></pre>
+                        <pre><code>{{formatHeaders $req.ResponseHeaders $req.ResponseHeaderValues}}</code></pre>
                     </details>
                     {{end}}
 
@@ -258,3 +378,177 @@ const endpointTemplate = `<!DOCTYPE html>
     <script src="assets/script.js"></script>
 </body>
 </html>`
+
+const corsTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>CORS Policies - {{.SiteTitle}}</title>
+    <link rel="stylesheet" href="assets/style.css">
+</head>
+<body>
+    <header>
+        <div class="header-content">
+            <nav class="breadcrumb">
+                <a href="index.html">{{.SiteTitle}}</a>
+                <span class="separator">/</span>
+                <span class="current">CORS Policies</span>
+            </nav>
+            <button id="theme-toggle" class="theme-toggle" aria-label="Toggle theme">
+                <span class="sun-icon">☀️</span>
+                <span class="moon-icon">🌙</span>
+            </button>
+        </div>
+    </header>
+
+    <main>
+        {{range .Groups}}
+        <section class="endpoints cors">
+            <h2>{{if .Host}}{{.Host}}{{else}}(no host){{end}}</h2>
+            <table class="endpoints-table">
+                <thead>
+                    <tr>
+                        <th>Method</th>
+                        <th>Path</th>
+                        <th>Allowed Origins</th>
+                        <th>Allowed Methods</th>
+                        <th>Allowed Headers</th>
+                        <th>Exposed Headers</th>
+                        <th>Credentials</th>
+                        <th>Max Age</th>
+                        <th>Responses</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Policies}}
+                    <tr>
+                        <td><span class="method-badge {{methodClass .Method}}">{{.Method}}</span></td>
+                        <td><a href="{{.Slug}}.html" class="endpoint-link">{{.PathTemplate}}</a></td>
+                        <td><code>{{joinStrings .AllowedOrigins ", "}}</code></td>
+                        <td>{{joinStrings .AllowedMethods ", "}}</td>
+                        <td>{{joinStrings .AllowedHeaders ", "}}</td>
+                        <td>{{joinStrings .ExposedHeaders ", "}}</td>
+                        <td>{{if .AllowCredentials}}yes{{else}}no{{end}}</td>
+                        <td>{{if .MaxAge}}{{.MaxAge}}s{{end}}</td>
+                        <td class="count">{{.Count}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </section>
+        {{end}}
+    </main>
+
+    <script src="assets/script.js"></script>
+</body>
+</html>`
+
+const resourcesTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Resource Model - {{.SiteTitle}}</title>
+    <link rel="stylesheet" href="assets/style.css">
+</head>
+<body>
+    <header>
+        <div class="header-content">
+            <nav class="breadcrumb">
+                <a href="index.html">{{.SiteTitle}}</a>
+                <span class="separator">/</span>
+                <span class="current">Resource Model</span>
+            </nav>
+            <button id="theme-toggle" class="theme-toggle" aria-label="Toggle theme">
+                <span class="sun-icon">☀️</span>
+                <span class="moon-icon">🌙</span>
+            </button>
+        </div>
+    </header>
+
+    <main>
+        <section class="endpoints resource-model">
+            <h2>Diagram</h2>
+            <p>Mermaid ER diagram source, for mermaid.live or a Markdown renderer</p>
+            <div class="code-block">
+                <button class="copy-btn" data-copy-target="resource-diagram">Copy</button>
+                <pre id="resource-diagram" class="mermaid"><code>{{.Mermaid}}</code></pre>
+            </div>
+        </section>
+
+        {{if .Relations}}
+        <section class="endpoints resource-model">
+            <h2>Relations</h2>
+            <table class="endpoints-table">
+                <thead>
+                    <tr>
+                        <th>Resource</th>
+                        <th>Refers To</th>
+                        <th>Field</th>
+                        <th>From</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Relations}}
+                    <tr>
+                        <td><a href="#resource-{{.From}}" class="endpoint-link">{{.From}}</a></td>
+                        <td><a href="#resource-{{.To}}" class="endpoint-link">{{.To}}</a>{{if .Many}} (many){{end}}</td>
+                        <td><code>{{.Field}}</code></td>
+                        <td>{{.Via}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </section>
+        {{end}}
+
+        {{range .Resources}}
+        <section class="endpoints resource-model" id="resource-{{.Name}}">
+            <h2>{{.Name}}</h2>
+            <p><code>{{joinStrings .Paths ", "}}</code></p>
+            <table class="endpoints-table">
+                <thead>
+                    <tr>
+                        <th>Method</th>
+                        <th>Path</th>
+                        <th>Action</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Operations}}
+                    <tr>
+                        <td><span class="method-badge {{methodClass .Method}}">{{.Method}}</span></td>
+                        <td>{{.Path}}</td>
+                        <td><span class="action-badge">{{.Action}}</span></td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{if .Fields}}
+            <table class="endpoints-table">
+                <thead>
+                    <tr>
+                        <th>Field</th>
+                        <th>Type</th>
+                        <th>Format</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Fields}}
+                    <tr>
+                        <td><code>{{.Name}}</code></td>
+                        <td>{{.Type}}</td>
+                        <td>{{.Format}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{end}}
+        </section>
+        {{end}}
+    </main>
+
+    <script src="assets/script.js"></script>
+</body>
+</html>`
@@ -4,7 +4,9 @@ package sitegen
 import (
 	"time"
 
+	"github.com/grokify/traffic2openapi/pkg/inference"
 	"github.com/grokify/traffic2openapi/pkg/ir"
+	"github.com/grokify/traffic2openapi/pkg/resources"
 )
 
 // SiteData is the top-level data for template rendering.
@@ -12,9 +14,33 @@ type SiteData struct {
 	Title       string
 	GeneratedAt time.Time
 	Endpoints   []*EndpointPage
+	Flows       []*FlowGroup     // requests grouped by page/user flow (empty if no page refs)
+	Hosts       []*HostGroup     // endpoints grouped by host (empty unless Options.GroupByHost)
+	Resources   []*ResourceGroup // endpoints grouped by resource (empty unless Options.GroupByResource)
+	Sessions    []*SessionGroup  // requests grouped by client session (empty unless Options.Sessions)
+	CORS        []*CORSGroup     // CORS policies by host (empty if no Access-Control-* headers were seen)
+	Model       *resources.Graph // resource model (nil unless Options.ResourceModel)
 	Stats       *SiteStats
 }
 
+// HostGroup groups the endpoints served by a single host.
+type HostGroup struct {
+	Host          string // "" for records without a host
+	Anchor        string // URL fragment for the host's section
+	Endpoints     []*EndpointPage
+	RequestCount  int
+	EndpointCount int
+	ErrorCount    int // requests with a 4xx or 5xx response
+}
+
+// ResourceGroup groups the endpoints of a single REST resource, such as the
+// list, create, get, update, and delete endpoints of /users.
+type ResourceGroup struct {
+	Path      string // collection path, e.g. "/users" or "/users/{userId}/posts"
+	Anchor    string // URL fragment for the resource's section
+	Endpoints []*EndpointPage
+}
+
 // SiteStats contains aggregate statistics for the site.
 type SiteStats struct {
 	TotalRequests  int
@@ -24,11 +50,55 @@ type SiteStats struct {
 
 // EndpointPage represents a single endpoint's page.
 type EndpointPage struct {
+	Host         string // set when endpoints are grouped by host
 	Method       string
 	PathTemplate string
 	Slug         string // URL-safe filename (e.g., "get-users-userid")
 	RequestCount int
 	StatusGroups []*StatusGroup
+	Action       inference.CRUDAction // CRUD semantics, "" for other operations
+
+	pageKey string // Engine records key, for regenerating changed pages
+}
+
+// FlowGroup groups the endpoints called within a single page or user flow.
+type FlowGroup struct {
+	PageRef      string // HAR pageref, or flow metadata key and value
+	Title        string // HAR page title, or PageRef when the page has none
+	RequestCount int
+	Endpoints    []*FlowEndpoint // in order of first call within the flow
+}
+
+// SessionGroup is the sequence of requests made in one client session.
+type SessionGroup struct {
+	ID           string
+	Identity     string // hashed bearer token or API key, or client IP
+	Start        time.Time
+	End          time.Time
+	RequestCount int
+	Steps        []*FlowEndpoint // in call order, repeated consecutive calls collapsed
+}
+
+// CORSGroup lists the CORS policies observed on a single host.
+type CORSGroup struct {
+	Host     string // "" for records without a host
+	Policies []*CORSView
+}
+
+// CORSView is the CORS policy observed for an endpoint on a host.
+type CORSView struct {
+	*inference.CORSPolicy
+	Method       string
+	PathTemplate string
+	Slug         string
+}
+
+// FlowEndpoint is an endpoint called within a flow.
+type FlowEndpoint struct {
+	Method       string
+	PathTemplate string
+	Slug         string
+	RequestCount int
 }
 
 // StatusGroup groups requests by HTTP status code.
@@ -36,6 +106,7 @@ type StatusGroup struct {
 	StatusCode int
 	Distinct   []*RequestView // All unique requests
 	Deduped    *DedupedView   // Collapsed view with all seen values
+	Invalid    bool           // a 400 or 422 status, whose request bodies show what not to send
 }
 
 // RequestView represents a single request for display.
@@ -54,6 +125,11 @@ type RequestView struct {
 	ContentType     string
 	DurationMs      float64
 	DedupKey        string
+
+	// RequestHeaderValues and ResponseHeaderValues hold every value of
+	// headers that occurred more than once.
+	RequestHeaderValues  map[string][]string
+	ResponseHeaderValues map[string][]string
 }
 
 // DedupedView shows all variations in a compact format.
@@ -73,6 +149,7 @@ type StoredRecord struct {
 	PathTemplate string
 	PathParams   map[string]string
 	EndpointKey  string
+	PageKey      string // endpoint key, prefixed with the host when grouping by host
 	DedupKey     string
 }
 
@@ -80,6 +157,70 @@ type StoredRecord struct {
 type Options struct {
 	Title   string
 	BaseURL string
+
+	// FlowMetaKey groups records into user flows by this record metadata key
+	// (e.g., "label") instead of their HAR page (ir.MetaHARPageRef).
+	// Records without the key fall back to their HAR page.
+	FlowMetaKey string
+
+	// GroupByHost keeps endpoints of different hosts apart, with a section
+	// and stats per host, for captures spanning several backend services.
+	GroupByHost bool
+
+	// GroupByResource lists endpoints in a section per REST resource,
+	// ordered by CRUD action (list, create, get, replace, update, delete).
+	// It is ignored with GroupByHost.
+	GroupByResource bool
+
+	// ResourceModel adds a page with the resource model of the API: its
+	// resources, their fields, and the relations between them, with the
+	// source of a Mermaid ER diagram.
+	ResourceModel bool
+
+	// Sessions groups requests into client sessions by bearer token, API key,
+	// or client IP, ending a session after SessionGap of inactivity
+	// (ir.DefaultSessionGap if zero). Records must be processed in time order.
+	Sessions   bool
+	SessionGap time.Duration
+
+	// Dedup configures the key that groups requests into distinct views.
+	Dedup DedupOptions
+
+	// MetadataFilter skips records whose metadata doesn't have every key with
+	// the given value (e.g., {"service": "checkout"}), to document one
+	// service of a shared capture.
+	MetadataFilter map[string]any
+
+	// TemplateDir holds custom html/template files replacing the embedded
+	// defaults: index.html (executed with *SiteData), endpoint.html (executed
+	// with *EndpointPageData), cors.html (executed with *CORSPageData),
+	// resources.html (executed with *ResourcesPageData), and partials/*.html defining named templates such as "endpointTable".
+	// Partials may only contain {{define}} blocks. Missing files fall back to
+	// the defaults.
+	TemplateDir string
+}
+
+// EndpointPageData is the data an endpoint page template is executed with.
+type EndpointPageData struct {
+	*EndpointPage
+	SiteTitle string
+	BaseURL   string
+}
+
+// CORSPageData is the data the CORS page template is executed with.
+type CORSPageData struct {
+	Groups    []*CORSGroup
+	SiteTitle string
+	BaseURL   string
+}
+
+// ResourcesPageData is the data the resource model page template is executed
+// with. Mermaid is the model as a Mermaid erDiagram.
+type ResourcesPageData struct {
+	*resources.Graph
+	Mermaid   string
+	SiteTitle string
+	BaseURL   string
 }
 
 // DefaultOptions returns the default site generation options.